@@ -639,6 +639,11 @@ func CheckNotImplementedStatus(t *testing.T, resp *model.Response) {
 	checkHTTPStatus(t, resp, http.StatusNotImplemented, true)
 }
 
+func CheckUnprocessableEntityStatus(t *testing.T, resp *model.Response) {
+	t.Helper()
+	checkHTTPStatus(t, resp, http.StatusUnprocessableEntity, true)
+}
+
 func CheckRequestEntityTooLargeStatus(t *testing.T, resp *model.Response) {
 	t.Helper()
 	checkHTTPStatus(t, resp, http.StatusRequestEntityTooLarge, true)
@@ -4,12 +4,18 @@
 package api4
 
 import (
-	"database/sql"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
 )
 
@@ -18,19 +24,93 @@ const (
 	groupMemberActionDelete
 )
 
+const (
+	GROUP_LIST_PER_PAGE_DEFAULT = 60
+	GROUP_LIST_PER_PAGE_MAXIMUM = 200
+)
+
 func (api *API) InitGroup() {
+	// GET /api/v4/groups?page=0&per_page=100
+	// GET /api/v4/groups?manageable_only=true&page=0&per_page=100
+	// GET /api/v4/groups?exclude_default=true&page=0&per_page=100
+	// GET /api/v4/groups?locale=de&page=0&per_page=100
+	// GET /api/v4/groups?fields=id,display_name&page=0&per_page=100
+	api.BaseRoutes.Groups.Handle("",
+		api.ApiSessionRequired(groupListPage(getGroups))).Methods("GET")
+
+	// POST /api/v4/groups
+	api.BaseRoutes.Groups.Handle("",
+		api.ApiSessionRequired(idempotent(createGroup))).Methods("POST")
+
+	// GET /api/v4/groups/autocomplete?name=eng&team_id=:team_id
+	// GET /api/v4/groups/autocomplete?name=eng&channel_id=:channel_id
+	api.BaseRoutes.Groups.Handle("/autocomplete",
+		api.ApiSessionRequired(autocompleteGroups)).Methods("GET")
+
+	// GET /api/v4/groups/unused?source=custom&older_than_days=90&page=0&per_page=100
+	api.BaseRoutes.Groups.Handle("/unused",
+		api.ApiSessionRequired(groupListPage(getUnusedGroups))).Methods("GET")
+
+	// GET /api/v4/groups/by_member_email_domain/contractor.com?page=0&per_page=100
+	api.BaseRoutes.Groups.Handle("/by_member_email_domain/{email_domain:[A-Za-z0-9.-]+}",
+		api.ApiSessionRequired(groupListPage(getGroupsByMemberEmailDomain))).Methods("GET")
+
+	// POST /api/v4/groups/ids
+	api.BaseRoutes.Groups.Handle("/ids",
+		api.ApiSessionRequired(getGroupsByIds)).Methods("POST")
+
+	// POST /api/v4/groups/syncables/report
+	api.BaseRoutes.Groups.Handle("/syncables/report",
+		api.ApiSessionRequired(getGroupSyncablesReport)).Methods("POST")
+
+	// POST /api/v4/groups/members/transfer
+	api.BaseRoutes.Groups.Handle("/members/transfer",
+		api.ApiSessionRequired(transferGroupMemberships)).Methods("POST")
+
+	// POST /api/v4/groups/members/union?page=0&per_page=100
+	api.BaseRoutes.Groups.Handle("/members/union",
+		api.ApiSessionRequired(groupListPage(getUnionGroupMembers))).Methods("POST")
+
+	// POST /api/v4/groups/members/intersection?page=0&per_page=100
+	api.BaseRoutes.Groups.Handle("/members/intersection",
+		api.ApiSessionRequired(groupListPage(getIntersectionGroupMembers))).Methods("POST")
+
+	// GET /api/v4/groups/users/ungrouped?page=0&per_page=100
+	api.BaseRoutes.Groups.Handle("/users/ungrouped",
+		api.ApiSessionRequired(groupListPage(getUngroupedUsers))).Methods("GET")
+
 	// GET /api/v4/groups/:group_id
 	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}",
 		api.ApiSessionRequired(getGroup)).Methods("GET")
 
+	// GET /api/v4/groups/:group_id/delete_impact
+	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/delete_impact",
+		api.ApiSessionRequired(getGroupDeleteImpact)).Methods("GET")
+
+	// GET /api/v4/groups/:group_id/stats?include_deleted=true
+	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/stats",
+		api.ApiSessionRequired(getGroupStats)).Methods("GET")
+
 	// PUT /api/v4/groups/:group_id/patch
 	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/patch",
 		api.ApiSessionRequired(patchGroup)).Methods("PUT")
 
+	// PUT /api/v4/groups/:group_id/remote_id
+	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/remote_id",
+		api.ApiSessionRequired(rebindGroupRemoteId)).Methods("PUT")
+
 	// POST /api/v4/groups/:group_id/teams/:team_id/link
 	// POST /api/v4/groups/:group_id/channels/:channel_id/link
 	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/{syncable_type:teams|channels}/{syncable_id:[A-Za-z0-9]+}/link",
-		api.ApiSessionRequired(linkGroupSyncable)).Methods("POST")
+		api.ApiSessionRequired(idempotent(linkGroupSyncable))).Methods("POST")
+
+	// POST /api/v4/teams/:team_id/channels/name/:channel_name/groups/:group_id/link
+	api.BaseRoutes.ChannelByName.Handle("/groups/{group_id:[A-Za-z0-9]+}/link",
+		api.ApiSessionRequired(idempotent(linkGroupChannelByName))).Methods("POST")
+
+	// DELETE /api/v4/groups/:group_id/channels/link
+	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/channels/link",
+		api.ApiSessionRequired(unlinkGroupChannels)).Methods("DELETE")
 
 	// DELETE /api/v4/groups/:group_id/teams/:team_id/link
 	// DELETE /api/v4/groups/:group_id/channels/:channel_id/link
@@ -42,91 +122,199 @@ func (api *API) InitGroup() {
 	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/{syncable_type:teams|channels}/{syncable_id:[A-Za-z0-9]+}",
 		api.ApiSessionRequired(getGroupSyncable)).Methods("GET")
 
+	// GET /api/v4/groups/:group_id/teams/name/:team_name
+	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/teams/name/{team_name:[A-Za-z0-9_-]+}",
+		api.ApiSessionRequired(getGroupTeamByName)).Methods("GET")
+
+	// GET /api/v4/groups/:group_id/teams/name/:team_name/channels/name/:channel_name
+	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/teams/name/{team_name:[A-Za-z0-9_-]+}/channels/name/{channel_name:[A-Za-z0-9_-]+}",
+		api.ApiSessionRequired(getGroupChannelByName)).Methods("GET")
+
 	// GET /api/v4/groups/:group_id/teams
 	// GET /api/v4/groups/:group_id/channels
+	// GET /api/v4/groups/:group_id/channels?expiring_before=1560000000000
 	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/{syncable_type:teams|channels}",
 		api.ApiSessionRequired(getGroupSyncables)).Methods("GET")
 
+	// POST /api/v4/groups/:group_id/channel_patterns
+	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/channel_patterns",
+		api.ApiSessionRequired(createGroupChannelPattern)).Methods("POST")
+
+	// GET /api/v4/groups/:group_id/channel_patterns
+	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/channel_patterns",
+		api.ApiSessionRequired(getGroupChannelPatterns)).Methods("GET")
+
+	// GET /api/v4/groups/:group_id/admin_syncables?page=0&per_page=60
+	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/admin_syncables",
+		api.ApiSessionRequired(groupListPage(getGroupAdminSyncables))).Methods("GET")
+
 	// PUT /api/v4/groups/:group_id/teams/:team_id/patch
 	// PUT /api/v4/groups/:group_id/channels/:channel_id/patch
 	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/{syncable_type:teams|channels}/{syncable_id:[A-Za-z0-9]+}/patch",
 		api.ApiSessionRequired(patchGroupSyncable)).Methods("PUT")
 
+	// PUT /api/v4/groups/:group_id/teams/:team_id
+	// PUT /api/v4/groups/:group_id/channels/:channel_id
+	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/{syncable_type:teams|channels}/{syncable_id:[A-Za-z0-9]+}",
+		api.ApiSessionRequired(updateGroupSyncable)).Methods("PUT")
+
+	// POST /api/v4/groups/:group_id/message
+	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/message",
+		api.ApiSessionRequired(sendGroupMessage)).Methods("POST")
+
+	// POST /api/v4/groups/:group_id/merge/:source_group_id
+	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/merge/{source_group_id:[A-Za-z0-9]+}",
+		api.ApiSessionRequired(mergeGroups)).Methods("POST")
+
+	// GET /api/v4/users/:user_id/groups/:group_id/channels/:channel_id/permissions
+	api.BaseRoutes.User.Handle("/groups/{group_id:[A-Za-z0-9]+}/channels/{channel_id:[A-Za-z0-9]+}/permissions",
+		api.ApiSessionRequired(getGroupChannelAccess)).Methods("GET")
+
+	// GET /api/v4/users/:user_id/groups/provisioned_access
+	api.BaseRoutes.User.Handle("/groups/provisioned_access",
+		api.ApiSessionRequired(getUserGroupProvisionedAccess)).Methods("GET")
+
 	// GET /api/v4/groups/:group_id/members?page=0&per_page=100
+	// GET /api/v4/groups/:group_id/members?expiring_before=1560000000000&page=0&per_page=100
+	// GET /api/v4/groups/:group_id/members?include_nested=true&page=0&per_page=100
+	// GET /api/v4/groups/:group_id/members?include_source=true&page=0&per_page=100
+	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/members",
+		api.ApiSessionRequired(groupListPage(getGroupMembers))).Methods("GET")
+
+	// POST /api/v4/groups/:group_id/members
+	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/members",
+		api.ApiSessionRequired(idempotent(addGroupMembers))).Methods("POST")
+
+	// DELETE /api/v4/groups/:group_id/members
 	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/members",
-		api.ApiSessionRequired(getGroupMembers)).Methods("GET")
+		api.ApiSessionRequired(deleteGroupMembers)).Methods("DELETE")
+
+	// DELETE /api/v4/groups/:group_id/members/all?force=true
+	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/members/all",
+		api.ApiSessionRequired(clearGroupMembers)).Methods("DELETE")
+
+	// POST /api/v4/groups/:group_id/members/check
+	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/members/check",
+		api.ApiSessionRequired(checkGroupMembers)).Methods("POST")
+
+	// GET /api/v4/groups/:group_id/members/history?from=0&to=1560000000000&page=0&per_page=100
+	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/members/history",
+		api.ApiSessionRequired(groupListPage(getGroupMemberHistory))).Methods("GET")
+
+	// GET /api/v4/groups/:group_id/members/timezones
+	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/members/timezones",
+		api.ApiSessionRequired(getGroupMemberTimezones)).Methods("GET")
+
+	// PUT /api/v4/groups/:group_id/members/:user_id
+	api.BaseRoutes.Groups.Handle("/{group_id:[A-Za-z0-9]+}/members/{user_id:[A-Za-z0-9]+}",
+		api.ApiSessionRequired(patchGroupMember)).Methods("PUT")
 
 	// GET /api/v4/channels/:channel_id/groups?page=0&per_page=100
 	api.BaseRoutes.Channels.Handle("/{channel_id:[A-Za-z0-9]+}/groups",
-		api.ApiSessionRequired(getGroupsByChannel)).Methods("GET")
+		api.ApiSessionRequired(groupListPage(getGroupsByChannel))).Methods("GET")
+
+	// POST /api/v4/channels/:channel_id/groups/promote_to_team?remove_channel_links=false
+	api.BaseRoutes.Channels.Handle("/{channel_id:[A-Za-z0-9]+}/groups/promote_to_team",
+		api.ApiSessionRequired(promoteChannelGroupsToTeam)).Methods("POST")
+
+	// GET /api/v4/channels/:channel_id/groups/missing_team_link
+	api.BaseRoutes.Channels.Handle("/{channel_id:[A-Za-z0-9]+}/groups/missing_team_link",
+		api.ApiSessionRequired(getChannelGroupsMissingTeamLink)).Methods("GET")
+
+	// POST /api/v4/channels/:channel_id/members/:user_id/group_exemption
+	api.BaseRoutes.ChannelMember.Handle("/group_exemption",
+		api.ApiSessionRequired(exemptChannelMemberFromGroupConstraint)).Methods("POST")
+
+	// GET /api/v4/channels/:channel_id/groups/:group_id/members?page=0&per_page=100
+	api.BaseRoutes.Channels.Handle("/{channel_id:[A-Za-z0-9]+}/groups/{group_id:[A-Za-z0-9]+}/members",
+		api.ApiSessionRequired(groupListPage(getChannelGroupMembers))).Methods("GET")
+
+	// GET /api/v4/channels/:channel_id/groups/:group_id/mention_count
+	api.BaseRoutes.Channels.Handle("/{channel_id:[A-Za-z0-9]+}/groups/{group_id:[A-Za-z0-9]+}/mention_count",
+		api.ApiSessionRequired(getGroupMentionCount)).Methods("GET")
+
+	// GET /api/v4/channels/:channel_id/groups/conflicts
+	api.BaseRoutes.Channels.Handle("/{channel_id:[A-Za-z0-9]+}/groups/conflicts",
+		api.ApiSessionRequired(getChannelGroupMembershipConflicts)).Methods("GET")
 
 	// GET /api/v4/teams/:team_id/groups?page=0&per_page=100
 	api.BaseRoutes.Teams.Handle("/{team_id:[A-Za-z0-9]+}/groups",
-		api.ApiSessionRequired(getGroupsByTeam)).Methods("GET")
+		api.ApiSessionRequired(groupListPage(getGroupsByTeam))).Methods("GET")
+
+	// GET /api/v4/teams/:team_id/group_syncables?page=0&per_page=100
+	api.BaseRoutes.Teams.Handle("/{team_id:[A-Za-z0-9]+}/group_syncables",
+		api.ApiSessionRequired(groupListPage(getTeamGroupSyncables))).Methods("GET")
 }
 
-func getGroup(c *Context, w http.ResponseWriter, r *http.Request) {
-	c.RequireGroupId()
-	if c.Err != nil {
+// The handlers that create, patch and link groups answer 400 Bad Request when the body cannot be read, e.g. because it
+// is not JSON or a field has the wrong type, and 422 Unprocessable Entity when the body was read but its values break a
+// rule: a field that is missing, too long or not allowed, a reserved reference name, a parent that would make a
+// cycle, a membership webhook URL that is not HTTPS, a link expiring in the past or with roles outside the channel's
+// scheme, a link that both adds and excludes members, or a link of a referenceable group whose name cannot be
+// mentioned. A client can retry the latter once the values are fixed.
+func setUnprocessableGroupError(c *Context, err *model.AppError) {
+	if err.StatusCode == http.StatusBadRequest {
+		err.StatusCode = http.StatusUnprocessableEntity
+	}
+	c.Err = err
+}
+
+func createGroup(c *Context, w http.ResponseWriter, r *http.Request) {
+	var group *model.Group
+	if err := json.NewDecoder(r.Body).Decode(&group); err != nil || group == nil {
+		c.SetInvalidParam("group")
 		return
 	}
 
-	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
-		c.Err = model.NewAppError("Api4.getGroup", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+	// LDAP groups are created by linking them to their LDAP group. A SAML group is created with the value of the SAML
+	// group attribute that grants its membership as its remote id.
+	if group.Source != model.GroupSourceCustom && group.Source != model.GroupSourceSaml {
+		c.SetUnprocessableParam("source")
 		return
 	}
 
-	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
-		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+	if group.Source == model.GroupSourceSaml && len(group.RemoteId) == 0 {
+		c.SetUnprocessableParam("remote_id")
 		return
 	}
 
-	group, err := c.App.GetGroup(c.Params.GroupId)
-	if err != nil {
-		c.Err = err
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.createGroup", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
 		return
 	}
 
-	b, marshalErr := json.Marshal(group)
-	if marshalErr != nil {
-		c.Err = model.NewAppError("Api4.getGroup", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
 		return
 	}
 
-	w.Write(b)
-}
-
-func patchGroup(c *Context, w http.ResponseWriter, r *http.Request) {
-	c.RequireGroupId()
-	if c.Err != nil {
+	if err := c.App.ValidateGroupMembershipWebhookURL(group.MembershipWebhookURL); err != nil {
+		setUnprocessableGroupError(c, err)
 		return
 	}
 
-	groupPatch := model.GroupPatchFromJson(r.Body)
-	if groupPatch == nil {
-		c.SetInvalidParam("group")
+	if err := c.App.ValidateGroupReferenceName(group); err != nil {
+		setUnprocessableGroupError(c, err)
 		return
 	}
 
-	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
-		c.Err = model.NewAppError("Api4.patchGroup", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+	if err := c.App.ValidateGroupOwner(group); err != nil {
+		setUnprocessableGroupError(c, err)
 		return
 	}
 
-	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
-		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
-		return
+	// Custom groups have no remote counterpart, but the remote id must still be unique per source.
+	if group.Source == model.GroupSourceCustom {
+		group.RemoteId = model.NewId()
 	}
+	group.IsDefault = false
 
-	group, err := c.App.GetGroup(c.Params.GroupId)
-	if err != nil {
-		c.Err = err
+	if validationErr := group.Validate(); validationErr != nil {
+		setUnprocessableGroupError(c, validationErr.ToAppError("Api4.createGroup"))
 		return
 	}
 
-	group.Patch(groupPatch)
-
-	group, err = c.App.UpdateGroup(group)
+	group, err := c.App.CreateGroup(group)
 	if err != nil {
 		c.Err = err
 		return
@@ -134,46 +322,22 @@ func patchGroup(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	b, marshalErr := json.Marshal(group)
 	if marshalErr != nil {
-		c.Err = model.NewAppError("Api4.patchGroup", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		c.Err = model.NewAppError("Api4.createGroup", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	w.WriteHeader(http.StatusCreated)
 	w.Write(b)
 }
 
-func linkGroupSyncable(c *Context, w http.ResponseWriter, r *http.Request) {
+func getGroup(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequireGroupId()
 	if c.Err != nil {
 		return
 	}
 
-	c.RequireSyncableId()
-	if c.Err != nil {
-		return
-	}
-	syncableID := c.Params.SyncableId
-
-	c.RequireSyncableType()
-	if c.Err != nil {
-		return
-	}
-	syncableType := c.Params.SyncableType
-
-	body, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		c.Err = model.NewAppError("Api4.createGroupSyncable", "api.io_error", nil, err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	var patch *model.GroupSyncablePatch
-	err = json.Unmarshal(body, &patch)
-	if err != nil || patch == nil {
-		c.SetInvalidParam(fmt.Sprintf("Group%s", syncableType.String()))
-		return
-	}
-
 	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
-		c.Err = model.NewAppError("Api4.createGroupSyncable", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		c.Err = model.NewAppError("Api4.getGroup", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
 		return
 	}
 
@@ -182,102 +346,159 @@ func linkGroupSyncable(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	groupSyncable, appErr := c.App.GetGroupSyncable(c.Params.GroupId, syncableID, syncableType)
-	if appErr != nil && appErr.DetailedError != sql.ErrNoRows.Error() {
-		c.Err = appErr
+	group, err := c.App.GetGroup(c.Params.GroupId)
+	if err != nil {
+		c.Err = err
 		return
 	}
 
-	if groupSyncable == nil {
-		groupSyncable = &model.GroupSyncable{
-			GroupId:    c.Params.GroupId,
-			SyncableId: syncableID,
-			Type:       syncableType,
-		}
-		groupSyncable.Patch(patch)
-		groupSyncable, appErr = c.App.CreateGroupSyncable(groupSyncable)
+	if c.Params.IncludeMemberIds {
+		memberIds, hasMoreMembers, appErr := c.App.GetGroupMemberIds(group.Id, model.GroupMemberIdsInlineLimit)
 		if appErr != nil {
 			c.Err = appErr
 			return
 		}
-	} else {
-		groupSyncable.DeleteAt = 0
-		groupSyncable.Patch(patch)
-		groupSyncable, appErr = c.App.UpdateGroupSyncable(groupSyncable)
-		if appErr != nil {
-			c.Err = appErr
+
+		// The group may be shared with the store cache, so the ids are set on a copy.
+		groupWithMemberIds := *group
+		groupWithMemberIds.MemberIds = memberIds
+		groupWithMemberIds.HasMoreMembers = &hasMoreMembers
+		group = &groupWithMemberIds
+	}
+
+	var response interface{} = group
+	if c.Params.IncludeSyncables {
+		response, err = c.App.GetGroupWithSyncables(group, model.GroupSyncablesInlineLimit)
+		if err != nil {
+			c.Err = err
 			return
 		}
 	}
 
-	w.WriteHeader(http.StatusCreated)
-
-	b, marshalErr := json.Marshal(groupSyncable)
+	b, marshalErr := json.Marshal(response)
 	if marshalErr != nil {
-		c.Err = model.NewAppError("Api4.createGroupSyncable", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		c.Err = model.NewAppError("Api4.getGroup", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Write(b)
 }
 
-func getGroupSyncable(c *Context, w http.ResponseWriter, r *http.Request) {
-	c.RequireGroupId()
-	if c.Err != nil {
-		return
+// sessionOwnsGroup reports whether the session's user is the owner of the group. A group that cannot be fetched is
+// treated as not owned, so that its existence is not revealed to those without PERMISSION_MANAGE_SYSTEM.
+func sessionOwnsGroup(c *Context, groupID string) bool {
+	group, err := c.App.GetGroup(groupID)
+	if err != nil {
+		return false
 	}
+	return len(group.OwnerId) > 0 && group.OwnerId == c.App.Session.UserId
+}
 
-	c.RequireSyncableId()
+// sessionCanReadGroup reports whether the session's user may read the group without PERMISSION_MANAGE_SYSTEM, i.e.
+// whether they own it or are one of its members.
+func sessionCanReadGroup(c *Context, groupID string) bool {
+	if sessionOwnsGroup(c, groupID) {
+		return true
+	}
+	members, err := c.App.GetGroupMembers(groupID, []string{c.App.Session.UserId})
+	return err == nil && len(members) > 0
+}
+
+func patchGroup(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId()
 	if c.Err != nil {
 		return
 	}
-	syncableID := c.Params.SyncableId
 
-	c.RequireSyncableType()
-	if c.Err != nil {
+	var groupPatch *model.GroupPatch
+	if err := json.NewDecoder(r.Body).Decode(&groupPatch); err != nil || groupPatch == nil {
+		c.SetInvalidParam("group")
 		return
 	}
-	syncableType := c.Params.SyncableType
 
 	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
-		c.Err = model.NewAppError("Api4.getGroupSyncable", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		c.Err = model.NewAppError("Api4.patchGroup", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
 		return
 	}
 
+	// The group's owner may only change its metadata; everything else is left to system admins.
 	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
-		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		if !groupPatch.IsMetadataOnly() || !sessionOwnsGroup(c, c.Params.GroupId) {
+			c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+			return
+		}
+	}
+
+	if groupPatch.MembershipWebhookURL != nil {
+		if err := c.App.ValidateGroupMembershipWebhookURL(*groupPatch.MembershipWebhookURL); err != nil {
+			setUnprocessableGroupError(c, err)
+			return
+		}
+	}
+
+	group, err := c.App.GetGroup(c.Params.GroupId)
+	if err != nil {
+		c.Err = err
 		return
 	}
 
-	groupSyncable, err := c.App.GetGroupSyncable(c.Params.GroupId, syncableID, syncableType)
+	group.Patch(groupPatch)
+
+	if validationErr := group.Validate(); validationErr != nil {
+		setUnprocessableGroupError(c, validationErr.ToAppError("Api4.patchGroup"))
+		return
+	}
+
+	if groupPatch.ParentGroupId != nil {
+		if err = c.App.ValidateGroupParent(group); err != nil {
+			setUnprocessableGroupError(c, err)
+			return
+		}
+	}
+
+	if groupPatch.Name != nil || groupPatch.AllowReference != nil {
+		if err = c.App.ValidateGroupReferenceName(group); err != nil {
+			setUnprocessableGroupError(c, err)
+			return
+		}
+	}
+
+	if groupPatch.OwnerId != nil {
+		if err = c.App.ValidateGroupOwner(group); err != nil {
+			setUnprocessableGroupError(c, err)
+			return
+		}
+	}
+
+	group, err = c.App.UpdateGroup(group)
 	if err != nil {
 		c.Err = err
 		return
 	}
 
-	b, marshalErr := json.Marshal(groupSyncable)
+	b, marshalErr := json.Marshal(group)
 	if marshalErr != nil {
-		c.Err = model.NewAppError("Api4.getGroupSyncable", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		c.Err = model.NewAppError("Api4.patchGroup", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Write(b)
 }
 
-func getGroupSyncables(c *Context, w http.ResponseWriter, r *http.Request) {
+func rebindGroupRemoteId(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequireGroupId()
 	if c.Err != nil {
 		return
 	}
 
-	c.RequireSyncableType()
-	if c.Err != nil {
+	remoteId := model.MapFromJson(r.Body)["remote_id"]
+	if len(remoteId) == 0 || len(remoteId) > model.GroupRemoteIDMaxLength {
+		c.SetInvalidParam("remote_id")
 		return
 	}
-	syncableType := c.Params.SyncableType
 
 	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
-		c.Err = model.NewAppError("Api4.getGroupSyncables", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		c.Err = model.NewAppError("Api4.rebindGroupRemoteId", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
 		return
 	}
 
@@ -286,22 +507,28 @@ func getGroupSyncables(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	groupSyncables, err := c.App.GetGroupSyncables(c.Params.GroupId, syncableType)
+	group, err := c.App.GetGroup(c.Params.GroupId)
 	if err != nil {
 		c.Err = err
 		return
 	}
 
-	b, marshalErr := json.Marshal(groupSyncables)
+	group, err = c.App.RebindGroupRemoteId(group, remoteId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	b, marshalErr := json.Marshal(group)
 	if marshalErr != nil {
-		c.Err = model.NewAppError("Api4.getGroupSyncables", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		c.Err = model.NewAppError("Api4.rebindGroupRemoteId", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Write(b)
 }
 
-func patchGroupSyncable(c *Context, w http.ResponseWriter, r *http.Request) {
+func linkGroupSyncable(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequireGroupId()
 	if c.Err != nil {
 		return
@@ -321,20 +548,19 @@ func patchGroupSyncable(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		c.Err = model.NewAppError("Api4.patchGroupSyncable", "api.io_error", nil, err.Error(), http.StatusBadRequest)
+		c.Err = model.NewAppError("Api4.createGroupSyncable", "api.io_error", nil, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	var patch *model.GroupSyncablePatch
 	err = json.Unmarshal(body, &patch)
 	if err != nil || patch == nil {
-		c.SetInvalidParam(fmt.Sprintf("Group[%s]Patch", syncableType.String()))
+		c.SetInvalidParam(fmt.Sprintf("Group%s", syncableType.String()))
 		return
 	}
 
 	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
-		c.Err = model.NewAppError("Api4.patchGroupSyncable", "api.ldap_groups.license_error", nil, "",
-			http.StatusNotImplemented)
+		c.Err = model.NewAppError("Api4.createGroupSyncable", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
 		return
 	}
 
@@ -343,49 +569,131 @@ func patchGroupSyncable(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	groupSyncable, appErr := c.App.GetGroupSyncable(c.Params.GroupId, syncableID, syncableType)
-	if appErr != nil {
-		c.Err = appErr
+	createDefaultChannel := patch.CreateDefaultChannel != nil && *patch.CreateDefaultChannel
+	if createDefaultChannel && syncableType != model.GroupSyncableTypeTeam {
+		c.SetUnprocessableParam("create_default_channel")
 		return
 	}
 
-	groupSyncable.Patch(patch)
+	// A link can only be made to expire in the future, zero making it permanent.
+	if patch.ExpiresAt != nil && *patch.ExpiresAt != 0 && *patch.ExpiresAt <= model.GetMillis() {
+		c.SetUnprocessableParam("expires_at")
+		return
+	}
 
-	groupSyncable, appErr = c.App.UpdateGroupSyncable(groupSyncable)
+	group, appErr := c.App.GetGroup(c.Params.GroupId)
 	if appErr != nil {
 		c.Err = appErr
 		return
 	}
 
-	b, marshalErr := json.Marshal(groupSyncable)
-	if marshalErr != nil {
-		c.Err = model.NewAppError("Api4.patchGroupSyncable", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+	// Mentions of a referenceable group are resolved by its name, which would silently fail once the group is linked
+	// if the name cannot be mentioned.
+	if group.AllowReference && !model.IsValidGroupMentionName(group.Name) {
+		c.Err = model.NewAppError("Api4.createGroupSyncable", "api.group.link.reference_requires_name", map[string]interface{}{"Name": group.Name}, "group_id="+group.Id, http.StatusUnprocessableEntity)
 		return
 	}
 
-	w.Write(b)
-}
-
-func unlinkGroupSyncable(c *Context, w http.ResponseWriter, r *http.Request) {
-	c.RequireGroupId()
-	if c.Err != nil {
+	// The syncable id must refer to an existing team or channel, matching the syncable type in the URL.
+	var targetErr *model.AppError
+	switch syncableType {
+	case model.GroupSyncableTypeTeam:
+		_, targetErr = c.App.GetTeam(syncableID)
+	case model.GroupSyncableTypeChannel:
+		_, targetErr = c.App.GetChannel(syncableID)
+	}
+	if targetErr != nil {
+		if targetErr.StatusCode == http.StatusNotFound {
+			c.Err = model.NewAppError("Api4.createGroupSyncable", "api.group.syncable.target_not_found", map[string]interface{}{"SyncableType": syncableType.String()}, "syncable_id="+syncableID, http.StatusNotFound)
+		} else {
+			c.Err = targetErr
+		}
 		return
 	}
 
-	c.RequireSyncableId()
-	if c.Err != nil {
+	groupSyncable, appErr := c.App.GetGroupSyncable(c.Params.GroupId, syncableID, syncableType)
+	if appErr != nil && appErr.Id != "store.sql_group.no_rows" {
+		c.Err = appErr
 		return
 	}
-	syncableID := c.Params.SyncableId
 
-	c.RequireSyncableType()
-	if c.Err != nil {
-		return
+	if groupSyncable == nil {
+		groupSyncable = &model.GroupSyncable{
+			GroupId:    c.Params.GroupId,
+			SyncableId: syncableID,
+			Type:       syncableType,
+		}
+		groupSyncable.Patch(patch)
+		groupSyncable, appErr = c.App.CreateGroupSyncable(groupSyncable)
+		if appErr != nil && appErr.Id == "store.sql_group.syncable_uniqueness_error" {
+			// A concurrent request created the link between the lookup above and the insert.
+			c.Err = model.NewAppError("Api4.createGroupSyncable", "api.group.syncable.already_exists", map[string]interface{}{"SyncableType": syncableType.String()}, appErr.DetailedError, http.StatusConflict)
+			return
+		}
+		if appErr != nil {
+			setUnprocessableGroupError(c, appErr)
+			return
+		}
+	} else {
+		// A restored link does not keep the expiry time it was removed at.
+		if groupSyncable.DeleteAt != 0 {
+			groupSyncable.ExpiresAt = 0
+		}
+		groupSyncable.DeleteAt = 0
+		groupSyncable.Patch(patch)
+		groupSyncable, appErr = c.App.UpdateGroupSyncable(groupSyncable)
+		if appErr != nil {
+			setUnprocessableGroupError(c, appErr)
+			return
+		}
+	}
+
+	if createDefaultChannel {
+		channel, appErr := c.App.LinkGroupDefaultChannel(group, syncableID)
+		if appErr != nil {
+			c.Err = appErr
+			return
+		}
+		groupSyncable.DefaultChannelId = channel.Id
+	}
+
+	// The group's members are added right away rather than at the next group sync, skipping those already in the
+	// channel.
+	if syncableType == model.GroupSyncableTypeChannel && groupSyncable.AutoAdd {
+		added, skippedExisting, appErr := c.App.AddGroupMembersToChannel(groupSyncable)
+		if appErr != nil {
+			c.Err = appErr
+			return
+		}
+		groupSyncable.UsersAdded = model.NewInt(added)
+		groupSyncable.UsersSkippedExisting = model.NewInt(skippedExisting)
+	}
+
+	if c.App.Metrics != nil {
+		c.App.Metrics.IncrementGroupLinkCounter(strings.ToLower(syncableType.String()))
+	}
+
+	w.WriteHeader(http.StatusCreated)
+
+	b, marshalErr := json.Marshal(groupSyncable)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.createGroupSyncable", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+// linkGroupChannelByName links a group to a channel identified by its team and name, for scripts that do not know the
+// channel id. Once the channel is resolved the request is handled exactly like linkGroupSyncable.
+func linkGroupChannelByName(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId().RequireTeamId().RequireChannelName()
+	if c.Err != nil {
+		return
 	}
-	syncableType := c.Params.SyncableType
 
 	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
-		c.Err = model.NewAppError("Api4.unlinkGroupSyncable", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		c.Err = model.NewAppError("Api4.linkGroupChannelByName", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
 		return
 	}
 
@@ -394,23 +702,178 @@ func unlinkGroupSyncable(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err := c.App.DeleteGroupSyncable(c.Params.GroupId, syncableID, syncableType)
+	channel, err := c.App.GetChannelByName(c.Params.ChannelName, c.Params.TeamId, false)
+	if err != nil {
+		if err.StatusCode == http.StatusNotFound {
+			c.Err = model.NewAppError("Api4.linkGroupChannelByName", "api.group.syncable.target_not_found", map[string]interface{}{"SyncableType": model.GroupSyncableTypeChannel.String()}, "team_id="+c.Params.TeamId+", channel_name="+c.Params.ChannelName, http.StatusNotFound)
+		} else {
+			c.Err = err
+		}
+		return
+	}
+
+	c.Params.SyncableId = channel.Id
+	c.Params.SyncableType = model.GroupSyncableTypeChannel
+
+	linkGroupSyncable(c, w, r)
+}
+
+func unlinkGroupChannels(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	var body struct {
+		ChannelIds []string `json:"channel_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.ChannelIds) == 0 {
+		c.SetInvalidParam("channel_ids")
+		return
+	}
+
+	for _, channelId := range body.ChannelIds {
+		if !model.IsValidId(channelId) {
+			c.SetInvalidParam("channel_ids")
+			return
+		}
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.unlinkGroupChannels", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	statuses := make(map[string]*model.GroupSyncableStatus, len(body.ChannelIds))
+	var manageableIds []string
+
+	// The permissions are checked before the group or any channel is looked up, so that a channel whose members the
+	// caller cannot manage is reported as forbidden whether or not it exists.
+	asAdmin := c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM)
+	for _, channelId := range body.ChannelIds {
+		if _, ok := statuses[channelId]; ok {
+			continue
+		}
+
+		if !asAdmin &&
+			!c.App.SessionHasPermissionToChannel(c.App.Session, channelId, model.PERMISSION_MANAGE_PUBLIC_CHANNEL_MEMBERS) &&
+			!c.App.SessionHasPermissionToChannel(c.App.Session, channelId, model.PERMISSION_MANAGE_PRIVATE_CHANNEL_MEMBERS) {
+			statuses[channelId] = &model.GroupSyncableStatus{SyncableId: channelId, Status: model.GroupSyncableStatusForbidden}
+			continue
+		}
+
+		statuses[channelId] = nil
+		manageableIds = append(manageableIds, channelId)
+	}
+
+	if len(manageableIds) > 0 {
+		if _, err := c.App.GetGroup(c.Params.GroupId); err != nil {
+			c.Err = err
+			return
+		}
+	}
+
+	var unlinkIds []string
+
+	for _, channelId := range manageableIds {
+		channel, err := c.App.GetChannel(channelId)
+		if err != nil {
+			if err.StatusCode == http.StatusNotFound {
+				statuses[channelId] = &model.GroupSyncableStatus{SyncableId: channelId, Status: model.GroupSyncableStatusNotFound}
+			} else {
+				statuses[channelId] = &model.GroupSyncableStatus{SyncableId: channelId, Status: model.GroupSyncableStatusError, Error: err}
+			}
+			continue
+		}
+
+		permission := model.PERMISSION_MANAGE_PUBLIC_CHANNEL_MEMBERS
+		if channel.Type == model.CHANNEL_PRIVATE {
+			permission = model.PERMISSION_MANAGE_PRIVATE_CHANNEL_MEMBERS
+		}
+		if !c.App.SessionHasPermissionToChannel(c.App.Session, channelId, permission) {
+			statuses[channelId] = &model.GroupSyncableStatus{SyncableId: channelId, Status: model.GroupSyncableStatusForbidden}
+			continue
+		}
+
+		isLastGroup, err := c.App.IsLastGroupOfConstrainedChannel(c.Params.GroupId, channel)
+		if err != nil {
+			statuses[channelId] = &model.GroupSyncableStatus{SyncableId: channelId, Status: model.GroupSyncableStatusError, Error: err}
+			continue
+		}
+		if isLastGroup {
+			statuses[channelId] = &model.GroupSyncableStatus{SyncableId: channelId, Status: model.GroupSyncableStatusLastGroup}
+			continue
+		}
+
+		statuses[channelId] = nil
+		unlinkIds = append(unlinkIds, channelId)
+	}
+
+	for _, status := range c.App.DeleteGroupSyncables(c.Params.GroupId, unlinkIds, model.GroupSyncableTypeChannel) {
+		statuses[status.SyncableId] = status
+	}
+
+	results := make([]*model.GroupSyncableStatus, 0, len(statuses))
+	for _, channelId := range body.ChannelIds {
+		if status, ok := statuses[channelId]; ok {
+			if status.Error != nil {
+				// Only the error id and message are returned, not the underlying store error.
+				status.Error = model.NewAppError("Api4.unlinkGroupChannels", status.Error.Id, nil, "", status.Error.StatusCode)
+				status.Error.Translate(c.App.T)
+			}
+			results = append(results, status)
+			delete(statuses, channelId)
+		}
+	}
+
+	b, marshalErr := json.Marshal(results)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.unlinkGroupChannels", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+func getGroupDeleteImpact(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getGroupDeleteImpact", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	impact, err := c.App.GroupDeleteImpact(c.Params.GroupId)
 	if err != nil {
 		c.Err = err
 		return
 	}
 
-	ReturnStatusOK(w)
+	b, marshalErr := json.Marshal(impact)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.getGroupDeleteImpact", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
 }
 
-func getGroupMembers(c *Context, w http.ResponseWriter, r *http.Request) {
+func getGroupStats(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequireGroupId()
 	if c.Err != nil {
 		return
 	}
 
 	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
-		c.Err = model.NewAppError("Api4.getGroupMembers", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		c.Err = model.NewAppError("Api4.getGroupStats", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
 		return
 	}
 
@@ -419,35 +882,32 @@ func getGroupMembers(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	members, count, err := c.App.GetGroupMemberUsersPage(c.Params.GroupId, c.Params.Page, c.Params.PerPage)
+	// include_deleted also counts the members that were removed from the group.
+	includeDeleted := r.URL.Query().Get("include_deleted") == "true"
+
+	stats, err := c.App.GetGroupStats(c.Params.GroupId, includeDeleted)
 	if err != nil {
 		c.Err = err
 		return
 	}
 
-	b, marshalErr := json.Marshal(struct {
-		Members []*model.User `json:"members"`
-		Count   int           `json:"total_member_count"`
-	}{
-		Members: members,
-		Count:   count,
-	})
+	b, marshalErr := json.Marshal(stats)
 	if marshalErr != nil {
-		c.Err = model.NewAppError("Api4.getGroupMembers", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		c.Err = model.NewAppError("Api4.getGroupStats", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Write(b)
 }
 
-func getGroupsByChannel(c *Context, w http.ResponseWriter, r *http.Request) {
-	c.RequireChannelId()
+func getGroupChannelAccess(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequireGroupId().RequireChannelId()
 	if c.Err != nil {
 		return
 	}
 
 	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
-		c.Err = model.NewAppError("Api4.getGroupsByChannel", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		c.Err = model.NewAppError("Api4.getGroupChannelAccess", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
 		return
 	}
 
@@ -456,29 +916,74 @@ func getGroupsByChannel(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	groups, err := c.App.GetGroupsByChannel(c.Params.ChannelId, c.Params.Page, c.Params.PerPage)
+	access, err := c.App.GroupDerivedChannelAccess(c.Params.UserId, c.Params.GroupId, c.Params.ChannelId)
 	if err != nil {
 		c.Err = err
 		return
 	}
 
-	b, marshalErr := json.Marshal(groups)
+	b, marshalErr := json.Marshal(access)
 	if marshalErr != nil {
-		c.Err = model.NewAppError("Api4.getGroupsByChannel", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		c.Err = model.NewAppError("Api4.getGroupChannelAccess", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Write(b)
 }
 
-func getGroupsByTeam(c *Context, w http.ResponseWriter, r *http.Request) {
-	c.RequireTeamId()
+// getUserGroupProvisionedAccess previews the teams and channels a user is given by their groups, for an admin to check
+// before turning on group provisioning. Users may preview their own access.
+func getUserGroupProvisionedAccess(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
 	if c.Err != nil {
 		return
 	}
 
 	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
-		c.Err = model.NewAppError("Api4.getGroupsByTeam", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		c.Err = model.NewAppError("Api4.getUserGroupProvisionedAccess", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if c.App.Session.UserId != c.Params.UserId && !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	access, err := c.App.GetUserGroupProvisionedAccess(c.Params.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	b, marshalErr := json.Marshal(access)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.getUserGroupProvisionedAccess", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+func getGroupSyncable(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	c.RequireSyncableId()
+	if c.Err != nil {
+		return
+	}
+	syncableID := c.Params.SyncableId
+
+	c.RequireSyncableType()
+	if c.Err != nil {
+		return
+	}
+	syncableType := c.Params.SyncableType
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getGroupSyncable", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
 		return
 	}
 
@@ -487,15 +992,2200 @@ func getGroupsByTeam(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	groups, err := c.App.GetGroupsByTeam(c.Params.TeamId, c.Params.Page, c.Params.PerPage)
+	groupSyncable, err := c.App.GetGroupSyncable(c.Params.GroupId, syncableID, syncableType)
 	if err != nil {
 		c.Err = err
 		return
 	}
 
-	b, marshalErr := json.Marshal(groups)
+	b, marshalErr := json.Marshal(groupSyncable)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.getGroupSyncable", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+// getGroupTeamByName is getGroupSyncable for a team given by name.
+func getGroupTeamByName(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId().RequireTeamName()
+	if c.Err != nil {
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getGroupTeamByName", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	team, err := c.App.GetTeamByName(c.Params.TeamName)
+	if err != nil {
+		c.Err = model.NewAppError("Api4.getGroupTeamByName", "api.group.syncable.named_target_not_found", map[string]interface{}{"SyncableType": model.GroupSyncableTypeTeam.String()}, "team_name="+c.Params.TeamName+", "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	c.Params.SyncableId = team.Id
+	c.Params.SyncableType = model.GroupSyncableTypeTeam
+
+	getGroupSyncable(c, w, r)
+}
+
+// getGroupChannelByName is getGroupSyncable for a channel given by its name and the name of its team.
+func getGroupChannelByName(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId().RequireTeamName().RequireChannelName()
+	if c.Err != nil {
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getGroupChannelByName", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	channel, err := c.App.GetChannelByNameForTeamName(c.Params.ChannelName, c.Params.TeamName, false)
+	if err != nil {
+		if err.StatusCode == http.StatusNotFound {
+			c.Err = model.NewAppError("Api4.getGroupChannelByName", "api.group.syncable.named_target_not_found", map[string]interface{}{"SyncableType": model.GroupSyncableTypeChannel.String()}, "team_name="+c.Params.TeamName+", channel_name="+c.Params.ChannelName, http.StatusNotFound)
+		} else {
+			c.Err = err
+		}
+		return
+	}
+
+	c.Params.SyncableId = channel.Id
+	c.Params.SyncableType = model.GroupSyncableTypeChannel
+
+	getGroupSyncable(c, w, r)
+}
+
+func getGroupSyncables(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	c.RequireSyncableType()
+	if c.Err != nil {
+		return
+	}
+	syncableType := c.Params.SyncableType
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getGroupSyncables", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	// expiring_before lists only the links that expire before the given time.
+	var expiringBefore int64
+	if val := r.URL.Query().Get("expiring_before"); len(val) > 0 {
+		var parseErr error
+		if expiringBefore, parseErr = strconv.ParseInt(val, 10, 64); parseErr != nil || expiringBefore <= 0 {
+			c.SetInvalidParam("expiring_before")
+			return
+		}
+	}
+
+	if _, err := c.App.GetGroup(c.Params.GroupId); err != nil {
+		c.Err = err
+		return
+	}
+
+	listed := func(groupSyncable *model.GroupSyncable) bool {
+		if expiringBefore > 0 && (groupSyncable.ExpiresAt == 0 || groupSyncable.ExpiresAt >= expiringBefore) {
+			return false
+		}
+		// Links to archived channels are only listed on request, e.g. when cleaning them up.
+		if syncableType == model.GroupSyncableTypeChannel && !c.Params.IncludeArchivedChannels && groupSyncable.ChannelDeleteAt != 0 {
+			return false
+		}
+		return true
+	}
+
+	var response interface{}
+
+	// expand embeds the team or channel each link points to.
+	if r.URL.Query().Get("expand") == "true" {
+		expandedSyncables, err := c.App.GetGroupSyncablesExpanded(c.Params.GroupId, syncableType)
+		if err != nil {
+			c.Err = err
+			return
+		}
+
+		listedSyncables := make([]*model.GroupSyncableExpanded, 0, len(expandedSyncables))
+		for _, expandedSyncable := range expandedSyncables {
+			if listed(expandedSyncable.GroupSyncable) {
+				listedSyncables = append(listedSyncables, expandedSyncable)
+			}
+		}
+		response = listedSyncables
+	} else {
+		groupSyncables, err := c.App.GetGroupSyncables(c.Params.GroupId, syncableType)
+		if err != nil {
+			c.Err = err
+			return
+		}
+
+		listedSyncables := make([]*model.GroupSyncable, 0, len(groupSyncables))
+		for _, groupSyncable := range groupSyncables {
+			if listed(groupSyncable) {
+				listedSyncables = append(listedSyncables, groupSyncable)
+			}
+		}
+		response = listedSyncables
+	}
+
+	b, marshalErr := json.Marshal(response)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.getGroupSyncables", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+// createGroupChannelPattern links the group to every channel whose name matches a glob, both the existing ones and
+// those created later.
+func createGroupChannelPattern(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	pattern := model.GroupChannelPatternFromJson(r.Body)
+	if pattern == nil {
+		c.SetInvalidParam("channel_name_pattern")
+		return
+	}
+	pattern.GroupId = c.Params.GroupId
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.createGroupChannelPattern", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if _, err := c.App.GetGroup(c.Params.GroupId); err != nil {
+		c.Err = err
+		return
+	}
+
+	pattern, err := c.App.CreateGroupChannelPattern(pattern)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	b, marshalErr := json.Marshal(pattern)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.createGroupChannelPattern", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(b)
+}
+
+func getGroupChannelPatterns(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getGroupChannelPatterns", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if _, err := c.App.GetGroup(c.Params.GroupId); err != nil {
+		c.Err = err
+		return
+	}
+
+	patterns, err := c.App.GetGroupChannelPatterns(c.Params.GroupId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	b, marshalErr := json.Marshal(patterns)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.getGroupChannelPatterns", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+// getGroupAdminSyncables lists the teams and channels where the group grants admin rights, across both syncable
+// types.
+func getGroupAdminSyncables(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getGroupAdminSyncables", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if _, err := c.App.GetGroup(c.Params.GroupId); err != nil {
+		c.Err = err
+		return
+	}
+
+	groupSyncables, err := c.App.GetGroupAdminSyncables(c.Params.GroupId, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	b, marshalErr := json.Marshal(groupSyncables)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.getGroupAdminSyncables", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setGroupListPageHeaders(c, w)
+	w.Write(b)
+}
+
+func patchGroupSyncable(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	c.RequireSyncableId()
+	if c.Err != nil {
+		return
+	}
+	syncableID := c.Params.SyncableId
+
+	c.RequireSyncableType()
+	if c.Err != nil {
+		return
+	}
+	syncableType := c.Params.SyncableType
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		c.Err = model.NewAppError("Api4.patchGroupSyncable", "api.io_error", nil, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var patch *model.GroupSyncablePatch
+	err = json.Unmarshal(body, &patch)
+	if err != nil || patch == nil {
+		c.SetInvalidParam(fmt.Sprintf("Group[%s]Patch", syncableType.String()))
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.patchGroupSyncable", "api.ldap_groups.license_error", nil, "",
+			http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	groupSyncable, appErr := c.App.GetGroupSyncable(c.Params.GroupId, syncableID, syncableType)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	groupSyncable.Patch(patch)
+
+	groupSyncable, appErr = c.App.UpdateGroupSyncable(groupSyncable)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	b, marshalErr := json.Marshal(groupSyncable)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.patchGroupSyncable", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+// updateGroupSyncable replaces the settings of an existing group link with the request body. Unlike
+// linkGroupSyncable it never creates or restores a link, so clients can tell an update from a create.
+func updateGroupSyncable(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	c.RequireSyncableId()
+	if c.Err != nil {
+		return
+	}
+	syncableID := c.Params.SyncableId
+
+	c.RequireSyncableType()
+	if c.Err != nil {
+		return
+	}
+	syncableType := c.Params.SyncableType
+
+	var settings *model.GroupSyncable
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil || settings == nil {
+		c.SetInvalidParam(fmt.Sprintf("Group%s", syncableType.String()))
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.updateGroupSyncable", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	groupSyncable, appErr := c.App.GetGroupSyncable(c.Params.GroupId, syncableID, syncableType)
+	if appErr != nil && appErr.Id != "store.sql_group.no_rows" {
+		c.Err = appErr
+		return
+	}
+
+	// An unlinked group is reported the same way as one that was never linked.
+	if groupSyncable == nil || groupSyncable.DeleteAt != 0 {
+		c.Err = model.NewAppError("Api4.updateGroupSyncable", "api.group.syncable.not_linked.app_error", map[string]interface{}{"SyncableType": syncableType.String()}, "group_id="+c.Params.GroupId+", syncable_id="+syncableID, http.StatusNotFound)
+		return
+	}
+
+	if settings.ExpiresAt != 0 && settings.ExpiresAt != groupSyncable.ExpiresAt && settings.ExpiresAt <= model.GetMillis() {
+		c.SetInvalidParam("expires_at")
+		return
+	}
+
+	groupSyncable.ReplaceSettings(settings)
+
+	groupSyncable, appErr = c.App.UpdateGroupSyncable(groupSyncable)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	b, marshalErr := json.Marshal(groupSyncable)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.updateGroupSyncable", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+func unlinkGroupSyncable(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	c.RequireSyncableId()
+	if c.Err != nil {
+		return
+	}
+	syncableID := c.Params.SyncableId
+
+	c.RequireSyncableType()
+	if c.Err != nil {
+		return
+	}
+	syncableType := c.Params.SyncableType
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.unlinkGroupSyncable", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	_, err := c.App.DeleteGroupSyncable(c.Params.GroupId, syncableID, syncableType)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
+
+// getGroupMembers lists the members of the group to system admins and to the group's owner and members, the latter
+// without emails or auth data, see sanitizeGroupMemberUsers.
+func getGroupMembers(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getGroupMembers", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) && !sessionCanReadGroup(c, c.Params.GroupId) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	// expiring_before lists only the members whose membership expires before the given time.
+	var expiringBefore int64
+	if val := r.URL.Query().Get("expiring_before"); len(val) > 0 {
+		var parseErr error
+		if expiringBefore, parseErr = strconv.ParseInt(val, 10, 64); parseErr != nil || expiringBefore <= 0 {
+			c.SetInvalidParam("expiring_before")
+			return
+		}
+	}
+
+	if _, err := c.App.GetGroup(c.Params.GroupId); err != nil {
+		c.Err = err
+		return
+	}
+
+	// include_nested also lists the members of the groups nested beneath the group, each user once.
+	includeNested := r.URL.Query().Get("include_nested") == "true"
+
+	// only_ids lists just the user ids of the group's direct members, for clients that already cache the users.
+	if r.URL.Query().Get("only_ids") == "true" {
+		if includeNested || expiringBefore > 0 {
+			c.SetInvalidParam("only_ids")
+			return
+		}
+
+		userIds, count, err := c.App.GetGroupMemberIdsPage(c.Params.GroupId, c.Params.Page, c.Params.PerPage)
+		if err != nil {
+			c.Err = err
+			return
+		}
+
+		streamGroupList(c, w, "Api4.getGroupMembers", userIds, "member_ids", groupListField{"total_member_count", count})
+		return
+	}
+
+	var members []*model.User
+	var count int
+	var err *model.AppError
+	if includeNested {
+		members, count, err = c.App.GetNestedGroupMemberUsersPage(c.Params.GroupId, c.Params.Page, c.Params.PerPage)
+	} else if expiringBefore > 0 {
+		members, count, err = c.App.GetGroupMemberUsersExpiringPage(c.Params.GroupId, expiringBefore, c.Params.Page, c.Params.PerPage)
+	} else {
+		members, count, err = c.App.GetGroupMemberUsersPage(c.Params.GroupId, c.Params.Page, c.Params.PerPage)
+	}
+	if err != nil {
+		c.Err = err
+		return
+	}
+	sanitizeGroupMemberUsers(c, members)
+
+	userIds := make([]string, 0, len(members))
+	for _, member := range members {
+		userIds = append(userIds, member.Id)
+	}
+
+	groupMembers, err := c.App.GetGroupMembers(c.Params.GroupId, userIds)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	// The in-group roles, membership expiry times and join sources of the listed members, keyed by user id.
+	roles := make(map[string]string, len(groupMembers))
+	expiresAt := make(map[string]int64)
+	sources := make(map[string]string, len(groupMembers))
+	for _, groupMember := range groupMembers {
+		roles[groupMember.UserId] = groupMember.Roles
+		if groupMember.ExpiresAt > 0 {
+			expiresAt[groupMember.UserId] = groupMember.ExpiresAt
+		}
+		sources[groupMember.UserId] = groupMember.Source
+	}
+
+	fields := []groupListField{{"total_member_count", count}, {"roles", roles}}
+	if len(expiresAt) > 0 {
+		fields = append(fields, groupListField{"expires_at", expiresAt})
+	}
+
+	// include_source adds how each direct member joined the group, for auditing groups with members from several
+	// sources.
+	if r.URL.Query().Get("include_source") == "true" {
+		fields = append(fields, groupListField{"sources", sources})
+	}
+
+	streamGroupList(c, w, "Api4.getGroupMembers", members, "members", fields...)
+}
+
+// sanitizeGroupMemberUsers strips the private fields of listed group members, keeping emails and auth data only for
+// callers with PERMISSION_MANAGE_SYSTEM so that the list is safe to return to roles that may only read groups. Unlike
+// other user lists, emails are hidden from those callers even when PrivacySettings.ShowEmailAddress is on.
+func sanitizeGroupMemberUsers(c *Context, users []*model.User) {
+	asAdmin := c.IsSystemAdmin()
+	options := c.App.GetSanitizeOptions(asAdmin)
+	if !asAdmin {
+		options["email"] = false
+		options["authservice"] = false
+	}
+	for _, user := range users {
+		user.SanitizeProfile(options)
+	}
+}
+
+func patchGroupMember(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId().RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	patch := model.GroupMemberPatchFromJson(r.Body)
+	if patch == nil {
+		c.SetInvalidParam("group_member")
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.patchGroupMember", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) && !sessionOwnsGroup(c, c.Params.GroupId) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if _, err := c.App.GetGroup(c.Params.GroupId); err != nil {
+		c.Err = err
+		return
+	}
+
+	member, err := c.App.GetGroupMember(c.Params.GroupId, c.Params.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	member.Patch(patch)
+
+	member, err = c.App.UpdateGroupMember(member)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	b, marshalErr := json.Marshal(member)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.patchGroupMember", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+func addGroupMembers(c *Context, w http.ResponseWriter, r *http.Request) {
+	userIds, expiresAt := requireCustomGroupMembersChange(c, r, groupMemberActionCreate)
+	if c.Err != nil {
+		return
+	}
+
+	result, err := c.App.UpsertGroupMembers(c.Params.GroupId, userIds)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if err = c.App.SetGroupMemberExpiries(c.Params.GroupId, expiresAt); err != nil {
+		c.Err = err
+		return
+	}
+
+	b, marshalErr := json.Marshal(result)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.addGroupMembers", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+func deleteGroupMembers(c *Context, w http.ResponseWriter, r *http.Request) {
+	userIds, _ := requireCustomGroupMembersChange(c, r, groupMemberActionDelete)
+	if c.Err != nil {
+		return
+	}
+
+	members, err := c.App.DeleteGroupMembers(c.Params.GroupId, userIds)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	b, marshalErr := json.Marshal(members)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.deleteGroupMembers", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+// clearGroupMembers removes every member of a custom group without deleting the group. Members of group-constrained
+// teams and channels linked to the group stand to lose access, so for such a group the caller has to confirm with
+// force=true.
+func clearGroupMembers(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.clearGroupMembers", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) && !sessionOwnsGroup(c, c.Params.GroupId) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	group, err := c.App.GetGroup(c.Params.GroupId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if group.Source != model.GroupSourceCustom {
+		c.Err = model.NewAppError("Api4.clearGroupMembers", "api.group.members.not_custom.app_error", nil, "", http.StatusBadRequest)
+		return
+	}
+
+	if r.URL.Query().Get("force") != "true" {
+		impact, err := c.App.GroupDeleteImpact(group.Id)
+		if err != nil {
+			c.Err = err
+			return
+		}
+
+		if impact.ConstrainedTeamCount > 0 || impact.ConstrainedChannelCount > 0 {
+			params := map[string]interface{}{"TeamCount": impact.ConstrainedTeamCount, "ChannelCount": impact.ConstrainedChannelCount}
+			c.Err = model.NewAppError("Api4.clearGroupMembers", "api.group.members.clear.constrained.app_error", params, "", http.StatusConflict)
+			return
+		}
+	}
+
+	count, err := c.App.ClearGroupMembers(group.Id)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write((&model.GroupMembersClearResult{RemovedCount: count}).ToJson())
+}
+
+// checkGroupMembers reports which of the given users are members of the group. It only reads, so unlike adding and
+// removing members it works for groups of any source.
+func checkGroupMembers(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	var body struct {
+		UserIds []string `json:"user_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.UserIds) == 0 {
+		c.SetInvalidParam("user_ids")
+		return
+	}
+
+	if max := *c.App.Config().GroupSettings.MaxMembersPerRequest; len(body.UserIds) > max {
+		c.Err = model.NewAppError("Api4.checkGroupMembers", "api.group.member.batch_too_large", map[string]interface{}{"Max": max}, "", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	for _, userId := range body.UserIds {
+		if !model.IsValidId(userId) {
+			c.SetInvalidParam("user_ids")
+			return
+		}
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.checkGroupMembers", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if _, err := c.App.GetGroup(c.Params.GroupId); err != nil {
+		c.Err = err
+		return
+	}
+
+	result, err := c.App.FilterGroupMembers(c.Params.GroupId, body.UserIds)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	b, marshalErr := json.Marshal(result)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.checkGroupMembers", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+// getGroupMemberHistory lists the members added to and removed from a group, oldest first, so that auditors can
+// reconstruct its members at any point in time. from and to are optional bounds in milliseconds.
+func getGroupMemberHistory(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	query := r.URL.Query()
+
+	var from, to int64
+	if val := query.Get("from"); len(val) > 0 {
+		var err error
+		if from, err = strconv.ParseInt(val, 10, 64); err != nil || from < 0 {
+			c.SetInvalidParam("from")
+			return
+		}
+	}
+
+	if val := query.Get("to"); len(val) > 0 {
+		var err error
+		if to, err = strconv.ParseInt(val, 10, 64); err != nil || to <= 0 || to < from {
+			c.SetInvalidParam("to")
+			return
+		}
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getGroupMemberHistory", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if _, err := c.App.GetGroup(c.Params.GroupId); err != nil {
+		c.Err = err
+		return
+	}
+
+	events, err := c.App.GetGroupMemberHistory(c.Params.GroupId, from, to, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	b, marshalErr := json.Marshal(events)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.getGroupMemberHistory", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setGroupListPageHeaders(c, w)
+	w.Write(b)
+}
+
+// getGroupMemberTimezones returns the number of group members in each timezone, for scheduling across the group.
+func getGroupMemberTimezones(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getGroupMemberTimezones", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if _, err := c.App.GetGroup(c.Params.GroupId); err != nil {
+		c.Err = err
+		return
+	}
+
+	distribution, err := c.App.GetGroupMemberTimezoneDistribution(c.Params.GroupId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	b, marshalErr := json.Marshal(distribution)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.getGroupMemberTimezones", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+// requireCustomGroupMembersChange reads the user ids of a request adding or removing group members and checks that
+// the caller may change the members of the group. The members of LDAP groups are managed by the LDAP sync and
+// cannot be changed through the API. Members being added may also be given an expiry time, keyed by user id.
+func requireCustomGroupMembersChange(c *Context, r *http.Request, action int) ([]string, map[string]int64) {
+	where := "Api4.addGroupMembers"
+	if action == groupMemberActionDelete {
+		where = "Api4.deleteGroupMembers"
+	}
+
+	c.RequireGroupId()
+	if c.Err != nil {
+		return nil, nil
+	}
+
+	var body struct {
+		UserIds   []string         `json:"user_ids"`
+		ExpiresAt map[string]int64 `json:"expires_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		c.SetInvalidParam("user_ids")
+		return nil, nil
+	}
+
+	// A missing or empty list is most likely a client bug, so it is reported rather than treated as nothing to do.
+	if len(body.UserIds) == 0 {
+		c.Err = model.NewAppError(where, "api.group.member.empty_ids", nil, "", http.StatusBadRequest)
+		return nil, nil
+	}
+
+	for _, userId := range body.UserIds {
+		if !model.IsValidId(userId) {
+			c.SetInvalidParam("user_ids")
+			return nil, nil
+		}
+	}
+
+	// Listing a user more than once changes nothing, so duplicates are dropped before the batch is checked and applied.
+	body.UserIds = model.RemoveDuplicateStrings(body.UserIds)
+
+	if len(body.ExpiresAt) > 0 {
+		requested := make(map[string]bool, len(body.UserIds))
+		for _, userId := range body.UserIds {
+			requested[userId] = true
+		}
+
+		now := model.GetMillis()
+		for userId, expiresAt := range body.ExpiresAt {
+			if action != groupMemberActionCreate || !requested[userId] || expiresAt <= now {
+				c.SetInvalidParam("expires_at")
+				return nil, nil
+			}
+		}
+	}
+
+	if max := *c.App.Config().GroupSettings.MaxMembersPerRequest; len(body.UserIds) > max {
+		c.Err = model.NewAppError(where, "api.group.member.batch_too_large", map[string]interface{}{"Max": max}, "", http.StatusRequestEntityTooLarge)
+		return nil, nil
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError(where, "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return nil, nil
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) && !sessionOwnsGroup(c, c.Params.GroupId) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return nil, nil
+	}
+
+	group, err := c.App.GetGroup(c.Params.GroupId)
+	if err != nil {
+		c.Err = err
+		return nil, nil
+	}
+
+	if group.Source != model.GroupSourceCustom {
+		c.Err = model.NewAppError(where, "api.group.members.not_custom.app_error", nil, "", http.StatusBadRequest)
+		return nil, nil
+	}
+
+	return body.UserIds, body.ExpiresAt
+}
+
+func transferGroupMemberships(c *Context, w http.ResponseWriter, r *http.Request) {
+	transfer := model.GroupMembershipTransferFromJson(r.Body)
+	if transfer == nil {
+		c.SetInvalidParam("transfer")
+		return
+	}
+
+	if !model.IsValidId(transfer.FromUserId) {
+		c.SetInvalidParam("from_user_id")
+		return
+	}
+
+	if !model.IsValidId(transfer.ToUserId) {
+		c.SetInvalidParam("to_user_id")
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.transferGroupMemberships", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	groupIds, err := c.App.TransferGroupMemberships(transfer.FromUserId, transfer.ToUserId, transfer.RemoveSource)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.ArrayToJson(groupIds)))
+}
+
+// getUnionGroupMembers lists the distinct users who belong to any of the given groups, e.g. to reach everyone in several
+// groups with one broadcast.
+func getUnionGroupMembers(c *Context, w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		GroupIds []string `json:"group_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.GroupIds) == 0 {
+		c.SetInvalidParam("group_ids")
+		return
+	}
+
+	groupIds := model.RemoveDuplicateStrings(body.GroupIds)
+	if len(groupIds) > model.GroupMembersUnionMaxCount {
+		c.Err = model.NewAppError("Api4.getUnionGroupMembers", "api.group.ids.batch_too_large", map[string]interface{}{"Max": model.GroupMembersUnionMaxCount}, "", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	for _, groupId := range groupIds {
+		if !model.IsValidId(groupId) {
+			c.SetInvalidParam("group_ids")
+			return
+		}
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getUnionGroupMembers", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	members, count, err := c.App.GetUnionGroupMembers(groupIds, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+	sanitizeGroupMemberUsers(c, members)
+
+	streamGroupList(c, w, "Api4.getUnionGroupMembers", members, "members", groupListField{"total_member_count", count})
+}
+
+// getIntersectionGroupMembers lists the users who belong to every one of the given groups, e.g. to find the people who
+// are both on a team and in a rotation.
+func getIntersectionGroupMembers(c *Context, w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		GroupIds []string `json:"group_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.GroupIds) == 0 {
+		c.SetInvalidParam("group_ids")
+		return
+	}
+
+	groupIds := model.RemoveDuplicateStrings(body.GroupIds)
+	if len(groupIds) > model.GroupMembersIntersectionMaxCount {
+		c.Err = model.NewAppError("Api4.getIntersectionGroupMembers", "api.group.ids.batch_too_large", map[string]interface{}{"Max": model.GroupMembersIntersectionMaxCount}, "", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	for _, groupId := range groupIds {
+		if !model.IsValidId(groupId) {
+			c.SetInvalidParam("group_ids")
+			return
+		}
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getIntersectionGroupMembers", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	members, count, err := c.App.GetIntersectionGroupMembers(groupIds, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+	sanitizeGroupMemberUsers(c, members)
+
+	streamGroupList(c, w, "Api4.getIntersectionGroupMembers", members, "members", groupListField{"total_member_count", count})
+}
+
+// sendGroupMessage sends a direct message from the caller to each member of the group. Messages are sent in the
+// background, and the job tracking them is returned for the caller to follow.
+func sendGroupMessage(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	groupMessage := model.GroupMessageFromJson(r.Body)
+	if groupMessage == nil || len(strings.TrimSpace(groupMessage.Message)) == 0 || utf8.RuneCountInString(groupMessage.Message) > c.App.MaxPostSize() {
+		c.SetInvalidParam("message")
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.sendGroupMessage", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	job, err := c.App.SendGroupMessage(c.Params.GroupId, c.App.Session.UserId, groupMessage.Message)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	w.Write([]byte(job.ToJson()))
+}
+
+// getUngroupedUsers lists the active users who belong to no group, e.g. to find the accounts a directory sync missed.
+func getUngroupedUsers(c *Context, w http.ResponseWriter, r *http.Request) {
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getUngroupedUsers", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	users, count, err := c.App.GetUngroupedUsers(c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+	sanitizeGroupMemberUsers(c, users)
+
+	streamGroupList(c, w, "Api4.getUngroupedUsers", users, "users", groupListField{"total_count", count})
+}
+
+func mergeGroups(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	c.RequireSourceGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.mergeGroups", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	memberCount, err := c.App.MergeGroups(c.Params.GroupId, c.Params.SourceGroupId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	b, marshalErr := json.Marshal(&model.GroupMergeResult{MemberCount: memberCount})
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.mergeGroups", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+// promoteChannelGroupsToTeam links the groups of a channel to the channel's team, optionally removing the channel
+// links afterwards, so that channel-level group access can be moved up to the team.
+func promoteChannelGroupsToTeam(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	removeChannelLinks := r.URL.Query().Get("remove_channel_links") == "true"
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.promoteChannelGroupsToTeam", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	channel, err := c.App.GetChannel(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	permission := model.PERMISSION_MANAGE_PUBLIC_CHANNEL_MEMBERS
+	if channel.Type == model.CHANNEL_PRIVATE {
+		permission = model.PERMISSION_MANAGE_PRIVATE_CHANNEL_MEMBERS
+	}
+	if !c.App.SessionHasPermissionToChannel(c.App.Session, channel.Id, permission) {
+		c.SetPermissionError(permission)
+		return
+	}
+
+	if !c.App.SessionHasPermissionToTeam(c.App.Session, channel.TeamId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	// The links of a group-constrained channel decide who may stay in it, so they cannot all be removed.
+	if removeChannelLinks && channel.GroupConstrained != nil && *channel.GroupConstrained {
+		c.Err = model.NewAppError("Api4.promoteChannelGroupsToTeam", "api.group.promote_to_team.group_constrained.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+		return
+	}
+
+	result, err := c.App.PromoteChannelGroupsToTeam(channel, removeChannelLinks)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	b, marshalErr := json.Marshal(result)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.promoteChannelGroupsToTeam", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+func getGroupsByChannel(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getGroupsByChannel", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	groups, err := c.App.GetGroupsByChannel(c.Params.ChannelId, c.Params.Page, c.Params.PerPage, model.GroupSearchOpts{IncludeTeamGroups: c.Params.IncludeTeamGroups})
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	writeGroupList(c, w, "Api4.getGroupsByChannel", groups)
+}
+
+// exemptChannelMemberFromGroupConstraint keeps a member of a channel, such as a service account, in the channel when it
+// is group-constrained even if they belong to none of its groups.
+func exemptChannelMemberFromGroupConstraint(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId().RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.exemptChannelMemberFromGroupConstraint", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	channel, err := c.App.GetChannel(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	permission := model.PERMISSION_MANAGE_PUBLIC_CHANNEL_MEMBERS
+	if channel.Type == model.CHANNEL_PRIVATE {
+		permission = model.PERMISSION_MANAGE_PRIVATE_CHANNEL_MEMBERS
+	}
+	if !c.App.SessionHasPermissionToChannel(c.App.Session, channel.Id, permission) {
+		c.SetPermissionError(permission)
+		return
+	}
+
+	exemption, err := c.App.ExemptChannelMemberFromGroupConstraint(channel.Id, c.Params.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	b, marshalErr := json.Marshal(exemption)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.exemptChannelMemberFromGroupConstraint", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(b)
+}
+
+// getChannelGroupsMissingTeamLink lists the groups linked to the channel but not to its team, to help admins find
+// inconsistent links.
+func getChannelGroupsMissingTeamLink(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getChannelGroupsMissingTeamLink", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	channel, err := c.App.GetChannel(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	permission := model.PERMISSION_MANAGE_PUBLIC_CHANNEL_MEMBERS
+	if channel.Type == model.CHANNEL_PRIVATE {
+		permission = model.PERMISSION_MANAGE_PRIVATE_CHANNEL_MEMBERS
+	}
+	if !c.App.SessionHasPermissionToChannel(c.App.Session, channel.Id, permission) {
+		c.SetPermissionError(permission)
+		return
+	}
+
+	groups, err := c.App.GetChannelGroupsMissingTeamLink(channel.Id)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		for _, group := range groups {
+			group.Sanitize()
+		}
+	}
+
+	b, marshalErr := json.Marshal(groups)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.getChannelGroupsMissingTeamLink", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+// getGroupMentionCount returns how many users a mention of the group in the channel would notify, so that clients can
+// warn before a message pings a large number of people.
+func getGroupMentionCount(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId().RequireGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getGroupMentionCount", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionToChannel(c.App.Session, c.Params.ChannelId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	group, err := c.App.GetGroup(c.Params.GroupId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	// Only groups that can be mentioned have a mention count.
+	if !group.AllowReference {
+		c.Err = model.NewAppError("Api4.getGroupMentionCount", "api.group.mention_count.not_referenceable.app_error", nil, "group_id="+group.Id, http.StatusNotFound)
+		return
+	}
+
+	count, err := c.App.GetGroupMentionCount(group.Id, c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write((&model.GroupMentionCount{Count: count}).ToJson())
+}
+
+// getChannelGroupMembers lists the members of a group linked to a channel for those who manage the channel's members,
+// so that channel admins can see who a group gives access to without being system admins.
+func getChannelGroupMembers(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId().RequireGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getChannelGroupMembers", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	channel, err := c.App.GetChannel(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	permission := model.PERMISSION_MANAGE_PUBLIC_CHANNEL_MEMBERS
+	if channel.Type == model.CHANNEL_PRIVATE {
+		permission = model.PERMISSION_MANAGE_PRIVATE_CHANNEL_MEMBERS
+	}
+	if !c.App.SessionHasPermissionToChannel(c.App.Session, channel.Id, permission) {
+		c.SetPermissionError(permission)
+		return
+	}
+
+	// Only the groups linked to the channel are visible this way, whether or not the group itself exists.
+	linked, err := c.App.GroupSyncableExists(c.Params.GroupId, channel.Id, model.GroupSyncableTypeChannel)
+	if err != nil {
+		c.Err = err
+		return
+	}
+	if !linked {
+		c.Err = model.NewAppError("Api4.getChannelGroupMembers", "api.group.channel_members.not_linked.app_error", nil, "group_id="+c.Params.GroupId+" channel_id="+channel.Id, http.StatusNotFound)
+		return
+	}
+
+	if _, err = c.App.GetGroup(c.Params.GroupId); err != nil {
+		c.Err = err
+		return
+	}
+
+	members, count, err := c.App.GetGroupMemberUsersPage(c.Params.GroupId, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+	sanitizeGroupMemberUsers(c, members)
+
+	streamGroupList(c, w, "Api4.getChannelGroupMembers", members, "members", groupListField{"total_member_count", count})
+}
+
+// getChannelGroupMembershipConflicts lists the members of the channel who belong to a group excluded from it, see
+// App.ResolveGroupMembershipConflicts.
+func getChannelGroupMembershipConflicts(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getChannelGroupMembershipConflicts", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	channel, err := c.App.GetChannel(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	permission := model.PERMISSION_MANAGE_PUBLIC_CHANNEL_MEMBERS
+	if channel.Type == model.CHANNEL_PRIVATE {
+		permission = model.PERMISSION_MANAGE_PRIVATE_CHANNEL_MEMBERS
+	}
+	if !c.App.SessionHasPermissionToChannel(c.App.Session, channel.Id, permission) {
+		c.SetPermissionError(permission)
+		return
+	}
+
+	conflicts, err := c.App.ResolveGroupMembershipConflicts(channel.Id)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	b, marshalErr := json.Marshal(conflicts)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.getChannelGroupMembershipConflicts", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+func getGroupsByTeam(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getGroupsByTeam", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionToTeam(c.App.Session, c.Params.TeamId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	opts := model.GroupSearchOpts{
+		FilterAutoAdd:        c.Params.FilterAutoAdd,
+		IncludeChannelGroups: c.Params.IncludeChannelGroups,
+	}
+	groups, err := c.App.GetGroupsByTeam(c.Params.TeamId, c.Params.Page, c.Params.PerPage, opts)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		for _, group := range groups {
+			group.Sanitize()
+		}
+	}
+
+	writeGroupList(c, w, "Api4.getGroupsByTeam", groups)
+}
+
+func getGroups(c *Context, w http.ResponseWriter, r *http.Request) {
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getGroups", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	// manageable_only lists the groups linked to the teams the caller can manage and member_of_me the groups the caller
+	// belongs to, so neither needs further permission.
+	var manageableTeamIds []string
+	if c.Params.ManageableOnly {
+		var err *model.AppError
+		if manageableTeamIds, err = getManageableTeamIds(c); err != nil {
+			c.Err = err
+			return
+		}
+	} else if !c.Params.MemberOfMe && !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if len(c.Params.NotAssociatedToTeam) > 0 && !model.IsValidId(c.Params.NotAssociatedToTeam) {
+		c.SetInvalidParam("not_associated_to_team")
+		return
+	}
+
+	if len(c.Params.NotAssociatedToChannel) > 0 && !model.IsValidId(c.Params.NotAssociatedToChannel) {
+		c.SetInvalidParam("not_associated_to_channel")
+		return
+	}
+
+	if c.Params.FilterParentTeamPermitted != nil && !model.IsValidId(*c.Params.FilterParentTeamPermitted) {
+		c.SetInvalidParam("filter_parent_team_permitted")
+		return
+	}
+
+	if len(c.Params.Tag) > 0 && !model.IsValidGroupTag(c.Params.Tag) {
+		c.SetInvalidParam("tag")
+		return
+	}
+
+	// is_linked and is_configured only apply when listing LDAP groups.
+	if c.Params.IsLinked != nil {
+		c.SetInvalidParam("is_linked")
+		return
+	}
+
+	if c.Params.IsConfigured != nil {
+		c.SetInvalidParam("is_configured")
+		return
+	}
+
+	if !model.IsValidLocale(c.Params.Locale) {
+		c.SetInvalidParam("locale")
+		return
+	}
+
+	// fields limits each group to the given fields, for clients that want to keep the response small.
+	var projection []string
+	if val := r.URL.Query().Get("fields"); len(val) > 0 {
+		var ok bool
+		if projection, ok = parseGroupFields(val); !ok {
+			c.SetInvalidParam("fields")
+			return
+		}
+	}
+
+	// Display names are sorted for the caller's locale unless another one is asked for. The caller is read through the
+	// profile cache so that listing groups does not cost a user lookup each time.
+	locale := c.Params.Locale
+	if len(locale) == 0 {
+		users, err := c.App.GetUsersByIds([]string{c.App.Session.UserId}, false, nil)
+		if err != nil {
+			c.Err = err
+			return
+		}
+		if len(users) > 0 {
+			locale = users[0].Locale
+		}
+	}
+
+	opts := model.GroupSearchOpts{
+		Q:                         c.Params.Q,
+		NotAssociatedToTeam:       c.Params.NotAssociatedToTeam,
+		NotAssociatedToChannel:    c.Params.NotAssociatedToChannel,
+		FilterParentTeamPermitted: c.Params.FilterParentTeamPermitted,
+		Tag:                       c.Params.Tag,
+		ManageableOnly:            c.Params.ManageableOnly,
+		MemberOfMe:                c.Params.MemberOfMe,
+		ExcludeDefault:            c.Params.ExcludeDefault,
+		Locale:                    locale,
+	}
+	if c.Params.ManageableOnly {
+		opts.FilterTeamIds = manageableTeamIds
+	}
+	if c.Params.MemberOfMe {
+		opts.FilterMemberId = c.App.Session.UserId
+	}
+
+	// Linking a group to a team or channel or changing its members does not change the group itself, so conditional
+	// requests are only answered when the list is not filtered by links or members.
+	if ims := r.Header.Get(model.HEADER_IF_MODIFIED_SINCE); len(ims) > 0 && len(opts.NotAssociatedToTeam) == 0 && len(opts.NotAssociatedToChannel) == 0 && opts.FilterParentTeamPermitted == nil && opts.FilterTeamIds == nil && len(opts.FilterMemberId) == 0 {
+		if since, ok := parseModifiedSince(ims, r.URL.Query().Get("since")); ok {
+			lastUpdateAt, err := c.App.GetGroupsLastUpdateAt(opts)
+			if err != nil {
+				c.Err = err
+				return
+			}
+
+			if lastUpdateAt > 0 {
+				w.Header().Set(model.HEADER_LAST_MODIFIED, time.Unix(0, lastUpdateAt*int64(time.Millisecond)).UTC().Format(http.TimeFormat))
+			}
+
+			if lastUpdateAt <= since {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+	}
+
+	groups, err := c.App.GetGroups(c.Params.Page, c.Params.PerPage, opts)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		for _, group := range groups {
+			group.Sanitize()
+		}
+	}
+
+	if projection != nil {
+		projected, marshalErr := projectGroups(groups, projection)
+		if marshalErr != nil {
+			c.Err = model.NewAppError("Api4.getGroups", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		streamGroupList(c, w, "Api4.getGroups", projected, "")
+		return
+	}
+
+	streamGroupList(c, w, "Api4.getGroups", groups, "")
+}
+
+// getManageableTeamIds returns the ids of the teams the session user belongs to and has the manage team permission
+// on. The result is never nil, so that a user who manages no teams is shown no groups.
+func getManageableTeamIds(c *Context) ([]string, *model.AppError) {
+	members, err := c.App.GetTeamMembersForUser(c.App.Session.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	teamIds := []string{}
+	for _, member := range members {
+		if member.DeleteAt == 0 && c.App.SessionHasPermissionToTeam(c.App.Session, member.TeamId, model.PERMISSION_MANAGE_TEAM) {
+			teamIds = append(teamIds, member.TeamId)
+		}
+	}
+
+	return teamIds, nil
+}
+
+// parseModifiedSince returns the time in milliseconds named by an If-Modified-Since header. The header only has
+// second precision, so clients may also pass the exact time as the since query parameter, which then takes
+// precedence. ok is false if neither can be parsed, in which case the request is served unconditionally.
+func parseModifiedSince(header, sinceParam string) (since int64, ok bool) {
+	if since, err := strconv.ParseInt(sinceParam, 10, 64); err == nil && since >= 0 {
+		return since, true
+	}
+
+	t, err := http.ParseTime(header)
+	if err != nil {
+		return 0, false
+	}
+
+	// Anything within the second named by the header is not newer than it.
+	return t.Unix()*1000 + 999, true
+}
+
+// getUnusedGroups lists groups that look abandoned so that they can be reviewed for cleanup: groups older than
+// older_than_days that are not linked to any team or channel and whose members have not changed in that window.
+func getUnusedGroups(c *Context, w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	source := model.GroupSource(query.Get("source"))
+	if len(source) > 0 && source != model.GroupSourceCustom && source != model.GroupSourceLdap && source != model.GroupSourceSaml {
+		c.SetInvalidParam("source")
+		return
+	}
+
+	days := model.GroupUnusedDefaultDays
+	if val := query.Get("older_than_days"); len(val) > 0 {
+		var err error
+		if days, err = strconv.Atoi(val); err != nil || days < 0 {
+			c.SetInvalidParam("older_than_days")
+			return
+		}
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getUnusedGroups", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	opts := model.UnusedGroupSearchOpts{
+		Source: source,
+		Since:  model.GetMillis() - int64(days)*24*60*60*1000,
+	}
+
+	groups, err := c.App.GetUnusedGroups(c.Params.Page, c.Params.PerPage, opts)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	writeGroupList(c, w, "Api4.getUnusedGroups", groups)
+}
+
+// getGroupsByMemberEmailDomain lists the groups with at least one active member whose email address is at the domain,
+// e.g. to audit where the accounts of an outside organization have been granted access.
+func getGroupsByMemberEmailDomain(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireEmailDomain()
+	if c.Err != nil {
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getGroupsByMemberEmailDomain", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	groups, err := c.App.GetGroupsByMemberEmailDomain(c.Params.EmailDomain, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	writeGroupList(c, w, "Api4.getGroupsByMemberEmailDomain", groups)
+}
+
+// getGroupSyncablesReport reports which of a set of teams or channels each of a set of groups is linked to, so that the
+// links between them can be shown as a matrix without a request per group and team or channel.
+func getGroupSyncablesReport(c *Context, w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		GroupIds     []string `json:"group_ids"`
+		SyncableIds  []string `json:"syncable_ids"`
+		SyncableType string   `json:"syncable_type"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		c.SetInvalidParam("body")
+		return
+	}
+
+	syncableType, typeErr := model.GroupSyncableTypeFromString(body.SyncableType)
+	if typeErr != nil {
+		c.SetInvalidParam("syncable_type")
+		return
+	}
+
+	groupIds := model.RemoveDuplicateStrings(body.GroupIds)
+	syncableIds := model.RemoveDuplicateStrings(body.SyncableIds)
+	for _, param := range []string{"group_ids", "syncable_ids"} {
+		ids := groupIds
+		if param == "syncable_ids" {
+			ids = syncableIds
+		}
+
+		if len(ids) == 0 {
+			c.SetInvalidParam(param)
+			return
+		}
+
+		if len(ids) > model.GroupSyncablesReportMaxCount {
+			c.Err = model.NewAppError("Api4.getGroupSyncablesReport", "api.group.syncables_report.too_large", map[string]interface{}{"Max": model.GroupSyncablesReportMaxCount}, param, http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		for _, id := range ids {
+			if !model.IsValidId(id) {
+				c.SetInvalidParam(param)
+				return
+			}
+		}
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getGroupSyncablesReport", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	linked, err := c.App.GetGroupSyncablesMatrix(groupIds, syncableIds, syncableType)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	b, marshalErr := json.Marshal(linked)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.getGroupSyncablesReport", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+// getGroupsByIds resolves a batch of group ids in one request, e.g. to render the group mentions of a page of posts.
+// Users without PERMISSION_MANAGE_SYSTEM only see referenceable groups; any other id is reported as not found.
+func getGroupsByIds(c *Context, w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		GroupIds []string `json:"group_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.GroupIds) == 0 {
+		c.SetInvalidParam("group_ids")
+		return
+	}
+
+	groupIds := model.RemoveDuplicateStrings(body.GroupIds)
+	if len(groupIds) > model.GroupsByIdsMaxCount {
+		c.Err = model.NewAppError("Api4.getGroupsByIds", "api.group.ids.batch_too_large", map[string]interface{}{"Max": model.GroupsByIdsMaxCount}, "", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	for _, groupId := range groupIds {
+		if !model.IsValidId(groupId) {
+			c.SetInvalidParam("group_ids")
+			return
+		}
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getGroupsByIds", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	groups, err := c.App.GetGroupsByIDs(groupIds)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	isAdmin := c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM)
+
+	result := &model.GroupsByIdsResult{Groups: []*model.Group{}, NotFound: []string{}}
+	found := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		if !isAdmin {
+			if !group.AllowReference {
+				continue
+			}
+			group.Sanitize()
+		}
+		found[group.Id] = true
+		result.Groups = append(result.Groups, group)
+	}
+	for _, groupId := range groupIds {
+		if !found[groupId] {
+			result.NotFound = append(result.NotFound, groupId)
+		}
+	}
+
+	b, marshalErr := json.Marshal(result)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.getGroupsByIds", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+// autocompleteGroups matches the start of group names for mention typeahead. Unlike the other group lists it is open to
+// any user who can view the team or read the channel the autocomplete is shown in, and it only returns groups that
+// allow references. The team or channel only decides who may search: the results are not scoped to it and include
+// every referenceable group, linked to it or not.
+func autocompleteGroups(c *Context, w http.ResponseWriter, r *http.Request) {
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.autocompleteGroups", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	teamId := r.URL.Query().Get("team_id")
+	switch {
+	case len(c.Params.ChannelId) > 0:
+		if !model.IsValidId(c.Params.ChannelId) {
+			c.SetInvalidParam("channel_id")
+			return
+		}
+		if !c.App.SessionHasPermissionToChannel(c.App.Session, c.Params.ChannelId, model.PERMISSION_READ_CHANNEL) {
+			c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+			return
+		}
+	case len(teamId) > 0:
+		if !model.IsValidId(teamId) {
+			c.SetInvalidParam("team_id")
+			return
+		}
+		if !c.App.SessionHasPermissionToTeam(c.App.Session, teamId, model.PERMISSION_VIEW_TEAM) {
+			c.SetPermissionError(model.PERMISSION_VIEW_TEAM)
+			return
+		}
+	default:
+		c.SetInvalidParam("team_id")
+		return
+	}
+
+	groups, err := c.App.AutocompleteGroups(r.URL.Query().Get("name"))
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	for _, group := range groups {
+		group.Sanitize()
+	}
+
+	b, marshalErr := json.Marshal(groups)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.autocompleteGroups", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+// groupFieldNames holds the JSON names of the fields of a group, which the fields parameter selects from.
+var groupFieldNames = func() map[string]bool {
+	names := map[string]bool{}
+	groupType := reflect.TypeOf(model.Group{})
+	for i := 0; i < groupType.NumField(); i++ {
+		if name := strings.Split(groupType.Field(i).Tag.Get("json"), ",")[0]; len(name) > 0 && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}()
+
+// parseGroupFields parses a comma separated list of group fields, reporting false if any of them is unknown.
+func parseGroupFields(val string) ([]string, bool) {
+	var fields []string
+	for _, field := range strings.Split(val, ",") {
+		field = strings.TrimSpace(field)
+		if !groupFieldNames[field] {
+			return nil, false
+		}
+		fields = append(fields, field)
+	}
+	return fields, true
+}
+
+// projectGroups returns the groups as JSON objects holding only the given fields. Fields the group leaves out when
+// empty stay left out.
+func projectGroups(groups []*model.Group, fields []string) ([]map[string]json.RawMessage, error) {
+	projected := make([]map[string]json.RawMessage, 0, len(groups))
+	for _, group := range groups {
+		b, err := json.Marshal(group)
+		if err != nil {
+			return nil, err
+		}
+
+		var all map[string]json.RawMessage
+		if err = json.Unmarshal(b, &all); err != nil {
+			return nil, err
+		}
+
+		selected := make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if value, ok := all[field]; ok {
+				selected[field] = value
+			}
+		}
+		projected = append(projected, selected)
+	}
+	return projected, nil
+}
+
+// groupListField is a top-level field written after the list in a streamed group list response.
+type groupListField struct {
+	key   string
+	value interface{}
+}
+
+// groupListStream holds back the punctuation that precedes the next value of a streamed response until the value has
+// been encoded, so that nothing is written for a value that fails to encode. written reports whether anything has
+// reached the response, after which its status can no longer be changed.
+type groupListStream struct {
+	w       io.Writer
+	pending []byte
+	written bool
+}
+
+func (s *groupListStream) Write(p []byte) (int, error) {
+	if len(s.pending) > 0 {
+		if _, err := s.w.Write(s.pending); err != nil {
+			return 0, err
+		}
+		s.pending = nil
+	}
+
+	s.written = true
+	return s.w.Write(p)
+}
+
+// streamGroupList writes the same response as writeGroupList, but encodes the elements of list one at a time straight
+// to the response instead of marshalling it whole, so that a large page is never held in memory twice. The legacy
+// response is list as a bare array when listKey is empty, or an object with list under listKey followed by fields.
+// The envelope keeps only the total_member_count field, which clients need to page through the list.
+//
+// Everything that can fail has to be checked before calling it. If the first value cannot be encoded the usual error
+// is returned, but a failure after that can only be logged and leaves the response truncated.
+func streamGroupList(c *Context, w http.ResponseWriter, where string, list interface{}, listKey string, fields ...groupListField) {
+	setGroupListPageHeaders(c, w)
+
+	if c.Params.Envelope {
+		envelopeFields := []groupListField{{"page", c.Params.Page}, {"per_page", c.Params.PerPage}}
+		for _, field := range fields {
+			if field.key == "total_member_count" {
+				envelopeFields = append(envelopeFields, field)
+			}
+		}
+		listKey = "data"
+		fields = envelopeFields
+	}
+
+	stream := &groupListStream{w: w}
+	encoder := json.NewEncoder(stream)
+
+	fail := func(err error) {
+		if !stream.written {
+			c.Err = model.NewAppError(where, "api.marshal_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		mlog.Error("Failed to stream a group list", mlog.String("where", where), mlog.Err(err))
+	}
+
+	if len(listKey) > 0 {
+		stream.pending = append(stream.pending, `{"`+listKey+`":`...)
+	}
+	stream.pending = append(stream.pending, '[')
+
+	items := reflect.ValueOf(list)
+	for i := 0; i < items.Len(); i++ {
+		if i > 0 {
+			stream.pending = append(stream.pending, ',')
+		}
+		if err := encoder.Encode(items.Index(i).Interface()); err != nil {
+			fail(err)
+			return
+		}
+	}
+	stream.pending = append(stream.pending, ']')
+
+	if len(listKey) > 0 {
+		for _, field := range fields {
+			stream.pending = append(stream.pending, `,"`+field.key+`":`...)
+			if err := encoder.Encode(field.value); err != nil {
+				fail(err)
+				return
+			}
+		}
+		stream.pending = append(stream.pending, '}')
+	}
+
+	if _, err := stream.Write(nil); err != nil {
+		fail(err)
+	}
+}
+
+// getTeamGroupSyncables lists the links of every group to the team. Unlike getGroupsByTeam it lists the links rather
+// than the groups, so that a team admin can see the settings of each link at once.
+func getTeamGroupSyncables(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getTeamGroupSyncables", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionToTeam(c.App.Session, c.Params.TeamId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	if _, err := c.App.GetTeam(c.Params.TeamId); err != nil {
+		c.Err = err
+		return
+	}
+
+	groupSyncables, err := c.App.GetTeamGroupSyncables(c.Params.TeamId, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	b, marshalErr := json.Marshal(groupSyncables)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.getTeamGroupSyncables", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setGroupListPageHeaders(c, w)
+	w.Write(b)
+}
+
+// groupListPage wraps a group list handler so that a zero per_page gets GROUP_LIST_PER_PAGE_DEFAULT, like a missing or
+// negative one, and one above GROUP_LIST_PER_PAGE_MAXIMUM is clamped to it rather than rejected. The handler reports
+// the page size it served with setGroupListPageHeaders.
+func groupListPage(h func(*Context, http.ResponseWriter, *http.Request)) func(*Context, http.ResponseWriter, *http.Request) {
+	return func(c *Context, w http.ResponseWriter, r *http.Request) {
+		c.Params.PerPage = groupListPerPage(c.Params.PerPage)
+		h(c, w, r)
+	}
+}
+
+func groupListPerPage(perPage int) int {
+	if perPage <= 0 {
+		return GROUP_LIST_PER_PAGE_DEFAULT
+	}
+	if perPage > GROUP_LIST_PER_PAGE_MAXIMUM {
+		return GROUP_LIST_PER_PAGE_MAXIMUM
+	}
+	return perPage
+}
+
+// setGroupListPageHeaders reports the page and page size a group list was actually served with, since per_page is
+// defaulted or clamped when it is out of range.
+func setGroupListPageHeaders(c *Context, w http.ResponseWriter) {
+	w.Header().Set(model.HEADER_PAGE, strconv.Itoa(c.Params.Page))
+	w.Header().Set(model.HEADER_PER_PAGE, strconv.Itoa(c.Params.PerPage))
+}
+
+// writeGroupList writes the response of a group list endpoint. By default the list is written as a bare array. With
+// envelope=true it is instead wrapped as {"data": [...], "page": n, "per_page": m}, so that clients can handle every
+// group list the same way.
+func writeGroupList(c *Context, w http.ResponseWriter, where string, list interface{}) {
+	setGroupListPageHeaders(c, w)
+
+	response := list
+	if c.Params.Envelope {
+		response = &model.GroupListEnvelope{
+			Data:    list,
+			Page:    c.Params.Page,
+			PerPage: c.Params.PerPage,
+		}
+	}
+
+	b, marshalErr := json.Marshal(response)
 	if marshalErr != nil {
-		c.Err = model.NewAppError("Api4.getGroupsByTeam", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		c.Err = model.NewAppError(where, "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -4,13 +4,26 @@
 package api4
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
+	"github.com/mattermost/mattermost-server/einterfaces"
+	"github.com/mattermost/mattermost-server/einterfaces/mocks"
 	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+	"github.com/mattermost/mattermost-server/web"
 )
 
 func TestGetGroup(t *testing.T) {
@@ -58,6 +71,88 @@ func TestGetGroup(t *testing.T) {
 	CheckUnauthorizedStatus(t, response)
 }
 
+func TestGetGroupWithSyncables(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		Description: "description_" + id,
+		RemoteId:    model.NewId(),
+	})
+	assert.Nil(t, err)
+
+	_, err = th.App.CreateGroupSyncable(model.NewGroupTeam(g.Id, th.BasicTeam.Id, true))
+	assert.Nil(t, err)
+
+	_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(g.Id, th.BasicChannel.Id, true))
+	assert.Nil(t, err)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response := th.Client.GetGroupWithSyncables(g.Id, "")
+	CheckForbiddenStatus(t, response)
+
+	group, response := th.SystemAdminClient.GetGroupWithSyncables(g.Id, "")
+	CheckOKStatus(t, response)
+	assert.Equal(t, g.Id, group.Id)
+	assert.Equal(t, g.Name, group.Name)
+	require.Len(t, group.Teams, 1)
+	assert.Equal(t, th.BasicTeam.Id, group.Teams[0].SyncableId)
+	assert.False(t, group.TeamsHasMore)
+	require.Len(t, group.Channels, 1)
+	assert.Equal(t, g.Id, group.Channels[0].GroupId)
+	assert.False(t, group.ChannelsHasMore)
+
+	// Without the option the response keeps its usual shape.
+	r, appErr := th.SystemAdminClient.DoApiGet(th.SystemAdminClient.GetGroupRoute(g.Id), "")
+	require.Nil(t, appErr)
+	defer r.Body.Close()
+	var raw map[string]interface{}
+	require.Nil(t, json.NewDecoder(r.Body).Decode(&raw))
+	assert.Equal(t, g.Id, raw["id"])
+	assert.NotContains(t, raw, "teams")
+	assert.NotContains(t, raw, "channels")
+}
+
+func TestGetGroupWithMemberIds(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, err)
+
+	_, err = th.App.UpsertGroupMembers(g.Id, []string{th.BasicUser.Id, th.BasicUser2.Id})
+	require.Nil(t, err)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response := th.Client.GetGroupWithMemberIds(g.Id, "")
+	CheckForbiddenStatus(t, response)
+
+	group, response := th.SystemAdminClient.GetGroupWithMemberIds(g.Id, "")
+	CheckOKStatus(t, response)
+	assert.Equal(t, g.Id, group.Id)
+	assert.ElementsMatch(t, []string{th.BasicUser.Id, th.BasicUser2.Id}, group.MemberIds)
+	require.NotNil(t, group.HasMoreMembers)
+	assert.False(t, *group.HasMoreMembers)
+
+	// Without the option the response keeps its usual shape.
+	group, response = th.SystemAdminClient.GetGroup(g.Id, "")
+	CheckOKStatus(t, response)
+	assert.Nil(t, group.MemberIds)
+	assert.Nil(t, group.HasMoreMembers)
+}
+
 func TestPatchGroup(t *testing.T) {
 	th := Setup().InitBasic()
 	defer th.TearDown()
@@ -124,71 +219,110 @@ func TestPatchGroup(t *testing.T) {
 	CheckUnauthorizedStatus(t, response)
 }
 
-func TestLinkGroupTeam(t *testing.T) {
+func TestPatchGroupValidation(t *testing.T) {
 	th := Setup().InitBasic()
 	defer th.TearDown()
 
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
 	id := model.NewId()
 	g, err := th.App.CreateGroup(&model.Group{
 		DisplayName: "dn_" + id,
 		Name:        "name" + id,
-		Source:      model.GroupSourceLdap,
-		Description: "description_" + id,
-		RemoteId:    model.NewId(),
+		Source:      model.GroupSourceCustom,
 	})
-	assert.Nil(t, err)
-
-	patch := &model.GroupSyncablePatch{
-		AutoAdd: model.NewBool(true),
-	}
-
-	_, response := th.Client.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
-	CheckNotImplementedStatus(t, response)
+	require.Nil(t, err)
 
-	_, response = th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
-	CheckNotImplementedStatus(t, response)
-
-	th.App.SetLicense(model.NewTestLicense("ldap"))
+	// Both fields are invalid; the first one names the error.
+	_, response := th.SystemAdminClient.PatchGroup(g.Id, &model.GroupPatch{
+		Name:        model.NewString(strings.Repeat("a", model.GroupNameMaxLength+1)),
+		DisplayName: model.NewString(""),
+	})
+	CheckUnprocessableEntityStatus(t, response)
+	assert.Equal(t, "model.group.name.app_error", response.Error.Id)
 
-	groupTeam, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
-	assert.Equal(t, http.StatusCreated, response.StatusCode)
-	assert.NotNil(t, groupTeam)
+	group, err := th.App.GetGroup(g.Id)
+	require.Nil(t, err)
+	assert.Equal(t, g.Name, group.Name)
+	assert.Equal(t, g.DisplayName, group.DisplayName)
 }
 
-func TestLinkGroupChannel(t *testing.T) {
+func TestGroupOwner(t *testing.T) {
 	th := Setup().InitBasic()
 	defer th.TearDown()
 
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
 	id := model.NewId()
 	g, err := th.App.CreateGroup(&model.Group{
 		DisplayName: "dn_" + id,
 		Name:        "name" + id,
-		Source:      model.GroupSourceLdap,
-		Description: "description_" + id,
-		RemoteId:    model.NewId(),
+		Source:      model.GroupSourceCustom,
 	})
-	assert.Nil(t, err)
+	require.Nil(t, err)
 
-	patch := &model.GroupSyncablePatch{
-		AutoAdd: model.NewBool(true),
-	}
+	t.Run("owner must be an active user", func(t *testing.T) {
+		_, response := th.SystemAdminClient.PatchGroup(g.Id, &model.GroupPatch{OwnerId: model.NewString(model.NewId())})
+		CheckUnprocessableEntityStatus(t, response)
+		assert.Equal(t, "api.group.owner_id.invalid.app_error", response.Error.Id)
 
-	_, response := th.Client.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
-	CheckNotImplementedStatus(t, response)
+		inactiveUser := th.CreateUser()
+		_, err = th.App.UpdateActive(inactiveUser, false)
+		require.Nil(t, err)
 
-	_, response = th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
-	CheckNotImplementedStatus(t, response)
+		_, response = th.SystemAdminClient.PatchGroup(g.Id, &model.GroupPatch{OwnerId: model.NewString(inactiveUser.Id)})
+		CheckUnprocessableEntityStatus(t, response)
+		assert.Equal(t, "api.group.owner_id.invalid.app_error", response.Error.Id)
+	})
 
-	th.App.SetLicense(model.NewTestLicense("ldap"))
+	group, response := th.SystemAdminClient.PatchGroup(g.Id, &model.GroupPatch{OwnerId: model.NewString(th.BasicUser.Id)})
+	CheckOKStatus(t, response)
+	require.Equal(t, th.BasicUser.Id, group.OwnerId)
 
-	_, response = th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
-	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	t.Run("owner manages the group", func(t *testing.T) {
+		group, response := th.Client.PatchGroup(g.Id, &model.GroupPatch{DisplayName: model.NewString("owned")})
+		CheckOKStatus(t, response)
+		assert.Equal(t, "owned", group.DisplayName)
+
+		// Settings other than the metadata are left to system admins.
+		_, response = th.Client.PatchGroup(g.Id, &model.GroupPatch{AllowReference: model.NewBool(true)})
+		CheckForbiddenStatus(t, response)
+
+		_, response = th.Client.PatchGroup(g.Id, &model.GroupPatch{OwnerId: model.NewString(th.BasicUser2.Id)})
+		CheckForbiddenStatus(t, response)
+
+		_, response = th.Client.UpsertGroupMembers(g.Id, []string{th.BasicUser2.Id})
+		CheckOKStatus(t, response)
+
+		_, response = th.Client.DeleteGroupMembers(g.Id, []string{th.BasicUser2.Id})
+		CheckOKStatus(t, response)
+	})
+
+	t.Run("non-owner is rejected", func(t *testing.T) {
+		client := th.CreateClient()
+		th.LoginBasic2WithClient(client)
+
+		_, response := client.PatchGroup(g.Id, &model.GroupPatch{DisplayName: model.NewString("not owned")})
+		CheckForbiddenStatus(t, response)
+
+		_, response = client.UpsertGroupMembers(g.Id, []string{th.BasicUser2.Id})
+		CheckForbiddenStatus(t, response)
+
+		_, response = client.DeleteGroupMembers(g.Id, []string{th.BasicUser.Id})
+		CheckForbiddenStatus(t, response)
+
+		// A group that does not exist is not told apart from one owned by someone else.
+		_, response = client.PatchGroup(model.NewId(), &model.GroupPatch{DisplayName: model.NewString("not owned")})
+		CheckForbiddenStatus(t, response)
+	})
 }
 
-func TestUnlinkGroupTeam(t *testing.T) {
+func TestPatchGroupDescription(t *testing.T) {
 	th := Setup().InitBasic()
 	defer th.TearDown()
 
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
 	id := model.NewId()
 	g, err := th.App.CreateGroup(&model.Group{
 		DisplayName: "dn_" + id,
@@ -197,449 +331,634 @@ func TestUnlinkGroupTeam(t *testing.T) {
 		Description: "description_" + id,
 		RemoteId:    model.NewId(),
 	})
-	assert.Nil(t, err)
+	require.Nil(t, err)
 
-	patch := &model.GroupSyncablePatch{
-		AutoAdd: model.NewBool(true),
+	patch := func(body string) *model.Group {
+		r, appErr := th.SystemAdminClient.DoApiPut(th.SystemAdminClient.GetGroupRoute(g.Id)+"/patch", body)
+		require.Nil(t, appErr)
+		defer r.Body.Close()
+		return model.GroupFromJson(r.Body)
 	}
 
-	th.App.SetLicense(model.NewTestLicense("ldap"))
+	// An empty string clears the description
+	group := patch(`{"description": ""}`)
+	assert.Equal(t, "", group.Description)
 
-	_, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
-	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	// A new value sets it
+	group = patch(`{"description": "updated"}`)
+	assert.Equal(t, "updated", group.Description)
 
-	th.App.SetLicense(nil)
+	// Omitting the field leaves it unchanged
+	group = patch(`{"display_name": "dn_updated"}`)
+	assert.Equal(t, "updated", group.Description)
+	assert.Equal(t, "dn_updated", group.DisplayName)
 
-	response = th.Client.UnlinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam)
-	CheckNotImplementedStatus(t, response)
+	// So does null, which is what the client sends for fields it does not set
+	group = patch(`{"description": null}`)
+	assert.Equal(t, "updated", group.Description)
 
-	response = th.SystemAdminClient.UnlinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam)
-	CheckNotImplementedStatus(t, response)
+	group, response := th.SystemAdminClient.PatchGroup(g.Id, &model.GroupPatch{DisplayName: model.NewString("dn_" + id)})
+	CheckOKStatus(t, response)
+	assert.Equal(t, "updated", group.Description)
 
-	th.App.SetLicense(model.NewTestLicense("ldap"))
+	group, response = th.SystemAdminClient.GetGroup(g.Id, "")
+	CheckOKStatus(t, response)
+	assert.Equal(t, "updated", group.Description)
+	assert.False(t, group.DescriptionIsMarkdown)
 
-	response = th.SystemAdminClient.UnlinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam)
+	// The markdown flag is patched on its own and left alone by patches that omit it
+	group = patch(`{"description": "See [the docs](https://example.com/docs)", "description_is_markdown": true}`)
+	assert.True(t, group.DescriptionIsMarkdown)
+
+	group = patch(`{"display_name": "dn_markdown"}`)
+	assert.True(t, group.DescriptionIsMarkdown)
+
+	group, response = th.SystemAdminClient.GetGroup(g.Id, "")
 	CheckOKStatus(t, response)
+	assert.True(t, group.DescriptionIsMarkdown)
+	assert.Equal(t, "See [the docs](https://example.com/docs)", group.Description)
+
+	// The description length limit still applies to markdown descriptions
+	_, response = th.SystemAdminClient.PatchGroup(g.Id, &model.GroupPatch{
+		Description:           model.NewString(strings.Repeat("a", model.GroupDescriptionMaxLength+1)),
+		DescriptionIsMarkdown: model.NewBool(true),
+	})
+	CheckUnprocessableEntityStatus(t, response)
+
+	group, response = th.SystemAdminClient.PatchGroup(g.Id, &model.GroupPatch{DescriptionIsMarkdown: model.NewBool(false)})
+	CheckOKStatus(t, response)
+	assert.False(t, group.DescriptionIsMarkdown)
 }
 
-func TestUnlinkGroupChannel(t *testing.T) {
+func TestGroupHandlersNotFound(t *testing.T) {
 	th := Setup().InitBasic()
 	defer th.TearDown()
 
-	id := model.NewId()
-	g, err := th.App.CreateGroup(&model.Group{
-		DisplayName: "dn_" + id,
-		Name:        "name" + id,
-		Source:      model.GroupSourceLdap,
-		Description: "description_" + id,
-		RemoteId:    model.NewId(),
-	})
-	assert.Nil(t, err)
-
-	patch := &model.GroupSyncablePatch{
-		AutoAdd: model.NewBool(true),
-	}
-
 	th.App.SetLicense(model.NewTestLicense("ldap"))
 
-	_, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
-	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	groupId := model.NewId()
+	client := th.SystemAdminClient
 
-	th.App.SetLicense(nil)
+	_, response := client.GetGroup(groupId, "")
+	CheckNotFoundStatus(t, response)
 
-	response = th.Client.UnlinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel)
-	CheckNotImplementedStatus(t, response)
+	_, response = client.PatchGroup(groupId, &model.GroupPatch{DisplayName: model.NewString("dn")})
+	CheckNotFoundStatus(t, response)
 
-	response = th.SystemAdminClient.UnlinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel)
-	CheckNotImplementedStatus(t, response)
+	_, response = client.LinkGroupSyncable(groupId, th.BasicTeam.Id, model.GroupSyncableTypeTeam, &model.GroupSyncablePatch{AutoAdd: model.NewBool(true)})
+	CheckNotFoundStatus(t, response)
 
-	th.App.SetLicense(model.NewTestLicense("ldap"))
+	_, response = client.GetGroupSyncables(groupId, model.GroupSyncableTypeTeam, "")
+	CheckNotFoundStatus(t, response)
 
-	response = th.SystemAdminClient.UnlinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel)
-	CheckOKStatus(t, response)
+	_, appErr := client.DoApiGet(client.GetGroupRoute(groupId)+"/members", "")
+	require.NotNil(t, appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
 }
 
-func TestGetGroupTeam(t *testing.T) {
+func TestGroupTags(t *testing.T) {
 	th := Setup().InitBasic()
 	defer th.TearDown()
 
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	tag := model.NewId()
 	id := model.NewId()
 	g, err := th.App.CreateGroup(&model.Group{
 		DisplayName: "dn_" + id,
 		Name:        "name" + id,
 		Source:      model.GroupSourceLdap,
-		Description: "description_" + id,
 		RemoteId:    model.NewId(),
 	})
-	assert.Nil(t, err)
-
-	_, response := th.Client.GetGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, "")
-	CheckNotImplementedStatus(t, response)
-
-	_, response = th.SystemAdminClient.GetGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, "")
-	CheckNotImplementedStatus(t, response)
-
-	th.App.SetLicense(model.NewTestLicense("ldap"))
-
-	patch := &model.GroupSyncablePatch{
-		AutoAdd: model.NewBool(true),
-	}
+	require.Nil(t, err)
+	assert.Empty(t, g.Tags)
 
-	_, response = th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
-	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	// Add tags
+	group, response := th.SystemAdminClient.PatchGroup(g.Id, &model.GroupPatch{Tags: &model.StringArray{tag, "project"}})
+	CheckOKStatus(t, response)
+	assert.Equal(t, model.StringArray{tag, "project"}, group.Tags)
 
-	groupSyncable, response := th.SystemAdminClient.GetGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, "")
+	groups, response := th.SystemAdminClient.GetGroups(model.GroupSearchOpts{Tag: tag}, 0, 60)
 	CheckOKStatus(t, response)
-	assert.NotNil(t, groupSyncable)
+	require.Len(t, groups, 1)
+	assert.Equal(t, g.Id, groups[0].Id)
 
-	assert.Equal(t, g.Id, groupSyncable.GroupId)
-	assert.Equal(t, th.BasicTeam.Id, groupSyncable.SyncableId)
-	assert.Equal(t, *patch.AutoAdd, groupSyncable.AutoAdd)
+	// Patching other fields leaves the tags alone
+	group, response = th.SystemAdminClient.PatchGroup(g.Id, &model.GroupPatch{DisplayName: model.NewString("dn_" + model.NewId())})
+	CheckOKStatus(t, response)
+	assert.Equal(t, model.StringArray{tag, "project"}, group.Tags)
 
-	_, response = th.SystemAdminClient.GetGroupSyncable(model.NewId(), th.BasicTeam.Id, model.GroupSyncableTypeTeam, "")
-	CheckNotFoundStatus(t, response)
+	// Remove a tag
+	group, response = th.SystemAdminClient.PatchGroup(g.Id, &model.GroupPatch{Tags: &model.StringArray{"project"}})
+	CheckOKStatus(t, response)
+	assert.Equal(t, model.StringArray{"project"}, group.Tags)
 
-	_, response = th.SystemAdminClient.GetGroupSyncable(g.Id, model.NewId(), model.GroupSyncableTypeTeam, "")
-	CheckNotFoundStatus(t, response)
+	groups, response = th.SystemAdminClient.GetGroups(model.GroupSearchOpts{Tag: tag}, 0, 60)
+	CheckOKStatus(t, response)
+	assert.Empty(t, groups)
 
-	_, response = th.SystemAdminClient.GetGroupSyncable("asdfasdfe3", th.BasicTeam.Id, model.GroupSyncableTypeTeam, "")
-	CheckBadRequestStatus(t, response)
+	// Invalid tags
+	_, response = th.SystemAdminClient.PatchGroup(g.Id, &model.GroupPatch{Tags: &model.StringArray{"Project"}})
+	CheckUnprocessableEntityStatus(t, response)
 
-	_, response = th.SystemAdminClient.GetGroupSyncable(g.Id, "asdfasdfe3", model.GroupSyncableTypeTeam, "")
+	_, response = th.SystemAdminClient.GetGroups(model.GroupSearchOpts{Tag: "Project"}, 0, 60)
 	CheckBadRequestStatus(t, response)
-
-	th.SystemAdminClient.Logout()
-	_, response = th.SystemAdminClient.GetGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, "")
-	CheckUnauthorizedStatus(t, response)
 }
 
-func TestGetGroupChannel(t *testing.T) {
+func TestCreateGroup(t *testing.T) {
 	th := Setup().InitBasic()
 	defer th.TearDown()
 
 	id := model.NewId()
-	g, err := th.App.CreateGroup(&model.Group{
+	group := &model.Group{
 		DisplayName: "dn_" + id,
 		Name:        "name" + id,
-		Source:      model.GroupSourceLdap,
-		Description: "description_" + id,
-		RemoteId:    model.NewId(),
-	})
-	assert.Nil(t, err)
-
-	_, response := th.Client.GetGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, "")
-	CheckNotImplementedStatus(t, response)
+		Source:      model.GroupSourceCustom,
+	}
 
-	_, response = th.SystemAdminClient.GetGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, "")
+	_, response := th.SystemAdminClient.CreateGroup(group)
 	CheckNotImplementedStatus(t, response)
 
 	th.App.SetLicense(model.NewTestLicense("ldap"))
 
-	patch := &model.GroupSyncablePatch{
-		AutoAdd: model.NewBool(true),
-	}
-
-	_, response = th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
-	assert.Equal(t, http.StatusCreated, response.StatusCode)
-
-	groupSyncable, response := th.SystemAdminClient.GetGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, "")
-	CheckOKStatus(t, response)
-	assert.NotNil(t, groupSyncable)
-
-	assert.Equal(t, g.Id, groupSyncable.GroupId)
-	assert.Equal(t, th.BasicChannel.Id, groupSyncable.SyncableId)
-	assert.Equal(t, *patch.AutoAdd, groupSyncable.AutoAdd)
-
-	_, response = th.SystemAdminClient.GetGroupSyncable(model.NewId(), th.BasicChannel.Id, model.GroupSyncableTypeChannel, "")
-	CheckNotFoundStatus(t, response)
+	_, response = th.Client.CreateGroup(group)
+	CheckForbiddenStatus(t, response)
 
-	_, response = th.SystemAdminClient.GetGroupSyncable(g.Id, model.NewId(), model.GroupSyncableTypeChannel, "")
-	CheckNotFoundStatus(t, response)
+	ldapGroup := *group
+	ldapGroup.Source = model.GroupSourceLdap
+	_, response = th.SystemAdminClient.CreateGroup(&ldapGroup)
+	CheckUnprocessableEntityStatus(t, response)
 
-	_, response = th.SystemAdminClient.GetGroupSyncable("asdfasdfe3", th.BasicChannel.Id, model.GroupSyncableTypeChannel, "")
-	CheckBadRequestStatus(t, response)
+	created, response := th.SystemAdminClient.CreateGroup(group)
+	CheckCreatedStatus(t, response)
+	assert.True(t, model.IsValidId(created.Id))
+	assert.Equal(t, group.Name, created.Name)
+	assert.Equal(t, model.GroupSourceCustom, created.Source)
 
-	_, response = th.SystemAdminClient.GetGroupSyncable(g.Id, "asdfasdfe3", model.GroupSyncableTypeChannel, "")
-	CheckBadRequestStatus(t, response)
+	// Names differing only in case are the same name
+	_, response = th.SystemAdminClient.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "Engineering" + id,
+		Source:      model.GroupSourceCustom,
+	})
+	CheckCreatedStatus(t, response)
+	_, response = th.SystemAdminClient.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "engineering" + id,
+		Source:      model.GroupSourceCustom,
+	})
+	require.NotNil(t, response.Error)
+	assert.Equal(t, "store.sql_group.unique_constraint", response.Error.Id)
 
-	th.SystemAdminClient.Logout()
-	_, response = th.SystemAdminClient.GetGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, "")
-	CheckUnauthorizedStatus(t, response)
+	// A SAML group keeps the attribute value it is created with as its remote id
+	samlGroup := &model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "saml" + id,
+		Source:      model.GroupSourceSaml,
+	}
+	_, response = th.SystemAdminClient.CreateGroup(samlGroup)
+	CheckUnprocessableEntityStatus(t, response)
+
+	samlGroup.RemoteId = "engineering" + id
+	created, response = th.SystemAdminClient.CreateGroup(samlGroup)
+	CheckCreatedStatus(t, response)
+	assert.Equal(t, model.GroupSourceSaml, created.Source)
+	assert.Equal(t, samlGroup.RemoteId, created.RemoteId)
 }
 
-func TestGetGroupTeams(t *testing.T) {
+func TestCreateGroupIdempotencyKey(t *testing.T) {
 	th := Setup().InitBasic()
 	defer th.TearDown()
 
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
 	id := model.NewId()
-	g, err := th.App.CreateGroup(&model.Group{
+	group := &model.Group{
 		DisplayName: "dn_" + id,
 		Name:        "name" + id,
-		Source:      model.GroupSourceLdap,
-		Description: "description_" + id,
-		RemoteId:    model.NewId(),
-	})
-	assert.Nil(t, err)
+		Source:      model.GroupSourceCustom,
+	}
 
-	th.App.SetLicense(model.NewTestLicense("ldap"))
+	th.SystemAdminClient.HttpHeader = map[string]string{model.HEADER_IDEMPOTENCY_KEY: model.NewId()}
+	defer func() { th.SystemAdminClient.HttpHeader = nil }()
 
-	patch := &model.GroupSyncablePatch{
-		AutoAdd: model.NewBool(true),
+	created1, response := th.SystemAdminClient.CreateGroup(group)
+	CheckCreatedStatus(t, response)
+
+	created2, response := th.SystemAdminClient.CreateGroup(group)
+	CheckCreatedStatus(t, response)
+	assert.Equal(t, created1.Id, created2.Id)
+
+	groups, err := th.App.GetGroupsBySource(model.GroupSourceCustom)
+	require.Nil(t, err)
+	count := 0
+	for _, g := range groups {
+		if g.Name == group.Name {
+			count++
+		}
 	}
+	assert.Equal(t, 1, count)
 
-	for i := 0; i < 10; i++ {
-		team := th.CreateTeam()
-		_, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, team.Id, model.GroupSyncableTypeTeam, patch)
-		assert.Equal(t, http.StatusCreated, response.StatusCode)
-	}
+	// The key belongs to the user who sent it.
+	th.Client.HttpHeader = th.SystemAdminClient.HttpHeader
+	defer func() { th.Client.HttpHeader = nil }()
+	_, response = th.Client.CreateGroup(group)
+	CheckForbiddenStatus(t, response)
+}
 
-	th.App.SetLicense(nil)
+func TestGroupReservedNames(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
 
-	_, response := th.Client.GetGroupSyncables(g.Id, model.GroupSyncableTypeTeam, "")
-	CheckNotImplementedStatus(t, response)
+	th.App.SetLicense(model.NewTestLicense("ldap"))
 
-	_, response = th.SystemAdminClient.GetGroupSyncables(g.Id, model.GroupSyncableTypeTeam, "")
-	CheckNotImplementedStatus(t, response)
+	newGroup := func(name string, allowReference bool) *model.Group {
+		return &model.Group{
+			DisplayName:    "dn_" + model.NewId(),
+			Name:           name,
+			Source:         model.GroupSourceCustom,
+			AllowReference: allowReference,
+		}
+	}
+	checkReserved := func(t *testing.T, response *model.Response) {
+		CheckUnprocessableEntityStatus(t, response)
+		assert.Equal(t, "api.group.name.reserved", response.Error.Id)
+	}
 
-	th.App.SetLicense(model.NewTestLicense("ldap"))
+	for _, name := range []string{"all", "channel", "here", "Here"} {
+		t.Run(name, func(t *testing.T) {
+			_, response := th.SystemAdminClient.CreateGroup(newGroup(name, true))
+			checkReserved(t, response)
 
-	_, response = th.Client.GetGroupSyncables(g.Id, model.GroupSyncableTypeTeam, "")
-	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+			group, response := th.SystemAdminClient.CreateGroup(newGroup("name"+model.NewId(), true))
+			CheckCreatedStatus(t, response)
+			_, response = th.SystemAdminClient.PatchGroup(group.Id, &model.GroupPatch{Name: model.NewString(name)})
+			checkReserved(t, response)
+		})
+	}
 
-	groupSyncables, response := th.SystemAdminClient.GetGroupSyncables(g.Id, model.GroupSyncableTypeTeam, "")
-	CheckOKStatus(t, response)
+	t.Run("username", func(t *testing.T) {
+		_, response := th.SystemAdminClient.CreateGroup(newGroup(th.BasicUser.Username, true))
+		checkReserved(t, response)
 
-	assert.Len(t, groupSyncables, 10)
+		// A group that cannot be mentioned may share a username, but cannot then be made referenceable
+		group, response := th.SystemAdminClient.CreateGroup(newGroup(th.BasicUser2.Username, false))
+		CheckCreatedStatus(t, response)
+		_, response = th.SystemAdminClient.PatchGroup(group.Id, &model.GroupPatch{AllowReference: model.NewBool(true)})
+		checkReserved(t, response)
 
-	th.SystemAdminClient.Logout()
-	_, response = th.SystemAdminClient.GetGroupSyncables(g.Id, model.GroupSyncableTypeTeam, "")
-	CheckUnauthorizedStatus(t, response)
+		// Other changes to the group are still allowed
+		_, response = th.SystemAdminClient.PatchGroup(group.Id, &model.GroupPatch{Description: model.NewString("updated")})
+		CheckOKStatus(t, response)
+	})
 }
 
-func TestGetGroupChannels(t *testing.T) {
+func TestGroupValidationStatusCodes(t *testing.T) {
 	th := Setup().InitBasic()
 	defer th.TearDown()
 
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
 	id := model.NewId()
-	g, err := th.App.CreateGroup(&model.Group{
+	group, err := th.App.CreateGroup(&model.Group{
 		DisplayName: "dn_" + id,
 		Name:        "name" + id,
-		Source:      model.GroupSourceLdap,
-		Description: "description_" + id,
+		Source:      model.GroupSourceCustom,
 		RemoteId:    model.NewId(),
 	})
-	assert.Nil(t, err)
-
-	th.App.SetLicense(model.NewTestLicense("ldap"))
+	require.Nil(t, err)
 
-	patch := &model.GroupSyncablePatch{
-		AutoAdd: model.NewBool(true),
+	client := th.SystemAdminClient
+	checkStatus := func(t *testing.T, expectedStatus int, r *http.Response, appErr *model.AppError) {
+		t.Helper()
+		require.NotNil(t, appErr)
+		assert.Equal(t, expectedStatus, r.StatusCode)
 	}
 
-	for i := 0; i < 10; i++ {
-		channel := th.CreatePublicChannel()
-		_, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, channel.Id, model.GroupSyncableTypeChannel, patch)
-		assert.Equal(t, http.StatusCreated, response.StatusCode)
-	}
+	t.Run("malformed bodies are bad requests", func(t *testing.T) {
+		r, appErr := client.DoApiPost(client.GetGroupsRoute(), "not json")
+		checkStatus(t, http.StatusBadRequest, r, appErr)
 
-	th.App.SetLicense(nil)
+		r, appErr = client.DoApiPost(client.GetGroupsRoute(), `{"name": 5}`)
+		checkStatus(t, http.StatusBadRequest, r, appErr)
 
-	_, response := th.Client.GetGroupSyncables(g.Id, model.GroupSyncableTypeChannel, "")
-	CheckNotImplementedStatus(t, response)
+		r, appErr = client.DoApiPut(client.GetGroupRoute(group.Id)+"/patch", `{"allow_reference": "yes"}`)
+		checkStatus(t, http.StatusBadRequest, r, appErr)
 
-	_, response = th.SystemAdminClient.GetGroupSyncables(g.Id, model.GroupSyncableTypeChannel, "")
-	CheckNotImplementedStatus(t, response)
+		r, appErr = client.DoApiPost(client.GetGroupRoute(group.Id)+"/teams/"+th.BasicTeam.Id+"/link", `{"auto_add": 1}`)
+		checkStatus(t, http.StatusBadRequest, r, appErr)
+	})
 
-	th.App.SetLicense(model.NewTestLicense("ldap"))
+	t.Run("invalid values are unprocessable", func(t *testing.T) {
+		_, response := client.CreateGroup(&model.Group{DisplayName: "dn_" + id, Source: model.GroupSourceCustom})
+		CheckUnprocessableEntityStatus(t, response)
+		assert.Equal(t, "model.group.name.app_error", response.Error.Id)
 
-	_, response = th.Client.GetGroupSyncables(g.Id, model.GroupSyncableTypeChannel, "")
-	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+		_, response = client.CreateGroup(&model.Group{DisplayName: "dn_" + id, Name: "here", Source: model.GroupSourceCustom, AllowReference: true})
+		CheckUnprocessableEntityStatus(t, response)
+		assert.Equal(t, "api.group.name.reserved", response.Error.Id)
 
-	groupSyncables, response := th.SystemAdminClient.GetGroupSyncables(g.Id, model.GroupSyncableTypeChannel, "")
-	CheckOKStatus(t, response)
+		_, response = client.PatchGroup(group.Id, &model.GroupPatch{DisplayName: model.NewString("")})
+		CheckUnprocessableEntityStatus(t, response)
 
-	assert.Len(t, groupSyncables, 10)
+		_, response = client.PatchGroup(group.Id, &model.GroupPatch{MembershipWebhookURL: model.NewString("http://example.com/hook")})
+		CheckUnprocessableEntityStatus(t, response)
 
-	th.SystemAdminClient.Logout()
-	_, response = th.SystemAdminClient.GetGroupSyncables(g.Id, model.GroupSyncableTypeChannel, "")
-	CheckUnauthorizedStatus(t, response)
+		_, response = client.LinkGroupSyncable(group.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, &model.GroupSyncablePatch{CreateDefaultChannel: model.NewBool(true)})
+		CheckUnprocessableEntityStatus(t, response)
+
+		_, response = client.LinkGroupSyncable(group.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, &model.GroupSyncablePatch{Roles: model.NewString(model.CHANNEL_ADMIN_ROLE_ID)})
+		CheckUnprocessableEntityStatus(t, response)
+		assert.Equal(t, "api.group.syncable.invalid_roles", response.Error.Id)
+	})
 }
 
-func TestPatchGroupTeam(t *testing.T) {
+func TestAddGroupMembers(t *testing.T) {
 	th := Setup().InitBasic()
 	defer th.TearDown()
 
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
 	id := model.NewId()
-	g, err := th.App.CreateGroup(&model.Group{
+	group, response := th.SystemAdminClient.CreateGroup(&model.Group{
 		DisplayName: "dn_" + id,
 		Name:        "name" + id,
-		Source:      model.GroupSourceLdap,
-		Description: "description_" + id,
-		RemoteId:    model.NewId(),
+		Source:      model.GroupSourceCustom,
 	})
-	assert.Nil(t, err)
+	CheckCreatedStatus(t, response)
 
-	patch := &model.GroupSyncablePatch{
-		AutoAdd: model.NewBool(true),
-	}
+	user1 := th.CreateUser()
+	user2 := th.CreateUser()
 
-	th.App.SetLicense(model.NewTestLicense("ldap"))
+	_, response = th.Client.UpsertGroupMembers(group.Id, []string{user1.Id})
+	CheckForbiddenStatus(t, response)
 
-	groupSyncable, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
-	assert.Equal(t, http.StatusCreated, response.StatusCode)
-	assert.NotNil(t, groupSyncable)
-	assert.True(t, groupSyncable.AutoAdd)
+	_, response = th.SystemAdminClient.UpsertGroupMembers(group.Id, []string{})
+	CheckBadRequestStatus(t, response)
+	assert.Equal(t, "api.group.member.empty_ids", response.Error.Id)
 
-	_, response = th.Client.PatchGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
-	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	_, response = th.SystemAdminClient.UpsertGroupMembers(group.Id, nil)
+	CheckBadRequestStatus(t, response)
+	assert.Equal(t, "api.group.member.empty_ids", response.Error.Id)
 
-	th.App.SetLicense(nil)
+	_, response = th.SystemAdminClient.UpsertGroupMembers(group.Id, []string{"junk"})
+	CheckBadRequestStatus(t, response)
 
-	_, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
-	CheckNotImplementedStatus(t, response)
+	_, response = th.SystemAdminClient.UpsertGroupMembers(group.Id, []string{user1.Id, "junk"})
+	CheckBadRequestStatus(t, response)
 
-	th.App.SetLicense(model.NewTestLicense("ldap"))
+	_, response = th.SystemAdminClient.UpsertGroupMembers(model.NewId(), []string{user1.Id})
+	CheckNotFoundStatus(t, response)
 
-	patch.AutoAdd = model.NewBool(false)
-	groupSyncable, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
+	// Duplicates are only added once
+	result, response := th.SystemAdminClient.UpsertGroupMembers(group.Id, []string{user1.Id, user1.Id})
 	CheckOKStatus(t, response)
-	assert.False(t, groupSyncable.AutoAdd)
-
-	assert.Equal(t, g.Id, groupSyncable.GroupId)
-	assert.Equal(t, th.BasicTeam.Id, groupSyncable.SyncableId)
-	assert.Equal(t, model.GroupSyncableTypeTeam, groupSyncable.Type)
+	assert.Equal(t, []string{user1.Id}, result.Added)
+	assert.Empty(t, result.AlreadyMembers)
 
-	patch.AutoAdd = model.NewBool(true)
-	groupSyncable, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
+	// Partial overlap with the existing members
+	result, response = th.SystemAdminClient.UpsertGroupMembers(group.Id, []string{user1.Id, user2.Id})
 	CheckOKStatus(t, response)
+	assert.Equal(t, []string{user2.Id}, result.Added)
+	assert.Equal(t, []string{user1.Id}, result.AlreadyMembers)
 
-	_, response = th.SystemAdminClient.PatchGroupSyncable(model.NewId(), th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
-	CheckNotFoundStatus(t, response)
-
-	_, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, model.NewId(), model.GroupSyncableTypeTeam, patch)
-	CheckNotFoundStatus(t, response)
+	users, err := th.App.GetGroupMemberUsers(group.Id)
+	require.Nil(t, err)
+	assert.Len(t, users, 2)
 
-	_, response = th.SystemAdminClient.PatchGroupSyncable("abc", th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
-	CheckBadRequestStatus(t, response)
+	// The members of LDAP groups are managed by the sync.
+	ldapGroup, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "ldap" + id,
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, err)
 
-	_, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, "abc", model.GroupSyncableTypeTeam, patch)
+	_, response = th.SystemAdminClient.UpsertGroupMembers(ldapGroup.Id, []string{user1.Id})
 	CheckBadRequestStatus(t, response)
-
-	th.SystemAdminClient.Logout()
-	_, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
-	CheckUnauthorizedStatus(t, response)
 }
 
-func TestPatchGroupChannel(t *testing.T) {
+func TestGetGroupMemberHistory(t *testing.T) {
 	th := Setup().InitBasic()
 	defer th.TearDown()
 
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
 	id := model.NewId()
-	g, err := th.App.CreateGroup(&model.Group{
+	group, response := th.SystemAdminClient.CreateGroup(&model.Group{
 		DisplayName: "dn_" + id,
 		Name:        "name" + id,
-		Source:      model.GroupSourceLdap,
-		Description: "description_" + id,
-		RemoteId:    model.NewId(),
+		Source:      model.GroupSourceCustom,
 	})
-	assert.Nil(t, err)
-
-	patch := &model.GroupSyncablePatch{
-		AutoAdd: model.NewBool(true),
+	CheckCreatedStatus(t, response)
+
+	user1 := th.CreateUser()
+	user2 := th.CreateUser()
+
+	// Events are ordered by time, so make sure each change lands in a later millisecond than the one before.
+	changes := []func(){
+		func() { th.SystemAdminClient.UpsertGroupMembers(group.Id, []string{user1.Id}) },
+		func() { th.SystemAdminClient.UpsertGroupMembers(group.Id, []string{user1.Id, user2.Id}) },
+		func() { th.SystemAdminClient.DeleteGroupMembers(group.Id, []string{user1.Id}) },
+		func() { th.SystemAdminClient.UpsertGroupMembers(group.Id, []string{user1.Id}) },
+		func() { th.SystemAdminClient.DeleteGroupMembers(group.Id, []string{user1.Id, user2.Id}) },
+	}
+	for _, change := range changes {
+		change()
+		time.Sleep(2 * time.Millisecond)
 	}
 
-	th.App.SetLicense(model.NewTestLicense("ldap"))
-
-	groupSyncable, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
-	assert.Equal(t, http.StatusCreated, response.StatusCode)
-	assert.NotNil(t, groupSyncable)
-	assert.True(t, groupSyncable.AutoAdd)
+	_, response = th.Client.GetGroupMemberHistory(group.Id, 0, 0, 0, 60)
+	CheckForbiddenStatus(t, response)
 
-	_, response = th.Client.PatchGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
-	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	_, response = th.SystemAdminClient.GetGroupMemberHistory(model.NewId(), 0, 0, 0, 60)
+	CheckNotFoundStatus(t, response)
 
-	th.App.SetLicense(nil)
+	_, response = th.SystemAdminClient.GetGroupMemberHistory(group.Id, 10, 5, 0, 60)
+	CheckBadRequestStatus(t, response)
 
-	_, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
-	CheckNotImplementedStatus(t, response)
+	events, response := th.SystemAdminClient.GetGroupMemberHistory(group.Id, 0, 0, 0, 60)
+	CheckOKStatus(t, response)
 
-	th.App.SetLicense(model.NewTestLicense("ldap"))
+	type entry struct{ UserId, Action string }
+	var entries []entry
+	for _, event := range events {
+		entries = append(entries, entry{event.UserId, event.Action})
+	}
+	add, remove := model.GroupMembershipWebhookActionAdd, model.GroupMembershipWebhookActionRemove
+	require.Len(t, entries, 6)
+	assert.Equal(t, []entry{{user1.Id, add}, {user2.Id, add}, {user1.Id, remove}, {user1.Id, add}}, entries[:4])
+	assert.ElementsMatch(t, []entry{{user1.Id, remove}, {user2.Id, remove}}, entries[4:])
 
-	patch.AutoAdd = model.NewBool(false)
-	groupSyncable, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	// Paging and time bounds
+	page, response := th.SystemAdminClient.GetGroupMemberHistory(group.Id, 0, 0, 1, 2)
 	CheckOKStatus(t, response)
-	assert.False(t, groupSyncable.AutoAdd)
+	assert.Equal(t, events[2:4], page)
 
-	assert.Equal(t, g.Id, groupSyncable.GroupId)
-	assert.Equal(t, th.BasicChannel.Id, groupSyncable.SyncableId)
-	assert.Equal(t, model.GroupSyncableTypeChannel, groupSyncable.Type)
+	page, response = th.SystemAdminClient.GetGroupMemberHistory(group.Id, events[3].CreateAt, 0, 0, 60)
+	CheckOKStatus(t, response)
+	assert.Equal(t, events[3:], page)
 
-	patch.AutoAdd = model.NewBool(true)
-	groupSyncable, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	page, response = th.SystemAdminClient.GetGroupMemberHistory(group.Id, 0, events[1].CreateAt, 0, 60)
 	CheckOKStatus(t, response)
+	assert.Equal(t, events[:2], page)
+}
 
-	_, response = th.SystemAdminClient.PatchGroupSyncable(model.NewId(), th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
-	CheckNotFoundStatus(t, response)
+func TestAddGroupMembersWithExpiry(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
 
-	_, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, model.NewId(), model.GroupSyncableTypeChannel, patch)
-	CheckNotFoundStatus(t, response)
+	th.App.SetLicense(model.NewTestLicense("ldap"))
 
-	_, response = th.SystemAdminClient.PatchGroupSyncable("abc", th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	id := model.NewId()
+	group, response := th.SystemAdminClient.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceCustom,
+	})
+	CheckCreatedStatus(t, response)
+
+	user1 := th.CreateUser()
+	user2 := th.CreateUser()
+	expiresAt := model.GetMillis() + 60*60*1000
+
+	// Expiry times must be in the future and for one of the users being added.
+	_, response = th.SystemAdminClient.UpsertGroupMembersWithExpiry(group.Id, []string{user1.Id}, map[string]int64{user1.Id: model.GetMillis() - 1000})
 	CheckBadRequestStatus(t, response)
 
-	_, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, "abc", model.GroupSyncableTypeChannel, patch)
+	_, response = th.SystemAdminClient.UpsertGroupMembersWithExpiry(group.Id, []string{user1.Id}, map[string]int64{user2.Id: expiresAt})
 	CheckBadRequestStatus(t, response)
 
-	th.SystemAdminClient.Logout()
-	_, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
-	CheckUnauthorizedStatus(t, response)
+	result, response := th.SystemAdminClient.UpsertGroupMembersWithExpiry(group.Id, []string{user1.Id, user2.Id}, map[string]int64{user1.Id: expiresAt})
+	CheckOKStatus(t, response)
+	assert.Len(t, result.Added, 2)
+
+	member, err := th.App.GetGroupMember(group.Id, user1.Id)
+	require.Nil(t, err)
+	assert.Equal(t, expiresAt, member.ExpiresAt)
+
+	member, err = th.App.GetGroupMember(group.Id, user2.Id)
+	require.Nil(t, err)
+	assert.Zero(t, member.ExpiresAt)
+
+	var list struct {
+		Members   []*model.User    `json:"members"`
+		Count     int              `json:"total_member_count"`
+		ExpiresAt map[string]int64 `json:"expires_at"`
+	}
+
+	r, appErr := th.SystemAdminClient.DoApiGet("/groups/"+group.Id+"/members?expiring_before="+strconv.FormatInt(expiresAt+1, 10), "")
+	require.Nil(t, appErr)
+	defer r.Body.Close()
+	require.Nil(t, json.NewDecoder(r.Body).Decode(&list))
+	require.Len(t, list.Members, 1)
+	assert.Equal(t, user1.Id, list.Members[0].Id)
+	assert.Equal(t, 1, list.Count)
+	assert.Equal(t, map[string]int64{user1.Id: expiresAt}, list.ExpiresAt)
+
+	_, appErr = th.SystemAdminClient.DoApiGet("/groups/"+group.Id+"/members?expiring_before=junk", "")
+	require.NotNil(t, appErr)
+	assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
 }
 
-func TestGetGroupsByChannel(t *testing.T) {
+func TestGetGroupMembersIncludeSource(t *testing.T) {
 	th := Setup().InitBasic()
 	defer th.TearDown()
 
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	getSources := func(groupID string, includeSource bool) map[string]string {
+		r, appErr := th.SystemAdminClient.DoApiGet("/groups/"+groupID+"/members?include_source="+strconv.FormatBool(includeSource), "")
+		require.Nil(t, appErr)
+		defer r.Body.Close()
+
+		var listing struct {
+			Sources map[string]string `json:"sources"`
+		}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&listing))
+		return listing.Sources
+	}
+
 	id := model.NewId()
-	group, err := th.App.CreateGroup(&model.Group{
+	customGroup, response := th.SystemAdminClient.CreateGroup(&model.Group{
 		DisplayName: "dn_" + id,
-		Name:        "name" + id,
-		Source:      model.GroupSourceLdap,
-		Description: "description_" + id,
-		RemoteId:    model.NewId(),
+		Name:        "custom" + id,
+		Source:      model.GroupSourceCustom,
 	})
-	assert.Nil(t, err)
+	CheckCreatedStatus(t, response)
 
-	_, err = th.App.CreateGroupSyncable(&model.GroupSyncable{
-		AutoAdd:    true,
-		SyncableId: th.BasicChannel.Id,
-		Type:       model.GroupSyncableTypeChannel,
-		GroupId:    group.Id,
+	_, response = th.SystemAdminClient.UpsertGroupMembers(customGroup.Id, []string{th.BasicUser.Id})
+	CheckOKStatus(t, response)
+
+	assert.Equal(t, map[string]string{th.BasicUser.Id: model.GroupMemberSourceManual}, getSources(customGroup.Id, true))
+	assert.Nil(t, getSources(customGroup.Id, false), "sources are only listed when asked for")
+
+	ldapGroup, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "ldap" + id,
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
 	})
-	assert.Nil(t, err)
+	require.Nil(t, err)
 
-	_, response := th.SystemAdminClient.GetGroupsByChannel("asdfasdf", 0, 60)
+	// Users can't be added by hand to an LDAP group, so all of its members come from the sync.
+	_, response = th.SystemAdminClient.UpsertGroupMembers(ldapGroup.Id, []string{th.BasicUser.Id})
 	CheckBadRequestStatus(t, response)
 
-	th.App.SetLicense(nil)
+	require.Nil(t, th.App.SyncGroupMembers(ldapGroup, []string{th.BasicUser2.Id}, &model.GroupSyncSummary{}))
+	assert.Equal(t, map[string]string{th.BasicUser2.Id: model.GroupMemberSourceLdap}, getSources(ldapGroup.Id, true))
 
-	_, response = th.SystemAdminClient.GetGroupsByChannel(th.BasicChannel.Id, 0, 60)
-	CheckNotImplementedStatus(t, response)
+	samlGroup, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "saml" + id,
+		Source:      model.GroupSourceSaml,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, err)
+
+	_, err = th.App.UpsertGroupMembers(samlGroup.Id, []string{th.BasicUser.Id})
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{th.BasicUser.Id: model.GroupMemberSourceSaml}, getSources(samlGroup.Id, true))
+}
+
+func TestGroupMembersBatchLimit(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
 
 	th.App.SetLicense(model.NewTestLicense("ldap"))
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.GroupSettings.MaxMembersPerRequest = 3 })
 
-	_, response = th.Client.GetGroupsByChannel(th.BasicChannel.Id, 0, 60)
-	CheckForbiddenStatus(t, response)
+	id := model.NewId()
+	group, response := th.SystemAdminClient.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceCustom,
+	})
+	CheckCreatedStatus(t, response)
 
-	groups, response := th.SystemAdminClient.GetGroupsByChannel(th.BasicChannel.Id, 0, 60)
-	assert.Nil(t, response.Error)
-	assert.ElementsMatch(t, []*model.Group{group}, groups)
+	userIds := []string{model.NewId(), model.NewId(), model.NewId(), model.NewId()}
 
-	groups, response = th.SystemAdminClient.GetGroupsByChannel(model.NewId(), 0, 60)
-	assert.Nil(t, response.Error)
-	assert.Empty(t, groups)
+	_, response = th.SystemAdminClient.UpsertGroupMembers(group.Id, userIds)
+	CheckRequestEntityTooLargeStatus(t, response)
+	assert.Equal(t, "api.group.member.batch_too_large", response.Error.Id)
+
+	_, response = th.SystemAdminClient.DeleteGroupMembers(group.Id, userIds)
+	CheckRequestEntityTooLargeStatus(t, response)
+
+	_, response = th.SystemAdminClient.CheckGroupMembers(group.Id, userIds)
+	CheckRequestEntityTooLargeStatus(t, response)
+
+	result, response := th.SystemAdminClient.UpsertGroupMembers(group.Id, userIds[:3])
+	CheckOKStatus(t, response)
+	assert.Len(t, result.Added, 3)
+
+	members, response := th.SystemAdminClient.DeleteGroupMembers(group.Id, userIds[:3])
+	CheckOKStatus(t, response)
+	assert.Len(t, members, 3)
 }
 
-func TestGetGroupsByTeam(t *testing.T) {
+func TestCheckGroupMembers(t *testing.T) {
 	th := Setup().InitBasic()
 	defer th.TearDown()
 
@@ -648,37 +967,4159 @@ func TestGetGroupsByTeam(t *testing.T) {
 		DisplayName: "dn_" + id,
 		Name:        "name" + id,
 		Source:      model.GroupSourceLdap,
-		Description: "description_" + id,
 		RemoteId:    model.NewId(),
 	})
-	assert.Nil(t, err)
-
-	_, err = th.App.CreateGroupSyncable(&model.GroupSyncable{
-		AutoAdd:    true,
-		SyncableId: th.BasicTeam.Id,
-		Type:       model.GroupSyncableTypeTeam,
-		GroupId:    group.Id,
-	})
-	assert.Nil(t, err)
+	require.Nil(t, err)
+
+	member1 := th.CreateUser()
+	member2 := th.CreateUser()
+	removed := th.CreateUser()
+	for _, user := range []*model.User{member1, member2, removed} {
+		_, err = th.App.CreateOrRestoreGroupMember(group.Id, user.Id)
+		require.Nil(t, err)
+	}
+	_, err = th.App.DeleteGroupMember(group.Id, removed.Id)
+	require.Nil(t, err)
 
-	_, response := th.SystemAdminClient.GetGroupsByTeam("asdfasdf", 0, 60)
-	CheckBadRequestStatus(t, response)
+	unknownUserId := model.NewId()
+	userIds := []string{member1.Id, th.BasicUser.Id, unknownUserId, member2.Id, removed.Id, member1.Id}
 
 	th.App.SetLicense(nil)
 
-	_, response = th.SystemAdminClient.GetGroupsByTeam(th.BasicTeam.Id, 0, 60)
+	_, response := th.SystemAdminClient.CheckGroupMembers(group.Id, userIds)
 	CheckNotImplementedStatus(t, response)
 
 	th.App.SetLicense(model.NewTestLicense("ldap"))
 
-	_, response = th.Client.GetGroupsByTeam(th.BasicTeam.Id, 0, 60)
+	_, response = th.Client.CheckGroupMembers(group.Id, userIds)
 	CheckForbiddenStatus(t, response)
 
-	groups, response := th.SystemAdminClient.GetGroupsByTeam(th.BasicTeam.Id, 0, 60)
-	assert.Nil(t, response.Error)
-	assert.ElementsMatch(t, []*model.Group{group}, groups)
+	_, response = th.SystemAdminClient.CheckGroupMembers(group.Id, nil)
+	CheckBadRequestStatus(t, response)
 
-	groups, response = th.SystemAdminClient.GetGroupsByTeam(model.NewId(), 0, 60)
-	assert.Nil(t, response.Error)
-	assert.Empty(t, groups)
+	_, response = th.SystemAdminClient.CheckGroupMembers(group.Id, []string{"junk"})
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.SystemAdminClient.CheckGroupMembers(model.NewId(), userIds)
+	CheckNotFoundStatus(t, response)
+
+	// The members of LDAP groups can be checked even though they cannot be changed through the API.
+	result, response := th.SystemAdminClient.CheckGroupMembers(group.Id, userIds)
+	CheckOKStatus(t, response)
+	assert.Equal(t, []string{member1.Id, member2.Id}, result.Members)
+	assert.Equal(t, []string{th.BasicUser.Id, unknownUserId, removed.Id}, result.NonMembers)
+}
+
+func TestDeleteGroupMembers(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	id := model.NewId()
+	group, response := th.SystemAdminClient.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceCustom,
+	})
+	CheckCreatedStatus(t, response)
+
+	user1 := th.CreateUser()
+	user2 := th.CreateUser()
+
+	_, response = th.SystemAdminClient.UpsertGroupMembers(group.Id, []string{user1.Id})
+	CheckOKStatus(t, response)
+
+	_, response = th.Client.DeleteGroupMembers(group.Id, []string{user1.Id})
+	CheckForbiddenStatus(t, response)
+
+	_, response = th.SystemAdminClient.DeleteGroupMembers(group.Id, []string{})
+	CheckBadRequestStatus(t, response)
+	assert.Equal(t, "api.group.member.empty_ids", response.Error.Id)
+
+	_, response = th.SystemAdminClient.DeleteGroupMembers(group.Id, []string{user1.Id, "junk"})
+	CheckBadRequestStatus(t, response)
+
+	members, response := th.SystemAdminClient.DeleteGroupMembers(group.Id, []string{user1.Id, user2.Id, user1.Id})
+	CheckOKStatus(t, response)
+	require.Len(t, members, 1)
+	assert.Equal(t, user1.Id, members[0].UserId)
+
+	users, err := th.App.GetGroupMemberUsers(group.Id)
+	require.Nil(t, err)
+	assert.Empty(t, users)
+}
+
+func TestClearGroupMembers(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	id := model.NewId()
+	group, response := th.SystemAdminClient.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceCustom,
+	})
+	CheckCreatedStatus(t, response)
+
+	userIds := []string{th.CreateUser().Id, th.CreateUser().Id, th.CreateUser().Id}
+	_, response = th.SystemAdminClient.UpsertGroupMembers(group.Id, userIds)
+	CheckOKStatus(t, response)
+
+	_, response = th.Client.ClearGroupMembers(group.Id, false)
+	CheckForbiddenStatus(t, response)
+
+	_, response = th.SystemAdminClient.ClearGroupMembers(model.NewId(), false)
+	CheckNotFoundStatus(t, response)
+
+	ldapGroup := th.CreateGroup()
+	_, response = th.SystemAdminClient.ClearGroupMembers(ldapGroup.Id, true)
+	CheckBadRequestStatus(t, response)
+
+	constrainedChannel := th.BasicChannel
+	constrainedChannel.GroupConstrained = model.NewBool(true)
+	constrainedChannel, err := th.App.UpdateChannel(constrainedChannel)
+	require.Nil(t, err)
+	_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(group.Id, constrainedChannel.Id, true))
+	require.Nil(t, err)
+
+	// The members of the constrained channel may lose access, so this has to be confirmed.
+	_, response = th.SystemAdminClient.ClearGroupMembers(group.Id, false)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+	assert.Equal(t, "api.group.members.clear.constrained.app_error", response.Error.Id)
+
+	result, response := th.SystemAdminClient.ClearGroupMembers(group.Id, true)
+	CheckOKStatus(t, response)
+	assert.Equal(t, 3, result.RemovedCount)
+
+	users, err := th.App.GetGroupMemberUsers(group.Id)
+	require.Nil(t, err)
+	assert.Empty(t, users)
+
+	_, err = th.App.GetGroup(group.Id)
+	require.Nil(t, err)
+
+	events, response := th.SystemAdminClient.GetGroupMemberHistory(group.Id, 0, 0, 0, 60)
+	CheckOKStatus(t, response)
+	var removed []string
+	for _, event := range events {
+		if event.Action == model.GroupMembershipWebhookActionRemove {
+			removed = append(removed, event.UserId)
+		}
+	}
+	assert.ElementsMatch(t, userIds, removed)
+
+	// Clearing an empty group removes nothing.
+	result, response = th.SystemAdminClient.ClearGroupMembers(group.Id, true)
+	CheckOKStatus(t, response)
+	assert.Equal(t, 0, result.RemovedCount)
+}
+
+func TestGetGroupMembersSanitization(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+	require.True(t, *th.App.Config().PrivacySettings.ShowEmailAddress)
+
+	id := model.NewId()
+	group, response := th.SystemAdminClient.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceCustom,
+	})
+	CheckCreatedStatus(t, response)
+
+	_, response = th.SystemAdminClient.UpsertGroupMembers(group.Id, []string{th.BasicUser.Id, th.BasicUser2.Id})
+	CheckOKStatus(t, response)
+
+	getMembers := func(client *model.Client4) []*model.User {
+		r, appErr := client.DoApiGet("/groups/"+group.Id+"/members?page=0&per_page=60", "")
+		require.Nil(t, appErr)
+		defer r.Body.Close()
+
+		var result struct {
+			Members []*model.User `json:"members"`
+		}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&result))
+		require.Len(t, result.Members, 2)
+		return result.Members
+	}
+
+	t.Run("admins see emails", func(t *testing.T) {
+		for _, member := range getMembers(th.SystemAdminClient) {
+			assert.NotEmpty(t, member.Email)
+			assert.Empty(t, member.Password)
+		}
+	})
+
+	t.Run("members of the group do not", func(t *testing.T) {
+		for _, member := range getMembers(th.Client) {
+			assert.NotEmpty(t, member.Username)
+			assert.Empty(t, member.Email)
+			assert.Empty(t, member.AuthService)
+			assert.Empty(t, member.Password)
+			if member.AuthData != nil {
+				assert.Empty(t, *member.AuthData)
+			}
+		}
+	})
+
+	t.Run("others may not list the members", func(t *testing.T) {
+		outsider := th.CreateUser()
+		client := th.CreateClient()
+		_, response := client.Login(outsider.Email, outsider.Password)
+		CheckNoError(t, response)
+
+		_, appErr := client.DoApiGet("/groups/"+group.Id+"/members", "")
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusForbidden, appErr.StatusCode)
+	})
+}
+
+func TestGetGroupMemberIds(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	id := model.NewId()
+	group, response := th.SystemAdminClient.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceCustom,
+	})
+	CheckCreatedStatus(t, response)
+
+	var userIds []string
+	for i := 0; i < 5; i++ {
+		userIds = append(userIds, th.CreateUser().Id)
+	}
+	_, response = th.SystemAdminClient.UpsertGroupMembers(group.Id, userIds)
+	CheckOKStatus(t, response)
+
+	getMembers := func(query string, result interface{}) {
+		r, appErr := th.SystemAdminClient.DoApiGet("/groups/"+group.Id+"/members?"+query, "")
+		require.Nil(t, appErr)
+		defer r.Body.Close()
+		require.Nil(t, json.NewDecoder(r.Body).Decode(result))
+	}
+
+	for page := 0; page < 3; page++ {
+		var full struct {
+			Members []*model.User `json:"members"`
+			Count   int           `json:"total_member_count"`
+		}
+		getMembers(fmt.Sprintf("page=%d&per_page=2", page), &full)
+
+		var ids struct {
+			MemberIds []string `json:"member_ids"`
+			Count     int      `json:"total_member_count"`
+		}
+		getMembers(fmt.Sprintf("page=%d&per_page=2&only_ids=true", page), &ids)
+
+		var fullIds []string
+		for _, user := range full.Members {
+			fullIds = append(fullIds, user.Id)
+		}
+		assert.Equal(t, fullIds, ids.MemberIds, "page %d", page)
+		assert.Equal(t, 5, ids.Count)
+		assert.Equal(t, full.Count, ids.Count)
+	}
+
+	_, appErr := th.SystemAdminClient.DoApiGet("/groups/"+group.Id+"/members?only_ids=true&include_nested=true", "")
+	require.NotNil(t, appErr)
+	assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+
+	_, appErr = th.Client.DoApiGet("/groups/"+group.Id+"/members?only_ids=true", "")
+	require.NotNil(t, appErr)
+	assert.Equal(t, http.StatusForbidden, appErr.StatusCode)
+}
+
+func TestGetGroupMembersStreamed(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	id := model.NewId()
+	group, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceCustom,
+	})
+	require.Nil(t, err)
+
+	const memberCount = 450
+	userIds := make([]string, 0, memberCount)
+	for i := 0; i < memberCount; i++ {
+		res := <-th.App.Srv.Store.User().Save(&model.User{Email: th.GenerateTestEmail(), Username: "un_" + model.NewId()})
+		require.Nil(t, res.Err)
+		userIds = append(userIds, res.Data.(*model.User).Id)
+	}
+	_, err = th.App.UpsertGroupMembers(group.Id, userIds)
+	require.Nil(t, err)
+
+	getBody := func(route string) []byte {
+		r, appErr := th.SystemAdminClient.DoApiGet(route, "")
+		require.Nil(t, appErr)
+		defer r.Body.Close()
+		body, readErr := ioutil.ReadAll(r.Body)
+		require.Nil(t, readErr)
+		require.True(t, json.Valid(body), "the streamed response is not valid JSON")
+		return body
+	}
+
+	var seen []string
+	for page, expected := range []int{200, 200, 50} {
+		body := getBody(fmt.Sprintf("/groups/%s/members?page=%d&per_page=200", group.Id, page))
+
+		var fields map[string]json.RawMessage
+		require.Nil(t, json.Unmarshal(body, &fields))
+		assert.Len(t, fields, 3, "expires_at is left out when no membership expires")
+
+		var response struct {
+			Members []*model.User     `json:"members"`
+			Count   int               `json:"total_member_count"`
+			Roles   map[string]string `json:"roles"`
+		}
+		require.Nil(t, json.Unmarshal(body, &response))
+		require.Len(t, response.Members, expected, "page %d", page)
+		assert.Equal(t, memberCount, response.Count)
+		assert.Len(t, response.Roles, expected)
+
+		for _, member := range response.Members {
+			assert.Contains(t, response.Roles, member.Id)
+			seen = append(seen, member.Id)
+		}
+	}
+	assert.ElementsMatch(t, userIds, seen)
+
+	var ids struct {
+		MemberIds []string `json:"member_ids"`
+		Count     int      `json:"total_member_count"`
+	}
+	require.Nil(t, json.Unmarshal(getBody("/groups/"+group.Id+"/members?page=0&per_page=200&only_ids=true"), &ids))
+	assert.Len(t, ids.MemberIds, 200)
+	assert.Equal(t, memberCount, ids.Count)
+
+	var envelope struct {
+		Data    []*model.User `json:"data"`
+		Page    int           `json:"page"`
+		PerPage int           `json:"per_page"`
+		Count   int           `json:"total_member_count"`
+	}
+	require.Nil(t, json.Unmarshal(getBody("/groups/"+group.Id+"/members?page=2&per_page=200&envelope=true"), &envelope))
+	assert.Len(t, envelope.Data, 50)
+	assert.Equal(t, 2, envelope.Page)
+	assert.Equal(t, 200, envelope.PerPage)
+	assert.Equal(t, memberCount, envelope.Count)
+
+	var groups []*model.Group
+	require.Nil(t, json.Unmarshal(getBody("/groups?page=0&per_page=200&q="+id), &groups))
+	require.Len(t, groups, 1)
+	assert.Equal(t, group.Id, groups[0].Id)
+
+	// An empty page is still an array
+	assert.Equal(t, "[]", string(getBody("/groups?page=0&per_page=200&q="+model.NewId())))
+}
+
+func TestTransferGroupMemberships(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	createGroup := func(source model.GroupSource) *model.Group {
+		id := model.NewId()
+		group := &model.Group{
+			DisplayName: "dn_" + id,
+			Name:        "name" + id,
+			Source:      source,
+		}
+		if source == model.GroupSourceLdap {
+			group.RemoteId = model.NewId()
+		}
+		group, err := th.App.CreateGroup(group)
+		require.Nil(t, err)
+		return group
+	}
+
+	customGroup1 := createGroup(model.GroupSourceCustom)
+	customGroup2 := createGroup(model.GroupSourceCustom)
+	ldapGroup := createGroup(model.GroupSourceLdap)
+
+	from := th.CreateUser()
+	to := th.CreateUser()
+
+	for _, group := range []*model.Group{customGroup1, customGroup2, ldapGroup} {
+		_, err := th.App.CreateOrRestoreGroupMember(group.Id, from.Id)
+		require.Nil(t, err)
+	}
+
+	// The target already belonging to a group is not an error
+	_, err := th.App.CreateOrRestoreGroupMember(customGroup2.Id, to.Id)
+	require.Nil(t, err)
+
+	transfer := &model.GroupMembershipTransfer{FromUserId: from.Id, ToUserId: to.Id}
+
+	_, response := th.Client.TransferGroupMemberships(transfer)
+	CheckForbiddenStatus(t, response)
+
+	_, response = th.SystemAdminClient.TransferGroupMemberships(&model.GroupMembershipTransfer{FromUserId: from.Id, ToUserId: "asdfasdf"})
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.SystemAdminClient.TransferGroupMemberships(&model.GroupMembershipTransfer{FromUserId: from.Id, ToUserId: from.Id})
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.SystemAdminClient.TransferGroupMemberships(&model.GroupMembershipTransfer{FromUserId: from.Id, ToUserId: model.NewId()})
+	CheckNotFoundStatus(t, response)
+
+	groupIds, response := th.SystemAdminClient.TransferGroupMemberships(transfer)
+	CheckOKStatus(t, response)
+	assert.ElementsMatch(t, []string{customGroup1.Id, customGroup2.Id}, groupIds)
+
+	isMember := func(userId, groupId string) bool {
+		members, appErr := th.App.GetGroupMembers(groupId, []string{userId})
+		require.Nil(t, appErr)
+		return len(members) == 1
+	}
+
+	assert.True(t, isMember(to.Id, customGroup1.Id))
+	assert.True(t, isMember(to.Id, customGroup2.Id))
+	assert.False(t, isMember(to.Id, ldapGroup.Id))
+	assert.True(t, isMember(from.Id, customGroup1.Id))
+
+	transfer.RemoveSource = true
+	groupIds, response = th.SystemAdminClient.TransferGroupMemberships(transfer)
+	CheckOKStatus(t, response)
+	assert.ElementsMatch(t, []string{customGroup1.Id, customGroup2.Id}, groupIds)
+
+	assert.False(t, isMember(from.Id, customGroup1.Id))
+	assert.False(t, isMember(from.Id, customGroup2.Id))
+	assert.True(t, isMember(from.Id, ldapGroup.Id))
+	assert.True(t, isMember(to.Id, customGroup1.Id))
+}
+
+func TestRebindGroupRemoteId(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	createGroup := func(source model.GroupSource) *model.Group {
+		id := model.NewId()
+		group, err := th.App.CreateGroup(&model.Group{
+			DisplayName: "dn_" + id,
+			Name:        "name" + id,
+			Source:      source,
+			RemoteId:    model.NewId(),
+		})
+		require.Nil(t, err)
+		return group
+	}
+	group := createGroup(model.GroupSourceLdap)
+	other := createGroup(model.GroupSourceLdap)
+	custom := createGroup(model.GroupSourceCustom)
+
+	_, err := th.App.CreateOrRestoreGroupMember(group.Id, th.BasicUser.Id)
+	require.Nil(t, err)
+	_, err = th.App.CreateGroupSyncable(model.NewGroupTeam(group.Id, th.BasicTeam.Id, false))
+	require.Nil(t, err)
+
+	newRemoteId := model.NewId()
+
+	_, response := th.SystemAdminClient.RebindGroupRemoteId(group.Id, newRemoteId)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.Client.RebindGroupRemoteId(group.Id, newRemoteId)
+	CheckForbiddenStatus(t, response)
+
+	_, response = th.SystemAdminClient.RebindGroupRemoteId(group.Id, "")
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.SystemAdminClient.RebindGroupRemoteId(model.NewId(), newRemoteId)
+	CheckNotFoundStatus(t, response)
+
+	_, response = th.SystemAdminClient.RebindGroupRemoteId(custom.Id, newRemoteId)
+	CheckBadRequestStatus(t, response)
+
+	// The remote id of another LDAP group cannot be taken
+	_, response = th.SystemAdminClient.RebindGroupRemoteId(group.Id, other.RemoteId)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+	assert.Equal(t, "app.group.remote_id.conflict.app_error", response.Error.Id)
+
+	rebound, response := th.SystemAdminClient.RebindGroupRemoteId(group.Id, newRemoteId)
+	CheckOKStatus(t, response)
+	assert.Equal(t, group.Id, rebound.Id)
+	assert.Equal(t, newRemoteId, rebound.RemoteId)
+
+	// The group keeps its members and syncables
+	_, err = th.App.GetGroupSyncable(group.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam)
+	require.Nil(t, err)
+	isMember, err := th.App.IsUserInGroup(th.BasicUser.Id, group.Id)
+	require.Nil(t, err)
+	assert.True(t, isMember)
+
+	found, err := th.App.GetGroupByRemoteID(newRemoteId, model.GroupSourceLdap)
+	require.Nil(t, err)
+	assert.Equal(t, group.Id, found.Id)
+
+	// Rebinding to the current remote id is allowed
+	_, response = th.SystemAdminClient.RebindGroupRemoteId(group.Id, newRemoteId)
+	CheckOKStatus(t, response)
+}
+
+func TestGetUnionGroupMembers(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	group1 := th.CreateGroup()
+	group2 := th.CreateGroup()
+	user1 := th.CreateUser()
+	user2 := th.CreateUser()
+	user3 := th.CreateUser()
+
+	_, err := th.App.UpsertGroupMembers(group1.Id, []string{user1.Id, user2.Id})
+	require.Nil(t, err)
+	_, err = th.App.UpsertGroupMembers(group2.Id, []string{user2.Id, user3.Id})
+	require.Nil(t, err)
+
+	groupIds := []string{group1.Id, group2.Id}
+
+	_, _, response := th.Client.GetUnionGroupMembers(groupIds, 0, 60)
+	CheckForbiddenStatus(t, response)
+
+	// The member of both groups is listed once
+	members, count, response := th.SystemAdminClient.GetUnionGroupMembers(groupIds, 0, 60)
+	CheckOKStatus(t, response)
+	assert.Equal(t, 3, count)
+	var ids []string
+	for _, member := range members {
+		ids = append(ids, member.Id)
+	}
+	assert.ElementsMatch(t, []string{user1.Id, user2.Id, user3.Id}, ids)
+
+	// Paging covers each user once
+	firstPage, count, response := th.SystemAdminClient.GetUnionGroupMembers(groupIds, 0, 2)
+	CheckOKStatus(t, response)
+	assert.Equal(t, 3, count)
+	secondPage, _, response := th.SystemAdminClient.GetUnionGroupMembers(groupIds, 1, 2)
+	CheckOKStatus(t, response)
+	require.Len(t, firstPage, 2)
+	require.Len(t, secondPage, 1)
+	assert.NotContains(t, []string{firstPage[0].Id, firstPage[1].Id}, secondPage[0].Id)
+
+	_, _, response = th.SystemAdminClient.GetUnionGroupMembers([]string{group1.Id, model.NewId()}, 0, 60)
+	CheckNotFoundStatus(t, response)
+
+	_, _, response = th.SystemAdminClient.GetUnionGroupMembers([]string{}, 0, 60)
+	CheckBadRequestStatus(t, response)
+
+	tooMany := make([]string, model.GroupMembersUnionMaxCount+1)
+	for i := range tooMany {
+		tooMany[i] = model.NewId()
+	}
+	_, _, response = th.SystemAdminClient.GetUnionGroupMembers(tooMany, 0, 60)
+	CheckRequestEntityTooLargeStatus(t, response)
+}
+
+func TestSendGroupMessage(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	group := th.CreateGroup()
+	user1 := th.CreateUser()
+	user2 := th.CreateUser()
+	_, err := th.App.UpsertGroupMembers(group.Id, []string{user1.Id, user2.Id, th.SystemAdminUser.Id})
+	require.Nil(t, err)
+
+	_, response := th.Client.SendGroupMessage(group.Id, "hello")
+	CheckForbiddenStatus(t, response)
+
+	_, response = th.SystemAdminClient.SendGroupMessage(group.Id, " ")
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.SystemAdminClient.SendGroupMessage(model.NewId(), "hello")
+	CheckNotFoundStatus(t, response)
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.GroupSettings.MaxMessageFanout = 2 })
+	_, response = th.SystemAdminClient.SendGroupMessage(group.Id, "hello")
+	CheckRequestEntityTooLargeStatus(t, response)
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.GroupSettings.MaxMessageFanout = 3 })
+
+	message := "hello " + model.NewId()
+	job, response := th.SystemAdminClient.SendGroupMessage(group.Id, message)
+	require.Nil(t, response.Error)
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+	require.NotNil(t, job)
+	assert.Equal(t, model.JOB_TYPE_GROUP_MESSAGE, job.Type)
+
+	for deadline := time.Now().Add(10 * time.Second); ; {
+		job, err = th.App.GetJob(job.Id)
+		require.Nil(t, err)
+		if job.Status == model.JOB_STATUS_SUCCESS || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	require.Equal(t, model.JOB_STATUS_SUCCESS, job.Status)
+	assert.Equal(t, "2", job.Data["sent_count"])
+	assert.Equal(t, "0", job.Data["failed_count"])
+
+	// Each member other than the sender has the message in their direct channel with the sender
+	for _, user := range []*model.User{user1, user2} {
+		channel, err := th.App.GetOrCreateDirectChannel(th.SystemAdminUser.Id, user.Id)
+		require.Nil(t, err)
+		posts, err := th.App.GetPosts(channel.Id, 0, 10)
+		require.Nil(t, err)
+		require.Len(t, posts.Order, 1)
+		post := posts.Posts[posts.Order[0]]
+		assert.Equal(t, message, post.Message)
+		assert.Equal(t, th.SystemAdminUser.Id, post.UserId)
+	}
+}
+
+func TestGroupListPerPageClamp(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	group := th.CreateGroup()
+	userIds := make([]string, 0, 205)
+	for i := 0; i < 205; i++ {
+		userIds = append(userIds, th.CreateUser().Id)
+	}
+	_, err := th.App.UpsertGroupMembers(group.Id, userIds)
+	require.Nil(t, err)
+
+	for _, tc := range []struct {
+		perPage         string
+		expectedPerPage int
+	}{
+		{"", 60},
+		{"-5", 60},
+		{"0", 60},
+		{"1", 1},
+		{"199", 199},
+		{"200", 200},
+		{"201", 200},
+		{"100000", 200},
+	} {
+		t.Run("per_page="+tc.perPage, func(t *testing.T) {
+			r, appErr := th.SystemAdminClient.DoApiGet("/groups/"+group.Id+"/members?only_ids=true&envelope=true&per_page="+tc.perPage, "")
+			require.Nil(t, appErr)
+			defer r.Body.Close()
+
+			assert.Equal(t, strconv.Itoa(tc.expectedPerPage), r.Header.Get(model.HEADER_PER_PAGE))
+			assert.Equal(t, "0", r.Header.Get(model.HEADER_PAGE))
+
+			var envelope struct {
+				Data    []string `json:"data"`
+				PerPage int      `json:"per_page"`
+			}
+			require.Nil(t, json.NewDecoder(r.Body).Decode(&envelope))
+			assert.Equal(t, tc.expectedPerPage, envelope.PerPage)
+			assert.Len(t, envelope.Data, tc.expectedPerPage)
+		})
+	}
+
+	// Endpoints writing their lists directly report the page size too
+	r, appErr := th.SystemAdminClient.DoApiGet("/groups/"+group.Id+"/members/history?per_page=100000", "")
+	require.Nil(t, appErr)
+	r.Body.Close()
+	assert.Equal(t, "200", r.Header.Get(model.HEADER_PER_PAGE))
+}
+
+func TestGetUngroupedUsers(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	_, _, response := th.SystemAdminClient.GetUngroupedUsers(0, 60)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, _, response = th.Client.GetUngroupedUsers(0, 60)
+	CheckForbiddenStatus(t, response)
+
+	group := th.CreateGroup()
+	deletedGroup := th.CreateGroup()
+	grouped := th.CreateUser()
+	ungrouped := th.CreateUser()
+	formerMember := th.CreateUser()
+	inDeletedGroup := th.CreateUser()
+	_, err := th.App.UpsertGroupMembers(group.Id, []string{grouped.Id, formerMember.Id})
+	require.Nil(t, err)
+	_, err = th.App.DeleteGroupMember(group.Id, formerMember.Id)
+	require.Nil(t, err)
+	_, err = th.App.UpsertGroupMembers(deletedGroup.Id, []string{inDeletedGroup.Id})
+	require.Nil(t, err)
+	_, err = th.App.DeleteGroup(deletedGroup.Id)
+	require.Nil(t, err)
+
+	allUngrouped := func() (map[string]bool, int) {
+		ids := map[string]bool{}
+		var total int
+		for page := 0; ; page++ {
+			users, count, response := th.SystemAdminClient.GetUngroupedUsers(page, 200)
+			CheckOKStatus(t, response)
+			total = count
+			for _, user := range users {
+				ids[user.Id] = true
+			}
+			if len(users) < 200 {
+				break
+			}
+		}
+		return ids, total
+	}
+
+	ids, count := allUngrouped()
+	assert.Equal(t, len(ids), count)
+	assert.True(t, ids[ungrouped.Id])
+	assert.True(t, ids[formerMember.Id])
+	assert.True(t, ids[inDeletedGroup.Id])
+	assert.False(t, ids[grouped.Id])
+
+	_, err = th.App.UpsertGroupMembers(group.Id, []string{ungrouped.Id})
+	require.Nil(t, err)
+	ids, newCount := allUngrouped()
+	assert.Equal(t, count-1, newCount)
+	assert.False(t, ids[ungrouped.Id])
+}
+
+func TestGroupListPerPage(t *testing.T) {
+	for perPage, expected := range map[int]int{
+		-1:     GROUP_LIST_PER_PAGE_DEFAULT,
+		0:      GROUP_LIST_PER_PAGE_DEFAULT,
+		1:      1,
+		199:    199,
+		200:    200,
+		201:    GROUP_LIST_PER_PAGE_MAXIMUM,
+		100000: GROUP_LIST_PER_PAGE_MAXIMUM,
+	} {
+		assert.Equal(t, expected, groupListPerPage(perPage), "per_page=%d", perPage)
+	}
+}
+
+func TestGetIntersectionGroupMembers(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	group1 := th.CreateGroup()
+	group2 := th.CreateGroup()
+	user1 := th.CreateUser()
+	user2 := th.CreateUser()
+	user3 := th.CreateUser()
+	user4 := th.CreateUser()
+
+	_, err := th.App.UpsertGroupMembers(group1.Id, []string{user1.Id, user2.Id, user3.Id})
+	require.Nil(t, err)
+	_, err = th.App.UpsertGroupMembers(group2.Id, []string{user2.Id, user3.Id, user4.Id})
+	require.Nil(t, err)
+
+	groupIds := []string{group1.Id, group2.Id}
+
+	_, _, response := th.Client.GetIntersectionGroupMembers(groupIds, 0, 60)
+	CheckForbiddenStatus(t, response)
+
+	// Users in only one of the groups are left out
+	members, count, response := th.SystemAdminClient.GetIntersectionGroupMembers(groupIds, 0, 60)
+	CheckOKStatus(t, response)
+	assert.Equal(t, 2, count)
+	var ids []string
+	for _, member := range members {
+		ids = append(ids, member.Id)
+	}
+	assert.ElementsMatch(t, []string{user2.Id, user3.Id}, ids)
+
+	// Paging keeps the total
+	page, count, response := th.SystemAdminClient.GetIntersectionGroupMembers(groupIds, 1, 1)
+	CheckOKStatus(t, response)
+	assert.Equal(t, 2, count)
+	require.Len(t, page, 1)
+
+	// A removed membership no longer counts towards the intersection
+	_, err = th.App.DeleteGroupMember(group2.Id, user3.Id)
+	require.Nil(t, err)
+	members, count, response = th.SystemAdminClient.GetIntersectionGroupMembers(groupIds, 0, 60)
+	CheckOKStatus(t, response)
+	assert.Equal(t, 1, count)
+	require.Len(t, members, 1)
+	assert.Equal(t, user2.Id, members[0].Id)
+
+	_, _, response = th.SystemAdminClient.GetIntersectionGroupMembers([]string{group1.Id, model.NewId()}, 0, 60)
+	CheckNotFoundStatus(t, response)
+
+	_, _, response = th.SystemAdminClient.GetIntersectionGroupMembers([]string{}, 0, 60)
+	CheckBadRequestStatus(t, response)
+
+	tooMany := make([]string, model.GroupMembersIntersectionMaxCount+1)
+	for i := range tooMany {
+		tooMany[i] = model.NewId()
+	}
+	_, _, response = th.SystemAdminClient.GetIntersectionGroupMembers(tooMany, 0, 60)
+	CheckRequestEntityTooLargeStatus(t, response)
+}
+
+func TestPatchGroupMember(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	id := model.NewId()
+	group, response := th.SystemAdminClient.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceCustom,
+	})
+	CheckCreatedStatus(t, response)
+
+	user1 := th.CreateUser()
+	user2 := th.CreateUser()
+
+	_, response = th.SystemAdminClient.UpsertGroupMembers(group.Id, []string{user1.Id, user2.Id})
+	CheckOKStatus(t, response)
+
+	_, response = th.Client.PatchGroupMember(group.Id, user1.Id, &model.GroupMemberPatch{Roles: model.NewString("owner")})
+	CheckForbiddenStatus(t, response)
+
+	member, response := th.SystemAdminClient.PatchGroupMember(group.Id, user1.Id, &model.GroupMemberPatch{Roles: model.NewString("owner")})
+	CheckOKStatus(t, response)
+	assert.Equal(t, user1.Id, member.UserId)
+	assert.Equal(t, "owner", member.Roles)
+
+	// A patch without roles leaves them unchanged
+	member, response = th.SystemAdminClient.PatchGroupMember(group.Id, user1.Id, &model.GroupMemberPatch{})
+	CheckOKStatus(t, response)
+	assert.Equal(t, "owner", member.Roles)
+
+	_, response = th.SystemAdminClient.PatchGroupMember(group.Id, user1.Id, &model.GroupMemberPatch{Roles: model.NewString(strings.Repeat("a", model.GroupMemberRolesMaxLength+1))})
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.SystemAdminClient.PatchGroupMember(group.Id, th.BasicUser.Id, &model.GroupMemberPatch{Roles: model.NewString("owner")})
+	CheckNotFoundStatus(t, response)
+
+	_, response = th.SystemAdminClient.PatchGroupMember(model.NewId(), user1.Id, &model.GroupMemberPatch{Roles: model.NewString("owner")})
+	CheckNotFoundStatus(t, response)
+
+	// Roles survive adding the members again
+	_, response = th.SystemAdminClient.UpsertGroupMembers(group.Id, []string{user1.Id, user2.Id})
+	CheckOKStatus(t, response)
+
+	r, err := th.SystemAdminClient.DoApiGet(th.SystemAdminClient.GetGroupRoute(group.Id)+"/members", "")
+	require.Nil(t, err)
+	defer r.Body.Close()
+
+	var listing struct {
+		Members []*model.User     `json:"members"`
+		Roles   map[string]string `json:"roles"`
+	}
+	require.NoError(t, json.NewDecoder(r.Body).Decode(&listing))
+	assert.Len(t, listing.Members, 2)
+	assert.Equal(t, map[string]string{user1.Id: "owner", user2.Id: ""}, listing.Roles)
+}
+
+func TestNestedGroups(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	var groups []*model.Group
+	var users []*model.User
+	for i := 0; i < 3; i++ {
+		id := model.NewId()
+		group, response := th.SystemAdminClient.CreateGroup(&model.Group{
+			DisplayName: "dn_" + id,
+			Name:        "name" + id,
+			Source:      model.GroupSourceCustom,
+		})
+		CheckCreatedStatus(t, response)
+		groups = append(groups, group)
+
+		user := th.CreateUser()
+		users = append(users, user)
+		_, response = th.SystemAdminClient.UpsertGroupMembers(group.Id, []string{user.Id})
+		CheckOKStatus(t, response)
+	}
+
+	// groups[2] is nested beneath groups[1], which is nested beneath groups[0]
+	for i := 1; i < 3; i++ {
+		group, response := th.SystemAdminClient.PatchGroup(groups[i].Id, &model.GroupPatch{ParentGroupId: model.NewString(groups[i-1].Id)})
+		CheckOKStatus(t, response)
+		assert.Equal(t, groups[i-1].Id, group.ParentGroupId)
+	}
+
+	// A group cannot be nested beneath itself or one of its descendants
+	_, response := th.SystemAdminClient.PatchGroup(groups[0].Id, &model.GroupPatch{ParentGroupId: model.NewString(groups[0].Id)})
+	CheckUnprocessableEntityStatus(t, response)
+
+	_, response = th.SystemAdminClient.PatchGroup(groups[0].Id, &model.GroupPatch{ParentGroupId: model.NewString(groups[2].Id)})
+	CheckUnprocessableEntityStatus(t, response)
+	assert.Equal(t, "api.group.parent_group_id.cycle.app_error", response.Error.Id)
+
+	_, response = th.SystemAdminClient.PatchGroup(groups[0].Id, &model.GroupPatch{ParentGroupId: model.NewString(model.NewId())})
+	CheckNotFoundStatus(t, response)
+
+	listMembers := func(groupID, query string) []string {
+		r, appErr := th.SystemAdminClient.DoApiGet(th.SystemAdminClient.GetGroupRoute(groupID)+"/members"+query, "")
+		require.Nil(t, appErr)
+		defer r.Body.Close()
+
+		var listing struct {
+			Members []*model.User `json:"members"`
+			Count   int           `json:"total_member_count"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&listing))
+		require.Len(t, listing.Members, listing.Count)
+
+		var userIds []string
+		for _, member := range listing.Members {
+			userIds = append(userIds, member.Id)
+		}
+		return userIds
+	}
+
+	assert.Equal(t, []string{users[0].Id}, listMembers(groups[0].Id, ""))
+	assert.ElementsMatch(t, []string{users[0].Id, users[1].Id, users[2].Id}, listMembers(groups[0].Id, "?include_nested=true"))
+	assert.ElementsMatch(t, []string{users[1].Id, users[2].Id}, listMembers(groups[1].Id, "?include_nested=true"))
+
+	// A cycle written directly to the store does not stop the listing from finishing
+	groups[0].ParentGroupId = groups[2].Id
+	res := <-th.App.Srv.Store.Group().Update(groups[0])
+	require.Nil(t, res.Err)
+
+	assert.ElementsMatch(t, []string{users[0].Id, users[1].Id, users[2].Id}, listMembers(groups[1].Id, "?include_nested=true"))
+}
+
+func TestGetGroupAdminSyncables(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, err)
+
+	link := func(groupSyncable *model.GroupSyncable, schemeAdmin bool) {
+		groupSyncable.SchemeAdmin = schemeAdmin
+		_, err := th.App.CreateGroupSyncable(groupSyncable)
+		require.Nil(t, err)
+	}
+
+	// One admin and one plain link of each type
+	adminTeam := th.CreateTeam()
+	adminChannel := th.CreatePublicChannel()
+	link(model.NewGroupTeam(g.Id, adminTeam.Id, false), true)
+	link(model.NewGroupTeam(g.Id, th.BasicTeam.Id, false), false)
+	link(model.NewGroupChannel(g.Id, adminChannel.Id, false), true)
+	link(model.NewGroupChannel(g.Id, th.BasicChannel.Id, false), false)
+
+	_, response := th.SystemAdminClient.GetGroupAdminSyncables(g.Id, 0, 60)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.Client.GetGroupAdminSyncables(g.Id, 0, 60)
+	CheckForbiddenStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroupAdminSyncables(model.NewId(), 0, 60)
+	CheckNotFoundStatus(t, response)
+
+	syncables, response := th.SystemAdminClient.GetGroupAdminSyncables(g.Id, 0, 60)
+	CheckOKStatus(t, response)
+	require.Len(t, syncables, 2)
+
+	assert.Equal(t, model.GroupSyncableTypeTeam, syncables[0].Type)
+	assert.Equal(t, adminTeam.Id, syncables[0].SyncableId)
+	assert.True(t, syncables[0].SchemeAdmin)
+
+	assert.Equal(t, model.GroupSyncableTypeChannel, syncables[1].Type)
+	assert.Equal(t, adminChannel.Id, syncables[1].SyncableId)
+	assert.Equal(t, adminChannel.TeamId, syncables[1].TeamID)
+	assert.True(t, syncables[1].SchemeAdmin)
+
+	// Display names of the targets are included
+	r, appErr := th.SystemAdminClient.DoApiGet(th.SystemAdminClient.GetGroupRoute(g.Id)+"/admin_syncables", "")
+	require.Nil(t, appErr)
+	defer r.Body.Close()
+	var listing []map[string]interface{}
+	require.NoError(t, json.NewDecoder(r.Body).Decode(&listing))
+	require.Len(t, listing, 2)
+	assert.Equal(t, adminTeam.DisplayName, listing[0]["team_display_name"])
+	assert.Equal(t, adminChannel.DisplayName, listing[1]["channel_display_name"])
+
+	// Paging
+	syncables, response = th.SystemAdminClient.GetGroupAdminSyncables(g.Id, 1, 1)
+	CheckOKStatus(t, response)
+	require.Len(t, syncables, 1)
+	assert.Equal(t, adminChannel.Id, syncables[0].SyncableId)
+
+	syncables, response = th.SystemAdminClient.GetGroupAdminSyncables(g.Id, 1, 2)
+	CheckOKStatus(t, response)
+	assert.Empty(t, syncables)
+}
+
+func TestGetGroupMemberTimezones(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	id := model.NewId()
+	group, response := th.SystemAdminClient.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceCustom,
+	})
+	CheckCreatedStatus(t, response)
+
+	// Two members in Paris, one in New York and one without a timezone
+	timezones := []map[string]string{
+		{"useAutomaticTimezone": "false", "manualTimezone": "Europe/Paris"},
+		{"useAutomaticTimezone": "true", "automaticTimezone": "Europe/Paris"},
+		{"useAutomaticTimezone": "true", "automaticTimezone": "America/New_York", "manualTimezone": "Asia/Tokyo"},
+		{"useAutomaticTimezone": "true", "automaticTimezone": ""},
+	}
+	var userIds []string
+	for _, timezone := range timezones {
+		user := th.CreateUser()
+		user.Timezone = timezone
+		_, response = th.SystemAdminClient.UpdateUser(user)
+		CheckNoError(t, response)
+		userIds = append(userIds, user.Id)
+	}
+
+	_, response = th.SystemAdminClient.UpsertGroupMembers(group.Id, userIds)
+	CheckOKStatus(t, response)
+
+	// Users outside the group are not counted
+	outsider := th.BasicUser
+	outsider.Timezone["useAutomaticTimezone"] = "false"
+	outsider.Timezone["manualTimezone"] = "Europe/Paris"
+	_, response = th.SystemAdminClient.UpdateUser(outsider)
+	CheckNoError(t, response)
+
+	_, response = th.Client.GetGroupMemberTimezones(group.Id)
+	CheckForbiddenStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroupMemberTimezones(model.NewId())
+	CheckNotFoundStatus(t, response)
+
+	distribution, response := th.SystemAdminClient.GetGroupMemberTimezones(group.Id)
+	CheckOKStatus(t, response)
+	assert.Equal(t, map[string]int{
+		"Europe/Paris":                   2,
+		"America/New_York":               1,
+		model.GroupMemberTimezoneUnknown: 1,
+	}, distribution)
+
+	th.App.SetLicense(nil)
+
+	_, response = th.SystemAdminClient.GetGroupMemberTimezones(group.Id)
+	CheckNotImplementedStatus(t, response)
+}
+
+func TestGetGroupMentionCount(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	createGroup := func(allowReference bool) *model.Group {
+		id := model.NewId()
+		group, err := th.App.CreateGroup(&model.Group{
+			DisplayName:    "dn_" + id,
+			Name:           "name" + id,
+			Source:         model.GroupSourceCustom,
+			AllowReference: allowReference,
+		})
+		require.Nil(t, err)
+		return group
+	}
+	group := createGroup(true)
+	hidden := createGroup(false)
+
+	// The group has 100 members, only 10 of whom are in the channel
+	for i := 0; i < 100; i++ {
+		user := th.CreateUser()
+		_, err := th.App.CreateOrRestoreGroupMember(group.Id, user.Id)
+		require.Nil(t, err)
+		if i < 10 {
+			th.LinkUserToTeam(user, th.BasicTeam)
+			th.AddUserToChannel(user, th.BasicChannel)
+		}
+	}
+
+	_, response := th.Client.GetGroupMentionCount(th.BasicChannel.Id, group.Id)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	count, response := th.Client.GetGroupMentionCount(th.BasicChannel.Id, group.Id)
+	CheckOKStatus(t, response)
+	assert.Equal(t, int64(10), count.Count)
+
+	_, response = th.Client.GetGroupMentionCount(th.BasicChannel.Id, hidden.Id)
+	CheckNotFoundStatus(t, response)
+
+	_, response = th.Client.GetGroupMentionCount(th.BasicChannel.Id, model.NewId())
+	CheckNotFoundStatus(t, response)
+
+	// Users who cannot read the channel cannot see the count
+	privateChannel := th.CreatePrivateChannel()
+	th.LoginBasic2()
+	_, response = th.Client.GetGroupMentionCount(privateChannel.Id, group.Id)
+	CheckForbiddenStatus(t, response)
+}
+
+func TestGetChannelGroupMembers(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	defaultRolePermissions := th.SaveDefaultRolePermissions()
+	defer func() {
+		th.RestoreDefaultRolePermissions(defaultRolePermissions)
+	}()
+	th.AddPermissionToRole(model.PERMISSION_MANAGE_PRIVATE_CHANNEL_MEMBERS.Id, model.CHANNEL_ADMIN_ROLE_ID)
+	th.RemovePermissionFromRole(model.PERMISSION_MANAGE_PRIVATE_CHANNEL_MEMBERS.Id, model.CHANNEL_USER_ROLE_ID)
+
+	channel := th.CreateChannelWithClient(th.SystemAdminClient, model.CHANNEL_PRIVATE)
+	th.AddUserToChannel(th.BasicUser, channel)
+
+	linked := th.CreateGroup()
+	unlinked := th.CreateGroup()
+	user1 := th.CreateUser()
+	user2 := th.CreateUser()
+	_, err := th.App.UpsertGroupMembers(linked.Id, []string{user1.Id, user2.Id})
+	require.Nil(t, err)
+	_, err = th.App.UpsertGroupMembers(unlinked.Id, []string{user1.Id})
+	require.Nil(t, err)
+	_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(linked.Id, channel.Id, false))
+	require.Nil(t, err)
+
+	// A channel member without the permission to manage its members cannot list the group
+	_, _, response := th.Client.GetChannelGroupMembers(channel.Id, linked.Id, 0, 60)
+	CheckForbiddenStatus(t, response)
+
+	th.MakeUserChannelAdmin(th.BasicUser, channel)
+	th.App.InvalidateAllCaches()
+
+	members, count, response := th.Client.GetChannelGroupMembers(channel.Id, linked.Id, 0, 60)
+	CheckOKStatus(t, response)
+	assert.Equal(t, 2, count)
+	var ids []string
+	for _, member := range members {
+		ids = append(ids, member.Id)
+	}
+	assert.ElementsMatch(t, []string{user1.Id, user2.Id}, ids)
+
+	// The channel admin cannot see groups that are not linked to the channel
+	_, _, response = th.Client.GetChannelGroupMembers(channel.Id, unlinked.Id, 0, 60)
+	CheckNotFoundStatus(t, response)
+
+	// Nor groups whose link has been removed
+	_, err = th.App.DeleteGroupSyncable(linked.Id, channel.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, err)
+	_, _, response = th.Client.GetChannelGroupMembers(channel.Id, linked.Id, 0, 60)
+	CheckNotFoundStatus(t, response)
+
+	// The link is required even for system admins
+	_, _, response = th.SystemAdminClient.GetChannelGroupMembers(channel.Id, unlinked.Id, 0, 60)
+	CheckNotFoundStatus(t, response)
+
+	_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(unlinked.Id, channel.Id, false))
+	require.Nil(t, err)
+	members, count, response = th.SystemAdminClient.GetChannelGroupMembers(channel.Id, unlinked.Id, 0, 60)
+	CheckOKStatus(t, response)
+	assert.Equal(t, 1, count)
+	require.Len(t, members, 1)
+	assert.Equal(t, user1.Id, members[0].Id)
+
+	// An admin of another channel cannot use it to list the group
+	th.LoginBasic2()
+	th.CreatePrivateChannel()
+	_, _, response = th.Client.GetChannelGroupMembers(channel.Id, unlinked.Id, 0, 60)
+	CheckForbiddenStatus(t, response)
+}
+
+func TestGroupChannelPatterns(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, err)
+
+	prefix := "team-" + model.NewId()[:8]
+	createChannel := func(name string) *model.Channel {
+		channel, response := th.Client.CreateChannel(&model.Channel{
+			DisplayName: name,
+			Name:        name,
+			Type:        model.CHANNEL_OPEN,
+			TeamId:      th.BasicTeam.Id,
+		})
+		CheckCreatedStatus(t, response)
+		return channel
+	}
+	isLinked := func(channel *model.Channel) bool {
+		_, appErr := th.App.GetGroupSyncable(g.Id, channel.Id, model.GroupSyncableTypeChannel)
+		return appErr == nil
+	}
+
+	existing := createChannel(prefix + "-existing")
+	other := createChannel("other-" + model.NewId()[:8])
+
+	pattern := &model.GroupChannelPattern{Pattern: prefix + "-*", AutoAdd: true}
+
+	_, response := th.SystemAdminClient.CreateGroupChannelPattern(g.Id, pattern)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.Client.CreateGroupChannelPattern(g.Id, pattern)
+	CheckForbiddenStatus(t, response)
+
+	_, response = th.SystemAdminClient.CreateGroupChannelPattern(g.Id, &model.GroupChannelPattern{Pattern: "team-["})
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.SystemAdminClient.CreateGroupChannelPattern(model.NewId(), pattern)
+	CheckNotFoundStatus(t, response)
+
+	created, response := th.SystemAdminClient.CreateGroupChannelPattern(g.Id, pattern)
+	CheckCreatedStatus(t, response)
+	assert.Equal(t, g.Id, created.GroupId)
+	assert.Equal(t, pattern.Pattern, created.Pattern)
+
+	// Existing matches are linked right away
+	assert.True(t, isLinked(existing))
+	assert.False(t, isLinked(other))
+
+	syncable, appErr := th.App.GetGroupSyncable(g.Id, existing.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, appErr)
+	assert.True(t, syncable.AutoAdd)
+
+	patterns, response := th.SystemAdminClient.GetGroupChannelPatterns(g.Id)
+	CheckOKStatus(t, response)
+	require.Len(t, patterns, 1)
+	assert.Equal(t, pattern.Pattern, patterns[0].Pattern)
+
+	_, response = th.Client.GetGroupChannelPatterns(g.Id)
+	CheckForbiddenStatus(t, response)
+
+	// New matches are linked by the background task
+	since := model.GetMillis()
+	created2 := createChannel(prefix + "-new")
+	other2 := createChannel("other-" + model.NewId()[:8])
+	assert.False(t, isLinked(created2))
+
+	statuses, appErr := th.App.LinkGroupChannelPatterns(since)
+	require.Nil(t, appErr)
+	assert.Contains(t, statuses, &model.GroupChannelPatternStatus{GroupId: g.Id, Pattern: pattern.Pattern, Linked: 1})
+	assert.True(t, isLinked(created2))
+	assert.False(t, isLinked(other2))
+
+	// A channel the admin unlinked stays unlinked
+	_, appErr = th.App.DeleteGroupSyncable(g.Id, existing.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, appErr)
+
+	statuses, appErr = th.App.LinkGroupChannelPatterns(0)
+	require.Nil(t, appErr)
+	assert.Contains(t, statuses, &model.GroupChannelPatternStatus{GroupId: g.Id, Pattern: pattern.Pattern, Linked: 0})
+	syncable, appErr = th.App.GetGroupSyncable(g.Id, existing.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, appErr)
+	assert.NotZero(t, syncable.DeleteAt)
+}
+
+func TestGetGroupSyncablesReport(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	g1 := th.CreateGroup()
+	g2 := th.CreateGroup()
+	g3 := th.CreateGroup()
+	c1 := th.BasicChannel
+	c2 := th.BasicChannel2
+
+	for _, groupSyncable := range []*model.GroupSyncable{
+		model.NewGroupChannel(g1.Id, c1.Id, false),
+		model.NewGroupChannel(g1.Id, c2.Id, false),
+		model.NewGroupChannel(g2.Id, c2.Id, false),
+		model.NewGroupTeam(g3.Id, th.BasicTeam.Id, false),
+	} {
+		_, err := th.App.CreateGroupSyncable(groupSyncable)
+		require.Nil(t, err)
+	}
+	_, err := th.App.DeleteGroupSyncable(g2.Id, c2.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, err)
+
+	groupIds := []string{g1.Id, g2.Id, g3.Id}
+	channelIds := []string{c1.Id, c2.Id, model.NewId()}
+
+	_, response := th.SystemAdminClient.GetGroupSyncablesReport(groupIds, channelIds, model.GroupSyncableTypeChannel)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.Client.GetGroupSyncablesReport(groupIds, channelIds, model.GroupSyncableTypeChannel)
+	CheckForbiddenStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroupSyncablesReport(groupIds, []string{}, model.GroupSyncableTypeChannel)
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroupSyncablesReport(groupIds, channelIds, model.GroupSyncableType("Bogus"))
+	CheckBadRequestStatus(t, response)
+
+	tooMany := make([]string, model.GroupSyncablesReportMaxCount+1)
+	for i := range tooMany {
+		tooMany[i] = model.NewId()
+	}
+	_, response = th.SystemAdminClient.GetGroupSyncablesReport(tooMany, channelIds, model.GroupSyncableTypeChannel)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, response.StatusCode)
+
+	// Only active links are reported, and groups without one are left out
+	linked, response := th.SystemAdminClient.GetGroupSyncablesReport(groupIds, channelIds, model.GroupSyncableTypeChannel)
+	CheckOKStatus(t, response)
+	require.Len(t, linked, 1)
+	assert.ElementsMatch(t, []string{c1.Id, c2.Id}, linked[g1.Id])
+
+	linked, response = th.SystemAdminClient.GetGroupSyncablesReport(groupIds, []string{th.BasicTeam.Id}, model.GroupSyncableTypeTeam)
+	CheckOKStatus(t, response)
+	assert.Equal(t, map[string][]string{g3.Id: {th.BasicTeam.Id}}, linked)
+}
+
+func TestGetGroupSyncablesExpanded(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	group := th.CreateGroup()
+	team := th.BasicTeam
+	channel := th.CreatePrivateChannel()
+
+	_, err := th.App.CreateGroupSyncable(model.NewGroupTeam(group.Id, team.Id, false))
+	require.Nil(t, err)
+	_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(group.Id, channel.Id, false))
+	require.Nil(t, err)
+
+	_, response := th.Client.GetGroupSyncablesExpanded(group.Id, model.GroupSyncableTypeTeam)
+	CheckForbiddenStatus(t, response)
+
+	teamSyncables, response := th.SystemAdminClient.GetGroupSyncablesExpanded(group.Id, model.GroupSyncableTypeTeam)
+	CheckOKStatus(t, response)
+	require.Len(t, teamSyncables, 1)
+	assert.Equal(t, team.Id, teamSyncables[0].SyncableId)
+	assert.Equal(t, team.DisplayName, teamSyncables[0].DisplayName)
+	assert.Equal(t, team.Name, teamSyncables[0].Name)
+	assert.Nil(t, teamSyncables[0].Team)
+
+	channelSyncables, response := th.SystemAdminClient.GetGroupSyncablesExpanded(group.Id, model.GroupSyncableTypeChannel)
+	CheckOKStatus(t, response)
+	require.Len(t, channelSyncables, 1)
+	assert.Equal(t, channel.Id, channelSyncables[0].SyncableId)
+	assert.Equal(t, channel.DisplayName, channelSyncables[0].DisplayName)
+	assert.Equal(t, channel.Name, channelSyncables[0].Name)
+	assert.Equal(t, channel.Type, channelSyncables[0].Type)
+	require.NotNil(t, channelSyncables[0].Team)
+	assert.Equal(t, team.Id, channelSyncables[0].Team.Id)
+	assert.Equal(t, team.DisplayName, channelSyncables[0].Team.DisplayName)
+	assert.Equal(t, team.Name, channelSyncables[0].Team.Name)
+	assert.Equal(t, team.Type, channelSyncables[0].Team.Type)
+
+	// Without expand the bare links are returned
+	r, appErr := th.SystemAdminClient.DoApiGet(th.SystemAdminClient.GetGroupSyncablesRoute(group.Id, model.GroupSyncableTypeChannel), "")
+	require.Nil(t, appErr)
+	defer r.Body.Close()
+	var bare []map[string]interface{}
+	require.Nil(t, json.NewDecoder(r.Body).Decode(&bare))
+	require.Len(t, bare, 1)
+	assert.Equal(t, channel.Id, bare[0]["channel_id"])
+	assert.NotContains(t, bare[0], "name")
+	assert.NotContains(t, bare[0], "team")
+}
+
+func TestGetGroupsByIds(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	createGroup := func(allowReference bool) *model.Group {
+		id := model.NewId()
+		group, err := th.App.CreateGroup(&model.Group{
+			DisplayName:          "dn_" + id,
+			Name:                 "name" + id,
+			Source:               model.GroupSourceCustom,
+			AllowReference:       allowReference,
+			MembershipWebhookURL: "https://example.com/" + id,
+		})
+		require.Nil(t, err)
+		return group
+	}
+	referenceable := createGroup(true)
+	hidden := createGroup(false)
+	deleted := createGroup(true)
+	_, err := th.App.DeleteGroup(deleted.Id)
+	require.Nil(t, err)
+	unknownId := model.NewId()
+
+	groupIds := []string{referenceable.Id, hidden.Id, deleted.Id, unknownId, referenceable.Id}
+
+	_, response := th.Client.GetGroupsByIds(groupIds)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	t.Run("admins see every group", func(t *testing.T) {
+		result, response := th.SystemAdminClient.GetGroupsByIds(groupIds)
+		CheckOKStatus(t, response)
+		require.Len(t, result.Groups, 2)
+		assert.ElementsMatch(t, []string{referenceable.Id, hidden.Id}, []string{result.Groups[0].Id, result.Groups[1].Id})
+		assert.ElementsMatch(t, []string{deleted.Id, unknownId}, result.NotFound)
+	})
+
+	t.Run("users only see referenceable groups", func(t *testing.T) {
+		result, response := th.Client.GetGroupsByIds(groupIds)
+		CheckOKStatus(t, response)
+		require.Len(t, result.Groups, 1)
+		assert.Equal(t, referenceable.Id, result.Groups[0].Id)
+		assert.Empty(t, result.Groups[0].MembershipWebhookURL)
+		assert.ElementsMatch(t, []string{hidden.Id, deleted.Id, unknownId}, result.NotFound)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, response := th.Client.GetGroupsByIds([]string{})
+		CheckBadRequestStatus(t, response)
+
+		_, response = th.Client.GetGroupsByIds([]string{"junk"})
+		CheckBadRequestStatus(t, response)
+
+		tooMany := make([]string, model.GroupsByIdsMaxCount+1)
+		for i := range tooMany {
+			tooMany[i] = model.NewId()
+		}
+		_, response = th.Client.GetGroupsByIds(tooMany)
+		require.NotNil(t, response.Error)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, response.StatusCode)
+	})
+}
+
+func TestMergeGroups(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	createGroup := func() *model.Group {
+		id := model.NewId()
+		group, response := th.SystemAdminClient.CreateGroup(&model.Group{
+			DisplayName: "dn_" + id,
+			Name:        "name" + id,
+			Source:      model.GroupSourceCustom,
+		})
+		CheckCreatedStatus(t, response)
+		return group
+	}
+	target := createGroup()
+	source := createGroup()
+
+	user1 := th.CreateUser()
+	user2 := th.CreateUser()
+	user3 := th.CreateUser()
+
+	_, response := th.SystemAdminClient.UpsertGroupMembers(target.Id, []string{user1.Id, user2.Id})
+	CheckOKStatus(t, response)
+	_, response = th.SystemAdminClient.UpsertGroupMembers(source.Id, []string{user2.Id, user3.Id})
+	CheckOKStatus(t, response)
+
+	_, response = th.SystemAdminClient.LinkGroupSyncable(target.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, &model.GroupSyncablePatch{AutoAdd: model.NewBool(false)})
+	CheckCreatedStatus(t, response)
+	_, response = th.SystemAdminClient.LinkGroupSyncable(source.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, &model.GroupSyncablePatch{AutoAdd: model.NewBool(true)})
+	CheckCreatedStatus(t, response)
+	_, response = th.SystemAdminClient.LinkGroupSyncable(source.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, &model.GroupSyncablePatch{AutoAdd: model.NewBool(true)})
+	CheckCreatedStatus(t, response)
+
+	_, response = th.Client.MergeGroups(target.Id, source.Id)
+	CheckForbiddenStatus(t, response)
+
+	_, response = th.SystemAdminClient.MergeGroups(target.Id, target.Id)
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.SystemAdminClient.MergeGroups(target.Id, model.NewId())
+	CheckNotFoundStatus(t, response)
+
+	id := model.NewId()
+	ldapGroup, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "ldap" + id,
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, err)
+
+	_, response = th.SystemAdminClient.MergeGroups(target.Id, ldapGroup.Id)
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.SystemAdminClient.MergeGroups(ldapGroup.Id, source.Id)
+	CheckBadRequestStatus(t, response)
+
+	result, response := th.SystemAdminClient.MergeGroups(target.Id, source.Id)
+	CheckOKStatus(t, response)
+	assert.Equal(t, int64(3), result.MemberCount)
+
+	teams, response := th.SystemAdminClient.GetGroupSyncables(target.Id, model.GroupSyncableTypeTeam, "")
+	CheckOKStatus(t, response)
+	require.Len(t, teams, 1)
+	assert.False(t, teams[0].AutoAdd)
+
+	channels, response := th.SystemAdminClient.GetGroupSyncables(target.Id, model.GroupSyncableTypeChannel, "")
+	CheckOKStatus(t, response)
+	assert.Len(t, channels, 1)
+
+	group, response := th.SystemAdminClient.GetGroup(source.Id, "")
+	CheckOKStatus(t, response)
+	assert.NotZero(t, group.DeleteAt)
+
+	// The source is gone, so merging it again fails
+	_, response = th.SystemAdminClient.MergeGroups(target.Id, source.Id)
+	CheckNotFoundStatus(t, response)
+}
+
+func TestGroupSyncableHandlersRejectUnknownSyncableType(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	handlers := map[string]func(*Context, http.ResponseWriter, *http.Request){
+		"linkGroupSyncable":   linkGroupSyncable,
+		"getGroupSyncable":    getGroupSyncable,
+		"getGroupSyncables":   getGroupSyncables,
+		"patchGroupSyncable":  patchGroupSyncable,
+		"unlinkGroupSyncable": unlinkGroupSyncable,
+	}
+
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			c := &Context{
+				App: th.App,
+				Params: &web.Params{
+					GroupId:      model.NewId(),
+					SyncableId:   model.NewId(),
+					SyncableType: model.GroupSyncableType("Bogus"),
+				},
+			}
+			request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+
+			handler(c, httptest.NewRecorder(), request)
+
+			require.NotNil(t, c.Err)
+			assert.Equal(t, http.StatusBadRequest, c.Err.StatusCode)
+			assert.Equal(t, "api.context.invalid_url_param.app_error", c.Err.Id)
+		})
+	}
+}
+
+func TestLinkGroupTeam(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		Description: "description_" + id,
+		RemoteId:    model.NewId(),
+	})
+	assert.Nil(t, err)
+
+	patch := &model.GroupSyncablePatch{
+		AutoAdd: model.NewBool(true),
+	}
+
+	_, response := th.Client.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
+	CheckNotImplementedStatus(t, response)
+
+	_, response = th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	groupTeam, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	assert.NotNil(t, groupTeam)
+}
+
+func TestLinkGroupChannel(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		Description: "description_" + id,
+		RemoteId:    model.NewId(),
+	})
+	assert.Nil(t, err)
+
+	patch := &model.GroupSyncablePatch{
+		AutoAdd: model.NewBool(true),
+	}
+
+	_, response := th.Client.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	CheckNotImplementedStatus(t, response)
+
+	_, response = th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+}
+
+func TestLinkGroupSyncableTargetNotFound(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, err)
+
+	patch := &model.GroupSyncablePatch{AutoAdd: model.NewBool(true)}
+
+	// A team id claimed to be a channel
+	_, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeChannel, patch)
+	CheckNotFoundStatus(t, response)
+	assert.Equal(t, "api.group.syncable.target_not_found", response.Error.Id)
+
+	// A channel id claimed to be a team
+	_, response = th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeTeam, patch)
+	CheckNotFoundStatus(t, response)
+	assert.Equal(t, "api.group.syncable.target_not_found", response.Error.Id)
+
+	// An id that matches nothing
+	_, response = th.SystemAdminClient.LinkGroupSyncable(g.Id, model.NewId(), model.GroupSyncableTypeChannel, patch)
+	CheckNotFoundStatus(t, response)
+	assert.Equal(t, "api.group.syncable.target_not_found", response.Error.Id)
+
+	syncables, err := th.App.GetGroupSyncables(g.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, err)
+	assert.Empty(t, syncables)
+
+	syncables, err = th.App.GetGroupSyncables(g.Id, model.GroupSyncableTypeTeam)
+	require.Nil(t, err)
+	assert.Empty(t, syncables)
+}
+
+func TestLinkGroupSyncableReferenceName(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	createGroup := func(name string, allowReference bool) *model.Group {
+		group, err := th.App.CreateGroup(&model.Group{
+			DisplayName:    "dn_" + model.NewId(),
+			Name:           name,
+			Source:         model.GroupSourceCustom,
+			AllowReference: allowReference,
+		})
+		require.Nil(t, err)
+		return group
+	}
+
+	patch := &model.GroupSyncablePatch{AutoAdd: model.NewBool(true)}
+
+	// A referenceable group whose name cannot be mentioned
+	g := createGroup("no mention "+model.NewId(), true)
+	_, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	CheckUnprocessableEntityStatus(t, response)
+	assert.Equal(t, "api.group.link.reference_requires_name", response.Error.Id)
+
+	syncables, err := th.App.GetGroupSyncables(g.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, err)
+	assert.Empty(t, syncables)
+
+	// A referenceable group with a name that can be mentioned
+	g = createGroup("Mention."+model.NewId(), true)
+	_, response = th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	CheckCreatedStatus(t, response)
+
+	// A group that cannot be mentioned links whatever its name
+	g = createGroup("no mention "+model.NewId(), false)
+	_, response = th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	CheckCreatedStatus(t, response)
+}
+
+func TestLinkGroupSyncableExpiresAt(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, err)
+
+	expiresAt := model.GetMillis() + 60*60*1000
+
+	_, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, &model.GroupSyncablePatch{ExpiresAt: model.NewInt64(model.GetMillis() - 1000)})
+	CheckUnprocessableEntityStatus(t, response)
+
+	groupSyncable, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, &model.GroupSyncablePatch{ExpiresAt: model.NewInt64(expiresAt)})
+	CheckCreatedStatus(t, response)
+	assert.Equal(t, expiresAt, groupSyncable.ExpiresAt)
+
+	otherChannel := th.CreatePublicChannel()
+	_, response = th.SystemAdminClient.LinkGroupSyncable(g.Id, otherChannel.Id, model.GroupSyncableTypeChannel, &model.GroupSyncablePatch{AutoAdd: model.NewBool(true)})
+	CheckCreatedStatus(t, response)
+
+	// Only the link expiring within the window is listed
+	groupSyncables, response := th.SystemAdminClient.GetExpiringGroupSyncables(g.Id, model.GroupSyncableTypeChannel, expiresAt+1)
+	CheckNoError(t, response)
+	require.Len(t, groupSyncables, 1)
+	assert.Equal(t, th.BasicChannel.Id, groupSyncables[0].SyncableId)
+	assert.Equal(t, expiresAt, groupSyncables[0].ExpiresAt)
+
+	groupSyncables, response = th.SystemAdminClient.GetExpiringGroupSyncables(g.Id, model.GroupSyncableTypeChannel, expiresAt)
+	CheckNoError(t, response)
+	assert.Empty(t, groupSyncables)
+
+	_, response = th.SystemAdminClient.GetExpiringGroupSyncables(g.Id, model.GroupSyncableTypeChannel, -1)
+	CheckBadRequestStatus(t, response)
+
+	// A restored link does not keep its old expiry time
+	response = th.SystemAdminClient.UnlinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel)
+	CheckNoError(t, response)
+
+	groupSyncable, response = th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, &model.GroupSyncablePatch{AutoAdd: model.NewBool(true)})
+	CheckCreatedStatus(t, response)
+	assert.Zero(t, groupSyncable.ExpiresAt)
+}
+
+func TestLinkGroupSyncableSkipsExistingChannelMembers(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	group := th.CreateGroup()
+	channel := th.CreatePublicChannel()
+
+	// Nine in ten of the group's members already belong to the channel
+	var userIds, newUserIds []string
+	for i := 0; i < 20; i++ {
+		user := th.CreateUser()
+		if i%10 == 0 {
+			newUserIds = append(newUserIds, user.Id)
+		} else {
+			th.LinkUserToTeam(user, th.BasicTeam)
+			th.AddUserToChannel(user, channel)
+		}
+		userIds = append(userIds, user.Id)
+	}
+	_, err := th.App.UpsertGroupMembers(group.Id, userIds)
+	require.Nil(t, err)
+
+	groupSyncable, response := th.SystemAdminClient.LinkGroupSyncable(group.Id, channel.Id, model.GroupSyncableTypeChannel, &model.GroupSyncablePatch{AutoAdd: model.NewBool(true)})
+	CheckCreatedStatus(t, response)
+	require.NotNil(t, groupSyncable.UsersAdded)
+	require.NotNil(t, groupSyncable.UsersSkippedExisting)
+	assert.Equal(t, 2, *groupSyncable.UsersAdded)
+	assert.Equal(t, 18, *groupSyncable.UsersSkippedExisting)
+
+	for _, userId := range newUserIds {
+		_, err = th.App.GetChannelMember(channel.Id, userId)
+		assert.Nil(t, err)
+	}
+
+	// Linking again finds every member in the channel
+	groupSyncable, response = th.SystemAdminClient.LinkGroupSyncable(group.Id, channel.Id, model.GroupSyncableTypeChannel, &model.GroupSyncablePatch{AutoAdd: model.NewBool(true)})
+	CheckCreatedStatus(t, response)
+	assert.Equal(t, 0, *groupSyncable.UsersAdded)
+	assert.Equal(t, 20, *groupSyncable.UsersSkippedExisting)
+
+	// Without auto-add nothing is reconciled
+	_, err = th.App.DeleteGroupSyncable(group.Id, channel.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, err)
+	groupSyncable, response = th.SystemAdminClient.LinkGroupSyncable(group.Id, channel.Id, model.GroupSyncableTypeChannel, &model.GroupSyncablePatch{AutoAdd: model.NewBool(false)})
+	CheckCreatedStatus(t, response)
+	assert.Nil(t, groupSyncable.UsersAdded)
+	assert.Nil(t, groupSyncable.UsersSkippedExisting)
+}
+
+func TestLinkGroupSyncableRoles(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+	th.App.SetPhase2PermissionsMigrationStatus(true)
+
+	group := th.CreateGroup()
+	channel := th.CreatePublicChannel()
+	user := th.CreateUser()
+	_, err := th.App.UpsertGroupMembers(group.Id, []string{user.Id})
+	require.Nil(t, err)
+
+	scheme, err := th.App.CreateScheme(&model.Scheme{
+		DisplayName: "dn_" + model.NewId(),
+		Name:        model.NewId(),
+		Scope:       model.SCHEME_SCOPE_CHANNEL,
+	})
+	require.Nil(t, err)
+	_, response := th.SystemAdminClient.UpdateChannelScheme(channel.Id, scheme.Id)
+	CheckNoError(t, response)
+
+	// The default admin role is not a role of the channel's scheme
+	_, response = th.SystemAdminClient.LinkGroupSyncable(group.Id, channel.Id, model.GroupSyncableTypeChannel, &model.GroupSyncablePatch{
+		AutoAdd: model.NewBool(true),
+		Roles:   model.NewString(model.CHANNEL_ADMIN_ROLE_ID),
+	})
+	CheckUnprocessableEntityStatus(t, response)
+	assert.Equal(t, "api.group.syncable.invalid_roles", response.Error.Id)
+
+	// Roles are only given through channel links
+	_, response = th.SystemAdminClient.LinkGroupSyncable(group.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, &model.GroupSyncablePatch{
+		Roles: model.NewString(scheme.DefaultChannelAdminRole),
+	})
+	CheckUnprocessableEntityStatus(t, response)
+
+	groupSyncable, response := th.SystemAdminClient.LinkGroupSyncable(group.Id, channel.Id, model.GroupSyncableTypeChannel, &model.GroupSyncablePatch{
+		AutoAdd: model.NewBool(true),
+		Roles:   model.NewString(scheme.DefaultChannelAdminRole),
+	})
+	CheckCreatedStatus(t, response)
+	assert.Equal(t, scheme.DefaultChannelAdminRole, groupSyncable.Roles)
+
+	// The member added by the link has the link's role
+	member, err := th.App.GetChannelMember(channel.Id, user.Id)
+	require.Nil(t, err)
+	assert.True(t, member.SchemeUser)
+	assert.True(t, member.SchemeAdmin)
+
+	groupSyncable, response = th.SystemAdminClient.GetGroupSyncable(group.Id, channel.Id, model.GroupSyncableTypeChannel, "")
+	CheckOKStatus(t, response)
+	assert.Equal(t, scheme.DefaultChannelAdminRole, groupSyncable.Roles)
+}
+
+// conflictingGroupSyncableStore fails to create group syncables as the SQL store does when the link was created
+// concurrently by another request.
+type conflictingGroupSyncableStore struct {
+	store.Store
+}
+
+func (s conflictingGroupSyncableStore) Group() store.GroupStore {
+	return conflictingGroupSyncableGroupStore{s.Store.Group()}
+}
+
+type conflictingGroupSyncableGroupStore struct {
+	store.GroupStore
+}
+
+func (s conflictingGroupSyncableGroupStore) CreateGroupSyncable(groupSyncable *model.GroupSyncable) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		result.Err = model.NewAppError("SqlGroupStore.GroupCreateGroupSyncable", "store.sql_group.syncable_uniqueness_error", nil, "", http.StatusBadRequest)
+	})
+}
+
+func TestLinkGroupSyncableConflict(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, err)
+
+	originalStore := th.App.Srv.Store
+	defer func() { th.App.Srv.Store = originalStore }()
+	th.App.Srv.Store = conflictingGroupSyncableStore{originalStore}
+
+	_, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, &model.GroupSyncablePatch{AutoAdd: model.NewBool(true)})
+	require.NotNil(t, response.Error)
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+	assert.Equal(t, "api.group.syncable.already_exists", response.Error.Id)
+}
+
+func TestExemptChannelMemberFromGroupConstraint(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	group := th.CreateGroup()
+
+	channel := th.BasicChannel
+
+	_, response := th.SystemAdminClient.ExemptChannelMemberFromGroupConstraint(channel.Id, th.BasicUser2.Id)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	th.RemovePermissionFromRole(model.PERMISSION_MANAGE_PUBLIC_CHANNEL_MEMBERS.Id, model.CHANNEL_USER_ROLE_ID)
+	_, response = th.Client.ExemptChannelMemberFromGroupConstraint(channel.Id, th.BasicUser2.Id)
+	CheckForbiddenStatus(t, response)
+	th.AddPermissionToRole(model.PERMISSION_MANAGE_PUBLIC_CHANNEL_MEMBERS.Id, model.CHANNEL_USER_ROLE_ID)
+
+	_, response = th.SystemAdminClient.ExemptChannelMemberFromGroupConstraint(channel.Id, th.SystemAdminUser.Id)
+	CheckNotFoundStatus(t, response)
+
+	exemption, response := th.SystemAdminClient.ExemptChannelMemberFromGroupConstraint(channel.Id, th.BasicUser2.Id)
+	CheckCreatedStatus(t, response)
+	assert.Equal(t, channel.Id, exemption.ChannelId)
+	assert.Equal(t, th.BasicUser2.Id, exemption.UserId)
+
+	channel.GroupConstrained = model.NewBool(true)
+	channel, err := th.App.UpdateChannel(channel)
+	require.Nil(t, err)
+	_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(group.Id, channel.Id, true))
+	require.Nil(t, err)
+
+	require.Nil(t, th.App.DeleteGroupConstrainedMemberships())
+
+	// The exempt member survives the reconciliation, unlike the other member outside the group
+	_, response = th.SystemAdminClient.GetChannelMember(channel.Id, th.BasicUser2.Id, "")
+	CheckOKStatus(t, response)
+	_, response = th.SystemAdminClient.GetChannelMember(channel.Id, th.BasicUser.Id, "")
+	CheckNotFoundStatus(t, response)
+}
+
+func TestGetChannelGroupsMissingTeamLink(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	channel := th.BasicPrivateChannel
+
+	createGroup := func(displayName string) *model.Group {
+		group, err := th.App.CreateGroup(&model.Group{
+			DisplayName: displayName,
+			Name:        model.NewId(),
+			Source:      model.GroupSourceLdap,
+			RemoteId:    model.NewId(),
+		})
+		require.Nil(t, err)
+		return group
+	}
+
+	linked := createGroup("a linked")
+	channelOnly := createGroup("b channel only")
+	teamUnlinked := createGroup("c team unlinked")
+	channelUnlinked := createGroup("d channel unlinked")
+
+	for _, group := range []*model.Group{linked, channelOnly, teamUnlinked, channelUnlinked} {
+		_, err := th.App.CreateGroupSyncable(model.NewGroupChannel(group.Id, channel.Id, false))
+		require.Nil(t, err)
+	}
+	for _, group := range []*model.Group{linked, teamUnlinked} {
+		_, err := th.App.CreateGroupSyncable(model.NewGroupTeam(group.Id, th.BasicTeam.Id, false))
+		require.Nil(t, err)
+	}
+	_, err := th.App.DeleteGroupSyncable(teamUnlinked.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam)
+	require.Nil(t, err)
+	_, err = th.App.DeleteGroupSyncable(channelUnlinked.Id, channel.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, err)
+
+	_, response := th.Client.GetChannelGroupsMissingTeamLink(channel.Id)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	groups, response := th.Client.GetChannelGroupsMissingTeamLink(channel.Id)
+	CheckNoError(t, response)
+	require.Len(t, groups, 2)
+	assert.Equal(t, channelOnly.Id, groups[0].Id)
+	assert.Equal(t, teamUnlinked.Id, groups[1].Id)
+
+	groups, response = th.Client.GetChannelGroupsMissingTeamLink(th.BasicChannel.Id)
+	CheckNoError(t, response)
+	assert.Empty(t, groups)
+
+	th.LoginBasic2()
+	_, response = th.Client.GetChannelGroupsMissingTeamLink(channel.Id)
+	CheckForbiddenStatus(t, response)
+}
+
+func TestGetChannelGroupMembershipConflicts(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	channel := th.BasicPrivateChannel
+
+	createGroup := func() *model.Group {
+		group, err := th.App.CreateGroup(&model.Group{
+			DisplayName: model.NewId(),
+			Name:        model.NewId(),
+			Source:      model.GroupSourceLdap,
+			RemoteId:    model.NewId(),
+		})
+		require.Nil(t, err)
+		return group
+	}
+
+	// BasicUser is in both groups, BasicUser2 in the including group only
+	include := createGroup()
+	exclude := createGroup()
+	_, err := th.App.UpsertGroupMembers(include.Id, []string{th.BasicUser.Id, th.BasicUser2.Id})
+	require.Nil(t, err)
+	_, err = th.App.UpsertGroupMembers(exclude.Id, []string{th.BasicUser.Id})
+	require.Nil(t, err)
+
+	_, response := th.Client.GetChannelGroupMembershipConflicts(channel.Id)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.SystemAdminClient.LinkGroupSyncable(include.Id, channel.Id, model.GroupSyncableTypeChannel, &model.GroupSyncablePatch{AutoAdd: model.NewBool(true)})
+	CheckCreatedStatus(t, response)
+
+	// A link cannot both add and exclude the group's members
+	_, response = th.SystemAdminClient.LinkGroupSyncable(exclude.Id, channel.Id, model.GroupSyncableTypeChannel, &model.GroupSyncablePatch{AutoAdd: model.NewBool(true), ExcludeMembers: model.NewBool(true)})
+	CheckUnprocessableEntityStatus(t, response)
+
+	conflicts, response := th.Client.GetChannelGroupMembershipConflicts(channel.Id)
+	CheckNoError(t, response)
+	assert.Empty(t, conflicts)
+
+	groupSyncable, response := th.SystemAdminClient.LinkGroupSyncable(exclude.Id, channel.Id, model.GroupSyncableTypeChannel, &model.GroupSyncablePatch{ExcludeMembers: model.NewBool(true)})
+	CheckCreatedStatus(t, response)
+	assert.True(t, groupSyncable.ExcludeMembers)
+
+	conflicts, response = th.Client.GetChannelGroupMembershipConflicts(channel.Id)
+	CheckNoError(t, response)
+	assert.Equal(t, []*model.GroupMembershipConflict{{
+		UserId:             th.BasicUser.Id,
+		ExcludedByGroupIds: []string{exclude.Id},
+		IncludedByGroupIds: []string{include.Id},
+	}}, conflicts)
+
+	_, response = th.Client.GetChannelGroupMembershipConflicts(model.NewId())
+	CheckNotFoundStatus(t, response)
+
+	outsider := th.CreateUser()
+	th.LinkUserToTeam(outsider, th.BasicTeam)
+	_, response = th.Client.Login(outsider.Email, outsider.Password)
+	CheckNoError(t, response)
+
+	_, response = th.Client.GetChannelGroupMembershipConflicts(channel.Id)
+	CheckForbiddenStatus(t, response)
+}
+
+func TestPromoteChannelGroupsToTeam(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	createGroup := func() *model.Group {
+		id := model.NewId()
+		group, err := th.App.CreateGroup(&model.Group{
+			DisplayName: "dn_" + id,
+			Name:        "name" + id,
+			Source:      model.GroupSourceLdap,
+			RemoteId:    model.NewId(),
+		})
+		require.Nil(t, err)
+		return group
+	}
+
+	// The first group is only linked to the channel, the second is already linked to the team as well.
+	channelGroup := createGroup()
+	teamGroup := createGroup()
+
+	_, err := th.App.CreateGroupSyncable(model.NewGroupChannel(channelGroup.Id, th.BasicChannel.Id, true))
+	require.Nil(t, err)
+	_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(teamGroup.Id, th.BasicChannel.Id, false))
+	require.Nil(t, err)
+	_, err = th.App.CreateGroupSyncable(model.NewGroupTeam(teamGroup.Id, th.BasicTeam.Id, false))
+	require.Nil(t, err)
+
+	_, response := th.SystemAdminClient.PromoteChannelGroupsToTeam(th.BasicChannel.Id, false)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	// A channel member who cannot manage the team
+	_, response = th.Client.PromoteChannelGroupsToTeam(th.BasicChannel.Id, false)
+	CheckForbiddenStatus(t, response)
+
+	_, response = th.SystemAdminClient.PromoteChannelGroupsToTeam(model.NewId(), false)
+	CheckNotFoundStatus(t, response)
+
+	result, response := th.SystemAdminClient.PromoteChannelGroupsToTeam(th.BasicChannel.Id, true)
+	CheckOKStatus(t, response)
+	assert.Equal(t, &model.GroupPromotionResult{Promoted: 1, Skipped: 1}, result)
+
+	teamSyncable, err := th.App.GetGroupSyncable(channelGroup.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam)
+	require.Nil(t, err)
+	assert.True(t, teamSyncable.AutoAdd)
+	assert.Zero(t, teamSyncable.DeleteAt)
+
+	groups, err := th.App.GetGroupsByChannel(th.BasicChannel.Id, 0, 60, model.GroupSearchOpts{})
+	require.Nil(t, err)
+	assert.Empty(t, groups)
+
+	// The links of a group-constrained channel are kept
+	_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(channelGroup.Id, th.BasicChannel2.Id, true))
+	require.Nil(t, err)
+	channel := th.BasicChannel2
+	channel.GroupConstrained = model.NewBool(true)
+	_, err = th.App.UpdateChannel(channel)
+	require.Nil(t, err)
+
+	_, response = th.SystemAdminClient.PromoteChannelGroupsToTeam(channel.Id, true)
+	CheckBadRequestStatus(t, response)
+
+	result, response = th.SystemAdminClient.PromoteChannelGroupsToTeam(channel.Id, false)
+	CheckOKStatus(t, response)
+	assert.Equal(t, &model.GroupPromotionResult{Promoted: 0, Skipped: 1}, result)
+}
+
+func TestLinkGroupTeamWithDefaultChannel(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "Engineering " + id,
+		Name:        "Engineering." + id,
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, err)
+
+	patch := &model.GroupSyncablePatch{AutoAdd: model.NewBool(true), CreateDefaultChannel: model.NewBool(true)}
+
+	_, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	CheckUnprocessableEntityStatus(t, response)
+
+	groupSyncable, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
+	CheckCreatedStatus(t, response)
+	require.NotEmpty(t, groupSyncable.DefaultChannelId)
+
+	channel, appErr := th.App.GetChannel(groupSyncable.DefaultChannelId)
+	require.Nil(t, appErr)
+	assert.Equal(t, th.BasicTeam.Id, channel.TeamId)
+	assert.Equal(t, model.CHANNEL_PRIVATE, channel.Type)
+	assert.Equal(t, "engineering-"+id, channel.Name)
+	assert.Equal(t, g.DisplayName, channel.DisplayName)
+
+	channelSyncable, appErr := th.App.GetGroupSyncable(g.Id, channel.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, appErr)
+	assert.True(t, channelSyncable.AutoAdd)
+
+	// Linking again reuses the channel and revives its link
+	_, appErr = th.App.DeleteGroupSyncable(g.Id, channel.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, appErr)
+
+	groupSyncable, response = th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
+	CheckCreatedStatus(t, response)
+	assert.Equal(t, channel.Id, groupSyncable.DefaultChannelId)
+
+	channelSyncable, appErr = th.App.GetGroupSyncable(g.Id, channel.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, appErr)
+	assert.Equal(t, int64(0), channelSyncable.DeleteAt)
+
+	// Without the flag no channel is reported
+	groupSyncable, response = th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, &model.GroupSyncablePatch{AutoAdd: model.NewBool(true)})
+	CheckCreatedStatus(t, response)
+	assert.Empty(t, groupSyncable.DefaultChannelId)
+}
+
+func TestLinkGroupChannelByName(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, err)
+
+	patch := &model.GroupSyncablePatch{AutoAdd: model.NewBool(true)}
+
+	th.App.SetLicense(nil)
+
+	_, response := th.SystemAdminClient.LinkGroupChannelByName(g.Id, th.BasicTeam.Id, th.BasicChannel.Name, patch)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.Client.LinkGroupChannelByName(g.Id, th.BasicTeam.Id, th.BasicChannel.Name, patch)
+	CheckForbiddenStatus(t, response)
+
+	_, response = th.SystemAdminClient.LinkGroupChannelByName(g.Id, th.BasicTeam.Id, "missing-"+id, patch)
+	CheckNotFoundStatus(t, response)
+	assert.Equal(t, "api.group.syncable.target_not_found", response.Error.Id)
+
+	groupSyncable, response := th.SystemAdminClient.LinkGroupChannelByName(g.Id, th.BasicTeam.Id, th.BasicChannel.Name, patch)
+	CheckCreatedStatus(t, response)
+	assert.Equal(t, th.BasicChannel.Id, groupSyncable.SyncableId)
+	assert.Equal(t, model.GroupSyncableTypeChannel, groupSyncable.Type)
+	assert.True(t, groupSyncable.AutoAdd)
+
+	// Linking again after unlinking revives the existing link
+	response = th.SystemAdminClient.UnlinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel)
+	CheckOKStatus(t, response)
+
+	revived, response := th.SystemAdminClient.LinkGroupChannelByName(g.Id, th.BasicTeam.Id, th.BasicChannel.Name, &model.GroupSyncablePatch{AutoAdd: model.NewBool(false)})
+	CheckCreatedStatus(t, response)
+	assert.Equal(t, groupSyncable.CreateAt, revived.CreateAt)
+	assert.Equal(t, int64(0), revived.DeleteAt)
+	assert.False(t, revived.AutoAdd)
+}
+
+func TestGetGroupSyncableByName(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, err)
+
+	th.App.SetLicense(nil)
+
+	_, response := th.SystemAdminClient.GetGroupTeamByName(g.Id, th.BasicTeam.Name)
+	CheckNotImplementedStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroupChannelByName(g.Id, th.BasicTeam.Name, th.BasicChannel.Name)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.Client.GetGroupTeamByName(g.Id, th.BasicTeam.Name)
+	CheckForbiddenStatus(t, response)
+
+	_, response = th.Client.GetGroupChannelByName(g.Id, th.BasicTeam.Name, th.BasicChannel.Name)
+	CheckForbiddenStatus(t, response)
+
+	// Not linked yet
+	_, response = th.SystemAdminClient.GetGroupTeamByName(g.Id, th.BasicTeam.Name)
+	CheckNotFoundStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroupChannelByName(g.Id, th.BasicTeam.Name, th.BasicChannel.Name)
+	CheckNotFoundStatus(t, response)
+
+	// Missing targets
+	_, response = th.SystemAdminClient.GetGroupTeamByName(g.Id, "missing-"+id)
+	CheckNotFoundStatus(t, response)
+	assert.Equal(t, "api.group.syncable.named_target_not_found", response.Error.Id)
+
+	_, response = th.SystemAdminClient.GetGroupChannelByName(g.Id, th.BasicTeam.Name, "missing-"+id)
+	CheckNotFoundStatus(t, response)
+	assert.Equal(t, "api.group.syncable.named_target_not_found", response.Error.Id)
+
+	_, err = th.App.CreateGroupSyncable(model.NewGroupTeam(g.Id, th.BasicTeam.Id, true))
+	require.Nil(t, err)
+
+	_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(g.Id, th.BasicChannel.Id, false))
+	require.Nil(t, err)
+
+	teamSyncable, response := th.SystemAdminClient.GetGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, "")
+	CheckOKStatus(t, response)
+
+	byName, response := th.SystemAdminClient.GetGroupTeamByName(g.Id, th.BasicTeam.Name)
+	CheckOKStatus(t, response)
+	assert.Equal(t, teamSyncable, byName)
+
+	channelSyncable, response := th.SystemAdminClient.GetGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, "")
+	CheckOKStatus(t, response)
+
+	byName, response = th.SystemAdminClient.GetGroupChannelByName(g.Id, th.BasicTeam.Name, th.BasicChannel.Name)
+	CheckOKStatus(t, response)
+	assert.Equal(t, channelSyncable, byName)
+}
+
+func TestGetGroupDeleteImpact(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	createGroup := func() *model.Group {
+		id := model.NewId()
+		group, err := th.App.CreateGroup(&model.Group{
+			DisplayName: "dn_" + id,
+			Name:        "name" + id,
+			Source:      model.GroupSourceLdap,
+			RemoteId:    model.NewId(),
+		})
+		require.Nil(t, err)
+		return group
+	}
+
+	g := createGroup()
+	otherGroup := createGroup()
+
+	th.App.SetLicense(nil)
+
+	_, response := th.SystemAdminClient.GetGroupDeleteImpact(g.Id)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.Client.GetGroupDeleteImpact(g.Id)
+	CheckForbiddenStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroupDeleteImpact(model.NewId())
+	CheckNotFoundStatus(t, response)
+
+	impact, response := th.SystemAdminClient.GetGroupDeleteImpact(g.Id)
+	CheckOKStatus(t, response)
+	assert.Equal(t, &model.GroupDeleteImpact{}, impact)
+
+	constrainedChannel := th.BasicChannel
+	constrainedChannel.GroupConstrained = model.NewBool(true)
+	constrainedChannel, err := th.App.UpdateChannel(constrainedChannel)
+	require.Nil(t, err)
+
+	// Links to unconstrained teams and channels do not count.
+	for _, groupSyncable := range []*model.GroupSyncable{
+		model.NewGroupChannel(g.Id, constrainedChannel.Id, true),
+		model.NewGroupChannel(g.Id, th.BasicChannel2.Id, true),
+		model.NewGroupTeam(g.Id, th.BasicTeam.Id, true),
+		model.NewGroupChannel(otherGroup.Id, constrainedChannel.Id, true),
+	} {
+		_, err = th.App.CreateGroupSyncable(groupSyncable)
+		require.Nil(t, err)
+	}
+
+	// BasicUser2 keeps access to the channel through the other group.
+	for _, member := range []struct{ groupID, userID string }{
+		{g.Id, th.BasicUser.Id},
+		{g.Id, th.BasicUser2.Id},
+		{otherGroup.Id, th.BasicUser2.Id},
+	} {
+		_, err = th.App.CreateOrRestoreGroupMember(member.groupID, member.userID)
+		require.Nil(t, err)
+	}
+
+	impact, response = th.SystemAdminClient.GetGroupDeleteImpact(g.Id)
+	CheckOKStatus(t, response)
+	assert.Equal(t, &model.GroupDeleteImpact{
+		ConstrainedTeamCount:    0,
+		ConstrainedChannelCount: 1,
+		AffectedUserCount:       1,
+	}, impact)
+
+	impact, response = th.SystemAdminClient.GetGroupDeleteImpact(otherGroup.Id)
+	CheckOKStatus(t, response)
+	assert.Equal(t, int64(1), impact.ConstrainedChannelCount)
+	assert.Equal(t, int64(0), impact.AffectedUserCount)
+}
+
+func TestGetGroupStats(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, err)
+
+	for _, userID := range []string{th.BasicUser.Id, th.BasicUser2.Id, th.SystemAdminUser.Id} {
+		_, err = th.App.CreateOrRestoreGroupMember(g.Id, userID)
+		require.Nil(t, err)
+	}
+	_, err = th.App.DeleteGroupMember(g.Id, th.SystemAdminUser.Id)
+	require.Nil(t, err)
+
+	th.App.SetLicense(nil)
+
+	_, response := th.SystemAdminClient.GetGroupStats(g.Id, false)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.Client.GetGroupStats(g.Id, false)
+	CheckForbiddenStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroupStats(model.NewId(), false)
+	CheckNotFoundStatus(t, response)
+
+	// Removed members are left out by default.
+	stats, response := th.SystemAdminClient.GetGroupStats(g.Id, false)
+	CheckOKStatus(t, response)
+	assert.Equal(t, g.Id, stats.GroupId)
+	assert.Equal(t, int64(2), stats.TotalMemberCount)
+	assert.Nil(t, stats.DeletedMemberCount)
+
+	stats, response = th.SystemAdminClient.GetGroupStats(g.Id, true)
+	CheckOKStatus(t, response)
+	assert.Equal(t, int64(2), stats.TotalMemberCount)
+	require.NotNil(t, stats.DeletedMemberCount)
+	assert.Equal(t, int64(1), *stats.DeletedMemberCount)
+}
+
+func TestGetGroupChannelAccess(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	group, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, err)
+
+	member := th.CreateUser()
+	nonMember := th.CreateUser()
+	_, err = th.App.CreateOrRestoreGroupMember(group.Id, member.Id)
+	require.Nil(t, err)
+
+	_, response := th.SystemAdminClient.GetGroupChannelAccess(member.Id, group.Id, th.BasicChannel.Id)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.Client.GetGroupChannelAccess(member.Id, group.Id, th.BasicChannel.Id)
+	CheckForbiddenStatus(t, response)
+
+	// Not linked yet
+	access, response := th.SystemAdminClient.GetGroupChannelAccess(member.Id, group.Id, th.BasicChannel.Id)
+	CheckOKStatus(t, response)
+	assert.True(t, access.IsGroupMember)
+	assert.False(t, access.IsLinked)
+	assert.False(t, access.GrantsMembership)
+	assert.False(t, access.SchemeAdmin)
+
+	_, response = th.SystemAdminClient.LinkGroupSyncable(group.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, &model.GroupSyncablePatch{AutoAdd: model.NewBool(true)})
+	CheckCreatedStatus(t, response)
+
+	access, response = th.SystemAdminClient.GetGroupChannelAccess(member.Id, group.Id, th.BasicChannel.Id)
+	CheckOKStatus(t, response)
+	assert.True(t, access.GrantsMembership)
+	assert.False(t, access.SchemeAdmin)
+
+	syncable, response := th.SystemAdminClient.PatchGroupSyncable(group.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, &model.GroupSyncablePatch{SchemeAdmin: model.NewBool(true)})
+	CheckOKStatus(t, response)
+	assert.True(t, syncable.SchemeAdmin)
+	assert.True(t, syncable.AutoAdd)
+
+	access, response = th.SystemAdminClient.GetGroupChannelAccess(member.Id, group.Id, th.BasicChannel.Id)
+	CheckOKStatus(t, response)
+	assert.True(t, access.GrantsMembership)
+	assert.True(t, access.SchemeAdmin)
+
+	// The group grants nothing to users outside it
+	access, response = th.SystemAdminClient.GetGroupChannelAccess(nonMember.Id, group.Id, th.BasicChannel.Id)
+	CheckOKStatus(t, response)
+	assert.False(t, access.IsGroupMember)
+	assert.True(t, access.IsLinked)
+	assert.False(t, access.GrantsMembership)
+	assert.False(t, access.SchemeAdmin)
+
+	_, response = th.SystemAdminClient.GetGroupChannelAccess(member.Id, model.NewId(), th.BasicChannel.Id)
+	CheckNotFoundStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroupChannelAccess(member.Id, group.Id, model.NewId())
+	CheckNotFoundStatus(t, response)
+}
+
+func TestGetUserGroupProvisionedAccess(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	group, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, err)
+
+	_, err = th.App.CreateOrRestoreGroupMember(group.Id, th.BasicUser.Id)
+	require.Nil(t, err)
+
+	_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(group.Id, th.BasicChannel.Id, true))
+	require.Nil(t, err)
+	_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(group.Id, th.BasicChannel2.Id, true))
+	require.Nil(t, err)
+
+	// A link without auto-add gives no access.
+	_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(group.Id, th.BasicPrivateChannel.Id, false))
+	require.Nil(t, err)
+
+	_, response := th.SystemAdminClient.GetUserGroupProvisionedAccess(th.BasicUser.Id)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.Client.GetUserGroupProvisionedAccess(th.BasicUser2.Id)
+	CheckForbiddenStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetUserGroupProvisionedAccess(model.NewId())
+	CheckNotFoundStatus(t, response)
+
+	for _, client := range []*model.Client4{th.Client, th.SystemAdminClient} {
+		access, response := client.GetUserGroupProvisionedAccess(th.BasicUser.Id)
+		CheckOKStatus(t, response)
+		assert.Equal(t, th.BasicUser.Id, access.UserId)
+		assert.Empty(t, access.Teams)
+		require.Len(t, access.Channels, 2)
+
+		channelIds := []string{access.Channels[0].Id, access.Channels[1].Id}
+		assert.ElementsMatch(t, []string{th.BasicChannel.Id, th.BasicChannel2.Id}, channelIds)
+		for _, channel := range access.Channels {
+			assert.Equal(t, th.BasicTeam.Id, channel.TeamId)
+			assert.Equal(t, []string{group.Id}, channel.GroupIds)
+		}
+	}
+
+	// Users outside the group are given nothing.
+	access, response := th.SystemAdminClient.GetUserGroupProvisionedAccess(th.BasicUser2.Id)
+	CheckOKStatus(t, response)
+	assert.Empty(t, access.Teams)
+	assert.Empty(t, access.Channels)
+}
+
+func TestLinkGroupSyncableMetrics(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, err)
+
+	metricsMock := &mocks.MetricsInterface{}
+	metricsMock.On("IncrementGroupLinkCounter", "team").Return()
+	metricsMock.On("IncrementGroupLinkCounter", "channel").Return()
+
+	// Tolerate the request and cache metrics recorded along the way.
+	metricsType := reflect.TypeOf((*einterfaces.MetricsInterface)(nil)).Elem()
+	for i := 0; i < metricsType.NumMethod(); i++ {
+		method := metricsType.Method(i)
+		args := make([]interface{}, method.Type.NumIn())
+		for j := range args {
+			args[j] = mock.Anything
+		}
+		metricsMock.On(method.Name, args...).Return().Maybe()
+	}
+
+	th.App.Srv.Metrics = metricsMock
+	defer func() { th.App.Srv.Metrics = nil }()
+
+	patch := &model.GroupSyncablePatch{AutoAdd: model.NewBool(true)}
+
+	_, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
+	CheckCreatedStatus(t, response)
+	metricsMock.AssertCalled(t, "IncrementGroupLinkCounter", "team")
+	metricsMock.AssertNotCalled(t, "IncrementGroupLinkCounter", "channel")
+
+	_, response = th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	CheckCreatedStatus(t, response)
+	metricsMock.AssertCalled(t, "IncrementGroupLinkCounter", "channel")
+
+	_, response = th.Client.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
+	CheckForbiddenStatus(t, response)
+	metricsMock.AssertNumberOfCalls(t, "IncrementGroupLinkCounter", 2)
+}
+
+func TestLinkGroupSyncableIdempotencyKey(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		Description: "description_" + id,
+		RemoteId:    model.NewId(),
+	})
+	assert.Nil(t, err)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	th.SystemAdminClient.HttpHeader = map[string]string{model.HEADER_IDEMPOTENCY_KEY: model.NewId()}
+	defer func() { th.SystemAdminClient.HttpHeader = nil }()
+
+	groupTeam1, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, &model.GroupSyncablePatch{AutoAdd: model.NewBool(true)})
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+
+	// The retried request is answered from the recorded response rather than being re-executed.
+	groupTeam2, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, &model.GroupSyncablePatch{AutoAdd: model.NewBool(true)})
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	assert.Equal(t, groupTeam1.UpdateAt, groupTeam2.UpdateAt)
+	assert.True(t, groupTeam2.AutoAdd)
+
+	// Reusing the key with a different body is rejected.
+	_, response = th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, &model.GroupSyncablePatch{AutoAdd: model.NewBool(false)})
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+
+	groupTeams, response := th.SystemAdminClient.GetGroupSyncables(g.Id, model.GroupSyncableTypeTeam, "")
+	CheckOKStatus(t, response)
+	assert.Len(t, groupTeams, 1)
+	assert.True(t, groupTeams[0].AutoAdd)
+
+	// A new key executes the request again.
+	th.SystemAdminClient.HttpHeader = map[string]string{model.HEADER_IDEMPOTENCY_KEY: model.NewId()}
+	groupTeam3, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, &model.GroupSyncablePatch{AutoAdd: model.NewBool(false)})
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	assert.False(t, groupTeam3.AutoAdd)
+}
+
+func TestUnlinkGroupTeam(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		Description: "description_" + id,
+		RemoteId:    model.NewId(),
+	})
+	assert.Nil(t, err)
+
+	patch := &model.GroupSyncablePatch{
+		AutoAdd: model.NewBool(true),
+	}
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+
+	th.App.SetLicense(nil)
+
+	response = th.Client.UnlinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam)
+	CheckNotImplementedStatus(t, response)
+
+	response = th.SystemAdminClient.UnlinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	response = th.SystemAdminClient.UnlinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam)
+	CheckOKStatus(t, response)
+
+	syncable, appErr := th.App.GetGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam)
+	require.Nil(t, appErr)
+	require.NotZero(t, syncable.DeleteAt)
+
+	// Unlinking again is safe to retry and keeps the original deletion time
+	response = th.SystemAdminClient.UnlinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam)
+	CheckOKStatus(t, response)
+
+	retried, appErr := th.App.GetGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam)
+	require.Nil(t, appErr)
+	assert.Equal(t, syncable.DeleteAt, retried.DeleteAt)
+
+	response = th.SystemAdminClient.UnlinkGroupSyncable(g.Id, model.NewId(), model.GroupSyncableTypeTeam)
+	CheckNotFoundStatus(t, response)
+}
+
+func TestUnlinkGroupChannel(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		Description: "description_" + id,
+		RemoteId:    model.NewId(),
+	})
+	assert.Nil(t, err)
+
+	patch := &model.GroupSyncablePatch{
+		AutoAdd: model.NewBool(true),
+	}
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+
+	th.App.SetLicense(nil)
+
+	response = th.Client.UnlinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel)
+	CheckNotImplementedStatus(t, response)
+
+	response = th.SystemAdminClient.UnlinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	response = th.SystemAdminClient.UnlinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel)
+	CheckOKStatus(t, response)
+}
+
+func TestUnlinkGroupChannels(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	createGroup := func() *model.Group {
+		id := model.NewId()
+		group, err := th.App.CreateGroup(&model.Group{
+			DisplayName: "dn_" + id,
+			Name:        "name" + id,
+			Source:      model.GroupSourceLdap,
+			Description: "description_" + id,
+			RemoteId:    model.NewId(),
+		})
+		assert.Nil(t, err)
+		return group
+	}
+
+	g := createGroup()
+	unlinkedGroup := createGroup()
+
+	constrainedChannel := th.CreatePublicChannel()
+	constrainedChannel.GroupConstrained = model.NewBool(true)
+	constrainedChannel, err := th.App.UpdateChannel(constrainedChannel)
+	assert.Nil(t, err)
+
+	for _, channel := range []*model.Channel{th.BasicChannel, constrainedChannel} {
+		_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(g.Id, channel.Id, true))
+		assert.Nil(t, err)
+	}
+
+	// A group that was linked to the constrained channel and later unlinked does not count towards its groups.
+	_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(unlinkedGroup.Id, constrainedChannel.Id, true))
+	assert.Nil(t, err)
+	_, err = th.App.DeleteGroupSyncable(unlinkedGroup.Id, constrainedChannel.Id, model.GroupSyncableTypeChannel)
+	assert.Nil(t, err)
+
+	missingChannelId := model.NewId()
+	channelIds := []string{th.BasicChannel.Id, th.BasicChannel2.Id, constrainedChannel.Id, missingChannelId}
+
+	_, response := th.SystemAdminClient.UnlinkGroupChannels(g.Id, channelIds)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.SystemAdminClient.UnlinkGroupChannels(g.Id, []string{})
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.SystemAdminClient.UnlinkGroupChannels(g.Id, []string{"asdfasdf"})
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.SystemAdminClient.UnlinkGroupChannels(model.NewId(), channelIds)
+	CheckNotFoundStatus(t, response)
+
+	statuses, response := th.SystemAdminClient.UnlinkGroupChannels(g.Id, channelIds)
+	CheckOKStatus(t, response)
+	require.Len(t, statuses, 4)
+
+	assert.Equal(t, th.BasicChannel.Id, statuses[0].SyncableId)
+	assert.Equal(t, model.GroupSyncableStatusUnlinked, statuses[0].Status)
+
+	assert.Equal(t, th.BasicChannel2.Id, statuses[1].SyncableId)
+	assert.Equal(t, model.GroupSyncableStatusNotLinked, statuses[1].Status)
+	assert.Nil(t, statuses[1].Error)
+
+	assert.Equal(t, constrainedChannel.Id, statuses[2].SyncableId)
+	assert.Equal(t, model.GroupSyncableStatusLastGroup, statuses[2].Status)
+
+	assert.Equal(t, missingChannelId, statuses[3].SyncableId)
+	assert.Equal(t, model.GroupSyncableStatusNotFound, statuses[3].Status)
+	assert.Nil(t, statuses[3].Error)
+
+	syncable, err := th.App.GetGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel)
+	assert.Nil(t, err)
+	assert.NotZero(t, syncable.DeleteAt)
+
+	syncable, err = th.App.GetGroupSyncable(g.Id, constrainedChannel.Id, model.GroupSyncableTypeChannel)
+	assert.Nil(t, err)
+	assert.Zero(t, syncable.DeleteAt)
+
+	// The previously unlinked group is reported as not linked rather than as the last group.
+	statuses, response = th.SystemAdminClient.UnlinkGroupChannels(unlinkedGroup.Id, []string{constrainedChannel.Id})
+	CheckOKStatus(t, response)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, model.GroupSyncableStatusNotLinked, statuses[0].Status)
+
+	// A user who is not a system admin unlinks the channels whose members they manage, and only those.
+	publicChannel := th.CreatePublicChannel()
+	privateChannel := th.CreateChannelWithClient(th.SystemAdminClient, model.CHANNEL_PRIVATE)
+	for _, channel := range []*model.Channel{publicChannel, privateChannel} {
+		_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(g.Id, channel.Id, true))
+		assert.Nil(t, err)
+	}
+
+	statuses, response = th.Client.UnlinkGroupChannels(g.Id, []string{publicChannel.Id, privateChannel.Id})
+	CheckOKStatus(t, response)
+	require.Len(t, statuses, 2)
+	assert.Equal(t, model.GroupSyncableStatusUnlinked, statuses[0].Status)
+	assert.Equal(t, model.GroupSyncableStatusForbidden, statuses[1].Status)
+
+	syncable, err = th.App.GetGroupSyncable(g.Id, privateChannel.Id, model.GroupSyncableTypeChannel)
+	assert.Nil(t, err)
+	assert.Zero(t, syncable.DeleteAt)
+
+	// Probing a private channel the user cannot see tells them no more than probing a channel that does not exist,
+	// and neither reveals whether the group exists.
+	for _, groupId := range []string{g.Id, model.NewId()} {
+		statuses, response = th.Client.UnlinkGroupChannels(groupId, []string{privateChannel.Id, missingChannelId})
+		CheckOKStatus(t, response)
+		require.Len(t, statuses, 2)
+		assert.Equal(t, privateChannel.Id, statuses[0].SyncableId)
+		assert.Equal(t, model.GroupSyncableStatusForbidden, statuses[0].Status)
+		assert.Equal(t, missingChannelId, statuses[1].SyncableId)
+		assert.Equal(t, model.GroupSyncableStatusForbidden, statuses[1].Status)
+	}
+}
+
+func TestGetGroupTeam(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		Description: "description_" + id,
+		RemoteId:    model.NewId(),
+	})
+	assert.Nil(t, err)
+
+	_, response := th.Client.GetGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, "")
+	CheckNotImplementedStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, "")
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	patch := &model.GroupSyncablePatch{
+		AutoAdd: model.NewBool(true),
+	}
+
+	_, response = th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+
+	groupSyncable, response := th.SystemAdminClient.GetGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, "")
+	CheckOKStatus(t, response)
+	assert.NotNil(t, groupSyncable)
+
+	assert.Equal(t, g.Id, groupSyncable.GroupId)
+	assert.Equal(t, th.BasicTeam.Id, groupSyncable.SyncableId)
+	assert.Equal(t, *patch.AutoAdd, groupSyncable.AutoAdd)
+
+	_, response = th.SystemAdminClient.GetGroupSyncable(model.NewId(), th.BasicTeam.Id, model.GroupSyncableTypeTeam, "")
+	CheckNotFoundStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroupSyncable(g.Id, model.NewId(), model.GroupSyncableTypeTeam, "")
+	CheckNotFoundStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroupSyncable("asdfasdfe3", th.BasicTeam.Id, model.GroupSyncableTypeTeam, "")
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroupSyncable(g.Id, "asdfasdfe3", model.GroupSyncableTypeTeam, "")
+	CheckBadRequestStatus(t, response)
+
+	th.SystemAdminClient.Logout()
+	_, response = th.SystemAdminClient.GetGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, "")
+	CheckUnauthorizedStatus(t, response)
+}
+
+func TestGetGroupChannel(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		Description: "description_" + id,
+		RemoteId:    model.NewId(),
+	})
+	assert.Nil(t, err)
+
+	_, response := th.Client.GetGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, "")
+	CheckNotImplementedStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, "")
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	patch := &model.GroupSyncablePatch{
+		AutoAdd: model.NewBool(true),
+	}
+
+	_, response = th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+
+	groupSyncable, response := th.SystemAdminClient.GetGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, "")
+	CheckOKStatus(t, response)
+	assert.NotNil(t, groupSyncable)
+
+	assert.Equal(t, g.Id, groupSyncable.GroupId)
+	assert.Equal(t, th.BasicChannel.Id, groupSyncable.SyncableId)
+	assert.Equal(t, *patch.AutoAdd, groupSyncable.AutoAdd)
+
+	_, response = th.SystemAdminClient.GetGroupSyncable(model.NewId(), th.BasicChannel.Id, model.GroupSyncableTypeChannel, "")
+	CheckNotFoundStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroupSyncable(g.Id, model.NewId(), model.GroupSyncableTypeChannel, "")
+	CheckNotFoundStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroupSyncable("asdfasdfe3", th.BasicChannel.Id, model.GroupSyncableTypeChannel, "")
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroupSyncable(g.Id, "asdfasdfe3", model.GroupSyncableTypeChannel, "")
+	CheckBadRequestStatus(t, response)
+
+	th.SystemAdminClient.Logout()
+	_, response = th.SystemAdminClient.GetGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, "")
+	CheckUnauthorizedStatus(t, response)
+}
+
+func TestGetGroupTeams(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		Description: "description_" + id,
+		RemoteId:    model.NewId(),
+	})
+	assert.Nil(t, err)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	patch := &model.GroupSyncablePatch{
+		AutoAdd: model.NewBool(true),
+	}
+
+	for i := 0; i < 10; i++ {
+		team := th.CreateTeam()
+		_, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, team.Id, model.GroupSyncableTypeTeam, patch)
+		assert.Equal(t, http.StatusCreated, response.StatusCode)
+	}
+
+	th.App.SetLicense(nil)
+
+	_, response := th.Client.GetGroupSyncables(g.Id, model.GroupSyncableTypeTeam, "")
+	CheckNotImplementedStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroupSyncables(g.Id, model.GroupSyncableTypeTeam, "")
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.Client.GetGroupSyncables(g.Id, model.GroupSyncableTypeTeam, "")
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+	groupSyncables, response := th.SystemAdminClient.GetGroupSyncables(g.Id, model.GroupSyncableTypeTeam, "")
+	CheckOKStatus(t, response)
+
+	assert.Len(t, groupSyncables, 10)
+
+	th.SystemAdminClient.Logout()
+	_, response = th.SystemAdminClient.GetGroupSyncables(g.Id, model.GroupSyncableTypeTeam, "")
+	CheckUnauthorizedStatus(t, response)
+}
+
+func TestGetGroupChannels(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		Description: "description_" + id,
+		RemoteId:    model.NewId(),
+	})
+	assert.Nil(t, err)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	patch := &model.GroupSyncablePatch{
+		AutoAdd: model.NewBool(true),
+	}
+
+	for i := 0; i < 10; i++ {
+		channel := th.CreatePublicChannel()
+		_, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, channel.Id, model.GroupSyncableTypeChannel, patch)
+		assert.Equal(t, http.StatusCreated, response.StatusCode)
+	}
+
+	th.App.SetLicense(nil)
+
+	_, response := th.Client.GetGroupSyncables(g.Id, model.GroupSyncableTypeChannel, "")
+	CheckNotImplementedStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroupSyncables(g.Id, model.GroupSyncableTypeChannel, "")
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.Client.GetGroupSyncables(g.Id, model.GroupSyncableTypeChannel, "")
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+	groupSyncables, response := th.SystemAdminClient.GetGroupSyncables(g.Id, model.GroupSyncableTypeChannel, "")
+	CheckOKStatus(t, response)
+
+	assert.Len(t, groupSyncables, 10)
+
+	th.SystemAdminClient.Logout()
+	_, response = th.SystemAdminClient.GetGroupSyncables(g.Id, model.GroupSyncableTypeChannel, "")
+	CheckUnauthorizedStatus(t, response)
+}
+
+func TestGetGroupChannelsArchived(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, err)
+
+	channel := th.CreatePublicChannel()
+	_, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, channel.Id, model.GroupSyncableTypeChannel, &model.GroupSyncablePatch{AutoAdd: model.NewBool(true)})
+	CheckCreatedStatus(t, response)
+
+	err = th.App.DeleteChannel(channel, th.SystemAdminUser.Id)
+	require.Nil(t, err)
+
+	// The archived channel still lists its groups
+	groups, response := th.SystemAdminClient.GetGroupsByChannel(channel.Id, 0, 60)
+	CheckOKStatus(t, response)
+	require.Len(t, groups, 1)
+	assert.Equal(t, g.Id, groups[0].Id)
+
+	groupSyncables, response := th.SystemAdminClient.GetGroupSyncables(g.Id, model.GroupSyncableTypeChannel, "")
+	CheckOKStatus(t, response)
+	assert.Empty(t, groupSyncables)
+
+	groupSyncables, response = th.SystemAdminClient.GetGroupChannelsIncludingArchived(g.Id, "")
+	CheckOKStatus(t, response)
+	require.Len(t, groupSyncables, 1)
+	assert.NotZero(t, groupSyncables[0].ChannelDeleteAt)
+}
+
+func TestUpdateGroupSyncable(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, err)
+
+	settings := &model.GroupSyncable{SchemeAdmin: true}
+
+	_, response := th.SystemAdminClient.UpdateGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, settings)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.Client.UpdateGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, settings)
+	CheckForbiddenStatus(t, response)
+
+	// The link does not exist yet, and is not created
+	_, response = th.SystemAdminClient.UpdateGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, settings)
+	CheckNotFoundStatus(t, response)
+	assert.Equal(t, "api.group.syncable.not_linked.app_error", response.Error.Id)
+
+	_, err = th.App.GetGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam)
+	require.NotNil(t, err)
+
+	_, err = th.App.CreateGroupSyncable(model.NewGroupTeam(g.Id, th.BasicTeam.Id, true))
+	require.Nil(t, err)
+
+	// The body replaces every setting, so the omitted auto_add is turned off
+	groupSyncable, response := th.SystemAdminClient.UpdateGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, settings)
+	CheckOKStatus(t, response)
+	assert.False(t, groupSyncable.AutoAdd)
+	assert.True(t, groupSyncable.SchemeAdmin)
+
+	// An unlinked group is not restored
+	_, err = th.App.DeleteGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam)
+	require.Nil(t, err)
+
+	_, response = th.SystemAdminClient.UpdateGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, settings)
+	CheckNotFoundStatus(t, response)
+
+	groupSyncable, err = th.App.GetGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam)
+	require.Nil(t, err)
+	assert.NotZero(t, groupSyncable.DeleteAt)
+}
+
+func TestPatchGroupTeam(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		Description: "description_" + id,
+		RemoteId:    model.NewId(),
+	})
+	assert.Nil(t, err)
+
+	patch := &model.GroupSyncablePatch{
+		AutoAdd: model.NewBool(true),
+	}
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	groupSyncable, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	assert.NotNil(t, groupSyncable)
+	assert.True(t, groupSyncable.AutoAdd)
+
+	_, response = th.Client.PatchGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+	th.App.SetLicense(nil)
+
+	_, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	patch.AutoAdd = model.NewBool(false)
+	groupSyncable, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
+	CheckOKStatus(t, response)
+	assert.False(t, groupSyncable.AutoAdd)
+
+	assert.Equal(t, g.Id, groupSyncable.GroupId)
+	assert.Equal(t, th.BasicTeam.Id, groupSyncable.SyncableId)
+	assert.Equal(t, model.GroupSyncableTypeTeam, groupSyncable.Type)
+
+	patch.AutoAdd = model.NewBool(true)
+	groupSyncable, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
+	CheckOKStatus(t, response)
+
+	_, response = th.SystemAdminClient.PatchGroupSyncable(model.NewId(), th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
+	CheckNotFoundStatus(t, response)
+
+	_, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, model.NewId(), model.GroupSyncableTypeTeam, patch)
+	CheckNotFoundStatus(t, response)
+
+	_, response = th.SystemAdminClient.PatchGroupSyncable("abc", th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, "abc", model.GroupSyncableTypeTeam, patch)
+	CheckBadRequestStatus(t, response)
+
+	th.SystemAdminClient.Logout()
+	_, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam, patch)
+	CheckUnauthorizedStatus(t, response)
+}
+
+func TestPatchGroupChannel(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	g, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		Description: "description_" + id,
+		RemoteId:    model.NewId(),
+	})
+	assert.Nil(t, err)
+
+	patch := &model.GroupSyncablePatch{
+		AutoAdd: model.NewBool(true),
+	}
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	groupSyncable, response := th.SystemAdminClient.LinkGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	assert.NotNil(t, groupSyncable)
+	assert.True(t, groupSyncable.AutoAdd)
+
+	_, response = th.Client.PatchGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+	th.App.SetLicense(nil)
+
+	_, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	patch.AutoAdd = model.NewBool(false)
+	groupSyncable, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	CheckOKStatus(t, response)
+	assert.False(t, groupSyncable.AutoAdd)
+
+	assert.Equal(t, g.Id, groupSyncable.GroupId)
+	assert.Equal(t, th.BasicChannel.Id, groupSyncable.SyncableId)
+	assert.Equal(t, model.GroupSyncableTypeChannel, groupSyncable.Type)
+
+	patch.AutoAdd = model.NewBool(true)
+	groupSyncable, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	CheckOKStatus(t, response)
+
+	_, response = th.SystemAdminClient.PatchGroupSyncable(model.NewId(), th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	CheckNotFoundStatus(t, response)
+
+	_, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, model.NewId(), model.GroupSyncableTypeChannel, patch)
+	CheckNotFoundStatus(t, response)
+
+	_, response = th.SystemAdminClient.PatchGroupSyncable("abc", th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, "abc", model.GroupSyncableTypeChannel, patch)
+	CheckBadRequestStatus(t, response)
+
+	th.SystemAdminClient.Logout()
+	_, response = th.SystemAdminClient.PatchGroupSyncable(g.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel, patch)
+	CheckUnauthorizedStatus(t, response)
+}
+
+func TestGetGroupsByChannel(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	group, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		Description: "description_" + id,
+		RemoteId:    model.NewId(),
+	})
+	assert.Nil(t, err)
+
+	_, err = th.App.CreateGroupSyncable(&model.GroupSyncable{
+		AutoAdd:    true,
+		SyncableId: th.BasicChannel.Id,
+		Type:       model.GroupSyncableTypeChannel,
+		GroupId:    group.Id,
+	})
+	assert.Nil(t, err)
+
+	_, response := th.SystemAdminClient.GetGroupsByChannel("asdfasdf", 0, 60)
+	CheckBadRequestStatus(t, response)
+
+	th.App.SetLicense(nil)
+
+	_, response = th.SystemAdminClient.GetGroupsByChannel(th.BasicChannel.Id, 0, 60)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.Client.GetGroupsByChannel(th.BasicChannel.Id, 0, 60)
+	CheckForbiddenStatus(t, response)
+
+	groups, response := th.SystemAdminClient.GetGroupsByChannel(th.BasicChannel.Id, 0, 60)
+	assert.Nil(t, response.Error)
+	assert.ElementsMatch(t, []*model.Group{group}, groups)
+
+	groups, response = th.SystemAdminClient.GetGroupsByChannel(model.NewId(), 0, 60)
+	assert.Nil(t, response.Error)
+	assert.Empty(t, groups)
+}
+
+func TestGetGroupsByChannelIncludingTeamGroups(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	createGroup := func(displayName string) *model.Group {
+		group, err := th.App.CreateGroup(&model.Group{
+			DisplayName: displayName,
+			Name:        "name" + model.NewId(),
+			Source:      model.GroupSourceLdap,
+			RemoteId:    model.NewId(),
+		})
+		require.Nil(t, err)
+		return group
+	}
+	link := func(group *model.Group, syncableId string, syncableType model.GroupSyncableType) {
+		_, err := th.App.CreateGroupSyncable(&model.GroupSyncable{
+			AutoAdd:    true,
+			SyncableId: syncableId,
+			Type:       syncableType,
+			GroupId:    group.Id,
+		})
+		require.Nil(t, err)
+	}
+
+	channelGroup := createGroup("a-channel")
+	teamGroup := createGroup("b-team")
+	bothGroup := createGroup("c-both")
+
+	link(channelGroup, th.BasicChannel.Id, model.GroupSyncableTypeChannel)
+	link(teamGroup, th.BasicTeam.Id, model.GroupSyncableTypeTeam)
+	link(bothGroup, th.BasicChannel.Id, model.GroupSyncableTypeChannel)
+	link(bothGroup, th.BasicTeam.Id, model.GroupSyncableTypeTeam)
+
+	groups, response := th.SystemAdminClient.GetGroupsByChannel(th.BasicChannel.Id, 0, 60)
+	CheckOKStatus(t, response)
+	require.Len(t, groups, 2)
+	for _, group := range groups {
+		assert.False(t, group.ViaTeam)
+	}
+
+	groups, response = th.SystemAdminClient.GetGroupsByChannelWithOptions(th.BasicChannel.Id, 0, 60, model.GroupSearchOpts{IncludeTeamGroups: true})
+	CheckOKStatus(t, response)
+	require.Len(t, groups, 3)
+	assert.Equal(t, channelGroup.Id, groups[0].Id)
+	assert.False(t, groups[0].ViaTeam)
+	assert.Equal(t, teamGroup.Id, groups[1].Id)
+	assert.True(t, groups[1].ViaTeam)
+	assert.Equal(t, bothGroup.Id, groups[2].Id)
+	assert.False(t, groups[2].ViaTeam)
+
+	groups, response = th.SystemAdminClient.GetGroupsByChannelWithOptions(th.BasicChannel.Id, 1, 1, model.GroupSearchOpts{IncludeTeamGroups: true})
+	CheckOKStatus(t, response)
+	require.Len(t, groups, 1)
+	assert.Equal(t, teamGroup.Id, groups[0].Id)
+}
+
+func TestGroupListEnvelope(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	id := model.NewId()
+	group, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, err)
+
+	for _, syncable := range []*model.GroupSyncable{
+		{GroupId: group.Id, SyncableId: th.BasicTeam.Id, Type: model.GroupSyncableTypeTeam, AutoAdd: true},
+		{GroupId: group.Id, SyncableId: th.BasicChannel.Id, Type: model.GroupSyncableTypeChannel, AutoAdd: true},
+	} {
+		_, err = th.App.CreateGroupSyncable(syncable)
+		require.Nil(t, err)
+	}
+
+	_, err = th.App.CreateOrRestoreGroupMember(group.Id, th.BasicUser.Id)
+	require.Nil(t, err)
+
+	for _, route := range []string{
+		th.SystemAdminClient.GetGroupsRoute(),
+		th.SystemAdminClient.GetTeamRoute(th.BasicTeam.Id) + "/groups",
+		th.SystemAdminClient.GetChannelRoute(th.BasicChannel.Id) + "/groups",
+		th.SystemAdminClient.GetGroupRoute(group.Id) + "/members",
+	} {
+		t.Run(route, func(t *testing.T) {
+			r, appErr := th.SystemAdminClient.DoApiGet(route+"?page=0&per_page=10&envelope=true", "")
+			require.Nil(t, appErr)
+			defer r.Body.Close()
+
+			var envelope struct {
+				Data    []map[string]interface{} `json:"data"`
+				Page    *int                     `json:"page"`
+				PerPage *int                     `json:"per_page"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&envelope))
+			require.NotNil(t, envelope.Page)
+			assert.Equal(t, 0, *envelope.Page)
+			require.NotNil(t, envelope.PerPage)
+			assert.Equal(t, 10, *envelope.PerPage)
+			assert.NotEmpty(t, envelope.Data)
+		})
+	}
+
+	// The legacy shapes are unchanged without the envelope
+	groups, response := th.SystemAdminClient.GetGroupsByTeam(th.BasicTeam.Id, 0, 10)
+	CheckOKStatus(t, response)
+	require.Len(t, groups, 1)
+	assert.Equal(t, group.Id, groups[0].Id)
+
+	r, appErr := th.SystemAdminClient.DoApiGet(th.SystemAdminClient.GetGroupRoute(group.Id)+"/members", "")
+	require.Nil(t, appErr)
+	defer r.Body.Close()
+
+	var members struct {
+		Members []*model.User `json:"members"`
+		Count   int           `json:"total_member_count"`
+	}
+	require.NoError(t, json.NewDecoder(r.Body).Decode(&members))
+	require.Len(t, members.Members, 1)
+	assert.Equal(t, 1, members.Count)
+}
+
+func TestGetGroupsByTeam(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	group, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		Description: "description_" + id,
+		RemoteId:    model.NewId(),
+	})
+	assert.Nil(t, err)
+
+	_, err = th.App.CreateGroupSyncable(&model.GroupSyncable{
+		AutoAdd:    true,
+		SyncableId: th.BasicTeam.Id,
+		Type:       model.GroupSyncableTypeTeam,
+		GroupId:    group.Id,
+	})
+	assert.Nil(t, err)
+
+	_, response := th.SystemAdminClient.GetGroupsByTeam("asdfasdf", 0, 60)
+	CheckBadRequestStatus(t, response)
+
+	th.App.SetLicense(nil)
+
+	_, response = th.SystemAdminClient.GetGroupsByTeam(th.BasicTeam.Id, 0, 60)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.Client.GetGroupsByTeam(th.BasicTeam.Id, 0, 60)
+	CheckForbiddenStatus(t, response)
+
+	groups, response := th.SystemAdminClient.GetGroupsByTeam(th.BasicTeam.Id, 0, 60)
+	assert.Nil(t, response.Error)
+	assert.ElementsMatch(t, []*model.Group{group}, groups)
+
+	groups, response = th.SystemAdminClient.GetGroupsByTeam(model.NewId(), 0, 60)
+	assert.Nil(t, response.Error)
+	assert.Empty(t, groups)
+
+	// Only groups whose team link adds members automatically are returned with filter_auto_add.
+	id = model.NewId()
+	manualGroup, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		Description: "description_" + id,
+		RemoteId:    model.NewId(),
+	})
+	assert.Nil(t, err)
+
+	_, err = th.App.CreateGroupSyncable(model.NewGroupTeam(manualGroup.Id, th.BasicTeam.Id, false))
+	assert.Nil(t, err)
+
+	groups, response = th.SystemAdminClient.GetGroupsByTeam(th.BasicTeam.Id, 0, 60)
+	assert.Nil(t, response.Error)
+	assert.ElementsMatch(t, []*model.Group{group, manualGroup}, groups)
+
+	groups, response = th.SystemAdminClient.GetGroupsByTeamWithOptions(th.BasicTeam.Id, 0, 60, model.GroupSearchOpts{FilterAutoAdd: true})
+	assert.Nil(t, response.Error)
+	assert.ElementsMatch(t, []*model.Group{group}, groups)
+
+	// A group linked only to a channel of the team is listed with include_channel_groups, flagged as such.
+	id = model.NewId()
+	channelGroup, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		Description: "description_" + id,
+		RemoteId:    model.NewId(),
+	})
+	assert.Nil(t, err)
+
+	_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(channelGroup.Id, th.BasicChannel.Id, true))
+	assert.Nil(t, err)
+
+	groups, response = th.SystemAdminClient.GetGroupsByTeam(th.BasicTeam.Id, 0, 60)
+	assert.Nil(t, response.Error)
+	assert.Len(t, groups, 2)
+
+	th.LoginTeamAdmin()
+	groups, response = th.Client.GetGroupsByTeamWithOptions(th.BasicTeam.Id, 0, 60, model.GroupSearchOpts{IncludeChannelGroups: true})
+	assert.Nil(t, response.Error)
+	assert.Len(t, groups, 3)
+
+	viaChannel := map[string]bool{}
+	for _, g := range groups {
+		viaChannel[g.Id] = g.ViaChannel
+	}
+	assert.Equal(t, map[string]bool{group.Id: false, manualGroup.Id: false, channelGroup.Id: true}, viaChannel)
+}
+
+func TestGetGroups(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	createGroup := func() *model.Group {
+		id := model.NewId()
+		group, err := th.App.CreateGroup(&model.Group{
+			DisplayName: "dn_" + id,
+			Name:        "name" + id,
+			Source:      model.GroupSourceLdap,
+			Description: "description_" + id,
+			RemoteId:    model.NewId(),
+		})
+		assert.Nil(t, err)
+		return group
+	}
+
+	teamGroup := createGroup()
+	channelGroup := createGroup()
+	unlinkedGroup := createGroup()
+
+	for _, group := range []*model.Group{teamGroup, channelGroup} {
+		_, err := th.App.CreateGroupSyncable(&model.GroupSyncable{
+			AutoAdd:    true,
+			SyncableId: th.BasicTeam.Id,
+			Type:       model.GroupSyncableTypeTeam,
+			GroupId:    group.Id,
+		})
+		assert.Nil(t, err)
+	}
+
+	_, err := th.App.CreateGroupSyncable(&model.GroupSyncable{
+		AutoAdd:    true,
+		SyncableId: th.BasicChannel.Id,
+		Type:       model.GroupSyncableTypeChannel,
+		GroupId:    channelGroup.Id,
+	})
+	assert.Nil(t, err)
+
+	opts := model.GroupSearchOpts{FilterParentTeamPermitted: &th.BasicTeam.Id}
+
+	th.App.SetLicense(nil)
+
+	_, response := th.SystemAdminClient.GetGroups(opts, 0, 60)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.Client.GetGroups(opts, 0, 60)
+	CheckForbiddenStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroups(model.GroupSearchOpts{FilterParentTeamPermitted: model.NewString("asdfasdf")}, 0, 60)
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroups(model.GroupSearchOpts{NotAssociatedToTeam: "asdfasdf"}, 0, 60)
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroups(model.GroupSearchOpts{NotAssociatedToChannel: "asdfasdf"}, 0, 60)
+	CheckBadRequestStatus(t, response)
+
+	_, appErr := th.SystemAdminClient.DoApiGet("/groups?is_linked=true", "")
+	require.NotNil(t, appErr)
+	assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+
+	groups, response := th.SystemAdminClient.GetGroups(opts, 0, 60)
+	CheckOKStatus(t, response)
+	assert.ElementsMatch(t, []*model.Group{teamGroup, channelGroup}, groups)
+
+	opts.NotAssociatedToChannel = th.BasicChannel.Id
+	groups, response = th.SystemAdminClient.GetGroups(opts, 0, 60)
+	CheckOKStatus(t, response)
+	assert.ElementsMatch(t, []*model.Group{teamGroup}, groups)
+
+	groups, response = th.SystemAdminClient.GetGroups(model.GroupSearchOpts{Q: unlinkedGroup.Name}, 0, 60)
+	CheckOKStatus(t, response)
+	assert.ElementsMatch(t, []*model.Group{unlinkedGroup}, groups)
+
+	groups, response = th.SystemAdminClient.GetGroups(model.GroupSearchOpts{FilterParentTeamPermitted: model.NewString(model.NewId())}, 0, 60)
+	CheckOKStatus(t, response)
+	assert.Empty(t, groups)
+}
+
+func TestGetGroupsExcludeDefault(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	prefix := model.NewId()
+
+	// Only the system creates default groups
+	defaultGroup, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + prefix,
+		Name:        prefix + "default",
+		Source:      model.GroupSourceCustom,
+		RemoteId:    model.NewId(),
+		IsDefault:   true,
+	})
+	require.Nil(t, err)
+
+	userGroup, response := th.SystemAdminClient.CreateGroup(&model.Group{
+		DisplayName: "dn_" + prefix,
+		Name:        prefix + "user",
+		Source:      model.GroupSourceCustom,
+		IsDefault:   true,
+	})
+	CheckCreatedStatus(t, response)
+	assert.False(t, userGroup.IsDefault)
+
+	groups, response := th.SystemAdminClient.GetGroups(model.GroupSearchOpts{Q: prefix}, 0, 60)
+	CheckOKStatus(t, response)
+	assert.ElementsMatch(t, []*model.Group{defaultGroup, userGroup}, groups)
+
+	groups, response = th.SystemAdminClient.GetGroups(model.GroupSearchOpts{Q: prefix, ExcludeDefault: true}, 0, 60)
+	CheckOKStatus(t, response)
+	assert.ElementsMatch(t, []*model.Group{userGroup}, groups)
+}
+
+func TestGetGroupsManageableOnly(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	otherTeam := th.CreateTeamWithClient(th.SystemAdminClient)
+
+	createLinkedGroup := func(teamId string) *model.Group {
+		id := model.NewId()
+		group, err := th.App.CreateGroup(&model.Group{
+			DisplayName: "dn_" + id,
+			Name:        "name" + id,
+			Source:      model.GroupSourceLdap,
+			RemoteId:    model.NewId(),
+		})
+		require.Nil(t, err)
+
+		_, err = th.App.CreateGroupSyncable(model.NewGroupTeam(group.Id, teamId, true))
+		require.Nil(t, err)
+		return group
+	}
+
+	basicTeamGroup := createLinkedGroup(th.BasicTeam.Id)
+	createLinkedGroup(otherTeam.Id)
+
+	// The team admin of the basic team
+	teamAdminClient := th.CreateClient()
+	th.LoginTeamAdminWithClient(teamAdminClient)
+
+	_, response := teamAdminClient.GetGroups(model.GroupSearchOpts{}, 0, 60)
+	CheckForbiddenStatus(t, response)
+
+	groups, response := teamAdminClient.GetGroups(model.GroupSearchOpts{ManageableOnly: true}, 0, 60)
+	CheckOKStatus(t, response)
+	assert.ElementsMatch(t, []*model.Group{basicTeamGroup}, groups)
+
+	// A member who manages no teams sees no groups
+	groups, response = th.Client.GetGroups(model.GroupSearchOpts{ManageableOnly: true}, 0, 60)
+	CheckOKStatus(t, response)
+	assert.Empty(t, groups)
+}
+
+func TestGetGroupsMemberOfMe(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	prefix := model.NewId()[:10]
+	createGroup := func(name string) *model.Group {
+		group, err := th.App.CreateGroup(&model.Group{
+			DisplayName:          "dn_" + name,
+			Name:                 prefix + name,
+			Source:               model.GroupSourceCustom,
+			RemoteId:             model.NewId(),
+			MembershipWebhookURL: "https://example.com/hooks/" + name,
+		})
+		require.Nil(t, err)
+		return group
+	}
+
+	engineering := createGroup("engineering")
+	design := createGroup("design")
+	removed := createGroup("removed")
+	createGroup("other")
+
+	for _, group := range []*model.Group{engineering, design, removed} {
+		_, err := th.App.UpsertGroupMembers(group.Id, []string{th.BasicUser.Id})
+		require.Nil(t, err)
+	}
+	_, err := th.App.DeleteGroupMembers(removed.Id, []string{th.BasicUser.Id})
+	require.Nil(t, err)
+
+	_, response := th.Client.GetGroups(model.GroupSearchOpts{Q: prefix}, 0, 60)
+	CheckForbiddenStatus(t, response)
+
+	groupIds := func(groups []*model.Group) []string {
+		var ids []string
+		for _, group := range groups {
+			ids = append(ids, group.Id)
+			assert.Empty(t, group.MembershipWebhookURL)
+		}
+		return ids
+	}
+
+	groups, response := th.Client.GetGroups(model.GroupSearchOpts{MemberOfMe: true}, 0, 60)
+	CheckOKStatus(t, response)
+	assert.ElementsMatch(t, []string{engineering.Id, design.Id}, groupIds(groups))
+
+	// The search only matches the user's own groups
+	groups, response = th.Client.GetGroups(model.GroupSearchOpts{MemberOfMe: true, Q: prefix + "eng"}, 0, 60)
+	CheckOKStatus(t, response)
+	assert.Equal(t, []string{engineering.Id}, groupIds(groups))
+
+	groups, response = th.Client.GetGroups(model.GroupSearchOpts{MemberOfMe: true, Q: prefix + "other"}, 0, 60)
+	CheckOKStatus(t, response)
+	assert.Empty(t, groups)
+
+	// Another user sees only their own groups
+	groups, response = th.SystemAdminClient.GetGroups(model.GroupSearchOpts{MemberOfMe: true, Q: prefix}, 0, 60)
+	CheckOKStatus(t, response)
+	assert.Empty(t, groups)
+}
+
+func TestGetGroupsLocale(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	token := model.NewId()
+	for _, displayName := range []string{"Zoë", "Émile", "Able"} {
+		_, err := th.App.CreateGroup(&model.Group{
+			DisplayName: displayName + " " + token,
+			Name:        model.NewId(),
+			Source:      model.GroupSourceCustom,
+			RemoteId:    model.NewId(),
+		})
+		require.Nil(t, err)
+	}
+
+	_, response := th.SystemAdminClient.GetGroups(model.GroupSearchOpts{Q: token, Locale: "not a locale"}, 0, 60)
+	CheckBadRequestStatus(t, response)
+
+	// Both an explicit locale and the caller's own are accepted
+	groups, response := th.SystemAdminClient.GetGroups(model.GroupSearchOpts{Q: token, Locale: "fr"}, 0, 60)
+	CheckNoError(t, response)
+	assert.Len(t, groups, 3)
+
+	groups, response = th.SystemAdminClient.GetGroups(model.GroupSearchOpts{Q: token}, 0, 60)
+	CheckNoError(t, response)
+	assert.Len(t, groups, 3)
+}
+
+func TestGetGroupsFields(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	id := model.NewId()
+	group, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Description: "description",
+		Source:      model.GroupSourceCustom,
+	})
+	require.Nil(t, err)
+
+	r, appErr := th.SystemAdminClient.DoApiGet("/groups?q="+id+"&fields=id,display_name", "")
+	require.Nil(t, appErr)
+	defer r.Body.Close()
+
+	var groups []map[string]interface{}
+	require.Nil(t, json.NewDecoder(r.Body).Decode(&groups))
+	require.Len(t, groups, 1)
+	assert.Equal(t, map[string]interface{}{"id": group.Id, "display_name": group.DisplayName}, groups[0])
+
+	_, appErr = th.SystemAdminClient.DoApiGet("/groups?q="+id+"&fields=id,junk", "")
+	require.NotNil(t, appErr)
+	assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+
+	// Without fields the whole group is returned
+	fullGroups, response := th.SystemAdminClient.GetGroups(model.GroupSearchOpts{Q: id}, 0, 60)
+	CheckNoError(t, response)
+	require.Len(t, fullGroups, 1)
+	assert.Equal(t, "description", fullGroups[0].Description)
+}
+
+func TestGetGroupsModifiedSince(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	id := model.NewId()
+	group, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceCustom,
+	})
+	require.Nil(t, err)
+
+	opts := model.GroupSearchOpts{Q: group.Name}
+
+	_, response := th.Client.GetGroupsModifiedSince(opts, 0, 60, group.UpdateAt)
+	CheckForbiddenStatus(t, response)
+
+	// The group changed after the given time.
+	groups, response := th.SystemAdminClient.GetGroupsModifiedSince(opts, 0, 60, group.UpdateAt-1)
+	CheckOKStatus(t, response)
+	assert.ElementsMatch(t, []*model.Group{group}, groups)
+
+	// Nothing changed since the group was created.
+	groups, response = th.SystemAdminClient.GetGroupsModifiedSince(opts, 0, 60, group.UpdateAt)
+	require.Nil(t, response.Error)
+	assert.Equal(t, http.StatusNotModified, response.StatusCode)
+	assert.Nil(t, groups)
+
+	// Requests without the header are always answered in full.
+	groups, response = th.SystemAdminClient.GetGroups(opts, 0, 60)
+	CheckOKStatus(t, response)
+	assert.ElementsMatch(t, []*model.Group{group}, groups)
+
+	// Link filters are not covered by the group's update time, so the header is ignored.
+	groups, response = th.SystemAdminClient.GetGroupsModifiedSince(model.GroupSearchOpts{Q: group.Name, NotAssociatedToTeam: th.BasicTeam.Id}, 0, 60, group.UpdateAt)
+	CheckOKStatus(t, response)
+	assert.ElementsMatch(t, []*model.Group{group}, groups)
+
+	since := group.UpdateAt
+	time.Sleep(2 * time.Millisecond)
+
+	group, response = th.SystemAdminClient.PatchGroup(group.Id, &model.GroupPatch{DisplayName: model.NewString("changed")})
+	CheckOKStatus(t, response)
+
+	groups, response = th.SystemAdminClient.GetGroupsModifiedSince(opts, 0, 60, since)
+	CheckOKStatus(t, response)
+	assert.ElementsMatch(t, []*model.Group{group}, groups)
+
+	// Deleting the group is a change too, even though it is no longer listed.
+	since = group.UpdateAt
+	time.Sleep(2 * time.Millisecond)
+
+	_, err = th.App.DeleteGroup(group.Id)
+	require.Nil(t, err)
+
+	groups, response = th.SystemAdminClient.GetGroupsModifiedSince(opts, 0, 60, since)
+	CheckOKStatus(t, response)
+	assert.Empty(t, groups)
+}
+
+func TestGetUnusedGroups(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	id := model.NewId()
+	group, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceCustom,
+	})
+	require.Nil(t, err)
+
+	th.App.SetLicense(nil)
+
+	_, response := th.SystemAdminClient.GetUnusedGroups(model.GroupSourceCustom, 0, 0, 100)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.Client.GetUnusedGroups(model.GroupSourceCustom, 0, 0, 100)
+	CheckForbiddenStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetUnusedGroups("bogus", 0, 0, 100)
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetUnusedGroups(model.GroupSourceCustom, -1, 0, 100)
+	CheckBadRequestStatus(t, response)
+
+	// The group is too new to count as unused with the default window
+	groups, response := th.SystemAdminClient.GetUnusedGroups(model.GroupSourceCustom, model.GroupUnusedDefaultDays, 0, 100)
+	CheckOKStatus(t, response)
+	assert.NotContains(t, groups, group)
+
+	time.Sleep(5 * time.Millisecond)
+
+	groups, response = th.SystemAdminClient.GetUnusedGroups(model.GroupSourceCustom, 0, 0, 100)
+	CheckOKStatus(t, response)
+	assert.Contains(t, groups, group)
+
+	_, err = th.App.CreateGroupSyncable(model.NewGroupTeam(group.Id, th.BasicTeam.Id, false))
+	require.Nil(t, err)
+
+	groups, response = th.SystemAdminClient.GetUnusedGroups(model.GroupSourceCustom, 0, 0, 100)
+	CheckOKStatus(t, response)
+	assert.NotContains(t, groups, group)
+}
+
+func TestGetGroupsByMemberEmailDomain(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	domain := model.NewId() + ".com"
+	otherDomain := model.NewId() + ".com"
+
+	createGroup := func(domains ...string) *model.Group {
+		id := model.NewId()
+		group, err := th.App.CreateGroup(&model.Group{
+			DisplayName: "dn_" + id,
+			Name:        "name" + id,
+			Source:      model.GroupSourceCustom,
+		})
+		require.Nil(t, err)
+
+		var userIds []string
+		for _, domain := range domains {
+			user, err := th.App.CreateUser(&model.User{
+				Email:    model.NewId() + "@" + domain,
+				Username: "un_" + model.NewId(),
+				Password: "Password1",
+			})
+			require.Nil(t, err)
+			userIds = append(userIds, user.Id)
+		}
+		if len(userIds) > 0 {
+			_, err = th.App.UpsertGroupMembers(group.Id, userIds)
+			require.Nil(t, err)
+		}
+		return group
+	}
+
+	bothDomains := createGroup(domain, otherDomain)
+	otherDomainOnly := createGroup(otherDomain)
+	createGroup()
+
+	th.App.SetLicense(nil)
+
+	_, response := th.SystemAdminClient.GetGroupsByMemberEmailDomain(domain, 0, 100)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.Client.GetGroupsByMemberEmailDomain(domain, 0, 100)
+	CheckForbiddenStatus(t, response)
+
+	_, response = th.SystemAdminClient.GetGroupsByMemberEmailDomain(".com", 0, 100)
+	CheckBadRequestStatus(t, response)
+
+	groups, response := th.SystemAdminClient.GetGroupsByMemberEmailDomain(domain, 0, 100)
+	CheckOKStatus(t, response)
+	require.Len(t, groups, 1)
+	assert.Equal(t, bothDomains.Id, groups[0].Id)
+
+	groups, response = th.SystemAdminClient.GetGroupsByMemberEmailDomain(strings.ToUpper(otherDomain), 0, 100)
+	CheckOKStatus(t, response)
+	var ids []string
+	for _, group := range groups {
+		ids = append(ids, group.Id)
+	}
+	assert.ElementsMatch(t, []string{bothDomains.Id, otherDomainOnly.Id}, ids)
+
+	// Only the domain part of the address is matched
+	groups, response = th.SystemAdminClient.GetGroupsByMemberEmailDomain(domain[1:], 0, 100)
+	CheckOKStatus(t, response)
+	assert.Empty(t, groups)
+
+	groups, response = th.SystemAdminClient.GetGroupsByMemberEmailDomain(otherDomain, 1, 1)
+	CheckOKStatus(t, response)
+	assert.Len(t, groups, 1)
+}
+
+func TestAutocompleteGroups(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	prefix := "ac" + model.NewId()[:10]
+
+	createGroup := func(name string, allowReference bool) *model.Group {
+		group, err := th.App.CreateGroup(&model.Group{
+			DisplayName:    "dn_" + name,
+			Name:           name,
+			Source:         model.GroupSourceLdap,
+			RemoteId:       model.NewId(),
+			AllowReference: allowReference,
+		})
+		require.Nil(t, err)
+		return group
+	}
+
+	group := createGroup(prefix+"-eng", true)
+	createGroup(prefix+"-hidden", false)
+	createGroup("x"+prefix, true)
+
+	th.App.SetLicense(nil)
+
+	_, response := th.Client.AutocompleteGroups(th.BasicTeam.Id, "", prefix)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.Client.AutocompleteGroups("", "", prefix)
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.Client.AutocompleteGroups("asdfasdf", "", prefix)
+	CheckBadRequestStatus(t, response)
+
+	_, response = th.Client.AutocompleteGroups(model.NewId(), "", prefix)
+	CheckForbiddenStatus(t, response)
+
+	groups, response := th.Client.AutocompleteGroups(th.BasicTeam.Id, "", prefix)
+	CheckOKStatus(t, response)
+	assert.Equal(t, []*model.Group{group}, groups)
+
+	groups, response = th.Client.AutocompleteGroups("", th.BasicChannel.Id, prefix)
+	CheckOKStatus(t, response)
+	assert.Equal(t, []*model.Group{group}, groups)
+
+	// The results are the same whichever team or channel the search is made from, and ignore case
+	otherTeam := th.CreateTeam()
+	th.LinkUserToTeam(th.BasicUser, otherTeam)
+	groups, response = th.Client.AutocompleteGroups(otherTeam.Id, "", strings.ToUpper(prefix))
+	CheckOKStatus(t, response)
+	assert.Equal(t, []*model.Group{group}, groups)
+
+	// Unlike q on getGroups, the middle of a name does not match
+	groups, response = th.Client.AutocompleteGroups(th.BasicTeam.Id, "", prefix[2:])
+	CheckOKStatus(t, response)
+	assert.Empty(t, groups)
+
+	groups, response = th.SystemAdminClient.GetGroups(model.GroupSearchOpts{Q: prefix[2:]}, 0, 60)
+	CheckOKStatus(t, response)
+	assert.Len(t, groups, 3)
+}
+
+func TestGetTeamGroupSyncables(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	createGroup := func(displayName string) *model.Group {
+		id := model.NewId()
+		group, err := th.App.CreateGroup(&model.Group{
+			DisplayName: displayName,
+			Name:        "name" + id,
+			Source:      model.GroupSourceLdap,
+			RemoteId:    model.NewId(),
+		})
+		require.Nil(t, err)
+		return group
+	}
+
+	groupA := createGroup("a group " + model.NewId())
+	groupB := createGroup("b group " + model.NewId())
+	groupC := createGroup("c group " + model.NewId())
+	unlinkedGroup := createGroup("d group " + model.NewId())
+
+	_, err := th.App.CreateGroupSyncable(model.NewGroupTeam(groupC.Id, th.BasicTeam.Id, false))
+	require.Nil(t, err)
+	adminSyncable := model.NewGroupTeam(groupA.Id, th.BasicTeam.Id, true)
+	adminSyncable.SchemeAdmin = true
+	_, err = th.App.CreateGroupSyncable(adminSyncable)
+	require.Nil(t, err)
+	_, err = th.App.CreateGroupSyncable(model.NewGroupTeam(groupB.Id, th.BasicTeam.Id, true))
+	require.Nil(t, err)
+	_, err = th.App.CreateGroupSyncable(model.NewGroupTeam(unlinkedGroup.Id, th.BasicTeam.Id, true))
+	require.Nil(t, err)
+	_, err = th.App.DeleteGroupSyncable(unlinkedGroup.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam)
+	require.Nil(t, err)
+
+	// Links to other teams are left out.
+	otherTeam := th.CreateTeam()
+	_, err = th.App.CreateGroupSyncable(model.NewGroupTeam(groupA.Id, otherTeam.Id, true))
+	require.Nil(t, err)
+
+	th.App.SetLicense(nil)
+
+	_, response := th.SystemAdminClient.GetTeamGroupSyncables(th.BasicTeam.Id, 0, 60)
+	CheckNotImplementedStatus(t, response)
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	_, response = th.Client.GetTeamGroupSyncables(th.BasicTeam.Id, 0, 60)
+	CheckForbiddenStatus(t, response)
+
+	teamAdminClient := th.CreateClient()
+	th.LoginTeamAdminWithClient(teamAdminClient)
+
+	groupSyncables, response := teamAdminClient.GetTeamGroupSyncables(th.BasicTeam.Id, 0, 60)
+	CheckOKStatus(t, response)
+	require.Len(t, groupSyncables, 3)
+	for i, group := range []*model.Group{groupA, groupB, groupC} {
+		assert.Equal(t, group.Id, groupSyncables[i].GroupId)
+		assert.Equal(t, group.DisplayName, groupSyncables[i].GroupDisplayName)
+		assert.Equal(t, th.BasicTeam.Id, groupSyncables[i].SyncableId)
+	}
+	assert.True(t, groupSyncables[0].AutoAdd)
+	assert.True(t, groupSyncables[0].SchemeAdmin)
+	assert.False(t, groupSyncables[2].AutoAdd)
+	assert.False(t, groupSyncables[2].SchemeAdmin)
+
+	groupSyncables, response = teamAdminClient.GetTeamGroupSyncables(th.BasicTeam.Id, 1, 2)
+	CheckOKStatus(t, response)
+	require.Len(t, groupSyncables, 1)
+	assert.Equal(t, groupC.Id, groupSyncables[0].GroupId)
+
+	_, response = teamAdminClient.GetTeamGroupSyncables(otherTeam.Id, 0, 60)
+	CheckForbiddenStatus(t, response)
 }
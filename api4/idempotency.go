@@ -0,0 +1,93 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// idempotencyResponseWriter captures the status code and body written by a handler so that they can be replayed for
+// a retried request.
+type idempotencyResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (w *idempotencyResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *idempotencyResponseWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// idempotent wraps a write handler so that a request repeating the Idempotency-Key header of an earlier successful
+// request by the same user to the same endpoint is answered with the original response instead of being re-executed.
+func idempotent(h func(*Context, http.ResponseWriter, *http.Request)) func(*Context, http.ResponseWriter, *http.Request) {
+	return func(c *Context, w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(model.HEADER_IDEMPOTENCY_KEY)
+		if len(key) == 0 {
+			h(c, w, r)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			c.Err = model.NewAppError("idempotent", "api.io_error", nil, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.Sum256(body)
+		requestHash := hex.EncodeToString(hash[:])
+		endpoint := r.Method + " " + r.URL.Path
+
+		response, appErr := c.App.BeginIdempotentRequest(endpoint, key, requestHash)
+		if appErr != nil {
+			c.Err = appErr
+			return
+		}
+
+		if response != nil {
+			for name, values := range response.Header {
+				w.Header()[name] = values
+			}
+			w.WriteHeader(response.StatusCode)
+			w.Write(response.Body)
+			return
+		}
+
+		recorder := &idempotencyResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		h(c, recorder, r)
+
+		if c.Err != nil || recorder.statusCode >= 300 {
+			c.App.AbandonIdempotentRequest(endpoint, key)
+			return
+		}
+
+		header := w.Header()
+		recordedHeader := make(http.Header, len(header))
+		for name, values := range header {
+			if name != http.CanonicalHeaderKey(model.HEADER_REQUEST_ID) {
+				recordedHeader[name] = values
+			}
+		}
+
+		c.App.CompleteIdempotentRequest(endpoint, key, &model.IdempotentResponse{
+			RequestHash: requestHash,
+			StatusCode:  recorder.statusCode,
+			Header:      recordedHeader,
+			Body:        recorder.body.Bytes(),
+		})
+	}
+}
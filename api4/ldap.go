@@ -4,9 +4,9 @@
 package api4
 
 import (
-	"database/sql"
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/mattermost/mattermost-server/model"
 )
@@ -22,14 +22,26 @@ func (api *API) InitLdap() {
 	api.BaseRoutes.LDAP.Handle("/sync", api.ApiSessionRequired(syncLdap)).Methods("POST")
 	api.BaseRoutes.LDAP.Handle("/test", api.ApiSessionRequired(testLdap)).Methods("POST")
 
-	// GET /api/v4/ldap/groups?page=0&per_page=1000
-	api.BaseRoutes.LDAP.Handle("/groups", api.ApiSessionRequired(getLdapGroups)).Methods("GET")
+	// GET /api/v4/ldap/groups?page=0&per_page=100
+	api.BaseRoutes.LDAP.Handle("/groups", api.ApiSessionRequired(groupListPage(getLdapGroups))).Methods("GET")
 
 	// POST /api/v4/ldap/groups/:remote_id/link
 	api.BaseRoutes.LDAP.Handle(`/groups/{remote_id}/link`, api.ApiSessionRequired(linkLdapGroup)).Methods("POST")
 
 	// DELETE /api/v4/ldap/groups/:remote_id/link
 	api.BaseRoutes.LDAP.Handle(`/groups/{remote_id}/link`, api.ApiSessionRequired(unlinkLdapGroup)).Methods("DELETE")
+
+	// POST /api/v4/ldap/groups/:remote_id/validate
+	api.BaseRoutes.LDAP.Handle(`/groups/{remote_id}/validate`, api.ApiSessionRequired(validateLdapGroup)).Methods("POST")
+
+	// GET /api/v4/ldap/groups/stale?older_than_hours=24&page=0&per_page=100
+	api.BaseRoutes.LDAP.Handle("/groups/stale", api.ApiSessionRequired(groupListPage(getStaleLdapGroups))).Methods("GET")
+
+	// POST /api/v4/ldap/groups/sync?dry_run=true
+	api.BaseRoutes.LDAP.Handle("/groups/sync", api.ApiSessionRequired(syncLdapGroups)).Methods("POST")
+
+	// POST /api/v4/teams/:team_id/ldap_groups/link
+	api.BaseRoutes.Team.Handle("/ldap_groups/link", api.ApiSessionRequired(linkLdapGroupsToTeam)).Methods("POST")
 }
 
 func syncLdap(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -48,6 +60,72 @@ func syncLdap(c *Context, w http.ResponseWriter, r *http.Request) {
 	ReturnStatusOK(w)
 }
 
+// getStaleLdapGroups lists the LDAP groups whose members have not been synced for older_than_hours, oldest first, as a
+// sign of groups that the directory no longer returns or that fail to sync.
+func getStaleLdapGroups(c *Context, w http.ResponseWriter, r *http.Request) {
+	hours := model.GroupStaleSyncDefaultHours
+	if val := r.URL.Query().Get("older_than_hours"); len(val) > 0 {
+		var err error
+		if hours, err = strconv.Atoi(val); err != nil || hours < 0 {
+			c.SetInvalidParam("older_than_hours")
+			return
+		}
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.getStaleLdapGroups", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	since := model.GetMillis() - int64(hours)*60*60*1000
+	groups, err := c.App.GetStaleLdapGroups(since, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	writeGroupList(c, w, "Api4.getStaleLdapGroups", groups)
+}
+
+// syncLdapGroups starts a full LDAP sync, which includes groups. With dry_run=true nothing is synced and the totals
+// the sync would change are returned instead.
+func syncLdapGroups(c *Context, w http.ResponseWriter, r *http.Request) {
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.syncLdapGroups", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if r.URL.Query().Get("dry_run") != "true" {
+		c.App.SyncLdap()
+		ReturnStatusOK(w)
+		return
+	}
+
+	dryRun, err := c.App.GroupSyncDryRun()
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	b, marshalErr := json.Marshal(dryRun)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.syncLdapGroups", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
 func testLdap(c *Context, w http.ResponseWriter, r *http.Request) {
 	if c.App.License() == nil || !*c.App.License().Features.LDAP {
 		c.Err = model.NewAppError("Api4.testLdap", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
@@ -107,6 +185,8 @@ func getLdapGroups(c *Context, w http.ResponseWriter, r *http.Request) {
 		mugs = append(mugs, mug)
 	}
 
+	setGroupListPageHeaders(c, w)
+
 	b, marshalErr := json.Marshal(struct {
 		Count  int                   `json:"count"`
 		Groups []*mixedUnlinkedGroup `json:"groups"`
@@ -147,7 +227,7 @@ func linkLdapGroup(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 
 	group, err := c.App.GetGroupByRemoteID(ldapGroup.RemoteId, model.GroupSourceLdap)
-	if err != nil && err.DetailedError != sql.ErrNoRows.Error() {
+	if err != nil && err.Id != "store.sql_group.no_rows" {
 		c.Err = err
 		return
 	}
@@ -172,17 +252,12 @@ func linkLdapGroup(c *Context, w http.ResponseWriter, r *http.Request) {
 		status = http.StatusOK
 	} else {
 		// Group has never been linked
-		//
-		// TODO: In a future phase of LDAP groups sync `Name` will be used for at-mentions and will be editable on
-		// the front-end so it will not have an initial value of `model.NewId()` but rather a slugified version of
-		// the LDAP group name with an appended duplicate-breaker.
 		newGroup := &model.Group{
-			Name:        model.NewId(),
 			DisplayName: ldapGroup.DisplayName,
 			RemoteId:    ldapGroup.RemoteId,
 			Source:      model.GroupSourceLdap,
 		}
-		newOrUpdatedGroup, err = c.App.CreateGroup(newGroup)
+		newOrUpdatedGroup, err = c.App.CreateLdapGroup(newGroup)
 		if err != nil {
 			c.Err = err
 			return
@@ -200,6 +275,98 @@ func linkLdapGroup(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.Write(b)
 }
 
+// linkLdapGroupsToTeam links each of the LDAP groups with the given remote ids to the team, reporting the outcome per
+// remote id rather than failing the whole request when one of them cannot be linked.
+func linkLdapGroupsToTeam(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	link := model.LdapGroupsTeamLinkFromJson(r.Body)
+	if link == nil || len(link.RemoteIds) == 0 {
+		c.SetInvalidParam("remote_ids")
+		return
+	}
+
+	for _, remoteId := range link.RemoteIds {
+		if len(remoteId) == 0 {
+			c.SetInvalidParam("remote_ids")
+			return
+		}
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.linkLdapGroupsToTeam", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionToTeam(c.App.Session, c.Params.TeamId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	if _, err := c.App.GetTeam(c.Params.TeamId); err != nil {
+		c.Err = err
+		return
+	}
+
+	statuses, err := c.App.LinkLdapGroupsToTeam(c.Params.TeamId, model.RemoveDuplicateStrings(link.RemoteIds), link.AutoAdd)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	for _, status := range statuses {
+		if status.Error != nil {
+			// Only the error id and message are returned, not the underlying store or LDAP error.
+			status.Error = model.NewAppError("Api4.linkLdapGroupsToTeam", status.Error.Id, nil, "", status.Error.StatusCode)
+			status.Error.Translate(c.App.T)
+		}
+	}
+
+	b, marshalErr := json.Marshal(statuses)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.linkLdapGroupsToTeam", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
+// validateLdapGroup checks that the LDAP group still exists on the LDAP server and counts its members, without linking
+// or syncing it.
+func validateLdapGroup(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireRemoteId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if c.App.License() == nil || !*c.App.License().Features.LDAPGroups {
+		c.Err = model.NewAppError("Api4.validateLdapGroup", "api.ldap_groups.license_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	validation, err := c.App.ValidateLdapGroup(c.Params.RemoteId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	b, marshalErr := json.Marshal(validation)
+	if marshalErr != nil {
+		c.Err = model.NewAppError("Api4.validateLdapGroup", "api.marshal_error", nil, marshalErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(b)
+}
+
 func unlinkLdapGroup(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequireRemoteId()
 	if c.Err != nil {
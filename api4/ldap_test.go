@@ -4,10 +4,18 @@
 package api4
 
 import (
+	"errors"
+	"net/http"
+	"strconv"
 	"testing"
 
+	"github.com/go-ldap/ldap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/mattermost/mattermost-server/app"
+	"github.com/mattermost/mattermost-server/einterfaces/mocks"
 	"github.com/mattermost/mattermost-server/model"
 )
 
@@ -60,6 +68,27 @@ func TestGetLdapGroups(t *testing.T) {
 
 	_, resp = th.SystemAdminClient.GetLdapGroups()
 	CheckNotImplementedStatus(t, resp)
+
+	th.App.SetLicense(model.NewTestLicense("ldap_groups"))
+
+	ldapMock := &mocks.LdapInterface{}
+	ldapMock.On("GetAllGroupsPage", 0, mock.AnythingOfType("int"), model.GroupSearchOpts{}).Return([]*model.Group{}, 0, nil)
+	th.App.Ldap = ldapMock
+	defer func() { th.App.Ldap = nil }()
+
+	// Like the other group lists, a zero per_page gets the default and a large one is clamped.
+	for perPage, expected := range map[string]int{
+		"0":    GROUP_LIST_PER_PAGE_DEFAULT,
+		"200":  200,
+		"201":  GROUP_LIST_PER_PAGE_MAXIMUM,
+		"1000": GROUP_LIST_PER_PAGE_MAXIMUM,
+	} {
+		r, appErr := th.SystemAdminClient.DoApiGet("/ldap/groups?page=0&per_page="+perPage, "")
+		require.Nil(t, appErr)
+		r.Body.Close()
+		assert.Equal(t, strconv.Itoa(expected), r.Header.Get(model.HEADER_PER_PAGE), "per_page=%s", perPage)
+		ldapMock.AssertCalled(t, "GetAllGroupsPage", 0, expected, model.GroupSearchOpts{})
+	}
 }
 
 func TestLinkLdapGroup(t *testing.T) {
@@ -87,3 +116,222 @@ func TestUnlinkLdapGroup(t *testing.T) {
 	_, resp = th.SystemAdminClient.UnlinkLdapGroup(entryUUID)
 	CheckNotImplementedStatus(t, resp)
 }
+
+func TestValidateLdapGroup(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	const (
+		existingDN = "cn=existing,ou=groups,dc=example,dc=com"
+		missingDN  = "cn=missing,ou=groups,dc=example,dc=com"
+		brokenDN   = "cn=broken,ou=groups,dc=example,dc=com"
+	)
+
+	ldapMock := &mocks.LdapInterface{}
+	ldapMock.On("GetGroup", existingDN).Return(&model.Group{DisplayName: "existing", RemoteId: existingDN}, nil)
+	ldapMock.On("GetGroupMemberCount", existingDN).Return(12, nil)
+	ldapMock.On("GetGroup", missingDN).Return(nil, nil)
+	ldapMock.On("GetGroup", brokenDN).Return(nil, model.NewAppError("GetGroup", "ent.ldap.do_login.unable_to_connect.app_error", nil, "", http.StatusInternalServerError))
+	th.App.Srv.Ldap = ldapMock
+	defer func() { th.App.Srv.Ldap = nil }()
+
+	_, resp := th.SystemAdminClient.ValidateLdapGroup(existingDN)
+	CheckNotImplementedStatus(t, resp)
+
+	th.App.SetLicense(model.NewTestLicense("ldap_groups"))
+
+	_, resp = th.Client.ValidateLdapGroup(existingDN)
+	CheckForbiddenStatus(t, resp)
+
+	validation, resp := th.SystemAdminClient.ValidateLdapGroup(existingDN)
+	CheckOKStatus(t, resp)
+	assert.Equal(t, &model.LdapGroupValidation{Exists: true, MemberCount: 12}, validation)
+
+	validation, resp = th.SystemAdminClient.ValidateLdapGroup(missingDN)
+	CheckOKStatus(t, resp)
+	assert.Equal(t, &model.LdapGroupValidation{}, validation)
+
+	_, resp = th.SystemAdminClient.ValidateLdapGroup(brokenDN)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, "app.ldap.validate_group.app_error", resp.Error.Id)
+
+	// Validating a group never syncs it.
+	ldapMock.AssertNotCalled(t, "StartSynchronizeJob", mock.Anything)
+}
+
+func TestLinkLdapGroupsToTeam(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	const (
+		knownDN   = "cn=known,ou=groups,dc=example,dc=com"
+		unknownDN = "cn=unknown,ou=groups,dc=example,dc=com"
+	)
+
+	ldapMock := &mocks.LdapInterface{}
+	ldapMock.On("GetGroup", knownDN).Return(&model.Group{DisplayName: "known", RemoteId: knownDN}, nil)
+	ldapMock.On("GetGroup", unknownDN).Return(nil, nil)
+	th.App.Srv.Ldap = ldapMock
+	defer func() { th.App.Srv.Ldap = nil }()
+
+	_, resp := th.SystemAdminClient.LinkLdapGroupsToTeam(th.BasicTeam.Id, []string{knownDN}, true)
+	CheckNotImplementedStatus(t, resp)
+
+	th.App.SetLicense(model.NewTestLicense("ldap_groups"))
+
+	_, resp = th.Client.LinkLdapGroupsToTeam(th.BasicTeam.Id, []string{knownDN}, true)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.LinkLdapGroupsToTeam(th.BasicTeam.Id, []string{}, true)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.LinkLdapGroupsToTeam(model.NewId(), []string{knownDN}, true)
+	CheckNotFoundStatus(t, resp)
+
+	statuses, resp := th.SystemAdminClient.LinkLdapGroupsToTeam(th.BasicTeam.Id, []string{knownDN, unknownDN}, true)
+	CheckOKStatus(t, resp)
+	require.Len(t, statuses, 2)
+
+	assert.Equal(t, knownDN, statuses[0].RemoteId)
+	assert.Equal(t, model.LdapGroupTeamLinkStatusLinked, statuses[0].Status)
+	assert.Nil(t, statuses[0].Error)
+
+	assert.Equal(t, unknownDN, statuses[1].RemoteId)
+	assert.Equal(t, model.LdapGroupTeamLinkStatusNotFound, statuses[1].Status)
+	assert.Empty(t, statuses[1].GroupId)
+
+	// The known remote id was upserted to a local group and linked to the team.
+	group, err := th.App.GetGroupByRemoteID(knownDN, model.GroupSourceLdap)
+	require.Nil(t, err)
+	assert.Equal(t, group.Id, statuses[0].GroupId)
+
+	groupSyncable, err := th.App.GetGroupSyncable(group.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam)
+	require.Nil(t, err)
+	assert.True(t, groupSyncable.AutoAdd)
+
+	// Linking again reuses the same local group.
+	statuses, resp = th.SystemAdminClient.LinkLdapGroupsToTeam(th.BasicTeam.Id, []string{knownDN}, false)
+	CheckOKStatus(t, resp)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, group.Id, statuses[0].GroupId)
+	assert.Equal(t, model.LdapGroupTeamLinkStatusLinked, statuses[0].Status)
+
+	groupSyncable, err = th.App.GetGroupSyncable(group.Id, th.BasicTeam.Id, model.GroupSyncableTypeTeam)
+	require.Nil(t, err)
+	assert.False(t, groupSyncable.AutoAdd)
+}
+
+func TestGetStaleLdapGroups(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	_, resp := th.SystemAdminClient.GetStaleLdapGroups(24, 0, 60)
+	CheckNotImplementedStatus(t, resp)
+
+	th.App.SetLicense(model.NewTestLicense("ldap_groups"))
+
+	_, resp = th.Client.GetStaleLdapGroups(24, 0, 60)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetStaleLdapGroups(-1, 0, 60)
+	CheckBadRequestStatus(t, resp)
+
+	now := model.GetMillis()
+	hour := int64(60 * 60 * 1000)
+	seed := func(lastSyncAt int64) *model.Group {
+		group := th.CreateGroup()
+		if lastSyncAt > 0 {
+			result := <-th.App.Srv.Store.Group().SetLastSyncAt(group.Id, lastSyncAt)
+			require.Nil(t, result.Err)
+		}
+		return group
+	}
+	syncedTwoDaysAgo := seed(now - 48*hour)
+	syncedYesterday := seed(now - 30*hour)
+	syncedRecently := seed(now - hour)
+	neverSynced := seed(0)
+
+	seeded := map[string]bool{syncedTwoDaysAgo.Id: true, syncedYesterday.Id: true, syncedRecently.Id: true, neverSynced.Id: true}
+	staleIds := func(olderThanHours int) []string {
+		groups, resp := th.SystemAdminClient.GetStaleLdapGroups(olderThanHours, 0, 200)
+		CheckOKStatus(t, resp)
+		var ids []string
+		for _, group := range groups {
+			if seeded[group.Id] {
+				ids = append(ids, group.Id)
+			}
+		}
+		return ids
+	}
+
+	// Oldest first; a group created within the threshold is not stale before its first sync
+	assert.Equal(t, []string{syncedTwoDaysAgo.Id, syncedYesterday.Id}, staleIds(24))
+	assert.Equal(t, []string{syncedTwoDaysAgo.Id}, staleIds(36))
+	assert.Empty(t, staleIds(72))
+
+	groups, resp := th.SystemAdminClient.GetStaleLdapGroups(24, 0, 1)
+	CheckOKStatus(t, resp)
+	assert.Len(t, groups, 1)
+
+	// Updating a group keeps its sync time
+	syncedYesterday.DisplayName = "renamed"
+	_, err := th.App.UpdateGroup(syncedYesterday)
+	require.Nil(t, err)
+	assert.Equal(t, []string{syncedTwoDaysAgo.Id, syncedYesterday.Id}, staleIds(24))
+
+	// A group synced by the LDAP group sync is no longer stale, while a group the LDAP server cannot return stays so
+	ldapMock := &mocks.LdapInterface{}
+	ldapMock.On("GetAllGroupsPage", 0, app.LDAP_GROUP_SYNC_PAGE_SIZE, model.GroupSearchOpts{}).Return([]*model.Group{}, 0, nil)
+	ldapMock.On("GetGroupMemberAuthData", syncedTwoDaysAgo.RemoteId).Return([]string{}, nil)
+	ldapMock.On("GetGroupMemberAuthData", mock.Anything).Return(nil, ldap.NewError(ldap.LDAPResultNoSuchObject, errors.New("no such object")))
+	th.App.Ldap = ldapMock
+	defer func() { th.App.Ldap = nil }()
+
+	_, err = th.App.SyncLdapGroups()
+	require.Nil(t, err)
+	assert.Equal(t, []string{syncedYesterday.Id}, staleIds(24))
+
+	group, err := th.App.GetGroup(syncedTwoDaysAgo.Id)
+	require.Nil(t, err)
+	assert.True(t, group.LastSyncAt >= now)
+}
+
+func TestGroupSyncDryRun(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	_, resp := th.SystemAdminClient.GroupSyncDryRun()
+	CheckNotImplementedStatus(t, resp)
+
+	th.App.SetLicense(model.NewTestLicense("ldap_groups"))
+
+	_, resp = th.Client.GroupSyncDryRun()
+	CheckForbiddenStatus(t, resp)
+
+	id := model.NewId()
+	group, err := th.App.CreateGroup(&model.Group{
+		DisplayName: "dn_" + id,
+		Name:        "name" + id,
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, err)
+
+	_, err = th.App.CreateGroupSyncable(model.NewGroupTeam(group.Id, th.BasicTeam.Id, true))
+	require.Nil(t, err)
+
+	user := th.CreateUser()
+	_, err = th.App.CreateOrRestoreGroupMember(group.Id, user.Id)
+	require.Nil(t, err)
+
+	result, resp := th.SystemAdminClient.GroupSyncDryRun()
+	CheckOKStatus(t, resp)
+	require.NotNil(t, result)
+	require.True(t, result.Groups >= 1)
+	require.True(t, result.MembersToAdd >= 1)
+
+	// Nothing was synced.
+	_, err = th.App.GetTeamMember(th.BasicTeam.Id, user.Id)
+	require.NotNil(t, err)
+}
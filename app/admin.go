@@ -149,6 +149,7 @@ func (a *App) InvalidateAllCaches() *model.AppError {
 func (a *App) InvalidateAllCachesSkipSend() {
 	mlog.Info("Purging all caches")
 	a.Srv.sessionCache.Purge()
+	a.Srv.groupSearchCache.Purge()
 	ClearStatusCache()
 	a.Srv.Store.Team().ClearCaches()
 	a.Srv.Store.Channel().ClearCaches()
@@ -34,6 +34,10 @@ func (a *App) CreateDefaultChannels(teamId string) ([]*model.Channel, *model.App
 }
 
 func (a *App) JoinDefaultChannels(teamId string, user *model.User, shouldBeAdmin bool, userRequestorId string) *model.AppError {
+	return a.joinDefaultChannels(teamId, user, shouldBeAdmin, userRequestorId, true)
+}
+
+func (a *App) joinDefaultChannels(teamId string, user *model.User, shouldBeAdmin bool, userRequestorId string, postJoinMessages bool) *model.AppError {
 	var requestor *model.User
 	if userRequestorId != "" {
 		var err *model.AppError
@@ -84,7 +88,7 @@ func (a *App) JoinDefaultChannels(teamId string, user *model.User, shouldBeAdmin
 				mlog.Warn(fmt.Sprintf("Failed to update ChannelMemberHistory table %v", result.Err))
 			}
 
-			if *a.Config().ServiceSettings.ExperimentalEnableDefaultChannelLeaveJoinMessages {
+			if postJoinMessages && *a.Config().ServiceSettings.ExperimentalEnableDefaultChannelLeaveJoinMessages {
 				a.postJoinMessageForDefaultChannel(user, requestor, channel)
 			}
 
@@ -916,6 +920,28 @@ func (a *App) addUserToChannel(user *model.User, channel *model.Channel, teamMem
 	return newMember, nil
 }
 
+// checkGroupConstrainedChannelMember rejects a user joining a group-constrained channel unless they are a member of
+// one of the groups linked to it. Users brought in by the group sync pass, since they come from those groups.
+func (a *App) checkGroupConstrainedChannelMember(channel *model.Channel, userId string) *model.AppError {
+	if channel.GroupConstrained == nil || !*channel.GroupConstrained {
+		return nil
+	}
+
+	nonMembers, err := a.FilterNonGroupChannelMembers([]string{userId}, channel)
+	if err != nil {
+		if appErr, ok := err.(*model.AppError); ok {
+			return appErr
+		}
+		return model.NewAppError("checkGroupConstrainedChannelMember", "api.channel.add_members.error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if len(nonMembers) > 0 {
+		return model.NewAppError("checkGroupConstrainedChannelMember", "api.channel.group_constrained.not_in_group", nil, "channel_id="+channel.Id+", user_id="+userId, http.StatusForbidden)
+	}
+
+	return nil
+}
+
 func (a *App) AddUserToChannel(user *model.User, channel *model.Channel) (*model.ChannelMember, *model.AppError) {
 	tmchan := a.Srv.Store.Team().GetMember(channel.TeamId, user.Id)
 	var teamMember *model.TeamMember
@@ -943,6 +969,11 @@ func (a *App) AddUserToChannel(user *model.User, channel *model.Channel) (*model
 }
 
 func (a *App) AddChannelMember(userId string, channel *model.Channel, userRequestorId string, postRootId string) (*model.ChannelMember, *model.AppError) {
+	return a.addChannelMember(userId, channel, userRequestorId, postRootId, true)
+}
+
+// addChannelMember adds the user to the channel, posting a join or added-by message if postJoinMessage is set.
+func (a *App) addChannelMember(userId string, channel *model.Channel, userRequestorId string, postRootId string, postJoinMessage bool) (*model.ChannelMember, *model.AppError) {
 	if member, err := a.Srv.Store.Channel().GetMember(channel.Id, userId); err != nil {
 		if err.Id != store.MISSING_CHANNEL_MEMBER_ERROR {
 			return nil, err
@@ -951,6 +982,10 @@ func (a *App) AddChannelMember(userId string, channel *model.Channel, userReques
 		return member, nil
 	}
 
+	if err := a.checkGroupConstrainedChannelMember(channel, userId); err != nil {
+		return nil, err
+	}
+
 	var user *model.User
 	var err *model.AppError
 
@@ -989,6 +1024,10 @@ func (a *App) AddChannelMember(userId string, channel *model.Channel, userReques
 		})
 	}
 
+	if !postJoinMessage {
+		return cm, nil
+	}
+
 	if userRequestorId == "" || userId == userRequestorId {
 		a.postJoinChannelMessage(user, channel)
 	} else {
@@ -1379,6 +1418,10 @@ func (a *App) JoinChannel(channel *model.Channel, userId string) *model.AppError
 		return model.NewAppError("JoinChannel", "api.channel.join_channel.permissions.app_error", nil, "", http.StatusBadRequest)
 	}
 
+	if err := a.checkGroupConstrainedChannelMember(channel, userId); err != nil {
+		return err
+	}
+
 	cm, err := a.AddUserToChannel(user, channel)
 	if err != nil {
 		return err
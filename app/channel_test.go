@@ -895,3 +895,62 @@ func TestUpdateChannelMemberRolesChangingGuest(t *testing.T) {
 		}
 	})
 }
+
+func TestGroupConstrainedChannelJoin(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	group := th.CreateGroup()
+	groupMember := th.CreateUser()
+	outsider := th.CreateUser()
+	for _, user := range []*model.User{groupMember, outsider} {
+		th.LinkUserToTeam(user, th.BasicTeam)
+	}
+
+	_, err := th.App.UpsertGroupMembers(group.Id, []string{groupMember.Id})
+	require.Nil(t, err)
+
+	channel := th.CreateChannel(th.BasicTeam)
+	channel.GroupConstrained = model.NewBool(true)
+	channel, err = th.App.UpdateChannel(channel)
+	require.Nil(t, err)
+
+	_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(group.Id, channel.Id, true))
+	require.Nil(t, err)
+
+	t.Run("group member can join", func(t *testing.T) {
+		require.Nil(t, th.App.JoinChannel(channel, groupMember.Id))
+
+		_, err := th.App.GetChannelMember(channel.Id, groupMember.Id)
+		require.Nil(t, err)
+	})
+
+	t.Run("non member cannot join", func(t *testing.T) {
+		err := th.App.JoinChannel(channel, outsider.Id)
+		require.NotNil(t, err)
+		require.Equal(t, "api.channel.group_constrained.not_in_group", err.Id)
+
+		_, err = th.App.AddChannelMember(outsider.Id, channel, th.BasicUser.Id, "")
+		require.NotNil(t, err)
+		require.Equal(t, "api.channel.group_constrained.not_in_group", err.Id)
+
+		_, err = th.App.GetChannelMember(channel.Id, outsider.Id)
+		require.NotNil(t, err)
+	})
+
+	t.Run("sync adds group members", func(t *testing.T) {
+		syncedMember := th.CreateUser()
+		th.LinkUserToTeam(syncedMember, th.BasicTeam)
+
+		_, err := th.App.UpsertGroupMembers(group.Id, []string{syncedMember.Id})
+		require.Nil(t, err)
+
+		require.Nil(t, th.App.CreateDefaultMemberships(0))
+
+		_, err = th.App.GetChannelMember(channel.Id, syncedMember.Id)
+		require.Nil(t, err)
+
+		_, err = th.App.GetChannelMember(channel.Id, outsider.Id)
+		require.NotNil(t, err)
+	})
+}
@@ -4,12 +4,36 @@
 package app
 
 import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
 )
 
+// GROUP_PROMOTION_PAGE_SIZE is the number of a channel's groups read at a time when promoting them to its team.
+const GROUP_PROMOTION_PAGE_SIZE = 100
+
+const (
+	GROUP_SEARCH_CACHE_SIZE = 10000
+	GROUP_SEARCH_CACHE_SEC  = 60
+)
+
+var invalidChannelNameChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// reservedGroupMentionNames are the mentions that notify a whole channel, which a referenceable group cannot shadow.
+var reservedGroupMentionNames = map[string]bool{"all": true, "channel": true, "here": true}
+
+// GetGroup returns the group with the given id, or an error with http.StatusNotFound if there is no such group.
 func (a *App) GetGroup(id string) (*model.Group, *model.AppError) {
 	result := <-a.Srv.Store.Group().Get(id)
 	if result.Err != nil {
+		if result.Err.Id == "store.sql_group.no_rows" {
+			return nil, model.NewAppError("GetGroup", "app.group.not_found.app_error", nil, "group_id="+id, http.StatusNotFound)
+		}
 		return nil, result.Err
 	}
 	return result.Data.(*model.Group), nil
@@ -36,6 +60,7 @@ func (a *App) CreateGroup(group *model.Group) (*model.Group, *model.AppError) {
 	if result.Err != nil {
 		return nil, result.Err
 	}
+	a.InvalidateCacheForGroupSearch()
 	return result.Data.(*model.Group), nil
 }
 
@@ -44,14 +69,53 @@ func (a *App) UpdateGroup(group *model.Group) (*model.Group, *model.AppError) {
 	if result.Err != nil {
 		return nil, result.Err
 	}
+	a.InvalidateCacheForGroupSearch()
 	return result.Data.(*model.Group), nil
 }
 
+// UpdateGroupsMetadata sets the display name and description of each of the groups in a few batched writes, for
+// directory syncs updating thousands of groups at once. Groups whose metadata is unchanged are left untouched. It
+// returns the number of groups changed.
+func (a *App) UpdateGroupsMetadata(groups []*model.Group) (int, *model.AppError) {
+	result := <-a.Srv.Store.Group().UpdateMetadata(groups)
+	if result.Err != nil {
+		return 0, result.Err
+	}
+
+	count := result.Data.(int)
+	if count > 0 {
+		a.InvalidateCacheForGroupSearch()
+	}
+	return count, nil
+}
+
+// RebindGroupRemoteId points an LDAP group at another directory object, for instance after the DN of its LDAP group
+// changed. The group keeps its id, so its members and syncables are preserved.
+func (a *App) RebindGroupRemoteId(group *model.Group, remoteId string) (*model.Group, *model.AppError) {
+	if group.Source != model.GroupSourceLdap {
+		return nil, model.NewAppError("RebindGroupRemoteId", "app.group.remote_id.not_ldap.app_error", nil, "group_id="+group.Id, http.StatusBadRequest)
+	}
+
+	// Deleted groups keep their remote id, so they still conflict.
+	result := <-a.Srv.Store.Group().GetByRemoteID(remoteId, model.GroupSourceLdap)
+	if result.Err == nil {
+		if existing := result.Data.(*model.Group); existing.Id != group.Id {
+			return nil, model.NewAppError("RebindGroupRemoteId", "app.group.remote_id.conflict.app_error", nil, "group_id="+existing.Id, http.StatusConflict)
+		}
+	} else if result.Err.StatusCode != http.StatusNotFound {
+		return nil, result.Err
+	}
+
+	group.RemoteId = remoteId
+	return a.UpdateGroup(group)
+}
+
 func (a *App) DeleteGroup(groupID string) (*model.Group, *model.AppError) {
 	result := <-a.Srv.Store.Group().Delete(groupID)
 	if result.Err != nil {
 		return nil, result.Err
 	}
+	a.InvalidateCacheForGroupSearch()
 	return result.Data.(*model.Group), nil
 }
 
@@ -77,11 +141,354 @@ func (a *App) GetGroupMemberUsersPage(groupID string, page int, perPage int) ([]
 	return members, count, nil
 }
 
+// GetGroupMemberIdsPage returns the user ids of a page of the group's members, ordered as by GetGroupMemberUsersPage,
+// along with the group's member count.
+func (a *App) GetGroupMemberIdsPage(groupID string, page int, perPage int) ([]string, int, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetMemberIdsPage(groupID, page, perPage)
+	if result.Err != nil {
+		return nil, 0, result.Err
+	}
+	userIDs := result.Data.([]string)
+	result = <-a.Srv.Store.Group().GetMemberCount(groupID)
+	if result.Err != nil {
+		return nil, 0, result.Err
+	}
+	count := int(result.Data.(int64))
+	return userIDs, count, nil
+}
+
+// GetNestedGroupIds returns the id of the group followed by the ids of all the groups nested beneath it, at any
+// depth. Each group is visited once, so a cycle in the hierarchy cannot make it loop.
+func (a *App) GetNestedGroupIds(groupID string) ([]string, *model.AppError) {
+	groupIds := []string{groupID}
+	visited := map[string]bool{groupID: true}
+
+	for parentIds := groupIds; len(parentIds) > 0; {
+		result := <-a.Srv.Store.Group().GetChildGroupIds(parentIds)
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		parentIds = nil
+		for _, childId := range result.Data.([]string) {
+			if !visited[childId] {
+				visited[childId] = true
+				groupIds = append(groupIds, childId)
+				parentIds = append(parentIds, childId)
+			}
+		}
+	}
+
+	return groupIds, nil
+}
+
+// GetNestedGroupMemberUsersPage returns a page of the members of the group and of all the groups nested beneath it,
+// with each user listed once, along with the total number of such users.
+func (a *App) GetNestedGroupMemberUsersPage(groupID string, page int, perPage int) ([]*model.User, int, *model.AppError) {
+	groupIds, err := a.GetNestedGroupIds(groupID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := <-a.Srv.Store.Group().GetNestedMemberUsersPage(groupIds, page, perPage)
+	if result.Err != nil {
+		return nil, 0, result.Err
+	}
+	members := result.Data.([]*model.User)
+	result = <-a.Srv.Store.Group().GetNestedMemberCount(groupIds)
+	if result.Err != nil {
+		return nil, 0, result.Err
+	}
+	count := int(result.Data.(int64))
+	return members, count, nil
+}
+
+// GetUnionGroupMembers returns a page of the users who are members of at least one of the groups, with each user
+// listed once, along with the total number of such users. Every group must exist.
+func (a *App) GetUnionGroupMembers(groupIDs []string, page int, perPage int) ([]*model.User, int, *model.AppError) {
+	groups, err := a.GetGroupsByIDs(groupIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(groups) != len(groupIDs) {
+		return nil, 0, model.NewAppError("GetUnionGroupMembers", "app.group.members_union.not_found.app_error", nil, "", http.StatusNotFound)
+	}
+
+	result := <-a.Srv.Store.Group().GetNestedMemberUsersPage(groupIDs, page, perPage)
+	if result.Err != nil {
+		return nil, 0, result.Err
+	}
+	members := result.Data.([]*model.User)
+	result = <-a.Srv.Store.Group().GetNestedMemberCount(groupIDs)
+	if result.Err != nil {
+		return nil, 0, result.Err
+	}
+	count := int(result.Data.(int64))
+	return members, count, nil
+}
+
+// GetIntersectionGroupMembers returns a page of the users who are members of every one of the groups, along with the
+// total number of such users. Every group must exist.
+func (a *App) GetIntersectionGroupMembers(groupIDs []string, page int, perPage int) ([]*model.User, int, *model.AppError) {
+	groups, err := a.GetGroupsByIDs(groupIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(groups) != len(groupIDs) {
+		return nil, 0, model.NewAppError("GetIntersectionGroupMembers", "app.group.members_intersection.not_found.app_error", nil, "", http.StatusNotFound)
+	}
+
+	result := <-a.Srv.Store.Group().GetIntersectionMemberUsersPage(groupIDs, page, perPage)
+	if result.Err != nil {
+		return nil, 0, result.Err
+	}
+	members := result.Data.([]*model.User)
+	result = <-a.Srv.Store.Group().GetIntersectionMemberCount(groupIDs)
+	if result.Err != nil {
+		return nil, 0, result.Err
+	}
+	count := int(result.Data.(int64))
+	return members, count, nil
+}
+
+// GetUngroupedUsers returns a page of the active users who are not members of any group, along with the total number
+// of such users.
+func (a *App) GetUngroupedUsers(page int, perPage int) ([]*model.User, int, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetUngroupedUsers(page, perPage)
+	if result.Err != nil {
+		return nil, 0, result.Err
+	}
+	users := result.Data.([]*model.User)
+	result = <-a.Srv.Store.Group().GetUngroupedUserCount()
+	if result.Err != nil {
+		return nil, 0, result.Err
+	}
+	count := int(result.Data.(int64))
+	return users, count, nil
+}
+
+// ValidateGroupParent checks that the group's parent exists and that nesting the group beneath it would not create a
+// cycle, i.e. that the group is not already one of its parent's ancestors.
+func (a *App) ValidateGroupParent(group *model.Group) *model.AppError {
+	visited := map[string]bool{group.Id: true}
+	for parentId := group.ParentGroupId; len(parentId) > 0; {
+		if visited[parentId] {
+			return model.NewAppError("ValidateGroupParent", "api.group.parent_group_id.cycle.app_error", nil, "group_id="+group.Id, http.StatusBadRequest)
+		}
+		visited[parentId] = true
+
+		parent, err := a.GetGroup(parentId)
+		if err != nil {
+			return err
+		}
+		parentId = parent.ParentGroupId
+	}
+
+	return nil
+}
+
+// ValidateGroupOwner checks that the group's owner, if it has one, is an active user.
+func (a *App) ValidateGroupOwner(group *model.Group) *model.AppError {
+	if len(group.OwnerId) == 0 {
+		return nil
+	}
+
+	owner, err := a.GetUser(group.OwnerId)
+	if err != nil && err.StatusCode != http.StatusNotFound {
+		return err
+	}
+	if err != nil || owner.DeleteAt != 0 {
+		return model.NewAppError("ValidateGroupOwner", "api.group.owner_id.invalid.app_error", nil, "owner_id="+group.OwnerId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// ValidateGroupReferenceName checks that mentioning a referenceable group cannot be mistaken for another mention: its
+// name can be neither one of the mentions that notify a whole channel nor the username of a user.
+func (a *App) ValidateGroupReferenceName(group *model.Group) *model.AppError {
+	if !group.AllowReference {
+		return nil
+	}
+
+	name := strings.ToLower(group.Name)
+	if reservedGroupMentionNames[name] {
+		return model.NewAppError("ValidateGroupReferenceName", "api.group.name.reserved", map[string]interface{}{"Name": group.Name}, "", http.StatusBadRequest)
+	}
+
+	result := <-a.Srv.Store.User().GetByUsername(name)
+	if result.Err == nil {
+		return model.NewAppError("ValidateGroupReferenceName", "api.group.name.reserved", map[string]interface{}{"Name": group.Name}, "user_id="+result.Data.(*model.User).Id, http.StatusBadRequest)
+	}
+	if result.Err.Id != "store.sql_user.get_by_username.app_error" {
+		return result.Err
+	}
+
+	return nil
+}
+
+// GetGroupMemberUsersExpiringPage returns a page of the members of the group whose membership expires before the
+// given time, along with the total number of such members.
+func (a *App) GetGroupMemberUsersExpiringPage(groupID string, before int64, page int, perPage int) ([]*model.User, int, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetMemberUsersExpiringPage(groupID, before, page, perPage)
+	if result.Err != nil {
+		return nil, 0, result.Err
+	}
+	members := result.Data.([]*model.User)
+	result = <-a.Srv.Store.Group().GetExpiringMemberCount(groupID, before)
+	if result.Err != nil {
+		return nil, 0, result.Err
+	}
+	count := int(result.Data.(int64))
+	return members, count, nil
+}
+
+// SetGroupMemberExpiries sets when the memberships of the given users, keyed by user id, expire. Users that are not
+// active members of the group are ignored.
+func (a *App) SetGroupMemberExpiries(groupID string, expiresAt map[string]int64) *model.AppError {
+	if len(expiresAt) == 0 {
+		return nil
+	}
+
+	userIDs := make([]string, 0, len(expiresAt))
+	for userID := range expiresAt {
+		userIDs = append(userIDs, userID)
+	}
+
+	members, err := a.GetGroupMembers(groupID, userIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range members {
+		member.ExpiresAt = expiresAt[member.UserId]
+		if result := <-a.Srv.Store.Group().UpdateMember(member); result.Err != nil {
+			return result.Err
+		}
+	}
+
+	return nil
+}
+
+// GetGroupsByUserId returns the groups the user is an active member of. The result is cached per user and
+// invalidated whenever the user's memberships change.
+func (a *App) GetGroupsByUserId(userID string) ([]*model.Group, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetGroupsByUserId(userID)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Data.([]*model.Group), nil
+}
+
+// IsUserInGroup reports whether the user is an active member of the group, using the same cached memberships as
+// GetGroupsByUserId.
+func (a *App) IsUserInGroup(userID, groupID string) (bool, *model.AppError) {
+	groups, err := a.GetGroupsByUserId(userID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, group := range groups {
+		if group.Id == groupID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// InvalidateGroupMembershipCacheForUsers drops the cached group memberships of the given users across the cluster.
+func (a *App) InvalidateGroupMembershipCacheForUsers(userIDs []string) {
+	for _, userID := range userIDs {
+		a.Srv.Store.Group().InvalidateMembershipCacheForUser(userID)
+	}
+}
+
+// GetGroupMemberIds returns the ids of up to limit members of the group and whether it has more members than that.
+func (a *App) GetGroupMemberIds(groupID string, limit int) ([]string, bool, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetMemberIds(groupID, limit+1)
+	if result.Err != nil {
+		return nil, false, result.Err
+	}
+
+	userIDs := result.Data.([]string)
+	if len(userIDs) > limit {
+		return userIDs[:limit], true, nil
+	}
+	return userIDs, false, nil
+}
+
+// groupMembershipChanged records the users being added to or removed from the group in its membership history and
+// notifies the group's membership webhook. A failure to record the history is logged rather than failing the change,
+// which has already been made. If GroupSettings.ReconcileDebounceSeconds is set, the group's auto-add links are
+// reconciled once its changes settle; otherwise they are left to the sync of default memberships.
+func (a *App) groupMembershipChanged(groupID string, userIDs []string, action string) {
+	if result := <-a.Srv.Store.Group().LogMemberEvents(groupID, userIDs, action); result.Err != nil {
+		mlog.Error("Failed to record group membership history", mlog.String("group_id", groupID), mlog.String("action", action), mlog.Err(result.Err))
+	}
+
+	a.NotifyGroupMembershipChange(groupID, userIDs, action)
+
+	if *a.Config().GroupSettings.ReconcileDebounceSeconds > 0 {
+		if err := a.RequestGroupReconcile(groupID, a.reconcileGroupSyncables); err != nil {
+			mlog.Error("Failed to request a group reconciliation", mlog.String("group_id", groupID), mlog.Err(err))
+		}
+	}
+}
+
+// GetGroupMemberHistory returns a page of the membership events of the group between from and to, oldest first. A
+// zero to includes everything up to now.
+func (a *App) GetGroupMemberHistory(groupID string, from, to int64, page, perPage int) ([]*model.GroupMemberHistoryEvent, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetMemberHistory(groupID, from, to, page, perPage)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Data.([]*model.GroupMemberHistoryEvent), nil
+}
+
+// GetGroupMemberTimezoneDistribution counts the active members of the group by their preferred timezone. Members who
+// have not set a timezone are counted under model.GroupMemberTimezoneUnknown.
+func (a *App) GetGroupMemberTimezoneDistribution(groupID string) (map[string]int, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetMemberTimezones(groupID)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	distribution := map[string]int{}
+	for _, timezone := range result.Data.([]map[string]string) {
+		preferred := model.GetPreferredTimezone(timezone)
+		if len(preferred) == 0 {
+			preferred = model.GroupMemberTimezoneUnknown
+		}
+		distribution[preferred]++
+	}
+
+	return distribution, nil
+}
+
+// GetGroupMentionCount returns the number of active members of the group who belong to the channel, which is how many
+// users a mention of the group in that channel notifies.
+func (a *App) GetGroupMentionCount(groupID, channelID string) (int64, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetMemberCountInChannel(groupID, channelID)
+	if result.Err != nil {
+		return 0, result.Err
+	}
+
+	return result.Data.(int64), nil
+}
+
 func (a *App) CreateOrRestoreGroupMember(groupID string, userID string) (*model.GroupMember, *model.AppError) {
 	result := <-a.Srv.Store.Group().CreateOrRestoreMember(groupID, userID)
 	if result.Err != nil {
 		return nil, result.Err
 	}
+
+	if a.Metrics != nil {
+		a.Metrics.IncrementGroupMemberUpsertCounter()
+	}
+
+	a.groupMembershipChanged(groupID, []string{userID}, model.GroupMembershipWebhookActionAdd)
+
 	return result.Data.(*model.GroupMember), nil
 }
 
@@ -90,10 +497,196 @@ func (a *App) DeleteGroupMember(groupID string, userID string) (*model.GroupMemb
 	if result.Err != nil {
 		return nil, result.Err
 	}
+
+	a.groupMembershipChanged(groupID, []string{userID}, model.GroupMembershipWebhookActionRemove)
+
+	return result.Data.(*model.GroupMember), nil
+}
+
+// UpsertGroupMembers adds the given users to the group, reporting the users that were already members separately.
+func (a *App) UpsertGroupMembers(groupID string, userIDs []string) (*model.GroupMembersUpsertResult, *model.AppError) {
+	result := <-a.Srv.Store.Group().UpsertMembers(groupID, userIDs)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	upsertResult := result.Data.(*model.GroupMembersUpsertResult)
+
+	if a.Metrics != nil {
+		for range upsertResult.Added {
+			a.Metrics.IncrementGroupMemberUpsertCounter()
+		}
+	}
+
+	a.groupMembershipChanged(groupID, upsertResult.Added, model.GroupMembershipWebhookActionAdd)
+
+	return upsertResult, nil
+}
+
+func (a *App) DeleteGroupMembers(groupID string, userIDs []string) ([]*model.GroupMember, *model.AppError) {
+	result := <-a.Srv.Store.Group().DeleteMembers(groupID, userIDs)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	members := result.Data.([]*model.GroupMember)
+
+	removedUserIDs := make([]string, 0, len(members))
+	for _, member := range members {
+		removedUserIDs = append(removedUserIDs, member.UserId)
+	}
+	a.groupMembershipChanged(groupID, removedUserIDs, model.GroupMembershipWebhookActionRemove)
+
+	return members, nil
+}
+
+// ClearGroupMembers removes every member of the group, keeping the group and its links, and returns how many members
+// were removed. Each removal is recorded and notified like any other.
+func (a *App) ClearGroupMembers(groupID string) (int, *model.AppError) {
+	result := <-a.Srv.Store.Group().DeleteAllMembers(groupID)
+	if result.Err != nil {
+		return 0, result.Err
+	}
+	userIDs := result.Data.([]string)
+
+	a.groupMembershipChanged(groupID, userIDs, model.GroupMembershipWebhookActionRemove)
+
+	return len(userIDs), nil
+}
+
+// GetGroupMembers returns the active memberships of the given users in the group.
+func (a *App) GetGroupMembers(groupID string, userIDs []string) ([]*model.GroupMember, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetMembers(groupID, userIDs)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Data.([]*model.GroupMember), nil
+}
+
+// FilterGroupMembers splits the given users into the active members of the group and the rest, looking up all of
+// their memberships in one query. Both lists keep the order of userIDs, without duplicates.
+func (a *App) FilterGroupMembers(groupID string, userIDs []string) (*model.GroupMembershipCheckResult, *model.AppError) {
+	members, err := a.GetGroupMembers(groupID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	isMember := make(map[string]bool, len(members))
+	for _, member := range members {
+		isMember[member.UserId] = true
+	}
+
+	result := &model.GroupMembershipCheckResult{Members: []string{}, NonMembers: []string{}}
+	seen := make(map[string]bool, len(userIDs))
+	for _, userID := range userIDs {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		if isMember[userID] {
+			result.Members = append(result.Members, userID)
+		} else {
+			result.NonMembers = append(result.NonMembers, userID)
+		}
+	}
+
+	return result, nil
+}
+
+func (a *App) GetGroupMember(groupID, userID string) (*model.GroupMember, *model.AppError) {
+	members, err := a.GetGroupMembers(groupID, []string{userID})
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, model.NewAppError("GetGroupMember", "app.group.member.not_found.app_error", nil, "group_id="+groupID+", user_id="+userID, http.StatusNotFound)
+	}
+	return members[0], nil
+}
+
+func (a *App) UpdateGroupMember(member *model.GroupMember) (*model.GroupMember, *model.AppError) {
+	result := <-a.Srv.Store.Group().UpdateMember(member)
+	if result.Err != nil {
+		return nil, result.Err
+	}
 	return result.Data.(*model.GroupMember), nil
 }
 
+// TransferGroupMemberships adds toUserID to every custom group fromUserID belongs to, and removes fromUserID from
+// them if removeSource is set. LDAP groups are skipped since their members come from the directory. The ids of the
+// groups that were changed are returned.
+func (a *App) TransferGroupMemberships(fromUserID, toUserID string, removeSource bool) ([]string, *model.AppError) {
+	if fromUserID == toUserID {
+		return nil, model.NewAppError("TransferGroupMemberships", "app.group.transfer.same_user.app_error", nil, "user_id="+fromUserID, http.StatusBadRequest)
+	}
+
+	for _, userID := range []string{fromUserID, toUserID} {
+		if _, err := a.GetUser(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	groups, err := a.GetGroupsByUserId(fromUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	groupIDs := []string{}
+	for _, group := range groups {
+		if group.Source != model.GroupSourceCustom {
+			continue
+		}
+
+		if _, err = a.UpsertGroupMembers(group.Id, []string{toUserID}); err != nil {
+			return nil, err
+		}
+
+		if removeSource {
+			if _, err = a.DeleteGroupMember(group.Id, fromUserID); err != nil {
+				return nil, err
+			}
+		}
+
+		groupIDs = append(groupIDs, group.Id)
+	}
+
+	return groupIDs, nil
+}
+
+// MergeGroups moves the members and the team and channel links of the source group into the target group and then
+// deletes the source group. Both groups must be custom groups. The new member count of the target is returned.
+func (a *App) MergeGroups(targetID, sourceID string) (int64, *model.AppError) {
+	if targetID == sourceID {
+		return 0, model.NewAppError("MergeGroups", "app.group.merge.same_group.app_error", nil, "group_id="+targetID, http.StatusBadRequest)
+	}
+
+	for _, groupID := range []string{targetID, sourceID} {
+		group, err := a.GetGroup(groupID)
+		if err != nil {
+			return 0, err
+		}
+
+		if group.DeleteAt != 0 {
+			return 0, model.NewAppError("MergeGroups", "app.group.not_found.app_error", nil, "group_id="+groupID, http.StatusNotFound)
+		}
+
+		if group.Source != model.GroupSourceCustom {
+			return 0, model.NewAppError("MergeGroups", "app.group.merge.not_custom.app_error", nil, "group_id="+groupID, http.StatusBadRequest)
+		}
+	}
+
+	result := <-a.Srv.Store.Group().MergeGroups(targetID, sourceID)
+	if result.Err != nil {
+		return 0, result.Err
+	}
+	a.InvalidateCacheForGroupSearch()
+	return result.Data.(int64), nil
+}
+
 func (a *App) CreateGroupSyncable(groupSyncable *model.GroupSyncable) (*model.GroupSyncable, *model.AppError) {
+	if err := a.validateGroupSyncableRoles(groupSyncable); err != nil {
+		return nil, err
+	}
+
 	result := <-a.Srv.Store.Group().CreateGroupSyncable(groupSyncable)
 	if result.Err != nil {
 		return nil, result.Err
@@ -101,6 +694,16 @@ func (a *App) CreateGroupSyncable(groupSyncable *model.GroupSyncable) (*model.Gr
 	return result.Data.(*model.GroupSyncable), nil
 }
 
+// GroupSyncableExists reports whether the group is actively linked to the syncable. Use it instead of
+// GetGroupSyncable when the link's settings are not needed.
+func (a *App) GroupSyncableExists(groupID string, syncableID string, syncableType model.GroupSyncableType) (bool, *model.AppError) {
+	result := <-a.Srv.Store.Group().GroupSyncableExists(groupID, syncableID, syncableType)
+	if result.Err != nil {
+		return false, result.Err
+	}
+	return result.Data.(bool), nil
+}
+
 func (a *App) GetGroupSyncable(groupID string, syncableID string, syncableType model.GroupSyncableType) (*model.GroupSyncable, *model.AppError) {
 	result := <-a.Srv.Store.Group().GetGroupSyncable(groupID, syncableID, syncableType)
 	if result.Err != nil {
@@ -109,6 +712,113 @@ func (a *App) GetGroupSyncable(groupID string, syncableID string, syncableType m
 	return result.Data.(*model.GroupSyncable), nil
 }
 
+// GroupDerivedChannelAccess reports what the group gives the user in the channel, for support staff debugging why a
+// user does or does not have access. It only inspects the group and its link to the channel.
+func (a *App) GroupDerivedChannelAccess(userID, groupID, channelID string) (*model.GroupChannelAccess, *model.AppError) {
+	if _, err := a.GetUser(userID); err != nil {
+		return nil, err
+	}
+
+	if _, err := a.GetGroup(groupID); err != nil {
+		return nil, err
+	}
+
+	if _, err := a.GetChannel(channelID); err != nil {
+		return nil, err
+	}
+
+	members, err := a.GetGroupMembers(groupID, []string{userID})
+	if err != nil {
+		return nil, err
+	}
+
+	syncable, err := a.GetGroupSyncable(groupID, channelID, model.GroupSyncableTypeChannel)
+	if err != nil && err.Id != "store.sql_group.no_rows" {
+		return nil, err
+	}
+
+	access := &model.GroupChannelAccess{
+		UserId:        userID,
+		GroupId:       groupID,
+		ChannelId:     channelID,
+		IsGroupMember: len(members) > 0,
+		IsLinked:      syncable != nil && syncable.DeleteAt == 0,
+	}
+	access.GrantsMembership = access.IsGroupMember && access.IsLinked
+	access.SchemeAdmin = access.GrantsMembership && syncable.SchemeAdmin
+
+	return access, nil
+}
+
+// GetUserGroupProvisionedAccess previews the teams and channels the user is given by the auto-add links of the groups
+// they belong to. It changes nothing, and the teams and channels are listed even if the user is already a member.
+func (a *App) GetUserGroupProvisionedAccess(userID string) (*model.GroupProvisionedAccess, *model.AppError) {
+	if _, err := a.GetUser(userID); err != nil {
+		return nil, err
+	}
+
+	groups, err := a.GetGroupsByUserId(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	teams := map[string]*model.GroupProvisionedSyncable{}
+	channels := map[string]*model.GroupProvisionedSyncable{}
+	for _, group := range groups {
+		for _, syncableType := range []model.GroupSyncableType{model.GroupSyncableTypeTeam, model.GroupSyncableTypeChannel} {
+			syncables, err := a.GetGroupSyncables(group.Id, syncableType)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, syncable := range syncables {
+				// Nobody is added to an archived channel.
+				if !syncable.AutoAdd || syncable.ChannelDeleteAt != 0 {
+					continue
+				}
+
+				provisioned := teams
+				if syncableType == model.GroupSyncableTypeChannel {
+					provisioned = channels
+				}
+
+				if _, ok := provisioned[syncable.SyncableId]; !ok {
+					provisioned[syncable.SyncableId] = &model.GroupProvisionedSyncable{Id: syncable.SyncableId}
+					if syncableType == model.GroupSyncableTypeTeam {
+						provisioned[syncable.SyncableId].DisplayName = syncable.TeamDisplayName
+						provisioned[syncable.SyncableId].Name = syncable.TeamName
+					} else {
+						provisioned[syncable.SyncableId].DisplayName = syncable.ChannelDisplayName
+						provisioned[syncable.SyncableId].Name = syncable.ChannelName
+						provisioned[syncable.SyncableId].TeamId = syncable.TeamID
+					}
+				}
+				provisioned[syncable.SyncableId].GroupIds = append(provisioned[syncable.SyncableId].GroupIds, group.Id)
+			}
+		}
+	}
+
+	return &model.GroupProvisionedAccess{
+		UserId:   userID,
+		Teams:    sortedGroupProvisionedSyncables(teams),
+		Channels: sortedGroupProvisionedSyncables(channels),
+	}, nil
+}
+
+func sortedGroupProvisionedSyncables(provisioned map[string]*model.GroupProvisionedSyncable) []*model.GroupProvisionedSyncable {
+	sorted := make([]*model.GroupProvisionedSyncable, 0, len(provisioned))
+	for _, syncable := range provisioned {
+		sorted = append(sorted, syncable)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].DisplayName != sorted[j].DisplayName {
+			return sorted[i].DisplayName < sorted[j].DisplayName
+		}
+		return sorted[i].Id < sorted[j].Id
+	})
+	return sorted
+}
+
 func (a *App) GetGroupSyncables(groupID string, syncableType model.GroupSyncableType) ([]*model.GroupSyncable, *model.AppError) {
 	result := <-a.Srv.Store.Group().GetAllGroupSyncablesByGroupId(groupID, syncableType)
 	if result.Err != nil {
@@ -117,7 +827,117 @@ func (a *App) GetGroupSyncables(groupID string, syncableType model.GroupSyncable
 	return result.Data.([]*model.GroupSyncable), nil
 }
 
+// GetTeamGroupSyncables returns a page of the active links of groups to the team, each with the display name of its
+// group, sorted by that name.
+func (a *App) GetTeamGroupSyncables(teamID string, page, perPage int) ([]*model.GroupSyncable, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetTeamGroupSyncables(teamID, page, perPage)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Data.([]*model.GroupSyncable), nil
+}
+
+// GetGroupSyncablesExpanded returns the group's active team or channel links along with the team or channel each links
+// to.
+func (a *App) GetGroupSyncablesExpanded(groupID string, syncableType model.GroupSyncableType) ([]*model.GroupSyncableExpanded, *model.AppError) {
+	groupSyncables, err := a.GetGroupSyncables(groupID, syncableType)
+	if err != nil {
+		return nil, err
+	}
+
+	expanded := make([]*model.GroupSyncableExpanded, 0, len(groupSyncables))
+	for _, groupSyncable := range groupSyncables {
+		expanded = append(expanded, model.NewGroupSyncableExpanded(groupSyncable))
+	}
+	return expanded, nil
+}
+
+// GetGroupSyncablesMatrix reports which of the given teams or channels each of the given groups is linked to, keyed by
+// group id. Groups linked to none of them are left out.
+func (a *App) GetGroupSyncablesMatrix(groupIDs, syncableIDs []string, syncableType model.GroupSyncableType) (map[string][]string, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetLinkedSyncableIds(groupIDs, syncableIDs, syncableType)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Data.(map[string][]string), nil
+}
+
+// GetGroupAdminSyncables returns a page of the group's active team and channel links that are marked scheme admin,
+// i.e. everywhere the group makes its members admins. Team links are listed before channel links, each sorted by
+// display name.
+func (a *App) GetGroupAdminSyncables(groupID string, page, perPage int) ([]*model.GroupSyncable, *model.AppError) {
+	var adminSyncables []*model.GroupSyncable
+	for _, syncableType := range []model.GroupSyncableType{model.GroupSyncableTypeTeam, model.GroupSyncableTypeChannel} {
+		groupSyncables, err := a.GetGroupSyncables(groupID, syncableType)
+		if err != nil {
+			return nil, err
+		}
+
+		var typeSyncables []*model.GroupSyncable
+		for _, groupSyncable := range groupSyncables {
+			if groupSyncable.SchemeAdmin {
+				typeSyncables = append(typeSyncables, groupSyncable)
+			}
+		}
+
+		sort.Slice(typeSyncables, func(i, j int) bool {
+			if syncableType == model.GroupSyncableTypeTeam {
+				return typeSyncables[i].TeamDisplayName < typeSyncables[j].TeamDisplayName
+			}
+			return typeSyncables[i].ChannelDisplayName < typeSyncables[j].ChannelDisplayName
+		})
+
+		adminSyncables = append(adminSyncables, typeSyncables...)
+	}
+
+	start := page * perPage
+	if start >= len(adminSyncables) {
+		return []*model.GroupSyncable{}, nil
+	}
+	end := start + perPage
+	if end > len(adminSyncables) {
+		end = len(adminSyncables)
+	}
+
+	return adminSyncables[start:end], nil
+}
+
+// GetGroupWithSyncables returns the group along with up to limit of its active team and channel links of each type.
+func (a *App) GetGroupWithSyncables(group *model.Group, limit int) (*model.GroupWithSyncables, *model.AppError) {
+	teams, err := a.GetGroupSyncables(group.Id, model.GroupSyncableTypeTeam)
+	if err != nil {
+		return nil, err
+	}
+
+	channels, err := a.GetGroupSyncables(group.Id, model.GroupSyncableTypeChannel)
+	if err != nil {
+		return nil, err
+	}
+
+	groupWithSyncables := &model.GroupWithSyncables{
+		Group:    group,
+		Teams:    teams,
+		Channels: channels,
+	}
+
+	if len(teams) > limit {
+		groupWithSyncables.Teams = teams[:limit]
+		groupWithSyncables.TeamsHasMore = true
+	}
+
+	if len(channels) > limit {
+		groupWithSyncables.Channels = channels[:limit]
+		groupWithSyncables.ChannelsHasMore = true
+	}
+
+	return groupWithSyncables, nil
+}
+
 func (a *App) UpdateGroupSyncable(groupSyncable *model.GroupSyncable) (*model.GroupSyncable, *model.AppError) {
+	if err := a.validateGroupSyncableRoles(groupSyncable); err != nil {
+		return nil, err
+	}
+
 	result := <-a.Srv.Store.Group().UpdateGroupSyncable(groupSyncable)
 	if result.Err != nil {
 		return nil, result.Err
@@ -125,14 +945,227 @@ func (a *App) UpdateGroupSyncable(groupSyncable *model.GroupSyncable) (*model.Gr
 	return result.Data.(*model.GroupSyncable), nil
 }
 
+// validateGroupSyncableRoles checks that the roles of a link are the member or admin roles of the scheme of the channel
+// it links to, so that they can be given to the members the group brings in. Only channel links can have roles.
+func (a *App) validateGroupSyncableRoles(groupSyncable *model.GroupSyncable) *model.AppError {
+	if len(strings.TrimSpace(groupSyncable.Roles)) == 0 {
+		return nil
+	}
+
+	if groupSyncable.Type != model.GroupSyncableTypeChannel {
+		return model.NewAppError("validateGroupSyncableRoles", "api.group.syncable.invalid_roles", nil, "roles="+groupSyncable.Roles, http.StatusBadRequest)
+	}
+
+	_, schemeUserRole, schemeAdminRole, err := a.GetSchemeRolesForChannel(groupSyncable.SyncableId)
+	if err != nil {
+		return err
+	}
+
+	for _, roleName := range strings.Fields(groupSyncable.Roles) {
+		if roleName != schemeUserRole && roleName != schemeAdminRole {
+			return model.NewAppError("validateGroupSyncableRoles", "api.group.syncable.invalid_roles", nil, "role_name="+roleName, http.StatusBadRequest)
+		}
+	}
+
+	return nil
+}
+
+// LinkGroupDefaultChannel makes sure the team has a private channel named after the group and that the group is
+// linked to it with auto-add. The channel is reused if it already exists, so linking the group to the team again
+// does not create a second one.
+func (a *App) LinkGroupDefaultChannel(group *model.Group, teamID string) (*model.Channel, *model.AppError) {
+	name := groupDefaultChannelName(group)
+
+	channel, err := a.GetChannelByName(name, teamID, true)
+	if err != nil && err.StatusCode != http.StatusNotFound {
+		return nil, err
+	}
+
+	if channel != nil && channel.DeleteAt != 0 {
+		return nil, model.NewAppError("LinkGroupDefaultChannel", "app.group.default_channel.archived.app_error", map[string]interface{}{"Name": name}, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	if channel == nil {
+		displayName := group.DisplayName
+		if utf8.RuneCountInString(displayName) > model.CHANNEL_DISPLAY_NAME_MAX_RUNES {
+			displayName = string([]rune(displayName)[:model.CHANNEL_DISPLAY_NAME_MAX_RUNES])
+		}
+
+		channel, err = a.CreateChannel(&model.Channel{
+			TeamId:      teamID,
+			Name:        name,
+			DisplayName: displayName,
+			Type:        model.CHANNEL_PRIVATE,
+			CreatorId:   a.Session.UserId,
+		}, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	groupSyncable, err := a.GetGroupSyncable(group.Id, channel.Id, model.GroupSyncableTypeChannel)
+	if err != nil && err.Id != "store.sql_group.no_rows" {
+		return nil, err
+	}
+
+	if groupSyncable == nil {
+		_, err = a.CreateGroupSyncable(model.NewGroupChannel(group.Id, channel.Id, true))
+	} else if groupSyncable.DeleteAt != 0 || !groupSyncable.AutoAdd {
+		groupSyncable.DeleteAt = 0
+		groupSyncable.AutoAdd = true
+		_, err = a.UpdateGroupSyncable(groupSyncable)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+// groupDefaultChannelName derives a channel name from the group name, falling back to the group id if nothing valid
+// is left.
+func groupDefaultChannelName(group *model.Group) string {
+	name := invalidChannelNameChars.ReplaceAllString(strings.ToLower(group.Name), "-")
+	if len(name) > model.CHANNEL_NAME_MAX_LENGTH {
+		name = name[:model.CHANNEL_NAME_MAX_LENGTH]
+	}
+	name = strings.Trim(name, "-_")
+
+	if !model.IsValidChannelIdentifier(name) {
+		return group.Id
+	}
+	return name
+}
+
+// DeleteGroupSyncable soft deletes the link between the group and the team or channel. Deleting a link that is
+// already deleted succeeds and returns it unchanged, so that retried requests are safe.
 func (a *App) DeleteGroupSyncable(groupID string, syncableID string, syncableType model.GroupSyncableType) (*model.GroupSyncable, *model.AppError) {
 	result := <-a.Srv.Store.Group().DeleteGroupSyncable(groupID, syncableID, syncableType)
 	if result.Err != nil {
+		if result.Err.Id == "store.sql_group.group_syncable_already_deleted" {
+			return a.GetGroupSyncable(groupID, syncableID, syncableType)
+		}
 		return nil, result.Err
 	}
 	return result.Data.(*model.GroupSyncable), nil
 }
 
+// PromoteChannelGroupsToTeam links each group linked to the channel to the channel's team as well, with the same
+// auto-add setting. Groups already linked to the team are skipped, and a deleted team link is restored. If
+// removeChannelLinks is set, the channel links are removed once the team links are in place.
+func (a *App) PromoteChannelGroupsToTeam(channel *model.Channel, removeChannelLinks bool) (*model.GroupPromotionResult, *model.AppError) {
+	var groups []*model.Group
+	for page := 0; ; page++ {
+		pageGroups, err := a.GetGroupsByChannel(channel.Id, page, GROUP_PROMOTION_PAGE_SIZE, model.GroupSearchOpts{})
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, pageGroups...)
+		if len(pageGroups) < GROUP_PROMOTION_PAGE_SIZE {
+			break
+		}
+	}
+
+	result := &model.GroupPromotionResult{}
+	for _, group := range groups {
+		channelSyncable, err := a.GetGroupSyncable(group.Id, channel.Id, model.GroupSyncableTypeChannel)
+		if err != nil {
+			return nil, err
+		}
+
+		teamSyncable, err := a.GetGroupSyncable(group.Id, channel.TeamId, model.GroupSyncableTypeTeam)
+		if err != nil && err.Id != "store.sql_group.no_rows" {
+			return nil, err
+		}
+
+		if teamSyncable != nil && teamSyncable.DeleteAt == 0 {
+			result.Skipped++
+		} else {
+			if teamSyncable == nil {
+				_, err = a.CreateGroupSyncable(model.NewGroupTeam(group.Id, channel.TeamId, channelSyncable.AutoAdd))
+			} else {
+				teamSyncable.DeleteAt = 0
+				teamSyncable.AutoAdd = channelSyncable.AutoAdd
+				_, err = a.UpdateGroupSyncable(teamSyncable)
+			}
+			if err != nil {
+				return nil, err
+			}
+			result.Promoted++
+		}
+
+		if removeChannelLinks {
+			if _, err = a.DeleteGroupSyncable(group.Id, channel.Id, model.GroupSyncableTypeChannel); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// DeleteGroupSyncables unlinks the group from each of the given syncables. A syncable that the group is not linked to,
+// or whose link is already deleted, is reported as not linked rather than as an error.
+func (a *App) DeleteGroupSyncables(groupID string, syncableIDs []string, syncableType model.GroupSyncableType) []*model.GroupSyncableStatus {
+	statuses := make([]*model.GroupSyncableStatus, 0, len(syncableIDs))
+	for _, syncableID := range syncableIDs {
+		status := &model.GroupSyncableStatus{SyncableId: syncableID, Status: model.GroupSyncableStatusUnlinked}
+		if result := <-a.Srv.Store.Group().DeleteGroupSyncable(groupID, syncableID, syncableType); result.Err != nil {
+			err := result.Err
+			switch err.Id {
+			case "store.sql_group.no_rows", "store.sql_group.group_syncable_already_deleted":
+				status.Status = model.GroupSyncableStatusNotLinked
+			default:
+				status.Status = model.GroupSyncableStatusError
+				status.Error = err
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// GroupDeleteImpact reports how many group-constrained teams and channels rely on the group and how many of their
+// members would lose access if it were deleted. It changes nothing and is meant to be consulted before DeleteGroup.
+func (a *App) GroupDeleteImpact(groupID string) (*model.GroupDeleteImpact, *model.AppError) {
+	if _, err := a.GetGroup(groupID); err != nil {
+		return nil, err
+	}
+
+	result := <-a.Srv.Store.Group().GetDeleteImpact(groupID)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Data.(*model.GroupDeleteImpact), nil
+}
+
+func (a *App) GetGroupStats(groupID string, includeDeleted bool) (*model.GroupStats, *model.AppError) {
+	if _, err := a.GetGroup(groupID); err != nil {
+		return nil, err
+	}
+
+	result := <-a.Srv.Store.Group().GetStats(groupID, includeDeleted)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Data.(*model.GroupStats), nil
+}
+
+// IsLastGroupOfConstrainedChannel reports whether the group is the only group linked to a group-constrained channel,
+// in which case unlinking it would leave the channel without any permitted members.
+func (a *App) IsLastGroupOfConstrainedChannel(groupID string, channel *model.Channel) (bool, *model.AppError) {
+	if channel.GroupConstrained == nil || !*channel.GroupConstrained {
+		return false, nil
+	}
+
+	groups, err := a.GetGroupsByChannel(channel.Id, 0, 2, model.GroupSearchOpts{})
+	if err != nil {
+		return false, err
+	}
+
+	return len(groups) == 1 && groups[0].Id == groupID, nil
+}
+
 func (a *App) TeamMembersToAdd(since int64) ([]*model.UserTeamIDPair, *model.AppError) {
 	result := <-a.Srv.Store.Group().TeamMembersToAdd(since)
 	if result.Err != nil {
@@ -165,18 +1198,241 @@ func (a *App) ChannelMembersToRemove() ([]*model.ChannelMember, *model.AppError)
 	return result.Data.([]*model.ChannelMember), nil
 }
 
-func (a *App) GetGroupsByChannel(channelId string, page, perPage int) ([]*model.Group, *model.AppError) {
-	result := <-a.Srv.Store.Group().GetGroupsByChannel(channelId, page, perPage)
+// ExemptChannelMemberFromGroupConstraint keeps the member of the channel from being removed by the group constraint
+// of the channel, even though they belong to none of the groups linked to it.
+func (a *App) ExemptChannelMemberFromGroupConstraint(channelID, userID string) (*model.GroupChannelExemption, *model.AppError) {
+	if _, err := a.GetChannelMember(channelID, userID); err != nil {
+		return nil, err
+	}
+
+	result := <-a.Srv.Store.Group().CreateChannelExemption(&model.GroupChannelExemption{ChannelId: channelID, UserId: userID})
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Data.(*model.GroupChannelExemption), nil
+}
+
+func (a *App) GetGroupsByChannel(channelId string, page, perPage int, opts model.GroupSearchOpts) ([]*model.Group, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetGroupsByChannel(channelId, page, perPage, opts)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Data.([]*model.Group), nil
+}
+
+func (a *App) GetGroupsByTeam(teamId string, page, perPage int, opts model.GroupSearchOpts) ([]*model.Group, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetGroupsByTeam(teamId, page, perPage, opts)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Data.([]*model.Group), nil
+}
+
+// GetChannelGroupsMissingTeamLink returns the groups linked to the channel without being linked to its team, which
+// usually means the links were set up inconsistently.
+func (a *App) GetChannelGroupsMissingTeamLink(channelID string) ([]*model.Group, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetGroupsMissingTeamLink(channelID)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Data.([]*model.Group), nil
+}
+
+// GetGroupsByIDs returns the undeleted groups with the given ids, skipping unknown ids.
+func (a *App) GetGroupsByIDs(groupIDs []string) ([]*model.Group, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetByIDs(groupIDs)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Data.([]*model.Group), nil
+}
+
+// ResolveGroupMembershipConflicts returns the members of the channel who belong to a group whose link to the channel
+// excludes its members. Exclusion wins over inclusion: the group sync never adds such a user to the channel, even when
+// another group of theirs is linked with auto-add, and a user already in the channel is reported here for an admin to
+// remove.
+func (a *App) ResolveGroupMembershipConflicts(channelID string) ([]*model.GroupMembershipConflict, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetChannelMembershipConflicts(channelID)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Data.([]*model.GroupMembershipConflict), nil
+}
+
+// AutocompleteGroups returns the referenceable groups whose name starts with namePrefix, ignoring case, capped at
+// model.GroupAutocompleteLimit. The mention typeahead repeats the same searches as users type, so results are cached
+// briefly, keyed by the lowercased search alone since they do not depend on where it was made from.
+func (a *App) AutocompleteGroups(namePrefix string) ([]*model.Group, *model.AppError) {
+	key := strings.ToLower(strings.TrimSpace(namePrefix))
+
+	if cached, ok := a.Srv.groupSearchCache.Get(key); ok {
+		if a.Metrics != nil {
+			a.Metrics.IncrementMemCacheHitCounter(a.Srv.groupSearchCache.Name())
+		}
+		return copyGroups(cached.([]*model.Group)), nil
+	}
+
+	if a.Metrics != nil {
+		a.Metrics.IncrementMemCacheMissCounter(a.Srv.groupSearchCache.Name())
+	}
+
+	result := <-a.Srv.Store.Group().Autocomplete(key, model.GroupAutocompleteLimit)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	groups := result.Data.([]*model.Group)
+
+	a.Srv.groupSearchCache.AddWithDefaultExpires(key, copyGroups(groups))
+
+	return groups, nil
+}
+
+// copyGroups copies the groups so that callers sanitizing the groups they are given cannot change the cached ones.
+func copyGroups(groups []*model.Group) []*model.Group {
+	copies := make([]*model.Group, 0, len(groups))
+	for _, group := range groups {
+		groupCopy := *group
+		copies = append(copies, &groupCopy)
+	}
+	return copies
+}
+
+// InvalidateCacheForGroupSearch drops the cached group searches on every server in the cluster, after a group has
+// been created, changed or deleted.
+func (a *App) InvalidateCacheForGroupSearch() {
+	a.InvalidateCacheForGroupSearchSkipClusterSend()
+
+	if a.Cluster != nil {
+		msg := &model.ClusterMessage{
+			Event:    model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_GROUP_SEARCH,
+			SendType: model.CLUSTER_SEND_BEST_EFFORT,
+		}
+		a.Cluster.SendClusterMessage(msg)
+	}
+}
+
+func (a *App) InvalidateCacheForGroupSearchSkipClusterSend() {
+	a.Srv.groupSearchCache.Purge()
+
+	if a.Metrics != nil {
+		a.Metrics.IncrementMemCacheInvalidationCounter(a.Srv.groupSearchCache.Name())
+	}
+}
+
+// GetUnusedGroups returns a page of the groups that have been neither linked nor had member changes since opts.Since.
+func (a *App) GetUnusedGroups(page, perPage int, opts model.UnusedGroupSearchOpts) ([]*model.Group, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetUnusedGroups(page, perPage, opts)
 	if result.Err != nil {
 		return nil, result.Err
 	}
 	return result.Data.([]*model.Group), nil
 }
 
-func (a *App) GetGroupsByTeam(teamId string, page, perPage int) ([]*model.Group, *model.AppError) {
-	result := <-a.Srv.Store.Group().GetGroupsByTeam(teamId, page, perPage)
+// GetStaleLdapGroups returns a page of the LDAP groups whose members were last synced before since, oldest first.
+func (a *App) GetStaleLdapGroups(since int64, page, perPage int) ([]*model.Group, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetStaleLdapGroups(since, page, perPage)
 	if result.Err != nil {
 		return nil, result.Err
 	}
 	return result.Data.([]*model.Group), nil
 }
+
+// GetGroupsByMemberEmailDomain returns a page of the groups with at least one active member whose email address is at
+// domain.
+func (a *App) GetGroupsByMemberEmailDomain(domain string, page, perPage int) ([]*model.Group, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetGroupsByMemberEmailDomain(domain, page, perPage)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Data.([]*model.Group), nil
+}
+
+func (a *App) GetGroups(page, perPage int, opts model.GroupSearchOpts) ([]*model.Group, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetGroups(page, perPage, opts)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Data.([]*model.Group), nil
+}
+
+// GetGroupsLastUpdateAt returns the time of the latest change to any group matching opts, including deletions, or zero
+// if there are no such groups.
+func (a *App) GetGroupsLastUpdateAt(opts model.GroupSearchOpts) (int64, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetGroupsLastUpdateAt(opts)
+	if result.Err != nil {
+		return 0, result.Err
+	}
+	return result.Data.(int64), nil
+}
+
+// CreateGroupChannelPattern saves a channel name pattern for the group and links the group to the existing channels
+// that match it. Channels created later are linked by the group channel pattern task.
+func (a *App) CreateGroupChannelPattern(pattern *model.GroupChannelPattern) (*model.GroupChannelPattern, *model.AppError) {
+	result := <-a.Srv.Store.Group().CreateChannelPattern(pattern)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	pattern = result.Data.(*model.GroupChannelPattern)
+
+	if _, err := a.linkGroupChannelPattern(pattern, 0); err != nil {
+		return nil, err
+	}
+
+	return pattern, nil
+}
+
+func (a *App) GetGroupChannelPatterns(groupID string) ([]*model.GroupChannelPattern, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetChannelPatterns(groupID)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Data.([]*model.GroupChannelPattern), nil
+}
+
+// LinkGroupChannelPatterns links each group to the channels created at or after since whose names match one of its
+// channel name patterns. A channel the group was linked to before, even if the link was later removed, is left alone,
+// so a run can be repeated. A pattern that fails does not stop the others: the outcome of each is returned, and the
+// error is only set when the patterns cannot be listed.
+func (a *App) LinkGroupChannelPatterns(since int64) ([]*model.GroupChannelPatternStatus, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetAllChannelPatterns()
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	patterns := result.Data.([]*model.GroupChannelPattern)
+	statuses := make([]*model.GroupChannelPatternStatus, 0, len(patterns))
+	for _, pattern := range patterns {
+		linked, err := a.linkGroupChannelPattern(pattern, since)
+		statuses = append(statuses, &model.GroupChannelPatternStatus{
+			GroupId: pattern.GroupId,
+			Pattern: pattern.Pattern,
+			Linked:  linked,
+			Error:   err,
+		})
+	}
+
+	return statuses, nil
+}
+
+// linkGroupChannelPattern links the group to the unlinked channels created at or after since that match the pattern,
+// and returns how many it linked.
+func (a *App) linkGroupChannelPattern(pattern *model.GroupChannelPattern, since int64) (int, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetUnlinkedChannelsCreatedSince(pattern.GroupId, pattern.LiteralPrefix(), since)
+	if result.Err != nil {
+		return 0, result.Err
+	}
+
+	linked := 0
+	for _, channel := range result.Data.([]*model.Channel) {
+		if !pattern.Matches(channel.Name) {
+			continue
+		}
+
+		if _, err := a.CreateGroupSyncable(model.NewGroupChannel(pattern.GroupId, channel.Id, pattern.AutoAdd)); err != nil {
+			return linked, err
+		}
+		linked++
+	}
+
+	return linked, nil
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// groupMessageBatchSize is the number of members a group message job looks up at a time.
+const groupMessageBatchSize = 100
+
+// SendGroupMessage starts sending the message to each member of the group as a direct message from the sender, and
+// returns the job that tracks it. Groups with more members than GroupSettings.MaxMessageFanout are refused.
+//
+// The job is run by this server in the background rather than by the job workers. Its data records the number of
+// messages sent and that failed to send once it is done.
+func (a *App) SendGroupMessage(groupID, senderID, message string) (*model.Job, *model.AppError) {
+	if _, err := a.GetGroup(groupID); err != nil {
+		return nil, err
+	}
+
+	result := <-a.Srv.Store.Group().GetMemberCount(groupID)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	memberCount := result.Data.(int64)
+
+	maxFanout := *a.Config().GroupSettings.MaxMessageFanout
+	if memberCount > int64(maxFanout) {
+		return nil, model.NewAppError("SendGroupMessage", "app.group.message.too_many_members.app_error", map[string]interface{}{"Max": maxFanout}, "group_id="+groupID, http.StatusRequestEntityTooLarge)
+	}
+
+	job, err := a.Srv.Jobs.CreateJob(model.JOB_TYPE_GROUP_MESSAGE, map[string]string{
+		"group_id":     groupID,
+		"sender_id":    senderID,
+		"member_count": strconv.FormatInt(memberCount, 10),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Claim the job straight away so that it is never mistaken for one waiting on a worker.
+	if _, err = a.Srv.Jobs.ClaimJob(job); err != nil {
+		return nil, err
+	}
+	job.Status = model.JOB_STATUS_IN_PROGRESS
+
+	running := *job
+	running.Data = model.CopyStringMap(job.Data)
+	a.Srv.Go(func() {
+		a.runGroupMessageJob(&running, groupID, senderID, message, memberCount)
+	})
+
+	return job, nil
+}
+
+func (a *App) runGroupMessageJob(job *model.Job, groupID, senderID, message string, memberCount int64) {
+	var sent, failed int64
+	for page := 0; ; page++ {
+		result := <-a.Srv.Store.Group().GetMemberIdsPage(groupID, page, groupMessageBatchSize)
+		if result.Err != nil {
+			mlog.Error("Failed to list the members of a group to message", mlog.String("group_id", groupID), mlog.Err(result.Err))
+			if err := a.Srv.Jobs.SetJobError(job, result.Err); err != nil {
+				mlog.Error("Failed to set the group message job error", mlog.String("job_id", job.Id), mlog.Err(err))
+			}
+			return
+		}
+		userIds := result.Data.([]string)
+
+		for _, userId := range userIds {
+			if userId == senderID {
+				continue
+			}
+
+			if err := a.sendGroupMessageToMember(senderID, userId, message); err != nil {
+				mlog.Warn("Failed to send a group message to a member", mlog.String("group_id", groupID), mlog.String("user_id", userId), mlog.Err(err))
+				failed++
+				continue
+			}
+			sent++
+		}
+
+		job.Data["sent_count"] = strconv.FormatInt(sent, 10)
+		job.Data["failed_count"] = strconv.FormatInt(failed, 10)
+
+		if len(userIds) < groupMessageBatchSize {
+			break
+		}
+
+		if memberCount > 0 {
+			if err := a.Srv.Jobs.SetJobProgress(job, (sent+failed)*100/memberCount); err != nil {
+				mlog.Error("Failed to set the group message job progress", mlog.String("job_id", job.Id), mlog.Err(err))
+			}
+		}
+	}
+
+	job.Progress = 100
+	if err := a.Srv.Jobs.UpdateInProgressJobData(job); err != nil {
+		mlog.Error("Failed to update the group message job", mlog.String("job_id", job.Id), mlog.Err(err))
+	}
+	if err := a.Srv.Jobs.SetJobSuccess(job); err != nil {
+		mlog.Error("Failed to set the group message job success", mlog.String("job_id", job.Id), mlog.Err(err))
+	}
+}
+
+func (a *App) sendGroupMessageToMember(senderID, userID, message string) *model.AppError {
+	channel, err := a.GetOrCreateDirectChannel(senderID, userID)
+	if err != nil {
+		return err
+	}
+
+	post := &model.Post{
+		UserId:    senderID,
+		ChannelId: channel.Id,
+		Message:   message,
+	}
+	_, err = a.CreatePost(post, channel, true)
+	return err
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// GroupReconcileFunc brings what depends on the members of a group in line with them, e.g. the teams and channels the
+// group is linked to. It is given the group as it is stored when the reconciliation runs.
+type GroupReconcileFunc func(group *model.Group) *model.AppError
+
+// groupReconcileQueue collapses the reconciliation requests made for a group within
+// GroupSettings.ReconcileDebounceSeconds of each other into a single run.
+type groupReconcileQueue struct {
+	mutex   sync.Mutex
+	pending map[string]*pendingGroupReconcile
+	running map[string]bool
+	stopped bool
+}
+
+// pendingGroupReconcile is a reconciliation that has been requested for a group but has not started yet. reconcile is
+// the function given by the latest request.
+type pendingGroupReconcile struct {
+	timer     *time.Timer
+	reconcile GroupReconcileFunc
+}
+
+func newGroupReconcileQueue() *groupReconcileQueue {
+	return &groupReconcileQueue{
+		pending: map[string]*pendingGroupReconcile{},
+		running: map[string]bool{},
+	}
+}
+
+// RequestGroupReconcile reconciles the group once no other reconciliation has been requested for it for
+// GroupSettings.ReconcileDebounceSeconds. A request made while another one is waiting replaces it and restarts the
+// wait, so that a group changing constantly is reconciled once its changes settle. The group is read when the
+// reconciliation starts rather than when it is requested, so that the run reflects the latest state. Without a
+// debounce window the group is reconciled immediately.
+func (a *App) RequestGroupReconcile(groupID string, reconcile GroupReconcileFunc) *model.AppError {
+	window := time.Duration(*a.Config().GroupSettings.ReconcileDebounceSeconds) * time.Second
+	if window <= 0 {
+		return a.reconcileGroup(groupID, reconcile)
+	}
+
+	queue := a.Srv.groupReconcileQueue
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	if queue.stopped {
+		return nil
+	}
+
+	if pending, ok := queue.pending[groupID]; ok {
+		pending.reconcile = reconcile
+		// If the timer has already fired, the run has not started yet and will use this request's function.
+		if pending.timer.Stop() {
+			pending.timer.Reset(window)
+		}
+
+		if a.Metrics != nil {
+			a.Metrics.IncrementGroupReconcileCoalescedCounter()
+		}
+		return nil
+	}
+
+	queue.pending[groupID] = &pendingGroupReconcile{
+		timer:     time.AfterFunc(window, func() { a.runQueuedGroupReconcile(groupID, window) }),
+		reconcile: reconcile,
+	}
+
+	return nil
+}
+
+// runQueuedGroupReconcile starts the pending reconciliation of the group. If the previous one is still running, it is
+// put off for another debounce window so that two reconciliations of a group never overlap.
+func (a *App) runQueuedGroupReconcile(groupID string, window time.Duration) {
+	queue := a.Srv.groupReconcileQueue
+	queue.mutex.Lock()
+
+	pending, ok := queue.pending[groupID]
+	if !ok || queue.stopped {
+		queue.mutex.Unlock()
+		return
+	}
+
+	if queue.running[groupID] {
+		pending.timer.Reset(window)
+		queue.mutex.Unlock()
+		return
+	}
+
+	delete(queue.pending, groupID)
+	queue.running[groupID] = true
+	queue.mutex.Unlock()
+
+	a.Srv.Go(func() {
+		defer func() {
+			queue.mutex.Lock()
+			delete(queue.running, groupID)
+			queue.mutex.Unlock()
+		}()
+
+		if err := a.reconcileGroup(groupID, pending.reconcile); err != nil {
+			mlog.Error("Failed to reconcile group", mlog.String("group_id", groupID), mlog.Err(err))
+		}
+	})
+}
+
+func (a *App) reconcileGroup(groupID string, reconcile GroupReconcileFunc) *model.AppError {
+	group, err := a.GetGroup(groupID)
+	if err != nil {
+		return err
+	}
+
+	return reconcile(group)
+}
+
+// stop drops the reconciliations that have not started yet and refuses new ones, for the server to shut down.
+func (queue *groupReconcileQueue) stop() {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	queue.stopped = true
+	for groupID, pending := range queue.pending {
+		pending.timer.Stop()
+		delete(queue.pending, groupID)
+	}
+}
+
+// reconcileGroupSyncables adds the members of the group to the teams and channels it is linked to with auto-add, so
+// that a change to its members reaches them without waiting for the next sync of default memberships.
+func (a *App) reconcileGroupSyncables(group *model.Group) *model.AppError {
+	teamSyncables, err := a.GetGroupSyncables(group.Id, model.GroupSyncableTypeTeam)
+	if err != nil {
+		return err
+	}
+
+	members, err := a.GetGroupMemberUsers(group.Id)
+	if err != nil {
+		return err
+	}
+
+	for _, groupSyncable := range teamSyncables {
+		if !groupSyncable.AutoAdd {
+			continue
+		}
+
+		for _, user := range members {
+			tmem, err := a.GetTeamMember(groupSyncable.SyncableId, user.Id)
+			if err != nil && err.Id != "store.sql_team.get_member.missing.app_error" {
+				return err
+			}
+			if tmem != nil && tmem.DeleteAt == 0 {
+				continue
+			}
+
+			if err = a.addGroupSyncedTeamMember(groupSyncable.SyncableId, user.Id, groupSyncable.SuppressNotifications); err != nil {
+				return err
+			}
+			a.Log.Info("added teammember",
+				mlog.String("user_id", user.Id),
+				mlog.String("team_id", groupSyncable.SyncableId),
+			)
+		}
+	}
+
+	channelSyncables, err := a.GetGroupSyncables(group.Id, model.GroupSyncableTypeChannel)
+	if err != nil {
+		return err
+	}
+
+	for _, groupSyncable := range channelSyncables {
+		if !groupSyncable.AutoAdd {
+			continue
+		}
+
+		if _, _, err := a.AddGroupMembersToChannel(groupSyncable); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/einterfaces/mocks"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestRequestGroupReconcile(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	group := th.CreateGroup()
+
+	type reconcileRun struct {
+		request     int
+		displayName string
+	}
+	runs := make(chan reconcileRun, 10)
+	reconcile := func(request int) GroupReconcileFunc {
+		return func(group *model.Group) *model.AppError {
+			runs <- reconcileRun{request: request, displayName: group.DisplayName}
+			return nil
+		}
+	}
+
+	t.Run("without a debounce window", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.GroupSettings.ReconcileDebounceSeconds = 0 })
+
+		require.Nil(t, th.App.RequestGroupReconcile(group.Id, reconcile(0)))
+		require.Len(t, runs, 1)
+		<-runs
+	})
+
+	t.Run("rapid requests are coalesced", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.GroupSettings.ReconcileDebounceSeconds = 1 })
+
+		metricsMock := &mocks.MetricsInterface{}
+		metricsMock.On("IncrementGroupReconcileCoalescedCounter").Return()
+		metricsMock.On("IncrementMemCacheInvalidationCounter", "GroupSearch").Return()
+		th.App.Metrics = metricsMock
+		defer func() { th.App.Metrics = nil }()
+
+		for i := 1; i <= 5; i++ {
+			group.DisplayName = fmt.Sprintf("dn_%d", i)
+			var err *model.AppError
+			group, err = th.App.UpdateGroup(group)
+			require.Nil(t, err)
+
+			require.Nil(t, th.App.RequestGroupReconcile(group.Id, reconcile(i)))
+		}
+
+		select {
+		case run := <-runs:
+			// The single run uses the latest request and sees the group as it was last changed.
+			assert.Equal(t, 5, run.request)
+			assert.Equal(t, "dn_5", run.displayName)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "the group was not reconciled")
+		}
+
+		select {
+		case run := <-runs:
+			assert.Fail(t, "the group was reconciled more than once", "request %d", run.request)
+		case <-time.After(1500 * time.Millisecond):
+		}
+
+		metricsMock.AssertNumberOfCalls(t, "IncrementGroupReconcileCoalescedCounter", 4)
+	})
+}
+
+func TestGroupMembershipChangeReconcilesSyncables(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.GroupSettings.ReconcileDebounceSeconds = 1 })
+	defer th.App.UpdateConfig(func(cfg *model.Config) { *cfg.GroupSettings.ReconcileDebounceSeconds = 0 })
+
+	group := th.CreateGroup()
+	_, err := th.App.CreateGroupSyncable(&model.GroupSyncable{
+		GroupId:    group.Id,
+		SyncableId: th.BasicChannel.Id,
+		Type:       model.GroupSyncableTypeChannel,
+		AutoAdd:    true,
+	})
+	require.Nil(t, err)
+
+	user := th.CreateUser()
+	_, err = th.App.UpsertGroupMembers(group.Id, []string{user.Id})
+	require.Nil(t, err)
+
+	// Nothing is added until the group's changes settle.
+	_, err = th.App.GetChannelMember(th.BasicChannel.Id, user.Id)
+	require.NotNil(t, err)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err = th.App.GetChannelMember(th.BasicChannel.Id, user.Id); err == nil || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	require.Nil(t, err, "the user was not added to the channel linked to the group")
+
+	_, err = th.App.GetTeamMember(th.BasicTeam.Id, user.Id)
+	assert.Nil(t, err)
+}
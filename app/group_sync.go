@@ -0,0 +1,573 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-ldap/ldap"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+var invalidGroupNameChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+const (
+	GROUP_SYNC_WEBHOOK_MAX_ATTEMPTS = 5
+	GROUP_MEMBER_FETCH_MAX_ATTEMPTS = 5
+
+	// GROUP_NAME_COLLISION_MAX_SUFFIX is the highest suffix tried for the name of an LDAP group whose name is taken.
+	GROUP_NAME_COLLISION_MAX_SUFFIX = 100
+
+	// LDAP_GROUP_SYNC_PAGE_SIZE is how many groups the LDAP group sync reads from the LDAP server at a time.
+	LDAP_GROUP_SYNC_PAGE_SIZE = 1000
+)
+
+// groupSyncWebhookBackoff is the delay before the first retry of a failed delivery, doubled after each attempt.
+var groupSyncWebhookBackoff = time.Second
+
+// groupMemberFetchBackoff is the delay before the first retry of a transient member fetch failure, doubled after each
+// attempt.
+var groupMemberFetchBackoff = 500 * time.Millisecond
+
+// GroupMemberFetchFunc returns the remote ids of the members of a group from its source, e.g. an LDAP server.
+type GroupMemberFetchFunc func(group *model.Group) ([]string, error)
+
+// GroupMemberFetchResult holds the outcome of fetching the members of one group. Err is set if the members could not
+// be fetched, in which case the group should be skipped for this sync.
+type GroupMemberFetchResult struct {
+	MemberRemoteIds []string
+	Err             error
+}
+
+// FetchGroupMembers fetches the members of each group, running at most GroupSettings.SyncConcurrency fetches at a
+// time so that a sync does not overwhelm the source. Transient errors are retried with exponential backoff, and a
+// group that still fails only records the error in its own result. The results are keyed by group id.
+func (a *App) FetchGroupMembers(groups []*model.Group, fetch GroupMemberFetchFunc) map[string]*GroupMemberFetchResult {
+	results := make(map[string]*GroupMemberFetchResult, len(groups))
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, *a.Config().GroupSettings.SyncConcurrency)
+
+	for _, group := range groups {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(group *model.Group) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			remoteIds, err := fetchGroupMembersWithRetry(group, fetch)
+			if err != nil {
+				mlog.Error("Failed to fetch group members", mlog.String("group_id", group.Id), mlog.Err(err))
+			}
+
+			mutex.Lock()
+			results[group.Id] = &GroupMemberFetchResult{MemberRemoteIds: remoteIds, Err: err}
+			mutex.Unlock()
+		}(group)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+func fetchGroupMembersWithRetry(group *model.Group, fetch GroupMemberFetchFunc) ([]string, error) {
+	backoff := groupMemberFetchBackoff
+	for attempt := 1; ; attempt++ {
+		remoteIds, err := fetch(group)
+		if err == nil || attempt == GROUP_MEMBER_FETCH_MAX_ATTEMPTS || !isTransientGroupFetchError(err) {
+			return remoteIds, err
+		}
+
+		mlog.Warn("Group member fetch failed, retrying", mlog.String("group_id", group.Id), mlog.Int("attempt", attempt), mlog.Err(err))
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// isTransientGroupFetchError reports whether a failed member fetch is worth retrying: a network error, or an LDAP
+// server that is busy, unavailable or timed out.
+func isTransientGroupFetchError(err error) bool {
+	if netErr, ok := err.(net.Error); ok && (netErr.Timeout() || netErr.Temporary()) {
+		return true
+	}
+
+	for _, code := range []uint16{
+		ldap.LDAPResultBusy,
+		ldap.LDAPResultUnavailable,
+		ldap.LDAPResultTimeLimitExceeded,
+		ldap.LDAPResultAdminLimitExceeded,
+		ldap.LDAPResultServerDown,
+		ldap.LDAPResultTimeout,
+		ldap.ErrorNetwork,
+	} {
+		if ldap.IsErrorWithCode(err, code) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SyncGroupMembers makes the members of the group exactly the given users, adding the missing ones and removing the
+// rest, and adds the changes to the summary of the running sync. Nothing is changed for a group whose sync is paused,
+// so that its members are kept as they are until the pause is lifted. A sync of the group that is already running is
+// waited for briefly, and api.group.sync.in_progress is returned if it does not finish in time.
+func (a *App) SyncGroupMembers(group *model.Group, userIDs []string, summary *model.GroupSyncSummary) *model.AppError {
+	if group.SyncPaused {
+		mlog.Info("Skipping members of paused group", mlog.String("group_id", group.Id))
+		return nil
+	}
+
+	if err := a.lockGroupSync(group.Id); err != nil {
+		return err
+	}
+	defer a.unlockGroupSync(group.Id)
+
+	currentMembers, err := a.GetGroupMemberUsers(group.Id)
+	if err != nil {
+		return err
+	}
+
+	keep := make(map[string]bool, len(userIDs))
+	for _, userID := range userIDs {
+		keep[userID] = true
+	}
+
+	var removeIDs []string
+	for _, member := range currentMembers {
+		if !keep[member.Id] {
+			removeIDs = append(removeIDs, member.Id)
+		}
+	}
+
+	upsertResult, err := a.UpsertGroupMembers(group.Id, userIDs)
+	if err != nil {
+		return err
+	}
+
+	var removed []*model.GroupMember
+	if len(removeIDs) > 0 {
+		if removed, err = a.DeleteGroupMembers(group.Id, removeIDs); err != nil {
+			return err
+		}
+	}
+
+	now := model.GetMillis()
+	if result := <-a.Srv.Store.Group().SetLastSyncAt(group.Id, now); result.Err != nil {
+		return result.Err
+	}
+	group.LastSyncAt = now
+
+	summary.GroupsSynced++
+	summary.MembersAdded += len(upsertResult.Added)
+	summary.MembersRemoved += len(removed)
+	summary.AffectedUserIds = append(summary.AffectedUserIds, upsertResult.Added...)
+	for _, member := range removed {
+		summary.AffectedUserIds = append(summary.AffectedUserIds, member.UserId)
+	}
+
+	return nil
+}
+
+// SyncLdapGroups brings the display names and members of every LDAP group in line with the LDAP server. It runs after the LDAP sync job,
+// so that the users it brought in can be matched to the members of their groups by their AuthData. Members without a
+// user yet are ignored until a later sync. The members are fetched with FetchGroupMembers, so that the LDAP server is
+// not overwhelmed and transient errors are retried. A group whose members cannot be fetched or synced is recorded in
+// the summary and skipped, and the summary is sent with NotifyGroupSyncComplete once every group has been visited. The
+// duration of the whole sync is observed in the metrics.
+func (a *App) SyncLdapGroups() (*model.GroupSyncSummary, *model.AppError) {
+	if a.Ldap == nil {
+		return nil, model.NewAppError("SyncLdapGroups", "ent.ldap.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	start := time.Now()
+
+	groups, err := a.GetGroupsBySource(model.GroupSourceLdap)
+	if err != nil {
+		return nil, err
+	}
+
+	result := <-a.Srv.Store.User().GetAllUsingAuthService(model.USER_AUTH_SERVICE_LDAP)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	userIDsByAuthData := map[string]string{}
+	for _, user := range result.Data.([]*model.User) {
+		if user.AuthData != nil {
+			userIDsByAuthData[*user.AuthData] = user.Id
+		}
+	}
+
+	summary := &model.GroupSyncSummary{Errors: []string{}}
+
+	if err := a.syncLdapGroupDisplayNames(groups); err != nil {
+		mlog.Error("Failed to sync LDAP group display names", mlog.Err(err))
+		summary.Errors = append(summary.Errors, err.Error())
+	}
+
+	fetched := a.FetchGroupMembers(groups, func(group *model.Group) ([]string, error) {
+		return a.Ldap.GetGroupMemberAuthData(group.RemoteId)
+	})
+
+	for _, group := range groups {
+		members := fetched[group.Id]
+		if members.Err != nil {
+			summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %s", group.RemoteId, members.Err.Error()))
+			continue
+		}
+
+		userIDs := make([]string, 0, len(members.MemberRemoteIds))
+		for _, memberAuthData := range members.MemberRemoteIds {
+			if userID, ok := userIDsByAuthData[memberAuthData]; ok {
+				userIDs = append(userIDs, userID)
+			}
+		}
+
+		if err := a.SyncGroupMembers(group, userIDs, summary); err != nil {
+			mlog.Error("Failed to sync group members", mlog.String("group_id", group.Id), mlog.Err(err))
+			summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %s", group.RemoteId, err.Error()))
+		}
+	}
+
+	if a.Metrics != nil {
+		a.Metrics.ObserveGroupSyncDuration(float64(time.Since(start)) / float64(time.Second))
+	}
+
+	a.NotifyGroupSyncComplete(summary)
+
+	return summary, nil
+}
+
+// syncLdapGroupDisplayNames gives the groups the display names the LDAP server has for them, which it reads from the
+// attribute named by LdapSettings.GroupDisplayNameAttribute, so that renaming a group or changing the attribute reaches
+// existing groups on the next sync. The renamed groups are written in batches, so that a sync renaming thousands of
+// groups does not update them one by one. Groups whose sync is paused are updated too, since only their members are
+// frozen.
+func (a *App) syncLdapGroupDisplayNames(groups []*model.Group) *model.AppError {
+	displayNames := map[string]string{}
+	for page := 0; ; page++ {
+		ldapGroups, total, err := a.Ldap.GetAllGroupsPage(page, LDAP_GROUP_SYNC_PAGE_SIZE, model.GroupSearchOpts{})
+		if err != nil {
+			return err
+		}
+
+		for _, ldapGroup := range ldapGroups {
+			displayNames[ldapGroup.RemoteId] = ldapGroup.DisplayName
+		}
+
+		if len(ldapGroups) == 0 || (page+1)*LDAP_GROUP_SYNC_PAGE_SIZE >= total {
+			break
+		}
+	}
+
+	changed := []*model.Group{}
+	for _, group := range groups {
+		displayName := displayNames[group.RemoteId]
+		if len(displayName) == 0 || displayName == group.DisplayName {
+			continue
+		}
+
+		group.DisplayName = displayName
+		changed = append(changed, group)
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	_, err := a.UpdateGroupsMetadata(changed)
+	return err
+}
+
+// SyncSamlGroupsForUser brings the user's memberships of SAML groups in line with the assertion they logged in with.
+// Each value of the SamlSettings.GroupAttribute attribute is matched against the remote ids of the SAML groups: the
+// user is added to the matching groups and removed from the other SAML groups. Values without a group are ignored,
+// and groups whose sync is paused are left as they are. Nothing is done when no group attribute is configured.
+func (a *App) SyncSamlGroupsForUser(userID string, attributes map[string][]string) *model.AppError {
+	attribute := *a.Config().SamlSettings.GroupAttribute
+	if license := a.License(); license == nil || !*license.Features.LDAPGroups || len(attribute) == 0 {
+		return nil
+	}
+
+	samlGroups, err := a.GetGroupsBySource(model.GroupSourceSaml)
+	if err != nil {
+		return err
+	}
+
+	groupsByRemoteID := make(map[string]*model.Group, len(samlGroups))
+	for _, group := range samlGroups {
+		groupsByRemoteID[group.RemoteId] = group
+	}
+
+	asserted := map[string]bool{}
+	for _, value := range attributes[attribute] {
+		if group, ok := groupsByRemoteID[strings.TrimSpace(value)]; ok {
+			asserted[group.Id] = true
+		}
+	}
+
+	userGroups, err := a.GetGroupsByUserId(userID)
+	if err != nil {
+		return err
+	}
+
+	isMember := map[string]bool{}
+	for _, group := range userGroups {
+		if group.Source == model.GroupSourceSaml {
+			isMember[group.Id] = true
+		}
+	}
+
+	for _, group := range samlGroups {
+		if group.SyncPaused || asserted[group.Id] == isMember[group.Id] {
+			continue
+		}
+
+		if asserted[group.Id] {
+			_, err = a.UpsertGroupMembers(group.Id, []string{userID})
+		} else {
+			_, err = a.DeleteGroupMembers(group.Id, []string{userID})
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GroupSyncDryRun previews a full group sync without writing anything: the number of linked LDAP groups, and the
+// team and channel memberships the sync would add and remove. Each total comes from its own read query rather than
+// one long transaction, and each result is discarded once it has been counted.
+func (a *App) GroupSyncDryRun() (*model.GroupSyncDryRunResult, *model.AppError) {
+	result := <-a.Srv.Store.Group().GetAllBySource(model.GroupSourceLdap)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	dryRun := &model.GroupSyncDryRunResult{Groups: len(result.Data.([]*model.Group))}
+
+	channelIds := map[string]bool{}
+
+	teamMembersToAdd, err := a.TeamMembersToAdd(0)
+	if err != nil {
+		return nil, err
+	}
+	dryRun.MembersToAdd += len(teamMembersToAdd)
+
+	channelMembersToAdd, err := a.ChannelMembersToAdd(0)
+	if err != nil {
+		return nil, err
+	}
+	dryRun.MembersToAdd += len(channelMembersToAdd)
+	for _, pair := range channelMembersToAdd {
+		channelIds[pair.ChannelID] = true
+	}
+
+	teamMembersToRemove, err := a.TeamMembersToRemove()
+	if err != nil {
+		return nil, err
+	}
+	dryRun.MembersToRemove += len(teamMembersToRemove)
+
+	channelMembersToRemove, err := a.ChannelMembersToRemove()
+	if err != nil {
+		return nil, err
+	}
+	dryRun.MembersToRemove += len(channelMembersToRemove)
+	for _, member := range channelMembersToRemove {
+		channelIds[member.ChannelId] = true
+	}
+
+	dryRun.ChannelsAffected = len(channelIds)
+
+	return dryRun, nil
+}
+
+// NotifyGroupSyncComplete drops the cached group memberships of the users affected by a finished group sync and posts
+// its summary to GroupSettings.SyncCompleteWebhookURL, if one is configured. Delivery happens in the background and
+// failures are logged rather than returned, so that they never fail the sync itself.
+func (a *App) NotifyGroupSyncComplete(summary *model.GroupSyncSummary) {
+	a.InvalidateGroupMembershipCacheForUsers(summary.AffectedUserIds)
+
+	url := *a.Config().GroupSettings.SyncCompleteWebhookURL
+	if len(url) == 0 {
+		return
+	}
+
+	a.Srv.Go(func() {
+		if err := a.sendGroupSyncCompleteWebhook(url, summary); err != nil {
+			mlog.Error("Failed to deliver group sync complete webhook", mlog.String("url", url), mlog.Err(err))
+		}
+	})
+}
+
+func (a *App) sendGroupSyncCompleteWebhook(url string, summary *model.GroupSyncSummary) error {
+	return a.sendGroupWebhook(url, *a.Config().GroupSettings.SyncCompleteWebhookSecret, summary.ToJson())
+}
+
+// NotifyGroupMembershipChange posts a GroupMembershipWebhookPayload for each of the users to the group's
+// MembershipWebhookURL, if it has one. As with the sync complete webhook, delivery happens in the background with
+// retries and failures are only logged.
+func (a *App) NotifyGroupMembershipChange(groupID string, userIDs []string, action string) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	group, err := a.GetGroup(groupID)
+	if err != nil {
+		mlog.Error("Failed to get group for membership webhook", mlog.String("group_id", groupID), mlog.Err(err))
+		return
+	}
+
+	url := group.MembershipWebhookURL
+	if len(url) == 0 {
+		return
+	}
+
+	secret := *a.Config().GroupSettings.MembershipWebhookSecret
+	timestamp := model.GetMillis()
+
+	a.Srv.Go(func() {
+		for _, userID := range userIDs {
+			payload := &model.GroupMembershipWebhookPayload{
+				GroupId:   groupID,
+				UserId:    userID,
+				Action:    action,
+				Timestamp: timestamp,
+			}
+
+			if err := a.sendGroupWebhook(url, secret, payload.ToJson()); err != nil {
+				mlog.Error("Failed to deliver group membership webhook", mlog.String("group_id", groupID), mlog.String("user_id", userID), mlog.Err(err))
+			}
+		}
+	})
+}
+
+// ValidateGroupMembershipWebhookURL requires a group's membership webhook to use https, since its payloads identify
+// users. Plain http is allowed in developer mode for local testing.
+func (a *App) ValidateGroupMembershipWebhookURL(url string) *model.AppError {
+	if len(url) == 0 || *a.Config().ServiceSettings.EnableDeveloper || strings.HasPrefix(strings.ToLower(url), "https://") {
+		return nil
+	}
+
+	return model.NewAppError("ValidateGroupMembershipWebhookURL", "api.group.membership_webhook_url.https.app_error", nil, "", http.StatusBadRequest)
+}
+
+func (a *App) sendGroupWebhook(url, secret string, payload []byte) error {
+	var err error
+	backoff := groupSyncWebhookBackoff
+	for attempt := 1; attempt <= GROUP_SYNC_WEBHOOK_MAX_ATTEMPTS; attempt++ {
+		if err = a.postGroupWebhook(url, secret, payload); err == nil {
+			return nil
+		}
+
+		if attempt < GROUP_SYNC_WEBHOOK_MAX_ATTEMPTS {
+			mlog.Warn("Group webhook delivery failed, retrying", mlog.String("url", url), mlog.Int("attempt", attempt), mlog.Err(err))
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	return err
+}
+
+func (a *App) postGroupWebhook(url, secret string, payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if len(secret) > 0 {
+		req.Header.Set(model.HEADER_WEBHOOK_SIGNATURE, SignWebhookPayload(secret, payload))
+	}
+
+	resp, err := a.HTTPService.MakeClient(false).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// SignWebhookPayload returns the hex encoded HMAC-SHA256 of the payload, keyed with the webhook secret, so that the
+// receiver can verify the request came from this server.
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CreateLdapGroup creates a group found by the LDAP sync. A group without a name is named after its display name. If
+// its name is already taken, e.g. because two directory groups normalize to the same name, the group is given the
+// first free name of name-2, name-3 and so on and flagged with NameCollision for an admin to review, rather than
+// failing the sync. The suffixes are tried in order so that the same groups get the same names whichever sync creates
+// them.
+func (a *App) CreateLdapGroup(group *model.Group) (*model.Group, *model.AppError) {
+	if len(group.Name) == 0 {
+		group.Name = ldapGroupName(group.DisplayName)
+	}
+
+	name := group.Name
+	created, err := a.CreateGroup(group)
+	for suffix := 2; err != nil && err.Id == "store.sql_group.unique_constraint" && suffix <= GROUP_NAME_COLLISION_MAX_SUFFIX; suffix++ {
+		suffixed := fmt.Sprintf("-%d", suffix)
+		base := name
+		if len(base)+len(suffixed) > model.GroupNameMaxLength {
+			base = base[:model.GroupNameMaxLength-len(suffixed)]
+		}
+
+		group.Id = ""
+		group.Name = base + suffixed
+		group.NameCollision = true
+		created, err = a.CreateGroup(group)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if created.NameCollision {
+		mlog.Warn("LDAP group name already taken, created the group with a suffixed name", mlog.String("remote_id", created.RemoteId), mlog.String("name", name), mlog.String("suffixed_name", created.Name))
+	}
+
+	return created, nil
+}
+
+// ldapGroupName normalizes the display name of an LDAP group into a mentionable group name, e.g. "Platform Team" into
+// "platform-team". A display name with nothing left to mention gets a random name.
+func ldapGroupName(displayName string) string {
+	name := invalidGroupNameChars.ReplaceAllString(strings.ToLower(displayName), "-")
+	if len(name) > model.GroupNameMaxLength {
+		name = name[:model.GroupNameMaxLength]
+	}
+	name = strings.Trim(name, "-_.")
+
+	if !model.IsValidGroupMentionName(name) {
+		return model.NewId()
+	}
+	return name
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	GROUP_SYNC_LOCK_PREFIX     = "GroupSyncLock_"
+	GROUP_SYNC_LOCK_POLL_MS    = 100
+	GROUP_SYNC_LOCK_DEFAULT_MS = 5 * 1000
+)
+
+// groupSyncLockWait is how long a sync waits for another sync of the same group to finish before giving up.
+var groupSyncLockWait = GROUP_SYNC_LOCK_DEFAULT_MS * time.Millisecond
+
+// groupSyncLocks makes sure that a group is only synced by one goroutine of this server at a time. Each held lock has
+// a channel that is closed when it is released, for the waiting goroutines to try again.
+type groupSyncLocks struct {
+	mutex sync.Mutex
+	held  map[string]chan struct{}
+}
+
+func newGroupSyncLocks() *groupSyncLocks {
+	return &groupSyncLocks{
+		held: map[string]chan struct{}{},
+	}
+}
+
+// acquire takes the lock of the group, waiting up to wait for it to be released. It reports whether the lock was
+// taken.
+func (locks *groupSyncLocks) acquire(groupID string, wait time.Duration) bool {
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+
+	for {
+		locks.mutex.Lock()
+		released, ok := locks.held[groupID]
+		if !ok {
+			locks.held[groupID] = make(chan struct{})
+			locks.mutex.Unlock()
+			return true
+		}
+		locks.mutex.Unlock()
+
+		select {
+		case <-released:
+		case <-timeout.C:
+			return false
+		}
+	}
+}
+
+func (locks *groupSyncLocks) release(groupID string) {
+	locks.mutex.Lock()
+	defer locks.mutex.Unlock()
+
+	if released, ok := locks.held[groupID]; ok {
+		close(released)
+		delete(locks.held, groupID)
+	}
+}
+
+// lockGroupSync takes the lock that keeps two syncs from reconciling the group at the same time, waiting briefly for a
+// running sync to finish. When clustering is enabled, the lock is also held in the database so that the other nodes
+// respect it. It must be released with unlockGroupSync.
+func (a *App) lockGroupSync(groupID string) *model.AppError {
+	deadline := time.Now().Add(groupSyncLockWait)
+
+	if !a.Srv.groupSyncLocks.acquire(groupID, groupSyncLockWait) {
+		return model.NewAppError("lockGroupSync", "api.group.sync.in_progress", nil, "group_id="+groupID, http.StatusConflict)
+	}
+
+	if !a.isClusterEnabled() {
+		return nil
+	}
+
+	for {
+		if a.tryLockGroupSyncInDatabase(groupID) {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			a.Srv.groupSyncLocks.release(groupID)
+			return model.NewAppError("lockGroupSync", "api.group.sync.in_progress", nil, "group_id="+groupID, http.StatusConflict)
+		}
+		time.Sleep(GROUP_SYNC_LOCK_POLL_MS * time.Millisecond)
+	}
+}
+
+func (a *App) unlockGroupSync(groupID string) {
+	if a.isClusterEnabled() {
+		if result := <-a.Srv.Store.System().PermanentDeleteByName(GROUP_SYNC_LOCK_PREFIX + groupID); result.Err != nil {
+			mlog.Error("Failed to release the group sync lock", mlog.String("group_id", groupID), mlog.Err(result.Err))
+		}
+	}
+
+	a.Srv.groupSyncLocks.release(groupID)
+}
+
+// tryLockGroupSyncInDatabase inserts the row holding the group's lock, relying on the uniqueness of system names to
+// fail if another node holds it. A lock older than GroupSettings.SyncLockStaleSeconds is assumed to be left over by a
+// node that stopped during a sync, and is taken over.
+func (a *App) tryLockGroupSyncInDatabase(groupID string) bool {
+	name := GROUP_SYNC_LOCK_PREFIX + groupID
+	now := model.GetMillis()
+	staleAfter := int64(*a.Config().GroupSettings.SyncLockStaleSeconds) * 1000
+
+	if result := <-a.Srv.Store.System().Save(&model.System{Name: name, Value: strconv.FormatInt(now, 10)}); result.Err == nil {
+		return true
+	}
+
+	result := <-a.Srv.Store.System().GetByName(name)
+	if result.Err != nil {
+		return false
+	}
+
+	lockedAt, err := strconv.ParseInt(result.Data.(*model.System).Value, 10, 64)
+	if err == nil && now-lockedAt < staleAfter {
+		return false
+	}
+
+	mlog.Warn("Taking over a stale group sync lock", mlog.String("group_id", groupID))
+	if result := <-a.Srv.Store.System().PermanentDeleteByName(name); result.Err != nil {
+		return false
+	}
+	result = <-a.Srv.Store.System().Save(&model.System{Name: name, Value: strconv.FormatInt(now, 10)})
+	return result.Err == nil
+}
+
+func (a *App) isClusterEnabled() bool {
+	return a.Cluster != nil && *a.Config().ClusterSettings.Enable
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestGroupSyncLocks(t *testing.T) {
+	t.Run("serializes the syncs of a group", func(t *testing.T) {
+		locks := newGroupSyncLocks()
+		groupID := model.NewId()
+
+		var running, maxRunning int32
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if !assert.True(t, locks.acquire(groupID, 5*time.Second)) {
+					return
+				}
+				defer locks.release(groupID)
+
+				current := atomic.AddInt32(&running, 1)
+				for {
+					max := atomic.LoadInt32(&maxRunning)
+					if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), maxRunning)
+	})
+
+	t.Run("does not block other groups", func(t *testing.T) {
+		locks := newGroupSyncLocks()
+		groupID := model.NewId()
+
+		require.True(t, locks.acquire(groupID, time.Second))
+		defer locks.release(groupID)
+
+		assert.True(t, locks.acquire(model.NewId(), 0))
+	})
+
+	t.Run("gives up once the wait is over", func(t *testing.T) {
+		locks := newGroupSyncLocks()
+		groupID := model.NewId()
+
+		require.True(t, locks.acquire(groupID, time.Second))
+		assert.False(t, locks.acquire(groupID, 50*time.Millisecond))
+
+		locks.release(groupID)
+		assert.True(t, locks.acquire(groupID, 50*time.Millisecond))
+	})
+}
+
+func TestSyncGroupMembersInProgress(t *testing.T) {
+	th := Setup(t)
+	defer th.TearDown()
+
+	defer func(wait time.Duration) { groupSyncLockWait = wait }(groupSyncLockWait)
+	groupSyncLockWait = 100 * time.Millisecond
+
+	group := th.CreateGroup()
+	user := th.CreateUser()
+
+	require.Nil(t, th.App.lockGroupSync(group.Id))
+
+	err := th.App.SyncGroupMembers(group, []string{user.Id}, &model.GroupSyncSummary{})
+	require.NotNil(t, err)
+	assert.Equal(t, "api.group.sync.in_progress", err.Id)
+	assert.Equal(t, http.StatusConflict, err.StatusCode)
+
+	// The sync waits for the running one to finish rather than failing right away.
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		th.App.unlockGroupSync(group.Id)
+	}()
+	require.Nil(t, th.App.SyncGroupMembers(group, []string{user.Id}, &model.GroupSyncSummary{}))
+
+	members, err := th.App.GetGroupMemberUsers(group.Id)
+	require.Nil(t, err)
+	require.Len(t, members, 1)
+	assert.Equal(t, user.Id, members[0].Id)
+}
+
+func TestTryLockGroupSyncInDatabase(t *testing.T) {
+	th := Setup(t)
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.GroupSettings.SyncLockStaleSeconds = 60 })
+
+	lockAt := func(groupID string, lockedAt int64) {
+		result := <-th.App.Srv.Store.System().Save(&model.System{Name: GROUP_SYNC_LOCK_PREFIX + groupID, Value: strconv.FormatInt(lockedAt, 10)})
+		require.Nil(t, result.Err)
+	}
+
+	t.Run("a lock held by another node is respected", func(t *testing.T) {
+		groupID := model.NewId()
+		lockAt(groupID, model.GetMillis()-30*1000)
+
+		assert.False(t, th.App.tryLockGroupSyncInDatabase(groupID))
+	})
+
+	t.Run("a lock older than the stale timeout is taken over", func(t *testing.T) {
+		groupID := model.NewId()
+		lockAt(groupID, model.GetMillis()-90*1000)
+
+		assert.True(t, th.App.tryLockGroupSyncInDatabase(groupID))
+	})
+}
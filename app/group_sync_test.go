@@ -0,0 +1,668 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/einterfaces/mocks"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestSendGroupSyncCompleteWebhook(t *testing.T) {
+	th := Setup(t)
+	defer th.TearDown()
+
+	originalBackoff := groupSyncWebhookBackoff
+	groupSyncWebhookBackoff = time.Millisecond
+	defer func() { groupSyncWebhookBackoff = originalBackoff }()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.ServiceSettings.AllowedUntrustedInternalConnections = "localhost 127.0.0.1"
+		*cfg.GroupSettings.SyncCompleteWebhookSecret = "secret"
+	})
+
+	summary := &model.GroupSyncSummary{
+		GroupsSynced:   3,
+		MembersAdded:   5,
+		MembersRemoved: 1,
+		Errors:         []string{"group not found"},
+	}
+
+	t.Run("payload shape and signature", func(t *testing.T) {
+		var payload map[string]interface{}
+		var signature string
+		var expectedSignature string
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := ioutil.ReadAll(r.Body)
+			require.Nil(t, err)
+			require.Nil(t, json.Unmarshal(body, &payload))
+			signature = r.Header.Get(model.HEADER_WEBHOOK_SIGNATURE)
+			expectedSignature = SignWebhookPayload("secret", body)
+		}))
+		defer ts.Close()
+
+		require.Nil(t, th.App.sendGroupSyncCompleteWebhook(ts.URL, summary))
+
+		assert.Equal(t, map[string]interface{}{
+			"groups_synced":   float64(3),
+			"members_added":   float64(5),
+			"members_removed": float64(1),
+			"errors":          []interface{}{"group not found"},
+		}, payload)
+		assert.NotEmpty(t, signature)
+		assert.Equal(t, expectedSignature, signature)
+	})
+
+	t.Run("failed deliveries are retried", func(t *testing.T) {
+		attempts := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer ts.Close()
+
+		require.Nil(t, th.App.sendGroupSyncCompleteWebhook(ts.URL, summary))
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("gives up after the maximum number of attempts", func(t *testing.T) {
+		attempts := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer ts.Close()
+
+		assert.NotNil(t, th.App.sendGroupSyncCompleteWebhook(ts.URL, summary))
+		assert.Equal(t, GROUP_SYNC_WEBHOOK_MAX_ATTEMPTS, attempts)
+	})
+}
+
+func TestNotifyGroupMembershipChange(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.ServiceSettings.AllowedUntrustedInternalConnections = "localhost 127.0.0.1"
+		*cfg.GroupSettings.MembershipWebhookSecret = "secret"
+	})
+
+	type delivery struct {
+		payload   *model.GroupMembershipWebhookPayload
+		signature string
+		body      []byte
+	}
+	deliveries := make(chan delivery, 10)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.Nil(t, err)
+		deliveries <- delivery{
+			payload:   model.GroupMembershipWebhookPayloadFromJson(bytes.NewReader(body)),
+			signature: r.Header.Get(model.HEADER_WEBHOOK_SIGNATURE),
+			body:      body,
+		}
+	}))
+	defer ts.Close()
+
+	id := model.NewId()
+	group, err := th.App.CreateGroup(&model.Group{
+		DisplayName:          "dn_" + id,
+		Name:                 "name" + id,
+		Source:               model.GroupSourceCustom,
+		RemoteId:             model.NewId(),
+		MembershipWebhookURL: ts.URL,
+	})
+	require.Nil(t, err)
+
+	receive := func() delivery {
+		select {
+		case d := <-deliveries:
+			return d
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for the membership webhook")
+			return delivery{}
+		}
+	}
+
+	_, err = th.App.CreateOrRestoreGroupMember(group.Id, th.BasicUser.Id)
+	require.Nil(t, err)
+
+	d := receive()
+	assert.Equal(t, group.Id, d.payload.GroupId)
+	assert.Equal(t, th.BasicUser.Id, d.payload.UserId)
+	assert.Equal(t, model.GroupMembershipWebhookActionAdd, d.payload.Action)
+	assert.NotZero(t, d.payload.Timestamp)
+	assert.Equal(t, SignWebhookPayload("secret", d.body), d.signature)
+
+	_, err = th.App.DeleteGroupMember(group.Id, th.BasicUser.Id)
+	require.Nil(t, err)
+
+	d = receive()
+	assert.Equal(t, th.BasicUser.Id, d.payload.UserId)
+	assert.Equal(t, model.GroupMembershipWebhookActionRemove, d.payload.Action)
+}
+
+func TestValidateGroupMembershipWebhookURL(t *testing.T) {
+	th := Setup(t)
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.ServiceSettings.EnableDeveloper = false })
+
+	assert.Nil(t, th.App.ValidateGroupMembershipWebhookURL(""))
+	assert.Nil(t, th.App.ValidateGroupMembershipWebhookURL("https://example.com/hook"))
+	assert.NotNil(t, th.App.ValidateGroupMembershipWebhookURL("http://example.com/hook"))
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.ServiceSettings.EnableDeveloper = true })
+
+	assert.Nil(t, th.App.ValidateGroupMembershipWebhookURL("http://example.com/hook"))
+}
+
+func TestFetchGroupMembers(t *testing.T) {
+	th := Setup(t)
+	defer th.TearDown()
+
+	originalBackoff := groupMemberFetchBackoff
+	groupMemberFetchBackoff = time.Millisecond
+	defer func() { groupMemberFetchBackoff = originalBackoff }()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.GroupSettings.SyncConcurrency = 2
+	})
+
+	flaky := &model.Group{Id: model.NewId(), RemoteId: "flaky"}
+	down := &model.Group{Id: model.NewId(), RemoteId: "down"}
+	missing := &model.Group{Id: model.NewId(), RemoteId: "missing"}
+	groups := []*model.Group{flaky, down, missing}
+	for i := 0; i < 5; i++ {
+		groups = append(groups, &model.Group{Id: model.NewId(), RemoteId: "healthy"})
+	}
+
+	var mutex sync.Mutex
+	attempts := map[string]int{}
+	running, maxRunning := 0, 0
+
+	fetch := func(group *model.Group) ([]string, error) {
+		mutex.Lock()
+		attempts[group.Id]++
+		attempt := attempts[group.Id]
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mutex.Unlock()
+
+		defer func() {
+			mutex.Lock()
+			running--
+			mutex.Unlock()
+		}()
+		time.Sleep(5 * time.Millisecond)
+
+		switch group.RemoteId {
+		case "flaky":
+			if attempt < 3 {
+				return nil, ldap.NewError(ldap.LDAPResultBusy, errors.New("server busy"))
+			}
+		case "down":
+			return nil, ldap.NewError(ldap.LDAPResultUnavailable, errors.New("server unavailable"))
+		case "missing":
+			return nil, ldap.NewError(ldap.LDAPResultNoSuchObject, errors.New("no such object"))
+		}
+		return []string{"member-" + group.Id}, nil
+	}
+
+	results := th.App.FetchGroupMembers(groups, fetch)
+	require.Len(t, results, len(groups))
+
+	assert.NoError(t, results[flaky.Id].Err)
+	assert.Equal(t, []string{"member-" + flaky.Id}, results[flaky.Id].MemberRemoteIds)
+	assert.Equal(t, 3, attempts[flaky.Id])
+
+	assert.True(t, ldap.IsErrorWithCode(results[down.Id].Err, ldap.LDAPResultUnavailable))
+	assert.Equal(t, GROUP_MEMBER_FETCH_MAX_ATTEMPTS, attempts[down.Id])
+
+	assert.True(t, ldap.IsErrorWithCode(results[missing.Id].Err, ldap.LDAPResultNoSuchObject))
+	assert.Equal(t, 1, attempts[missing.Id], "permanent errors should not be retried")
+
+	for _, group := range groups[3:] {
+		assert.NoError(t, results[group.Id].Err)
+		assert.Equal(t, []string{"member-" + group.Id}, results[group.Id].MemberRemoteIds)
+		assert.Equal(t, 1, attempts[group.Id])
+	}
+
+	assert.True(t, maxRunning <= 2, "at most SyncConcurrency fetches should run at once, saw %d", maxRunning)
+}
+
+func TestCreateLdapGroupNameCollision(t *testing.T) {
+	th := Setup(t)
+	defer th.TearDown()
+
+	name := "engineering_" + model.NewId()[:8]
+	newLdapGroup := func() *model.Group {
+		return &model.Group{
+			Name:        name,
+			DisplayName: "Engineering",
+			Source:      model.GroupSourceLdap,
+			RemoteId:    model.NewId(),
+		}
+	}
+
+	first, err := th.App.CreateLdapGroup(newLdapGroup())
+	require.Nil(t, err)
+	assert.Equal(t, name, first.Name)
+	assert.False(t, first.NameCollision)
+
+	second, err := th.App.CreateLdapGroup(newLdapGroup())
+	require.Nil(t, err)
+	assert.Equal(t, name+"-2", second.Name)
+	assert.True(t, second.NameCollision)
+
+	third, err := th.App.CreateLdapGroup(newLdapGroup())
+	require.Nil(t, err)
+	assert.Equal(t, name+"-3", third.Name)
+
+	// The flag is stored for admins to find the groups to rename.
+	stored, err := th.App.GetGroup(second.Id)
+	require.Nil(t, err)
+	assert.True(t, stored.NameCollision)
+
+	// Groups found by the sync are named after their display names, so that differently cased directory groups
+	// collide.
+	suffix := model.NewId()[:8]
+	first, err = th.App.CreateLdapGroup(&model.Group{DisplayName: "Platform Team " + suffix, Source: model.GroupSourceLdap, RemoteId: model.NewId()})
+	require.Nil(t, err)
+	assert.Equal(t, "platform-team-"+suffix, first.Name)
+
+	second, err = th.App.CreateLdapGroup(&model.Group{DisplayName: "PLATFORM TEAM " + suffix, Source: model.GroupSourceLdap, RemoteId: model.NewId()})
+	require.Nil(t, err)
+	assert.Equal(t, "platform-team-"+suffix+"-2", second.Name)
+	assert.True(t, second.NameCollision)
+}
+
+func TestLdapGroupName(t *testing.T) {
+	assert.Equal(t, "engineering", ldapGroupName("Engineering"))
+	assert.Equal(t, "platform-team", ldapGroupName("Platform Team"))
+	assert.Equal(t, "r-d", ldapGroupName("R&D"))
+	assert.Equal(t, "sales.emea", ldapGroupName(" Sales.EMEA "))
+	assert.Len(t, ldapGroupName(strings.Repeat("a", 100)), model.GroupNameMaxLength)
+
+	name := ldapGroupName("!!!")
+	assert.True(t, model.IsValidId(name))
+}
+
+func TestSyncGroupMembers(t *testing.T) {
+	th := Setup(t)
+	defer th.TearDown()
+
+	user1 := th.CreateUser()
+	user2 := th.CreateUser()
+	user3 := th.CreateUser()
+
+	memberIds := func(group *model.Group) []string {
+		users, err := th.App.GetGroupMemberUsers(group.Id)
+		require.Nil(t, err)
+		var ids []string
+		for _, user := range users {
+			ids = append(ids, user.Id)
+		}
+		return ids
+	}
+
+	createGroup := func() *model.Group {
+		group, err := th.App.CreateGroup(&model.Group{
+			Name:        "name" + model.NewId(),
+			DisplayName: "developers",
+			Source:      model.GroupSourceLdap,
+			RemoteId:    model.NewId(),
+		})
+		require.Nil(t, err)
+
+		_, err = th.App.UpsertGroupMembers(group.Id, []string{user1.Id, user2.Id})
+		require.Nil(t, err)
+		return group
+	}
+
+	t.Run("reconciles members", func(t *testing.T) {
+		group := createGroup()
+
+		summary := &model.GroupSyncSummary{}
+		require.Nil(t, th.App.SyncGroupMembers(group, []string{user2.Id, user3.Id}, summary))
+
+		assert.ElementsMatch(t, []string{user2.Id, user3.Id}, memberIds(group))
+		assert.Equal(t, 1, summary.GroupsSynced)
+		assert.Equal(t, 1, summary.MembersAdded)
+		assert.Equal(t, 1, summary.MembersRemoved)
+		assert.ElementsMatch(t, []string{user1.Id, user3.Id}, summary.AffectedUserIds)
+
+		group, err := th.App.GetGroup(group.Id)
+		require.Nil(t, err)
+		assert.NotZero(t, group.LastSyncAt)
+	})
+
+	t.Run("paused group keeps its members", func(t *testing.T) {
+		group := createGroup()
+		group.SyncPaused = true
+		group, err := th.App.UpdateGroup(group)
+		require.Nil(t, err)
+
+		summary := &model.GroupSyncSummary{}
+		require.Nil(t, th.App.SyncGroupMembers(group, []string{user3.Id}, summary))
+
+		assert.ElementsMatch(t, []string{user1.Id, user2.Id}, memberIds(group))
+		assert.Equal(t, model.GroupSyncSummary{}, *summary)
+		assert.Zero(t, group.LastSyncAt)
+
+		// Once unpaused, the next sync reconciles as usual
+		group.SyncPaused = false
+		group, err = th.App.UpdateGroup(group)
+		require.Nil(t, err)
+
+		require.Nil(t, th.App.SyncGroupMembers(group, []string{user3.Id}, summary))
+		assert.Equal(t, []string{user3.Id}, memberIds(group))
+	})
+}
+
+func TestSyncLdapGroups(t *testing.T) {
+	th := Setup(t)
+	defer th.TearDown()
+
+	createLdapUser := func() (*model.User, string) {
+		user := th.CreateUser()
+		authData := "uid=" + model.NewId()
+		result := <-th.App.Srv.Store.User().UpdateAuthData(user.Id, model.USER_AUTH_SERVICE_LDAP, &authData, "", false)
+		require.Nil(t, result.Err)
+		return user, authData
+	}
+	user1, _ := createLdapUser()
+	user2, authData2 := createLdapUser()
+
+	createGroup := func() *model.Group {
+		group, err := th.App.CreateGroup(&model.Group{
+			Name:        "name" + model.NewId(),
+			DisplayName: "developers",
+			Source:      model.GroupSourceLdap,
+			RemoteId:    "cn=" + model.NewId() + ",ou=groups,dc=example,dc=com",
+		})
+		require.Nil(t, err)
+
+		_, err = th.App.UpsertGroupMembers(group.Id, []string{user1.Id})
+		require.Nil(t, err)
+		return group
+	}
+
+	memberIds := func(group *model.Group) []string {
+		users, err := th.App.GetGroupMemberUsers(group.Id)
+		require.Nil(t, err)
+		var ids []string
+		for _, user := range users {
+			ids = append(ids, user.Id)
+		}
+		return ids
+	}
+
+	// The LDAP server knows the members of the given groups only, so that the groups left by other tests are not
+	// changed.
+	mockLdap := func(members map[string][]string, ldapGroups ...*model.Group) *mocks.LdapInterface {
+		ldapMock := &mocks.LdapInterface{}
+		ldapMock.On("GetAllGroupsPage", 0, LDAP_GROUP_SYNC_PAGE_SIZE, model.GroupSearchOpts{}).Return(ldapGroups, len(ldapGroups), nil)
+		for remoteID, authData := range members {
+			ldapMock.On("GetGroupMemberAuthData", remoteID).Return(authData, nil)
+		}
+		ldapMock.On("GetGroupMemberAuthData", mock.Anything).Return(nil, ldap.NewError(ldap.LDAPResultNoSuchObject, errors.New("no such object")))
+		th.App.Ldap = ldapMock
+		return ldapMock
+	}
+	defer func() { th.App.Ldap = nil }()
+
+	t.Run("notifies the sync complete webhook", func(t *testing.T) {
+		bodies := make(chan []byte, 1)
+		signatures := make(chan string, 1)
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := ioutil.ReadAll(r.Body)
+			require.Nil(t, err)
+			signatures <- r.Header.Get(model.HEADER_WEBHOOK_SIGNATURE)
+			bodies <- body
+		}))
+		defer ts.Close()
+
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.ServiceSettings.AllowedUntrustedInternalConnections = "localhost 127.0.0.1"
+			*cfg.GroupSettings.SyncCompleteWebhookURL = ts.URL
+			*cfg.GroupSettings.SyncCompleteWebhookSecret = "secret"
+		})
+		defer th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.GroupSettings.SyncCompleteWebhookURL = ""
+			*cfg.GroupSettings.SyncCompleteWebhookSecret = ""
+		})
+
+		group := createGroup()
+		mockLdap(map[string][]string{group.RemoteId: {authData2, "uid=unknown"}})
+
+		_, err := th.App.SyncLdapGroups()
+		require.Nil(t, err)
+		assert.ElementsMatch(t, []string{user2.Id}, memberIds(group))
+
+		var body []byte
+		select {
+		case body = <-bodies:
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for the sync complete webhook")
+		}
+
+		var payload map[string]interface{}
+		require.Nil(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, float64(1), payload["groups_synced"])
+		assert.Equal(t, float64(1), payload["members_added"])
+		assert.Equal(t, float64(1), payload["members_removed"])
+		assert.IsType(t, []interface{}{}, payload["errors"])
+		assert.Equal(t, SignWebhookPayload("secret", body), <-signatures)
+	})
+
+	t.Run("observes the sync duration", func(t *testing.T) {
+		metricsMock := &mocks.MetricsInterface{}
+		metricsMock.On("IncrementGroupMemberUpsertCounter").Return()
+		metricsMock.On("ObserveGroupSyncDuration", mock.AnythingOfType("float64")).Return()
+		th.App.Metrics = metricsMock
+		defer func() { th.App.Metrics = nil }()
+
+		group := createGroup()
+		mockLdap(map[string][]string{group.RemoteId: {authData2}})
+
+		_, err := th.App.SyncLdapGroups()
+		require.Nil(t, err)
+		metricsMock.AssertNumberOfCalls(t, "ObserveGroupSyncDuration", 1)
+	})
+
+	t.Run("a group that cannot be fetched does not stop the others", func(t *testing.T) {
+		originalBackoff := groupMemberFetchBackoff
+		groupMemberFetchBackoff = time.Millisecond
+		defer func() { groupMemberFetchBackoff = originalBackoff }()
+
+		flaky := createGroup()
+		missing := createGroup()
+		healthy := createGroup()
+
+		ldapMock := &mocks.LdapInterface{}
+		ldapMock.On("GetAllGroupsPage", 0, LDAP_GROUP_SYNC_PAGE_SIZE, model.GroupSearchOpts{}).Return([]*model.Group{}, 0, nil)
+		ldapMock.On("GetGroupMemberAuthData", flaky.RemoteId).Return(nil, ldap.NewError(ldap.LDAPResultBusy, errors.New("server busy"))).Once()
+		ldapMock.On("GetGroupMemberAuthData", flaky.RemoteId).Return([]string{authData2}, nil)
+		ldapMock.On("GetGroupMemberAuthData", healthy.RemoteId).Return([]string{authData2}, nil)
+		ldapMock.On("GetGroupMemberAuthData", mock.Anything).Return(nil, ldap.NewError(ldap.LDAPResultNoSuchObject, errors.New("no such object")))
+		th.App.Ldap = ldapMock
+
+		summary, err := th.App.SyncLdapGroups()
+		require.Nil(t, err)
+
+		assert.ElementsMatch(t, []string{user2.Id}, memberIds(flaky))
+		assert.ElementsMatch(t, []string{user2.Id}, memberIds(healthy))
+		assert.ElementsMatch(t, []string{user1.Id}, memberIds(missing))
+
+		assert.Contains(t, summary.Errors, missing.RemoteId+": "+ldap.NewError(ldap.LDAPResultNoSuchObject, errors.New("no such object")).Error())
+	})
+
+	t.Run("paused group keeps its members", func(t *testing.T) {
+		group := createGroup()
+		group.SyncPaused = true
+		group, err := th.App.UpdateGroup(group)
+		require.Nil(t, err)
+
+		mockLdap(map[string][]string{group.RemoteId: {authData2}})
+
+		_, err = th.App.SyncLdapGroups()
+		require.Nil(t, err)
+		assert.ElementsMatch(t, []string{user1.Id}, memberIds(group))
+
+		group, err = th.App.GetGroup(group.Id)
+		require.Nil(t, err)
+		assert.Zero(t, group.LastSyncAt)
+
+		// Once unpaused, the next sync reconciles as usual
+		group.SyncPaused = false
+		_, err = th.App.UpdateGroup(group)
+		require.Nil(t, err)
+
+		_, err = th.App.SyncLdapGroups()
+		require.Nil(t, err)
+		assert.ElementsMatch(t, []string{user2.Id}, memberIds(group))
+	})
+
+	t.Run("display names come from the LDAP server", func(t *testing.T) {
+		renamed := createGroup()
+		alsoRenamed := createGroup()
+		unchanged := createGroup()
+
+		paused := createGroup()
+		paused.SyncPaused = true
+		paused, err := th.App.UpdateGroup(paused)
+		require.Nil(t, err)
+
+		mockLdap(
+			map[string][]string{},
+			&model.Group{RemoteId: renamed.RemoteId, DisplayName: "Engineering"},
+			&model.Group{RemoteId: alsoRenamed.RemoteId, DisplayName: "Marketing"},
+			&model.Group{RemoteId: unchanged.RemoteId, DisplayName: unchanged.DisplayName},
+			&model.Group{RemoteId: paused.RemoteId, DisplayName: "Sales"},
+		)
+
+		_, err = th.App.SyncLdapGroups()
+		require.Nil(t, err)
+
+		group, err := th.App.GetGroup(renamed.Id)
+		require.Nil(t, err)
+		assert.Equal(t, "Engineering", group.DisplayName)
+
+		group, err = th.App.GetGroup(alsoRenamed.Id)
+		require.Nil(t, err)
+		assert.Equal(t, "Marketing", group.DisplayName)
+
+		group, err = th.App.GetGroup(unchanged.Id)
+		require.Nil(t, err)
+		assert.Equal(t, unchanged.UpdateAt, group.UpdateAt)
+
+		// Only the members of a paused group are frozen
+		group, err = th.App.GetGroup(paused.Id)
+		require.Nil(t, err)
+		assert.Equal(t, "Sales", group.DisplayName)
+		assert.True(t, group.SyncPaused)
+	})
+}
+
+func TestSyncSamlGroupsForUser(t *testing.T) {
+	th := Setup(t)
+	defer th.TearDown()
+
+	th.App.SetLicense(model.NewTestLicense("ldap"))
+
+	user := th.CreateUser()
+
+	createGroup := func(source model.GroupSource, remoteId string) *model.Group {
+		group, err := th.App.CreateGroup(&model.Group{
+			Name:        "name" + model.NewId(),
+			DisplayName: "dn_" + remoteId,
+			Source:      source,
+			RemoteId:    remoteId,
+		})
+		require.Nil(t, err)
+		return group
+	}
+
+	engineering := createGroup(model.GroupSourceSaml, "engineering"+model.NewId())
+	sales := createGroup(model.GroupSourceSaml, "sales"+model.NewId())
+	paused := createGroup(model.GroupSourceSaml, "paused"+model.NewId())
+	custom := createGroup(model.GroupSourceCustom, model.NewId())
+
+	paused.SyncPaused = true
+	paused, err := th.App.UpdateGroup(paused)
+	require.Nil(t, err)
+
+	for _, group := range []*model.Group{sales, paused, custom} {
+		_, err = th.App.UpsertGroupMembers(group.Id, []string{user.Id})
+		require.Nil(t, err)
+	}
+
+	groupIds := func() []string {
+		groups, err := th.App.GetGroupsByUserId(user.Id)
+		require.Nil(t, err)
+		var ids []string
+		for _, group := range groups {
+			ids = append(ids, group.Id)
+		}
+		return ids
+	}
+
+	// The attributes of the assertion the user logs in with
+	attributes := map[string][]string{
+		"memberOf": {engineering.RemoteId, "unknown" + model.NewId()},
+		"email":    {user.Email},
+	}
+
+	t.Run("without a group attribute", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.SamlSettings.GroupAttribute = "" })
+
+		require.Nil(t, th.App.SyncSamlGroupsForUser(user.Id, attributes))
+		assert.ElementsMatch(t, []string{sales.Id, paused.Id, custom.Id}, groupIds())
+	})
+
+	t.Run("login assertion", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.SamlSettings.GroupAttribute = "memberOf" })
+
+		require.Nil(t, th.App.SyncSamlGroupsForUser(user.Id, attributes))
+		assert.ElementsMatch(t, []string{engineering.Id, paused.Id, custom.Id}, groupIds())
+
+		// Logging in again with the same assertion changes nothing
+		require.Nil(t, th.App.SyncSamlGroupsForUser(user.Id, attributes))
+		assert.ElementsMatch(t, []string{engineering.Id, paused.Id, custom.Id}, groupIds())
+
+		// An assertion without the attribute removes the user from the SAML groups
+		require.Nil(t, th.App.SyncSamlGroupsForUser(user.Id, map[string][]string{}))
+		assert.ElementsMatch(t, []string{paused.Id, custom.Id}, groupIds())
+	})
+
+	t.Run("without a license", func(t *testing.T) {
+		th.App.SetLicense(nil)
+		defer th.App.SetLicense(model.NewTestLicense("ldap"))
+
+		require.Nil(t, th.App.SyncSamlGroupsForUser(user.Id, attributes))
+		assert.ElementsMatch(t, []string{paused.Id, custom.Id}, groupIds())
+	})
+}
@@ -4,8 +4,11 @@
 package app
 
 import (
+	"net/http"
+	"strings"
 	"testing"
 
+	"github.com/mattermost/mattermost-server/einterfaces/mocks"
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/stretchr/testify/require"
 )
@@ -21,6 +24,7 @@ func TestGetGroup(t *testing.T) {
 
 	group, err = th.App.GetGroup(model.NewId())
 	require.NotNil(t, err)
+	require.Equal(t, http.StatusNotFound, err.StatusCode)
 	require.Nil(t, group)
 }
 
@@ -114,6 +118,64 @@ func TestCreateOrRestoreGroupMember(t *testing.T) {
 	require.Nil(t, g)
 }
 
+func TestCreateOrRestoreGroupMemberMetrics(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+	group := th.CreateGroup()
+
+	metricsMock := &mocks.MetricsInterface{}
+	metricsMock.On("IncrementGroupMemberUpsertCounter").Return()
+	th.App.Metrics = metricsMock
+	defer func() { th.App.Metrics = nil }()
+
+	_, err := th.App.CreateOrRestoreGroupMember(group.Id, th.BasicUser.Id)
+	require.Nil(t, err)
+	metricsMock.AssertNumberOfCalls(t, "IncrementGroupMemberUpsertCounter", 1)
+
+	_, err = th.App.CreateOrRestoreGroupMember(model.NewId(), th.BasicUser.Id)
+	require.NotNil(t, err)
+	metricsMock.AssertNumberOfCalls(t, "IncrementGroupMemberUpsertCounter", 1)
+}
+
+func TestGetGroupMemberIds(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+	group := th.CreateGroup()
+
+	userIDs, hasMore, err := th.App.GetGroupMemberIds(group.Id, 2)
+	require.Nil(t, err)
+	require.Empty(t, userIDs)
+	require.False(t, hasMore)
+
+	_, err = th.App.UpsertGroupMembers(group.Id, []string{th.BasicUser.Id, th.BasicUser2.Id})
+	require.Nil(t, err)
+
+	// At the cap
+	userIDs, hasMore, err = th.App.GetGroupMemberIds(group.Id, 2)
+	require.Nil(t, err)
+	require.ElementsMatch(t, []string{th.BasicUser.Id, th.BasicUser2.Id}, userIDs)
+	require.False(t, hasMore)
+
+	user := th.CreateUser()
+	_, err = th.App.CreateOrRestoreGroupMember(group.Id, user.Id)
+	require.Nil(t, err)
+
+	// Above the cap
+	userIDs, hasMore, err = th.App.GetGroupMemberIds(group.Id, 2)
+	require.Nil(t, err)
+	require.Len(t, userIDs, 2)
+	require.True(t, hasMore)
+
+	// Deleted members are not listed
+	_, err = th.App.DeleteGroupMember(group.Id, user.Id)
+	require.Nil(t, err)
+
+	userIDs, hasMore, err = th.App.GetGroupMemberIds(group.Id, 2)
+	require.Nil(t, err)
+	require.Len(t, userIDs, 2)
+	require.False(t, hasMore)
+}
+
 func TestDeleteGroupMember(t *testing.T) {
 	th := Setup(t).InitBasic()
 	defer th.TearDown()
@@ -131,6 +193,93 @@ func TestDeleteGroupMember(t *testing.T) {
 	require.Nil(t, groupMember)
 }
 
+func TestIsUserInGroupCacheInvalidation(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+	group := th.CreateGroup()
+
+	// Prime the cache before the user is a member.
+	isMember, err := th.App.IsUserInGroup(th.BasicUser.Id, group.Id)
+	require.Nil(t, err)
+	require.False(t, isMember)
+
+	_, err = th.App.CreateOrRestoreGroupMember(group.Id, th.BasicUser.Id)
+	require.Nil(t, err)
+
+	isMember, err = th.App.IsUserInGroup(th.BasicUser.Id, group.Id)
+	require.Nil(t, err)
+	require.True(t, isMember)
+
+	groups, err := th.App.GetGroupsByUserId(th.BasicUser.Id)
+	require.Nil(t, err)
+	require.Len(t, groups, 1)
+	require.Equal(t, group.Id, groups[0].Id)
+
+	_, err = th.App.DeleteGroupMembers(group.Id, []string{th.BasicUser.Id})
+	require.Nil(t, err)
+
+	isMember, err = th.App.IsUserInGroup(th.BasicUser.Id, group.Id)
+	require.Nil(t, err)
+	require.False(t, isMember)
+
+	_, err = th.App.UpsertGroupMembers(group.Id, []string{th.BasicUser.Id})
+	require.Nil(t, err)
+
+	isMember, err = th.App.IsUserInGroup(th.BasicUser.Id, group.Id)
+	require.Nil(t, err)
+	require.True(t, isMember)
+}
+
+func TestAutocompleteGroupsCache(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	metricsMock := &mocks.MetricsInterface{}
+	metricsMock.On("IncrementMemCacheHitCounter", "GroupSearch").Return()
+	metricsMock.On("IncrementMemCacheMissCounter", "GroupSearch").Return()
+	metricsMock.On("IncrementMemCacheInvalidationCounter", "GroupSearch").Return()
+	th.App.Metrics = metricsMock
+	defer func() { th.App.Metrics = nil }()
+
+	prefix := "ac" + model.NewId()[:10]
+
+	// Prime the cache with an empty result.
+	groups, err := th.App.AutocompleteGroups(prefix)
+	require.Nil(t, err)
+	require.Empty(t, groups)
+
+	// Searches differing only in surrounding spaces or case share the cached result.
+	groups, err = th.App.AutocompleteGroups(" " + prefix)
+	require.Nil(t, err)
+	require.Empty(t, groups)
+	groups, err = th.App.AutocompleteGroups(strings.ToUpper(prefix))
+	require.Nil(t, err)
+	require.Empty(t, groups)
+	metricsMock.AssertNumberOfCalls(t, "IncrementMemCacheMissCounter", 1)
+	metricsMock.AssertNumberOfCalls(t, "IncrementMemCacheHitCounter", 2)
+
+	group, err := th.App.CreateGroup(&model.Group{
+		DisplayName:          "dn_" + prefix,
+		Name:                 prefix + "-eng",
+		Source:               model.GroupSourceCustom,
+		AllowReference:       true,
+		MembershipWebhookURL: "https://example.com/" + prefix,
+	})
+	require.Nil(t, err)
+
+	groups, err = th.App.AutocompleteGroups(prefix)
+	require.Nil(t, err)
+	require.Len(t, groups, 1)
+	require.Equal(t, group.Id, groups[0].Id)
+
+	// Sanitizing the returned groups does not change the cached ones.
+	groups[0].Sanitize()
+	groups, err = th.App.AutocompleteGroups(prefix)
+	require.Nil(t, err)
+	require.Len(t, groups, 1)
+	require.Equal(t, group.MembershipWebhookURL, groups[0].MembershipWebhookURL)
+}
+
 func TestCreateGroupSyncable(t *testing.T) {
 	th := Setup(t).InitBasic()
 	defer th.TearDown()
@@ -179,6 +328,33 @@ func TestGetGroupSyncables(t *testing.T) {
 	require.NotEmpty(t, groupTeams)
 }
 
+func TestGetGroupWithSyncables(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+	group := th.CreateGroup()
+
+	_, err := th.App.CreateGroupSyncable(model.NewGroupTeam(group.Id, th.BasicTeam.Id, false))
+	require.Nil(t, err)
+
+	for _, channel := range []*model.Channel{th.BasicChannel, th.CreateChannel(th.BasicTeam)} {
+		_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(group.Id, channel.Id, false))
+		require.Nil(t, err)
+	}
+
+	groupWithSyncables, err := th.App.GetGroupWithSyncables(group, 1)
+	require.Nil(t, err)
+	require.Equal(t, group.Id, groupWithSyncables.Id)
+	require.Len(t, groupWithSyncables.Teams, 1)
+	require.False(t, groupWithSyncables.TeamsHasMore)
+	require.Len(t, groupWithSyncables.Channels, 1)
+	require.True(t, groupWithSyncables.ChannelsHasMore)
+
+	groupWithSyncables, err = th.App.GetGroupWithSyncables(group, 2)
+	require.Nil(t, err)
+	require.Len(t, groupWithSyncables.Channels, 2)
+	require.False(t, groupWithSyncables.ChannelsHasMore)
+}
+
 func TestDeleteGroupSyncable(t *testing.T) {
 	th := Setup(t).InitBasic()
 	defer th.TearDown()
@@ -193,9 +369,14 @@ func TestDeleteGroupSyncable(t *testing.T) {
 	require.Nil(t, err)
 	require.NotNil(t, gs)
 
-	gs, err = th.App.DeleteGroupSyncable(group.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel)
+	// Deleting again succeeds without touching the link
+	again, err := th.App.DeleteGroupSyncable(group.Id, th.BasicChannel.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, err)
+	require.Equal(t, gs.DeleteAt, again.DeleteAt)
+	require.Equal(t, gs.UpdateAt, again.UpdateAt)
+
+	_, err = th.App.DeleteGroupSyncable(group.Id, model.NewId(), model.GroupSyncableTypeChannel)
 	require.NotNil(t, err)
-	require.Nil(t, gs)
 }
 
 func TestGetGroupsByChannel(t *testing.T) {
@@ -215,11 +396,11 @@ func TestGetGroupsByChannel(t *testing.T) {
 	require.Nil(t, err)
 	require.NotNil(t, gs)
 
-	groups, err := th.App.GetGroupsByChannel(th.BasicChannel.Id, 0, 60)
+	groups, err := th.App.GetGroupsByChannel(th.BasicChannel.Id, 0, 60, model.GroupSearchOpts{})
 	require.Nil(t, err)
 	require.ElementsMatch(t, []*model.Group{group}, groups)
 
-	groups, err = th.App.GetGroupsByChannel(model.NewId(), 0, 60)
+	groups, err = th.App.GetGroupsByChannel(model.NewId(), 0, 60, model.GroupSearchOpts{})
 	require.Nil(t, err)
 	require.Empty(t, groups)
 }
@@ -241,11 +422,11 @@ func TestGetGroupsByTeam(t *testing.T) {
 	require.Nil(t, err)
 	require.NotNil(t, gs)
 
-	groups, err := th.App.GetGroupsByTeam(th.BasicTeam.Id, 0, 60)
+	groups, err := th.App.GetGroupsByTeam(th.BasicTeam.Id, 0, 60, model.GroupSearchOpts{})
 	require.Nil(t, err)
 	require.ElementsMatch(t, []*model.Group{group}, groups)
 
-	groups, err = th.App.GetGroupsByTeam(model.NewId(), 0, 60)
+	groups, err = th.App.GetGroupsByTeam(model.NewId(), 0, 60, model.GroupSearchOpts{})
 	require.Nil(t, err)
 	require.Empty(t, groups)
 }
@@ -0,0 +1,71 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	IDEMPOTENCY_RESPONSE_SEC = 5 * 60
+	IDEMPOTENCY_PENDING_SEC  = 60
+)
+
+// idempotencyKeyId identifies the key a user sent to an endpoint. The parts are hashed so that the id has a fixed
+// length whatever the client sends.
+func idempotencyKeyId(userId, endpoint, key string) string {
+	hash := sha256.Sum256([]byte(userId + ":" + endpoint + ":" + key))
+	return hex.EncodeToString(hash[:])
+}
+
+// BeginIdempotentRequest reserves the idempotency key for the session user and endpoint. If the key is new, nil is
+// returned and the caller must execute the request and then call CompleteIdempotentRequest or
+// AbandonIdempotentRequest. If a response was already recorded for the key, it is returned for replay. Reusing a key
+// with a different request body, or while the original request is still being processed, is an error. Keys are kept
+// in the database, so that a retry routed to any node of a cluster is recognized, for IDEMPOTENCY_RESPONSE_SEC once
+// their request completes.
+func (a *App) BeginIdempotentRequest(endpoint, key, requestHash string) (*model.IdempotentResponse, *model.AppError) {
+	expiresAt := model.GetMillis() + IDEMPOTENCY_PENDING_SEC*1000
+
+	result := <-a.Srv.Store.Idempotency().Reserve(idempotencyKeyId(a.Session.UserId, endpoint, key), &model.IdempotentResponse{RequestHash: requestHash, Pending: true}, expiresAt)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	if result.Data == nil {
+		return nil, nil
+	}
+
+	response := result.Data.(*model.IdempotentResponse)
+	if response.RequestHash != requestHash {
+		return nil, model.NewAppError("BeginIdempotentRequest", "app.idempotency.key_reused.app_error", nil, "", http.StatusUnprocessableEntity)
+	}
+
+	if response.Pending {
+		return nil, model.NewAppError("BeginIdempotentRequest", "app.idempotency.in_progress.app_error", nil, "", http.StatusConflict)
+	}
+
+	return response, nil
+}
+
+// CompleteIdempotentRequest records the response for a key reserved with BeginIdempotentRequest.
+func (a *App) CompleteIdempotentRequest(endpoint, key string, response *model.IdempotentResponse) {
+	expiresAt := model.GetMillis() + IDEMPOTENCY_RESPONSE_SEC*1000
+
+	if result := <-a.Srv.Store.Idempotency().Save(idempotencyKeyId(a.Session.UserId, endpoint, key), response, expiresAt); result.Err != nil {
+		mlog.Error("Failed to record the response for an idempotency key", mlog.String("endpoint", endpoint), mlog.Err(result.Err))
+	}
+}
+
+// AbandonIdempotentRequest releases a key reserved with BeginIdempotentRequest without recording a response, so that
+// a failed request can be retried with the same key.
+func (a *App) AbandonIdempotentRequest(endpoint, key string) {
+	if result := <-a.Srv.Store.Idempotency().Delete(idempotencyKeyId(a.Session.UserId, endpoint, key)); result.Err != nil {
+		mlog.Error("Failed to release an idempotency key", mlog.String("endpoint", endpoint), mlog.Err(result.Err))
+	}
+}
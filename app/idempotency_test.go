@@ -0,0 +1,89 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestIdempotentRequest(t *testing.T) {
+	th := Setup(t)
+	defer th.TearDown()
+
+	a := th.App
+	a.Session = model.Session{UserId: model.NewId()}
+	endpoint := "POST /api/v4/groups"
+
+	t.Run("a new key is reserved and replayed once completed", func(t *testing.T) {
+		key := model.NewId()
+
+		response, err := a.BeginIdempotentRequest(endpoint, key, "hash")
+		require.Nil(t, err)
+		require.Nil(t, response)
+
+		// A concurrent retry is rejected while the original request is in flight.
+		_, err = a.BeginIdempotentRequest(endpoint, key, "hash")
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusConflict, err.StatusCode)
+
+		a.CompleteIdempotentRequest(endpoint, key, &model.IdempotentResponse{
+			RequestHash: "hash",
+			StatusCode:  http.StatusCreated,
+			Header:      http.Header{"Content-Type": []string{"application/json"}},
+			Body:        []byte("{}"),
+		})
+
+		response, err = a.BeginIdempotentRequest(endpoint, key, "hash")
+		require.Nil(t, err)
+		require.NotNil(t, response)
+		assert.Equal(t, http.StatusCreated, response.StatusCode)
+		assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+		assert.Equal(t, []byte("{}"), response.Body)
+	})
+
+	t.Run("reusing a key with a different body is rejected", func(t *testing.T) {
+		key := model.NewId()
+
+		_, err := a.BeginIdempotentRequest(endpoint, key, "hash")
+		require.Nil(t, err)
+
+		_, err = a.BeginIdempotentRequest(endpoint, key, "other")
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, err.StatusCode)
+	})
+
+	t.Run("an abandoned key can be retried", func(t *testing.T) {
+		key := model.NewId()
+
+		_, err := a.BeginIdempotentRequest(endpoint, key, "hash")
+		require.Nil(t, err)
+
+		a.AbandonIdempotentRequest(endpoint, key)
+
+		response, err := a.BeginIdempotentRequest(endpoint, key, "hash")
+		require.Nil(t, err)
+		assert.Nil(t, response)
+	})
+
+	t.Run("keys are scoped to the user", func(t *testing.T) {
+		key := model.NewId()
+
+		_, err := a.BeginIdempotentRequest(endpoint, key, "hash")
+		require.Nil(t, err)
+
+		userId := a.Session.UserId
+		a.Session.UserId = model.NewId()
+		defer func() { a.Session.UserId = userId }()
+
+		response, err := a.BeginIdempotentRequest(endpoint, key, "hash")
+		require.Nil(t, err)
+		assert.Nil(t, response)
+	})
+}
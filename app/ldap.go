@@ -12,12 +12,23 @@ import (
 	"github.com/mattermost/mattermost-server/utils"
 )
 
+// SyncLdap runs the LDAP sync job in the background, followed by the sync of the LDAP groups' members when the
+// license includes LDAP groups.
 func (a *App) SyncLdap() {
 	a.Srv.Go(func() {
 
 		if license := a.License(); license != nil && *license.Features.LDAP && *a.Config().LdapSettings.EnableSync {
 			if ldapI := a.Ldap; ldapI != nil {
-				ldapI.StartSynchronizeJob(false)
+				if _, err := ldapI.StartSynchronizeJob(true); err != nil {
+					mlog.Error("Failed to run the LDAP sync job", mlog.Err(err))
+					return
+				}
+
+				if *license.Features.LDAPGroups {
+					if _, err := a.SyncLdapGroups(); err != nil {
+						mlog.Error("Failed to sync LDAP groups", mlog.Err(err))
+					}
+				}
 			} else {
 				mlog.Error(fmt.Sprintf("%v", model.NewAppError("SyncLdap", "ent.ldap.disabled.app_error", nil, "", http.StatusNotImplemented).Error()))
 			}
@@ -59,6 +70,33 @@ func (a *App) GetLdapGroup(ldapGroupID string) (*model.Group, *model.AppError) {
 	return group, nil
 }
 
+// ValidateLdapGroup looks up the LDAP group with the given id on the LDAP server, without syncing it, to check that it
+// still exists and to count its members. Errors from the LDAP server are reported as a bad gateway.
+func (a *App) ValidateLdapGroup(ldapGroupID string) (*model.LdapGroupValidation, *model.AppError) {
+	if a.Ldap == nil {
+		ae := model.NewAppError("ValidateLdapGroup", "ent.ldap.app_error", nil, "", http.StatusNotImplemented)
+		mlog.Error(fmt.Sprintf("%v", ae.Error()))
+		return nil, ae
+	}
+
+	group, err := a.Ldap.GetGroup(ldapGroupID)
+	if err != nil {
+		return nil, model.NewAppError("ValidateLdapGroup", "app.ldap.validate_group.app_error", nil, err.Error(), http.StatusBadGateway)
+	}
+
+	validation := &model.LdapGroupValidation{}
+	if group == nil {
+		return validation, nil
+	}
+	validation.Exists = true
+
+	if validation.MemberCount, err = a.Ldap.GetGroupMemberCount(ldapGroupID); err != nil {
+		return nil, model.NewAppError("ValidateLdapGroup", "app.ldap.validate_group.app_error", nil, err.Error(), http.StatusBadGateway)
+	}
+
+	return validation, nil
+}
+
 // GetAllLdapGroupsPage retrieves all LDAP groups under the configured base DN using the default or configured group
 // filter.
 func (a *App) GetAllLdapGroupsPage(page int, perPage int, opts model.GroupSearchOpts) ([]*model.Group, int, *model.AppError) {
@@ -161,3 +199,90 @@ func (a *App) SwitchLdapToEmail(ldapPassword, code, email, newPassword string) (
 
 	return "/login?extra=signin_change", nil
 }
+
+// LinkLdapGroupsToTeam links each of the LDAP groups with the given remote ids to the team, creating or restoring the
+// local group of an LDAP group that was never linked or was unlinked. A remote id unknown to the LDAP server is
+// reported as not found, and the other remote ids are still linked.
+func (a *App) LinkLdapGroupsToTeam(teamID string, remoteIDs []string, autoAdd bool) ([]*model.LdapGroupTeamLinkStatus, *model.AppError) {
+	if a.Ldap == nil {
+		ae := model.NewAppError("LinkLdapGroupsToTeam", "ent.ldap.app_error", nil, "", http.StatusNotImplemented)
+		mlog.Error(fmt.Sprintf("%v", ae.Error()))
+		return nil, ae
+	}
+
+	statuses := make([]*model.LdapGroupTeamLinkStatus, 0, len(remoteIDs))
+	for _, remoteID := range remoteIDs {
+		status := &model.LdapGroupTeamLinkStatus{RemoteId: remoteID, Status: model.LdapGroupTeamLinkStatusLinked}
+		statuses = append(statuses, status)
+
+		group, err := a.upsertLdapGroup(remoteID)
+		if err != nil {
+			status.Status = model.LdapGroupTeamLinkStatusError
+			status.Error = err
+			continue
+		}
+		if group == nil {
+			status.Status = model.LdapGroupTeamLinkStatusNotFound
+			continue
+		}
+		status.GroupId = group.Id
+
+		if err := a.linkGroupToTeam(group.Id, teamID, autoAdd); err != nil {
+			status.Status = model.LdapGroupTeamLinkStatusError
+			status.Error = err
+		}
+	}
+
+	return statuses, nil
+}
+
+// upsertLdapGroup returns the local group of the LDAP group with the given remote id, creating it or restoring it if
+// needed. It returns nil if the LDAP server does not know the remote id.
+func (a *App) upsertLdapGroup(remoteID string) (*model.Group, *model.AppError) {
+	ldapGroup, err := a.Ldap.GetGroup(remoteID)
+	if err != nil {
+		return nil, err
+	}
+	if ldapGroup == nil {
+		return nil, nil
+	}
+
+	group, err := a.GetGroupByRemoteID(ldapGroup.RemoteId, model.GroupSourceLdap)
+	if err != nil && err.Id != "store.sql_group.no_rows" {
+		return nil, err
+	}
+
+	if group == nil {
+		return a.CreateLdapGroup(&model.Group{
+			DisplayName: ldapGroup.DisplayName,
+			RemoteId:    ldapGroup.RemoteId,
+			Source:      model.GroupSourceLdap,
+		})
+	}
+
+	if group.DeleteAt != 0 {
+		group.DeleteAt = 0
+		group.DisplayName = ldapGroup.DisplayName
+		return a.UpdateGroup(group)
+	}
+
+	return group, nil
+}
+
+// linkGroupToTeam links the group to the team with the given auto-add setting, restoring a deleted link.
+func (a *App) linkGroupToTeam(groupID, teamID string, autoAdd bool) *model.AppError {
+	groupSyncable, err := a.GetGroupSyncable(groupID, teamID, model.GroupSyncableTypeTeam)
+	if err != nil && err.Id != "store.sql_group.no_rows" {
+		return err
+	}
+
+	if groupSyncable == nil {
+		_, err = a.CreateGroupSyncable(model.NewGroupTeam(groupID, teamID, autoAdd))
+		return err
+	}
+
+	groupSyncable.DeleteAt = 0
+	groupSyncable.AutoAdd = autoAdd
+	_, err = a.UpdateGroupSyncable(groupSyncable)
+	return err
+}
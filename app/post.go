@@ -208,6 +208,10 @@ func (a *App) CreatePost(post *model.Post, channel *model.Channel, triggerWebhoo
 		return nil, err
 	}
 
+	if err := a.checkGroupMentionSize(post, channel); err != nil {
+		return nil, err
+	}
+
 	// Temporary fix so old plugins don't clobber new fields in SlackAttachment struct, see MM-13088
 	if attachments, ok := post.Props["attachments"].([]*model.SlackAttachment); ok {
 		jsonAttachments, err := json.Marshal(attachments)
@@ -320,6 +324,59 @@ func (a *App) attachFilesToPost(post *model.Post) *model.AppError {
 	return nil
 }
 
+// checkGroupMentionSize rejects a post mentioning a group that would notify more members of the channel than
+// GroupSettings.MaxMentionMembers allows, unless the post is marked as confirmed with the confirm_group_mention prop,
+// in the same way that clients ask for confirmation before sending @all or @channel.
+func (a *App) checkGroupMentionSize(post *model.Post, channel *model.Channel) *model.AppError {
+	maxMembers := *a.Config().GroupSettings.MaxMentionMembers
+	if maxMembers <= 0 || post.IsSystemMessage() {
+		return nil
+	}
+
+	confirmed := post.Props[model.POST_PROPS_CONFIRM_GROUP_MENTION]
+	if confirmed != nil {
+		delete(post.Props, model.POST_PROPS_CONFIRM_GROUP_MENTION)
+		if confirmed == true || confirmed == "true" {
+			return nil
+		}
+	}
+
+	var names []string
+	for _, mention := range GetExplicitMentions(post, map[string][]string{}).OtherPotentialMentions {
+		mention = strings.ToLower(mention)
+		names = append(names, mention)
+		if trimmed := strings.TrimRight(mention, ".-:_"); trimmed != mention {
+			names = append(names, trimmed)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	result := <-a.Srv.Store.Group().GetByNames(names)
+	if result.Err != nil {
+		return result.Err
+	}
+
+	for _, group := range result.Data.([]*model.Group) {
+		if !group.AllowReference {
+			continue
+		}
+
+		count, err := a.GetGroupMentionCount(group.Id, channel.Id)
+		if err != nil {
+			return err
+		}
+
+		if count > int64(maxMembers) {
+			params := map[string]interface{}{"Name": group.Name, "Count": count, "Max": maxMembers}
+			return model.NewAppError("createPost", "api.post.group_mention.too_large", params, "group_id="+group.Id, http.StatusBadRequest)
+		}
+	}
+
+	return nil
+}
+
 // FillInPostProps should be invoked before saving posts to fill in properties such as
 // channel_mentions.
 //
@@ -693,6 +693,61 @@ func TestCreatePost(t *testing.T) {
 	})
 }
 
+func TestCreatePostGroupMentionLimit(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	group := th.CreateGroup()
+	group.AllowReference = true
+	group, err := th.App.UpdateGroup(group)
+	require.Nil(t, err)
+
+	// Three members of the group are in the channel
+	user := th.CreateUser()
+	th.LinkUserToTeam(user, th.BasicTeam)
+	th.AddUserToChannel(user, th.BasicChannel)
+	th.AddUserToChannel(th.BasicUser2, th.BasicChannel)
+	for _, userId := range []string{th.BasicUser.Id, th.BasicUser2.Id, user.Id} {
+		_, err = th.App.CreateOrRestoreGroupMember(group.Id, userId)
+		require.Nil(t, err)
+	}
+
+	createPost := func(props model.StringInterface) (*model.Post, *model.AppError) {
+		return th.App.CreatePost(&model.Post{
+			ChannelId: th.BasicChannel.Id,
+			Message:   "hello @" + group.Name + ".",
+			UserId:    th.BasicUser.Id,
+			Props:     props,
+		}, th.BasicChannel, false)
+	}
+
+	t.Run("under the limit", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.GroupSettings.MaxMentionMembers = 3 })
+
+		_, err := createPost(nil)
+		require.Nil(t, err)
+	})
+
+	t.Run("over the limit", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.GroupSettings.MaxMentionMembers = 2 })
+
+		_, err := createPost(nil)
+		require.NotNil(t, err)
+		assert.Equal(t, "api.post.group_mention.too_large", err.Id)
+
+		post, err := createPost(model.StringInterface{model.POST_PROPS_CONFIRM_GROUP_MENTION: true})
+		require.Nil(t, err)
+		assert.NotContains(t, post.Props, model.POST_PROPS_CONFIRM_GROUP_MENTION)
+	})
+
+	t.Run("limit disabled", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.GroupSettings.MaxMentionMembers = 0 })
+
+		_, err := createPost(nil)
+		require.Nil(t, err)
+	})
+}
+
 func TestPatchPost(t *testing.T) {
 	t.Run("call PreparePostForClient before returning", func(t *testing.T) {
 		th := Setup(t).InitBasic()
@@ -86,6 +86,9 @@ type Server struct {
 	htmlTemplateWatcher     *utils.HTMLTemplateWatcher
 	sessionCache            *utils.Cache
 	seenPendingPostIdsCache *utils.Cache
+	groupSearchCache        *utils.Cache
+	groupReconcileQueue     *groupReconcileQueue
+	groupSyncLocks          *groupSyncLocks
 	configListenerId        string
 	licenseListenerId       string
 	logListenerId           string
@@ -134,6 +137,9 @@ func NewServer(options ...Option) (*Server, error) {
 		licenseListeners:        map[string]func(){},
 		sessionCache:            utils.NewLru(model.SESSION_CACHE_SIZE),
 		seenPendingPostIdsCache: utils.NewLru(PENDING_POST_IDS_CACHE_SIZE),
+		groupSearchCache:        utils.NewLruWithParams(GROUP_SEARCH_CACHE_SIZE, "GroupSearch", GROUP_SEARCH_CACHE_SEC, model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_GROUP_SEARCH),
+		groupReconcileQueue:     newGroupReconcileQueue(),
+		groupSyncLocks:          newGroupSyncLocks(),
 		clientConfig:            make(map[string]string),
 	}
 	for _, option := range options {
@@ -251,6 +257,21 @@ func NewServer(options ...Option) (*Server, error) {
 		s.Go(func() {
 			runCommandWebhookCleanupJob(s)
 		})
+		s.Go(func() {
+			runIdempotencyKeyCleanupJob(s)
+		})
+		s.Go(func() {
+			runGroupMemberExpiryJob(s)
+		})
+		s.Go(func() {
+			runGroupSyncableExpiryJob(s)
+		})
+		s.Go(func() {
+			runGroupChannelPatternJob(s)
+		})
+		s.Go(func() {
+			runLdapGroupSyncJob(s)
+		})
 
 		if complianceI := s.Compliance; complianceI != nil {
 			complianceI.StartComplianceDailyJob()
@@ -304,6 +325,7 @@ func (s *Server) Shutdown() error {
 	s.RunOldAppShutdown()
 
 	s.StopHTTPServer()
+	s.groupReconcileQueue.stop()
 	s.WaitForGoroutines()
 
 	if s.Store != nil {
@@ -626,6 +648,44 @@ func runCommandWebhookCleanupJob(s *Server) {
 	}, time.Hour*1)
 }
 
+func runIdempotencyKeyCleanupJob(s *Server) {
+	doIdempotencyKeyCleanup(s)
+	model.CreateRecurringTask("Idempotency Key Cleanup", func() {
+		doIdempotencyKeyCleanup(s)
+	}, time.Hour*1)
+}
+
+func runGroupMemberExpiryJob(s *Server) {
+	doGroupMemberExpiry(s)
+	model.CreateRecurringTask("Group Member Expiry", func() {
+		doGroupMemberExpiry(s)
+	}, time.Minute*5)
+}
+
+func runGroupSyncableExpiryJob(s *Server) {
+	doGroupSyncableExpiry(s)
+	model.CreateRecurringTask("Group Syncable Expiry", func() {
+		doGroupSyncableExpiry(s)
+	}, time.Minute*5)
+}
+
+// runGroupChannelPatternJob links groups to the new channels matching their channel name patterns. The first run
+// looks at every channel, so that channels created while the server was down are not missed.
+func runGroupChannelPatternJob(s *Server) {
+	since := doGroupChannelPatterns(s, 0)
+	model.CreateRecurringTask("Group Channel Patterns", func() {
+		since = doGroupChannelPatterns(s, since)
+	}, time.Minute*5)
+}
+
+// runLdapGroupSyncJob syncs the members of the LDAP groups as often as the LDAP sync job runs. The interval is read
+// when the server starts.
+func runLdapGroupSyncJob(s *Server) {
+	model.CreateRecurringTask("LDAP Group Sync", func() {
+		doLdapGroupSync(s)
+	}, time.Duration(*s.Config().LdapSettings.SyncIntervalMinutes)*time.Minute)
+}
+
 func runSessionCleanupJob(s *Server) {
 	doSessionCleanup(s)
 	model.CreateRecurringTask("Session Cleanup", func() {
@@ -651,10 +711,63 @@ func doCommandWebhookCleanup(s *Server) {
 	s.Store.CommandWebhook().Cleanup()
 }
 
+func doIdempotencyKeyCleanup(s *Server) {
+	s.Store.Idempotency().Cleanup()
+}
+
 const (
-	SESSIONS_CLEANUP_BATCH_SIZE = 1000
+	SESSIONS_CLEANUP_BATCH_SIZE      = 1000
+	GROUP_MEMBER_EXPIRY_BATCH_SIZE   = 1000
+	GROUP_SYNCABLE_EXPIRY_BATCH_SIZE = 1000
 )
 
+func doGroupMemberExpiry(s *Server) {
+	if err := s.FakeApp().DeleteExpiredGroupMembers(); err != nil {
+		mlog.Error("Failed to remove expired group members", mlog.Err(err))
+	}
+}
+
+func doGroupSyncableExpiry(s *Server) {
+	if err := s.FakeApp().DeleteExpiredGroupSyncables(); err != nil {
+		mlog.Error("Failed to remove expired group syncables", mlog.Err(err))
+	}
+}
+
+// doGroupChannelPatterns links the channels created since the given time and returns the time the next run should
+// start from. After a failure, even of a single pattern, the next run retries from the same time; the channels already
+// linked are skipped then.
+func doGroupChannelPatterns(s *Server, since int64) int64 {
+	startAt := model.GetMillis()
+	statuses, err := s.FakeApp().LinkGroupChannelPatterns(since)
+	if err != nil {
+		mlog.Error("Failed to link groups to channels matching their patterns", mlog.Err(err))
+		return since
+	}
+
+	failed := false
+	for _, status := range statuses {
+		if status.Error != nil {
+			mlog.Error("Failed to link a group to channels matching its pattern", mlog.String("group_id", status.GroupId), mlog.String("pattern", status.Pattern), mlog.Int("linked", status.Linked), mlog.Err(status.Error))
+			failed = true
+		}
+	}
+	if failed {
+		return since
+	}
+	return startAt
+}
+
+func doLdapGroupSync(s *Server) {
+	license := s.License()
+	if license == nil || !*license.Features.LDAPGroups || !*s.Config().LdapSettings.EnableSync || s.Ldap == nil {
+		return
+	}
+
+	if _, err := s.FakeApp().SyncLdapGroups(); err != nil {
+		mlog.Error("Failed to sync LDAP groups", mlog.Err(err))
+	}
+}
+
 func doSessionCleanup(s *Server) {
 	s.Store.Session().Cleanup(model.GetMillis(), SESSIONS_CLEANUP_BATCH_SIZE)
 }
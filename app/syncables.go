@@ -4,7 +4,11 @@
 package app
 
 import (
+	"strings"
+
 	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/utils"
 )
 
 // CreateDefaultMemberships adds users to teams and channels based on their group memberships and how those groups are
@@ -15,8 +19,23 @@ func (a *App) CreateDefaultMemberships(since int64) error {
 		return appErr
 	}
 
+	// A user brought in by several groups is added silently only if every one of those links suppresses
+	// notifications.
+	notifyTeam := map[model.UserTeamIDPair]bool{}
 	for _, userTeam := range teamMembers {
-		_, err := a.AddTeamMember(userTeam.TeamID, userTeam.UserID)
+		key := model.UserTeamIDPair{UserID: userTeam.UserID, TeamID: userTeam.TeamID}
+		notifyTeam[key] = notifyTeam[key] || !userTeam.SuppressNotifications
+	}
+
+	for _, userTeam := range teamMembers {
+		key := model.UserTeamIDPair{UserID: userTeam.UserID, TeamID: userTeam.TeamID}
+		notify, pending := notifyTeam[key]
+		if !pending {
+			continue
+		}
+		delete(notifyTeam, key)
+
+		err := a.addGroupSyncedTeamMember(userTeam.TeamID, userTeam.UserID, !notify)
 		if err != nil {
 			return err
 		}
@@ -32,7 +51,23 @@ func (a *App) CreateDefaultMemberships(since int64) error {
 		return appErr
 	}
 
+	// A user brought in by several groups gets the roles of each of those links.
+	notifyChannel := map[model.UserChannelIDPair]bool{}
+	channelRoles := map[model.UserChannelIDPair][]string{}
 	for _, userChannel := range channelMembers {
+		key := model.UserChannelIDPair{UserID: userChannel.UserID, ChannelID: userChannel.ChannelID}
+		notifyChannel[key] = notifyChannel[key] || !userChannel.SuppressNotifications
+		channelRoles[key] = append(channelRoles[key], strings.Fields(userChannel.Roles)...)
+	}
+
+	for _, userChannel := range channelMembers {
+		key := model.UserChannelIDPair{UserID: userChannel.UserID, ChannelID: userChannel.ChannelID}
+		notify, pending := notifyChannel[key]
+		if !pending {
+			continue
+		}
+		delete(notifyChannel, key)
+
 		channel, err := a.GetChannel(userChannel.ChannelID)
 		if err != nil {
 			return err
@@ -45,7 +80,7 @@ func (a *App) CreateDefaultMemberships(since int64) error {
 
 		// First add user to team
 		if tmem == nil {
-			_, err = a.AddTeamMember(channel.TeamId, userChannel.UserID)
+			err = a.addGroupSyncedTeamMember(channel.TeamId, userChannel.UserID, !notify)
 			if err != nil {
 				return err
 			}
@@ -55,11 +90,15 @@ func (a *App) CreateDefaultMemberships(since int64) error {
 			)
 		}
 
-		_, err = a.AddChannelMember(userChannel.UserID, channel, "", "")
+		_, err = a.addChannelMember(userChannel.UserID, channel, "", "", notify)
 		if err != nil {
 			return err
 		}
 
+		if err = a.addGroupSyncedChannelRoles(channel.Id, userChannel.UserID, channelRoles[key]); err != nil {
+			return err
+		}
+
 		a.Log.Info("added channelmember",
 			mlog.String("user_id", userChannel.UserID),
 			mlog.String("channel_id", userChannel.ChannelID),
@@ -69,6 +108,265 @@ func (a *App) CreateDefaultMemberships(since int64) error {
 	return nil
 }
 
+// AddGroupMembersToChannel adds the members of the group linked to a channel who do not belong to it yet, as the group
+// sync would for an auto-add link. Only those users are touched, so that linking a group which mostly overlaps with the
+// channel stays cheap. It returns how many users were added and how many of the group's members already belonged to
+// the channel.
+func (a *App) AddGroupMembersToChannel(groupSyncable *model.GroupSyncable) (int, int, *model.AppError) {
+	channel, err := a.GetChannel(groupSyncable.SyncableId)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Nobody can join an archived channel.
+	if channel.DeleteAt != 0 {
+		return 0, 0, nil
+	}
+
+	skippedExisting, err := a.GetGroupMentionCount(groupSyncable.GroupId, channel.Id)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	result := <-a.Srv.Store.Group().GetMembersNotInChannel(groupSyncable.GroupId, channel.Id)
+	if result.Err != nil {
+		return 0, 0, result.Err
+	}
+	userIDs := result.Data.([]string)
+
+	added := 0
+	for _, userID := range userIDs {
+		tmem, err := a.GetTeamMember(channel.TeamId, userID)
+		if err != nil && err.Id != "store.sql_team.get_member.missing.app_error" {
+			return added, int(skippedExisting), err
+		}
+
+		// First add user to team
+		if tmem == nil || tmem.DeleteAt != 0 {
+			if err = a.addGroupSyncedTeamMember(channel.TeamId, userID, groupSyncable.SuppressNotifications); err != nil {
+				return added, int(skippedExisting), err
+			}
+			a.Log.Info("added teammember",
+				mlog.String("user_id", userID),
+				mlog.String("team_id", channel.TeamId),
+			)
+		}
+
+		if _, err = a.addChannelMember(userID, channel, "", "", !groupSyncable.SuppressNotifications); err != nil {
+			return added, int(skippedExisting), err
+		}
+		added++
+
+		if err = a.addGroupSyncedChannelRoles(channel.Id, userID, strings.Fields(groupSyncable.Roles)); err != nil {
+			return added, int(skippedExisting), err
+		}
+
+		a.Log.Info("added channelmember",
+			mlog.String("user_id", userID),
+			mlog.String("channel_id", channel.Id),
+		)
+	}
+
+	return added, int(skippedExisting), nil
+}
+
+// addGroupSyncedChannelRoles gives a channel member the roles of the group links that brought them in, on top of the
+// roles they already have.
+func (a *App) addGroupSyncedChannelRoles(channelId, userId string, roles []string) *model.AppError {
+	if len(roles) == 0 {
+		return nil
+	}
+
+	member, err := a.GetChannelMember(channelId, userId)
+	if err != nil {
+		return err
+	}
+
+	newRoles := member.GetRoles()
+	for _, role := range roles {
+		if !utils.StringInSlice(role, newRoles) {
+			newRoles = append(newRoles, role)
+		}
+	}
+	if len(newRoles) == len(member.GetRoles()) {
+		return nil
+	}
+
+	_, err = a.UpdateChannelMemberRoles(channelId, userId, strings.Join(newRoles, " "))
+	return err
+}
+
+// addGroupSyncedTeamMember adds a user to a team on behalf of a group link. If suppressNotifications is set, no join
+// messages are posted in the team's default channels.
+func (a *App) addGroupSyncedTeamMember(teamId, userId string, suppressNotifications bool) *model.AppError {
+	if !suppressNotifications {
+		_, err := a.AddTeamMember(teamId, userId)
+		return err
+	}
+
+	team, err := a.GetTeam(teamId)
+	if err != nil {
+		return err
+	}
+
+	user, err := a.GetUser(userId)
+	if err != nil {
+		return err
+	}
+
+	return a.joinUserToTeamAndDefaultChannels(team, user, "", false)
+}
+
+// DeleteExpiredGroupMembers removes the group memberships whose expiry has passed, then removes the affected users
+// from the group-constrained channels that no other group of theirs still permits.
+func (a *App) DeleteExpiredGroupMembers() error {
+	expiredUserIDs := map[string]bool{}
+
+	for {
+		result := <-a.Srv.Store.Group().GetExpiredMembers(model.GetMillis(), GROUP_MEMBER_EXPIRY_BATCH_SIZE)
+		if result.Err != nil {
+			return result.Err
+		}
+		members := result.Data.([]*model.GroupMember)
+
+		userIDsByGroup := map[string][]string{}
+		for _, member := range members {
+			userIDsByGroup[member.GroupId] = append(userIDsByGroup[member.GroupId], member.UserId)
+			expiredUserIDs[member.UserId] = true
+		}
+
+		for groupID, userIDs := range userIDsByGroup {
+			if _, err := a.DeleteGroupMembers(groupID, userIDs); err != nil {
+				return err
+			}
+
+			a.Log.Info("removed expired groupmembers",
+				mlog.String("group_id", groupID),
+				mlog.Int("count", len(userIDs)),
+			)
+		}
+
+		if len(members) < GROUP_MEMBER_EXPIRY_BATCH_SIZE {
+			break
+		}
+	}
+
+	if len(expiredUserIDs) == 0 {
+		return nil
+	}
+
+	channelMembers, appErr := a.ChannelMembersToRemove()
+	if appErr != nil {
+		return appErr
+	}
+
+	for _, userChannel := range channelMembers {
+		if !expiredUserIDs[userChannel.UserId] {
+			continue
+		}
+
+		channel, err := a.GetChannel(userChannel.ChannelId)
+		if err != nil {
+			return err
+		}
+
+		if err = a.RemoveUserFromChannel(userChannel.UserId, "", channel); err != nil {
+			return err
+		}
+
+		a.Log.Info("removed channelmember",
+			mlog.String("user_id", userChannel.UserId),
+			mlog.String("channel_id", channel.Id),
+		)
+	}
+
+	return nil
+}
+
+// DeleteExpiredGroupSyncables unlinks groups from the teams and channels whose links have expired. The users who were
+// only permitted in a group-constrained team or channel by an expired link are then removed from it.
+func (a *App) DeleteExpiredGroupSyncables() error {
+	expiredSyncableIDs := map[string]bool{}
+
+	for _, syncableType := range []model.GroupSyncableType{model.GroupSyncableTypeTeam, model.GroupSyncableTypeChannel} {
+		for {
+			result := <-a.Srv.Store.Group().GetExpiredGroupSyncables(syncableType, model.GetMillis(), GROUP_SYNCABLE_EXPIRY_BATCH_SIZE)
+			if result.Err != nil {
+				return result.Err
+			}
+			groupSyncables := result.Data.([]*model.GroupSyncable)
+
+			for _, groupSyncable := range groupSyncables {
+				if _, err := a.DeleteGroupSyncable(groupSyncable.GroupId, groupSyncable.SyncableId, syncableType); err != nil {
+					return err
+				}
+				expiredSyncableIDs[groupSyncable.SyncableId] = true
+
+				a.Log.Info("removed expired groupsyncable",
+					mlog.String("group_id", groupSyncable.GroupId),
+					mlog.String("syncable_id", groupSyncable.SyncableId),
+					mlog.String("syncable_type", syncableType.String()),
+				)
+			}
+
+			if len(groupSyncables) < GROUP_SYNCABLE_EXPIRY_BATCH_SIZE {
+				break
+			}
+		}
+	}
+
+	if len(expiredSyncableIDs) == 0 {
+		return nil
+	}
+
+	channelMembers, appErr := a.ChannelMembersToRemove()
+	if appErr != nil {
+		return appErr
+	}
+
+	for _, userChannel := range channelMembers {
+		if !expiredSyncableIDs[userChannel.ChannelId] {
+			continue
+		}
+
+		channel, err := a.GetChannel(userChannel.ChannelId)
+		if err != nil {
+			return err
+		}
+
+		if err = a.RemoveUserFromChannel(userChannel.UserId, "", channel); err != nil {
+			return err
+		}
+
+		a.Log.Info("removed channelmember",
+			mlog.String("user_id", userChannel.UserId),
+			mlog.String("channel_id", channel.Id),
+		)
+	}
+
+	teamMembers, appErr := a.TeamMembersToRemove()
+	if appErr != nil {
+		return appErr
+	}
+
+	for _, userTeam := range teamMembers {
+		if !expiredSyncableIDs[userTeam.TeamId] {
+			continue
+		}
+
+		if err := a.RemoveUserFromTeam(userTeam.TeamId, userTeam.UserId, ""); err != nil {
+			return err
+		}
+
+		a.Log.Info("removed teammember",
+			mlog.String("user_id", userTeam.UserId),
+			mlog.String("team_id", userTeam.TeamId),
+		)
+	}
+
+	return nil
+}
+
 // DeleteGroupConstrainedMemberships deletes team and channel memberships of users who aren't members of the allowed
 // groups of all group-constrained teams and channels.
 func (a *App) DeleteGroupConstrainedMemberships() error {
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/mattermost/mattermost-server/model"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -393,3 +394,231 @@ func TestDeleteGroupMemberships(t *testing.T) {
 	require.Len(t, (*cmembers), 1)
 	require.Equal(t, th.SystemAdminUser.Id, (*cmembers)[0].UserId)
 }
+
+func TestDeleteGroupMembershipsExemptMember(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	group := th.CreateGroup()
+
+	for _, userID := range []string{th.BasicUser2.Id, th.SystemAdminUser.Id} {
+		_, err := th.App.AddTeamMember(th.BasicTeam.Id, userID)
+		require.Nil(t, err)
+
+		_, err = th.App.AddChannelMember(userID, th.BasicChannel, "", "")
+		require.Nil(t, err)
+	}
+
+	channel := th.BasicChannel
+	channel.GroupConstrained = model.NewBool(true)
+	channel, err := th.App.UpdateChannel(channel)
+	require.Nil(t, err)
+
+	_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(group.Id, channel.Id, true))
+	require.Nil(t, err)
+
+	_, err = th.App.CreateOrRestoreGroupMember(group.Id, th.SystemAdminUser.Id)
+	require.Nil(t, err)
+
+	// BasicUser2 belongs to no group but is exempt, BasicUser is neither
+	exemption, err := th.App.ExemptChannelMemberFromGroupConstraint(channel.Id, th.BasicUser2.Id)
+	require.Nil(t, err)
+	assert.NotZero(t, exemption.CreateAt)
+
+	// Exempting a member twice keeps the first exemption
+	again, err := th.App.ExemptChannelMemberFromGroupConstraint(channel.Id, th.BasicUser2.Id)
+	require.Nil(t, err)
+	assert.Equal(t, exemption.CreateAt, again.CreateAt)
+
+	require.Nil(t, th.App.DeleteGroupConstrainedMemberships())
+
+	cmembers, err := th.App.GetChannelMembersPage(channel.Id, 0, 99)
+	require.Nil(t, err)
+	var userIDs []string
+	for _, member := range *cmembers {
+		userIDs = append(userIDs, member.UserId)
+	}
+	assert.ElementsMatch(t, []string{th.BasicUser2.Id, th.SystemAdminUser.Id}, userIDs)
+
+	// Only members of the channel can be exempted
+	_, err = th.App.ExemptChannelMemberFromGroupConstraint(channel.Id, th.BasicUser.Id)
+	require.NotNil(t, err)
+}
+
+func TestDeleteExpiredGroupMembers(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	group := th.CreateGroup()
+
+	_, err := th.App.UpsertGroupMembers(group.Id, []string{th.BasicUser.Id, th.BasicUser2.Id})
+	require.Nil(t, err)
+
+	// make channel group-constrained
+	channel := th.BasicChannel
+	channel.GroupConstrained = model.NewBool(true)
+	channel, err = th.App.UpdateChannel(channel)
+	require.Nil(t, err)
+
+	_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(group.Id, channel.Id, true))
+	require.Nil(t, err)
+
+	_, err = th.App.AddChannelMember(th.BasicUser2.Id, channel, "", "")
+	require.Nil(t, err)
+
+	// only the second user's membership has expired
+	err = th.App.SetGroupMemberExpiries(group.Id, map[string]int64{
+		th.BasicUser.Id:  model.GetMillis() + 60*60*1000,
+		th.BasicUser2.Id: model.GetMillis() - 1000,
+	})
+	require.Nil(t, err)
+
+	require.Nil(t, th.App.DeleteExpiredGroupMembers())
+
+	members, err := th.App.GetGroupMembers(group.Id, []string{th.BasicUser.Id, th.BasicUser2.Id})
+	require.Nil(t, err)
+	require.Len(t, members, 1)
+	require.Equal(t, th.BasicUser.Id, members[0].UserId)
+
+	_, err = th.App.GetChannelMember(channel.Id, th.BasicUser2.Id)
+	require.NotNil(t, err)
+
+	_, err = th.App.GetChannelMember(channel.Id, th.BasicUser.Id)
+	require.Nil(t, err)
+}
+
+func TestDeleteExpiredGroupSyncables(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	expiringGroup := th.CreateGroup()
+	_, err := th.App.UpsertGroupMembers(expiringGroup.Id, []string{th.BasicUser2.Id})
+	require.Nil(t, err)
+
+	permanentGroup := th.CreateGroup()
+	_, err = th.App.UpsertGroupMembers(permanentGroup.Id, []string{th.BasicUser.Id})
+	require.Nil(t, err)
+
+	// make channel group-constrained
+	channel := th.BasicChannel
+	channel.GroupConstrained = model.NewBool(true)
+	channel, err = th.App.UpdateChannel(channel)
+	require.Nil(t, err)
+
+	expiringSyncable := model.NewGroupChannel(expiringGroup.Id, channel.Id, true)
+	expiringSyncable.ExpiresAt = model.GetMillis() + 60*60*1000
+	_, err = th.App.CreateGroupSyncable(expiringSyncable)
+	require.Nil(t, err)
+
+	_, err = th.App.CreateGroupSyncable(model.NewGroupChannel(permanentGroup.Id, channel.Id, true))
+	require.Nil(t, err)
+
+	_, err = th.App.AddChannelMember(th.BasicUser2.Id, channel, "", "")
+	require.Nil(t, err)
+
+	otherChannel := th.CreateChannel(th.BasicTeam)
+	otherSyncable := model.NewGroupChannel(expiringGroup.Id, otherChannel.Id, true)
+	otherSyncable.ExpiresAt = model.GetMillis() + 60*60*1000
+	_, err = th.App.CreateGroupSyncable(otherSyncable)
+	require.Nil(t, err)
+
+	// nothing has expired yet
+	require.Nil(t, th.App.DeleteExpiredGroupSyncables())
+
+	groupSyncable, err := th.App.GetGroupSyncable(expiringGroup.Id, channel.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, err)
+	require.Zero(t, groupSyncable.DeleteAt)
+
+	_, err = th.App.GetChannelMember(channel.Id, th.BasicUser2.Id)
+	require.Nil(t, err)
+
+	// only the link to the constrained channel has expired
+	groupSyncable.ExpiresAt = model.GetMillis() - 1000
+	_, err = th.App.UpdateGroupSyncable(groupSyncable)
+	require.Nil(t, err)
+
+	require.Nil(t, th.App.DeleteExpiredGroupSyncables())
+
+	groupSyncable, err = th.App.GetGroupSyncable(expiringGroup.Id, channel.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, err)
+	require.NotZero(t, groupSyncable.DeleteAt)
+
+	groupSyncable, err = th.App.GetGroupSyncable(expiringGroup.Id, otherChannel.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, err)
+	require.Zero(t, groupSyncable.DeleteAt)
+
+	_, err = th.App.GetChannelMember(channel.Id, th.BasicUser2.Id)
+	require.NotNil(t, err)
+
+	_, err = th.App.GetChannelMember(channel.Id, th.BasicUser.Id)
+	require.Nil(t, err)
+}
+
+func TestCreateDefaultMembershipsSuppressNotifications(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.ServiceSettings.ExperimentalEnableDefaultChannelLeaveJoinMessages = true
+	})
+
+	townSquare, err := th.App.GetChannelByName("town-square", th.BasicTeam.Id, false)
+	require.Nil(t, err)
+
+	joinPosts := func(channelId, userId string) int {
+		posts, err := th.App.GetPostsPage(channelId, 0, 1000)
+		require.Nil(t, err)
+
+		count := 0
+		for _, post := range posts.Posts {
+			if post.UserId == userId && (post.Type == model.POST_JOIN_CHANNEL || post.Type == model.POST_JOIN_TEAM) {
+				count++
+			}
+		}
+		return count
+	}
+
+	setup := func(suppressNotifications bool) (*model.Channel, *model.User) {
+		channel := th.CreateChannel(th.BasicTeam)
+		user := th.CreateUser()
+
+		group, err := th.App.CreateGroup(&model.Group{
+			Name:        model.NewId(),
+			DisplayName: model.NewId(),
+			RemoteId:    model.NewId(),
+			Source:      model.GroupSourceLdap,
+		})
+		require.Nil(t, err)
+
+		syncable := model.NewGroupChannel(group.Id, channel.Id, true)
+		syncable.SuppressNotifications = suppressNotifications
+		_, err = th.App.CreateGroupSyncable(syncable)
+		require.Nil(t, err)
+
+		_, err = th.App.CreateOrRestoreGroupMember(group.Id, user.Id)
+		require.Nil(t, err)
+
+		return channel, user
+	}
+
+	silentChannel, silentUser := setup(true)
+	noisyChannel, noisyUser := setup(false)
+
+	require.Nil(t, th.App.CreateDefaultMemberships(0))
+
+	for _, membership := range []struct {
+		channel *model.Channel
+		user    *model.User
+	}{{silentChannel, silentUser}, {noisyChannel, noisyUser}} {
+		_, err = th.App.GetTeamMember(th.BasicTeam.Id, membership.user.Id)
+		require.Nil(t, err)
+		_, err = th.App.GetChannelMember(membership.channel.Id, membership.user.Id)
+		require.Nil(t, err)
+	}
+
+	require.Zero(t, joinPosts(silentChannel.Id, silentUser.Id))
+	require.Zero(t, joinPosts(townSquare.Id, silentUser.Id))
+
+	require.NotZero(t, joinPosts(noisyChannel.Id, noisyUser.Id))
+	require.NotZero(t, joinPosts(townSquare.Id, noisyUser.Id))
+}
@@ -538,6 +538,12 @@ func (a *App) joinUserToTeam(team *model.Team, user *model.User) (*model.TeamMem
 }
 
 func (a *App) JoinUserToTeam(team *model.Team, user *model.User, userRequestorId string) *model.AppError {
+	return a.joinUserToTeamAndDefaultChannels(team, user, userRequestorId, true)
+}
+
+// joinUserToTeamAndDefaultChannels adds the user to the team and its default channels. Join messages are only posted
+// in the default channels if postJoinMessages is set.
+func (a *App) joinUserToTeamAndDefaultChannels(team *model.Team, user *model.User, userRequestorId string, postJoinMessages bool) *model.AppError {
 	if !a.isTeamEmailAllowed(user, team) {
 		return model.NewAppError("JoinUserToTeam", "api.team.join_user_to_team.allowed_domains.app_error", nil, "", http.StatusBadRequest)
 	}
@@ -571,7 +577,7 @@ func (a *App) JoinUserToTeam(team *model.Team, user *model.User, userRequestorId
 	shouldBeAdmin := team.Email == user.Email
 
 	// Soft error if there is an issue joining the default channels
-	if err := a.JoinDefaultChannels(team.Id, user, shouldBeAdmin, userRequestorId); err != nil {
+	if err := a.joinDefaultChannels(team.Id, user, shouldBeAdmin, userRequestorId, postJoinMessages); err != nil {
 		mlog.Error(fmt.Sprintf("Encountered an issue joining default channels err=%v", err), mlog.String("user_id", user.Id), mlog.String("team_id", team.Id))
 	}
 
@@ -51,6 +51,14 @@ func ldapSyncCmdF(command *cobra.Command, args []string) error {
 			CommandPrintErrorln("ERROR: AD/LDAP Synchronization please check the server logs")
 		} else {
 			CommandPrettyPrintln("SUCCESS: AD/LDAP Synchronization Complete")
+
+			if license := a.License(); license != nil && *license.Features.LDAPGroups {
+				if _, err := a.SyncLdapGroups(); err != nil {
+					CommandPrintErrorln("ERROR: AD/LDAP Group Synchronization failed! Error: " + err.Error())
+				} else {
+					CommandPrettyPrintln("SUCCESS: AD/LDAP Group Synchronization Complete")
+				}
+			}
 		}
 	}
 
@@ -43,6 +43,14 @@ func desanitize(actual, target *model.Config) {
 		*target.ElasticsearchSettings.Password = *actual.ElasticsearchSettings.Password
 	}
 
+	if *target.GroupSettings.SyncCompleteWebhookSecret == model.FAKE_SETTING {
+		*target.GroupSettings.SyncCompleteWebhookSecret = *actual.GroupSettings.SyncCompleteWebhookSecret
+	}
+
+	if *target.GroupSettings.MembershipWebhookSecret == model.FAKE_SETTING {
+		*target.GroupSettings.MembershipWebhookSecret = *actual.GroupSettings.MembershipWebhookSecret
+	}
+
 	target.SqlSettings.DataSourceReplicas = make([]string, len(actual.SqlSettings.DataSourceReplicas))
 	for i := range target.SqlSettings.DataSourceReplicas {
 		target.SqlSettings.DataSourceReplicas[i] = actual.SqlSettings.DataSourceReplicas[i]
@@ -19,6 +19,8 @@ type LdapInterface interface {
 	GetAllLdapUsers() ([]*model.User, *model.AppError)
 	MigrateIDAttribute(toAttribute string) error
 	GetGroup(groupUID string) (*model.Group, *model.AppError)
+	GetGroupMemberCount(groupUID string) (int, *model.AppError)
+	GetGroupMemberAuthData(groupUID string) ([]string, error)
 	GetAllGroupsPage(page int, perPage int, opts model.GroupSearchOpts) ([]*model.Group, int, *model.AppError)
 	FirstLoginSync(userID, userAuthService, userAuthData string) *model.AppError
 }
@@ -43,4 +43,9 @@ type MetricsInterface interface {
 
 	IncrementPostsSearchCounter()
 	ObservePostsSearchDuration(elapsed float64)
+
+	IncrementGroupLinkCounter(syncableType string)
+	IncrementGroupMemberUpsertCounter()
+	IncrementGroupReconcileCoalescedCounter()
+	ObserveGroupSyncDuration(elapsed float64)
 }
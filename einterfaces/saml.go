@@ -12,4 +12,7 @@ type SamlInterface interface {
 	BuildRequest(relayState string) (*model.SamlAuthRequest, *model.AppError)
 	DoLogin(encodedXML string, relayState map[string]string) (*model.User, *model.AppError)
 	GetMetadata() (string, *model.AppError)
+	// GetAssertionAttributes returns the attributes of the assertion in a SAML response accepted by DoLogin, keyed by
+	// attribute name.
+	GetAssertionAttributes(encodedXML string) (map[string][]string, *model.AppError)
 }
@@ -23,6 +23,8 @@ const (
 	HEADER_CLUSTER_ID         = "X-Cluster-ID"
 	HEADER_ETAG_SERVER        = "ETag"
 	HEADER_ETAG_CLIENT        = "If-None-Match"
+	HEADER_IF_MODIFIED_SINCE  = "If-Modified-Since"
+	HEADER_LAST_MODIFIED      = "Last-Modified"
 	HEADER_FORWARDED          = "X-Forwarded-For"
 	HEADER_REAL_IP            = "X-Real-IP"
 	HEADER_FORWARDED_PROTO    = "X-Forwarded-Proto"
@@ -32,6 +34,10 @@ const (
 	HEADER_AUTH               = "Authorization"
 	HEADER_REQUESTED_WITH     = "X-Requested-With"
 	HEADER_REQUESTED_WITH_XML = "XMLHttpRequest"
+	HEADER_IDEMPOTENCY_KEY    = "Idempotency-Key"
+	HEADER_WEBHOOK_SIGNATURE  = "X-Mattermost-Signature"
+	HEADER_PAGE               = "X-Page"
+	HEADER_PER_PAGE           = "X-Per-Page"
 	STATUS                    = "status"
 	STATUS_OK                 = "OK"
 	STATUS_FAIL               = "FAIL"
@@ -3297,6 +3303,61 @@ func (c *Client4) LinkLdapGroup(dn string) (*Group, *Response) {
 	return GroupFromJson(r.Body), BuildResponse(r)
 }
 
+// LinkLdapGroupsToTeam links each of the LDAP groups with the given remote ids to the team, returning the outcome
+// for each remote id.
+func (c *Client4) LinkLdapGroupsToTeam(teamId string, remoteIds []string, autoAdd bool) ([]*LdapGroupTeamLinkStatus, *Response) {
+	payload, _ := json.Marshal(LdapGroupsTeamLink{RemoteIds: remoteIds, AutoAdd: autoAdd})
+	r, appErr := c.DoApiPost(c.GetTeamRoute(teamId)+"/ldap_groups/link", string(payload))
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+
+	return LdapGroupTeamLinkStatusesFromJson(r.Body), BuildResponse(r)
+}
+
+// ValidateLdapGroup checks that the LDAP group with the given DN still exists and counts its members, without syncing
+// it.
+func (c *Client4) ValidateLdapGroup(dn string) (*LdapGroupValidation, *Response) {
+	path := fmt.Sprintf("%s/groups/%s/validate", c.GetLdapRoute(), dn)
+
+	r, appErr := c.DoApiPost(path, "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+
+	return LdapGroupValidationFromJson(r.Body), BuildResponse(r)
+}
+
+// GetStaleLdapGroups retrieves a page of the LDAP groups whose members have not been synced for the given number of
+// hours, oldest first.
+func (c *Client4) GetStaleLdapGroups(olderThanHours, page, perPage int) ([]*Group, *Response) {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(perPage))
+	query.Set("older_than_hours", strconv.Itoa(olderThanHours))
+
+	r, appErr := c.DoApiGet(c.GetLdapRoute()+"/groups/stale?"+query.Encode(), "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+
+	return GroupsFromJson(r.Body), BuildResponse(r)
+}
+
+// GroupSyncDryRun returns the totals a full LDAP group sync would change, without syncing.
+func (c *Client4) GroupSyncDryRun() (*GroupSyncDryRunResult, *Response) {
+	r, appErr := c.DoApiPost(c.GetLdapRoute()+"/groups/sync?dry_run=true", "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+
+	return GroupSyncDryRunResultFromJson(r.Body), BuildResponse(r)
+}
+
 // UnlinkLdapGroup deletes the Mattermost group associated with the given LDAP group DN.
 func (c *Client4) UnlinkLdapGroup(dn string) (*Group, *Response) {
 	path := fmt.Sprintf("%s/groups/%s/link", c.GetLdapRoute(), dn)
@@ -3312,7 +3373,16 @@ func (c *Client4) UnlinkLdapGroup(dn string) (*Group, *Response) {
 
 // GetLdapGroupsByChannel retrieves the Mattermost Groups associated with a given channel
 func (c *Client4) GetGroupsByChannel(channelId string, page, perPage int) ([]*Group, *Response) {
+	return c.GetGroupsByChannelWithOptions(channelId, page, perPage, GroupSearchOpts{})
+}
+
+// GetGroupsByChannelWithOptions retrieves the Mattermost Groups associated with a given channel, optionally including
+// the groups associated with the channel's team.
+func (c *Client4) GetGroupsByChannelWithOptions(channelId string, page, perPage int, opts GroupSearchOpts) ([]*Group, *Response) {
 	path := fmt.Sprintf("%s/groups?page=%v&per_page=%v", c.GetChannelRoute(channelId), page, perPage)
+	if opts.IncludeTeamGroups {
+		path += "&include_team_groups=true"
+	}
 	r, appErr := c.DoApiGet(path, "")
 	if appErr != nil {
 		return nil, BuildErrorResponse(r, appErr)
@@ -3322,9 +3392,43 @@ func (c *Client4) GetGroupsByChannel(channelId string, page, perPage int) ([]*Gr
 	return GroupsFromJson(r.Body), BuildResponse(r)
 }
 
+// GetChannelGroupsMissingTeamLink retrieves the groups linked to a channel but not to the channel's team.
+func (c *Client4) GetChannelGroupsMissingTeamLink(channelId string) ([]*Group, *Response) {
+	r, appErr := c.DoApiGet(c.GetChannelRoute(channelId)+"/groups/missing_team_link", "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+
+	return GroupsFromJson(r.Body), BuildResponse(r)
+}
+
+// GetChannelGroupMembershipConflicts returns the members of the channel who belong to a group excluded from it.
+func (c *Client4) GetChannelGroupMembershipConflicts(channelId string) ([]*GroupMembershipConflict, *Response) {
+	r, appErr := c.DoApiGet(c.GetChannelRoute(channelId)+"/groups/conflicts", "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+
+	return GroupMembershipConflictsFromJson(r.Body), BuildResponse(r)
+}
+
 // GetLdapGroupsByTeam retrieves the Mattermost Groups associated with a given team
 func (c *Client4) GetGroupsByTeam(teamId string, page, perPage int) ([]*Group, *Response) {
+	return c.GetGroupsByTeamWithOptions(teamId, page, perPage, GroupSearchOpts{})
+}
+
+// GetGroupsByTeamWithOptions retrieves the Mattermost Groups associated with a given team, filtered by the given
+// search options and optionally including the groups associated with the team's channels.
+func (c *Client4) GetGroupsByTeamWithOptions(teamId string, page, perPage int, opts GroupSearchOpts) ([]*Group, *Response) {
 	path := fmt.Sprintf("%s/groups?page=%v&per_page=%v", c.GetTeamRoute(teamId), page, perPage)
+	if opts.FilterAutoAdd {
+		path += "&filter_auto_add=true"
+	}
+	if opts.IncludeChannelGroups {
+		path += "&include_channel_groups=true"
+	}
 	r, appErr := c.DoApiGet(path, "")
 	if appErr != nil {
 		return nil, BuildErrorResponse(r, appErr)
@@ -3334,6 +3438,140 @@ func (c *Client4) GetGroupsByTeam(teamId string, page, perPage int) ([]*Group, *
 	return GroupsFromJson(r.Body), BuildResponse(r)
 }
 
+// GetGroups retrieves a page of Mattermost Groups matching the given search options.
+func (c *Client4) GetGroups(opts GroupSearchOpts, page, perPage int) ([]*Group, *Response) {
+	r, appErr := c.DoApiGet(c.GetGroupsRoute()+"?"+groupSearchQuery(opts, page, perPage).Encode(), "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+
+	return GroupsFromJson(r.Body), BuildResponse(r)
+}
+
+// GetGroupsModifiedSince returns a page of groups, or no groups and a 304 Not Modified response if none of the groups
+// matching opts changed after since, in milliseconds.
+func (c *Client4) GetGroupsModifiedSince(opts GroupSearchOpts, page, perPage int, since int64) ([]*Group, *Response) {
+	query := groupSearchQuery(opts, page, perPage)
+	query.Set("since", strconv.FormatInt(since, 10))
+
+	route := c.GetGroupsRoute() + "?" + query.Encode()
+	rq, err := http.NewRequest("GET", c.ApiUrl+route, nil)
+	if err != nil {
+		return nil, &Response{Error: NewAppError(route, "model.client.connecting.app_error", nil, err.Error(), http.StatusBadRequest)}
+	}
+	rq.Header.Set(HEADER_IF_MODIFIED_SINCE, time.Unix(0, since*int64(time.Millisecond)).UTC().Format(http.TimeFormat))
+
+	if len(c.AuthToken) > 0 {
+		rq.Header.Set(HEADER_AUTH, c.AuthType+" "+c.AuthToken)
+	}
+
+	rp, err := c.HttpClient.Do(rq)
+	if err != nil || rp == nil {
+		return nil, BuildErrorResponse(rp, NewAppError(route, "model.client.connecting.app_error", nil, err.Error(), 0))
+	}
+	defer closeBody(rp)
+
+	if rp.StatusCode == http.StatusNotModified {
+		return nil, BuildResponse(rp)
+	}
+
+	if rp.StatusCode >= 300 {
+		return nil, BuildErrorResponse(rp, AppErrorFromJson(rp.Body))
+	}
+
+	return GroupsFromJson(rp.Body), BuildResponse(rp)
+}
+
+func groupSearchQuery(opts GroupSearchOpts, page, perPage int) url.Values {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(perPage))
+	if len(opts.Q) > 0 {
+		query.Set("q", opts.Q)
+	}
+	if len(opts.NotAssociatedToTeam) > 0 {
+		query.Set("not_associated_to_team", opts.NotAssociatedToTeam)
+	}
+	if len(opts.NotAssociatedToChannel) > 0 {
+		query.Set("not_associated_to_channel", opts.NotAssociatedToChannel)
+	}
+	if opts.FilterParentTeamPermitted != nil {
+		query.Set("filter_parent_team_permitted", *opts.FilterParentTeamPermitted)
+	}
+	if len(opts.Tag) > 0 {
+		query.Set("tag", opts.Tag)
+	}
+	if opts.ManageableOnly {
+		query.Set("manageable_only", "true")
+	}
+	if opts.MemberOfMe {
+		query.Set("member_of_me", "true")
+	}
+	if opts.ExcludeDefault {
+		query.Set("exclude_default", "true")
+	}
+	if len(opts.Locale) > 0 {
+		query.Set("locale", opts.Locale)
+	}
+
+	return query
+}
+
+// GetGroupsByMemberEmailDomain returns a page of the groups with at least one active member whose email address is at
+// the given domain.
+func (c *Client4) GetGroupsByMemberEmailDomain(domain string, page, perPage int) ([]*Group, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	r, appErr := c.DoApiGet(c.GetGroupsRoute()+"/by_member_email_domain/"+domain+query, "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+
+	return GroupsFromJson(r.Body), BuildResponse(r)
+}
+
+// GetUnusedGroups returns a page of the groups that have not been linked to a team or channel or had member changes
+// in the last olderThanDays days. An empty source lists groups from every source.
+func (c *Client4) GetUnusedGroups(source GroupSource, olderThanDays, page, perPage int) ([]*Group, *Response) {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(perPage))
+	query.Set("older_than_days", strconv.Itoa(olderThanDays))
+	if len(source) > 0 {
+		query.Set("source", string(source))
+	}
+
+	r, appErr := c.DoApiGet(c.GetGroupsRoute()+"/unused?"+query.Encode(), "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+
+	return GroupsFromJson(r.Body), BuildResponse(r)
+}
+
+// AutocompleteGroups returns the referenceable groups whose name starts with name. Exactly one of teamId and
+// channelId should be set to the team or channel the autocomplete is shown in.
+func (c *Client4) AutocompleteGroups(teamId, channelId, name string) ([]*Group, *Response) {
+	query := url.Values{}
+	query.Set("name", name)
+	if len(teamId) > 0 {
+		query.Set("team_id", teamId)
+	}
+	if len(channelId) > 0 {
+		query.Set("channel_id", channelId)
+	}
+
+	r, appErr := c.DoApiGet(c.GetGroupsRoute()+"/autocomplete?"+query.Encode(), "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+
+	return GroupsFromJson(r.Body), BuildResponse(r)
+}
+
 // Audits Section
 
 // GetAudits returns a list of audits for the whole system.
@@ -4299,6 +4537,17 @@ func (c *Client4) CreateTermsOfService(text, userId string) (*TermsOfService, *R
 	return TermsOfServiceFromJson(r.Body), BuildResponse(r)
 }
 
+// CreateGroup creates a custom group.
+func (c *Client4) CreateGroup(group *Group) (*Group, *Response) {
+	payload, _ := json.Marshal(group)
+	r, appErr := c.DoApiPost(c.GetGroupsRoute(), string(payload))
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupFromJson(r.Body), BuildResponse(r)
+}
+
 func (c *Client4) GetGroup(groupID, etag string) (*Group, *Response) {
 	r, appErr := c.DoApiGet(c.GetGroupRoute(groupID), etag)
 	if appErr != nil {
@@ -4308,6 +4557,26 @@ func (c *Client4) GetGroup(groupID, etag string) (*Group, *Response) {
 	return GroupFromJson(r.Body), BuildResponse(r)
 }
 
+// GetGroupWithSyncables retrieves a group along with the teams and channels it is linked to.
+func (c *Client4) GetGroupWithSyncables(groupID, etag string) (*GroupWithSyncables, *Response) {
+	r, appErr := c.DoApiGet(c.GetGroupRoute(groupID)+"?include_syncables=true", etag)
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupWithSyncablesFromJson(r.Body), BuildResponse(r)
+}
+
+// GetGroupWithMemberIds retrieves a group along with the ids of its members, capped at GroupMemberIdsInlineLimit.
+func (c *Client4) GetGroupWithMemberIds(groupID, etag string) (*Group, *Response) {
+	r, appErr := c.DoApiGet(c.GetGroupRoute(groupID)+"?include_member_ids=true", etag)
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupFromJson(r.Body), BuildResponse(r)
+}
+
 func (c *Client4) PatchGroup(groupID string, patch *GroupPatch) (*Group, *Response) {
 	payload, _ := json.Marshal(patch)
 	r, appErr := c.DoApiPut(c.GetGroupRoute(groupID)+"/patch", string(payload))
@@ -4318,6 +4587,16 @@ func (c *Client4) PatchGroup(groupID string, patch *GroupPatch) (*Group, *Respon
 	return GroupFromJson(r.Body), BuildResponse(r)
 }
 
+// RebindGroupRemoteId points an LDAP group at another directory object, keeping its members and syncables.
+func (c *Client4) RebindGroupRemoteId(groupID, remoteID string) (*Group, *Response) {
+	r, appErr := c.DoApiPut(c.GetGroupRoute(groupID)+"/remote_id", MapToJson(map[string]string{"remote_id": remoteID}))
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupFromJson(r.Body), BuildResponse(r)
+}
+
 func (c *Client4) LinkGroupSyncable(groupID, syncableID string, syncableType GroupSyncableType, patch *GroupSyncablePatch) (*GroupSyncable, *Response) {
 	payload, _ := json.Marshal(patch)
 	url := fmt.Sprintf("%s/link", c.GetGroupSyncableRoute(groupID, syncableID, syncableType))
@@ -4329,6 +4608,18 @@ func (c *Client4) LinkGroupSyncable(groupID, syncableID string, syncableType Gro
 	return GroupSyncableFromJson(r.Body), BuildResponse(r)
 }
 
+// LinkGroupChannelByName links a group to the channel with the given name in a team.
+func (c *Client4) LinkGroupChannelByName(groupID, teamId, channelName string, patch *GroupSyncablePatch) (*GroupSyncable, *Response) {
+	payload, _ := json.Marshal(patch)
+	url := fmt.Sprintf("%s/groups/%s/link", c.GetChannelByNameRoute(channelName, teamId), groupID)
+	r, appErr := c.DoApiPost(url, string(payload))
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupSyncableFromJson(r.Body), BuildResponse(r)
+}
+
 func (c *Client4) UnlinkGroupSyncable(groupID, syncableID string, syncableType GroupSyncableType) *Response {
 	url := fmt.Sprintf("%s/link", c.GetGroupSyncableRoute(groupID, syncableID, syncableType))
 	r, appErr := c.DoApiDelete(url)
@@ -4339,6 +4630,292 @@ func (c *Client4) UnlinkGroupSyncable(groupID, syncableID string, syncableType G
 	return BuildResponse(r)
 }
 
+// UnlinkGroupChannels unlinks a group from several channels at once, returning the outcome for each channel.
+func (c *Client4) UnlinkGroupChannels(groupID string, channelIDs []string) ([]*GroupSyncableStatus, *Response) {
+	payload, _ := json.Marshal(map[string][]string{"channel_ids": channelIDs})
+	r, appErr := c.doApiRequestBytes(http.MethodDelete, c.ApiUrl+c.GetGroupRoute(groupID)+"/channels/link", payload, "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupSyncableStatusesFromJson(r.Body), BuildResponse(r)
+}
+
+// PromoteChannelGroupsToTeam links each group linked to the channel to the channel's team too, optionally removing
+// the channel links.
+func (c *Client4) PromoteChannelGroupsToTeam(channelID string, removeChannelLinks bool) (*GroupPromotionResult, *Response) {
+	r, appErr := c.DoApiPost(c.GetChannelRoute(channelID)+"/groups/promote_to_team?remove_channel_links="+strconv.FormatBool(removeChannelLinks), "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupPromotionResultFromJson(r.Body), BuildResponse(r)
+}
+
+// UpsertGroupMembers adds users to a custom group, returning which of them were added and which were already members.
+func (c *Client4) UpsertGroupMembers(groupID string, userIDs []string) (*GroupMembersUpsertResult, *Response) {
+	payload, _ := json.Marshal(map[string][]string{"user_ids": userIDs})
+	r, appErr := c.DoApiPost(c.GetGroupRoute(groupID)+"/members", string(payload))
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupMembersUpsertResultFromJson(r.Body), BuildResponse(r)
+}
+
+// UpsertGroupMembersWithExpiry adds users to a custom group like UpsertGroupMembers, removing each user whose id is a
+// key of expiresAt from the group again once that time has passed.
+func (c *Client4) UpsertGroupMembersWithExpiry(groupID string, userIDs []string, expiresAt map[string]int64) (*GroupMembersUpsertResult, *Response) {
+	payload, _ := json.Marshal(map[string]interface{}{"user_ids": userIDs, "expires_at": expiresAt})
+	r, appErr := c.DoApiPost(c.GetGroupRoute(groupID)+"/members", string(payload))
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupMembersUpsertResultFromJson(r.Body), BuildResponse(r)
+}
+
+// CheckGroupMembers splits the given users into the members of the group and everyone else.
+func (c *Client4) CheckGroupMembers(groupID string, userIDs []string) (*GroupMembershipCheckResult, *Response) {
+	payload, _ := json.Marshal(map[string][]string{"user_ids": userIDs})
+	r, appErr := c.DoApiPost(c.GetGroupRoute(groupID)+"/members/check", string(payload))
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupMembershipCheckResultFromJson(r.Body), BuildResponse(r)
+}
+
+// GetGroupMemberHistory returns a page of the membership events of a group, oldest first. A zero to includes
+// everything up to now.
+func (c *Client4) GetGroupMemberHistory(groupID string, from, to int64, page, perPage int) ([]*GroupMemberHistoryEvent, *Response) {
+	query := url.Values{}
+	query.Set("from", strconv.FormatInt(from, 10))
+	if to > 0 {
+		query.Set("to", strconv.FormatInt(to, 10))
+	}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(perPage))
+
+	r, appErr := c.DoApiGet(c.GetGroupRoute(groupID)+"/members/history?"+query.Encode(), "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupMemberHistoryEventsFromJson(r.Body), BuildResponse(r)
+}
+
+// GetGroupMemberTimezones retrieves the number of members of a group in each timezone. Members without a timezone
+// are counted under GroupMemberTimezoneUnknown.
+func (c *Client4) GetGroupMemberTimezones(groupID string) (map[string]int, *Response) {
+	r, appErr := c.DoApiGet(c.GetGroupRoute(groupID)+"/members/timezones", "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	var distribution map[string]int
+	json.NewDecoder(r.Body).Decode(&distribution)
+	return distribution, BuildResponse(r)
+}
+
+// GetGroupMentionCount retrieves the number of users a mention of the group in the channel would notify.
+func (c *Client4) GetGroupMentionCount(channelID, groupID string) (*GroupMentionCount, *Response) {
+	r, appErr := c.DoApiGet(c.GetChannelRoute(channelID)+"/groups/"+groupID+"/mention_count", "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupMentionCountFromJson(r.Body), BuildResponse(r)
+}
+
+// GetChannelGroupMembers retrieves a page of the members of a group linked to the channel, along with the group's
+// total member count.
+func (c *Client4) GetChannelGroupMembers(channelID, groupID string, page, perPage int) ([]*User, int, *Response) {
+	r, appErr := c.DoApiGet(fmt.Sprintf("%s/groups/%s/members?page=%v&per_page=%v", c.GetChannelRoute(channelID), groupID, page, perPage), "")
+	if appErr != nil {
+		return nil, 0, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+
+	var members struct {
+		Members []*User `json:"members"`
+		Count   int     `json:"total_member_count"`
+	}
+	json.NewDecoder(r.Body).Decode(&members)
+	return members.Members, members.Count, BuildResponse(r)
+}
+
+// TransferGroupMemberships gives the custom group memberships of one user to another, returning the ids of the groups
+// that were changed.
+func (c *Client4) TransferGroupMemberships(transfer *GroupMembershipTransfer) ([]string, *Response) {
+	payload, _ := json.Marshal(transfer)
+	r, appErr := c.DoApiPost(c.GetGroupsRoute()+"/members/transfer", string(payload))
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return ArrayFromJson(r.Body), BuildResponse(r)
+}
+
+// GetUnionGroupMembers retrieves a page of the distinct users who belong to any of the given groups, along with the
+// total number of such users.
+func (c *Client4) GetUnionGroupMembers(groupIDs []string, page, perPage int) ([]*User, int, *Response) {
+	payload, _ := json.Marshal(map[string][]string{"group_ids": groupIDs})
+	r, appErr := c.DoApiPost(fmt.Sprintf("%s/members/union?page=%v&per_page=%v", c.GetGroupsRoute(), page, perPage), string(payload))
+	if appErr != nil {
+		return nil, 0, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+
+	var union struct {
+		Members []*User `json:"members"`
+		Count   int     `json:"total_member_count"`
+	}
+	json.NewDecoder(r.Body).Decode(&union)
+	return union.Members, union.Count, BuildResponse(r)
+}
+
+// SendGroupMessage sends a direct message to each member of the group, returning the job that sends them.
+func (c *Client4) SendGroupMessage(groupID, message string) (*Job, *Response) {
+	payload, _ := json.Marshal(&GroupMessage{Message: message})
+	r, appErr := c.DoApiPost(c.GetGroupRoute(groupID)+"/message", string(payload))
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return JobFromJson(r.Body), BuildResponse(r)
+}
+
+// GetIntersectionGroupMembers retrieves a page of the users who belong to every one of the given groups, along with the
+// total number of such users.
+func (c *Client4) GetIntersectionGroupMembers(groupIDs []string, page, perPage int) ([]*User, int, *Response) {
+	payload, _ := json.Marshal(map[string][]string{"group_ids": groupIDs})
+	r, appErr := c.DoApiPost(fmt.Sprintf("%s/members/intersection?page=%v&per_page=%v", c.GetGroupsRoute(), page, perPage), string(payload))
+	if appErr != nil {
+		return nil, 0, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+
+	var intersection struct {
+		Members []*User `json:"members"`
+		Count   int     `json:"total_member_count"`
+	}
+	json.NewDecoder(r.Body).Decode(&intersection)
+	return intersection.Members, intersection.Count, BuildResponse(r)
+}
+
+// GetUngroupedUsers retrieves a page of the active users who are not members of any group, along with the total
+// number of such users.
+func (c *Client4) GetUngroupedUsers(page, perPage int) ([]*User, int, *Response) {
+	r, appErr := c.DoApiGet(fmt.Sprintf("%s/users/ungrouped?page=%v&per_page=%v", c.GetGroupsRoute(), page, perPage), "")
+	if appErr != nil {
+		return nil, 0, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+
+	var ungrouped struct {
+		Users []*User `json:"users"`
+		Count int     `json:"total_count"`
+	}
+	json.NewDecoder(r.Body).Decode(&ungrouped)
+	return ungrouped.Users, ungrouped.Count, BuildResponse(r)
+}
+
+// DeleteGroupMembers removes users from a custom group, returning the memberships that were removed.
+func (c *Client4) DeleteGroupMembers(groupID string, userIDs []string) ([]*GroupMember, *Response) {
+	payload, _ := json.Marshal(map[string][]string{"user_ids": userIDs})
+	r, appErr := c.doApiRequestBytes(http.MethodDelete, c.ApiUrl+c.GetGroupRoute(groupID)+"/members", payload, "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupMembersFromJson(r.Body), BuildResponse(r)
+}
+
+// ClearGroupMembers removes every member of a custom group. force is required when the group is linked to
+// group-constrained teams or channels.
+func (c *Client4) ClearGroupMembers(groupID string, force bool) (*GroupMembersClearResult, *Response) {
+	r, appErr := c.DoApiDelete(c.GetGroupRoute(groupID) + "/members/all?force=" + strconv.FormatBool(force))
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupMembersClearResultFromJson(r.Body), BuildResponse(r)
+}
+
+// PatchGroupMember changes the in-group roles of a member of a group.
+func (c *Client4) PatchGroupMember(groupID, userID string, patch *GroupMemberPatch) (*GroupMember, *Response) {
+	payload, _ := json.Marshal(patch)
+	r, appErr := c.DoApiPut(c.GetGroupRoute(groupID)+"/members/"+userID, string(payload))
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupMemberFromJson(r.Body), BuildResponse(r)
+}
+
+// GetGroupDeleteImpact reports how many group-constrained teams and channels and how many of their members rely on a
+// group.
+func (c *Client4) GetGroupDeleteImpact(groupID string) (*GroupDeleteImpact, *Response) {
+	r, appErr := c.DoApiGet(c.GetGroupRoute(groupID)+"/delete_impact", "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupDeleteImpactFromJson(r.Body), BuildResponse(r)
+}
+
+// GetGroupStats counts the members of a group. With includeDeleted, the members that were removed are counted too.
+func (c *Client4) GetGroupStats(groupID string, includeDeleted bool) (*GroupStats, *Response) {
+	r, appErr := c.DoApiGet(c.GetGroupRoute(groupID)+"/stats?include_deleted="+strconv.FormatBool(includeDeleted), "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupStatsFromJson(r.Body), BuildResponse(r)
+}
+
+// GetGroupChannelAccess reports whether a group grants a user membership and admin rights in a channel.
+func (c *Client4) GetGroupChannelAccess(userID, groupID, channelID string) (*GroupChannelAccess, *Response) {
+	r, appErr := c.DoApiGet(c.GetUserRoute(userID)+"/groups/"+groupID+"/channels/"+channelID+"/permissions", "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupChannelAccessFromJson(r.Body), BuildResponse(r)
+}
+
+// ExemptChannelMemberFromGroupConstraint keeps the member of the channel from being removed when the channel is
+// group-constrained and they belong to none of its groups.
+func (c *Client4) ExemptChannelMemberFromGroupConstraint(channelID, userID string) (*GroupChannelExemption, *Response) {
+	r, appErr := c.DoApiPost(c.GetChannelMemberRoute(channelID, userID)+"/group_exemption", "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupChannelExemptionFromJson(r.Body), BuildResponse(r)
+}
+
+// GetUserGroupProvisionedAccess lists the teams and channels the user is given by the auto-add links of their groups.
+func (c *Client4) GetUserGroupProvisionedAccess(userID string) (*GroupProvisionedAccess, *Response) {
+	r, appErr := c.DoApiGet(c.GetUserRoute(userID)+"/groups/provisioned_access", "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupProvisionedAccessFromJson(r.Body), BuildResponse(r)
+}
+
+// MergeGroups merges the source custom group into the target custom group and deletes the source group.
+func (c *Client4) MergeGroups(targetGroupID, sourceGroupID string) (*GroupMergeResult, *Response) {
+	r, appErr := c.DoApiPost(c.GetGroupRoute(targetGroupID)+"/merge/"+sourceGroupID, "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupMergeResultFromJson(r.Body), BuildResponse(r)
+}
+
 func (c *Client4) GetGroupSyncable(groupID, syncableID string, syncableType GroupSyncableType, etag string) (*GroupSyncable, *Response) {
 	r, appErr := c.DoApiGet(c.GetGroupSyncableRoute(groupID, syncableID, syncableType), etag)
 	if appErr != nil {
@@ -4348,6 +4925,26 @@ func (c *Client4) GetGroupSyncable(groupID, syncableID string, syncableType Grou
 	return GroupSyncableFromJson(r.Body), BuildResponse(r)
 }
 
+// GetGroupTeamByName returns the link between a group and the team with the given name.
+func (c *Client4) GetGroupTeamByName(groupID, teamName string) (*GroupSyncable, *Response) {
+	r, appErr := c.DoApiGet(c.GetGroupRoute(groupID)+"/teams/name/"+teamName, "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupSyncableFromJson(r.Body), BuildResponse(r)
+}
+
+// GetGroupChannelByName returns the link between a group and the channel with the given name in the named team.
+func (c *Client4) GetGroupChannelByName(groupID, teamName, channelName string) (*GroupSyncable, *Response) {
+	r, appErr := c.DoApiGet(c.GetGroupRoute(groupID)+"/teams/name/"+teamName+"/channels/name/"+channelName, "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupSyncableFromJson(r.Body), BuildResponse(r)
+}
+
 func (c *Client4) GetGroupSyncables(groupID string, syncableType GroupSyncableType, etag string) ([]*GroupSyncable, *Response) {
 	r, appErr := c.DoApiGet(c.GetGroupSyncablesRoute(groupID, syncableType), etag)
 	if appErr != nil {
@@ -4357,6 +4954,122 @@ func (c *Client4) GetGroupSyncables(groupID string, syncableType GroupSyncableTy
 	return GroupSyncablesFromJson(r.Body), BuildResponse(r)
 }
 
+// GetGroupSyncablesExpanded retrieves the links of a group to teams or channels along with the display name, name and,
+// for channels, type and team of each team or channel.
+func (c *Client4) GetGroupSyncablesExpanded(groupID string, syncableType GroupSyncableType) ([]*GroupSyncableExpanded, *Response) {
+	r, appErr := c.DoApiGet(c.GetGroupSyncablesRoute(groupID, syncableType)+"?expand=true", "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupSyncablesExpandedFromJson(r.Body), BuildResponse(r)
+}
+
+// GetExpiringGroupSyncables retrieves the links of a group to teams or channels that expire before the given time.
+func (c *Client4) GetExpiringGroupSyncables(groupID string, syncableType GroupSyncableType, before int64) ([]*GroupSyncable, *Response) {
+	r, appErr := c.DoApiGet(c.GetGroupSyncablesRoute(groupID, syncableType)+"?expiring_before="+strconv.FormatInt(before, 10), "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupSyncablesFromJson(r.Body), BuildResponse(r)
+}
+
+// CreateGroupChannelPattern links a group to every channel whose name matches the pattern, including channels created
+// later.
+func (c *Client4) CreateGroupChannelPattern(groupID string, pattern *GroupChannelPattern) (*GroupChannelPattern, *Response) {
+	payload, _ := json.Marshal(pattern)
+	r, appErr := c.DoApiPost(c.GetGroupRoute(groupID)+"/channel_patterns", string(payload))
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupChannelPatternFromJson(r.Body), BuildResponse(r)
+}
+
+// GetGroupChannelPatterns retrieves the channel name patterns of a group.
+func (c *Client4) GetGroupChannelPatterns(groupID string) ([]*GroupChannelPattern, *Response) {
+	r, appErr := c.DoApiGet(c.GetGroupRoute(groupID)+"/channel_patterns", "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupChannelPatternsFromJson(r.Body), BuildResponse(r)
+}
+
+// GetGroupsByIds retrieves the groups with the given ids in one request. Ids of unknown groups, or of groups the user
+// may not see, are returned in NotFound.
+func (c *Client4) GetGroupsByIds(groupIDs []string) (*GroupsByIdsResult, *Response) {
+	payload, _ := json.Marshal(map[string][]string{"group_ids": groupIDs})
+	r, appErr := c.DoApiPost(c.GetGroupsRoute()+"/ids", string(payload))
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupsByIdsResultFromJson(r.Body), BuildResponse(r)
+}
+
+// GetGroupSyncablesReport returns, for each of the given groups linked to any of the given teams or channels, the ids
+// of those it is linked to.
+func (c *Client4) GetGroupSyncablesReport(groupIDs, syncableIDs []string, syncableType GroupSyncableType) (map[string][]string, *Response) {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"group_ids":     groupIDs,
+		"syncable_ids":  syncableIDs,
+		"syncable_type": strings.ToLower(syncableType.String()) + "s",
+	})
+	r, appErr := c.DoApiPost(c.GetGroupsRoute()+"/syncables/report", string(payload))
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+
+	var linked map[string][]string
+	json.NewDecoder(r.Body).Decode(&linked)
+	return linked, BuildResponse(r)
+}
+
+// GetGroupAdminSyncables retrieves a page of the teams and channels where a group grants admin rights.
+func (c *Client4) GetGroupAdminSyncables(groupID string, page, perPage int) ([]*GroupSyncable, *Response) {
+	r, appErr := c.DoApiGet(fmt.Sprintf("%s/admin_syncables?page=%v&per_page=%v", c.GetGroupRoute(groupID), page, perPage), "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupSyncablesFromJson(r.Body), BuildResponse(r)
+}
+
+// GetTeamGroupSyncables retrieves a page of the links of all groups to a team.
+func (c *Client4) GetTeamGroupSyncables(teamID string, page, perPage int) ([]*GroupSyncable, *Response) {
+	r, appErr := c.DoApiGet(fmt.Sprintf("%s/group_syncables?page=%v&per_page=%v", c.GetTeamRoute(teamID), page, perPage), "")
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupSyncablesFromJson(r.Body), BuildResponse(r)
+}
+
+// GetGroupChannelsIncludingArchived retrieves the channels a group is linked to, including archived channels.
+func (c *Client4) GetGroupChannelsIncludingArchived(groupID, etag string) ([]*GroupSyncable, *Response) {
+	r, appErr := c.DoApiGet(c.GetGroupSyncablesRoute(groupID, GroupSyncableTypeChannel)+"?include_archived_channels=true", etag)
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupSyncablesFromJson(r.Body), BuildResponse(r)
+}
+
+// UpdateGroupSyncable replaces the settings of an existing link between a group and a team or channel. Unlike
+// LinkGroupSyncable it fails with a 404 rather than creating the link if it does not exist.
+func (c *Client4) UpdateGroupSyncable(groupID, syncableID string, syncableType GroupSyncableType, groupSyncable *GroupSyncable) (*GroupSyncable, *Response) {
+	payload, _ := json.Marshal(groupSyncable)
+	r, appErr := c.DoApiPut(c.GetGroupSyncableRoute(groupID, syncableID, syncableType), string(payload))
+	if appErr != nil {
+		return nil, BuildErrorResponse(r, appErr)
+	}
+	defer closeBody(r)
+	return GroupSyncableFromJson(r.Body), BuildResponse(r)
+}
+
 func (c *Client4) PatchGroupSyncable(groupID, syncableID string, syncableType GroupSyncableType, patch *GroupSyncablePatch) (*GroupSyncable, *Response) {
 	payload, _ := json.Marshal(patch)
 	r, appErr := c.DoApiPut(c.GetGroupSyncableRoute(groupID, syncableID, syncableType)+"/patch", string(payload))
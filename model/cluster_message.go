@@ -25,6 +25,8 @@ const (
 	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_ROLES                        = "inv_roles"
 	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_SCHEMES                      = "inv_schemes"
 	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_GROUPS                       = "inv_groups"
+	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_GROUP_MEMBERSHIPS            = "inv_group_memberships"
+	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_GROUP_SEARCH                 = "inv_group_search"
 
 	CLUSTER_SEND_BEST_EFFORT = "best_effort"
 	CLUSTER_SEND_RELIABLE    = "reliable"
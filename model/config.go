@@ -128,6 +128,13 @@ const (
 	LDAP_SETTINGS_DEFAULT_GROUP_DISPLAY_NAME_ATTRIBUTE = ""
 	LDAP_SETTINGS_DEFAULT_GROUP_ID_ATTRIBUTE           = ""
 
+	GROUP_SETTINGS_DEFAULT_MAX_MEMBERS_PER_REQUEST = 1000
+	GROUP_SETTINGS_DEFAULT_SYNC_CONCURRENCY        = 2
+	GROUP_SETTINGS_DEFAULT_MAX_MENTION_MEMBERS     = 0
+	GROUP_SETTINGS_DEFAULT_RECONCILE_DEBOUNCE_SEC  = 0
+	GROUP_SETTINGS_DEFAULT_MAX_MESSAGE_FANOUT      = 1000
+	GROUP_SETTINGS_DEFAULT_SYNC_LOCK_STALE_SEC     = 10 * 60
+
 	SAML_SETTINGS_DEFAULT_ID_ATTRIBUTE         = ""
 	SAML_SETTINGS_DEFAULT_FIRST_NAME_ATTRIBUTE = ""
 	SAML_SETTINGS_DEFAULT_LAST_NAME_ATTRIBUTE  = ""
@@ -136,6 +143,7 @@ const (
 	SAML_SETTINGS_DEFAULT_NICKNAME_ATTRIBUTE   = ""
 	SAML_SETTINGS_DEFAULT_LOCALE_ATTRIBUTE     = ""
 	SAML_SETTINGS_DEFAULT_POSITION_ATTRIBUTE   = ""
+	SAML_SETTINGS_DEFAULT_GROUP_ATTRIBUTE      = ""
 
 	NATIVEAPP_SETTINGS_DEFAULT_APP_DOWNLOAD_LINK         = "https://about.mattermost.com/downloads/"
 	NATIVEAPP_SETTINGS_DEFAULT_ANDROID_APP_DOWNLOAD_LINK = "https://about.mattermost.com/mattermost-android-app/"
@@ -1786,6 +1794,9 @@ type SamlSettings struct {
 	LocaleAttribute    *string
 	PositionAttribute  *string
 
+	// Group Mapping
+	GroupAttribute *string
+
 	LoginButtonText *string
 
 	LoginButtonColor       *string
@@ -1882,6 +1893,10 @@ func (s *SamlSettings) SetDefaults() {
 		s.LocaleAttribute = NewString(SAML_SETTINGS_DEFAULT_LOCALE_ATTRIBUTE)
 	}
 
+	if s.GroupAttribute == nil {
+		s.GroupAttribute = NewString(SAML_SETTINGS_DEFAULT_GROUP_ATTRIBUTE)
+	}
+
 	if s.LoginButtonColor == nil {
 		s.LoginButtonColor = NewString("#34a28b")
 	}
@@ -2230,6 +2245,84 @@ func (ips *ImageProxySettings) SetDefaults(ss ServiceSettings) {
 
 type ConfigFunc func() *Config
 
+type GroupSettings struct {
+	SyncCompleteWebhookURL    *string
+	SyncCompleteWebhookSecret *string
+	MembershipWebhookSecret   *string
+	MaxMembersPerRequest      *int
+	SyncConcurrency           *int
+	MaxMentionMembers         *int
+	ReconcileDebounceSeconds  *int
+	MaxMessageFanout          *int
+	SyncLockStaleSeconds      *int
+}
+
+func (s *GroupSettings) SetDefaults() {
+	if s.SyncCompleteWebhookURL == nil {
+		s.SyncCompleteWebhookURL = NewString("")
+	}
+
+	if s.SyncCompleteWebhookSecret == nil {
+		s.SyncCompleteWebhookSecret = NewString("")
+	}
+
+	if s.MembershipWebhookSecret == nil {
+		s.MembershipWebhookSecret = NewString("")
+	}
+
+	if s.MaxMembersPerRequest == nil {
+		s.MaxMembersPerRequest = NewInt(GROUP_SETTINGS_DEFAULT_MAX_MEMBERS_PER_REQUEST)
+	}
+
+	if s.SyncConcurrency == nil {
+		s.SyncConcurrency = NewInt(GROUP_SETTINGS_DEFAULT_SYNC_CONCURRENCY)
+	}
+
+	if s.MaxMentionMembers == nil {
+		s.MaxMentionMembers = NewInt(GROUP_SETTINGS_DEFAULT_MAX_MENTION_MEMBERS)
+	}
+
+	if s.ReconcileDebounceSeconds == nil {
+		s.ReconcileDebounceSeconds = NewInt(GROUP_SETTINGS_DEFAULT_RECONCILE_DEBOUNCE_SEC)
+	}
+
+	if s.MaxMessageFanout == nil {
+		s.MaxMessageFanout = NewInt(GROUP_SETTINGS_DEFAULT_MAX_MESSAGE_FANOUT)
+	}
+
+	if s.SyncLockStaleSeconds == nil {
+		s.SyncLockStaleSeconds = NewInt(GROUP_SETTINGS_DEFAULT_SYNC_LOCK_STALE_SEC)
+	}
+}
+
+func (s *GroupSettings) isValid() *AppError {
+	if *s.MaxMembersPerRequest <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.group_max_members_per_request.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *s.SyncConcurrency <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.group_sync_concurrency.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *s.MaxMentionMembers < 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.group_max_mention_members.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *s.ReconcileDebounceSeconds < 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.group_reconcile_debounce_seconds.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *s.MaxMessageFanout <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.group_max_message_fanout.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *s.SyncLockStaleSeconds <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.group_sync_lock_stale_seconds.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
 type Config struct {
 	ServiceSettings       ServiceSettings
 	TeamSettings          TeamSettings
@@ -2263,6 +2356,7 @@ type Config struct {
 	PluginSettings        PluginSettings
 	DisplaySettings       DisplaySettings
 	ImageProxySettings    ImageProxySettings
+	GroupSettings         GroupSettings
 }
 
 func (o *Config) Clone() *Config {
@@ -2338,6 +2432,7 @@ func (o *Config) SetDefaults() {
 	o.MessageExportSettings.SetDefaults()
 	o.DisplaySettings.SetDefaults()
 	o.ImageProxySettings.SetDefaults(o.ServiceSettings)
+	o.GroupSettings.SetDefaults()
 }
 
 func (o *Config) IsValid() *AppError {
@@ -2393,6 +2488,10 @@ func (o *Config) IsValid() *AppError {
 		return err
 	}
 
+	if err := o.GroupSettings.isValid(); err != nil {
+		return err
+	}
+
 	if err := o.DataRetentionSettings.isValid(); err != nil {
 		return err
 	}
@@ -2881,4 +2980,12 @@ func (o *Config) Sanitize() {
 	}
 
 	*o.ElasticsearchSettings.Password = FAKE_SETTING
+
+	if len(*o.GroupSettings.SyncCompleteWebhookSecret) > 0 {
+		*o.GroupSettings.SyncCompleteWebhookSecret = FAKE_SETTING
+	}
+
+	if len(*o.GroupSettings.MembershipWebhookSecret) > 0 {
+		*o.GroupSettings.MembershipWebhookSecret = FAKE_SETTING
+	}
 }
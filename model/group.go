@@ -7,28 +7,81 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"regexp"
+	"strings"
 )
 
 const (
-	GroupSourceLdap GroupSource = "ldap"
+	GroupSourceLdap   GroupSource = "ldap"
+	GroupSourceCustom GroupSource = "custom"
+	GroupSourceSaml   GroupSource = "saml"
 
 	GroupNameMaxLength        = 64
 	GroupSourceMaxLength      = 64
 	GroupDisplayNameMaxLength = 128
 	GroupDescriptionMaxLength = 1024
 	GroupRemoteIDMaxLength    = 48
+	GroupTagMaxLength         = 64
+	GroupTagsMaxCount         = 20
+
+	GroupMembershipWebhookURLMaxLength = 512
+
+	// The reasons a field of a group can be invalid, as reported by Group.Validate.
+	GroupValidationReasonRequired = "required"
+	GroupValidationReasonTooLong  = "too_long"
+	GroupValidationReasonTooMany  = "too_many"
+	GroupValidationReasonInvalid  = "invalid"
+
+	// GroupSyncablesInlineLimit caps the number of teams and channels embedded in a group when they are requested
+	// along with it.
+	GroupSyncablesInlineLimit = 100
+
+	// GroupMemberIdsInlineLimit caps the number of member ids embedded in a group when they are requested along with
+	// it.
+	GroupMemberIdsInlineLimit = 5000
+
+	// GroupUnusedDefaultDays is how long a group must have gone without links or member changes to be listed as
+	// unused when no window is given.
+	GroupUnusedDefaultDays = 90
+
+	// GroupAutocompleteLimit caps the number of groups returned by a name prefix autocomplete.
+	GroupAutocompleteLimit = 25
+
+	// GroupsByIdsMaxCount caps the number of groups that can be fetched by id in one request.
+	GroupsByIdsMaxCount = 200
+
+	// GroupMembersUnionMaxCount caps the number of groups whose members can be combined in one request.
+	GroupMembersUnionMaxCount = 50
+
+	// GroupStaleSyncDefaultHours is how long ago an LDAP group must have last been synced to be listed as stale when
+	// no threshold is given.
+	GroupStaleSyncDefaultHours = 24
+
+	// GroupMembersIntersectionMaxCount caps the number of groups whose common members can be listed in one request.
+	GroupMembersIntersectionMaxCount = 50
+
+	// GroupSyncablesReportMaxCount caps both the number of groups and the number of teams or channels covered by one
+	// syncables report.
+	GroupSyncablesReportMaxCount = 200
 )
 
 type GroupSource string
 
 var allGroupSources = []GroupSource{
 	GroupSourceLdap,
+	GroupSourceCustom,
+	GroupSourceSaml,
 }
 
 var groupSourcesRequiringRemoteID = []GroupSource{
 	GroupSourceLdap,
+	GroupSourceSaml,
 }
 
+var validGroupTag = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+var validGroupMentionName = regexp.MustCompile(`^[a-z0-9._-]+$`)
+
 type Group struct {
 	Id           string      `json:"id"`
 	Name         string      `json:"name"`
@@ -39,24 +92,176 @@ type Group struct {
 	CreateAt     int64       `json:"create_at"`
 	UpdateAt     int64       `json:"update_at"`
 	DeleteAt     int64       `json:"delete_at"`
+	Tags         StringArray `json:"tags"`
 	HasSyncables bool        `db:"-" json:"has_syncables"`
+
+	// AllowReference makes the group available to mention autocomplete, which any user can query.
+	AllowReference bool `json:"allow_reference"`
+
+	// DescriptionIsMarkdown tells clients to render Description as markdown. The server stores it but never renders
+	// the description itself.
+	DescriptionIsMarkdown bool `json:"description_is_markdown"`
+
+	// MembershipWebhookURL, if set, receives a GroupMembershipWebhookPayload whenever a member is added to or removed
+	// from the group.
+	MembershipWebhookURL string `json:"membership_webhook_url"`
+
+	// IsDefault marks a group created by the system to grant baseline access, as opposed to one created by an admin.
+	// It cannot be set through the API.
+	IsDefault bool `json:"is_default"`
+
+	// ParentGroupId nests the group inside another group, whose members then include this group's members when
+	// listed with include_nested=true.
+	ParentGroupId string `json:"parent_group_id"`
+
+	// OwnerId is the user the group's management is delegated to. The owner may change the group's metadata and
+	// members without PERMISSION_MANAGE_SYSTEM.
+	OwnerId string `json:"owner_id"`
+
+	// SyncPaused freezes the members of an LDAP group: the sync keeps updating the group itself but neither adds nor
+	// removes members until it is unset.
+	SyncPaused bool `json:"sync_paused"`
+
+	// NameCollision is set on an LDAP group that was given a suffixed name by the sync because its name was already
+	// taken by another group. It is cleared when the group is renamed.
+	NameCollision bool `json:"name_collision"`
+
+	// LastSyncAt is when the members of an LDAP group were last synced from the directory, or zero if they never
+	// have been. It is only set by the sync.
+	LastSyncAt int64 `json:"last_sync_at"`
+
+	// NameLower is Name in lower case, by which group names are compared so that they are unique regardless of case.
+	// It is set by the store.
+	NameLower string `json:"-"`
+
+	// ViaTeam is set on a group listed for a channel when the group is linked to the channel's team rather than to
+	// the channel itself.
+	ViaTeam bool `db:"-" json:"via_team,omitempty"`
+
+	// ViaChannel is set on a group listed for a team with include_channel_groups=true when the group is linked only
+	// to channels of the team rather than to the team itself.
+	ViaChannel bool `db:"-" json:"via_channel,omitempty"`
+
+	// MemberIds and HasMoreMembers are only set when a group is fetched with include_member_ids=true. HasMoreMembers
+	// is set if the group has more than GroupMemberIdsInlineLimit members.
+	MemberIds      []string `db:"-" json:"member_ids,omitempty"`
+	HasMoreMembers *bool    `db:"-" json:"has_more_members,omitempty"`
 }
 
+// GroupListEnvelope wraps a page of a group list endpoint when the caller asks for envelope=true.
+type GroupListEnvelope struct {
+	Data    interface{} `json:"data"`
+	Page    int         `json:"page"`
+	PerPage int         `json:"per_page"`
+}
+
+// GroupWithSyncables is a group along with the teams and channels it is linked to. HasMore is set for a type when
+// there were more than GroupSyncablesInlineLimit links of that type.
+type GroupWithSyncables struct {
+	*Group
+	Teams           []*GroupSyncable `json:"teams"`
+	TeamsHasMore    bool             `json:"teams_has_more"`
+	Channels        []*GroupSyncable `json:"channels"`
+	ChannelsHasMore bool             `json:"channels_has_more"`
+}
+
+// GroupPatch holds the fields to change on a group. A field that is omitted or null is left unchanged, so that a
+// client can send the whole struct; an empty string is a value like any other, which is how Description is cleared.
 type GroupPatch struct {
-	Name        *string `json:"name"`
-	DisplayName *string `json:"display_name"`
-	Description *string `json:"description"`
+	Name           *string      `json:"name"`
+	DisplayName    *string      `json:"display_name"`
+	Description    *string      `json:"description"`
+	Tags           *StringArray `json:"tags"`
+	AllowReference *bool        `json:"allow_reference"`
+
+	DescriptionIsMarkdown *bool `json:"description_is_markdown"`
+
+	MembershipWebhookURL *string `json:"membership_webhook_url"`
+
+	// ParentGroupId nests the group inside another group; an empty string makes it a top-level group again.
+	ParentGroupId *string `json:"parent_group_id"`
+
+	SyncPaused *bool `json:"sync_paused"`
+
+	// OwnerId delegates the management of the group to a user; an empty string leaves the group without an owner.
+	OwnerId *string `json:"owner_id"`
+}
+
+// IsMetadataOnly reports whether the patch changes nothing but the group's names, description and tags, which is
+// all that a group's owner may change.
+func (patch *GroupPatch) IsMetadataOnly() bool {
+	return patch.AllowReference == nil &&
+		patch.MembershipWebhookURL == nil &&
+		patch.ParentGroupId == nil &&
+		patch.SyncPaused == nil &&
+		patch.OwnerId == nil
 }
 
 type GroupSearchOpts struct {
-	Q            string
+	Q string
+
+	// IsLinked and IsConfigured filter LDAP groups by whether they have a corresponding Mattermost group and are
+	// only used when listing LDAP groups.
 	IsLinked     *bool
 	IsConfigured *bool
+
+	// NotAssociatedToTeam excludes groups already linked to the given team.
+	NotAssociatedToTeam string
+
+	// NotAssociatedToChannel excludes groups already linked to the given channel.
+	NotAssociatedToChannel string
+
+	// FilterParentTeamPermitted restricts results to groups linked to the given team, so that a channel's group
+	// picker only offers groups that are permitted on the channel's parent team.
+	FilterParentTeamPermitted *string
+
+	// FilterAutoAdd restricts groups listed for a team to those whose link to the team adds members automatically.
+	FilterAutoAdd bool
+
+	// Tag restricts results to groups carrying the given tag.
+	Tag string
+
+	// IncludeTeamGroups adds the groups linked to a channel's team to the groups listed for the channel.
+	IncludeTeamGroups bool
+
+	// IncludeChannelGroups adds the groups linked to any channel of a team to the groups listed for the team.
+	IncludeChannelGroups bool
+
+	// ManageableOnly asks the API to list only the groups linked to teams the caller can manage, which lets team
+	// admins list groups without the manage system permission. The API resolves it into FilterTeamIds.
+	ManageableOnly bool
+
+	// FilterTeamIds restricts results to groups linked to at least one of the given teams when set.
+	FilterTeamIds []string
+
+	// MemberOfMe asks the API to list only the groups the caller is a member of, which any user may do. The API
+	// resolves it into FilterMemberId.
+	MemberOfMe bool
+
+	// FilterMemberId restricts results to groups the given user is an active member of.
+	FilterMemberId string
+
+	// ExcludeDefault leaves out the groups created by the system, see Group.IsDefault.
+	ExcludeDefault bool
+
+	// Locale sorts the groups by display name following the rules of the given locale, e.g. "de" or "pt-BR", where the
+	// database supports it.
+	Locale string
+}
+
+// UnusedGroupSearchOpts selects the groups that are candidates for cleanup: groups created before Since that are not
+// linked to any team or channel and whose members have not changed since then.
+type UnusedGroupSearchOpts struct {
+	// Source restricts results to groups from the given source when set.
+	Source GroupSource
+
+	Since int64
 }
 
 func (group *Group) Patch(patch *GroupPatch) {
 	if patch.Name != nil {
 		group.Name = *patch.Name
+		group.NameCollision = false
 	}
 	if patch.DisplayName != nil {
 		group.DisplayName = *patch.DisplayName
@@ -64,19 +269,82 @@ func (group *Group) Patch(patch *GroupPatch) {
 	if patch.Description != nil {
 		group.Description = *patch.Description
 	}
+	if patch.DescriptionIsMarkdown != nil {
+		group.DescriptionIsMarkdown = *patch.DescriptionIsMarkdown
+	}
+	if patch.Tags != nil {
+		group.Tags = *patch.Tags
+	}
+	if patch.AllowReference != nil {
+		group.AllowReference = *patch.AllowReference
+	}
+	if patch.MembershipWebhookURL != nil {
+		group.MembershipWebhookURL = *patch.MembershipWebhookURL
+	}
+	if patch.ParentGroupId != nil {
+		group.ParentGroupId = *patch.ParentGroupId
+	}
+	if patch.SyncPaused != nil {
+		group.SyncPaused = *patch.SyncPaused
+	}
+	if patch.OwnerId != nil {
+		group.OwnerId = *patch.OwnerId
+	}
+}
+
+// GroupsByIdsResult holds the groups found by a lookup by id, and the requested ids that matched no group the caller
+// can see.
+type GroupsByIdsResult struct {
+	Groups   []*Group `json:"groups"`
+	NotFound []string `json:"not_found"`
+}
+
+func GroupsByIdsResultFromJson(data io.Reader) *GroupsByIdsResult {
+	var result *GroupsByIdsResult
+	json.NewDecoder(data).Decode(&result)
+	return result
+}
+
+// Sanitize removes the fields that only system admins may see, for groups returned to any user.
+func (group *Group) Sanitize() {
+	group.MembershipWebhookURL = ""
 }
 
 func (group *Group) IsValidForCreate() *AppError {
-	if l := len(group.Name); l == 0 || l > GroupNameMaxLength {
-		return NewAppError("Group.IsValidForCreate", "model.group.name.app_error", map[string]interface{}{"GroupNameMaxLength": GroupNameMaxLength}, "", http.StatusBadRequest)
+	if validationErr := group.Validate(); validationErr != nil {
+		return validationErr.Errors[0].Err
+	}
+	return nil
+}
+
+// Validate checks every field of the group and reports all invalid fields together, so that a client can highlight
+// each of them at once. It returns nil if the group is valid.
+func (group *Group) Validate() *GroupValidationError {
+	validationErr := &GroupValidationError{}
+	fail := func(field, reason, id string, params map[string]interface{}, details string) {
+		validationErr.Errors = append(validationErr.Errors, &GroupFieldError{
+			Field:  field,
+			Reason: reason,
+			Err:    NewAppError("Group.IsValidForCreate", id, params, details, http.StatusBadRequest),
+		})
+	}
+
+	nameParams := map[string]interface{}{"GroupNameMaxLength": GroupNameMaxLength}
+	if l := len(group.Name); l == 0 {
+		fail("name", GroupValidationReasonRequired, "model.group.name.app_error", nameParams, "")
+	} else if l > GroupNameMaxLength {
+		fail("name", GroupValidationReasonTooLong, "model.group.name.app_error", nameParams, "")
 	}
 
-	if l := len(group.DisplayName); l == 0 || l > GroupDisplayNameMaxLength {
-		return NewAppError("Group.IsValidForCreate", "model.group.display_name.app_error", map[string]interface{}{"GroupDisplayNameMaxLength": GroupDisplayNameMaxLength}, "", http.StatusBadRequest)
+	displayNameParams := map[string]interface{}{"GroupDisplayNameMaxLength": GroupDisplayNameMaxLength}
+	if l := len(group.DisplayName); l == 0 {
+		fail("display_name", GroupValidationReasonRequired, "model.group.display_name.app_error", displayNameParams, "")
+	} else if l > GroupDisplayNameMaxLength {
+		fail("display_name", GroupValidationReasonTooLong, "model.group.display_name.app_error", displayNameParams, "")
 	}
 
 	if len(group.Description) > GroupDescriptionMaxLength {
-		return NewAppError("Group.IsValidForCreate", "model.group.description.app_error", map[string]interface{}{"GroupDescriptionMaxLength": GroupDescriptionMaxLength}, "", http.StatusBadRequest)
+		fail("description", GroupValidationReasonTooLong, "model.group.description.app_error", map[string]interface{}{"GroupDescriptionMaxLength": GroupDescriptionMaxLength}, "")
 	}
 
 	isValidSource := false
@@ -87,14 +355,58 @@ func (group *Group) IsValidForCreate() *AppError {
 		}
 	}
 	if !isValidSource {
-		return NewAppError("Group.IsValidForCreate", "model.group.source.app_error", nil, "", http.StatusBadRequest)
+		fail("source", GroupValidationReasonInvalid, "model.group.source.app_error", nil, "")
 	}
 
-	if len(group.RemoteId) > GroupRemoteIDMaxLength || (len(group.RemoteId) == 0 && group.requiresRemoteId()) {
-		return NewAppError("Group.IsValidForCreate", "model.group.remote_id.app_error", nil, "", http.StatusBadRequest)
+	if len(group.RemoteId) > GroupRemoteIDMaxLength {
+		fail("remote_id", GroupValidationReasonTooLong, "model.group.remote_id.app_error", nil, "")
+	} else if len(group.RemoteId) == 0 && group.requiresRemoteId() {
+		fail("remote_id", GroupValidationReasonRequired, "model.group.remote_id.app_error", nil, "")
 	}
 
-	return nil
+	if len(group.Tags) > GroupTagsMaxCount {
+		fail("tags", GroupValidationReasonTooMany, "model.group.tags.app_error", map[string]interface{}{"GroupTagsMaxCount": GroupTagsMaxCount}, "")
+	} else {
+		seenTags := make(map[string]bool, len(group.Tags))
+		for _, tag := range group.Tags {
+			if !IsValidGroupTag(tag) || seenTags[tag] {
+				fail("tags", GroupValidationReasonInvalid, "model.group.tag.app_error", map[string]interface{}{"GroupTagMaxLength": GroupTagMaxLength}, "tag="+tag)
+				break
+			}
+			seenTags[tag] = true
+		}
+	}
+
+	if l := len(group.MembershipWebhookURL); l > GroupMembershipWebhookURLMaxLength {
+		fail("membership_webhook_url", GroupValidationReasonTooLong, "model.group.membership_webhook_url.app_error", map[string]interface{}{"GroupMembershipWebhookURLMaxLength": GroupMembershipWebhookURLMaxLength}, "")
+	} else if l > 0 && !IsValidHttpUrl(group.MembershipWebhookURL) {
+		fail("membership_webhook_url", GroupValidationReasonInvalid, "model.group.membership_webhook_url.app_error", map[string]interface{}{"GroupMembershipWebhookURLMaxLength": GroupMembershipWebhookURLMaxLength}, "")
+	}
+
+	if len(group.ParentGroupId) > 0 && (!IsValidId(group.ParentGroupId) || group.ParentGroupId == group.Id) {
+		fail("parent_group_id", GroupValidationReasonInvalid, "model.group.parent_group_id.app_error", nil, "")
+	}
+
+	if len(group.OwnerId) > 0 && !IsValidId(group.OwnerId) {
+		fail("owner_id", GroupValidationReasonInvalid, "model.group.owner_id.app_error", nil, "")
+	}
+
+	if len(validationErr.Errors) == 0 {
+		return nil
+	}
+	return validationErr
+}
+
+// IsValidGroupTag reports whether tag is non-empty, at most GroupTagMaxLength long and made of lowercase letters,
+// digits, '-', '_' and '.'.
+func IsValidGroupTag(tag string) bool {
+	return len(tag) <= GroupTagMaxLength && validGroupTag.MatchString(tag)
+}
+
+// IsValidGroupMentionName reports whether name can be mentioned, i.e. whether it is non-empty and, ignoring case, made
+// of the same characters as a username: letters, digits, '-', '_' and '.'.
+func IsValidGroupMentionName(name string) bool {
+	return validGroupMentionName.MatchString(strings.ToLower(name))
 }
 
 func (group *Group) requiresRemoteId() bool {
@@ -122,12 +434,58 @@ func (group *Group) IsValidForUpdate() *AppError {
 	return nil
 }
 
+// GroupFieldError describes why one field of a group is invalid. Reason is one of the GroupValidationReason
+// constants and Err is the error that IsValidForCreate reports for the field.
+type GroupFieldError struct {
+	Field  string
+	Reason string
+	Err    *AppError
+}
+
+// GroupValidationError lists the invalid fields of a group in the order they were checked.
+type GroupValidationError struct {
+	Errors []*GroupFieldError
+}
+
+func (e *GroupValidationError) Error() string {
+	return e.Errors[0].Err.Error()
+}
+
+// Fields maps the json name of each invalid field to the reason it is invalid.
+func (e *GroupValidationError) Fields() map[string]string {
+	fields := make(map[string]string, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		fields[fieldErr.Field] = fieldErr.Reason
+	}
+	return fields
+}
+
+// ToAppError reports every invalid field in a single 400 error. The error carries the message id of the first invalid
+// field, and its params also map each invalid field to the reason it is invalid, for example
+// {"fields": {"name": "too_long", "display_name": "required"}}.
+func (e *GroupValidationError) ToAppError(where string) *AppError {
+	first := e.Errors[0].Err
+
+	params := map[string]interface{}{"fields": e.Fields()}
+	for key, value := range first.params {
+		params[key] = value
+	}
+
+	return NewAppError(where, first.Id, params, first.DetailedError, http.StatusBadRequest)
+}
+
 func GroupFromJson(data io.Reader) *Group {
 	var group *Group
 	json.NewDecoder(data).Decode(&group)
 	return group
 }
 
+func GroupWithSyncablesFromJson(data io.Reader) *GroupWithSyncables {
+	groupWithSyncables := &GroupWithSyncables{}
+	json.NewDecoder(data).Decode(groupWithSyncables)
+	return groupWithSyncables
+}
+
 func GroupsFromJson(data io.Reader) []*Group {
 	var groups []*Group
 	json.NewDecoder(data).Decode(&groups)
@@ -139,3 +497,193 @@ func GroupPatchFromJson(data io.Reader) *GroupPatch {
 	json.NewDecoder(data).Decode(&groupPatch)
 	return groupPatch
 }
+
+// GroupMergeResult is the outcome of merging one group into another.
+type GroupMergeResult struct {
+	MemberCount int64 `json:"member_count"`
+}
+
+func GroupMergeResultFromJson(data io.Reader) *GroupMergeResult {
+	var result *GroupMergeResult
+	json.NewDecoder(data).Decode(&result)
+	return result
+}
+
+// GroupDeleteImpact describes what deleting a group would do to the group-constrained teams and channels it is
+// linked to. AffectedUserCount is the number of members of those teams and channels whose only permitting group is
+// this one.
+type GroupDeleteImpact struct {
+	ConstrainedTeamCount    int64 `json:"constrained_team_count"`
+	ConstrainedChannelCount int64 `json:"constrained_channel_count"`
+	AffectedUserCount       int64 `json:"affected_user_count"`
+}
+
+func GroupDeleteImpactFromJson(data io.Reader) *GroupDeleteImpact {
+	var impact *GroupDeleteImpact
+	json.NewDecoder(data).Decode(&impact)
+	return impact
+}
+
+// GroupStats summarizes the membership of a group. DeletedMemberCount is only set when removed members were asked
+// for.
+type GroupStats struct {
+	GroupId            string `json:"group_id"`
+	TotalMemberCount   int64  `json:"total_member_count"`
+	DeletedMemberCount *int64 `json:"deleted_member_count,omitempty"`
+}
+
+func GroupStatsFromJson(data io.Reader) *GroupStats {
+	var stats *GroupStats
+	json.NewDecoder(data).Decode(&stats)
+	return stats
+}
+
+// GroupMembersClearResult is the outcome of removing every member of a group.
+type GroupMembersClearResult struct {
+	RemovedCount int `json:"removed_count"`
+}
+
+func (result *GroupMembersClearResult) ToJson() []byte {
+	b, _ := json.Marshal(result)
+	return b
+}
+
+func GroupMembersClearResultFromJson(data io.Reader) *GroupMembersClearResult {
+	var result *GroupMembersClearResult
+	json.NewDecoder(data).Decode(&result)
+	return result
+}
+
+// GroupSyncSummary describes the outcome of a group synchronization run and is delivered to
+// GroupSettings.SyncCompleteWebhookURL when the run finishes.
+type GroupSyncSummary struct {
+	GroupsSynced   int      `json:"groups_synced"`
+	MembersAdded   int      `json:"members_added"`
+	MembersRemoved int      `json:"members_removed"`
+	Errors         []string `json:"errors"`
+
+	// AffectedUserIds are the users whose group memberships changed during the sync. They are not sent to the
+	// webhook.
+	AffectedUserIds []string `json:"-"`
+}
+
+// GroupSyncDryRunResult previews what a full group sync would change. MembersToAdd and MembersToRemove count team and
+// channel memberships, and ChannelsAffected the distinct channels those changes touch.
+type GroupSyncDryRunResult struct {
+	Groups           int `json:"groups"`
+	MembersToAdd     int `json:"members_to_add"`
+	MembersToRemove  int `json:"members_to_remove"`
+	ChannelsAffected int `json:"channels_affected"`
+}
+
+func GroupSyncDryRunResultFromJson(data io.Reader) *GroupSyncDryRunResult {
+	var result *GroupSyncDryRunResult
+	json.NewDecoder(data).Decode(&result)
+	return result
+}
+
+// LdapGroupValidation reports whether the LDAP object of a group still exists and how many members it has, as seen by
+// a live lookup.
+type LdapGroupValidation struct {
+	Exists      bool `json:"exists"`
+	MemberCount int  `json:"member_count"`
+}
+
+func LdapGroupValidationFromJson(data io.Reader) *LdapGroupValidation {
+	var validation *LdapGroupValidation
+	json.NewDecoder(data).Decode(&validation)
+	return validation
+}
+
+// GroupMessage is a message to send to each member of a group as a direct message from the sender.
+type GroupMessage struct {
+	Message string `json:"message"`
+}
+
+func GroupMessageFromJson(data io.Reader) *GroupMessage {
+	var message *GroupMessage
+	json.NewDecoder(data).Decode(&message)
+	return message
+}
+
+// GroupMembershipWebhookPayload is posted to a group's MembershipWebhookURL for each member added to or removed from
+// the group. Action is GroupMembershipWebhookActionAdd or GroupMembershipWebhookActionRemove.
+type GroupMembershipWebhookPayload struct {
+	GroupId   string `json:"group_id"`
+	UserId    string `json:"user_id"`
+	Action    string `json:"action"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+const (
+	GroupMembershipWebhookActionAdd    = "add"
+	GroupMembershipWebhookActionRemove = "remove"
+)
+
+// GroupMemberTimezoneUnknown buckets the group members who have not set a timezone in a timezone distribution.
+const GroupMemberTimezoneUnknown = "unknown"
+
+// GroupMentionCount is the number of users a mention of a group in a channel notifies.
+type GroupMentionCount struct {
+	Count int64 `json:"count"`
+}
+
+func (count *GroupMentionCount) ToJson() []byte {
+	b, _ := json.Marshal(count)
+	return b
+}
+
+func GroupMentionCountFromJson(data io.Reader) *GroupMentionCount {
+	var count *GroupMentionCount
+	json.NewDecoder(data).Decode(&count)
+	return count
+}
+
+func (payload *GroupMembershipWebhookPayload) ToJson() []byte {
+	b, _ := json.Marshal(payload)
+	return b
+}
+
+func GroupMembershipWebhookPayloadFromJson(data io.Reader) *GroupMembershipWebhookPayload {
+	var payload *GroupMembershipWebhookPayload
+	json.NewDecoder(data).Decode(&payload)
+	return payload
+}
+
+func (summary *GroupSyncSummary) ToJson() []byte {
+	b, _ := json.Marshal(summary)
+	return b
+}
+
+const (
+	LdapGroupTeamLinkStatusLinked   = "linked"
+	LdapGroupTeamLinkStatusNotFound = "not_found"
+	LdapGroupTeamLinkStatusError    = "error"
+)
+
+// LdapGroupsTeamLink is a request to link the LDAP groups with the given remote ids to a team in one call.
+type LdapGroupsTeamLink struct {
+	RemoteIds []string `json:"remote_ids"`
+	AutoAdd   bool     `json:"auto_add"`
+}
+
+func LdapGroupsTeamLinkFromJson(data io.Reader) *LdapGroupsTeamLink {
+	var link *LdapGroupsTeamLink
+	json.NewDecoder(data).Decode(&link)
+	return link
+}
+
+// LdapGroupTeamLinkStatus reports the outcome of linking a single LDAP group to a team. GroupId is only set once the
+// remote id has been resolved to a local group.
+type LdapGroupTeamLinkStatus struct {
+	RemoteId string    `json:"remote_id"`
+	GroupId  string    `json:"group_id,omitempty"`
+	Status   string    `json:"status"`
+	Error    *AppError `json:"error,omitempty"`
+}
+
+func LdapGroupTeamLinkStatusesFromJson(data io.Reader) []*LdapGroupTeamLinkStatus {
+	var statuses []*LdapGroupTeamLinkStatus
+	json.NewDecoder(data).Decode(&statuses)
+	return statuses
+}
@@ -3,13 +3,62 @@
 
 package model
 
-import "net/http"
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const (
+	GroupMemberRolesMaxLength  = 64
+	GroupMemberSourceMaxLength = 64
+)
+
+// The ways a user can join a group, recorded as the Source of their membership.
+const (
+	GroupMemberSourceManual = "manual"
+	GroupMemberSourceLdap   = "ldap"
+	GroupMemberSourceSaml   = "saml"
+)
 
 type GroupMember struct {
 	GroupId  string `json:"group_id"`
 	UserId   string `json:"user_id"`
 	CreateAt int64  `json:"create_at"`
 	DeleteAt int64  `json:"delete_at"`
+	Roles    string `json:"roles"`
+
+	// ExpiresAt is when the membership is removed by the group member expiry task, or zero if it does not expire.
+	ExpiresAt int64 `json:"expires_at"`
+
+	// Source is how the user joined the group: GroupMemberSourceLdap or GroupMemberSourceSaml for members added by a
+	// sync, and GroupMemberSourceManual for members added by hand.
+	Source string `json:"source"`
+}
+
+// GroupMemberSourceForGroup returns the Source of the memberships created in a group from the given source. Members
+// of LDAP and SAML groups come from their sync, while the members of custom groups are added by hand.
+func GroupMemberSourceForGroup(source GroupSource) string {
+	switch source {
+	case GroupSourceLdap:
+		return GroupMemberSourceLdap
+	case GroupSourceSaml:
+		return GroupMemberSourceSaml
+	default:
+		return GroupMemberSourceManual
+	}
+}
+
+// GroupMemberPatch changes the in-group roles of a member. The roles are metadata for integrations, such as marking
+// the owners of a group, and do not grant any permissions.
+type GroupMemberPatch struct {
+	Roles *string `json:"roles"`
+}
+
+func (gm *GroupMember) Patch(patch *GroupMemberPatch) {
+	if patch.Roles != nil {
+		gm.Roles = *patch.Roles
+	}
 }
 
 func (gm *GroupMember) IsValid() *AppError {
@@ -19,5 +68,89 @@ func (gm *GroupMember) IsValid() *AppError {
 	if !IsValidId(gm.UserId) {
 		return NewAppError("GroupMember.IsValid", "model.group_member.user_id.app_error", nil, "", http.StatusBadRequest)
 	}
+	if len(gm.Roles) > GroupMemberRolesMaxLength {
+		return NewAppError("GroupMember.IsValid", "model.group_member.roles.app_error", map[string]interface{}{"GroupMemberRolesMaxLength": GroupMemberRolesMaxLength}, "", http.StatusBadRequest)
+	}
+	if gm.ExpiresAt < 0 {
+		return NewAppError("GroupMember.IsValid", "model.group_member.expires_at.app_error", nil, "", http.StatusBadRequest)
+	}
+	if len(gm.Source) > GroupMemberSourceMaxLength {
+		return NewAppError("GroupMember.IsValid", "model.group_member.source.app_error", nil, "", http.StatusBadRequest)
+	}
 	return nil
 }
+
+// GroupMembersUpsertResult reports which of the requested users were added to a group and which of them already
+// belonged to it.
+type GroupMembersUpsertResult struct {
+	Added          []string `json:"added"`
+	AlreadyMembers []string `json:"already_members"`
+}
+
+func GroupMembersUpsertResultFromJson(data io.Reader) *GroupMembersUpsertResult {
+	var result *GroupMembersUpsertResult
+	json.NewDecoder(data).Decode(&result)
+	return result
+}
+
+// GroupMembershipCheckResult splits the users of a membership check into the active members of a group and everyone
+// else, including unknown users.
+type GroupMembershipCheckResult struct {
+	Members    []string `json:"members"`
+	NonMembers []string `json:"non_members"`
+}
+
+func GroupMembershipCheckResultFromJson(data io.Reader) *GroupMembershipCheckResult {
+	var result *GroupMembershipCheckResult
+	json.NewDecoder(data).Decode(&result)
+	return result
+}
+
+// GroupMembershipTransfer asks for the custom group memberships of one user to be given to another, e.g. when the
+// first user leaves and the second takes over their role. RemoveSource also removes the first user from the groups.
+type GroupMembershipTransfer struct {
+	FromUserId   string `json:"from_user_id"`
+	ToUserId     string `json:"to_user_id"`
+	RemoveSource bool   `json:"remove_source"`
+}
+
+func GroupMembershipTransferFromJson(data io.Reader) *GroupMembershipTransfer {
+	var transfer *GroupMembershipTransfer
+	json.NewDecoder(data).Decode(&transfer)
+	return transfer
+}
+
+// GroupMemberHistoryEvent records a user being added to or removed from a group, so that the members of a group at
+// any point in time can be reconstructed. Action is GroupMembershipWebhookActionAdd or
+// GroupMembershipWebhookActionRemove. Events are only ever appended.
+type GroupMemberHistoryEvent struct {
+	Id       string `json:"id"`
+	GroupId  string `json:"group_id"`
+	UserId   string `json:"user_id"`
+	Action   string `json:"action"`
+	CreateAt int64  `json:"create_at"`
+}
+
+func GroupMemberHistoryEventsFromJson(data io.Reader) []*GroupMemberHistoryEvent {
+	var events []*GroupMemberHistoryEvent
+	json.NewDecoder(data).Decode(&events)
+	return events
+}
+
+func GroupMembersFromJson(data io.Reader) []*GroupMember {
+	var members []*GroupMember
+	json.NewDecoder(data).Decode(&members)
+	return members
+}
+
+func GroupMemberFromJson(data io.Reader) *GroupMember {
+	var member *GroupMember
+	json.NewDecoder(data).Decode(&member)
+	return member
+}
+
+func GroupMemberPatchFromJson(data io.Reader) *GroupMemberPatch {
+	var patch *GroupMemberPatch
+	json.NewDecoder(data).Decode(&patch)
+	return patch
+}
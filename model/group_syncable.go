@@ -9,6 +9,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"path"
 )
 
 type GroupSyncableType string
@@ -18,10 +19,24 @@ const (
 	GroupSyncableTypeChannel GroupSyncableType = "Channel"
 )
 
+const GroupSyncableRolesMaxLength = 64
+
 func (gst GroupSyncableType) String() string {
 	return string(gst)
 }
 
+// GroupSyncableTypeFromString returns the syncable type named by a syncable_type route parameter, "teams" or
+// "channels".
+func GroupSyncableTypeFromString(s string) (GroupSyncableType, error) {
+	switch s {
+	case "teams":
+		return GroupSyncableTypeTeam, nil
+	case "channels":
+		return GroupSyncableTypeChannel, nil
+	}
+	return "", fmt.Errorf("unknown syncable type %q", s)
+}
+
 type GroupSyncable struct {
 	GroupId string `json:"group_id"`
 
@@ -29,18 +44,140 @@ type GroupSyncable struct {
 	// TeamId.
 	SyncableId string `db:"-" json:"-"`
 
-	AutoAdd  bool              `json:"auto_add"`
+	AutoAdd bool `json:"auto_add"`
+
+	// SchemeAdmin marks the members the group brings into the team or channel as its admins.
+	SchemeAdmin bool `json:"scheme_admin"`
+
+	// SuppressNotifications adds the members the group brings into the team or channel without posting join messages.
+	SuppressNotifications bool `json:"suppress_notifications"`
+
+	// ExpiresAt is when the link is removed by the group syncable expiry task, or zero if it does not expire.
+	ExpiresAt int64 `json:"expires_at"`
+
+	// Roles holds the space separated names of roles of the channel's scheme given to the members the group brings
+	// into a channel, on top of their member role.
+	Roles string `json:"roles"`
+
+	// ExcludeMembers makes a channel link keep the group's members out of the channel instead of bringing them in. It
+	// wins over the other links of the channel: a user in both an including and an excluding group is not added by
+	// the sync, and is reported by ResolveGroupMembershipConflicts if they are already a member.
+	ExcludeMembers bool `json:"exclude_members"`
+
 	CreateAt int64             `json:"create_at"`
 	DeleteAt int64             `json:"delete_at"`
 	UpdateAt int64             `json:"update_at"`
 	Type     GroupSyncableType `db:"-" json:"-"`
 
+	// DefaultChannelId is the channel created for the group when it was linked to a team with
+	// create_default_channel set.
+	DefaultChannelId string `db:"-" json:"default_channel_id,omitempty"`
+
+	// UsersAdded and UsersSkippedExisting are only set in the response to linking the group to a channel with auto-add.
+	// They count the group's members who were added to the channel and those who already belonged to it.
+	UsersAdded           *int `db:"-" json:"users_added,omitempty"`
+	UsersSkippedExisting *int `db:"-" json:"users_skipped_existing,omitempty"`
+
 	// Values joined in from the associated team and/or channel
 	ChannelDisplayName string `db:"-" json:"-"`
+	ChannelName        string `db:"-" json:"-"`
 	TeamDisplayName    string `db:"-" json:"-"`
+	TeamName           string `db:"-" json:"-"`
 	TeamType           string `db:"-" json:"-"`
 	ChannelType        string `db:"-" json:"-"`
 	TeamID             string `db:"-" json:"-"`
+	ChannelDeleteAt    int64  `db:"-" json:"-"`
+
+	// GroupDisplayName is only joined in for the team links listed for a team.
+	GroupDisplayName string `db:"-" json:"-"`
+}
+
+// GroupSyncableTeam is the team a syncable links to, or the team of the channel it links to.
+type GroupSyncableTeam struct {
+	Id          string `json:"id"`
+	DisplayName string `json:"display_name"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+}
+
+// GroupSyncableExpanded is a group syncable along with the team or channel it links to, as returned by
+// getGroupSyncables with expand set.
+type GroupSyncableExpanded struct {
+	*GroupSyncable
+
+	DisplayName string `json:"display_name"`
+	Name        string `json:"name"`
+
+	// Type and Team are only set for channel syncables.
+	Type string             `json:"type,omitempty"`
+	Team *GroupSyncableTeam `json:"team,omitempty"`
+}
+
+// NewGroupSyncableExpanded embeds the team or channel values joined into the syncable.
+func NewGroupSyncableExpanded(syncable *GroupSyncable) *GroupSyncableExpanded {
+	expanded := &GroupSyncableExpanded{GroupSyncable: syncable}
+	switch syncable.Type {
+	case GroupSyncableTypeTeam:
+		expanded.DisplayName = syncable.TeamDisplayName
+		expanded.Name = syncable.TeamName
+	case GroupSyncableTypeChannel:
+		expanded.DisplayName = syncable.ChannelDisplayName
+		expanded.Name = syncable.ChannelName
+		expanded.Type = syncable.ChannelType
+		expanded.Team = &GroupSyncableTeam{
+			Id:          syncable.TeamID,
+			DisplayName: syncable.TeamDisplayName,
+			Name:        syncable.TeamName,
+			Type:        syncable.TeamType,
+		}
+	}
+	return expanded
+}
+
+func (expanded *GroupSyncableExpanded) MarshalJSON() ([]byte, error) {
+	b, err := expanded.GroupSyncable.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]interface{}
+	if err = json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	fields["display_name"] = expanded.DisplayName
+	fields["name"] = expanded.Name
+	if expanded.Type != "" {
+		fields["type"] = expanded.Type
+	}
+	if expanded.Team != nil {
+		fields["team"] = expanded.Team
+	}
+
+	return json.Marshal(fields)
+}
+
+func (expanded *GroupSyncableExpanded) UnmarshalJSON(b []byte) error {
+	syncable := &GroupSyncable{}
+	if err := json.Unmarshal(b, syncable); err != nil {
+		return err
+	}
+
+	var fields struct {
+		DisplayName string             `json:"display_name"`
+		Name        string             `json:"name"`
+		Type        string             `json:"type"`
+		Team        *GroupSyncableTeam `json:"team"`
+	}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+
+	expanded.GroupSyncable = syncable
+	expanded.DisplayName = fields.DisplayName
+	expanded.Name = fields.Name
+	expanded.Type = fields.Type
+	expanded.Team = fields.Team
+	return nil
 }
 
 func (syncable *GroupSyncable) IsValid() *AppError {
@@ -50,6 +187,15 @@ func (syncable *GroupSyncable) IsValid() *AppError {
 	if !IsValidId(syncable.SyncableId) {
 		return NewAppError("GroupSyncable.SyncableIsValid", "model.group_syncable.syncable_id.app_error", nil, "", http.StatusBadRequest)
 	}
+	if syncable.ExpiresAt < 0 {
+		return NewAppError("GroupSyncable.SyncableIsValid", "model.group_syncable.expires_at.app_error", nil, "", http.StatusBadRequest)
+	}
+	if len(syncable.Roles) > GroupSyncableRolesMaxLength {
+		return NewAppError("GroupSyncable.SyncableIsValid", "model.group_syncable.roles.app_error", map[string]interface{}{"GroupSyncableRolesMaxLength": GroupSyncableRolesMaxLength}, "", http.StatusBadRequest)
+	}
+	if syncable.ExcludeMembers && (syncable.AutoAdd || syncable.Type == GroupSyncableTypeTeam) {
+		return NewAppError("GroupSyncable.SyncableIsValid", "model.group_syncable.exclude_members.app_error", nil, "", http.StatusBadRequest)
+	}
 	return nil
 }
 
@@ -62,6 +208,11 @@ func (syncable *GroupSyncable) UnmarshalJSON(b []byte) error {
 	for key, value := range kvp {
 		switch key {
 		case "team_id":
+			// Channel syncables also carry the id of the channel's team.
+			if _, isChannel := kvp["channel_id"]; isChannel {
+				syncable.TeamID = value.(string)
+				continue
+			}
 			syncable.SyncableId = value.(string)
 			syncable.Type = GroupSyncableTypeTeam
 		case "channel_id":
@@ -71,6 +222,26 @@ func (syncable *GroupSyncable) UnmarshalJSON(b []byte) error {
 			syncable.GroupId = value.(string)
 		case "auto_add":
 			syncable.AutoAdd = value.(bool)
+		case "scheme_admin":
+			syncable.SchemeAdmin = value.(bool)
+		case "suppress_notifications":
+			syncable.SuppressNotifications = value.(bool)
+		case "expires_at":
+			syncable.ExpiresAt = int64(value.(float64))
+		case "roles":
+			syncable.Roles = value.(string)
+		case "exclude_members":
+			syncable.ExcludeMembers = value.(bool)
+		case "channel_delete_at":
+			syncable.ChannelDeleteAt = int64(value.(float64))
+		case "default_channel_id":
+			syncable.DefaultChannelId = value.(string)
+		case "users_added":
+			syncable.UsersAdded = NewInt(int(value.(float64)))
+		case "users_skipped_existing":
+			syncable.UsersSkippedExisting = NewInt(int(value.(float64)))
+		case "group_display_name":
+			syncable.GroupDisplayName = value.(string)
 		default:
 		}
 	}
@@ -83,21 +254,24 @@ func (syncable *GroupSyncable) MarshalJSON() ([]byte, error) {
 	switch syncable.Type {
 	case GroupSyncableTypeTeam:
 		return json.Marshal(&struct {
-			TeamID          string `json:"team_id"`
-			TeamDisplayName string `json:"team_display_name,omitempty"`
-			TeamType        string `json:"team_type,omitempty"`
+			TeamID           string `json:"team_id"`
+			TeamDisplayName  string `json:"team_display_name,omitempty"`
+			TeamType         string `json:"team_type,omitempty"`
+			GroupDisplayName string `json:"group_display_name,omitempty"`
 			*Alias
 		}{
-			TeamDisplayName: syncable.TeamDisplayName,
-			TeamType:        syncable.TeamType,
-			TeamID:          syncable.SyncableId,
-			Alias:           (*Alias)(syncable),
+			TeamDisplayName:  syncable.TeamDisplayName,
+			TeamType:         syncable.TeamType,
+			TeamID:           syncable.SyncableId,
+			GroupDisplayName: syncable.GroupDisplayName,
+			Alias:            (*Alias)(syncable),
 		})
 	case GroupSyncableTypeChannel:
 		return json.Marshal(&struct {
 			ChannelID          string `json:"channel_id"`
 			ChannelDisplayName string `json:"channel_display_name,omitempty"`
 			ChannelType        string `json:"channel_type,omitempty"`
+			ChannelDeleteAt    int64  `json:"channel_delete_at,omitempty"`
 
 			TeamID          string `json:"team_id,omitempty"`
 			TeamDisplayName string `json:"team_display_name,omitempty"`
@@ -108,6 +282,7 @@ func (syncable *GroupSyncable) MarshalJSON() ([]byte, error) {
 			ChannelID:          syncable.SyncableId,
 			ChannelDisplayName: syncable.ChannelDisplayName,
 			ChannelType:        syncable.ChannelType,
+			ChannelDeleteAt:    syncable.ChannelDeleteAt,
 
 			TeamID:          syncable.TeamID,
 			TeamDisplayName: syncable.TeamDisplayName,
@@ -123,23 +298,237 @@ func (syncable *GroupSyncable) MarshalJSON() ([]byte, error) {
 }
 
 type GroupSyncablePatch struct {
-	AutoAdd *bool `json:"auto_add"`
+	AutoAdd               *bool `json:"auto_add"`
+	SchemeAdmin           *bool `json:"scheme_admin"`
+	SuppressNotifications *bool `json:"suppress_notifications"`
+
+	// ExpiresAt sets when the link expires. Zero makes it permanent.
+	ExpiresAt *int64 `json:"expires_at"`
+
+	// Roles sets the roles given to the members the group brings into a channel. An empty string removes them.
+	Roles *string `json:"roles"`
+
+	ExcludeMembers *bool `json:"exclude_members"`
+
+	// CreateDefaultChannel is only read when linking a group to a team. It also creates a private channel for the
+	// group in the team and links the group to it. It is not stored on the link.
+	CreateDefaultChannel *bool `json:"create_default_channel"`
 }
 
 func (syncable *GroupSyncable) Patch(patch *GroupSyncablePatch) {
 	if patch.AutoAdd != nil {
 		syncable.AutoAdd = *patch.AutoAdd
 	}
+	if patch.SchemeAdmin != nil {
+		syncable.SchemeAdmin = *patch.SchemeAdmin
+	}
+	if patch.SuppressNotifications != nil {
+		syncable.SuppressNotifications = *patch.SuppressNotifications
+	}
+	if patch.ExpiresAt != nil {
+		syncable.ExpiresAt = *patch.ExpiresAt
+	}
+	if patch.Roles != nil {
+		syncable.Roles = *patch.Roles
+	}
+	if patch.ExcludeMembers != nil {
+		syncable.ExcludeMembers = *patch.ExcludeMembers
+	}
+}
+
+// ReplaceSettings overwrites every setting of the link with those of settings, unlike Patch which only changes the
+// settings that are given.
+func (syncable *GroupSyncable) ReplaceSettings(settings *GroupSyncable) {
+	syncable.AutoAdd = settings.AutoAdd
+	syncable.SchemeAdmin = settings.SchemeAdmin
+	syncable.SuppressNotifications = settings.SuppressNotifications
+	syncable.ExpiresAt = settings.ExpiresAt
+	syncable.Roles = settings.Roles
+	syncable.ExcludeMembers = settings.ExcludeMembers
+}
+
+// GroupMembershipConflict is a member of a channel who belongs to a group whose link to the channel excludes its
+// members. ExcludedByGroupIds are those groups, and IncludedByGroupIds the other groups of the user linked to the
+// channel.
+type GroupMembershipConflict struct {
+	UserId             string   `json:"user_id"`
+	ExcludedByGroupIds []string `json:"excluded_by_group_ids"`
+	IncludedByGroupIds []string `json:"included_by_group_ids"`
+}
+
+func GroupMembershipConflictsFromJson(data io.Reader) []*GroupMembershipConflict {
+	var conflicts []*GroupMembershipConflict
+	json.NewDecoder(data).Decode(&conflicts)
+	return conflicts
+}
+
+// GroupChannelAccess describes what a group gives one of its members in a channel. The group grants membership while
+// the user is an active member of the group and the group is linked to the channel, and admin rights when that link
+// is also marked scheme admin.
+type GroupChannelAccess struct {
+	UserId           string `json:"user_id"`
+	GroupId          string `json:"group_id"`
+	ChannelId        string `json:"channel_id"`
+	IsGroupMember    bool   `json:"is_group_member"`
+	IsLinked         bool   `json:"is_linked"`
+	GrantsMembership bool   `json:"grants_membership"`
+	SchemeAdmin      bool   `json:"scheme_admin"`
+}
+
+func GroupChannelAccessFromJson(data io.Reader) *GroupChannelAccess {
+	var access *GroupChannelAccess
+	json.NewDecoder(data).Decode(&access)
+	return access
+}
+
+// GroupProvisionedAccess lists the teams and channels a user is given by the auto-add links of the groups they belong
+// to, whether or not they are already members.
+type GroupProvisionedAccess struct {
+	UserId   string                      `json:"user_id"`
+	Teams    []*GroupProvisionedSyncable `json:"teams"`
+	Channels []*GroupProvisionedSyncable `json:"channels"`
+}
+
+// GroupProvisionedSyncable is a team or channel given by group links, along with the groups linking to it. TeamId is
+// only set for channels.
+type GroupProvisionedSyncable struct {
+	Id          string   `json:"id"`
+	DisplayName string   `json:"display_name"`
+	Name        string   `json:"name"`
+	TeamId      string   `json:"team_id,omitempty"`
+	GroupIds    []string `json:"group_ids"`
+}
+
+func GroupProvisionedAccessFromJson(data io.Reader) *GroupProvisionedAccess {
+	var access *GroupProvisionedAccess
+	json.NewDecoder(data).Decode(&access)
+	return access
+}
+
+// GroupPromotionResult reports how many of a channel's group links were copied to the channel's team, and how many
+// were skipped because the group was already linked to the team.
+type GroupPromotionResult struct {
+	Promoted int `json:"promoted"`
+	Skipped  int `json:"skipped"`
+}
+
+func GroupPromotionResultFromJson(data io.Reader) *GroupPromotionResult {
+	var result *GroupPromotionResult
+	json.NewDecoder(data).Decode(&result)
+	return result
+}
+
+// GroupChannelPattern links a group to every channel whose name matches Pattern, a glob as understood by path.Match
+// such as "team-*". Channels created after the pattern are linked as well.
+type GroupChannelPattern struct {
+	GroupId  string `json:"group_id"`
+	Pattern  string `json:"pattern"`
+	AutoAdd  bool   `json:"auto_add"`
+	CreateAt int64  `json:"create_at"`
+}
+
+func (pattern *GroupChannelPattern) IsValid() *AppError {
+	if !IsValidId(pattern.GroupId) {
+		return NewAppError("GroupChannelPattern.IsValid", "model.group_syncable.group_id.app_error", nil, "", http.StatusBadRequest)
+	}
+	if len(pattern.Pattern) == 0 || len(pattern.Pattern) > CHANNEL_NAME_MAX_LENGTH {
+		return NewAppError("GroupChannelPattern.IsValid", "model.group_channel_pattern.pattern.app_error", nil, "", http.StatusBadRequest)
+	}
+	if _, err := path.Match(pattern.Pattern, ""); err != nil {
+		return NewAppError("GroupChannelPattern.IsValid", "model.group_channel_pattern.pattern.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+	return nil
+}
+
+// Matches reports whether the channel name matches the pattern.
+func (pattern *GroupChannelPattern) Matches(channelName string) bool {
+	matched, _ := path.Match(pattern.Pattern, channelName)
+	return matched
+}
+
+// LiteralPrefix returns the part of the pattern before its first wildcard, which every matching name starts with.
+func (pattern *GroupChannelPattern) LiteralPrefix() string {
+	for i, c := range pattern.Pattern {
+		switch c {
+		case '*', '?', '[', '\\':
+			return pattern.Pattern[:i]
+		}
+	}
+	return pattern.Pattern
+}
+
+// GroupChannelPatternStatus is the outcome of linking a group to the channels matching one of its channel name
+// patterns. Linked counts the links made before Error, if any, stopped the pattern.
+type GroupChannelPatternStatus struct {
+	GroupId string    `json:"group_id"`
+	Pattern string    `json:"pattern"`
+	Linked  int       `json:"linked"`
+	Error   *AppError `json:"error,omitempty"`
+}
+
+func GroupChannelPatternFromJson(data io.Reader) *GroupChannelPattern {
+	var pattern *GroupChannelPattern
+	json.NewDecoder(data).Decode(&pattern)
+	return pattern
+}
+
+func GroupChannelPatternsFromJson(data io.Reader) []*GroupChannelPattern {
+	var patterns []*GroupChannelPattern
+	json.NewDecoder(data).Decode(&patterns)
+	return patterns
+}
+
+// GroupChannelExemption keeps a member of a group-constrained channel, such as a service account, from being removed
+// from the channel for not belonging to any of its groups.
+type GroupChannelExemption struct {
+	ChannelId string `json:"channel_id"`
+	UserId    string `json:"user_id"`
+	CreateAt  int64  `json:"create_at"`
+}
+
+func (exemption *GroupChannelExemption) IsValid() *AppError {
+	if !IsValidId(exemption.ChannelId) {
+		return NewAppError("GroupChannelExemption.IsValid", "model.channel_member.is_valid.channel_id.app_error", nil, "", http.StatusBadRequest)
+	}
+	if !IsValidId(exemption.UserId) {
+		return NewAppError("GroupChannelExemption.IsValid", "model.channel_member.is_valid.user_id.app_error", nil, "", http.StatusBadRequest)
+	}
+	return nil
+}
+
+func GroupChannelExemptionFromJson(data io.Reader) *GroupChannelExemption {
+	var exemption *GroupChannelExemption
+	json.NewDecoder(data).Decode(&exemption)
+	return exemption
+}
+
+const (
+	GroupSyncableStatusUnlinked  = "unlinked"
+	GroupSyncableStatusNotLinked = "not_linked"
+	GroupSyncableStatusForbidden = "forbidden"
+	GroupSyncableStatusNotFound  = "not_found"
+	GroupSyncableStatusLastGroup = "last_group"
+	GroupSyncableStatusError     = "error"
+)
+
+// GroupSyncableStatus reports the outcome of a bulk link or unlink request for a single team or channel.
+type GroupSyncableStatus struct {
+	SyncableId string    `json:"syncable_id"`
+	Status     string    `json:"status"`
+	Error      *AppError `json:"error,omitempty"`
 }
 
 type UserTeamIDPair struct {
-	UserID string
-	TeamID string
+	UserID                string
+	TeamID                string
+	SuppressNotifications bool
 }
 
 type UserChannelIDPair struct {
-	UserID    string
-	ChannelID string
+	UserID                string
+	ChannelID             string
+	SuppressNotifications bool
+	Roles                 string
 }
 
 func GroupSyncableFromJson(data io.Reader) *GroupSyncable {
@@ -156,6 +545,20 @@ func GroupSyncablesFromJson(data io.Reader) []*GroupSyncable {
 	return groupSyncables
 }
 
+func GroupSyncablesExpandedFromJson(data io.Reader) []*GroupSyncableExpanded {
+	groupSyncables := []*GroupSyncableExpanded{}
+	bodyBytes, _ := ioutil.ReadAll(data)
+	json.Unmarshal(bodyBytes, &groupSyncables)
+	return groupSyncables
+}
+
+func GroupSyncableStatusesFromJson(data io.Reader) []*GroupSyncableStatus {
+	statuses := []*GroupSyncableStatus{}
+	bodyBytes, _ := ioutil.ReadAll(data)
+	json.Unmarshal(bodyBytes, &statuses)
+	return statuses
+}
+
 func NewGroupTeam(groupID, teamID string, autoAdd bool) *GroupSyncable {
 	return &GroupSyncable{
 		GroupId:    groupID,
@@ -0,0 +1,116 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupChannelPattern(t *testing.T) {
+	t.Run("matches", func(t *testing.T) {
+		for _, tc := range []struct {
+			Pattern string
+			Name    string
+			Matches bool
+		}{
+			{"team-*", "team-alpha", true},
+			{"team-*", "team-", true},
+			{"team-*", "teams-alpha", false},
+			{"team-*", "my-team-alpha", false},
+			{"*-ops", "platform-ops", true},
+			{"team-?", "team-a", true},
+			{"team-?", "team-ab", false},
+			{"team-[ab]*", "team-beta", true},
+			{"team-[ab]*", "team-gamma", false},
+			{"town-square", "town-square", true},
+		} {
+			pattern := &GroupChannelPattern{Pattern: tc.Pattern}
+			assert.Equal(t, tc.Matches, pattern.Matches(tc.Name), "%s against %s", tc.Pattern, tc.Name)
+		}
+	})
+
+	t.Run("literal prefix", func(t *testing.T) {
+		assert.Equal(t, "team-", (&GroupChannelPattern{Pattern: "team-*"}).LiteralPrefix())
+		assert.Equal(t, "team-", (&GroupChannelPattern{Pattern: "team-[ab]*"}).LiteralPrefix())
+		assert.Equal(t, "", (&GroupChannelPattern{Pattern: "*-ops"}).LiteralPrefix())
+		assert.Equal(t, "town-square", (&GroupChannelPattern{Pattern: "town-square"}).LiteralPrefix())
+	})
+
+	t.Run("is valid", func(t *testing.T) {
+		groupId := NewId()
+		assert.Nil(t, (&GroupChannelPattern{GroupId: groupId, Pattern: "team-*"}).IsValid())
+		assert.NotNil(t, (&GroupChannelPattern{GroupId: "junk", Pattern: "team-*"}).IsValid())
+		assert.NotNil(t, (&GroupChannelPattern{GroupId: groupId, Pattern: ""}).IsValid())
+		assert.NotNil(t, (&GroupChannelPattern{GroupId: groupId, Pattern: "team-["}).IsValid())
+		assert.NotNil(t, (&GroupChannelPattern{GroupId: groupId, Pattern: strings.Repeat("a", CHANNEL_NAME_MAX_LENGTH+1)}).IsValid())
+	})
+}
+
+func TestGroupSyncableExpandedJson(t *testing.T) {
+	channelSyncable := NewGroupChannel(NewId(), NewId(), true)
+	channelSyncable.ChannelDisplayName = "Channel"
+	channelSyncable.ChannelName = "channel"
+	channelSyncable.ChannelType = CHANNEL_PRIVATE
+	channelSyncable.TeamID = NewId()
+	channelSyncable.TeamDisplayName = "Team"
+	channelSyncable.TeamName = "team"
+	channelSyncable.TeamType = TEAM_OPEN
+
+	b, err := json.Marshal(NewGroupSyncableExpanded(channelSyncable))
+	require.Nil(t, err)
+
+	var expanded GroupSyncableExpanded
+	require.Nil(t, json.Unmarshal(b, &expanded))
+	assert.Equal(t, channelSyncable.SyncableId, expanded.SyncableId)
+	assert.Equal(t, channelSyncable.GroupId, expanded.GroupId)
+	assert.True(t, expanded.AutoAdd)
+	assert.Equal(t, "Channel", expanded.DisplayName)
+	assert.Equal(t, "channel", expanded.Name)
+	assert.Equal(t, CHANNEL_PRIVATE, expanded.Type)
+	assert.Equal(t, &GroupSyncableTeam{Id: channelSyncable.TeamID, DisplayName: "Team", Name: "team", Type: TEAM_OPEN}, expanded.Team)
+
+	teamSyncable := NewGroupTeam(NewId(), NewId(), false)
+	teamSyncable.TeamDisplayName = "Team"
+	teamSyncable.TeamName = "team"
+
+	b, err = json.Marshal(NewGroupSyncableExpanded(teamSyncable))
+	require.Nil(t, err)
+
+	expanded = GroupSyncableExpanded{}
+	require.Nil(t, json.Unmarshal(b, &expanded))
+	assert.Equal(t, teamSyncable.SyncableId, expanded.SyncableId)
+	assert.Equal(t, GroupSyncableTypeTeam, expanded.GroupSyncable.Type)
+	assert.Equal(t, "Team", expanded.DisplayName)
+	assert.Equal(t, "team", expanded.Name)
+	assert.Empty(t, expanded.Type)
+	assert.Nil(t, expanded.Team)
+}
+
+func TestGroupSyncableExcludeMembers(t *testing.T) {
+	syncable := NewGroupChannel(NewId(), NewId(), false)
+	syncable.Patch(&GroupSyncablePatch{ExcludeMembers: NewBool(true)})
+	require.Nil(t, syncable.IsValid())
+
+	b, err := json.Marshal(syncable)
+	require.Nil(t, err)
+
+	var decoded GroupSyncable
+	require.Nil(t, json.Unmarshal(b, &decoded))
+	assert.True(t, decoded.ExcludeMembers)
+
+	// A link cannot both add and exclude the group's members
+	syncable.AutoAdd = true
+	require.NotNil(t, syncable.IsValid())
+	assert.Equal(t, "model.group_syncable.exclude_members.app_error", syncable.IsValid().Id)
+
+	// Only channel links exclude
+	teamSyncable := NewGroupTeam(NewId(), NewId(), false)
+	teamSyncable.ExcludeMembers = true
+	require.NotNil(t, teamSyncable.IsValid())
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupValidate(t *testing.T) {
+	valid := func() *Group {
+		return &Group{
+			Name:        "name",
+			DisplayName: "display name",
+			Source:      GroupSourceCustom,
+		}
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		assert.Nil(t, valid().Validate())
+		assert.Nil(t, valid().IsValidForCreate())
+	})
+
+	t.Run("reports every invalid field", func(t *testing.T) {
+		group := valid()
+		group.Name = strings.Repeat("a", GroupNameMaxLength+1)
+		group.DisplayName = ""
+		group.Tags = []string{"Not A Tag"}
+
+		validationErr := group.Validate()
+		require.NotNil(t, validationErr)
+		assert.Equal(t, map[string]string{
+			"name":         GroupValidationReasonTooLong,
+			"display_name": GroupValidationReasonRequired,
+			"tags":         GroupValidationReasonInvalid,
+		}, validationErr.Fields())
+
+		// IsValidForCreate keeps reporting the first invalid field
+		appErr := group.IsValidForCreate()
+		require.NotNil(t, appErr)
+		assert.Equal(t, "model.group.name.app_error", appErr.Id)
+	})
+
+	t.Run("converts to a single app error", func(t *testing.T) {
+		group := valid()
+		group.Source = "unknown"
+		group.MembershipWebhookURL = "not a url"
+
+		appErr := group.Validate().ToAppError("Api4.patchGroup")
+		assert.Equal(t, "model.group.source.app_error", appErr.Id)
+		assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+		assert.Equal(t, "Api4.patchGroup", appErr.Where)
+		assert.Equal(t, map[string]string{
+			"source":                 GroupValidationReasonInvalid,
+			"membership_webhook_url": GroupValidationReasonInvalid,
+		}, appErr.params["fields"])
+	})
+
+	t.Run("parent group", func(t *testing.T) {
+		group := valid()
+		group.Id = NewId()
+		group.ParentGroupId = NewId()
+		assert.Nil(t, group.Validate())
+
+		group.ParentGroupId = group.Id
+		require.NotNil(t, group.Validate())
+		assert.Equal(t, map[string]string{"parent_group_id": GroupValidationReasonInvalid}, group.Validate().Fields())
+
+		group.ParentGroupId = "junk"
+		require.NotNil(t, group.Validate())
+	})
+}
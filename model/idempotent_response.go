@@ -0,0 +1,19 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"net/http"
+)
+
+// IdempotentResponse is the response recorded for a write request that carried an Idempotency-Key header, replayed
+// when the same request is retried. RequestHash identifies the request body the key was first used with. Pending is
+// set while the original request is still being processed and no response has been recorded yet.
+type IdempotentResponse struct {
+	RequestHash string
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+	Pending     bool
+}
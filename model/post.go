@@ -52,6 +52,10 @@ const (
 	POST_PROPS_DELETE_BY        = "deleteBy"
 )
 
+// POST_PROPS_CONFIRM_GROUP_MENTION marks a post as confirmed by its author to mention a group larger than
+// GroupSettings.MaxMentionMembers.
+const POST_PROPS_CONFIRM_GROUP_MENTION = "confirm_group_mention"
+
 type Post struct {
 	Id         string `json:"id"`
 	CreateAt   int64  `json:"create_at"`
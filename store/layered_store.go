@@ -195,6 +195,10 @@ func (s *LayeredStore) LinkMetadata() LinkMetadataStore {
 	return s.DatabaseLayer.LinkMetadata()
 }
 
+func (s *LayeredStore) Idempotency() IdempotencyStore {
+	return s.DatabaseLayer.Idempotency()
+}
+
 func (s *LayeredStore) MarkSystemRanUnitTests() {
 	s.DatabaseLayer.MarkSystemRanUnitTests()
 }
@@ -388,9 +392,9 @@ func (s *LayeredGroupStore) GetMemberUsers(groupID string) StoreChannel {
 	})
 }
 
-func (s *LayeredGroupStore) GetMemberUsersPage(groupID string, offset int, limit int) StoreChannel {
+func (s *LayeredGroupStore) GetMemberUsersPage(groupID string, page int, perPage int) StoreChannel {
 	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
-		return supplier.GroupGetMemberUsersPage(s.TmpContext, groupID, offset, limit)
+		return supplier.GroupGetMemberUsersPage(s.TmpContext, groupID, page, perPage)
 	})
 }
 
@@ -466,14 +470,300 @@ func (s *LayeredGroupStore) ChannelMembersToRemove() StoreChannel {
 	})
 }
 
-func (s *LayeredGroupStore) GetGroupsByChannel(channelId string, page, perPage int) StoreChannel {
+func (s *LayeredGroupStore) GetGroupsByChannel(channelId string, page, perPage int, opts model.GroupSearchOpts) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GetGroupsByChannel(s.TmpContext, channelId, page, perPage, opts)
+	})
+}
+
+func (s *LayeredGroupStore) GetGroupsByTeam(teamId string, page, perPage int, opts model.GroupSearchOpts) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GetGroupsByTeam(s.TmpContext, teamId, page, perPage, opts)
+	})
+}
+
+func (s *LayeredGroupStore) GetGroups(page, perPage int, opts model.GroupSearchOpts) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetGroups(s.TmpContext, page, perPage, opts)
+	})
+}
+
+func (s *LayeredGroupStore) UpsertMembers(groupID string, userIDs []string) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupUpsertMembers(s.TmpContext, groupID, userIDs)
+	})
+}
+
+func (s *LayeredGroupStore) DeleteMembers(groupID string, userIDs []string) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupDeleteMembers(s.TmpContext, groupID, userIDs)
+	})
+}
+
+func (s *LayeredGroupStore) MergeGroups(targetID string, sourceID string) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupMergeGroups(s.TmpContext, targetID, sourceID)
+	})
+}
+
+func (s *LayeredGroupStore) GetMembers(groupID string, userIDs []string) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetMembers(s.TmpContext, groupID, userIDs)
+	})
+}
+
+func (s *LayeredGroupStore) UpdateMember(member *model.GroupMember) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupUpdateMember(s.TmpContext, member)
+	})
+}
+
+func (s *LayeredGroupStore) GetGroupsByUserId(userID string) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetGroupsByUserId(s.TmpContext, userID)
+	})
+}
+
+func (s *LayeredGroupStore) InvalidateMembershipCacheForUser(userID string) {
+	s.LocalCacheLayer.InvalidateGroupMembershipCacheForUser(userID)
+}
+
+func (s *LayeredGroupStore) Autocomplete(namePrefix string, limit int) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupAutocomplete(s.TmpContext, namePrefix, limit)
+	})
+}
+
+func (s *LayeredGroupStore) GetDeleteImpact(groupID string) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetDeleteImpact(s.TmpContext, groupID)
+	})
+}
+
+func (s *LayeredGroupStore) GetMemberIds(groupID string, limit int) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetMemberIds(s.TmpContext, groupID, limit)
+	})
+}
+
+func (s *LayeredGroupStore) GetUnusedGroups(page, perPage int, opts model.UnusedGroupSearchOpts) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetUnusedGroups(s.TmpContext, page, perPage, opts)
+	})
+}
+
+func (s *LayeredGroupStore) GetGroupsLastUpdateAt(opts model.GroupSearchOpts) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetGroupsLastUpdateAt(s.TmpContext, opts)
+	})
+}
+
+func (s *LayeredGroupStore) GetExpiredMembers(now int64, limit int) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetExpiredMembers(s.TmpContext, now, limit)
+	})
+}
+
+func (s *LayeredGroupStore) GetMemberUsersExpiringPage(groupID string, before int64, page, perPage int) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetMemberUsersExpiringPage(s.TmpContext, groupID, before, page, perPage)
+	})
+}
+
+func (s *LayeredGroupStore) GetExpiringMemberCount(groupID string, before int64) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetExpiringMemberCount(s.TmpContext, groupID, before)
+	})
+}
+
+func (s *LayeredGroupStore) LogMemberEvents(groupID string, userIDs []string, action string) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupLogMemberEvents(s.TmpContext, groupID, userIDs, action)
+	})
+}
+
+func (s *LayeredGroupStore) GetMemberHistory(groupID string, from, to int64, page, perPage int) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetMemberHistory(s.TmpContext, groupID, from, to, page, perPage)
+	})
+}
+
+func (s *LayeredGroupStore) GetChildGroupIds(parentIDs []string) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetChildGroupIds(s.TmpContext, parentIDs)
+	})
+}
+
+func (s *LayeredGroupStore) GetNestedMemberUsersPage(groupIDs []string, page, perPage int) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetNestedMemberUsersPage(s.TmpContext, groupIDs, page, perPage)
+	})
+}
+
+func (s *LayeredGroupStore) GetNestedMemberCount(groupIDs []string) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetNestedMemberCount(s.TmpContext, groupIDs)
+	})
+}
+
+func (s *LayeredGroupStore) GetMemberTimezones(groupID string) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetMemberTimezones(s.TmpContext, groupID)
+	})
+}
+
+func (s *LayeredGroupStore) CreateChannelPattern(pattern *model.GroupChannelPattern) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupCreateChannelPattern(s.TmpContext, pattern)
+	})
+}
+
+func (s *LayeredGroupStore) GetChannelPatterns(groupID string) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetChannelPatterns(s.TmpContext, groupID)
+	})
+}
+
+func (s *LayeredGroupStore) GetAllChannelPatterns() StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetAllChannelPatterns(s.TmpContext)
+	})
+}
+
+func (s *LayeredGroupStore) GetUnlinkedChannelsCreatedSince(groupID string, namePrefix string, since int64) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetUnlinkedChannelsCreatedSince(s.TmpContext, groupID, namePrefix, since)
+	})
+}
+
+func (s *LayeredGroupStore) GetByIDs(groupIDs []string) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetByIDs(s.TmpContext, groupIDs)
+	})
+}
+
+func (s *LayeredGroupStore) GetMemberCountInChannel(groupID, channelID string) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetMemberCountInChannel(s.TmpContext, groupID, channelID)
+	})
+}
+
+func (s *LayeredGroupStore) GetByNames(names []string) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetByNames(s.TmpContext, names)
+	})
+}
+
+func (s *LayeredGroupStore) GetMemberIdsPage(groupID string, page, perPage int) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetMemberIdsPage(s.TmpContext, groupID, page, perPage)
+	})
+}
+
+func (s *LayeredGroupStore) GetGroupsByMemberEmailDomain(domain string, page, perPage int) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetGroupsByMemberEmailDomain(s.TmpContext, domain, page, perPage)
+	})
+}
+
+func (s *LayeredGroupStore) DeleteAllMembers(groupID string) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupDeleteAllMembers(s.TmpContext, groupID)
+	})
+}
+
+func (s *LayeredGroupStore) GetLinkedSyncableIds(groupIDs []string, syncableIDs []string, syncableType model.GroupSyncableType) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetLinkedSyncableIds(s.TmpContext, groupIDs, syncableIDs, syncableType)
+	})
+}
+
+func (s *LayeredGroupStore) GetExpiredGroupSyncables(syncableType model.GroupSyncableType, now int64, limit int) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetExpiredGroupSyncables(s.TmpContext, syncableType, now, limit)
+	})
+}
+
+func (s *LayeredGroupStore) GetMembersNotInChannel(groupID, channelID string) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetMembersNotInChannel(s.TmpContext, groupID, channelID)
+	})
+}
+
+func (s *LayeredGroupStore) GetGroupsMissingTeamLink(channelID string) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetGroupsMissingTeamLink(s.TmpContext, channelID)
+	})
+}
+
+func (s *LayeredGroupStore) UpdateMetadata(groups []*model.Group) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupUpdateMetadata(s.TmpContext, groups)
+	})
+}
+
+func (s *LayeredGroupStore) GetIntersectionMemberUsersPage(groupIDs []string, page, perPage int) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetIntersectionMemberUsersPage(s.TmpContext, groupIDs, page, perPage)
+	})
+}
+
+func (s *LayeredGroupStore) GetIntersectionMemberCount(groupIDs []string) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetIntersectionMemberCount(s.TmpContext, groupIDs)
+	})
+}
+
+func (s *LayeredGroupStore) SetLastSyncAt(groupID string, lastSyncAt int64) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupSetLastSyncAt(s.TmpContext, groupID, lastSyncAt)
+	})
+}
+
+func (s *LayeredGroupStore) GetStaleLdapGroups(since int64, page, perPage int) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetStaleLdapGroups(s.TmpContext, since, page, perPage)
+	})
+}
+
+func (s *LayeredGroupStore) GetUngroupedUsers(page, perPage int) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetUngroupedUsers(s.TmpContext, page, perPage)
+	})
+}
+
+func (s *LayeredGroupStore) GetUngroupedUserCount() StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetUngroupedUserCount(s.TmpContext)
+	})
+}
+
+func (s *LayeredGroupStore) CreateChannelExemption(exemption *model.GroupChannelExemption) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupCreateChannelExemption(s.TmpContext, exemption)
+	})
+}
+
+func (s *LayeredGroupStore) GroupSyncableExists(groupID string, syncableID string, syncableType model.GroupSyncableType) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGroupSyncableExists(s.TmpContext, groupID, syncableID, syncableType)
+	})
+}
+
+func (s *LayeredGroupStore) GetStats(groupID string, includeDeleted bool) StoreChannel {
+	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
+		return supplier.GroupGetStats(s.TmpContext, groupID, includeDeleted)
+	})
+}
+
+func (s *LayeredGroupStore) GetTeamGroupSyncables(teamID string, page int, perPage int) StoreChannel {
 	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
-		return supplier.GetGroupsByChannel(s.TmpContext, channelId, page, perPage)
+		return supplier.GroupGetTeamGroupSyncables(s.TmpContext, teamID, page, perPage)
 	})
 }
 
-func (s *LayeredGroupStore) GetGroupsByTeam(teamId string, page, perPage int) StoreChannel {
+func (s *LayeredGroupStore) GetChannelMembershipConflicts(channelID string) StoreChannel {
 	return s.RunQuery(func(supplier LayeredStoreSupplier) *LayeredStoreSupplierResult {
-		return supplier.GetGroupsByTeam(s.TmpContext, teamId, page, perPage)
+		return supplier.GroupGetChannelMembershipConflicts(s.TmpContext, channelID)
 	})
 }
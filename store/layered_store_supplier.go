@@ -57,7 +57,7 @@ type LayeredStoreSupplier interface {
 	GroupDelete(ctx context.Context, groupID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
 
 	GroupGetMemberUsers(ctx context.Context, groupID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
-	GroupGetMemberUsersPage(ctx context.Context, groupID string, offset int, limit int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetMemberUsersPage(ctx context.Context, groupID string, page int, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
 	GroupGetMemberCount(ctx context.Context, groupID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
 	GroupCreateOrRestoreMember(ctx context.Context, groupID string, userID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
 	GroupDeleteMember(ctx context.Context, groupID string, userID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
@@ -74,6 +74,53 @@ type LayeredStoreSupplier interface {
 	TeamMembersToRemove(ctx context.Context, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
 	ChannelMembersToRemove(ctx context.Context, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
 
-	GetGroupsByChannel(ctx context.Context, channelId string, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
-	GetGroupsByTeam(ctx context.Context, teamId string, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GetGroupsByChannel(ctx context.Context, channelId string, page, perPage int, opts model.GroupSearchOpts, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GetGroupsByTeam(ctx context.Context, teamId string, page, perPage int, opts model.GroupSearchOpts, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetGroups(ctx context.Context, page, perPage int, opts model.GroupSearchOpts, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupUpsertMembers(ctx context.Context, groupID string, userIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupDeleteMembers(ctx context.Context, groupID string, userIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupMergeGroups(ctx context.Context, targetID string, sourceID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetMembers(ctx context.Context, groupID string, userIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupUpdateMember(ctx context.Context, member *model.GroupMember, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetGroupsByUserId(ctx context.Context, userID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupAutocomplete(ctx context.Context, namePrefix string, limit int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetDeleteImpact(ctx context.Context, groupID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetMemberIds(ctx context.Context, groupID string, limit int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetUnusedGroups(ctx context.Context, page, perPage int, opts model.UnusedGroupSearchOpts, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetGroupsLastUpdateAt(ctx context.Context, opts model.GroupSearchOpts, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetExpiredMembers(ctx context.Context, now int64, limit int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetMemberUsersExpiringPage(ctx context.Context, groupID string, before int64, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetExpiringMemberCount(ctx context.Context, groupID string, before int64, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupLogMemberEvents(ctx context.Context, groupID string, userIDs []string, action string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetMemberHistory(ctx context.Context, groupID string, from, to int64, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetChildGroupIds(ctx context.Context, parentIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetNestedMemberUsersPage(ctx context.Context, groupIDs []string, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetNestedMemberCount(ctx context.Context, groupIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetMemberTimezones(ctx context.Context, groupID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupCreateChannelPattern(ctx context.Context, pattern *model.GroupChannelPattern, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetChannelPatterns(ctx context.Context, groupID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetAllChannelPatterns(ctx context.Context, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetUnlinkedChannelsCreatedSince(ctx context.Context, groupID string, namePrefix string, since int64, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetByIDs(ctx context.Context, groupIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetMemberCountInChannel(ctx context.Context, groupID, channelID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetByNames(ctx context.Context, names []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetMemberIdsPage(ctx context.Context, groupID string, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetGroupsByMemberEmailDomain(ctx context.Context, domain string, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupDeleteAllMembers(ctx context.Context, groupID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetLinkedSyncableIds(ctx context.Context, groupIDs []string, syncableIDs []string, syncableType model.GroupSyncableType, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetExpiredGroupSyncables(ctx context.Context, syncableType model.GroupSyncableType, now int64, limit int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetMembersNotInChannel(ctx context.Context, groupID, channelID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetGroupsMissingTeamLink(ctx context.Context, channelID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupUpdateMetadata(ctx context.Context, groups []*model.Group, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetIntersectionMemberUsersPage(ctx context.Context, groupIDs []string, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetIntersectionMemberCount(ctx context.Context, groupIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupSetLastSyncAt(ctx context.Context, groupID string, lastSyncAt int64, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetStaleLdapGroups(ctx context.Context, since int64, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetUngroupedUsers(ctx context.Context, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetUngroupedUserCount(ctx context.Context, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupCreateChannelExemption(ctx context.Context, exemption *model.GroupChannelExemption, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGroupSyncableExists(ctx context.Context, groupID string, syncableID string, syncableType model.GroupSyncableType, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetStats(ctx context.Context, groupID string, includeDeleted bool, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetTeamGroupSyncables(ctx context.Context, teamID string, page int, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
+	GroupGetChannelMembershipConflicts(ctx context.Context, channelID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult
 }
@@ -24,6 +24,9 @@ const (
 	GROUP_CACHE_SIZE = 20000
 	GROUP_CACHE_SEC  = 30 * 60
 
+	GROUP_MEMBERSHIP_CACHE_SIZE = 20000
+	GROUP_MEMBERSHIP_CACHE_SEC  = 30 * 60
+
 	CLEAR_CACHE_MESSAGE_DATA = ""
 )
 
@@ -35,6 +38,9 @@ type LocalCacheSupplier struct {
 	metrics       einterfaces.MetricsInterface
 	cluster       einterfaces.ClusterInterface
 	groupCache    *utils.Cache
+
+	// groupMembershipCache holds the groups each user is an active member of, keyed by user id.
+	groupMembershipCache *utils.Cache
 }
 
 // Caching Interface
@@ -51,18 +57,20 @@ type ObjectCache interface {
 
 func NewLocalCacheSupplier(metrics einterfaces.MetricsInterface, cluster einterfaces.ClusterInterface) *LocalCacheSupplier {
 	supplier := &LocalCacheSupplier{
-		reactionCache: utils.NewLruWithParams(REACTION_CACHE_SIZE, "Reaction", REACTION_CACHE_SEC, model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_REACTIONS),
-		roleCache:     utils.NewLruWithParams(ROLE_CACHE_SIZE, "Role", ROLE_CACHE_SEC, model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_ROLES),
-		schemeCache:   utils.NewLruWithParams(SCHEME_CACHE_SIZE, "Scheme", SCHEME_CACHE_SEC, model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_SCHEMES),
-		groupCache:    utils.NewLruWithParams(GROUP_CACHE_SIZE, "Group", GROUP_CACHE_SEC, model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_GROUPS),
-		metrics:       metrics,
-		cluster:       cluster,
+		reactionCache:        utils.NewLruWithParams(REACTION_CACHE_SIZE, "Reaction", REACTION_CACHE_SEC, model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_REACTIONS),
+		roleCache:            utils.NewLruWithParams(ROLE_CACHE_SIZE, "Role", ROLE_CACHE_SEC, model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_ROLES),
+		schemeCache:          utils.NewLruWithParams(SCHEME_CACHE_SIZE, "Scheme", SCHEME_CACHE_SEC, model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_SCHEMES),
+		groupCache:           utils.NewLruWithParams(GROUP_CACHE_SIZE, "Group", GROUP_CACHE_SEC, model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_GROUPS),
+		groupMembershipCache: utils.NewLruWithParams(GROUP_MEMBERSHIP_CACHE_SIZE, "GroupMembership", GROUP_MEMBERSHIP_CACHE_SEC, model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_GROUP_MEMBERSHIPS),
+		metrics:              metrics,
+		cluster:              cluster,
 	}
 
 	if cluster != nil {
 		cluster.RegisterClusterMessageHandler(model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_REACTIONS, supplier.handleClusterInvalidateReaction)
 		cluster.RegisterClusterMessageHandler(model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_ROLES, supplier.handleClusterInvalidateRole)
 		cluster.RegisterClusterMessageHandler(model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_GROUPS, supplier.handleClusterInvalidateGroup)
+		cluster.RegisterClusterMessageHandler(model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_GROUP_MEMBERSHIPS, supplier.handleClusterInvalidateGroupMembership)
 	}
 
 	return supplier
@@ -134,4 +142,5 @@ func (s *LocalCacheSupplier) Invalidate() {
 	s.doClearCacheCluster(s.reactionCache)
 	s.doClearCacheCluster(s.roleCache)
 	s.doClearCacheCluster(s.schemeCache)
+	s.doClearCacheCluster(s.groupMembershipCache)
 }
@@ -17,6 +17,26 @@ func (s *LocalCacheSupplier) handleClusterInvalidateGroup(msg *model.ClusterMess
 	}
 }
 
+func (s *LocalCacheSupplier) handleClusterInvalidateGroupMembership(msg *model.ClusterMessage) {
+	if msg.Data == CLEAR_CACHE_MESSAGE_DATA {
+		s.groupMembershipCache.Purge()
+	} else {
+		s.groupMembershipCache.Remove(msg.Data)
+	}
+}
+
+// InvalidateGroupMembershipCacheForUser removes the cached groups of a user on this and every other node in the
+// cluster.
+func (s *LocalCacheSupplier) InvalidateGroupMembershipCacheForUser(userID string) {
+	s.doInvalidateCacheCluster(s.groupMembershipCache, userID)
+}
+
+func (s *LocalCacheSupplier) invalidateGroupMembershipCacheForUsers(userIDs []string) {
+	for _, userID := range userIDs {
+		s.InvalidateGroupMembershipCacheForUser(userID)
+	}
+}
+
 func (s *LocalCacheSupplier) GroupCreate(ctx context.Context, group *model.Group, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
 	return s.Next().GroupCreate(ctx, group, hints...)
 }
@@ -43,12 +63,15 @@ func (s *LocalCacheSupplier) GroupGetAllBySource(ctx context.Context, groupSourc
 
 func (s *LocalCacheSupplier) GroupUpdate(ctx context.Context, group *model.Group, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
 	defer s.doInvalidateCacheCluster(s.groupCache, group.Id)
+	defer s.doClearCacheCluster(s.groupMembershipCache)
+
 	return s.Next().GroupUpdate(ctx, group, hints...)
 }
 
 func (s *LocalCacheSupplier) GroupDelete(ctx context.Context, groupID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
 	defer s.doInvalidateCacheCluster(s.groupCache, groupID)
 	defer s.doClearCacheCluster(s.groupCache)
+	defer s.doClearCacheCluster(s.groupMembershipCache)
 
 	return s.Next().GroupDelete(ctx, groupID, hints...)
 }
@@ -57,8 +80,8 @@ func (s *LocalCacheSupplier) GroupGetMemberUsers(ctx context.Context, groupID st
 	return s.Next().GroupGetMemberUsers(ctx, groupID, hints...)
 }
 
-func (s *LocalCacheSupplier) GroupGetMemberUsersPage(ctx context.Context, groupID string, offset int, limit int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
-	return s.Next().GroupGetMemberUsersPage(ctx, groupID, offset, limit, hints...)
+func (s *LocalCacheSupplier) GroupGetMemberUsersPage(ctx context.Context, groupID string, page int, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetMemberUsersPage(ctx, groupID, page, perPage, hints...)
 }
 
 func (s *LocalCacheSupplier) GroupGetMemberCount(ctx context.Context, groupID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
@@ -66,10 +89,12 @@ func (s *LocalCacheSupplier) GroupGetMemberCount(ctx context.Context, groupID st
 }
 
 func (s *LocalCacheSupplier) GroupCreateOrRestoreMember(ctx context.Context, groupID string, userID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	defer s.InvalidateGroupMembershipCacheForUser(userID)
 	return s.Next().GroupCreateOrRestoreMember(ctx, groupID, userID, hints...)
 }
 
 func (s *LocalCacheSupplier) GroupDeleteMember(ctx context.Context, groupID string, userID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	defer s.InvalidateGroupMembershipCacheForUser(userID)
 	return s.Next().GroupDeleteMember(ctx, groupID, userID, hints...)
 }
 
@@ -109,10 +134,222 @@ func (s *LocalCacheSupplier) ChannelMembersToRemove(ctx context.Context, hints .
 	return s.Next().ChannelMembersToRemove(ctx, hints...)
 }
 
-func (s *LocalCacheSupplier) GetGroupsByChannel(ctx context.Context, channelId string, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
-	return s.Next().GetGroupsByChannel(ctx, channelId, page, perPage, hints...)
+func (s *LocalCacheSupplier) GetGroupsByChannel(ctx context.Context, channelId string, page, perPage int, opts model.GroupSearchOpts, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GetGroupsByChannel(ctx, channelId, page, perPage, opts, hints...)
+}
+
+func (s *LocalCacheSupplier) GetGroupsByTeam(ctx context.Context, teamId string, page, perPage int, opts model.GroupSearchOpts, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GetGroupsByTeam(ctx, teamId, page, perPage, opts, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetGroups(ctx context.Context, page, perPage int, opts model.GroupSearchOpts, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetGroups(ctx, page, perPage, opts, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupUpsertMembers(ctx context.Context, groupID string, userIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	defer s.invalidateGroupMembershipCacheForUsers(userIDs)
+	return s.Next().GroupUpsertMembers(ctx, groupID, userIDs, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupDeleteMembers(ctx context.Context, groupID string, userIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	defer s.invalidateGroupMembershipCacheForUsers(userIDs)
+	return s.Next().GroupDeleteMembers(ctx, groupID, userIDs, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupMergeGroups(ctx context.Context, targetID string, sourceID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	defer s.doClearCacheCluster(s.groupMembershipCache)
+	return s.Next().GroupMergeGroups(ctx, targetID, sourceID, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetMembers(ctx context.Context, groupID string, userIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetMembers(ctx, groupID, userIDs, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupUpdateMember(ctx context.Context, member *model.GroupMember, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupUpdateMember(ctx, member, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetGroupsByUserId(ctx context.Context, userID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	if result := s.doStandardReadCache(ctx, s.groupMembershipCache, userID, hints...); result != nil {
+		return result
+	}
+
+	result := s.Next().GroupGetGroupsByUserId(ctx, userID, hints...)
+
+	s.doStandardAddToCache(ctx, s.groupMembershipCache, userID, result, hints...)
+
+	return result
+}
+
+func (s *LocalCacheSupplier) GroupAutocomplete(ctx context.Context, namePrefix string, limit int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupAutocomplete(ctx, namePrefix, limit, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetDeleteImpact(ctx context.Context, groupID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetDeleteImpact(ctx, groupID, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetMemberIds(ctx context.Context, groupID string, limit int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetMemberIds(ctx, groupID, limit, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetUnusedGroups(ctx context.Context, page, perPage int, opts model.UnusedGroupSearchOpts, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetUnusedGroups(ctx, page, perPage, opts, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetGroupsLastUpdateAt(ctx context.Context, opts model.GroupSearchOpts, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetGroupsLastUpdateAt(ctx, opts, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetExpiredMembers(ctx context.Context, now int64, limit int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetExpiredMembers(ctx, now, limit, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetMemberUsersExpiringPage(ctx context.Context, groupID string, before int64, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetMemberUsersExpiringPage(ctx, groupID, before, page, perPage, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetExpiringMemberCount(ctx context.Context, groupID string, before int64, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetExpiringMemberCount(ctx, groupID, before, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupLogMemberEvents(ctx context.Context, groupID string, userIDs []string, action string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupLogMemberEvents(ctx, groupID, userIDs, action, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetMemberHistory(ctx context.Context, groupID string, from, to int64, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetMemberHistory(ctx, groupID, from, to, page, perPage, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetChildGroupIds(ctx context.Context, parentIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetChildGroupIds(ctx, parentIDs, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetNestedMemberUsersPage(ctx context.Context, groupIDs []string, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetNestedMemberUsersPage(ctx, groupIDs, page, perPage, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetNestedMemberCount(ctx context.Context, groupIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetNestedMemberCount(ctx, groupIDs, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetMemberTimezones(ctx context.Context, groupID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetMemberTimezones(ctx, groupID, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupCreateChannelPattern(ctx context.Context, pattern *model.GroupChannelPattern, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupCreateChannelPattern(ctx, pattern, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetChannelPatterns(ctx context.Context, groupID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetChannelPatterns(ctx, groupID, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetAllChannelPatterns(ctx context.Context, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetAllChannelPatterns(ctx, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetUnlinkedChannelsCreatedSince(ctx context.Context, groupID string, namePrefix string, since int64, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetUnlinkedChannelsCreatedSince(ctx, groupID, namePrefix, since, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetByIDs(ctx context.Context, groupIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetByIDs(ctx, groupIDs, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetMemberCountInChannel(ctx context.Context, groupID, channelID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetMemberCountInChannel(ctx, groupID, channelID, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetByNames(ctx context.Context, names []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetByNames(ctx, names, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetMemberIdsPage(ctx context.Context, groupID string, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetMemberIdsPage(ctx, groupID, page, perPage, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetGroupsByMemberEmailDomain(ctx context.Context, domain string, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetGroupsByMemberEmailDomain(ctx, domain, page, perPage, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupDeleteAllMembers(ctx context.Context, groupID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	result := s.Next().GroupDeleteAllMembers(ctx, groupID, hints...)
+	if result.Err == nil {
+		s.invalidateGroupMembershipCacheForUsers(result.Data.([]string))
+	}
+	return result
+}
+
+func (s *LocalCacheSupplier) GroupGetLinkedSyncableIds(ctx context.Context, groupIDs []string, syncableIDs []string, syncableType model.GroupSyncableType, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetLinkedSyncableIds(ctx, groupIDs, syncableIDs, syncableType, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetExpiredGroupSyncables(ctx context.Context, syncableType model.GroupSyncableType, now int64, limit int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetExpiredGroupSyncables(ctx, syncableType, now, limit, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetMembersNotInChannel(ctx context.Context, groupID, channelID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetMembersNotInChannel(ctx, groupID, channelID, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetGroupsMissingTeamLink(ctx context.Context, channelID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetGroupsMissingTeamLink(ctx, channelID, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupUpdateMetadata(ctx context.Context, groups []*model.Group, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	defer s.doClearCacheCluster(s.groupMembershipCache)
+	defer func() {
+		for _, group := range groups {
+			s.doInvalidateCacheCluster(s.groupCache, group.Id)
+		}
+	}()
+
+	return s.Next().GroupUpdateMetadata(ctx, groups, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetIntersectionMemberUsersPage(ctx context.Context, groupIDs []string, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetIntersectionMemberUsersPage(ctx, groupIDs, page, perPage, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetIntersectionMemberCount(ctx context.Context, groupIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetIntersectionMemberCount(ctx, groupIDs, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupSetLastSyncAt(ctx context.Context, groupID string, lastSyncAt int64, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	defer s.doInvalidateCacheCluster(s.groupCache, groupID)
+
+	return s.Next().GroupSetLastSyncAt(ctx, groupID, lastSyncAt, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetStaleLdapGroups(ctx context.Context, since int64, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetStaleLdapGroups(ctx, since, page, perPage, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetUngroupedUsers(ctx context.Context, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetUngroupedUsers(ctx, page, perPage, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetUngroupedUserCount(ctx context.Context, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetUngroupedUserCount(ctx, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupCreateChannelExemption(ctx context.Context, exemption *model.GroupChannelExemption, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupCreateChannelExemption(ctx, exemption, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGroupSyncableExists(ctx context.Context, groupID string, syncableID string, syncableType model.GroupSyncableType, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGroupSyncableExists(ctx, groupID, syncableID, syncableType, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetStats(ctx context.Context, groupID string, includeDeleted bool, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetStats(ctx, groupID, includeDeleted, hints...)
+}
+
+func (s *LocalCacheSupplier) GroupGetTeamGroupSyncables(ctx context.Context, teamID string, page int, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetTeamGroupSyncables(ctx, teamID, page, perPage, hints...)
 }
 
-func (s *LocalCacheSupplier) GetGroupsByTeam(ctx context.Context, teamId string, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
-	return s.Next().GetGroupsByTeam(ctx, teamId, page, perPage, hints...)
+func (s *LocalCacheSupplier) GroupGetChannelMembershipConflicts(ctx context.Context, channelID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	return s.Next().GroupGetChannelMembershipConflicts(ctx, channelID, hints...)
 }
@@ -44,9 +44,9 @@ func (s *RedisSupplier) GroupGetMemberUsers(ctx context.Context, groupID string,
 	return s.Next().GroupGetMemberUsers(ctx, groupID, hints...)
 }
 
-func (s *RedisSupplier) GroupGetMemberUsersPage(ctx context.Context, groupID string, offset int, limit int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+func (s *RedisSupplier) GroupGetMemberUsersPage(ctx context.Context, groupID string, page int, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
 	// TODO: Redis caching.
-	return s.Next().GroupGetMemberUsersPage(ctx, groupID, offset, limit, hints...)
+	return s.Next().GroupGetMemberUsersPage(ctx, groupID, page, perPage, hints...)
 }
 
 func (s *RedisSupplier) GroupGetMemberCount(ctx context.Context, groupID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
@@ -109,12 +109,247 @@ func (s *RedisSupplier) ChannelMembersToRemove(ctx context.Context, hints ...Lay
 	return s.Next().ChannelMembersToRemove(ctx, hints...)
 }
 
-func (s *RedisSupplier) GetGroupsByChannel(ctx context.Context, channelId string, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+func (s *RedisSupplier) GetGroupsByChannel(ctx context.Context, channelId string, page, perPage int, opts model.GroupSearchOpts, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
 	// TODO: Redis caching.
-	return s.Next().GetGroupsByChannel(ctx, channelId, page, perPage, hints...)
+	return s.Next().GetGroupsByChannel(ctx, channelId, page, perPage, opts, hints...)
 }
 
-func (s *RedisSupplier) GetGroupsByTeam(ctx context.Context, teamId string, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+func (s *RedisSupplier) GetGroupsByTeam(ctx context.Context, teamId string, page, perPage int, opts model.GroupSearchOpts, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
 	// TODO: Redis caching.
-	return s.Next().GetGroupsByTeam(ctx, teamId, page, perPage, hints...)
+	return s.Next().GetGroupsByTeam(ctx, teamId, page, perPage, opts, hints...)
+}
+
+func (s *RedisSupplier) GroupGetGroups(ctx context.Context, page, perPage int, opts model.GroupSearchOpts, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetGroups(ctx, page, perPage, opts, hints...)
+}
+
+func (s *RedisSupplier) GroupUpsertMembers(ctx context.Context, groupID string, userIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupUpsertMembers(ctx, groupID, userIDs, hints...)
+}
+
+func (s *RedisSupplier) GroupDeleteMembers(ctx context.Context, groupID string, userIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupDeleteMembers(ctx, groupID, userIDs, hints...)
+}
+
+func (s *RedisSupplier) GroupMergeGroups(ctx context.Context, targetID string, sourceID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupMergeGroups(ctx, targetID, sourceID, hints...)
+}
+
+func (s *RedisSupplier) GroupGetMembers(ctx context.Context, groupID string, userIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetMembers(ctx, groupID, userIDs, hints...)
+}
+
+func (s *RedisSupplier) GroupUpdateMember(ctx context.Context, member *model.GroupMember, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupUpdateMember(ctx, member, hints...)
+}
+
+func (s *RedisSupplier) GroupGetGroupsByUserId(ctx context.Context, userID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetGroupsByUserId(ctx, userID, hints...)
+}
+
+func (s *RedisSupplier) GroupAutocomplete(ctx context.Context, namePrefix string, limit int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupAutocomplete(ctx, namePrefix, limit, hints...)
+}
+
+func (s *RedisSupplier) GroupGetDeleteImpact(ctx context.Context, groupID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetDeleteImpact(ctx, groupID, hints...)
+}
+
+func (s *RedisSupplier) GroupGetMemberIds(ctx context.Context, groupID string, limit int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetMemberIds(ctx, groupID, limit, hints...)
+}
+
+func (s *RedisSupplier) GroupGetUnusedGroups(ctx context.Context, page, perPage int, opts model.UnusedGroupSearchOpts, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetUnusedGroups(ctx, page, perPage, opts, hints...)
+}
+
+func (s *RedisSupplier) GroupGetGroupsLastUpdateAt(ctx context.Context, opts model.GroupSearchOpts, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetGroupsLastUpdateAt(ctx, opts, hints...)
+}
+
+func (s *RedisSupplier) GroupGetExpiredMembers(ctx context.Context, now int64, limit int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetExpiredMembers(ctx, now, limit, hints...)
+}
+
+func (s *RedisSupplier) GroupGetMemberUsersExpiringPage(ctx context.Context, groupID string, before int64, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetMemberUsersExpiringPage(ctx, groupID, before, page, perPage, hints...)
+}
+
+func (s *RedisSupplier) GroupGetExpiringMemberCount(ctx context.Context, groupID string, before int64, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetExpiringMemberCount(ctx, groupID, before, hints...)
+}
+
+func (s *RedisSupplier) GroupLogMemberEvents(ctx context.Context, groupID string, userIDs []string, action string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupLogMemberEvents(ctx, groupID, userIDs, action, hints...)
+}
+
+func (s *RedisSupplier) GroupGetMemberHistory(ctx context.Context, groupID string, from, to int64, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetMemberHistory(ctx, groupID, from, to, page, perPage, hints...)
+}
+
+func (s *RedisSupplier) GroupGetChildGroupIds(ctx context.Context, parentIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetChildGroupIds(ctx, parentIDs, hints...)
+}
+
+func (s *RedisSupplier) GroupGetNestedMemberUsersPage(ctx context.Context, groupIDs []string, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetNestedMemberUsersPage(ctx, groupIDs, page, perPage, hints...)
+}
+
+func (s *RedisSupplier) GroupGetNestedMemberCount(ctx context.Context, groupIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetNestedMemberCount(ctx, groupIDs, hints...)
+}
+
+func (s *RedisSupplier) GroupGetMemberTimezones(ctx context.Context, groupID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetMemberTimezones(ctx, groupID, hints...)
+}
+
+func (s *RedisSupplier) GroupCreateChannelPattern(ctx context.Context, pattern *model.GroupChannelPattern, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupCreateChannelPattern(ctx, pattern, hints...)
+}
+
+func (s *RedisSupplier) GroupGetChannelPatterns(ctx context.Context, groupID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetChannelPatterns(ctx, groupID, hints...)
+}
+
+func (s *RedisSupplier) GroupGetAllChannelPatterns(ctx context.Context, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetAllChannelPatterns(ctx, hints...)
+}
+
+func (s *RedisSupplier) GroupGetUnlinkedChannelsCreatedSince(ctx context.Context, groupID string, namePrefix string, since int64, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetUnlinkedChannelsCreatedSince(ctx, groupID, namePrefix, since, hints...)
+}
+
+func (s *RedisSupplier) GroupGetByIDs(ctx context.Context, groupIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetByIDs(ctx, groupIDs, hints...)
+}
+
+func (s *RedisSupplier) GroupGetMemberCountInChannel(ctx context.Context, groupID, channelID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetMemberCountInChannel(ctx, groupID, channelID, hints...)
+}
+
+func (s *RedisSupplier) GroupGetByNames(ctx context.Context, names []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetByNames(ctx, names, hints...)
+}
+
+func (s *RedisSupplier) GroupGetMemberIdsPage(ctx context.Context, groupID string, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetMemberIdsPage(ctx, groupID, page, perPage, hints...)
+}
+
+func (s *RedisSupplier) GroupGetGroupsByMemberEmailDomain(ctx context.Context, domain string, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetGroupsByMemberEmailDomain(ctx, domain, page, perPage, hints...)
+}
+
+func (s *RedisSupplier) GroupDeleteAllMembers(ctx context.Context, groupID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupDeleteAllMembers(ctx, groupID, hints...)
+}
+
+func (s *RedisSupplier) GroupGetLinkedSyncableIds(ctx context.Context, groupIDs []string, syncableIDs []string, syncableType model.GroupSyncableType, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetLinkedSyncableIds(ctx, groupIDs, syncableIDs, syncableType, hints...)
+}
+
+func (s *RedisSupplier) GroupGetExpiredGroupSyncables(ctx context.Context, syncableType model.GroupSyncableType, now int64, limit int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetExpiredGroupSyncables(ctx, syncableType, now, limit, hints...)
+}
+
+func (s *RedisSupplier) GroupGetMembersNotInChannel(ctx context.Context, groupID, channelID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetMembersNotInChannel(ctx, groupID, channelID, hints...)
+}
+
+func (s *RedisSupplier) GroupGetGroupsMissingTeamLink(ctx context.Context, channelID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetGroupsMissingTeamLink(ctx, channelID, hints...)
+}
+
+func (s *RedisSupplier) GroupUpdateMetadata(ctx context.Context, groups []*model.Group, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupUpdateMetadata(ctx, groups, hints...)
+}
+
+func (s *RedisSupplier) GroupGetIntersectionMemberUsersPage(ctx context.Context, groupIDs []string, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetIntersectionMemberUsersPage(ctx, groupIDs, page, perPage, hints...)
+}
+
+func (s *RedisSupplier) GroupGetIntersectionMemberCount(ctx context.Context, groupIDs []string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetIntersectionMemberCount(ctx, groupIDs, hints...)
+}
+
+func (s *RedisSupplier) GroupSetLastSyncAt(ctx context.Context, groupID string, lastSyncAt int64, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupSetLastSyncAt(ctx, groupID, lastSyncAt, hints...)
+}
+
+func (s *RedisSupplier) GroupGetStaleLdapGroups(ctx context.Context, since int64, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetStaleLdapGroups(ctx, since, page, perPage, hints...)
+}
+
+func (s *RedisSupplier) GroupGetUngroupedUsers(ctx context.Context, page, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetUngroupedUsers(ctx, page, perPage, hints...)
+}
+
+func (s *RedisSupplier) GroupGetUngroupedUserCount(ctx context.Context, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetUngroupedUserCount(ctx, hints...)
+}
+
+func (s *RedisSupplier) GroupCreateChannelExemption(ctx context.Context, exemption *model.GroupChannelExemption, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupCreateChannelExemption(ctx, exemption, hints...)
+}
+
+func (s *RedisSupplier) GroupGroupSyncableExists(ctx context.Context, groupID string, syncableID string, syncableType model.GroupSyncableType, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGroupSyncableExists(ctx, groupID, syncableID, syncableType, hints...)
+}
+
+func (s *RedisSupplier) GroupGetStats(ctx context.Context, groupID string, includeDeleted bool, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetStats(ctx, groupID, includeDeleted, hints...)
+}
+
+func (s *RedisSupplier) GroupGetTeamGroupSyncables(ctx context.Context, teamID string, page int, perPage int, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetTeamGroupSyncables(ctx, teamID, page, perPage, hints...)
+}
+
+func (s *RedisSupplier) GroupGetChannelMembershipConflicts(ctx context.Context, channelID string, hints ...LayeredStoreHint) *LayeredStoreSupplierResult {
+	// TODO: Redis caching.
+	return s.Next().GroupGetChannelMembershipConflicts(ctx, channelID, hints...)
 }
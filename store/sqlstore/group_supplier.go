@@ -8,11 +8,23 @@ import (
 	"database/sql"
 	"fmt"
 	"net/http"
+	"strings"
 
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/gorp"
+	"golang.org/x/text/language"
+
+	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/store"
 )
 
+// GROUP_MEMBERS_BATCH_SIZE is the number of users handled per query when adding or removing group members in bulk.
+const GROUP_MEMBERS_BATCH_SIZE = 500
+
+// GROUP_METADATA_BATCH_SIZE is the number of groups updated per query by GroupUpdateMetadata.
+const GROUP_METADATA_BATCH_SIZE = 500
+
 type groupTeam struct {
 	model.GroupSyncable
 	TeamId string `db:"TeamId"`
@@ -26,16 +38,38 @@ type groupChannel struct {
 type groupTeamJoin struct {
 	groupTeam
 	TeamDisplayName string `db:"TeamDisplayName"`
+	TeamName        string `db:"TeamName"`
 	TeamType        string `db:"TeamType"`
 }
 
+// groupTeamGroupJoin is a group's link to a team along with the display name of the group.
+type groupTeamGroupJoin struct {
+	groupTeam
+	GroupDisplayName string `db:"GroupDisplayName"`
+}
+
+// groupViaTeam is a group linked to a channel, either directly or through the channel's team.
+type groupViaTeam struct {
+	model.Group
+	IsViaTeam bool `db:"ViaTeam"`
+}
+
+// groupViaChannel is a group linked to a team, either directly or through one of the team's channels.
+type groupViaChannel struct {
+	model.Group
+	IsViaChannel bool `db:"ViaChannel"`
+}
+
 type groupChannelJoin struct {
 	groupChannel
 	ChannelDisplayName string `db:"ChannelDisplayName"`
+	ChannelName        string `db:"ChannelName"`
 	TeamDisplayName    string `db:"TeamDisplayName"`
+	TeamName           string `db:"TeamName"`
 	TeamType           string `db:"TeamType"`
 	ChannelType        string `db:"ChannelType"`
 	TeamID             string `db:"TeamId"`
+	ChannelDeleteAt    int64  `db:"ChannelDeleteAt"`
 }
 
 func initSqlSupplierGroups(sqlStore SqlStore) {
@@ -43,23 +77,46 @@ func initSqlSupplierGroups(sqlStore SqlStore) {
 		groups := db.AddTableWithName(model.Group{}, "UserGroups").SetKeys(false, "Id")
 		groups.ColMap("Id").SetMaxSize(26)
 		groups.ColMap("Name").SetMaxSize(model.GroupNameMaxLength).SetUnique(true)
+		groups.ColMap("NameLower").SetMaxSize(model.GroupNameMaxLength)
 		groups.ColMap("DisplayName").SetMaxSize(model.GroupDisplayNameMaxLength)
 		groups.ColMap("Description").SetMaxSize(model.GroupDescriptionMaxLength)
 		groups.ColMap("Source").SetMaxSize(model.GroupSourceMaxLength)
 		groups.ColMap("RemoteId").SetMaxSize(model.GroupRemoteIDMaxLength)
+		groups.ColMap("Tags").SetMaxSize(2048)
+		groups.ColMap("MembershipWebhookURL").SetMaxSize(model.GroupMembershipWebhookURLMaxLength)
+		groups.ColMap("ParentGroupId").SetMaxSize(26)
+		groups.ColMap("OwnerId").SetMaxSize(26)
 		groups.SetUniqueTogether("Source", "RemoteId")
 
 		groupMembers := db.AddTableWithName(model.GroupMember{}, "GroupMembers").SetKeys(false, "GroupId", "UserId")
 		groupMembers.ColMap("GroupId").SetMaxSize(26)
 		groupMembers.ColMap("UserId").SetMaxSize(26)
+		groupMembers.ColMap("Roles").SetMaxSize(model.GroupMemberRolesMaxLength)
+		groupMembers.ColMap("Source").SetMaxSize(model.GroupMemberSourceMaxLength)
 
 		groupTeams := db.AddTableWithName(groupTeam{}, "GroupTeams").SetKeys(false, "GroupId", "TeamId")
 		groupTeams.ColMap("GroupId").SetMaxSize(26)
 		groupTeams.ColMap("TeamId").SetMaxSize(26)
+		groupTeams.ColMap("Roles").SetMaxSize(model.GroupSyncableRolesMaxLength)
 
 		groupChannels := db.AddTableWithName(groupChannel{}, "GroupChannels").SetKeys(false, "GroupId", "ChannelId")
 		groupChannels.ColMap("GroupId").SetMaxSize(26)
 		groupChannels.ColMap("ChannelId").SetMaxSize(26)
+		groupChannels.ColMap("Roles").SetMaxSize(model.GroupSyncableRolesMaxLength)
+
+		groupMemberHistory := db.AddTableWithName(model.GroupMemberHistoryEvent{}, "GroupMemberHistory").SetKeys(false, "Id")
+		groupMemberHistory.ColMap("Id").SetMaxSize(26)
+		groupMemberHistory.ColMap("GroupId").SetMaxSize(26)
+		groupMemberHistory.ColMap("UserId").SetMaxSize(26)
+		groupMemberHistory.ColMap("Action").SetMaxSize(16)
+
+		groupChannelPatterns := db.AddTableWithName(model.GroupChannelPattern{}, "GroupChannelPatterns").SetKeys(false, "GroupId", "Pattern")
+		groupChannelPatterns.ColMap("GroupId").SetMaxSize(26)
+		groupChannelPatterns.ColMap("Pattern").SetMaxSize(model.CHANNEL_NAME_MAX_LENGTH)
+
+		groupChannelExemptions := db.AddTableWithName(model.GroupChannelExemption{}, "GroupChannelExemptions").SetKeys(false, "ChannelId", "UserId")
+		groupChannelExemptions.ColMap("ChannelId").SetMaxSize(26)
+		groupChannelExemptions.ColMap("UserId").SetMaxSize(26)
 	}
 }
 
@@ -67,6 +124,8 @@ func (s *SqlSupplier) CreateIndexesIfNotExistsGroups() {
 	s.CreateIndexIfNotExists("idx_groupmembers_create_at", "GroupMembers", "CreateAt")
 	s.CreateIndexIfNotExists("idx_usergroups_remote_id", "UserGroups", "RemoteId")
 	s.CreateIndexIfNotExists("idx_usergroups_delete_at", "UserGroups", "DeleteAt")
+	s.CreateIndexIfNotExists("idx_usergroups_name_lower", "UserGroups", "NameLower")
+	s.CreateCompositeIndexIfNotExists("idx_groupmemberhistory_group_id_create_at", "GroupMemberHistory", []string{"GroupId", "CreateAt"})
 }
 
 func (s *SqlSupplier) GroupCreate(ctx context.Context, group *model.Group, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
@@ -82,9 +141,21 @@ func (s *SqlSupplier) GroupCreate(ctx context.Context, group *model.Group, hints
 		return result
 	}
 
+	group.NameLower = strings.ToLower(group.Name)
+	if taken, err := s.groupNameTaken(group.NameLower, ""); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupCreate", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	} else if taken {
+		result.Err = model.NewAppError("SqlGroupStore.GroupCreate", "store.sql_group.unique_constraint", nil, "name="+group.Name, http.StatusInternalServerError)
+		return result
+	}
+
 	group.Id = model.NewId()
 	group.CreateAt = model.GetMillis()
 	group.UpdateAt = group.CreateAt
+	if group.Tags == nil {
+		group.Tags = model.StringArray{}
+	}
 
 	if err := s.GetMaster().Insert(group); err != nil {
 		if IsUniqueConstraintError(err, []string{"Name", "groups_name_key"}) {
@@ -116,6 +187,73 @@ func (s *SqlSupplier) GroupGet(ctx context.Context, groupId string, hints ...sto
 	return result
 }
 
+// GroupGetByIDs returns the undeleted groups with the given ids. Unknown ids are skipped.
+func (s *SqlSupplier) GroupGetByIDs(ctx context.Context, groupIDs []string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	groups := []*model.Group{}
+	if len(groupIDs) == 0 {
+		result.Data = groups
+		return result
+	}
+
+	query, args, err := s.getQueryBuilder().
+		Select("*").
+		From("UserGroups").
+		Where(sq.Eq{"Id": groupIDs, "DeleteAt": 0}).
+		OrderBy("DisplayName", "Id").
+		ToSql()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetByIDs", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	if _, err = s.GetReplica().Select(&groups, query, args...); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetByIDs", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = groups
+
+	return result
+}
+
+// GroupGetByNames returns the undeleted groups with the given names, compared regardless of case.
+func (s *SqlSupplier) GroupGetByNames(ctx context.Context, names []string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	groups := []*model.Group{}
+	if len(names) == 0 {
+		result.Data = groups
+		return result
+	}
+
+	lowerNames := make([]string, 0, len(names))
+	for _, name := range names {
+		lowerNames = append(lowerNames, strings.ToLower(name))
+	}
+
+	query, args, err := s.getQueryBuilder().
+		Select("*").
+		From("UserGroups").
+		Where(sq.Eq{"NameLower": lowerNames, "DeleteAt": 0}).
+		OrderBy("Name").
+		ToSql()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetByNames", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	if _, err = s.GetReplica().Select(&groups, query, args...); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetByNames", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = groups
+
+	return result
+}
+
 func (s *SqlSupplier) GroupGetByRemoteID(ctx context.Context, remoteID string, groupSource model.GroupSource, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
 	result := store.NewSupplierResult()
 
@@ -169,13 +307,28 @@ func (s *SqlSupplier) GroupUpdate(ctx context.Context, group *model.Group, hints
 
 	// Reset these properties, don't update them based on input
 	group.CreateAt = retrievedGroup.CreateAt
+	group.LastSyncAt = retrievedGroup.LastSyncAt
 	group.UpdateAt = model.GetMillis()
+	if group.Tags == nil {
+		group.Tags = model.StringArray{}
+	}
 
 	if err := group.IsValidForUpdate(); err != nil {
 		result.Err = err
 		return result
 	}
 
+	group.NameLower = strings.ToLower(group.Name)
+	if group.NameLower != retrievedGroup.NameLower {
+		if taken, err := s.groupNameTaken(group.NameLower, group.Id); err != nil {
+			result.Err = model.NewAppError("SqlGroupStore.GroupUpdate", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+			return result
+		} else if taken {
+			result.Err = model.NewAppError("SqlGroupStore.GroupUpdate", "store.update_error", nil, "name="+group.Name+" is taken", http.StatusInternalServerError)
+			return result
+		}
+	}
+
 	rowsChanged, err := s.GetMaster().Update(group)
 	if err != nil {
 		result.Err = model.NewAppError("SqlGroupStore.GroupUpdate", "store.update_error", nil, err.Error(), http.StatusInternalServerError)
@@ -190,6 +343,152 @@ func (s *SqlSupplier) GroupUpdate(ctx context.Context, group *model.Group, hints
 	return result
 }
 
+// groupNameTaken reports whether another group, deleted or not, has the same name ignoring case. Names only differing
+// in case are treated as the same so that they cannot be told apart when mentioned or synced.
+func (s *SqlSupplier) groupNameTaken(nameLower, excludeID string) (bool, error) {
+	count, err := s.GetMaster().SelectInt("SELECT COUNT(*) FROM UserGroups WHERE NameLower = :NameLower AND Id != :Id", map[string]interface{}{"NameLower": nameLower, "Id": excludeID})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GroupUpdateMetadata sets the display name and description of many undeleted groups at once, with a single statement
+// per batch of groups. Only the groups whose metadata actually changes are written and have their UpdateAt bumped, which
+// is also set on the given groups. Unknown and deleted groups are skipped. The number of groups changed is returned.
+func (s *SqlSupplier) GroupUpdateMetadata(ctx context.Context, groups []*model.Group, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	for _, group := range groups {
+		if !model.IsValidId(group.Id) {
+			result.Err = model.NewAppError("SqlGroupStore.GroupUpdateMetadata", "model.group.id.app_error", nil, "", http.StatusBadRequest)
+			return result
+		}
+		if l := len(group.DisplayName); l == 0 || l > model.GroupDisplayNameMaxLength {
+			result.Err = model.NewAppError("SqlGroupStore.GroupUpdateMetadata", "model.group.display_name.app_error", map[string]interface{}{"GroupDisplayNameMaxLength": model.GroupDisplayNameMaxLength}, "id="+group.Id, http.StatusBadRequest)
+			return result
+		}
+		if len(group.Description) > model.GroupDescriptionMaxLength {
+			result.Err = model.NewAppError("SqlGroupStore.GroupUpdateMetadata", "model.group.description.app_error", map[string]interface{}{"GroupDescriptionMaxLength": model.GroupDescriptionMaxLength}, "id="+group.Id, http.StatusBadRequest)
+			return result
+		}
+	}
+
+	transaction, err := s.GetMaster().Begin()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupUpdateMetadata", "store.sql_group.update_metadata.open_transaction.app_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+	defer finalizeTransaction(transaction)
+
+	now := model.GetMillis()
+	count := 0
+	for start := 0; start < len(groups); start += GROUP_METADATA_BATCH_SIZE {
+		end := start + GROUP_METADATA_BATCH_SIZE
+		if end > len(groups) {
+			end = len(groups)
+		}
+		batch := groups[start:end]
+
+		ids := make([]string, 0, len(batch))
+		for _, group := range batch {
+			ids = append(ids, group.Id)
+		}
+
+		query, args, err := s.getQueryBuilder().
+			Select("Id", "DisplayName", "Description").
+			From("UserGroups").
+			Where(sq.Eq{"Id": ids, "DeleteAt": 0}).
+			ToSql()
+		if err != nil {
+			result.Err = model.NewAppError("SqlGroupStore.GroupUpdateMetadata", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+			return result
+		}
+
+		var current []*model.Group
+		if _, err = transaction.Select(&current, query, args...); err != nil {
+			result.Err = model.NewAppError("SqlGroupStore.GroupUpdateMetadata", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+			return result
+		}
+
+		currentByID := make(map[string]*model.Group, len(current))
+		for _, group := range current {
+			currentByID[group.Id] = group
+		}
+
+		// A group listed more than once takes the metadata it was given last.
+		changed := map[string]*model.Group{}
+		var changedIDs []string
+		for _, group := range batch {
+			existing, ok := currentByID[group.Id]
+			if !ok {
+				continue
+			}
+			if _, seen := changed[group.Id]; !seen {
+				if existing.DisplayName == group.DisplayName && existing.Description == group.Description {
+					continue
+				}
+				changedIDs = append(changedIDs, group.Id)
+			}
+			changed[group.Id] = group
+		}
+
+		if len(changedIDs) == 0 {
+			continue
+		}
+
+		displayNames := sq.Case("Id")
+		descriptions := sq.Case("Id")
+		for _, id := range changedIDs {
+			displayNames = displayNames.When(sq.Expr("?", id), sq.Expr("?", changed[id].DisplayName))
+			descriptions = descriptions.When(sq.Expr("?", id), sq.Expr("?", changed[id].Description))
+		}
+
+		displayNamesSQL, displayNamesArgs, err := displayNames.ToSql()
+		if err != nil {
+			result.Err = model.NewAppError("SqlGroupStore.GroupUpdateMetadata", "store.update_error", nil, err.Error(), http.StatusInternalServerError)
+			return result
+		}
+		descriptionsSQL, descriptionsArgs, err := descriptions.ToSql()
+		if err != nil {
+			result.Err = model.NewAppError("SqlGroupStore.GroupUpdateMetadata", "store.update_error", nil, err.Error(), http.StatusInternalServerError)
+			return result
+		}
+
+		query, args, err = s.getQueryBuilder().
+			Update("UserGroups").
+			Set("DisplayName", sq.Expr(displayNamesSQL, displayNamesArgs...)).
+			Set("Description", sq.Expr(descriptionsSQL, descriptionsArgs...)).
+			Set("UpdateAt", now).
+			Where(sq.Eq{"Id": changedIDs}).
+			ToSql()
+		if err != nil {
+			result.Err = model.NewAppError("SqlGroupStore.GroupUpdateMetadata", "store.update_error", nil, err.Error(), http.StatusInternalServerError)
+			return result
+		}
+
+		if _, err = transaction.Exec(query, args...); err != nil {
+			result.Err = model.NewAppError("SqlGroupStore.GroupUpdateMetadata", "store.update_error", nil, err.Error(), http.StatusInternalServerError)
+			return result
+		}
+
+		for _, group := range batch {
+			if _, ok := changed[group.Id]; ok {
+				group.UpdateAt = now
+			}
+		}
+		count += len(changedIDs)
+	}
+
+	if err := transaction.Commit(); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupUpdateMetadata", "store.sql_group.update_metadata.commit_transaction.app_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = count
+	return result
+}
+
 func (s *SqlSupplier) GroupDelete(ctx context.Context, groupID string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
 	result := store.NewSupplierResult()
 
@@ -242,7 +541,7 @@ func (s *SqlSupplier) GroupGetMemberUsers(stc context.Context, groupID string, h
 	return result
 }
 
-func (s *SqlSupplier) GroupGetMemberUsersPage(stc context.Context, groupID string, offset int, limit int, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+func (s *SqlSupplier) GroupGetMemberUsersPage(stc context.Context, groupID string, page int, perPage int, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
 	result := store.NewSupplierResult()
 
 	var groupMembers []*model.User
@@ -258,13 +557,13 @@ func (s *SqlSupplier) GroupGetMemberUsersPage(stc context.Context, groupID strin
 			AND Users.DeleteAt = 0
 			AND GroupId = :GroupId
 		ORDER BY
-			GroupMembers.CreateAt DESC
+			Users.Username, Users.Id
 		LIMIT
 			:Limit
 		OFFSET
 			:Offset`
 
-	if _, err := s.GetReplica().Select(&groupMembers, query, map[string]interface{}{"GroupId": groupID, "Limit": limit, "Offset": offset}); err != nil {
+	if _, err := s.GetReplica().Select(&groupMembers, query, map[string]interface{}{"GroupId": groupID, "Limit": perPage, "Offset": page * perPage}); err != nil {
 		result.Err = model.NewAppError("SqlGroupStore.GroupGetMemberUsersPage", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
 		return result
 	}
@@ -274,6 +573,40 @@ func (s *SqlSupplier) GroupGetMemberUsersPage(stc context.Context, groupID strin
 	return result
 }
 
+// GroupGetMemberIdsPage returns the user ids of a page of the group's members, in the same order as
+// GroupGetMemberUsersPage, without reading the users themselves.
+func (s *SqlSupplier) GroupGetMemberIdsPage(ctx context.Context, groupID string, page, perPage int, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	userIDs := []string{}
+
+	query := `
+		SELECT
+			Users.Id
+		FROM
+			GroupMembers
+			JOIN Users ON Users.Id = GroupMembers.UserId
+		WHERE
+			GroupMembers.DeleteAt = 0
+			AND Users.DeleteAt = 0
+			AND GroupId = :GroupId
+		ORDER BY
+			Users.Username, Users.Id
+		LIMIT
+			:Limit
+		OFFSET
+			:Offset`
+
+	if _, err := s.GetReplica().Select(&userIDs, query, map[string]interface{}{"GroupId": groupID, "Limit": perPage, "Offset": page * perPage}); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetMemberIdsPage", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = userIDs
+
+	return result
+}
+
 func (s *SqlSupplier) GroupGetMemberCount(stc context.Context, groupID string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
 	result := store.NewSupplierResult()
 
@@ -298,626 +631,2775 @@ func (s *SqlSupplier) GroupGetMemberCount(stc context.Context, groupID string, h
 	return result
 }
 
-func (s *SqlSupplier) GroupCreateOrRestoreMember(ctx context.Context, groupID string, userID string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+// GroupGetStats counts the active members of the group and, when includeDeleted is set, the removed ones too.
+func (s *SqlSupplier) GroupGetStats(ctx context.Context, groupID string, includeDeleted bool, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
 	result := store.NewSupplierResult()
 
-	member := &model.GroupMember{
-		GroupId:  groupID,
-		UserId:   userID,
-		CreateAt: model.GetMillis(),
-	}
-
-	if result.Err = member.IsValid(); result.Err != nil {
-		return result
-	}
+	stats := &model.GroupStats{GroupId: groupID}
+	params := map[string]interface{}{"GroupId": groupID}
 
-	var retrievedGroup *model.Group
-	if err := s.GetMaster().SelectOne(&retrievedGroup, "SELECT * FROM UserGroups WHERE Id = :Id", map[string]interface{}{"Id": groupID}); err != nil {
-		result.Err = model.NewAppError("SqlGroupStore.GroupCreateOrRestoreMember", "store.insert_error", nil, "group_id="+member.GroupId+"user_id="+member.UserId+","+err.Error(), http.StatusInternalServerError)
+	if !includeDeleted {
+		count, err := s.GetReplica().SelectInt("SELECT COUNT(*) FROM GroupMembers WHERE GroupId = :GroupId AND DeleteAt = 0", params)
+		if err != nil {
+			result.Err = model.NewAppError("SqlGroupStore.GroupGetStats", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+			return result
+		}
+		stats.TotalMemberCount = count
+		result.Data = stats
 		return result
 	}
 
-	var retrievedMember *model.GroupMember
-	if err := s.GetMaster().SelectOne(&retrievedMember, "SELECT * FROM GroupMembers WHERE GroupId = :GroupId AND UserId = :UserId", map[string]interface{}{"GroupId": member.GroupId, "UserId": member.UserId}); err != nil {
-		if err != sql.ErrNoRows {
-			result.Err = model.NewAppError("SqlGroupStore.GroupCreateOrRestoreMember", "store.select_error", nil, "group_id="+member.GroupId+"user_id="+member.UserId+","+err.Error(), http.StatusInternalServerError)
-			return result
-		}
+	var counts struct {
+		Active  int64
+		Deleted int64
 	}
+	query := `
+		SELECT
+			COUNT(CASE WHEN DeleteAt = 0 THEN 1 END) AS Active,
+			COUNT(CASE WHEN DeleteAt != 0 THEN 1 END) AS Deleted
+		FROM
+			GroupMembers
+		WHERE
+			GroupId = :GroupId`
 
-	if retrievedMember != nil && retrievedMember.DeleteAt == 0 {
-		result.Err = model.NewAppError("SqlGroupStore.GroupCreateOrRestoreMember", "store.sql_group.uniqueness_error", nil, "group_id="+member.GroupId+", user_id="+member.UserId, http.StatusBadRequest)
+	if err := s.GetReplica().SelectOne(&counts, query, params); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetStats", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
 		return result
 	}
 
-	if retrievedMember == nil {
-		if err := s.GetMaster().Insert(member); err != nil {
-			if IsUniqueConstraintError(err, []string{"GroupId", "UserId", "groupmembers_pkey", "PRIMARY"}) {
-				result.Err = model.NewAppError("SqlGroupStore.GroupCreateOrRestoreMember", "store.sql_group.uniqueness_error", nil, "group_id="+member.GroupId+", user_id="+member.UserId+", "+err.Error(), http.StatusBadRequest)
-				return result
-			}
-			result.Err = model.NewAppError("SqlGroupStore.GroupCreateOrRestoreMember", "store.insert_error", nil, "group_id="+member.GroupId+", user_id="+member.UserId+", "+err.Error(), http.StatusInternalServerError)
-			return result
-		}
-	} else {
-		member.DeleteAt = 0
-		var rowsChanged int64
-		var err error
-		if rowsChanged, err = s.GetMaster().Update(member); err != nil {
-			result.Err = model.NewAppError("SqlGroupStore.GroupCreateOrRestoreMember", "store.update_error", nil, "group_id="+member.GroupId+", user_id="+member.UserId+", "+err.Error(), http.StatusInternalServerError)
-			return result
-		}
-		if rowsChanged != 1 {
-			result.Err = model.NewAppError("SqlGroupStore.GroupCreateOrRestoreMember", "store.sql_group.no_rows_changed", nil, "", http.StatusInternalServerError)
-			return result
-		}
-	}
+	stats.TotalMemberCount = counts.Active
+	stats.DeletedMemberCount = &counts.Deleted
+	result.Data = stats
 
-	result.Data = member
 	return result
 }
 
-func (s *SqlSupplier) GroupDeleteMember(ctx context.Context, groupID string, userID string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+// GroupGetMemberUsersExpiringPage returns a page of the active members of the group whose membership expires before
+// the given time, soonest first.
+func (s *SqlSupplier) GroupGetMemberUsersExpiringPage(ctx context.Context, groupID string, before int64, page, perPage int, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
 	result := store.NewSupplierResult()
 
-	var retrievedMember *model.GroupMember
-	if err := s.GetMaster().SelectOne(&retrievedMember, "SELECT * FROM GroupMembers WHERE GroupId = :GroupId AND UserId = :UserId AND DeleteAt = 0", map[string]interface{}{"GroupId": groupID, "UserId": userID}); err != nil {
-		if err == sql.ErrNoRows {
-			result.Err = model.NewAppError("SqlGroupStore.GroupDeleteMember", "store.sql_group.no_rows", nil, "group_id="+groupID+"user_id="+userID+","+err.Error(), http.StatusNotFound)
-			return result
-		}
-		result.Err = model.NewAppError("SqlGroupStore.GroupDeleteMember", "store.select_error", nil, "group_id="+groupID+"user_id="+userID+","+err.Error(), http.StatusInternalServerError)
-		return result
-	}
+	var users []*model.User
 
-	retrievedMember.DeleteAt = model.GetMillis()
+	query := `
+		SELECT
+			Users.*
+		FROM
+			GroupMembers
+			JOIN Users ON Users.Id = GroupMembers.UserId
+		WHERE
+			GroupMembers.DeleteAt = 0
+			AND GroupMembers.ExpiresAt > 0
+			AND GroupMembers.ExpiresAt < :Before
+			AND Users.DeleteAt = 0
+			AND GroupId = :GroupId
+		ORDER BY
+			GroupMembers.ExpiresAt, Users.Id
+		LIMIT
+			:Limit
+		OFFSET
+			:Offset`
 
-	if _, err := s.GetMaster().Update(retrievedMember); err != nil {
-		result.Err = model.NewAppError("SqlGroupStore.GroupDeleteMember", "store.update_error", nil, err.Error(), http.StatusInternalServerError)
+	if _, err := s.GetReplica().Select(&users, query, map[string]interface{}{"GroupId": groupID, "Before": before, "Limit": perPage, "Offset": page * perPage}); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetMemberUsersExpiringPage", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
 		return result
 	}
 
-	result.Data = retrievedMember
+	result.Data = users
+
 	return result
 }
 
-func (s *SqlSupplier) GroupCreateGroupSyncable(ctx context.Context, groupSyncable *model.GroupSyncable, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+// GroupGetExpiringMemberCount counts the active members of the group whose membership expires before the given time.
+func (s *SqlSupplier) GroupGetExpiringMemberCount(ctx context.Context, groupID string, before int64, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
 	result := store.NewSupplierResult()
 
-	if err := groupSyncable.IsValid(); err != nil {
-		result.Err = err
+	query := `
+		SELECT
+			count(*)
+		FROM
+			GroupMembers
+			JOIN Users ON Users.Id = GroupMembers.UserId
+		WHERE
+			GroupMembers.DeleteAt = 0
+			AND GroupMembers.ExpiresAt > 0
+			AND GroupMembers.ExpiresAt < :Before
+			AND Users.DeleteAt = 0
+			AND GroupId = :GroupId`
+
+	count, err := s.GetReplica().SelectInt(query, map[string]interface{}{"GroupId": groupID, "Before": before})
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetExpiringMemberCount", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
 		return result
 	}
 
-	// Reset values that shouldn't be updatable by parameter
-	groupSyncable.DeleteAt = 0
-	groupSyncable.CreateAt = model.GetMillis()
-	groupSyncable.UpdateAt = groupSyncable.CreateAt
+	result.Data = count
 
-	var err error
+	return result
+}
 
-	switch groupSyncable.Type {
-	case model.GroupSyncableTypeTeam:
-		if _, err := s.Team().Get(groupSyncable.SyncableId); err != nil {
-			result.Err = err
-			return result
-		}
+// GroupGetExpiredMembers returns up to limit active memberships, across all groups, whose expiry is at or before now.
+func (s *SqlSupplier) GroupGetExpiredMembers(ctx context.Context, now int64, limit int, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
 
-		err = s.GetMaster().Insert(groupSyncableToGroupTeam(groupSyncable))
-	case model.GroupSyncableTypeChannel:
-		_, errCh := s.Channel().Get(groupSyncable.SyncableId, false)
-		if errCh != nil {
-			result.Err = errCh
-			return result
-		}
+	var members []*model.GroupMember
 
-		err = s.GetMaster().Insert(groupSyncableToGroupChannel(groupSyncable))
-	default:
-		result.Err = model.NewAppError("SqlGroupStore.GroupCreateGroupSyncable", "model.group_syncable.type.app_error", nil, "group_id="+groupSyncable.GroupId+", syncable_id="+groupSyncable.SyncableId+", "+err.Error(), http.StatusInternalServerError)
-		return result
-	}
+	query := `
+		SELECT
+			*
+		FROM
+			GroupMembers
+		WHERE
+			DeleteAt = 0
+			AND ExpiresAt > 0
+			AND ExpiresAt <= :Now
+		ORDER BY
+			ExpiresAt, GroupId, UserId
+		LIMIT
+			:Limit`
 
-	if err != nil {
-		result.Err = model.NewAppError("SqlGroupStore.GroupCreateGroupSyncable", "store.insert_error", nil, "group_id="+groupSyncable.GroupId+", syncable_id="+groupSyncable.SyncableId+", "+err.Error(), http.StatusInternalServerError)
+	if _, err := s.GetMaster().Select(&members, query, map[string]interface{}{"Now": now, "Limit": limit}); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetExpiredMembers", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
 		return result
 	}
 
-	result.Data = groupSyncable
+	result.Data = members
+
 	return result
 }
 
-func (s *SqlSupplier) GroupGetGroupSyncable(ctx context.Context, groupID string, syncableID string, syncableType model.GroupSyncableType, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+// GroupLogMemberEvents appends an event with the given action for each of the users to the membership history of the
+// group.
+func (s *SqlSupplier) GroupLogMemberEvents(ctx context.Context, groupID string, userIDs []string, action string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
 	result := store.NewSupplierResult()
 
-	groupSyncable, err := s.getGroupSyncable(groupID, syncableID, syncableType)
+	if len(userIDs) == 0 {
+		return result
+	}
+
+	now := model.GetMillis()
+	query := s.getQueryBuilder().Insert("GroupMemberHistory").Columns("Id", "GroupId", "UserId", "Action", "CreateAt")
+	for _, userID := range userIDs {
+		query = query.Values(model.NewId(), groupID, userID, action, now)
+	}
+
+	queryString, args, err := query.ToSql()
 	if err != nil {
-		if err == sql.ErrNoRows {
-			result.Err = model.NewAppError("SqlGroupStore.GroupGetGroupSyncable", "store.sql_group.no_rows", nil, err.Error(), http.StatusNotFound)
-		} else {
-			result.Err = model.NewAppError("SqlGroupStore.GroupGetGroupSyncable", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
-		}
+		result.Err = model.NewAppError("SqlGroupStore.GroupLogMemberEvents", "store.insert_error", nil, err.Error(), http.StatusInternalServerError)
 		return result
 	}
 
-	result.Data = groupSyncable
+	if _, err = s.GetMaster().Exec(queryString, args...); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupLogMemberEvents", "store.insert_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
 
 	return result
 }
 
-func (s *SqlSupplier) getGroupSyncable(groupID string, syncableID string, syncableType model.GroupSyncableType) (*model.GroupSyncable, error) {
-	var err error
-	var result interface{}
+// GroupGetMemberHistory returns a page of the membership events of the group, oldest first. Only events at or after
+// from and, unless to is zero, at or before to are included.
+func (s *SqlSupplier) GroupGetMemberHistory(ctx context.Context, groupID string, from, to int64, page, perPage int, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
 
-	switch syncableType {
-	case model.GroupSyncableTypeTeam:
-		result, err = s.GetMaster().Get(groupTeam{}, groupID, syncableID)
-	case model.GroupSyncableTypeChannel:
-		result, err = s.GetMaster().Get(groupChannel{}, groupID, syncableID)
+	query := s.getQueryBuilder().
+		Select("*").
+		From("GroupMemberHistory").
+		Where(sq.Eq{"GroupId": groupID}).
+		Where(sq.GtOrEq{"CreateAt": from}).
+		OrderBy("CreateAt", "Id").
+		Limit(uint64(perPage)).
+		Offset(uint64(page * perPage))
+
+	if to > 0 {
+		query = query.Where(sq.LtOrEq{"CreateAt": to})
 	}
 
+	queryString, args, err := query.ToSql()
 	if err != nil {
-		return nil, err
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetMemberHistory", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
 	}
 
-	if result == nil {
-		return nil, sql.ErrNoRows
+	events := []*model.GroupMemberHistoryEvent{}
+	if _, err = s.GetReplica().Select(&events, queryString, args...); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetMemberHistory", "store.select_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+		return result
 	}
 
-	groupSyncable := model.GroupSyncable{}
-	switch syncableType {
-	case model.GroupSyncableTypeTeam:
-		groupTeam := result.(*groupTeam)
-		groupSyncable.SyncableId = groupTeam.TeamId
-		groupSyncable.GroupId = groupTeam.GroupId
-		groupSyncable.AutoAdd = groupTeam.AutoAdd
-		groupSyncable.CreateAt = groupTeam.CreateAt
-		groupSyncable.DeleteAt = groupTeam.DeleteAt
-		groupSyncable.UpdateAt = groupTeam.UpdateAt
-		groupSyncable.Type = syncableType
-	case model.GroupSyncableTypeChannel:
-		groupChannel := result.(*groupChannel)
-		groupSyncable.SyncableId = groupChannel.ChannelId
-		groupSyncable.GroupId = groupChannel.GroupId
-		groupSyncable.AutoAdd = groupChannel.AutoAdd
-		groupSyncable.CreateAt = groupChannel.CreateAt
-		groupSyncable.DeleteAt = groupChannel.DeleteAt
-		groupSyncable.UpdateAt = groupChannel.UpdateAt
-		groupSyncable.Type = syncableType
-	default:
-		return nil, fmt.Errorf("unable to convert syncableType: %s", syncableType.String())
-	}
+	result.Data = events
 
-	return &groupSyncable, nil
+	return result
 }
 
-func (s *SqlSupplier) GroupGetAllGroupSyncablesByGroup(ctx context.Context, groupID string, syncableType model.GroupSyncableType, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+func (s *SqlSupplier) GroupCreateOrRestoreMember(ctx context.Context, groupID string, userID string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
 	result := store.NewSupplierResult()
 
-	args := map[string]interface{}{"GroupId": groupID}
-
-	appErrF := func(msg string) *model.AppError {
-		return model.NewAppError("SqlGroupStore.GroupGetAllGroupSyncablesByGroup", "store.select_error", nil, msg, http.StatusInternalServerError)
+	member := &model.GroupMember{
+		GroupId:  groupID,
+		UserId:   userID,
+		CreateAt: model.GetMillis(),
 	}
 
-	groupSyncables := []*model.GroupSyncable{}
+	if result.Err = member.IsValid(); result.Err != nil {
+		return result
+	}
 
-	switch syncableType {
-	case model.GroupSyncableTypeTeam:
-		sqlQuery := `
-			SELECT
-				GroupTeams.*,
-				Teams.DisplayName AS TeamDisplayName,
-				Teams.Type AS TeamType
-			FROM
-				GroupTeams
-				JOIN Teams ON Teams.Id = GroupTeams.TeamId
-			WHERE
-				GroupId = :GroupId AND GroupTeams.DeleteAt = 0`
+	var retrievedGroup *model.Group
+	if err := s.GetMaster().SelectOne(&retrievedGroup, "SELECT * FROM UserGroups WHERE Id = :Id", map[string]interface{}{"Id": groupID}); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupCreateOrRestoreMember", "store.insert_error", nil, "group_id="+member.GroupId+"user_id="+member.UserId+","+err.Error(), http.StatusInternalServerError)
+		return result
+	}
 
-		results := []*groupTeamJoin{}
-		_, err := s.GetMaster().Select(&results, sqlQuery, args)
-		if err != nil {
-			result.Err = appErrF(err.Error())
+	var retrievedMember *model.GroupMember
+	if err := s.GetMaster().SelectOne(&retrievedMember, "SELECT * FROM GroupMembers WHERE GroupId = :GroupId AND UserId = :UserId", map[string]interface{}{"GroupId": member.GroupId, "UserId": member.UserId}); err != nil {
+		if err != sql.ErrNoRows {
+			result.Err = model.NewAppError("SqlGroupStore.GroupCreateOrRestoreMember", "store.select_error", nil, "group_id="+member.GroupId+"user_id="+member.UserId+","+err.Error(), http.StatusInternalServerError)
 			return result
 		}
-		for _, result := range results {
-			groupSyncable := &model.GroupSyncable{
-				SyncableId:      result.TeamId,
-				GroupId:         result.GroupId,
-				AutoAdd:         result.AutoAdd,
-				CreateAt:        result.CreateAt,
-				DeleteAt:        result.DeleteAt,
-				UpdateAt:        result.UpdateAt,
-				Type:            syncableType,
-				TeamDisplayName: result.TeamDisplayName,
-				TeamType:        result.TeamType,
+	}
+
+	if retrievedMember != nil && retrievedMember.DeleteAt == 0 {
+		result.Err = model.NewAppError("SqlGroupStore.GroupCreateOrRestoreMember", "store.sql_group.uniqueness_error", nil, "group_id="+member.GroupId+", user_id="+member.UserId, http.StatusBadRequest)
+		return result
+	}
+
+	member.Source = model.GroupMemberSourceForGroup(retrievedGroup.Source)
+
+	if retrievedMember == nil {
+		if err := s.GetMaster().Insert(member); err != nil {
+			if IsUniqueConstraintError(err, []string{"GroupId", "UserId", "groupmembers_pkey", "PRIMARY"}) {
+				result.Err = model.NewAppError("SqlGroupStore.GroupCreateOrRestoreMember", "store.sql_group.uniqueness_error", nil, "group_id="+member.GroupId+", user_id="+member.UserId+", "+err.Error(), http.StatusBadRequest)
+				return result
 			}
-			groupSyncables = append(groupSyncables, groupSyncable)
+			result.Err = model.NewAppError("SqlGroupStore.GroupCreateOrRestoreMember", "store.insert_error", nil, "group_id="+member.GroupId+", user_id="+member.UserId+", "+err.Error(), http.StatusInternalServerError)
+			return result
 		}
-	case model.GroupSyncableTypeChannel:
-		sqlQuery := `
-			SELECT
-				GroupChannels.*,
-				Channels.DisplayName AS ChannelDisplayName,
-				Teams.DisplayName AS TeamDisplayName,
-				Channels.Type As ChannelType,
-				Teams.Type As TeamType,
-				Teams.Id AS TeamId
-			FROM
-				GroupChannels
-				JOIN Channels ON Channels.Id = GroupChannels.ChannelId
-				JOIN Teams ON Teams.Id = Channels.TeamId
-			WHERE
-				GroupId = :GroupId AND GroupChannels.DeleteAt = 0`
-
-		results := []*groupChannelJoin{}
-		_, err := s.GetMaster().Select(&results, sqlQuery, args)
-		if err != nil {
-			result.Err = appErrF(err.Error())
+	} else {
+		member.DeleteAt = 0
+		// Restoring a membership keeps the roles the user had in the group.
+		member.Roles = retrievedMember.Roles
+		var rowsChanged int64
+		var err error
+		if rowsChanged, err = s.GetMaster().Update(member); err != nil {
+			result.Err = model.NewAppError("SqlGroupStore.GroupCreateOrRestoreMember", "store.update_error", nil, "group_id="+member.GroupId+", user_id="+member.UserId+", "+err.Error(), http.StatusInternalServerError)
 			return result
 		}
-		for _, result := range results {
-			groupSyncable := &model.GroupSyncable{
-				SyncableId:         result.ChannelId,
-				GroupId:            result.GroupId,
-				AutoAdd:            result.AutoAdd,
-				CreateAt:           result.CreateAt,
-				DeleteAt:           result.DeleteAt,
-				UpdateAt:           result.UpdateAt,
-				Type:               syncableType,
-				ChannelDisplayName: result.ChannelDisplayName,
-				ChannelType:        result.ChannelType,
-				TeamDisplayName:    result.TeamDisplayName,
-				TeamType:           result.TeamType,
-				TeamID:             result.TeamID,
-			}
-			groupSyncables = append(groupSyncables, groupSyncable)
+		if rowsChanged != 1 {
+			result.Err = model.NewAppError("SqlGroupStore.GroupCreateOrRestoreMember", "store.sql_group.no_rows_changed", nil, "", http.StatusInternalServerError)
+			return result
 		}
 	}
 
-	result.Data = groupSyncables
+	result.Data = member
 	return result
 }
 
-func (s *SqlSupplier) GroupUpdateGroupSyncable(ctx context.Context, groupSyncable *model.GroupSyncable, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+func (s *SqlSupplier) GroupDeleteMember(ctx context.Context, groupID string, userID string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
 	result := store.NewSupplierResult()
 
-	retrievedGroupSyncable, err := s.getGroupSyncable(groupSyncable.GroupId, groupSyncable.SyncableId, groupSyncable.Type)
-	if err != nil {
+	var retrievedMember *model.GroupMember
+	if err := s.GetMaster().SelectOne(&retrievedMember, "SELECT * FROM GroupMembers WHERE GroupId = :GroupId AND UserId = :UserId AND DeleteAt = 0", map[string]interface{}{"GroupId": groupID, "UserId": userID}); err != nil {
 		if err == sql.ErrNoRows {
-			result.Err = model.NewAppError("SqlGroupStore.GroupUpdateGroupSyncable", "store.sql_group.no_rows", nil, err.Error(), http.StatusInternalServerError)
+			result.Err = model.NewAppError("SqlGroupStore.GroupDeleteMember", "store.sql_group.no_rows", nil, "group_id="+groupID+"user_id="+userID+","+err.Error(), http.StatusNotFound)
 			return result
 		}
-		result.Err = model.NewAppError("SqlGroupStore.GroupUpdateGroupSyncable", "store.select_error", nil, "GroupId="+groupSyncable.GroupId+", SyncableId="+groupSyncable.SyncableId+", SyncableType="+groupSyncable.Type.String()+", "+err.Error(), http.StatusInternalServerError)
+		result.Err = model.NewAppError("SqlGroupStore.GroupDeleteMember", "store.select_error", nil, "group_id="+groupID+"user_id="+userID+","+err.Error(), http.StatusInternalServerError)
 		return result
 	}
 
-	if err := groupSyncable.IsValid(); err != nil {
-		result.Err = err
+	retrievedMember.DeleteAt = model.GetMillis()
+
+	if _, err := s.GetMaster().Update(retrievedMember); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupDeleteMember", "store.update_error", nil, err.Error(), http.StatusInternalServerError)
 		return result
 	}
 
-	// If updating DeleteAt it can only be to 0
-	if groupSyncable.DeleteAt != retrievedGroupSyncable.DeleteAt && groupSyncable.DeleteAt != 0 {
-		result.Err = model.NewAppError("SqlGroupStore.GroupUpdateGroupSyncable", "model.group.delete_at.app_error", nil, "", http.StatusInternalServerError)
+	result.Data = retrievedMember
+	return result
+}
+
+func (s *SqlSupplier) GroupGetMembers(ctx context.Context, groupID string, userIDs []string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	members := []*model.GroupMember{}
+	if len(userIDs) == 0 {
+		result.Data = members
 		return result
 	}
 
-	// Reset these properties, don't update them based on input
-	groupSyncable.CreateAt = retrievedGroupSyncable.CreateAt
-	groupSyncable.UpdateAt = model.GetMillis()
+	query, args, err := s.getQueryBuilder().
+		Select("*").
+		From("GroupMembers").
+		Where(sq.Eq{"GroupId": groupID, "UserId": userIDs, "DeleteAt": 0}).
+		ToSql()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetMembers", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
 
-	switch groupSyncable.Type {
-	case model.GroupSyncableTypeTeam:
-		_, err = s.GetMaster().Update(groupSyncableToGroupTeam(groupSyncable))
-	case model.GroupSyncableTypeChannel:
-		_, err = s.GetMaster().Update(groupSyncableToGroupChannel(groupSyncable))
-	default:
-		model.NewAppError("SqlGroupStore.GroupUpdateGroupSyncable", "model.group_syncable.type.app_error", nil, "group_id="+groupSyncable.GroupId+", syncable_id="+groupSyncable.SyncableId+", "+err.Error(), http.StatusInternalServerError)
+	if _, err = s.GetReplica().Select(&members, query, args...); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetMembers", "store.select_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = members
+	return result
+}
+
+func (s *SqlSupplier) GroupUpdateMember(ctx context.Context, member *model.GroupMember, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	if result.Err = member.IsValid(); result.Err != nil {
 		return result
 	}
 
+	sqlResult, err := s.GetMaster().Exec("UPDATE GroupMembers SET Roles = :Roles, ExpiresAt = :ExpiresAt WHERE GroupId = :GroupId AND UserId = :UserId AND DeleteAt = 0", map[string]interface{}{"Roles": member.Roles, "ExpiresAt": member.ExpiresAt, "GroupId": member.GroupId, "UserId": member.UserId})
 	if err != nil {
-		result.Err = model.NewAppError("SqlGroupStore.GroupUpdateGroupSyncable", "store.update_error", nil, err.Error(), http.StatusInternalServerError)
+		result.Err = model.NewAppError("SqlGroupStore.GroupUpdateMember", "store.update_error", nil, "group_id="+member.GroupId+", user_id="+member.UserId+", "+err.Error(), http.StatusInternalServerError)
 		return result
 	}
 
-	result.Data = groupSyncable
+	if rowsChanged, err := sqlResult.RowsAffected(); err == nil && rowsChanged == 0 {
+		var count int64
+		if count, err = s.GetMaster().SelectInt("SELECT COUNT(*) FROM GroupMembers WHERE GroupId = :GroupId AND UserId = :UserId AND DeleteAt = 0", map[string]interface{}{"GroupId": member.GroupId, "UserId": member.UserId}); err != nil {
+			result.Err = model.NewAppError("SqlGroupStore.GroupUpdateMember", "store.select_error", nil, "group_id="+member.GroupId+", user_id="+member.UserId+", "+err.Error(), http.StatusInternalServerError)
+			return result
+		}
+		if count == 0 {
+			result.Err = model.NewAppError("SqlGroupStore.GroupUpdateMember", "store.sql_group.no_rows", nil, "group_id="+member.GroupId+", user_id="+member.UserId, http.StatusNotFound)
+			return result
+		}
+	}
+
+	result.Data = member
 	return result
 }
 
-func (s *SqlSupplier) GroupDeleteGroupSyncable(ctx context.Context, groupID string, syncableID string, syncableType model.GroupSyncableType, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+// GroupUpsertMembers adds the given users to the group, restoring any previously deleted memberships. Users that are
+// already active members are skipped and reported separately, so retrying the same request is harmless.
+func (s *SqlSupplier) GroupUpsertMembers(ctx context.Context, groupID string, userIDs []string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
 	result := store.NewSupplierResult()
 
-	groupSyncable, err := s.getGroupSyncable(groupID, syncableID, syncableType)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			result.Err = model.NewAppError("SqlGroupStore.GroupDeleteGroupSyncable", "store.sql_group.no_rows", nil, "Id="+groupID+", "+err.Error(), http.StatusNotFound)
-		} else {
-			result.Err = model.NewAppError("SqlGroupStore.GroupDeleteGroupSyncable", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+	userIDs = model.RemoveDuplicateStrings(userIDs)
+	for _, userID := range userIDs {
+		member := &model.GroupMember{GroupId: groupID, UserId: userID}
+		if result.Err = member.IsValid(); result.Err != nil {
+			return result
 		}
+	}
+
+	upsertResult := &model.GroupMembersUpsertResult{Added: []string{}, AlreadyMembers: []string{}}
+	if len(userIDs) == 0 {
+		result.Data = upsertResult
 		return result
 	}
 
-	if groupSyncable.DeleteAt != 0 {
-		result.Err = model.NewAppError("SqlGroupStore.GroupDeleteGroupSyncable", "store.sql_group.group_syncable_already_deleted", nil, "group_id="+groupID+"syncable_id="+syncableID, http.StatusBadRequest)
+	transaction, err := s.GetMaster().Begin()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupUpsertMembers", "store.sql_group.upsert_members.open_transaction.app_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
 		return result
 	}
+	defer finalizeTransaction(transaction)
 
-	time := model.GetMillis()
-	groupSyncable.DeleteAt = time
-	groupSyncable.UpdateAt = time
+	source, appErr := groupMemberSource(transaction, groupID)
+	if appErr != nil {
+		result.Err = appErr
+		return result
+	}
+
+	now := model.GetMillis()
+	active := map[string]bool{}
+	for _, batch := range groupMemberBatches(userIDs) {
+		if result.Err = s.upsertGroupMembersBatch(transaction, groupID, batch, source, now, active); result.Err != nil {
+			return result
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupUpsertMembers", "store.sql_group.upsert_members.commit_transaction.app_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	for _, userID := range userIDs {
+		if active[userID] {
+			upsertResult.AlreadyMembers = append(upsertResult.AlreadyMembers, userID)
+		} else {
+			upsertResult.Added = append(upsertResult.Added, userID)
+		}
+	}
+
+	result.Data = upsertResult
+	return result
+}
+
+// groupMemberSource returns the Source of the memberships created in the group, based on the source of the group.
+func groupMemberSource(transaction *gorp.Transaction, groupID string) (string, *model.AppError) {
+	groupSource, err := transaction.SelectStr("SELECT Source FROM UserGroups WHERE Id = :Id", map[string]interface{}{"Id": groupID})
+	if err != nil {
+		return "", model.NewAppError("SqlGroupStore.GroupUpsertMembers", "store.select_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return model.GroupMemberSourceForGroup(model.GroupSource(groupSource)), nil
+}
+
+// upsertGroupMembersBatch adds one batch of users to the group with the given membership source, marking the users
+// that were already active members in active.
+func (s *SqlSupplier) upsertGroupMembersBatch(transaction *gorp.Transaction, groupID string, userIDs []string, source string, now int64, active map[string]bool) *model.AppError {
+	existingQuery, args, err := s.getQueryBuilder().
+		Select("UserId", "DeleteAt").
+		From("GroupMembers").
+		Where(sq.Eq{"GroupId": groupID, "UserId": userIDs}).
+		ToSql()
+	if err != nil {
+		return model.NewAppError("SqlGroupStore.GroupUpsertMembers", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	var existing []*model.GroupMember
+	if _, err = transaction.Select(&existing, existingQuery, args...); err != nil {
+		return model.NewAppError("SqlGroupStore.GroupUpsertMembers", "store.select_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	deleted := map[string]bool{}
+	var restore []string
+	for _, member := range existing {
+		if member.DeleteAt == 0 {
+			active[member.UserId] = true
+		} else {
+			deleted[member.UserId] = true
+			restore = append(restore, member.UserId)
+		}
+	}
+
+	var insert []string
+	for _, userID := range userIDs {
+		if !active[userID] && !deleted[userID] {
+			insert = append(insert, userID)
+		}
+	}
+
+	if len(restore) > 0 {
+		restoreQuery, args, err := s.getQueryBuilder().
+			Update("GroupMembers").
+			Set("DeleteAt", 0).
+			Set("CreateAt", now).
+			Set("ExpiresAt", 0).
+			Set("Source", source).
+			Where(sq.Eq{"GroupId": groupID, "UserId": restore}).
+			Where(sq.NotEq{"DeleteAt": 0}).
+			ToSql()
+		if err != nil {
+			return model.NewAppError("SqlGroupStore.GroupUpsertMembers", "store.update_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+		if _, err = transaction.Exec(restoreQuery, args...); err != nil {
+			return model.NewAppError("SqlGroupStore.GroupUpsertMembers", "store.update_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+		}
+	}
+
+	if len(insert) == 0 {
+		return nil
+	}
+
+	insertBuilder := s.getQueryBuilder().Insert("GroupMembers").Columns("GroupId", "UserId", "CreateAt", "DeleteAt", "Roles", "ExpiresAt", "Source")
+	for _, userID := range insert {
+		insertBuilder = insertBuilder.Values(groupID, userID, now, 0, "", 0, source)
+	}
+
+	// Rows inserted concurrently by another request are ignored rather than failing the whole batch.
+	if s.DriverName() == model.DATABASE_DRIVER_POSTGRES {
+		insertBuilder = insertBuilder.Suffix("ON CONFLICT DO NOTHING")
+	} else {
+		insertBuilder = insertBuilder.Options("IGNORE")
+	}
+
+	insertQuery, args, err := insertBuilder.ToSql()
+	if err != nil {
+		return model.NewAppError("SqlGroupStore.GroupUpsertMembers", "store.insert_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	sqlResult, err := transaction.Exec(insertQuery, args...)
+	if err != nil {
+		return model.NewAppError("SqlGroupStore.GroupUpsertMembers", "store.insert_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	if inserted, err := sqlResult.RowsAffected(); err == nil && int(inserted) != len(insert) {
+		// Some of the rows were created by someone else in the meantime; those users were already members.
+		concurrentQuery, args, err := s.getQueryBuilder().
+			Select("UserId").
+			From("GroupMembers").
+			Where(sq.Eq{"GroupId": groupID, "UserId": insert}).
+			Where(sq.NotEq{"CreateAt": now}).
+			ToSql()
+		if err != nil {
+			return model.NewAppError("SqlGroupStore.GroupUpsertMembers", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+
+		var concurrent []string
+		if _, err = transaction.Select(&concurrent, concurrentQuery, args...); err != nil {
+			return model.NewAppError("SqlGroupStore.GroupUpsertMembers", "store.select_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+		}
+		for _, userID := range concurrent {
+			active[userID] = true
+		}
+	}
+
+	return nil
+}
+
+// GroupDeleteMembers removes the given users from the group and returns the memberships that were removed. Users that
+// are not active members are ignored.
+func (s *SqlSupplier) GroupDeleteMembers(ctx context.Context, groupID string, userIDs []string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	userIDs = model.RemoveDuplicateStrings(userIDs)
+	members := []*model.GroupMember{}
+	if len(userIDs) == 0 {
+		result.Data = members
+		return result
+	}
+
+	transaction, err := s.GetMaster().Begin()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupDeleteMembers", "store.sql_group.delete_members.open_transaction.app_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+	defer finalizeTransaction(transaction)
+
+	deleteAt := model.GetMillis()
+	for _, batch := range groupMemberBatches(userIDs) {
+		var deleted []*model.GroupMember
+		if deleted, result.Err = s.deleteGroupMembersBatch(transaction, groupID, batch, deleteAt); result.Err != nil {
+			return result
+		}
+		members = append(members, deleted...)
+	}
+
+	if err := transaction.Commit(); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupDeleteMembers", "store.sql_group.delete_members.commit_transaction.app_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = members
+	return result
+}
+
+// GroupDeleteAllMembers removes every active member of the group and returns the ids of the users removed.
+func (s *SqlSupplier) GroupDeleteAllMembers(ctx context.Context, groupID string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	transaction, err := s.GetMaster().Begin()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupDeleteAllMembers", "store.sql_group.delete_members.open_transaction.app_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+	defer finalizeTransaction(transaction)
+
+	var memberIDs []string
+	if _, err = transaction.Select(&memberIDs, "SELECT UserId FROM GroupMembers WHERE GroupId = :GroupId AND DeleteAt = 0", map[string]interface{}{"GroupId": groupID}); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupDeleteAllMembers", "store.select_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	userIDs := make([]string, 0, len(memberIDs))
+	if len(memberIDs) > 0 {
+		deleteAt := model.GetMillis()
+		for _, batch := range groupMemberBatches(memberIDs) {
+			var deleted []*model.GroupMember
+			if deleted, result.Err = s.deleteGroupMembersBatch(transaction, groupID, batch, deleteAt); result.Err != nil {
+				return result
+			}
+			for _, member := range deleted {
+				userIDs = append(userIDs, member.UserId)
+			}
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupDeleteAllMembers", "store.sql_group.delete_members.commit_transaction.app_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = userIDs
+	return result
+}
+
+func (s *SqlSupplier) deleteGroupMembersBatch(transaction *gorp.Transaction, groupID string, userIDs []string, deleteAt int64) ([]*model.GroupMember, *model.AppError) {
+	selectQuery, args, err := s.getQueryBuilder().
+		Select("*").
+		From("GroupMembers").
+		Where(sq.Eq{"GroupId": groupID, "UserId": userIDs, "DeleteAt": 0}).
+		ToSql()
+	if err != nil {
+		return nil, model.NewAppError("SqlGroupStore.GroupDeleteMembers", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	var members []*model.GroupMember
+	if _, err = transaction.Select(&members, selectQuery, args...); err != nil {
+		return nil, model.NewAppError("SqlGroupStore.GroupDeleteMembers", "store.select_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	if len(members) == 0 {
+		return members, nil
+	}
+
+	deleteIds := make([]string, 0, len(members))
+	for _, member := range members {
+		member.DeleteAt = deleteAt
+		deleteIds = append(deleteIds, member.UserId)
+	}
+
+	deleteQuery, args, err := s.getQueryBuilder().
+		Update("GroupMembers").
+		Set("DeleteAt", deleteAt).
+		Where(sq.Eq{"GroupId": groupID, "UserId": deleteIds, "DeleteAt": 0}).
+		ToSql()
+	if err != nil {
+		return nil, model.NewAppError("SqlGroupStore.GroupDeleteMembers", "store.update_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if _, err = transaction.Exec(deleteQuery, args...); err != nil {
+		return nil, model.NewAppError("SqlGroupStore.GroupDeleteMembers", "store.update_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return members, nil
+}
+
+// GroupMergeGroups moves the members and team and channel links of the source group into the target group, skipping
+// those the target already has, and then deletes the source group. It returns the new member count of the target.
+func (s *SqlSupplier) GroupMergeGroups(ctx context.Context, targetID string, sourceID string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	transaction, err := s.GetMaster().Begin()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupMergeGroups", "store.sql_group.merge_groups.open_transaction.app_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+	defer finalizeTransaction(transaction)
+
+	now := model.GetMillis()
+
+	var userIDs []string
+	if _, err = transaction.Select(&userIDs, "SELECT UserId FROM GroupMembers WHERE GroupId = :GroupId AND DeleteAt = 0", map[string]interface{}{"GroupId": sourceID}); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupMergeGroups", "store.select_error", nil, "group_id="+sourceID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	if len(userIDs) > 0 {
+		source, appErr := groupMemberSource(transaction, targetID)
+		if appErr != nil {
+			result.Err = appErr
+			return result
+		}
+
+		active := map[string]bool{}
+		for _, batch := range groupMemberBatches(userIDs) {
+			if result.Err = s.upsertGroupMembersBatch(transaction, targetID, batch, source, now, active); result.Err != nil {
+				return result
+			}
+		}
+	}
+
+	if _, err = transaction.Exec("UPDATE GroupMembers SET DeleteAt = :DeleteAt WHERE GroupId = :GroupId AND DeleteAt = 0", map[string]interface{}{"DeleteAt": now, "GroupId": sourceID}); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupMergeGroups", "store.update_error", nil, "group_id="+sourceID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	if result.Err = s.mergeGroupSyncables(transaction, "GroupTeams", "TeamId", targetID, sourceID, now); result.Err != nil {
+		return result
+	}
+
+	if result.Err = s.mergeGroupSyncables(transaction, "GroupChannels", "ChannelId", targetID, sourceID, now); result.Err != nil {
+		return result
+	}
+
+	if _, err = transaction.Exec("UPDATE UserGroups SET DeleteAt = :DeleteAt, UpdateAt = :UpdateAt WHERE Id = :Id", map[string]interface{}{"DeleteAt": now, "UpdateAt": now, "Id": sourceID}); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupMergeGroups", "store.update_error", nil, "group_id="+sourceID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	count, err := transaction.SelectInt("SELECT COUNT(*) FROM GroupMembers WHERE GroupId = :GroupId AND DeleteAt = 0", map[string]interface{}{"GroupId": targetID})
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupMergeGroups", "store.select_error", nil, "group_id="+targetID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	if err := transaction.Commit(); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupMergeGroups", "store.sql_group.merge_groups.commit_transaction.app_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = count
+	return result
+}
+
+// mergeGroupSyncables copies the active links of the source group in the given syncable table to the target group,
+// restoring the target's deleted links where they exist, and then deletes the source group's links.
+func (s *SqlSupplier) mergeGroupSyncables(transaction *gorp.Transaction, table, idColumn, targetID, sourceID string, now int64) *model.AppError {
+	var sourceLinks []*struct {
+		SyncableId            string
+		AutoAdd               bool
+		SchemeAdmin           bool
+		SuppressNotifications bool
+		ExpiresAt             int64
+		Roles                 string
+	}
+	if _, err := transaction.Select(&sourceLinks, "SELECT "+idColumn+" AS SyncableId, AutoAdd, SchemeAdmin, SuppressNotifications, ExpiresAt, Roles FROM "+table+" WHERE GroupId = :GroupId AND DeleteAt = 0", map[string]interface{}{"GroupId": sourceID}); err != nil {
+		return model.NewAppError("SqlGroupStore.GroupMergeGroups", "store.select_error", nil, "group_id="+sourceID+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	if len(sourceLinks) == 0 {
+		return nil
+	}
+
+	var targetLinks []*struct {
+		SyncableId string
+		DeleteAt   int64
+	}
+	if _, err := transaction.Select(&targetLinks, "SELECT "+idColumn+" AS SyncableId, DeleteAt FROM "+table+" WHERE GroupId = :GroupId", map[string]interface{}{"GroupId": targetID}); err != nil {
+		return model.NewAppError("SqlGroupStore.GroupMergeGroups", "store.select_error", nil, "group_id="+targetID+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	targetDeleteAt := make(map[string]int64, len(targetLinks))
+	for _, link := range targetLinks {
+		targetDeleteAt[link.SyncableId] = link.DeleteAt
+	}
+
+	for _, link := range sourceLinks {
+		params := map[string]interface{}{"GroupId": targetID, "SyncableId": link.SyncableId, "AutoAdd": link.AutoAdd, "SchemeAdmin": link.SchemeAdmin, "SuppressNotifications": link.SuppressNotifications, "ExpiresAt": link.ExpiresAt, "Roles": link.Roles, "Now": now}
+
+		deleteAt, linked := targetDeleteAt[link.SyncableId]
+		if linked && deleteAt == 0 {
+			continue
+		}
+
+		var err error
+		if linked {
+			_, err = transaction.Exec("UPDATE "+table+" SET DeleteAt = 0, AutoAdd = :AutoAdd, SchemeAdmin = :SchemeAdmin, SuppressNotifications = :SuppressNotifications, ExpiresAt = :ExpiresAt, Roles = :Roles, UpdateAt = :Now WHERE GroupId = :GroupId AND "+idColumn+" = :SyncableId", params)
+		} else {
+			_, err = transaction.Exec("INSERT INTO "+table+" (GroupId, "+idColumn+", AutoAdd, SchemeAdmin, SuppressNotifications, ExpiresAt, Roles, CreateAt, DeleteAt, UpdateAt) VALUES (:GroupId, :SyncableId, :AutoAdd, :SchemeAdmin, :SuppressNotifications, :ExpiresAt, :Roles, :Now, 0, :Now)", params)
+		}
+		if err != nil {
+			return model.NewAppError("SqlGroupStore.GroupMergeGroups", "store.insert_error", nil, "group_id="+targetID+", syncable_id="+link.SyncableId+", "+err.Error(), http.StatusInternalServerError)
+		}
+	}
+
+	if _, err := transaction.Exec("UPDATE "+table+" SET DeleteAt = :Now, UpdateAt = :Now WHERE GroupId = :GroupId AND DeleteAt = 0", map[string]interface{}{"GroupId": sourceID, "Now": now}); err != nil {
+		return model.NewAppError("SqlGroupStore.GroupMergeGroups", "store.update_error", nil, "group_id="+sourceID+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// groupMemberBatches splits the user ids into batches of at most GROUP_MEMBERS_BATCH_SIZE, keeping the IN clauses and
+// multi-row inserts of the member queries within the database limits.
+func groupMemberBatches(userIDs []string) [][]string {
+	var batches [][]string
+	for len(userIDs) > GROUP_MEMBERS_BATCH_SIZE {
+		batches = append(batches, userIDs[:GROUP_MEMBERS_BATCH_SIZE])
+		userIDs = userIDs[GROUP_MEMBERS_BATCH_SIZE:]
+	}
+	return append(batches, userIDs)
+}
+
+func (s *SqlSupplier) GroupCreateGroupSyncable(ctx context.Context, groupSyncable *model.GroupSyncable, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	if err := groupSyncable.IsValid(); err != nil {
+		result.Err = err
+		return result
+	}
+
+	// Reset values that shouldn't be updatable by parameter
+	groupSyncable.DeleteAt = 0
+	groupSyncable.CreateAt = model.GetMillis()
+	groupSyncable.UpdateAt = groupSyncable.CreateAt
+
+	var err error
+
+	switch groupSyncable.Type {
+	case model.GroupSyncableTypeTeam:
+		if _, err := s.Team().Get(groupSyncable.SyncableId); err != nil {
+			result.Err = err
+			return result
+		}
+
+		err = s.GetMaster().Insert(groupSyncableToGroupTeam(groupSyncable))
+	case model.GroupSyncableTypeChannel:
+		_, errCh := s.Channel().Get(groupSyncable.SyncableId, false)
+		if errCh != nil {
+			result.Err = errCh
+			return result
+		}
+
+		err = s.GetMaster().Insert(groupSyncableToGroupChannel(groupSyncable))
+	default:
+		result.Err = model.NewAppError("SqlGroupStore.GroupCreateGroupSyncable", "model.group_syncable.type.app_error", nil, "group_id="+groupSyncable.GroupId+", syncable_id="+groupSyncable.SyncableId+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	if err != nil {
+		if IsUniqueConstraintError(err, []string{"GroupId", "groupteams_pkey", "groupchannels_pkey", "PRIMARY"}) {
+			result.Err = model.NewAppError("SqlGroupStore.GroupCreateGroupSyncable", "store.sql_group.syncable_uniqueness_error", nil, "group_id="+groupSyncable.GroupId+", syncable_id="+groupSyncable.SyncableId+", "+err.Error(), http.StatusBadRequest)
+			return result
+		}
+		result.Err = model.NewAppError("SqlGroupStore.GroupCreateGroupSyncable", "store.insert_error", nil, "group_id="+groupSyncable.GroupId+", syncable_id="+groupSyncable.SyncableId+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = groupSyncable
+	return result
+}
+
+func (s *SqlSupplier) GroupGetGroupSyncable(ctx context.Context, groupID string, syncableID string, syncableType model.GroupSyncableType, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	groupSyncable, err := s.getGroupSyncable(groupID, syncableID, syncableType)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			result.Err = model.NewAppError("SqlGroupStore.GroupGetGroupSyncable", "store.sql_group.no_rows", nil, err.Error(), http.StatusNotFound)
+		} else {
+			result.Err = model.NewAppError("SqlGroupStore.GroupGetGroupSyncable", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+		return result
+	}
+
+	result.Data = groupSyncable
+
+	return result
+}
+
+func (s *SqlSupplier) getGroupSyncable(groupID string, syncableID string, syncableType model.GroupSyncableType) (*model.GroupSyncable, error) {
+	var err error
+	var result interface{}
+
+	switch syncableType {
+	case model.GroupSyncableTypeTeam:
+		result, err = s.GetMaster().Get(groupTeam{}, groupID, syncableID)
+	case model.GroupSyncableTypeChannel:
+		result, err = s.GetMaster().Get(groupChannel{}, groupID, syncableID)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	groupSyncable := model.GroupSyncable{}
+	switch syncableType {
+	case model.GroupSyncableTypeTeam:
+		groupTeam := result.(*groupTeam)
+		groupSyncable.SyncableId = groupTeam.TeamId
+		groupSyncable.GroupId = groupTeam.GroupId
+		groupSyncable.AutoAdd = groupTeam.AutoAdd
+		groupSyncable.SchemeAdmin = groupTeam.SchemeAdmin
+		groupSyncable.SuppressNotifications = groupTeam.SuppressNotifications
+		groupSyncable.ExpiresAt = groupTeam.ExpiresAt
+		groupSyncable.Roles = groupTeam.Roles
+		groupSyncable.CreateAt = groupTeam.CreateAt
+		groupSyncable.DeleteAt = groupTeam.DeleteAt
+		groupSyncable.UpdateAt = groupTeam.UpdateAt
+		groupSyncable.Type = syncableType
+	case model.GroupSyncableTypeChannel:
+		groupChannel := result.(*groupChannel)
+		groupSyncable.SyncableId = groupChannel.ChannelId
+		groupSyncable.GroupId = groupChannel.GroupId
+		groupSyncable.AutoAdd = groupChannel.AutoAdd
+		groupSyncable.SchemeAdmin = groupChannel.SchemeAdmin
+		groupSyncable.SuppressNotifications = groupChannel.SuppressNotifications
+		groupSyncable.ExpiresAt = groupChannel.ExpiresAt
+		groupSyncable.Roles = groupChannel.Roles
+		groupSyncable.CreateAt = groupChannel.CreateAt
+		groupSyncable.DeleteAt = groupChannel.DeleteAt
+		groupSyncable.UpdateAt = groupChannel.UpdateAt
+		groupSyncable.Type = syncableType
+	default:
+		return nil, fmt.Errorf("unable to convert syncableType: %s", syncableType.String())
+	}
+
+	return &groupSyncable, nil
+}
+
+// GroupGroupSyncableExists reports whether the group is actively linked to the syncable without
+// loading the link itself. Deleted links do not count.
+func (s *SqlSupplier) GroupGroupSyncableExists(ctx context.Context, groupID string, syncableID string, syncableType model.GroupSyncableType, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	table, column := "GroupTeams", "TeamId"
+	if syncableType == model.GroupSyncableTypeChannel {
+		table, column = "GroupChannels", "ChannelId"
+	}
+
+	query := "SELECT 1 FROM " + table + " WHERE GroupId = :GroupId AND " + column + " = :SyncableId AND DeleteAt = 0"
+	exists, err := s.GetReplica().SelectNullInt(query, map[string]interface{}{"GroupId": groupID, "SyncableId": syncableID})
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGroupSyncableExists", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = exists.Valid
+
+	return result
+}
+
+func (s *SqlSupplier) GroupGetAllGroupSyncablesByGroup(ctx context.Context, groupID string, syncableType model.GroupSyncableType, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	args := map[string]interface{}{"GroupId": groupID}
+
+	appErrF := func(msg string) *model.AppError {
+		return model.NewAppError("SqlGroupStore.GroupGetAllGroupSyncablesByGroup", "store.select_error", nil, msg, http.StatusInternalServerError)
+	}
+
+	groupSyncables := []*model.GroupSyncable{}
+
+	switch syncableType {
+	case model.GroupSyncableTypeTeam:
+		sqlQuery := `
+			SELECT
+				GroupTeams.*,
+				Teams.DisplayName AS TeamDisplayName,
+				Teams.Name AS TeamName,
+				Teams.Type AS TeamType
+			FROM
+				GroupTeams
+				JOIN Teams ON Teams.Id = GroupTeams.TeamId
+			WHERE
+				GroupId = :GroupId AND GroupTeams.DeleteAt = 0`
+
+		results := []*groupTeamJoin{}
+		_, err := s.GetMaster().Select(&results, sqlQuery, args)
+		if err != nil {
+			result.Err = appErrF(err.Error())
+			return result
+		}
+		for _, result := range results {
+			groupSyncable := &model.GroupSyncable{
+				SyncableId:            result.TeamId,
+				GroupId:               result.GroupId,
+				AutoAdd:               result.AutoAdd,
+				SchemeAdmin:           result.SchemeAdmin,
+				SuppressNotifications: result.SuppressNotifications,
+				ExpiresAt:             result.ExpiresAt,
+				Roles:                 result.Roles,
+				CreateAt:              result.CreateAt,
+				DeleteAt:              result.DeleteAt,
+				UpdateAt:              result.UpdateAt,
+				Type:                  syncableType,
+				TeamDisplayName:       result.TeamDisplayName,
+				TeamName:              result.TeamName,
+				TeamType:              result.TeamType,
+			}
+			groupSyncables = append(groupSyncables, groupSyncable)
+		}
+	case model.GroupSyncableTypeChannel:
+		sqlQuery := `
+			SELECT
+				GroupChannels.*,
+				Channels.DisplayName AS ChannelDisplayName,
+				Channels.Name AS ChannelName,
+				Teams.DisplayName AS TeamDisplayName,
+				Teams.Name AS TeamName,
+				Channels.Type As ChannelType,
+				Teams.Type As TeamType,
+				Teams.Id AS TeamId,
+				Channels.DeleteAt AS ChannelDeleteAt
+			FROM
+				GroupChannels
+				JOIN Channels ON Channels.Id = GroupChannels.ChannelId
+				JOIN Teams ON Teams.Id = Channels.TeamId
+			WHERE
+				GroupId = :GroupId AND GroupChannels.DeleteAt = 0`
+
+		results := []*groupChannelJoin{}
+		_, err := s.GetMaster().Select(&results, sqlQuery, args)
+		if err != nil {
+			result.Err = appErrF(err.Error())
+			return result
+		}
+		for _, result := range results {
+			groupSyncable := &model.GroupSyncable{
+				SyncableId:            result.ChannelId,
+				GroupId:               result.GroupId,
+				AutoAdd:               result.AutoAdd,
+				SchemeAdmin:           result.SchemeAdmin,
+				SuppressNotifications: result.SuppressNotifications,
+				ExpiresAt:             result.ExpiresAt,
+				Roles:                 result.Roles,
+				CreateAt:              result.CreateAt,
+				DeleteAt:              result.DeleteAt,
+				UpdateAt:              result.UpdateAt,
+				Type:                  syncableType,
+				ChannelDisplayName:    result.ChannelDisplayName,
+				ChannelName:           result.ChannelName,
+				ChannelType:           result.ChannelType,
+				TeamDisplayName:       result.TeamDisplayName,
+				TeamName:              result.TeamName,
+				TeamType:              result.TeamType,
+				TeamID:                result.TeamID,
+				ChannelDeleteAt:       result.ChannelDeleteAt,
+			}
+			groupSyncables = append(groupSyncables, groupSyncable)
+		}
+	}
+
+	result.Data = groupSyncables
+	return result
+}
+
+// GroupGetExpiredGroupSyncables returns up to limit of the active team or channel links whose expiry time has passed,
+// the earliest expired first.
+func (s *SqlSupplier) GroupGetExpiredGroupSyncables(ctx context.Context, syncableType model.GroupSyncableType, now int64, limit int, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	args := map[string]interface{}{"Now": now, "Limit": limit}
+	groupSyncables := []*model.GroupSyncable{}
+
+	switch syncableType {
+	case model.GroupSyncableTypeTeam:
+		var groupTeams []*groupTeam
+		if _, err := s.GetMaster().Select(&groupTeams, `
+			SELECT
+				*
+			FROM
+				GroupTeams
+			WHERE
+				DeleteAt = 0
+				AND ExpiresAt > 0
+				AND ExpiresAt <= :Now
+			ORDER BY
+				ExpiresAt, GroupId, TeamId
+			LIMIT
+				:Limit`, args); err != nil {
+			result.Err = model.NewAppError("SqlGroupStore.GroupGetExpiredGroupSyncables", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+			return result
+		}
+		for _, groupTeam := range groupTeams {
+			groupSyncable := groupTeam.GroupSyncable
+			groupSyncable.SyncableId = groupTeam.TeamId
+			groupSyncable.Type = syncableType
+			groupSyncables = append(groupSyncables, &groupSyncable)
+		}
+	case model.GroupSyncableTypeChannel:
+		var groupChannels []*groupChannel
+		if _, err := s.GetMaster().Select(&groupChannels, `
+			SELECT
+				*
+			FROM
+				GroupChannels
+			WHERE
+				DeleteAt = 0
+				AND ExpiresAt > 0
+				AND ExpiresAt <= :Now
+			ORDER BY
+				ExpiresAt, GroupId, ChannelId
+			LIMIT
+				:Limit`, args); err != nil {
+			result.Err = model.NewAppError("SqlGroupStore.GroupGetExpiredGroupSyncables", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+			return result
+		}
+		for _, groupChannel := range groupChannels {
+			groupSyncable := groupChannel.GroupSyncable
+			groupSyncable.SyncableId = groupChannel.ChannelId
+			groupSyncable.Type = syncableType
+			groupSyncables = append(groupSyncables, &groupSyncable)
+		}
+	}
+
+	result.Data = groupSyncables
+	return result
+}
+
+// GroupGetLinkedSyncableIds returns, for each of the given groups that is linked to any of the given teams or channels,
+// the ids of those it is linked to. Groups without such a link are left out.
+func (s *SqlSupplier) GroupGetLinkedSyncableIds(ctx context.Context, groupIDs []string, syncableIDs []string, syncableType model.GroupSyncableType, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	linked := map[string][]string{}
+	if len(groupIDs) == 0 || len(syncableIDs) == 0 {
+		result.Data = linked
+		return result
+	}
+
+	table, column := "GroupTeams", "TeamId"
+	if syncableType == model.GroupSyncableTypeChannel {
+		table, column = "GroupChannels", "ChannelId"
+	}
+
+	query, args, err := s.getQueryBuilder().
+		Select("GroupId", column+" AS SyncableId").
+		From(table).
+		Where(sq.Eq{"DeleteAt": 0, "GroupId": groupIDs, column: syncableIDs}).
+		OrderBy("GroupId", column).
+		ToSql()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetLinkedSyncableIds", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	var links []struct {
+		GroupId    string
+		SyncableId string
+	}
+	if _, err = s.GetReplica().Select(&links, query, args...); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetLinkedSyncableIds", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	for _, link := range links {
+		linked[link.GroupId] = append(linked[link.GroupId], link.SyncableId)
+	}
+
+	result.Data = linked
+	return result
+}
+
+func (s *SqlSupplier) GroupUpdateGroupSyncable(ctx context.Context, groupSyncable *model.GroupSyncable, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	retrievedGroupSyncable, err := s.getGroupSyncable(groupSyncable.GroupId, groupSyncable.SyncableId, groupSyncable.Type)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			result.Err = model.NewAppError("SqlGroupStore.GroupUpdateGroupSyncable", "store.sql_group.no_rows", nil, err.Error(), http.StatusInternalServerError)
+			return result
+		}
+		result.Err = model.NewAppError("SqlGroupStore.GroupUpdateGroupSyncable", "store.select_error", nil, "GroupId="+groupSyncable.GroupId+", SyncableId="+groupSyncable.SyncableId+", SyncableType="+groupSyncable.Type.String()+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	if err := groupSyncable.IsValid(); err != nil {
+		result.Err = err
+		return result
+	}
+
+	// If updating DeleteAt it can only be to 0
+	if groupSyncable.DeleteAt != retrievedGroupSyncable.DeleteAt && groupSyncable.DeleteAt != 0 {
+		result.Err = model.NewAppError("SqlGroupStore.GroupUpdateGroupSyncable", "model.group.delete_at.app_error", nil, "", http.StatusInternalServerError)
+		return result
+	}
+
+	// Reset these properties, don't update them based on input
+	groupSyncable.CreateAt = retrievedGroupSyncable.CreateAt
+	groupSyncable.UpdateAt = model.GetMillis()
+
+	switch groupSyncable.Type {
+	case model.GroupSyncableTypeTeam:
+		_, err = s.GetMaster().Update(groupSyncableToGroupTeam(groupSyncable))
+	case model.GroupSyncableTypeChannel:
+		_, err = s.GetMaster().Update(groupSyncableToGroupChannel(groupSyncable))
+	default:
+		model.NewAppError("SqlGroupStore.GroupUpdateGroupSyncable", "model.group_syncable.type.app_error", nil, "group_id="+groupSyncable.GroupId+", syncable_id="+groupSyncable.SyncableId+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupUpdateGroupSyncable", "store.update_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = groupSyncable
+	return result
+}
+
+func (s *SqlSupplier) GroupDeleteGroupSyncable(ctx context.Context, groupID string, syncableID string, syncableType model.GroupSyncableType, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	groupSyncable, err := s.getGroupSyncable(groupID, syncableID, syncableType)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			result.Err = model.NewAppError("SqlGroupStore.GroupDeleteGroupSyncable", "store.sql_group.no_rows", nil, "Id="+groupID+", "+err.Error(), http.StatusNotFound)
+		} else {
+			result.Err = model.NewAppError("SqlGroupStore.GroupDeleteGroupSyncable", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+		return result
+	}
+
+	if groupSyncable.DeleteAt != 0 {
+		result.Err = model.NewAppError("SqlGroupStore.GroupDeleteGroupSyncable", "store.sql_group.group_syncable_already_deleted", nil, "group_id="+groupID+"syncable_id="+syncableID, http.StatusBadRequest)
+		return result
+	}
+
+	time := model.GetMillis()
+	groupSyncable.DeleteAt = time
+	groupSyncable.UpdateAt = time
+
+	switch groupSyncable.Type {
+	case model.GroupSyncableTypeTeam:
+		_, err = s.GetMaster().Update(groupSyncableToGroupTeam(groupSyncable))
+	case model.GroupSyncableTypeChannel:
+		_, err = s.GetMaster().Update(groupSyncableToGroupChannel(groupSyncable))
+	default:
+		model.NewAppError("SqlGroupStore.GroupDeleteGroupSyncable", "model.group_syncable.type.app_error", nil, "group_id="+groupSyncable.GroupId+", syncable_id="+groupSyncable.SyncableId+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupDeleteGroupSyncable", "store.update_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = groupSyncable
+	return result
+}
+
+// TeamMembersToAdd returns a slice of UserTeamIDPair that need newly created memberships
+// based on the groups configurations.
+//
+// Typically since will be the last successful group sync time.
+func (s *SqlSupplier) TeamMembersToAdd(ctx context.Context, since int64, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	sql := `
+		SELECT
+			GroupMembers.UserId, GroupTeams.TeamId, GroupTeams.SuppressNotifications
+		FROM
+			GroupMembers
+			JOIN GroupTeams
+			ON GroupTeams.GroupId = GroupMembers.GroupId
+			JOIN UserGroups ON UserGroups.Id = GroupMembers.GroupId
+			JOIN Teams ON Teams.Id = GroupTeams.TeamId
+			LEFT OUTER JOIN TeamMembers
+			ON
+				TeamMembers.TeamId = GroupTeams.TeamId
+				AND TeamMembers.UserId = GroupMembers.UserId
+		WHERE
+			TeamMembers.UserId IS NULL
+			AND UserGroups.DeleteAt = 0
+			AND GroupTeams.DeleteAt = 0
+			AND GroupTeams.AutoAdd = true
+			AND GroupMembers.DeleteAt = 0
+			AND Teams.DeleteAt = 0
+			AND (GroupMembers.CreateAt >= :Since
+			OR GroupTeams.UpdateAt >= :Since)`
+
+	var teamMembers []*model.UserTeamIDPair
+
+	_, err := s.GetReplica().Select(&teamMembers, sql, map[string]interface{}{"Since": since})
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.TeamMembersToAdd", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	result.Data = teamMembers
+
+	return result
+}
+
+// ChannelMembersToAdd returns a slice of UserChannelIDPair that need newly created memberships
+// based on the groups configurations.
+//
+// Typically since will be the last successful group sync time. Exclusion wins: the members of a group whose link to
+// the channel excludes its members are never returned, even if another group of theirs is linked with auto-add.
+func (s *SqlSupplier) ChannelMembersToAdd(ctx context.Context, since int64, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	sql := `
+		SELECT
+			GroupMembers.UserId, GroupChannels.ChannelId, GroupChannels.SuppressNotifications, GroupChannels.Roles
+		FROM
+			GroupMembers
+			JOIN GroupChannels ON GroupChannels.GroupId = GroupMembers.GroupId
+			JOIN UserGroups ON UserGroups.Id = GroupMembers.GroupId
+			JOIN Channels ON Channels.Id = GroupChannels.ChannelId
+			LEFT OUTER JOIN ChannelMemberHistory
+			ON
+				ChannelMemberHistory.ChannelId = GroupChannels.ChannelId
+				AND ChannelMemberHistory.UserId = GroupMembers.UserId
+		WHERE
+			ChannelMemberHistory.UserId IS NULL
+			AND ChannelMemberHistory.LeaveTime IS NULL
+			AND UserGroups.DeleteAt = 0
+			AND GroupChannels.DeleteAt = 0
+			AND GroupChannels.AutoAdd = true
+			AND GroupMembers.DeleteAt = 0
+			AND Channels.DeleteAt = 0
+			AND (GroupMembers.CreateAt >= :Since
+			OR GroupChannels.UpdateAt >= :Since)
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					GroupChannels ExcludingChannels
+					JOIN UserGroups ExcludingGroups ON ExcludingGroups.Id = ExcludingChannels.GroupId
+					JOIN GroupMembers ExcludedMembers ON ExcludedMembers.GroupId = ExcludingChannels.GroupId
+				WHERE
+					ExcludingChannels.ChannelId = GroupChannels.ChannelId
+					AND ExcludingChannels.ExcludeMembers = TRUE
+					AND ExcludingChannels.DeleteAt = 0
+					AND ExcludingGroups.DeleteAt = 0
+					AND ExcludedMembers.UserId = GroupMembers.UserId
+					AND ExcludedMembers.DeleteAt = 0)`
+
+	var channelMembers []*model.UserChannelIDPair
+
+	_, err := s.GetReplica().Select(&channelMembers, sql, map[string]interface{}{"Since": since})
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.ChannelMembersToAdd", "store.select_error", nil, "", http.StatusInternalServerError)
+	}
+
+	result.Data = channelMembers
+
+	return result
+}
+
+func groupSyncableToGroupTeam(groupSyncable *model.GroupSyncable) *groupTeam {
+	return &groupTeam{
+		GroupSyncable: *groupSyncable,
+		TeamId:        groupSyncable.SyncableId,
+	}
+}
+
+func groupSyncableToGroupChannel(groupSyncable *model.GroupSyncable) *groupChannel {
+	return &groupChannel{
+		GroupSyncable: *groupSyncable,
+		ChannelId:     groupSyncable.SyncableId,
+	}
+}
+
+// TeamMembersToRemove returns all team members that should be removed based on group constraints.
+func (s *SqlSupplier) TeamMembersToRemove(ctx context.Context, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	sql := `
+		SELECT
+			TeamMembers.*
+		FROM
+			TeamMembers
+			JOIN Teams ON Teams.Id = TeamMembers.TeamId
+		WHERE
+			TeamMembers.DeleteAt = 0
+			AND Teams.DeleteAt = 0
+			AND Teams.GroupConstrained = TRUE
+			AND (TeamMembers.TeamId, TeamMembers.UserId)
+			NOT IN (
+				SELECT
+					Teams.Id AS TeamId, GroupMembers.UserId
+				FROM
+					Teams
+					JOIN GroupTeams ON GroupTeams.TeamId = Teams.Id
+					JOIN UserGroups ON UserGroups.Id = GroupTeams.GroupId
+					JOIN GroupMembers ON GroupMembers.GroupId = UserGroups.Id
+				WHERE
+					Teams.GroupConstrained = TRUE
+					AND GroupTeams.DeleteAt = 0
+					AND UserGroups.DeleteAt = 0
+					AND Teams.DeleteAt = 0
+					AND GroupMembers.DeleteAt = 0
+				GROUP BY
+					Teams.Id,
+					GroupMembers.UserId)`
+
+	var teamMembers []*model.TeamMember
+
+	_, err := s.GetReplica().Select(&teamMembers, sql)
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.TeamMembersToRemove", "store.select_error", nil, "", http.StatusInternalServerError)
+	}
+
+	result.Data = teamMembers
+
+	return result
+}
+
+func (s *SqlSupplier) GetGroupsByChannel(ctx context.Context, channelId string, page, perPage int, opts model.GroupSearchOpts, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	offset := page * perPage
+
+	if opts.IncludeTeamGroups {
+		return s.getGroupsByChannelIncludingTeam(channelId, perPage, offset)
+	}
+
+	var groups []*model.Group
+	_, err := s.GetReplica().Select(&groups, `
+		SELECT
+			ug.*
+		FROM
+			GroupChannels gc
+		LEFT JOIN
+			UserGroups ug
+		ON
+			gc.GroupId = ug.Id
+		WHERE
+			ug.DeleteAt = 0
+		AND
+			gc.DeleteAt = 0
+		AND
+			gc.ChannelId = :ChannelId
+		ORDER BY
+			ug.DisplayName
+		LIMIT :Limit
+		OFFSET :Offset`,
+		map[string]interface{}{"ChannelId": channelId, "Limit": perPage, "Offset": offset})
+
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GetGroupsByChannel", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = groups
+
+	return result
+}
+
+// getGroupsByChannelIncludingTeam returns the groups linked to the channel along with those linked to its team. A
+// group linked both ways is listed once, as a direct link.
+func (s *SqlSupplier) getGroupsByChannelIncludingTeam(channelId string, limit, offset int) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	var rows []*groupViaTeam
+	_, err := s.GetReplica().Select(&rows, `
+		SELECT
+			ug.*,
+			CASE WHEN gc.GroupId IS NULL THEN 1 ELSE 0 END AS ViaTeam
+		FROM
+			UserGroups ug
+		LEFT JOIN
+			GroupChannels gc
+		ON
+			gc.GroupId = ug.Id
+			AND gc.ChannelId = :ChannelId
+			AND gc.DeleteAt = 0
+		LEFT JOIN
+			GroupTeams gt
+		ON
+			gt.GroupId = ug.Id
+			AND gt.TeamId = (SELECT TeamId FROM Channels WHERE Id = :ChannelId)
+			AND gt.DeleteAt = 0
+		WHERE
+			ug.DeleteAt = 0
+		AND
+			(gc.GroupId IS NOT NULL OR gt.GroupId IS NOT NULL)
+		ORDER BY
+			ug.DisplayName, ug.Id
+		LIMIT :Limit
+		OFFSET :Offset`,
+		map[string]interface{}{"ChannelId": channelId, "Limit": limit, "Offset": offset})
+
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GetGroupsByChannel", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	groups := make([]*model.Group, 0, len(rows))
+	for _, row := range rows {
+		group := row.Group
+		group.ViaTeam = row.IsViaTeam
+		groups = append(groups, &group)
+	}
+
+	result.Data = groups
+
+	return result
+}
+
+// ChannelMembersToRemove returns all channel members that should be removed based on group constraints. Members
+// exempted from the constraint of their channel are never returned. A link excluding its group's members does not
+// permit them in the channel.
+func (s *SqlSupplier) ChannelMembersToRemove(ctx context.Context, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	sql := `
+		SELECT
+			ChannelMembers.*
+		FROM
+			ChannelMembers
+			JOIN Channels ON Channels.Id = ChannelMembers.ChannelId
+		WHERE
+			Channels.DeleteAt = 0
+			AND Channels.GroupConstrained = TRUE
+			AND (ChannelMembers.ChannelId, ChannelMembers.UserId)
+			NOT IN (
+				SELECT
+					Channels.Id AS ChannelId, GroupMembers.UserId
+				FROM
+					Channels
+					JOIN GroupChannels ON GroupChannels.ChannelId = Channels.Id
+					JOIN UserGroups ON UserGroups.Id = GroupChannels.GroupId
+					JOIN GroupMembers ON GroupMembers.GroupId = UserGroups.Id
+				WHERE
+					Channels.GroupConstrained = TRUE
+					AND GroupChannels.DeleteAt = 0
+					AND GroupChannels.ExcludeMembers = FALSE
+					AND UserGroups.DeleteAt = 0
+					AND Channels.DeleteAt = 0
+					AND GroupMembers.DeleteAt = 0
+				GROUP BY
+					Channels.Id,
+					GroupMembers.UserId)
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					GroupChannelExemptions
+				WHERE
+					GroupChannelExemptions.ChannelId = ChannelMembers.ChannelId
+					AND GroupChannelExemptions.UserId = ChannelMembers.UserId)`
+
+	var channelMembers []*model.ChannelMember
+
+	_, err := s.GetReplica().Select(&channelMembers, sql)
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.ChannelMembersToRemove", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	result.Data = channelMembers
+
+	return result
+}
+
+func (s *SqlSupplier) GetGroupsByTeam(ctx context.Context, teamId string, page, perPage int, opts model.GroupSearchOpts, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	autoAddFilter := ""
+	if opts.FilterAutoAdd {
+		autoAddFilter = "AND gt.AutoAdd = :AutoAdd"
+	}
+
+	offset := page * perPage
+
+	if opts.IncludeChannelGroups {
+		return s.getGroupsByTeamIncludingChannels(teamId, opts.FilterAutoAdd, perPage, offset)
+	}
+
+	var groups []*model.Group
+	_, err := s.GetReplica().Select(&groups, `
+		SELECT
+			ug.*
+		FROM
+			GroupTeams gt
+		LEFT JOIN
+			UserGroups ug
+		ON
+			gt.GroupId = ug.Id
+		WHERE
+			ug.DeleteAt = 0
+		AND
+			gt.DeleteAt = 0
+		AND
+			gt.TeamId = :TeamId
+		`+autoAddFilter+`
+		ORDER BY
+			ug.DisplayName
+		LIMIT :Limit
+		OFFSET :Offset`,
+		map[string]interface{}{"TeamId": teamId, "AutoAdd": true, "Limit": perPage, "Offset": offset})
+
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GetGroupsByTeam", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = groups
+
+	return result
+}
+
+// getGroupsByTeamIncludingChannels returns the groups linked to the team along with those linked to any of its
+// channels. A group linked both ways, or to several channels, is listed once.
+func (s *SqlSupplier) getGroupsByTeamIncludingChannels(teamId string, filterAutoAdd bool, limit, offset int) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	teamAutoAddFilter := ""
+	channelAutoAddFilter := ""
+	if filterAutoAdd {
+		teamAutoAddFilter = "AND gt.AutoAdd = :AutoAdd"
+		channelAutoAddFilter = "AND gc.AutoAdd = :AutoAdd"
+	}
+
+	var rows []*groupViaChannel
+	_, err := s.GetReplica().Select(&rows, `
+		SELECT
+			ug.*,
+			CASE WHEN gt.GroupId IS NULL THEN 1 ELSE 0 END AS ViaChannel
+		FROM
+			UserGroups ug
+		LEFT JOIN
+			GroupTeams gt
+		ON
+			gt.GroupId = ug.Id
+			AND gt.TeamId = :TeamId
+			AND gt.DeleteAt = 0
+			`+teamAutoAddFilter+`
+		WHERE
+			ug.DeleteAt = 0
+		AND
+			(gt.GroupId IS NOT NULL OR ug.Id IN (
+				SELECT
+					gc.GroupId
+				FROM
+					GroupChannels gc
+					JOIN Channels c ON c.Id = gc.ChannelId
+				WHERE
+					c.TeamId = :TeamId
+					AND c.DeleteAt = 0
+					AND gc.DeleteAt = 0
+					`+channelAutoAddFilter+`))
+		ORDER BY
+			ug.DisplayName, ug.Id
+		LIMIT :Limit
+		OFFSET :Offset`,
+		map[string]interface{}{"TeamId": teamId, "AutoAdd": true, "Limit": limit, "Offset": offset})
+
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GetGroupsByTeam", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	groups := make([]*model.Group, 0, len(rows))
+	for _, row := range rows {
+		group := row.Group
+		group.ViaChannel = row.IsViaChannel
+		groups = append(groups, &group)
+	}
+
+	result.Data = groups
+
+	return result
+}
+
+// groupDisplayNameOrderBy returns the ORDER BY terms sorting groups by display name. When the database has a collation
+// for the locale, display names are compared following its rules, so that e.g. "Émile" sorts next to "Eve" rather than
+// after "Zoë". Otherwise the database's default ordering is used.
+func (s *SqlSupplier) groupDisplayNameOrderBy(locale string) []string {
+	if collation := s.localeCollation(locale); len(collation) > 0 {
+		return []string{"g.DisplayName COLLATE " + collation, "g.Id"}
+	}
+	return []string{"g.DisplayName", "g.Id"}
+}
+
+// localeCollation returns the database collation implementing the sorting rules of the locale, quoted for use in a
+// query, or "" if there is none. The most specific collation is preferred, falling back to the language's and then to
+// the root Unicode collation. Lookups are cached since the collations of a database do not change while it is in use.
+func (s *SqlSupplier) localeCollation(locale string) string {
+	if len(locale) == 0 {
+		return ""
+	}
+
+	if collation, ok := s.localeCollations.Load(locale); ok {
+		return collation.(string)
+	}
+
+	tag, err := language.Parse(locale)
+	if err != nil {
+		s.localeCollations.Store(locale, "")
+		return ""
+	}
+	base, _ := tag.Base()
+
+	var candidates []string
+	var query string
+	switch s.DriverName() {
+	case model.DATABASE_DRIVER_POSTGRES:
+		// ICU collations are only available when Postgres was built with ICU.
+		if region, confidence := tag.Region(); confidence == language.Exact {
+			candidates = append(candidates, base.String()+"-"+region.String()+"-x-icu")
+		}
+		candidates = append(candidates, base.String()+"-x-icu", "und-x-icu")
+		query = `
+			SELECT
+				collname
+			FROM
+				pg_collation
+			WHERE
+				collname = :Name
+				AND collencoding IN (-1, (SELECT encoding FROM pg_database WHERE datname = current_database()))`
+	case model.DATABASE_DRIVER_MYSQL:
+		// Only the collations of the display name column's character set can be applied to it.
+		candidates = []string{"utf8mb4_" + base.String() + "_0900_ai_ci", "utf8mb4_0900_ai_ci", "utf8mb4_unicode_520_ci", "utf8mb4_unicode_ci"}
+		query = `
+			SELECT
+				COLLATION_NAME
+			FROM
+				INFORMATION_SCHEMA.COLLATIONS
+			WHERE
+				COLLATION_NAME = :Name
+				AND CHARACTER_SET_NAME = (
+					SELECT CHARACTER_SET_NAME
+					FROM INFORMATION_SCHEMA.COLUMNS
+					WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'UserGroups' AND COLUMN_NAME = 'DisplayName'
+				)`
+	}
+
+	collation := ""
+	for _, candidate := range candidates {
+		name, err := s.GetReplica().SelectNullStr(query, map[string]interface{}{"Name": candidate})
+		if err != nil {
+			// Try again on the next request rather than caching the failure.
+			mlog.Warn("Failed to look up the collation for a locale", mlog.String("locale", locale), mlog.Err(err))
+			return ""
+		}
+		if name.Valid {
+			collation = name.String
+			break
+		}
+	}
+
+	// The names are built from the parsed language tag, so they only hold letters, digits, dashes and underscores.
+	if len(collation) > 0 && s.DriverName() == model.DATABASE_DRIVER_POSTGRES {
+		collation = `"` + collation + `"`
+	}
+
+	s.localeCollations.Store(locale, collation)
+	return collation
+}
+
+// GroupGetGroups returns a page of groups matching opts. The IsLinked and IsConfigured options describe LDAP groups
+// rather than Mattermost groups and are not supported here.
+func (s *SqlSupplier) GroupGetGroups(ctx context.Context, page, perPage int, opts model.GroupSearchOpts, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	query := s.getQueryBuilder().
+		Select("g.*").
+		From("UserGroups g").
+		Where(sq.Eq{"g.DeleteAt": 0}).
+		OrderBy(s.groupDisplayNameOrderBy(opts.Locale)...).
+		Limit(uint64(perPage)).
+		Offset(uint64(page * perPage))
+
+	query = s.applyGroupSearchOpts(query, opts)
+
+	queryString, args, err := query.ToSql()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GetGroups", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	var groups []*model.Group
+	if _, err = s.GetReplica().Select(&groups, queryString, args...); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GetGroups", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = groups
+
+	return result
+}
+
+// GroupGetGroupsLastUpdateAt returns the latest UpdateAt of the groups matching opts, including deleted groups so
+// that a deletion also counts as a change. Zero is returned if no group matches.
+func (s *SqlSupplier) GroupGetGroupsLastUpdateAt(ctx context.Context, opts model.GroupSearchOpts, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	query := s.applyGroupSearchOpts(s.getQueryBuilder().Select("COALESCE(MAX(g.UpdateAt), 0)").From("UserGroups g"), opts)
+
+	queryString, args, err := query.ToSql()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetGroupsLastUpdateAt", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	lastUpdateAt, err := s.GetReplica().SelectInt(queryString, args...)
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetGroupsLastUpdateAt", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = lastUpdateAt
+	return result
+}
+
+// applyGroupSearchOpts adds the filters of a group search to a query selecting from UserGroups aliased as g.
+func (s *SqlSupplier) applyGroupSearchOpts(query sq.SelectBuilder, opts model.GroupSearchOpts) sq.SelectBuilder {
+	if len(opts.Q) > 0 {
+		term := strings.ToLower(opts.Q)
+		for _, c := range ignoreLikeSearchChar {
+			term = strings.Replace(term, c, "", -1)
+		}
+		for _, c := range escapeLikeSearchChar {
+			term = strings.Replace(term, c, "*"+c, -1)
+		}
+
+		like := "%" + term + "%"
+		query = query.Where(sq.Or{
+			sq.Expr("LOWER(g.Name) LIKE ? ESCAPE '*'", like),
+			sq.Expr("LOWER(g.DisplayName) LIKE ? ESCAPE '*'", like),
+		})
+	}
+
+	if len(opts.NotAssociatedToTeam) > 0 {
+		query = query.Where("g.Id NOT IN (SELECT GroupId FROM GroupTeams WHERE DeleteAt = 0 AND TeamId = ?)", opts.NotAssociatedToTeam)
+	}
+
+	if len(opts.NotAssociatedToChannel) > 0 {
+		query = query.Where("g.Id NOT IN (SELECT GroupId FROM GroupChannels WHERE DeleteAt = 0 AND ChannelId = ?)", opts.NotAssociatedToChannel)
+	}
+
+	if opts.FilterParentTeamPermitted != nil {
+		query = query.Where("g.Id IN (SELECT GroupId FROM GroupTeams WHERE DeleteAt = 0 AND TeamId = ?)", *opts.FilterParentTeamPermitted)
+	}
+
+	if opts.ExcludeDefault {
+		query = query.Where(sq.Eq{"g.IsDefault": false})
+	}
+
+	if opts.FilterTeamIds != nil {
+		if len(opts.FilterTeamIds) == 0 {
+			return query.Where("1 = 0")
+		}
+		teamsQuery, args, _ := sq.Select("GroupId").From("GroupTeams").Where(sq.Eq{"DeleteAt": 0, "TeamId": opts.FilterTeamIds}).ToSql()
+		query = query.Where("g.Id IN ("+teamsQuery+")", args...)
+	}
+
+	if len(opts.FilterMemberId) > 0 {
+		query = query.Where("g.Id IN (SELECT GroupId FROM GroupMembers WHERE DeleteAt = 0 AND UserId = ?)", opts.FilterMemberId)
+	}
+
+	if len(opts.Tag) > 0 {
+		if s.DriverName() == model.DATABASE_DRIVER_POSTGRES {
+			query = query.Where("g.Tags::jsonb @> ?::jsonb", model.ArrayToJson([]string{opts.Tag}))
+		} else {
+			// Tags are stored as a JSON array, so matching the quoted tag only matches whole tags.
+			tag := opts.Tag
+			for _, c := range escapeLikeSearchChar {
+				tag = strings.Replace(tag, c, "*"+c, -1)
+			}
+			query = query.Where("g.Tags LIKE ? ESCAPE '*'", "%\""+tag+"\"%")
+		}
+	}
+
+	return query
+}
+
+// GroupGetGroupsByUserId returns the groups the user is an active member of, excluding deleted groups.
+func (s *SqlSupplier) GroupGetGroupsByUserId(ctx context.Context, userID string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	groups := []*model.Group{}
+
+	query := `
+		SELECT
+			UserGroups.*
+		FROM
+			UserGroups
+			JOIN GroupMembers ON GroupMembers.GroupId = UserGroups.Id
+		WHERE
+			GroupMembers.UserId = :UserId
+			AND GroupMembers.DeleteAt = 0
+			AND UserGroups.DeleteAt = 0
+		ORDER BY
+			UserGroups.DisplayName, UserGroups.Id`
+
+	if _, err := s.GetReplica().Select(&groups, query, map[string]interface{}{"UserId": userID}); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetGroupsByUserId", "store.select_error", nil, "user_id="+userID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = groups
+	return result
+}
+
+// GroupGetGroupsMissingTeamLink returns the undeleted groups linked to the channel but not to the channel's team, sorted
+// by display name.
+func (s *SqlSupplier) GroupGetGroupsMissingTeamLink(ctx context.Context, channelID string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	query := `
+		SELECT
+			UserGroups.*
+		FROM
+			UserGroups
+			JOIN GroupChannels ON GroupChannels.GroupId = UserGroups.Id
+			JOIN Channels ON Channels.Id = GroupChannels.ChannelId
+		WHERE
+			GroupChannels.ChannelId = :ChannelId
+			AND GroupChannels.DeleteAt = 0
+			AND UserGroups.DeleteAt = 0
+			AND NOT EXISTS (
+				SELECT 1
+				FROM GroupTeams
+				WHERE
+					GroupTeams.GroupId = UserGroups.Id
+					AND GroupTeams.TeamId = Channels.TeamId
+					AND GroupTeams.DeleteAt = 0
+			)
+		ORDER BY
+			UserGroups.DisplayName, UserGroups.Id`
+
+	groups := []*model.Group{}
+	if _, err := s.GetReplica().Select(&groups, query, map[string]interface{}{"ChannelId": channelID}); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetGroupsMissingTeamLink", "store.select_error", nil, "channel_id="+channelID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = groups
+	return result
+}
+
+// GroupGetGroupsByMemberEmailDomain returns a page of the undeleted groups with at least one active member whose email
+// address is at the given domain, sorted by display name. The domain is matched case-insensitively against everything
+// after the @, so example.com does not match sub.example.com.
+func (s *SqlSupplier) GroupGetGroupsByMemberEmailDomain(ctx context.Context, domain string, page, perPage int, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	term := strings.ToLower(domain)
+	for _, c := range escapeLikeSearchChar {
+		term = strings.Replace(term, c, "*"+c, -1)
+	}
+
+	query, args, err := s.getQueryBuilder().
+		Select("g.*").
+		From("UserGroups g").
+		Where(sq.Eq{"g.DeleteAt": 0}).
+		Where(`EXISTS (
+			SELECT 1
+			FROM GroupMembers
+				JOIN Users ON Users.Id = GroupMembers.UserId
+			WHERE
+				GroupMembers.GroupId = g.Id
+				AND GroupMembers.DeleteAt = 0
+				AND Users.DeleteAt = 0
+				AND LOWER(Users.Email) LIKE ? ESCAPE '*')`, "%@"+term).
+		OrderBy("g.DisplayName", "g.Id").
+		Limit(uint64(perPage)).
+		Offset(uint64(page * perPage)).
+		ToSql()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetGroupsByMemberEmailDomain", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	groups := []*model.Group{}
+	if _, err = s.GetReplica().Select(&groups, query, args...); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetGroupsByMemberEmailDomain", "store.select_error", nil, "domain="+domain+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = groups
+	return result
+}
+
+// GroupAutocomplete returns up to limit referenceable groups whose name starts with namePrefix, ignoring case, sorted
+// by name. Only the start of the name is matched so that the index on NameLower can be used.
+func (s *SqlSupplier) GroupAutocomplete(ctx context.Context, namePrefix string, limit int, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	term := strings.ToLower(namePrefix)
+	for _, c := range ignoreLikeSearchChar {
+		term = strings.Replace(term, c, "", -1)
+	}
+	for _, c := range escapeLikeSearchChar {
+		term = strings.Replace(term, c, "*"+c, -1)
+	}
+
+	query, args, err := s.getQueryBuilder().
+		Select("*").
+		From("UserGroups").
+		Where(sq.Eq{"DeleteAt": 0, "AllowReference": true}).
+		Where("NameLower LIKE ? ESCAPE '*'", term+"%").
+		OrderBy("NameLower").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupAutocomplete", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	groups := []*model.Group{}
+	if _, err = s.GetReplica().Select(&groups, query, args...); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupAutocomplete", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = groups
+	return result
+}
+
+// GroupGetDeleteImpact counts the group-constrained teams and channels linked to the group, and the members of those
+// teams and channels who belong to the group but to no other group linked to the same team or channel.
+func (s *SqlSupplier) GroupGetDeleteImpact(ctx context.Context, groupID string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	params := map[string]interface{}{"GroupId": groupID}
+	impact := &model.GroupDeleteImpact{}
+
+	teamCountQuery := `
+		SELECT
+			COUNT(*)
+		FROM
+			GroupTeams
+			JOIN Teams ON Teams.Id = GroupTeams.TeamId
+		WHERE
+			GroupTeams.GroupId = :GroupId
+			AND GroupTeams.DeleteAt = 0
+			AND Teams.DeleteAt = 0
+			AND Teams.GroupConstrained = TRUE`
+
+	var err error
+	if impact.ConstrainedTeamCount, err = s.GetReplica().SelectInt(teamCountQuery, params); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetDeleteImpact", "store.select_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	channelCountQuery := `
+		SELECT
+			COUNT(*)
+		FROM
+			GroupChannels
+			JOIN Channels ON Channels.Id = GroupChannels.ChannelId
+		WHERE
+			GroupChannels.GroupId = :GroupId
+			AND GroupChannels.DeleteAt = 0
+			AND Channels.DeleteAt = 0
+			AND Channels.GroupConstrained = TRUE`
+
+	if impact.ConstrainedChannelCount, err = s.GetReplica().SelectInt(channelCountQuery, params); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetDeleteImpact", "store.select_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	affectedUsersQuery := `
+		SELECT
+			COUNT(DISTINCT AffectedUsers.UserId)
+		FROM (
+			SELECT
+				TeamMembers.UserId
+			FROM
+				TeamMembers
+				JOIN Teams ON Teams.Id = TeamMembers.TeamId
+				JOIN GroupTeams ON GroupTeams.TeamId = Teams.Id
+				JOIN GroupMembers ON GroupMembers.GroupId = GroupTeams.GroupId AND GroupMembers.UserId = TeamMembers.UserId
+			WHERE
+				GroupTeams.GroupId = :GroupId
+				AND GroupTeams.DeleteAt = 0
+				AND GroupMembers.DeleteAt = 0
+				AND TeamMembers.DeleteAt = 0
+				AND Teams.DeleteAt = 0
+				AND Teams.GroupConstrained = TRUE
+				AND NOT EXISTS (
+					SELECT
+						1
+					FROM
+						GroupTeams OtherGroupTeams
+						JOIN UserGroups ON UserGroups.Id = OtherGroupTeams.GroupId
+						JOIN GroupMembers OtherGroupMembers ON OtherGroupMembers.GroupId = UserGroups.Id
+					WHERE
+						OtherGroupTeams.TeamId = Teams.Id
+						AND OtherGroupTeams.GroupId != :GroupId
+						AND OtherGroupTeams.DeleteAt = 0
+						AND UserGroups.DeleteAt = 0
+						AND OtherGroupMembers.UserId = TeamMembers.UserId
+						AND OtherGroupMembers.DeleteAt = 0)
+			UNION
+			SELECT
+				ChannelMembers.UserId
+			FROM
+				ChannelMembers
+				JOIN Channels ON Channels.Id = ChannelMembers.ChannelId
+				JOIN GroupChannels ON GroupChannels.ChannelId = Channels.Id
+				JOIN GroupMembers ON GroupMembers.GroupId = GroupChannels.GroupId AND GroupMembers.UserId = ChannelMembers.UserId
+			WHERE
+				GroupChannels.GroupId = :GroupId
+				AND GroupChannels.DeleteAt = 0
+				AND GroupMembers.DeleteAt = 0
+				AND Channels.DeleteAt = 0
+				AND Channels.GroupConstrained = TRUE
+				AND NOT EXISTS (
+					SELECT
+						1
+					FROM
+						GroupChannels OtherGroupChannels
+						JOIN UserGroups ON UserGroups.Id = OtherGroupChannels.GroupId
+						JOIN GroupMembers OtherGroupMembers ON OtherGroupMembers.GroupId = UserGroups.Id
+					WHERE
+						OtherGroupChannels.ChannelId = Channels.Id
+						AND OtherGroupChannels.GroupId != :GroupId
+						AND OtherGroupChannels.DeleteAt = 0
+						AND UserGroups.DeleteAt = 0
+						AND OtherGroupMembers.UserId = ChannelMembers.UserId
+						AND OtherGroupMembers.DeleteAt = 0)
+		) AffectedUsers`
+
+	if impact.AffectedUserCount, err = s.GetReplica().SelectInt(affectedUsersQuery, params); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetDeleteImpact", "store.select_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = impact
+	return result
+}
+
+// GroupGetMemberIds returns the ids of up to limit active members of the group, without loading the users.
+func (s *SqlSupplier) GroupGetMemberIds(ctx context.Context, groupID string, limit int, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	query, args, err := s.getQueryBuilder().
+		Select("UserId").
+		From("GroupMembers").
+		Where(sq.Eq{"GroupId": groupID, "DeleteAt": 0}).
+		OrderBy("UserId").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetMemberIds", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	userIDs := []string{}
+	if _, err = s.GetReplica().Select(&userIDs, query, args...); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetMemberIds", "store.select_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = userIDs
+	return result
+}
+
+// GroupGetUnusedGroups returns a page of the groups created before opts.Since that have no active team or channel
+// links and no members added, restored or removed since then, oldest first.
+func (s *SqlSupplier) GroupGetUnusedGroups(ctx context.Context, page, perPage int, opts model.UnusedGroupSearchOpts, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	query := s.getQueryBuilder().
+		Select("g.*").
+		From("UserGroups g").
+		Where(sq.Eq{"g.DeleteAt": 0}).
+		Where(sq.Lt{"g.CreateAt": opts.Since}).
+		Where("NOT EXISTS (SELECT 1 FROM GroupTeams WHERE GroupTeams.GroupId = g.Id AND GroupTeams.DeleteAt = 0)").
+		Where("NOT EXISTS (SELECT 1 FROM GroupChannels WHERE GroupChannels.GroupId = g.Id AND GroupChannels.DeleteAt = 0)").
+		Where("NOT EXISTS (SELECT 1 FROM GroupMembers WHERE GroupMembers.GroupId = g.Id AND (GroupMembers.CreateAt >= ? OR GroupMembers.DeleteAt >= ?))", opts.Since, opts.Since).
+		OrderBy("g.CreateAt", "g.Id").
+		Limit(uint64(perPage)).
+		Offset(uint64(page * perPage))
+
+	if len(opts.Source) > 0 {
+		query = query.Where(sq.Eq{"g.Source": opts.Source})
+	}
+
+	queryString, args, err := query.ToSql()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetUnusedGroups", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	groups := []*model.Group{}
+	if _, err = s.GetReplica().Select(&groups, queryString, args...); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetUnusedGroups", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = groups
+	return result
+}
+
+// GroupGetTeamGroupSyncables returns a page of the active links of undeleted groups to the team, sorted by the display
+// name of the group.
+func (s *SqlSupplier) GroupGetTeamGroupSyncables(ctx context.Context, teamID string, page int, perPage int, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	query := `
+		SELECT
+			GroupTeams.*,
+			UserGroups.DisplayName AS GroupDisplayName
+		FROM
+			GroupTeams
+			JOIN UserGroups ON UserGroups.Id = GroupTeams.GroupId
+		WHERE
+			GroupTeams.TeamId = :TeamId
+			AND GroupTeams.DeleteAt = 0
+			AND UserGroups.DeleteAt = 0
+		ORDER BY
+			UserGroups.DisplayName, GroupTeams.GroupId
+		LIMIT :Limit OFFSET :Offset`
+
+	var rows []*groupTeamGroupJoin
+	if _, err := s.GetReplica().Select(&rows, query, map[string]interface{}{"TeamId": teamID, "Limit": perPage, "Offset": page * perPage}); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetTeamGroupSyncables", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	groupSyncables := make([]*model.GroupSyncable, 0, len(rows))
+	for _, row := range rows {
+		groupSyncables = append(groupSyncables, &model.GroupSyncable{
+			SyncableId:            row.TeamId,
+			GroupId:               row.GroupId,
+			AutoAdd:               row.AutoAdd,
+			SchemeAdmin:           row.SchemeAdmin,
+			SuppressNotifications: row.SuppressNotifications,
+			ExpiresAt:             row.ExpiresAt,
+			Roles:                 row.Roles,
+			CreateAt:              row.CreateAt,
+			DeleteAt:              row.DeleteAt,
+			UpdateAt:              row.UpdateAt,
+			Type:                  model.GroupSyncableTypeTeam,
+			GroupDisplayName:      row.GroupDisplayName,
+		})
+	}
+
+	result.Data = groupSyncables
+	return result
+}
+
+// GroupSetLastSyncAt records when the members of the group were last synced from its source.
+func (s *SqlSupplier) GroupSetLastSyncAt(ctx context.Context, groupID string, lastSyncAt int64, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	if _, err := s.GetMaster().Exec("UPDATE UserGroups SET LastSyncAt = :LastSyncAt WHERE Id = :Id", map[string]interface{}{"LastSyncAt": lastSyncAt, "Id": groupID}); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupSetLastSyncAt", "store.update_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	return result
+}
+
+// GroupGetStaleLdapGroups returns a page of the undeleted LDAP groups whose members were last synced before since,
+// oldest first. Groups that have never been synced are included once they were created before since.
+func (s *SqlSupplier) GroupGetStaleLdapGroups(ctx context.Context, since int64, page, perPage int, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	query, args, err := s.getQueryBuilder().
+		Select("*").
+		From("UserGroups").
+		Where(sq.Eq{"Source": model.GroupSourceLdap, "DeleteAt": 0}).
+		Where(sq.Lt{"LastSyncAt": since}).
+		Where(sq.Or{sq.Gt{"LastSyncAt": 0}, sq.Lt{"CreateAt": since}}).
+		OrderBy("LastSyncAt", "CreateAt", "Id").
+		Limit(uint64(perPage)).
+		Offset(uint64(page * perPage)).
+		ToSql()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetStaleLdapGroups", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	groups := []*model.Group{}
+	if _, err = s.GetReplica().Select(&groups, query, args...); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetStaleLdapGroups", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = groups
+	return result
+}
+
+// GroupGetChildGroupIds returns the ids of the undeleted groups whose parent is one of the given groups.
+func (s *SqlSupplier) GroupGetChildGroupIds(ctx context.Context, parentIDs []string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	childIds := []string{}
+	if len(parentIDs) == 0 {
+		result.Data = childIds
+		return result
+	}
+
+	query, args, err := s.getQueryBuilder().
+		Select("Id").
+		From("UserGroups").
+		Where(sq.Eq{"ParentGroupId": parentIDs, "DeleteAt": 0}).
+		ToSql()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetChildGroupIds", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	if _, err = s.GetReplica().Select(&childIds, query, args...); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetChildGroupIds", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = childIds
+
+	return result
+}
+
+// GroupGetNestedMemberUsersPage returns a page of the active users who are members of at least one of the groups,
+// each listed once.
+func (s *SqlSupplier) GroupGetNestedMemberUsersPage(ctx context.Context, groupIDs []string, page, perPage int, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	users := []*model.User{}
+	if len(groupIDs) == 0 {
+		result.Data = users
+		return result
+	}
+
+	membersQuery, membersArgs, _ := sq.Select("UserId").From("GroupMembers").Where(sq.Eq{"GroupId": groupIDs, "DeleteAt": 0}).ToSql()
+	query, args, err := s.getQueryBuilder().
+		Select("*").
+		From("Users").
+		Where(sq.Eq{"DeleteAt": 0}).
+		Where("Id IN ("+membersQuery+")", membersArgs...).
+		OrderBy("Username", "Id").
+		Limit(uint64(perPage)).
+		Offset(uint64(page * perPage)).
+		ToSql()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetNestedMemberUsersPage", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	if _, err = s.GetReplica().Select(&users, query, args...); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetNestedMemberUsersPage", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = users
+
+	return result
+}
+
+// GroupGetNestedMemberCount returns the number of active users who are members of at least one of the groups.
+func (s *SqlSupplier) GroupGetNestedMemberCount(ctx context.Context, groupIDs []string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	if len(groupIDs) == 0 {
+		result.Data = int64(0)
+		return result
+	}
+
+	membersQuery, membersArgs, _ := sq.Select("UserId").From("GroupMembers").Where(sq.Eq{"GroupId": groupIDs, "DeleteAt": 0}).ToSql()
+	query, args, err := s.getQueryBuilder().
+		Select("count(*)").
+		From("Users").
+		Where(sq.Eq{"DeleteAt": 0}).
+		Where("Id IN ("+membersQuery+")", membersArgs...).
+		ToSql()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetNestedMemberCount", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	count, err := s.GetReplica().SelectInt(query, args...)
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetNestedMemberCount", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = count
+
+	return result
+}
+
+// intersectionMembersQuery selects the ids of the users who are active members of every one of the groups.
+func intersectionMembersQuery(groupIDs []string) (string, []interface{}) {
+	query, args, _ := sq.Select("UserId").
+		From("GroupMembers").
+		Where(sq.Eq{"GroupId": groupIDs, "DeleteAt": 0}).
+		GroupBy("UserId").
+		Having("COUNT(DISTINCT GroupId) = ?", len(groupIDs)).
+		ToSql()
+	return query, args
+}
+
+// GroupGetIntersectionMemberUsersPage returns a page of the active users who are members of every one of the groups.
+func (s *SqlSupplier) GroupGetIntersectionMemberUsersPage(ctx context.Context, groupIDs []string, page, perPage int, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	users := []*model.User{}
+	if len(groupIDs) == 0 {
+		result.Data = users
+		return result
+	}
+
+	membersQuery, membersArgs := intersectionMembersQuery(groupIDs)
+	query, args, err := s.getQueryBuilder().
+		Select("*").
+		From("Users").
+		Where(sq.Eq{"DeleteAt": 0}).
+		Where("Id IN ("+membersQuery+")", membersArgs...).
+		OrderBy("Username", "Id").
+		Limit(uint64(perPage)).
+		Offset(uint64(page * perPage)).
+		ToSql()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetIntersectionMemberUsersPage", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	if _, err = s.GetReplica().Select(&users, query, args...); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetIntersectionMemberUsersPage", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = users
+
+	return result
+}
+
+// GroupGetIntersectionMemberCount returns the number of active users who are members of every one of the groups.
+func (s *SqlSupplier) GroupGetIntersectionMemberCount(ctx context.Context, groupIDs []string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	if len(groupIDs) == 0 {
+		result.Data = int64(0)
+		return result
+	}
+
+	membersQuery, membersArgs := intersectionMembersQuery(groupIDs)
+	query, args, err := s.getQueryBuilder().
+		Select("count(*)").
+		From("Users").
+		Where(sq.Eq{"DeleteAt": 0}).
+		Where("Id IN ("+membersQuery+")", membersArgs...).
+		ToSql()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetIntersectionMemberCount", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	count, err := s.GetReplica().SelectInt(query, args...)
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetIntersectionMemberCount", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = count
+
+	return result
+}
+
+// ungroupedUsersCondition matches the users who are not an active member of any undeleted group.
+const ungroupedUsersCondition = `NOT EXISTS (
+	SELECT 1
+	FROM GroupMembers
+	JOIN UserGroups ON UserGroups.Id = GroupMembers.GroupId
+	WHERE GroupMembers.UserId = Users.Id AND GroupMembers.DeleteAt = 0 AND UserGroups.DeleteAt = 0
+)`
+
+// GroupGetUngroupedUsers returns a page of the active users who are not members of any group.
+func (s *SqlSupplier) GroupGetUngroupedUsers(ctx context.Context, page, perPage int, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	query, args, err := s.getQueryBuilder().
+		Select("*").
+		From("Users").
+		Where(sq.Eq{"DeleteAt": 0}).
+		Where(ungroupedUsersCondition).
+		OrderBy("Username", "Id").
+		Limit(uint64(perPage)).
+		Offset(uint64(page * perPage)).
+		ToSql()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetUngroupedUsers", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	users := []*model.User{}
+	if _, err = s.GetReplica().Select(&users, query, args...); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetUngroupedUsers", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = users
+
+	return result
+}
 
-	switch groupSyncable.Type {
-	case model.GroupSyncableTypeTeam:
-		_, err = s.GetMaster().Update(groupSyncableToGroupTeam(groupSyncable))
-	case model.GroupSyncableTypeChannel:
-		_, err = s.GetMaster().Update(groupSyncableToGroupChannel(groupSyncable))
-	default:
-		model.NewAppError("SqlGroupStore.GroupDeleteGroupSyncable", "model.group_syncable.type.app_error", nil, "group_id="+groupSyncable.GroupId+", syncable_id="+groupSyncable.SyncableId+", "+err.Error(), http.StatusInternalServerError)
+// GroupGetUngroupedUserCount returns the number of active users who are not members of any group.
+func (s *SqlSupplier) GroupGetUngroupedUserCount(ctx context.Context, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	query, args, err := s.getQueryBuilder().
+		Select("count(*)").
+		From("Users").
+		Where(sq.Eq{"DeleteAt": 0}).
+		Where(ungroupedUsersCondition).
+		ToSql()
+	if err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetUngroupedUserCount", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
 		return result
 	}
 
+	count, err := s.GetReplica().SelectInt(query, args...)
 	if err != nil {
-		result.Err = model.NewAppError("SqlGroupStore.GroupDeleteGroupSyncable", "store.update_error", nil, err.Error(), http.StatusInternalServerError)
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetUngroupedUserCount", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
 		return result
 	}
 
-	result.Data = groupSyncable
+	result.Data = count
+
 	return result
 }
 
-// TeamMembersToAdd returns a slice of UserTeamIDPair that need newly created memberships
-// based on the groups configurations.
-//
-// Typically since will be the last successful group sync time.
-func (s *SqlSupplier) TeamMembersToAdd(ctx context.Context, since int64, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+// GroupGetMemberCountInChannel counts the active members of the group who are also members of the channel, that is
+// the users a mention of the group in the channel would notify.
+func (s *SqlSupplier) GroupGetMemberCountInChannel(ctx context.Context, groupID, channelID string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
 	result := store.NewSupplierResult()
 
-	sql := `
+	query := `
 		SELECT
-			GroupMembers.UserId, GroupTeams.TeamId
+			count(*)
 		FROM
 			GroupMembers
-			JOIN GroupTeams
-			ON GroupTeams.GroupId = GroupMembers.GroupId
-			JOIN UserGroups ON UserGroups.Id = GroupMembers.GroupId
-			JOIN Teams ON Teams.Id = GroupTeams.TeamId
-			LEFT OUTER JOIN TeamMembers
-			ON
-				TeamMembers.TeamId = GroupTeams.TeamId
-				AND TeamMembers.UserId = GroupMembers.UserId
+			JOIN ChannelMembers ON ChannelMembers.UserId = GroupMembers.UserId
+			JOIN Users ON Users.Id = GroupMembers.UserId
 		WHERE
-			TeamMembers.UserId IS NULL
-			AND UserGroups.DeleteAt = 0
-			AND GroupTeams.DeleteAt = 0
-			AND GroupTeams.AutoAdd = true
+			GroupMembers.GroupId = :GroupId
 			AND GroupMembers.DeleteAt = 0
-			AND Teams.DeleteAt = 0
-			AND (GroupMembers.CreateAt >= :Since
-			OR GroupTeams.UpdateAt >= :Since)`
-
-	var teamMembers []*model.UserTeamIDPair
+			AND ChannelMembers.ChannelId = :ChannelId
+			AND Users.DeleteAt = 0`
 
-	_, err := s.GetReplica().Select(&teamMembers, sql, map[string]interface{}{"Since": since})
+	count, err := s.GetReplica().SelectInt(query, map[string]interface{}{"GroupId": groupID, "ChannelId": channelID})
 	if err != nil {
-		result.Err = model.NewAppError("SqlGroupStore.TeamMembersToAdd", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetMemberCountInChannel", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
 	}
 
-	result.Data = teamMembers
+	result.Data = count
 
 	return result
 }
 
-// ChannelMembersToAdd returns a slice of UserChannelIDPair that need newly created memberships
-// based on the groups configurations.
-//
-// Typically since will be the last successful group sync time.
-func (s *SqlSupplier) ChannelMembersToAdd(ctx context.Context, since int64, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+// GroupGetMembersNotInChannel returns the ids of the active members of the group who do not belong to the channel.
+func (s *SqlSupplier) GroupGetMembersNotInChannel(ctx context.Context, groupID, channelID string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
 	result := store.NewSupplierResult()
 
-	sql := `
+	query := `
 		SELECT
-			GroupMembers.UserId, GroupChannels.ChannelId
+			GroupMembers.UserId
 		FROM
 			GroupMembers
-			JOIN GroupChannels ON GroupChannels.GroupId = GroupMembers.GroupId
-			JOIN UserGroups ON UserGroups.Id = GroupMembers.GroupId
-			JOIN Channels ON Channels.Id = GroupChannels.ChannelId
-			LEFT OUTER JOIN ChannelMemberHistory
+			JOIN Users ON Users.Id = GroupMembers.UserId
+			LEFT OUTER JOIN ChannelMembers
 			ON
-				ChannelMemberHistory.ChannelId = GroupChannels.ChannelId
-				AND ChannelMemberHistory.UserId = GroupMembers.UserId
+				ChannelMembers.ChannelId = :ChannelId
+				AND ChannelMembers.UserId = GroupMembers.UserId
 		WHERE
-			ChannelMemberHistory.UserId IS NULL
-			AND ChannelMemberHistory.LeaveTime IS NULL
-			AND UserGroups.DeleteAt = 0
-			AND GroupChannels.DeleteAt = 0
-			AND GroupChannels.AutoAdd = true
+			GroupMembers.GroupId = :GroupId
 			AND GroupMembers.DeleteAt = 0
-			AND Channels.DeleteAt = 0
-			AND (GroupMembers.CreateAt >= :Since
-			OR GroupChannels.UpdateAt >= :Since)`
+			AND Users.DeleteAt = 0
+			AND ChannelMembers.UserId IS NULL
+		ORDER BY
+			GroupMembers.UserId`
 
-	var channelMembers []*model.UserChannelIDPair
+	var userIDs []string
+	if _, err := s.GetMaster().Select(&userIDs, query, map[string]interface{}{"GroupId": groupID, "ChannelId": channelID}); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetMembersNotInChannel", "store.select_error", nil, "group_id="+groupID+", channel_id="+channelID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
 
-	_, err := s.GetReplica().Select(&channelMembers, sql, map[string]interface{}{"Since": since})
-	if err != nil {
-		result.Err = model.NewAppError("SqlGroupStore.ChannelMembersToAdd", "store.select_error", nil, "", http.StatusInternalServerError)
+	result.Data = userIDs
+
+	return result
+}
+
+// GroupGetMemberTimezones returns the timezone settings of each active member of the group.
+func (s *SqlSupplier) GroupGetMemberTimezones(ctx context.Context, groupID string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	query := `
+		SELECT
+			Users.Timezone
+		FROM
+			GroupMembers
+			JOIN Users ON Users.Id = GroupMembers.UserId
+		WHERE
+			GroupMembers.GroupId = :GroupId
+			AND GroupMembers.DeleteAt = 0
+			AND Users.DeleteAt = 0`
+
+	var timezones []map[string]string
+	if _, err := s.GetReplica().Select(&timezones, query, map[string]interface{}{"GroupId": groupID}); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetMemberTimezones", "store.select_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+		return result
 	}
 
-	result.Data = channelMembers
+	result.Data = timezones
 
 	return result
 }
 
-func groupSyncableToGroupTeam(groupSyncable *model.GroupSyncable) *groupTeam {
-	return &groupTeam{
-		GroupSyncable: *groupSyncable,
-		TeamId:        groupSyncable.SyncableId,
+func (s *SqlSupplier) GroupCreateChannelPattern(ctx context.Context, pattern *model.GroupChannelPattern, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	if result.Err = pattern.IsValid(); result.Err != nil {
+		return result
+	}
+
+	pattern.CreateAt = model.GetMillis()
+
+	if err := s.GetMaster().Insert(pattern); err != nil {
+		if IsUniqueConstraintError(err, []string{"GroupId", "groupchannelpatterns_pkey", "PRIMARY"}) {
+			result.Err = model.NewAppError("SqlGroupStore.GroupCreateChannelPattern", "store.sql_group.channel_pattern_exists", nil, err.Error(), http.StatusBadRequest)
+		} else {
+			result.Err = model.NewAppError("SqlGroupStore.GroupCreateChannelPattern", "store.insert_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+		return result
 	}
+
+	result.Data = pattern
+
+	return result
 }
 
-func groupSyncableToGroupChannel(groupSyncable *model.GroupSyncable) *groupChannel {
-	return &groupChannel{
-		GroupSyncable: *groupSyncable,
-		ChannelId:     groupSyncable.SyncableId,
+// GroupGetChannelPatterns returns the channel name patterns of the group, oldest first.
+func (s *SqlSupplier) GroupGetChannelPatterns(ctx context.Context, groupID string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+	result := store.NewSupplierResult()
+
+	patterns := []*model.GroupChannelPattern{}
+	if _, err := s.GetReplica().Select(&patterns, "SELECT * FROM GroupChannelPatterns WHERE GroupId = :GroupId ORDER BY CreateAt, Pattern", map[string]interface{}{"GroupId": groupID}); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetChannelPatterns", "store.select_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+		return result
 	}
+
+	result.Data = patterns
+
+	return result
 }
 
-// TeamMembersToRemove returns all team members that should be removed based on group constraints.
-func (s *SqlSupplier) TeamMembersToRemove(ctx context.Context, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+// GroupGetAllChannelPatterns returns the channel name patterns of every undeleted group.
+func (s *SqlSupplier) GroupGetAllChannelPatterns(ctx context.Context, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
 	result := store.NewSupplierResult()
 
-	sql := `
+	query := `
 		SELECT
-			TeamMembers.*
+			GroupChannelPatterns.*
 		FROM
-			TeamMembers
-			JOIN Teams ON Teams.Id = TeamMembers.TeamId
+			GroupChannelPatterns
+			JOIN UserGroups ON UserGroups.Id = GroupChannelPatterns.GroupId
 		WHERE
-			TeamMembers.DeleteAt = 0
-			AND Teams.DeleteAt = 0
-			AND Teams.GroupConstrained = TRUE
-			AND (TeamMembers.TeamId, TeamMembers.UserId)
-			NOT IN (
-				SELECT
-					Teams.Id AS TeamId, GroupMembers.UserId
-				FROM
-					Teams
-					JOIN GroupTeams ON GroupTeams.TeamId = Teams.Id
-					JOIN UserGroups ON UserGroups.Id = GroupTeams.GroupId
-					JOIN GroupMembers ON GroupMembers.GroupId = UserGroups.Id
-				WHERE
-					Teams.GroupConstrained = TRUE
-					AND GroupTeams.DeleteAt = 0
-					AND UserGroups.DeleteAt = 0
-					AND Teams.DeleteAt = 0
-					AND GroupMembers.DeleteAt = 0
-				GROUP BY
-					Teams.Id,
-					GroupMembers.UserId)`
-
-	var teamMembers []*model.TeamMember
+			UserGroups.DeleteAt = 0
+		ORDER BY
+			GroupChannelPatterns.GroupId, GroupChannelPatterns.CreateAt`
 
-	_, err := s.GetReplica().Select(&teamMembers, sql)
-	if err != nil {
-		result.Err = model.NewAppError("SqlGroupStore.TeamMembersToRemove", "store.select_error", nil, "", http.StatusInternalServerError)
+	patterns := []*model.GroupChannelPattern{}
+	if _, err := s.GetReplica().Select(&patterns, query); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetAllChannelPatterns", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		return result
 	}
 
-	result.Data = teamMembers
+	result.Data = patterns
 
 	return result
 }
 
-func (s *SqlSupplier) GetGroupsByChannel(ctx context.Context, channelId string, page, perPage int, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+// GroupGetUnlinkedChannelsCreatedSince returns the undeleted public and private channels created at or after since
+// whose names start with namePrefix and that have never been linked to the group. A link that was removed counts as
+// linked, so that channels an admin unlinked stay unlinked.
+func (s *SqlSupplier) GroupGetUnlinkedChannelsCreatedSince(ctx context.Context, groupID string, namePrefix string, since int64, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
 	result := store.NewSupplierResult()
 
-	var groups []*model.Group
-	offset := page * perPage
-	_, err := s.GetReplica().Select(&groups, `
-		SELECT
-			ug.*
-		FROM
-			GroupChannels gc
-		LEFT JOIN
-			UserGroups ug
-		ON
-			gc.GroupId = ug.Id
-		WHERE
-			ug.DeleteAt = 0
-		AND
-			gc.ChannelId = :ChannelId
-		ORDER BY
-			ug.DisplayName
-		LIMIT :Limit
-		OFFSET :Offset`,
-		map[string]interface{}{"ChannelId": channelId, "Limit": perPage, "Offset": offset})
+	linkedQuery, linkedArgs, _ := sq.Select("ChannelId").From("GroupChannels").Where(sq.Eq{"GroupId": groupID}).ToSql()
+
+	query := s.getQueryBuilder().
+		Select("*").
+		From("Channels").
+		Where(sq.Eq{"DeleteAt": 0, "Type": []string{model.CHANNEL_OPEN, model.CHANNEL_PRIVATE}}).
+		Where(sq.GtOrEq{"CreateAt": since}).
+		Where("Id NOT IN ("+linkedQuery+")", linkedArgs...).
+		OrderBy("CreateAt", "Id")
+
+	if len(namePrefix) > 0 {
+		for _, c := range escapeLikeSearchChar {
+			namePrefix = strings.Replace(namePrefix, c, "*"+c, -1)
+		}
+		query = query.Where("Name LIKE ? ESCAPE '*'", namePrefix+"%")
+	}
 
+	queryString, args, err := query.ToSql()
 	if err != nil {
-		result.Err = model.NewAppError("SqlGroupStore.GetGroupsByChannel", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetUnlinkedChannelsCreatedSince", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
 		return result
 	}
 
-	result.Data = groups
+	channels := []*model.Channel{}
+	if _, err = s.GetReplica().Select(&channels, queryString, args...); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetUnlinkedChannelsCreatedSince", "store.select_error", nil, "group_id="+groupID+", "+err.Error(), http.StatusInternalServerError)
+		return result
+	}
+
+	result.Data = channels
 
 	return result
 }
 
-// ChannelMembersToRemove returns all channel members that should be removed based on group constraints.
-func (s *SqlSupplier) ChannelMembersToRemove(ctx context.Context, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+// GroupCreateChannelExemption exempts the user from the group constraint of the channel. Exempting a user twice keeps
+// the first exemption.
+func (s *SqlSupplier) GroupCreateChannelExemption(ctx context.Context, exemption *model.GroupChannelExemption, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
 	result := store.NewSupplierResult()
 
-	sql := `
-		SELECT
-			ChannelMembers.*
-		FROM
-			ChannelMembers
-			JOIN Channels ON Channels.Id = ChannelMembers.ChannelId
-		WHERE
-			Channels.DeleteAt = 0
-			AND Channels.GroupConstrained = TRUE
-			AND (ChannelMembers.ChannelId, ChannelMembers.UserId)
-			NOT IN (
-				SELECT
-					Channels.Id AS ChannelId, GroupMembers.UserId
-				FROM
-					Channels
-					JOIN GroupChannels ON GroupChannels.ChannelId = Channels.Id
-					JOIN UserGroups ON UserGroups.Id = GroupChannels.GroupId
-					JOIN GroupMembers ON GroupMembers.GroupId = UserGroups.Id
-				WHERE
-					Channels.GroupConstrained = TRUE
-					AND GroupChannels.DeleteAt = 0
-					AND UserGroups.DeleteAt = 0
-					AND Channels.DeleteAt = 0
-					AND GroupMembers.DeleteAt = 0
-				GROUP BY
-					Channels.Id,
-					GroupMembers.UserId)`
+	if result.Err = exemption.IsValid(); result.Err != nil {
+		return result
+	}
 
-	var channelMembers []*model.ChannelMember
+	exemption.CreateAt = model.GetMillis()
 
-	_, err := s.GetReplica().Select(&channelMembers, sql)
-	if err != nil {
-		result.Err = model.NewAppError("SqlGroupStore.ChannelMembersToRemove", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+	if err := s.GetMaster().Insert(exemption); err != nil {
+		if !IsUniqueConstraintError(err, []string{"ChannelId", "groupchannelexemptions_pkey", "PRIMARY"}) {
+			result.Err = model.NewAppError("SqlGroupStore.GroupCreateChannelExemption", "store.insert_error", nil, err.Error(), http.StatusInternalServerError)
+			return result
+		}
+
+		var existing *model.GroupChannelExemption
+		if err = s.GetMaster().SelectOne(&existing, "SELECT * FROM GroupChannelExemptions WHERE ChannelId = :ChannelId AND UserId = :UserId", map[string]interface{}{"ChannelId": exemption.ChannelId, "UserId": exemption.UserId}); err != nil {
+			result.Err = model.NewAppError("SqlGroupStore.GroupCreateChannelExemption", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+			return result
+		}
+		exemption = existing
 	}
 
-	result.Data = channelMembers
+	result.Data = exemption
 
 	return result
 }
 
-func (s *SqlSupplier) GetGroupsByTeam(ctx context.Context, teamId string, page, perPage int, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
+// GroupGetChannelMembershipConflicts returns the members of the channel who belong to a group whose link to the channel
+// excludes its members, along with every group of theirs linked to the channel, ordered by user id.
+func (s *SqlSupplier) GroupGetChannelMembershipConflicts(ctx context.Context, channelID string, hints ...store.LayeredStoreHint) *store.LayeredStoreSupplierResult {
 	result := store.NewSupplierResult()
 
-	var groups []*model.Group
-	offset := page * perPage
-	_, err := s.GetReplica().Select(&groups, `
+	query := `
 		SELECT
-			ug.*
+			GroupMembers.UserId, GroupChannels.GroupId, GroupChannels.ExcludeMembers
 		FROM
-			GroupTeams gt
-		LEFT JOIN
-			UserGroups ug
-		ON
-			gt.GroupId = ug.Id
+			ChannelMembers
+			JOIN GroupMembers ON GroupMembers.UserId = ChannelMembers.UserId
+			JOIN GroupChannels
+			ON
+				GroupChannels.GroupId = GroupMembers.GroupId
+				AND GroupChannels.ChannelId = ChannelMembers.ChannelId
+			JOIN UserGroups ON UserGroups.Id = GroupChannels.GroupId
 		WHERE
-			ug.DeleteAt = 0
-		AND
-			gt.TeamId = :TeamId
+			ChannelMembers.ChannelId = :ChannelId
+			AND GroupMembers.DeleteAt = 0
+			AND GroupChannels.DeleteAt = 0
+			AND UserGroups.DeleteAt = 0
+			AND GroupMembers.UserId IN (
+				SELECT
+					ExcludedMembers.UserId
+				FROM
+					GroupChannels ExcludingChannels
+					JOIN UserGroups ExcludingGroups ON ExcludingGroups.Id = ExcludingChannels.GroupId
+					JOIN GroupMembers ExcludedMembers ON ExcludedMembers.GroupId = ExcludingChannels.GroupId
+				WHERE
+					ExcludingChannels.ChannelId = :ChannelId
+					AND ExcludingChannels.ExcludeMembers = TRUE
+					AND ExcludingChannels.DeleteAt = 0
+					AND ExcludingGroups.DeleteAt = 0
+					AND ExcludedMembers.DeleteAt = 0)
 		ORDER BY
-			ug.DisplayName
-		LIMIT :Limit
-		OFFSET :Offset`,
-		map[string]interface{}{"TeamId": teamId, "Limit": perPage, "Offset": offset})
+			GroupMembers.UserId, GroupChannels.GroupId`
 
-	if err != nil {
-		result.Err = model.NewAppError("SqlGroupStore.GetGroupsByTeam", "store.select_error", nil, err.Error(), http.StatusInternalServerError)
+	var rows []struct {
+		UserId         string
+		GroupId        string
+		ExcludeMembers bool
+	}
+	if _, err := s.GetReplica().Select(&rows, query, map[string]interface{}{"ChannelId": channelID}); err != nil {
+		result.Err = model.NewAppError("SqlGroupStore.GroupGetChannelMembershipConflicts", "store.select_error", nil, "channel_id="+channelID+", "+err.Error(), http.StatusInternalServerError)
 		return result
 	}
 
-	result.Data = groups
+	conflicts := []*model.GroupMembershipConflict{}
+	for _, row := range rows {
+		if len(conflicts) == 0 || conflicts[len(conflicts)-1].UserId != row.UserId {
+			conflicts = append(conflicts, &model.GroupMembershipConflict{
+				UserId:             row.UserId,
+				ExcludedByGroupIds: []string{},
+				IncludedByGroupIds: []string{},
+			})
+		}
+
+		conflict := conflicts[len(conflicts)-1]
+		if row.ExcludeMembers {
+			conflict.ExcludedByGroupIds = append(conflict.ExcludedByGroupIds, row.GroupId)
+		} else {
+			conflict.IncludedByGroupIds = append(conflict.IncludedByGroupIds, row.GroupId)
+		}
+	}
+
+	result.Data = conflicts
 
 	return result
 }
@@ -0,0 +1,159 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package sqlstore
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+)
+
+// idempotencyKey is the row recorded for a model.IdempotentResponse. The response headers are stored as JSON.
+type idempotencyKey struct {
+	Id          string
+	RequestHash string
+	StatusCode  int
+	Header      string
+	Body        []byte
+	Pending     bool
+	ExpiresAt   int64
+}
+
+func newIdempotencyKey(key string, response *model.IdempotentResponse, expiresAt int64) (*idempotencyKey, error) {
+	header, err := json.Marshal(response.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	return &idempotencyKey{
+		Id:          key,
+		RequestHash: response.RequestHash,
+		StatusCode:  response.StatusCode,
+		Header:      string(header),
+		Body:        response.Body,
+		Pending:     response.Pending,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+func (k *idempotencyKey) toModel() (*model.IdempotentResponse, error) {
+	var header http.Header
+	if err := json.Unmarshal([]byte(k.Header), &header); err != nil {
+		return nil, err
+	}
+
+	return &model.IdempotentResponse{
+		RequestHash: k.RequestHash,
+		StatusCode:  k.StatusCode,
+		Header:      header,
+		Body:        k.Body,
+		Pending:     k.Pending,
+	}, nil
+}
+
+type SqlIdempotencyStore struct {
+	SqlStore
+}
+
+func NewSqlIdempotencyStore(sqlStore SqlStore) store.IdempotencyStore {
+	s := &SqlIdempotencyStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(idempotencyKey{}, "IdempotencyKeys").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(64)
+		table.ColMap("RequestHash").SetMaxSize(64)
+		table.ColMap("Header").SetMaxSize(8000)
+	}
+
+	return s
+}
+
+func (s SqlIdempotencyStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_idempotency_keys_expires_at", "IdempotencyKeys", "ExpiresAt")
+}
+
+// Reserve records the pending response for the key unless an unexpired response is already recorded for it, in which
+// case that response is returned and nothing is changed. The primary key on Id makes the reservation atomic across
+// every node of a cluster.
+func (s SqlIdempotencyStore) Reserve(key string, pending *model.IdempotentResponse, expiresAt int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		row, err := newIdempotencyKey(key, pending, expiresAt)
+		if err != nil {
+			result.Err = model.NewAppError("SqlIdempotencyStore.Reserve", "store.sql_idempotency.reserve.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		err = s.GetMaster().Insert(row)
+		if err == nil {
+			return
+		}
+		if !IsUniqueConstraintError(err, []string{"PRIMARY", "idempotencykeys_pkey"}) {
+			result.Err = model.NewAppError("SqlIdempotencyStore.Reserve", "store.sql_idempotency.reserve.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// The key is taken. An expired reservation is replaced, otherwise the recorded response is returned.
+		if _, err = s.GetMaster().Exec("DELETE FROM IdempotencyKeys WHERE Id = :Id AND ExpiresAt < :Now", map[string]interface{}{"Id": key, "Now": model.GetMillis()}); err != nil {
+			result.Err = model.NewAppError("SqlIdempotencyStore.Reserve", "store.sql_idempotency.reserve.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		err = s.GetMaster().Insert(row)
+		if err == nil {
+			return
+		}
+		if !IsUniqueConstraintError(err, []string{"PRIMARY", "idempotencykeys_pkey"}) {
+			result.Err = model.NewAppError("SqlIdempotencyStore.Reserve", "store.sql_idempotency.reserve.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var existing idempotencyKey
+		if err = s.GetMaster().SelectOne(&existing, "SELECT * FROM IdempotencyKeys WHERE Id = :Id", map[string]interface{}{"Id": key}); err != nil {
+			result.Err = model.NewAppError("SqlIdempotencyStore.Reserve", "store.sql_idempotency.reserve.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		response, err := existing.toModel()
+		if err != nil {
+			result.Err = model.NewAppError("SqlIdempotencyStore.Reserve", "store.sql_idempotency.reserve.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = response
+	})
+}
+
+// Save records the response for a key reserved with Reserve. Nothing is recorded if the reservation has since been
+// deleted.
+func (s SqlIdempotencyStore) Save(key string, response *model.IdempotentResponse, expiresAt int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		row, err := newIdempotencyKey(key, response, expiresAt)
+		if err != nil {
+			result.Err = model.NewAppError("SqlIdempotencyStore.Save", "store.sql_idempotency.save.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if _, err = s.GetMaster().Update(row); err != nil {
+			result.Err = model.NewAppError("SqlIdempotencyStore.Save", "store.sql_idempotency.save.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+func (s SqlIdempotencyStore) Delete(key string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec("DELETE FROM IdempotencyKeys WHERE Id = :Id", map[string]interface{}{"Id": key}); err != nil {
+			result.Err = model.NewAppError("SqlIdempotencyStore.Delete", "store.sql_idempotency.delete.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+func (s SqlIdempotencyStore) Cleanup() {
+	mlog.Debug("Cleaning up idempotency key store.")
+	if _, err := s.GetMaster().Exec("DELETE FROM IdempotencyKeys WHERE ExpiresAt < :Now", map[string]interface{}{"Now": model.GetMillis()}); err != nil {
+		mlog.Error("Unable to cleanup idempotency key store.", mlog.Err(err))
+	}
+}
@@ -687,6 +687,35 @@ func UpgradeDatabaseToVersion512(sqlStore SqlStore) {
 	sqlStore.CreateColumnIfNotExistsNoDefault("Schemes", "DefaultTeamGuestRole", "text", "VARCHAR(64)")
 	sqlStore.CreateColumnIfNotExistsNoDefault("Schemes", "DefaultChannelGuestRole", "text", "VARCHAR(64)")
 	sqlStore.GetMaster().Exec("UPDATE Schemes SET DefaultTeamGuestRole = '', DefaultChannelGuestRole = ''")
+	sqlStore.CreateColumnIfNotExists("UserGroups", "Tags", "varchar(2048)", "varchar(2048)", "[]")
+	sqlStore.CreateColumnIfNotExists("GroupMembers", "Roles", "varchar(64)", "varchar(64)", "")
+	sqlStore.CreateColumnIfNotExists("GroupTeams", "SchemeAdmin", "boolean", "boolean", "0")
+	sqlStore.CreateColumnIfNotExists("GroupChannels", "SchemeAdmin", "boolean", "boolean", "0")
+	sqlStore.CreateColumnIfNotExists("GroupTeams", "SuppressNotifications", "boolean", "boolean", "0")
+	sqlStore.CreateColumnIfNotExists("GroupChannels", "SuppressNotifications", "boolean", "boolean", "0")
+	sqlStore.CreateColumnIfNotExists("UserGroups", "AllowReference", "boolean", "boolean", "0")
+	sqlStore.CreateColumnIfNotExists("UserGroups", "MembershipWebhookURL", "varchar(512)", "varchar(512)", "")
+	sqlStore.CreateColumnIfNotExists("GroupMembers", "ExpiresAt", "bigint", "bigint", "0")
+	sqlStore.CreateColumnIfNotExists("UserGroups", "IsDefault", "boolean", "boolean", "0")
+	sqlStore.CreateColumnIfNotExists("UserGroups", "ParentGroupId", "varchar(26)", "varchar(26)", "")
+	sqlStore.CreateColumnIfNotExists("UserGroups", "SyncPaused", "boolean", "boolean", "0")
+	sqlStore.CreateColumnIfNotExists("GroupTeams", "ExpiresAt", "bigint", "bigint", "0")
+	sqlStore.CreateColumnIfNotExists("GroupChannels", "ExpiresAt", "bigint", "bigint", "0")
+	sqlStore.CreateColumnIfNotExists("GroupTeams", "Roles", "varchar(64)", "varchar(64)", "")
+	sqlStore.CreateColumnIfNotExists("GroupChannels", "Roles", "varchar(64)", "varchar(64)", "")
+	sqlStore.CreateColumnIfNotExists("UserGroups", "NameCollision", "boolean", "boolean", "0")
+	sqlStore.CreateColumnIfNotExists("UserGroups", "LastSyncAt", "bigint", "bigint", "0")
+	sqlStore.CreateColumnIfNotExists("UserGroups", "DescriptionIsMarkdown", "boolean", "boolean", "0")
+	sqlStore.CreateColumnIfNotExists("UserGroups", "OwnerId", "varchar(26)", "varchar(26)", "")
+	if sqlStore.CreateColumnIfNotExists("UserGroups", "NameLower", "varchar(64)", "varchar(64)", "") {
+		sqlStore.GetMaster().Exec("UPDATE UserGroups SET NameLower = LOWER(Name)")
+	}
+	if sqlStore.CreateColumnIfNotExists("GroupMembers", "Source", "varchar(64)", "varchar(64)", "") {
+		// Existing members of LDAP and SAML groups came from their sync; anyone else was added by hand.
+		sqlStore.GetMaster().Exec("UPDATE GroupMembers SET Source = COALESCE((SELECT UserGroups.Source FROM UserGroups WHERE UserGroups.Id = GroupMembers.GroupId AND UserGroups.Source IN ('ldap', 'saml')), 'manual')")
+	}
+	sqlStore.CreateColumnIfNotExists("GroupTeams", "ExcludeMembers", "boolean", "boolean", "0")
+	sqlStore.CreateColumnIfNotExists("GroupChannels", "ExcludeMembers", "boolean", "boolean", "0")
 
 	// saveSchemaVersion(sqlStore, VERSION_5_12_0)
 	// }
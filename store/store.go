@@ -70,6 +70,7 @@ type Store interface {
 	Group() GroupStore
 	UserTermsOfService() UserTermsOfServiceStore
 	LinkMetadata() LinkMetadataStore
+	Idempotency() IdempotencyStore
 	MarkSystemRanUnitTests()
 	Close()
 	LockToMaster()
@@ -577,10 +578,12 @@ type GroupStore interface {
 	Delete(groupID string) StoreChannel
 
 	GetMemberUsers(groupID string) StoreChannel
-	GetMemberUsersPage(groupID string, offset int, limit int) StoreChannel
+	GetMemberUsersPage(groupID string, page int, perPage int) StoreChannel
 	GetMemberCount(groupID string) StoreChannel
 	CreateOrRestoreMember(groupID string, userID string) StoreChannel
 	DeleteMember(groupID string, userID string) StoreChannel
+	UpsertMembers(groupID string, userIDs []string) StoreChannel
+	DeleteMembers(groupID string, userIDs []string) StoreChannel
 
 	CreateGroupSyncable(groupSyncable *model.GroupSyncable) StoreChannel
 	GetGroupSyncable(groupID string, syncableID string, syncableType model.GroupSyncableType) StoreChannel
@@ -594,11 +597,64 @@ type GroupStore interface {
 	TeamMembersToRemove() StoreChannel
 	ChannelMembersToRemove() StoreChannel
 
-	GetGroupsByChannel(channelId string, page, perPage int) StoreChannel
-	GetGroupsByTeam(teamId string, page, perPage int) StoreChannel
+	GetGroupsByChannel(channelId string, page, perPage int, opts model.GroupSearchOpts) StoreChannel
+	GetGroupsByTeam(teamId string, page, perPage int, opts model.GroupSearchOpts) StoreChannel
+	GetGroups(page, perPage int, opts model.GroupSearchOpts) StoreChannel
+	MergeGroups(targetID string, sourceID string) StoreChannel
+	GetMembers(groupID string, userIDs []string) StoreChannel
+	UpdateMember(member *model.GroupMember) StoreChannel
+	GetGroupsByUserId(userID string) StoreChannel
+	InvalidateMembershipCacheForUser(userID string)
+	Autocomplete(namePrefix string, limit int) StoreChannel
+	GetDeleteImpact(groupID string) StoreChannel
+	GetMemberIds(groupID string, limit int) StoreChannel
+	GetUnusedGroups(page, perPage int, opts model.UnusedGroupSearchOpts) StoreChannel
+	GetGroupsLastUpdateAt(opts model.GroupSearchOpts) StoreChannel
+	GetExpiredMembers(now int64, limit int) StoreChannel
+	GetMemberUsersExpiringPage(groupID string, before int64, page, perPage int) StoreChannel
+	GetExpiringMemberCount(groupID string, before int64) StoreChannel
+	LogMemberEvents(groupID string, userIDs []string, action string) StoreChannel
+	GetMemberHistory(groupID string, from, to int64, page, perPage int) StoreChannel
+	GetChildGroupIds(parentIDs []string) StoreChannel
+	GetNestedMemberUsersPage(groupIDs []string, page, perPage int) StoreChannel
+	GetNestedMemberCount(groupIDs []string) StoreChannel
+	GetMemberTimezones(groupID string) StoreChannel
+	CreateChannelPattern(pattern *model.GroupChannelPattern) StoreChannel
+	GetChannelPatterns(groupID string) StoreChannel
+	GetAllChannelPatterns() StoreChannel
+	GetUnlinkedChannelsCreatedSince(groupID string, namePrefix string, since int64) StoreChannel
+	GetByIDs(groupIDs []string) StoreChannel
+	GetMemberCountInChannel(groupID, channelID string) StoreChannel
+	GetByNames(names []string) StoreChannel
+	GetMemberIdsPage(groupID string, page, perPage int) StoreChannel
+	GetGroupsByMemberEmailDomain(domain string, page, perPage int) StoreChannel
+	DeleteAllMembers(groupID string) StoreChannel
+	GetLinkedSyncableIds(groupIDs []string, syncableIDs []string, syncableType model.GroupSyncableType) StoreChannel
+	GetExpiredGroupSyncables(syncableType model.GroupSyncableType, now int64, limit int) StoreChannel
+	GetMembersNotInChannel(groupID, channelID string) StoreChannel
+	GetGroupsMissingTeamLink(channelID string) StoreChannel
+	UpdateMetadata(groups []*model.Group) StoreChannel
+	GetIntersectionMemberUsersPage(groupIDs []string, page, perPage int) StoreChannel
+	GetIntersectionMemberCount(groupIDs []string) StoreChannel
+	SetLastSyncAt(groupID string, lastSyncAt int64) StoreChannel
+	GetStaleLdapGroups(since int64, page, perPage int) StoreChannel
+	GetUngroupedUsers(page, perPage int) StoreChannel
+	GetUngroupedUserCount() StoreChannel
+	CreateChannelExemption(exemption *model.GroupChannelExemption) StoreChannel
+	GroupSyncableExists(groupID string, syncableID string, syncableType model.GroupSyncableType) StoreChannel
+	GetStats(groupID string, includeDeleted bool) StoreChannel
+	GetTeamGroupSyncables(teamID string, page int, perPage int) StoreChannel
+	GetChannelMembershipConflicts(channelID string) StoreChannel
 }
 
 type LinkMetadataStore interface {
 	Save(linkMetadata *model.LinkMetadata) StoreChannel
 	Get(url string, timestamp int64) StoreChannel
 }
+
+type IdempotencyStore interface {
+	Reserve(key string, pending *model.IdempotentResponse, expiresAt int64) StoreChannel
+	Save(key string, response *model.IdempotentResponse, expiresAt int64) StoreChannel
+	Delete(key string) StoreChannel
+	Cleanup()
+}
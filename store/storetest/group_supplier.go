@@ -4,8 +4,11 @@
 package storetest
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/store"
@@ -15,30 +18,61 @@ import (
 func TestGroupStore(t *testing.T, ss store.Store) {
 	t.Run("Create", func(t *testing.T) { testGroupStoreCreate(t, ss) })
 	t.Run("Get", func(t *testing.T) { testGroupStoreGet(t, ss) })
+	t.Run("GetByIDs", func(t *testing.T) { testGroupStoreGetByIDs(t, ss) })
+	t.Run("GetByNames", func(t *testing.T) { testGroupStoreGetByNames(t, ss) })
 	t.Run("GetByRemoteID", func(t *testing.T) { testGroupStoreGetByRemoteID(t, ss) })
 	t.Run("GetAllBySource", func(t *testing.T) { testGroupStoreGetAllByType(t, ss) })
 	t.Run("Update", func(t *testing.T) { testGroupStoreUpdate(t, ss) })
+	t.Run("UpdateMetadata", func(t *testing.T) { testGroupStoreUpdateMetadata(t, ss) })
 	t.Run("Delete", func(t *testing.T) { testGroupStoreDelete(t, ss) })
 
 	t.Run("GetMemberUsers", func(t *testing.T) { testGroupGetMemberUsers(t, ss) })
 	t.Run("GetMemberUsersPage", func(t *testing.T) { testGroupGetMemberUsersPage(t, ss) })
 	t.Run("CreateOrRestoreMember", func(t *testing.T) { testGroupCreateOrRestoreMember(t, ss) })
 	t.Run("DeleteMember", func(t *testing.T) { testGroupDeleteMember(t, ss) })
+	t.Run("UpsertMembers", func(t *testing.T) { testGroupUpsertMembers(t, ss) })
+	t.Run("DeleteMembers", func(t *testing.T) { testGroupDeleteMembers(t, ss) })
+	t.Run("DeleteAllMembers", func(t *testing.T) { testGroupDeleteAllMembers(t, ss) })
+	t.Run("UpdateMember", func(t *testing.T) { testGroupUpdateMember(t, ss) })
+	t.Run("MembersBatches", func(t *testing.T) { testGroupMembersBatches(t, ss) })
+	t.Run("ExpiringMembers", func(t *testing.T) { testGroupExpiringMembers(t, ss) })
+	t.Run("MemberHistory", func(t *testing.T) { testGroupMemberHistory(t, ss) })
+	t.Run("NestedMembers", func(t *testing.T) { testGroupNestedMembers(t, ss) })
+	t.Run("ChannelPatterns", func(t *testing.T) { testGroupChannelPatterns(t, ss) })
+	t.Run("MemberCountInChannel", func(t *testing.T) { testGroupMemberCountInChannel(t, ss) })
+	t.Run("MembersNotInChannel", func(t *testing.T) { testGroupMembersNotInChannel(t, ss) })
+	t.Run("MergeGroups", func(t *testing.T) { testMergeGroups(t, ss) })
 
 	t.Run("CreateGroupSyncable", func(t *testing.T) { testCreateGroupSyncable(t, ss) })
 	t.Run("GetGroupSyncable", func(t *testing.T) { testGetGroupSyncable(t, ss) })
+	t.Run("GroupSyncableExists", func(t *testing.T) { testGroupSyncableExists(t, ss) })
 	t.Run("GetAllGroupSyncablesByGroupId", func(t *testing.T) { testGetAllGroupSyncablesByGroup(t, ss) })
+	t.Run("GetLinkedSyncableIds", func(t *testing.T) { testGetLinkedSyncableIds(t, ss) })
+	t.Run("GetExpiredGroupSyncables", func(t *testing.T) { testGetExpiredGroupSyncables(t, ss) })
+	t.Run("GetGroupsMissingTeamLink", func(t *testing.T) { testGetGroupsMissingTeamLink(t, ss) })
 	t.Run("UpdateGroupSyncable", func(t *testing.T) { testUpdateGroupSyncable(t, ss) })
 	t.Run("DeleteGroupSyncable", func(t *testing.T) { testDeleteGroupSyncable(t, ss) })
 
 	t.Run("TeamMembersToAdd", func(t *testing.T) { testPendingAutoAddTeamMembers(t, ss) })
 	t.Run("ChannelMembersToAdd", func(t *testing.T) { testPendingAutoAddChannelMembers(t, ss) })
+	t.Run("ChannelMembersToAddExcludedMembers", func(t *testing.T) { testPendingAutoAddChannelMembersExcluded(t, ss) })
+	t.Run("GetChannelMembershipConflicts", func(t *testing.T) { testGetChannelMembershipConflicts(t, ss) })
 
 	t.Run("TeamMembersToRemove", func(t *testing.T) { testPendingTeamMemberRemovals(t, ss) })
 	t.Run("ChannelMembersToRemove", func(t *testing.T) { testPendingChannelMemberRemovals(t, ss) })
 
 	t.Run("GetGroupsByChannel", func(t *testing.T) { testGetGroupsByChannel(t, ss) })
+	t.Run("GetGroupsByChannelIncludingTeam", func(t *testing.T) { testGetGroupsByChannelIncludingTeam(t, ss) })
 	t.Run("GetGroupsByTeam", func(t *testing.T) { testGetGroupsByTeam(t, ss) })
+	t.Run("GetGroupsByTeamIncludingChannels", func(t *testing.T) { testGetGroupsByTeamIncludingChannels(t, ss) })
+	t.Run("GetGroups", func(t *testing.T) { testGetGroups(t, ss) })
+	t.Run("GetGroupsLocaleSort", func(t *testing.T) { testGetGroupsLocaleSort(t, ss) })
+	t.Run("GetGroupsByTag", func(t *testing.T) { testGetGroupsByTag(t, ss) })
+	t.Run("GetGroupsExcludeDefault", func(t *testing.T) { testGetGroupsExcludeDefault(t, ss) })
+	t.Run("Autocomplete", func(t *testing.T) { testGroupAutocomplete(t, ss) })
+	t.Run("GetGroupsLastUpdateAt", func(t *testing.T) { testGetGroupsLastUpdateAt(t, ss) })
+	t.Run("GetUnusedGroups", func(t *testing.T) { testGetUnusedGroups(t, ss) })
+	t.Run("GetGroupsByMemberEmailDomain", func(t *testing.T) { testGetGroupsByMemberEmailDomain(t, ss) })
 }
 
 func testGroupStoreCreate(t *testing.T, ss store.Store) {
@@ -102,6 +136,25 @@ func testGroupStoreCreate(t *testing.T, ss store.Store) {
 	require.Nil(t, res5b.Data)
 	require.Equal(t, res5b.Err.Id, "store.sql_group.unique_constraint")
 
+	// Nor one only differing in case
+	suffix := model.NewId()
+	res5c := <-ss.Group().Create(&model.Group{
+		Name:        "Engineering" + suffix,
+		DisplayName: model.NewId(),
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, res5c.Err)
+	require.Equal(t, "engineering"+suffix, res5c.Data.(*model.Group).NameLower)
+	res5d := <-ss.Group().Create(&model.Group{
+		Name:        "engineering" + suffix,
+		DisplayName: model.NewId(),
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, res5d.Data)
+	require.Equal(t, res5d.Err.Id, "store.sql_group.unique_constraint")
+
 	// Fields cannot be greater than max values
 	g5 := &model.Group{
 		Name:        strings.Repeat("x", model.GroupNameMaxLength),
@@ -171,6 +224,80 @@ func testGroupStoreGet(t *testing.T, ss store.Store) {
 	require.Equal(t, res3.Err.Id, "store.sql_group.no_rows")
 }
 
+func testGroupStoreGetByIDs(t *testing.T, ss store.Store) {
+	var groups []*model.Group
+	for i := 0; i < 3; i++ {
+		res := <-ss.Group().Create(&model.Group{
+			Name:        model.NewId(),
+			DisplayName: model.NewId(),
+			Source:      model.GroupSourceLdap,
+			RemoteId:    model.NewId(),
+		})
+		require.Nil(t, res.Err)
+		groups = append(groups, res.Data.(*model.Group))
+	}
+
+	res := <-ss.Group().Delete(groups[2].Id)
+	require.Nil(t, res.Err)
+
+	// Unknown and deleted groups are skipped
+	res = <-ss.Group().GetByIDs([]string{groups[0].Id, groups[1].Id, groups[2].Id, model.NewId()})
+	require.Nil(t, res.Err)
+	var ids []string
+	for _, group := range res.Data.([]*model.Group) {
+		ids = append(ids, group.Id)
+	}
+	require.ElementsMatch(t, []string{groups[0].Id, groups[1].Id}, ids)
+
+	res = <-ss.Group().GetByIDs([]string{})
+	require.Nil(t, res.Err)
+	require.Empty(t, res.Data.([]*model.Group))
+}
+
+func testGroupStoreGetByNames(t *testing.T, ss store.Store) {
+	var groups []*model.Group
+	for i := 0; i < 3; i++ {
+		res := <-ss.Group().Create(&model.Group{
+			Name:        model.NewId(),
+			DisplayName: model.NewId(),
+			Source:      model.GroupSourceCustom,
+			RemoteId:    model.NewId(),
+		})
+		require.Nil(t, res.Err)
+		groups = append(groups, res.Data.(*model.Group))
+	}
+
+	res := <-ss.Group().Delete(groups[2].Id)
+	require.Nil(t, res.Err)
+
+	// Unknown and deleted groups are skipped
+	res = <-ss.Group().GetByNames([]string{groups[0].Name, groups[1].Name, groups[2].Name, model.NewId()})
+	require.Nil(t, res.Err)
+	var ids []string
+	for _, group := range res.Data.([]*model.Group) {
+		ids = append(ids, group.Id)
+	}
+	require.ElementsMatch(t, []string{groups[0].Id, groups[1].Id}, ids)
+
+	res = <-ss.Group().GetByNames([]string{})
+	require.Nil(t, res.Err)
+	require.Empty(t, res.Data.([]*model.Group))
+
+	// Names are matched regardless of case
+	res = <-ss.Group().Create(&model.Group{
+		Name:        "Engineering" + model.NewId(),
+		DisplayName: model.NewId(),
+		Source:      model.GroupSourceCustom,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, res.Err)
+	mixedCase := res.Data.(*model.Group)
+	res = <-ss.Group().GetByNames([]string{strings.ToLower(mixedCase.Name)})
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data.([]*model.Group), 1)
+	require.Equal(t, mixedCase.Id, res.Data.([]*model.Group)[0].Id)
+}
+
 func testGroupStoreGetByRemoteID(t *testing.T, ss store.Store) {
 	// Create a group
 	g1 := &model.Group{
@@ -239,6 +366,77 @@ func testGroupStoreGetAllByType(t *testing.T, ss store.Store) {
 	}
 }
 
+func testGroupStoreUpdateMetadata(t *testing.T, ss store.Store) {
+	var groups []*model.Group
+	for i := 0; i < 4; i++ {
+		res := <-ss.Group().Create(&model.Group{
+			Name:        model.NewId(),
+			DisplayName: fmt.Sprintf("dn_%d", i),
+			Description: fmt.Sprintf("description_%d", i),
+			Source:      model.GroupSourceLdap,
+			RemoteId:    model.NewId(),
+		})
+		require.Nil(t, res.Err)
+		groups = append(groups, res.Data.(*model.Group))
+	}
+	res := <-ss.Group().Delete(groups[3].Id)
+	require.Nil(t, res.Err)
+
+	time.Sleep(2 * time.Millisecond)
+
+	// The first group gets a new display name, the second a new description and the third is unchanged. The deleted
+	// group and an unknown group are skipped.
+	updates := []*model.Group{
+		{Id: groups[0].Id, DisplayName: "new_dn_0", Description: groups[0].Description},
+		{Id: groups[1].Id, DisplayName: groups[1].DisplayName, Description: "new_description_1"},
+		{Id: groups[2].Id, DisplayName: groups[2].DisplayName, Description: groups[2].Description},
+		{Id: groups[3].Id, DisplayName: "new_dn_3", Description: groups[3].Description},
+		{Id: model.NewId(), DisplayName: "new_dn_4"},
+	}
+	res = <-ss.Group().UpdateMetadata(updates)
+	require.Nil(t, res.Err)
+	require.Equal(t, 2, res.Data.(int))
+
+	for i, expected := range []struct {
+		displayName string
+		description string
+		bumped      bool
+	}{
+		{"new_dn_0", "description_0", true},
+		{"dn_1", "new_description_1", true},
+		{"dn_2", "description_2", false},
+	} {
+		res = <-ss.Group().Get(groups[i].Id)
+		require.Nil(t, res.Err)
+		group := res.Data.(*model.Group)
+		require.Equal(t, expected.displayName, group.DisplayName)
+		require.Equal(t, expected.description, group.Description)
+		require.Equal(t, groups[i].Name, group.Name)
+		if expected.bumped {
+			require.True(t, group.UpdateAt > groups[i].UpdateAt)
+			require.Equal(t, group.UpdateAt, updates[i].UpdateAt)
+		} else {
+			require.Equal(t, groups[i].UpdateAt, group.UpdateAt)
+			require.Zero(t, updates[i].UpdateAt)
+		}
+	}
+
+	res = <-ss.Group().GetByRemoteID(groups[3].RemoteId, model.GroupSourceLdap)
+	require.Nil(t, res.Err)
+	require.Equal(t, "dn_3", res.Data.(*model.Group).DisplayName)
+
+	// Invalid metadata is rejected before anything is written
+	res = <-ss.Group().UpdateMetadata([]*model.Group{
+		{Id: groups[0].Id, DisplayName: "newer_dn_0"},
+		{Id: groups[1].Id, DisplayName: ""},
+	})
+	require.NotNil(t, res.Err)
+
+	res = <-ss.Group().Get(groups[0].Id)
+	require.Nil(t, res.Err)
+	require.Equal(t, "new_dn_0", res.Data.(*model.Group).DisplayName)
+}
+
 func testGroupStoreUpdate(t *testing.T, ss store.Store) {
 	// Save a new group
 	g1 := &model.Group{
@@ -324,6 +522,17 @@ func testGroupStoreUpdate(t *testing.T, ss store.Store) {
 	})
 	require.Equal(t, res6.Err.Id, "store.update_error")
 
+	// Nor one only differing in case
+	res6b := <-ss.Group().Update(&model.Group{
+		Id:          d2.Id,
+		Name:        strings.ToUpper(g1Update.Name),
+		DisplayName: model.NewId(),
+		Source:      model.GroupSourceLdap,
+		Description: model.NewId(),
+		RemoteId:    model.NewId(),
+	})
+	require.Equal(t, res6b.Err.Id, "store.update_error")
+
 	// Cannot update CreateAt
 	someVal := model.GetMillis()
 	d1.CreateAt = someVal
@@ -488,19 +697,72 @@ func testGroupGetMemberUsersPage(t *testing.T, ss store.Store) {
 	groupMembers := res.Data.([]*model.User)
 	require.Equal(t, 2, len(groupMembers))
 
+	// Members are ordered by username
+	first, second := user1, user2
+	if user2.Username < user1.Username {
+		first, second = user2, user1
+	}
+
 	// Check page 1
 	res = <-ss.Group().GetMemberUsersPage(group.Id, 0, 1)
 	require.Nil(t, res.Err)
 	groupMembers = res.Data.([]*model.User)
 	require.Equal(t, 1, len(groupMembers))
-	require.Equal(t, user2.Id, groupMembers[0].Id)
+	require.Equal(t, first.Id, groupMembers[0].Id)
 
 	// Check page 2
 	res = <-ss.Group().GetMemberUsersPage(group.Id, 1, 1)
 	require.Nil(t, res.Err)
 	groupMembers = res.Data.([]*model.User)
 	require.Equal(t, 1, len(groupMembers))
-	require.Equal(t, user1.Id, groupMembers[0].Id)
+	require.Equal(t, second.Id, groupMembers[0].Id)
+
+	// Check that concatenating all pages yields every member exactly once, in username order
+	expectedUsers := []*model.User{user1, user2}
+	for i := 0; i < 5; i++ {
+		res = <-ss.User().Save(&model.User{
+			Email:    MakeEmail(),
+			Username: model.NewId(),
+		})
+		require.Nil(t, res.Err)
+		user := res.Data.(*model.User)
+
+		res = <-ss.Group().CreateOrRestoreMember(group.Id, user.Id)
+		require.Nil(t, res.Err)
+
+		expectedUsers = append(expectedUsers, user)
+	}
+	sort.Slice(expectedUsers, func(i, j int) bool { return expectedUsers[i].Username < expectedUsers[j].Username })
+
+	var expectedIds []string
+	for _, user := range expectedUsers {
+		expectedIds = append(expectedIds, user.Id)
+	}
+
+	var pagedIds []string
+	seen := map[string]bool{}
+	for page := 0; ; page++ {
+		res = <-ss.Group().GetMemberUsersPage(group.Id, page, 2)
+		require.Nil(t, res.Err)
+		users := res.Data.([]*model.User)
+		if len(users) == 0 {
+			break
+		}
+		for _, user := range users {
+			require.False(t, seen[user.Id], "user %s returned on more than one page", user.Id)
+			seen[user.Id] = true
+			pagedIds = append(pagedIds, user.Id)
+		}
+	}
+	require.Equal(t, expectedIds, pagedIds)
+
+	// Remove the extra members again
+	for _, userId := range expectedIds {
+		if userId != user1.Id && userId != user2.Id {
+			res = <-ss.Group().DeleteMember(group.Id, userId)
+			require.Nil(t, res.Err)
+		}
+	}
 
 	// Check madeup id
 	res = <-ss.Group().GetMemberUsersPage(model.NewId(), 0, 100)
@@ -544,6 +806,7 @@ func testGroupCreateOrRestoreMember(t *testing.T, ss store.Store) {
 	require.Equal(t, d2.UserId, user.Id)
 	require.NotZero(t, d2.CreateAt)
 	require.Zero(t, d2.DeleteAt)
+	require.Equal(t, model.GroupMemberSourceLdap, d2.Source)
 
 	// Duplicate composite key (GroupId, UserId)
 	res4 := <-ss.Group().CreateOrRestoreMember(group.Id, user.Id)
@@ -620,150 +883,373 @@ func testGroupDeleteMember(t *testing.T, ss store.Store) {
 	require.Equal(t, res9.Err.Id, "store.sql_group.no_rows")
 }
 
-func testCreateGroupSyncable(t *testing.T, ss store.Store) {
-	// Invalid GroupID
-	res2 := <-ss.Group().CreateGroupSyncable(model.NewGroupTeam("x", model.NewId(), false))
-	require.Equal(t, res2.Err.Id, "model.group_syncable.group_id.app_error")
-
-	// Create Group
-	g1 := &model.Group{
+func testGroupUpsertMembers(t *testing.T, ss store.Store) {
+	res := <-ss.Group().Create(&model.Group{
 		Name:        model.NewId(),
 		DisplayName: model.NewId(),
-		Source:      model.GroupSourceLdap,
+		Source:      model.GroupSourceCustom,
 		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, res.Err)
+	group := res.Data.(*model.Group)
+
+	var userIds []string
+	for i := 0; i < 4; i++ {
+		res = <-ss.User().Save(&model.User{Email: MakeEmail(), Username: model.NewId()})
+		require.Nil(t, res.Err)
+		userIds = append(userIds, res.Data.(*model.User).Id)
 	}
-	res4 := <-ss.Group().Create(g1)
-	require.Nil(t, res4.Err)
-	group := res4.Data.(*model.Group)
 
-	// Create Team
-	t1 := &model.Team{
-		DisplayName:     "Name",
-		Description:     "Some description",
-		CompanyName:     "Some company name",
-		AllowOpenInvite: false,
-		InviteId:        "inviteid0",
-		Name:            "z-z-" + model.NewId() + "a",
-		Email:           "success+" + model.NewId() + "@simulator.amazonses.com",
-		Type:            model.TEAM_OPEN,
+	// The first user is an active member and the second a removed one.
+	res = <-ss.Group().CreateOrRestoreMember(group.Id, userIds[0])
+	require.Nil(t, res.Err)
+	res = <-ss.Group().CreateOrRestoreMember(group.Id, userIds[1])
+	require.Nil(t, res.Err)
+	res = <-ss.Group().DeleteMember(group.Id, userIds[1])
+	require.Nil(t, res.Err)
+
+	// Partial overlap, with a duplicate
+	res = <-ss.Group().UpsertMembers(group.Id, []string{userIds[0], userIds[1], userIds[2], userIds[2]})
+	require.Nil(t, res.Err)
+	upsertResult := res.Data.(*model.GroupMembersUpsertResult)
+	require.Equal(t, []string{userIds[1], userIds[2]}, upsertResult.Added)
+	require.Equal(t, []string{userIds[0]}, upsertResult.AlreadyMembers)
+
+	res = <-ss.Group().GetMemberUsers(group.Id)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data.([]*model.User), 3)
+
+	// Repeating the request only adds the new user
+	res = <-ss.Group().UpsertMembers(group.Id, []string{userIds[0], userIds[1], userIds[2], userIds[3]})
+	require.Nil(t, res.Err)
+	upsertResult = res.Data.(*model.GroupMembersUpsertResult)
+	require.Equal(t, []string{userIds[3]}, upsertResult.Added)
+	require.Equal(t, []string{userIds[0], userIds[1], userIds[2]}, upsertResult.AlreadyMembers)
+
+	// The members of a custom group were added by hand, including the restored one.
+	res = <-ss.Group().GetMembers(group.Id, userIds)
+	require.Nil(t, res.Err)
+	for _, member := range res.Data.([]*model.GroupMember) {
+		require.Equal(t, model.GroupMemberSourceManual, member.Source)
 	}
-	team, err := ss.Team().Save(t1)
-	require.Nil(t, err)
 
-	// New GroupSyncable, happy path
-	gt1 := model.NewGroupTeam(group.Id, team.Id, false)
-	res6 := <-ss.Group().CreateGroupSyncable(gt1)
-	require.Nil(t, res6.Err)
-	d1 := res6.Data.(*model.GroupSyncable)
-	require.Equal(t, gt1.SyncableId, d1.SyncableId)
-	require.Equal(t, gt1.GroupId, d1.GroupId)
-	require.Equal(t, gt1.AutoAdd, d1.AutoAdd)
-	require.NotZero(t, d1.CreateAt)
-	require.Zero(t, d1.DeleteAt)
+	// Invalid user id
+	res = <-ss.Group().UpsertMembers(group.Id, []string{"junk"})
+	require.NotNil(t, res.Err)
 }
 
-func testGetGroupSyncable(t *testing.T, ss store.Store) {
-	// Create a group
-	g1 := &model.Group{
+func testGroupDeleteMembers(t *testing.T, ss store.Store) {
+	res := <-ss.Group().Create(&model.Group{
 		Name:        model.NewId(),
 		DisplayName: model.NewId(),
-		Description: model.NewId(),
-		Source:      model.GroupSourceLdap,
+		Source:      model.GroupSourceCustom,
 		RemoteId:    model.NewId(),
-	}
-	res1 := <-ss.Group().Create(g1)
-	require.Nil(t, res1.Err)
-	group := res1.Data.(*model.Group)
+	})
+	require.Nil(t, res.Err)
+	group := res.Data.(*model.Group)
 
-	// Create Team
-	t1 := &model.Team{
-		DisplayName:     "Name",
-		Description:     "Some description",
-		CompanyName:     "Some company name",
-		AllowOpenInvite: false,
-		InviteId:        "inviteid0",
-		Name:            "z-z-" + model.NewId() + "a",
-		Email:           "success+" + model.NewId() + "@simulator.amazonses.com",
-		Type:            model.TEAM_OPEN,
+	var userIds []string
+	for i := 0; i < 3; i++ {
+		res = <-ss.User().Save(&model.User{Email: MakeEmail(), Username: model.NewId()})
+		require.Nil(t, res.Err)
+		userIds = append(userIds, res.Data.(*model.User).Id)
 	}
-	team, err := ss.Team().Save(t1)
-	require.Nil(t, err)
 
-	// Create GroupSyncable
-	gt1 := model.NewGroupTeam(group.Id, team.Id, false)
-	res3 := <-ss.Group().CreateGroupSyncable(gt1)
-	require.Nil(t, res3.Err)
-	groupTeam := res3.Data.(*model.GroupSyncable)
+	res = <-ss.Group().UpsertMembers(group.Id, userIds[:2])
+	require.Nil(t, res.Err)
 
-	// Get GroupSyncable
-	res4 := <-ss.Group().GetGroupSyncable(groupTeam.GroupId, groupTeam.SyncableId, model.GroupSyncableTypeTeam)
-	require.Nil(t, res4.Err)
-	dgt := res4.Data.(*model.GroupSyncable)
-	require.Equal(t, gt1.GroupId, dgt.GroupId)
-	require.Equal(t, gt1.SyncableId, dgt.SyncableId)
-	require.Equal(t, gt1.AutoAdd, dgt.AutoAdd)
-	require.NotZero(t, gt1.CreateAt)
-	require.NotZero(t, gt1.UpdateAt)
-	require.Zero(t, gt1.DeleteAt)
+	// Only active members are removed
+	res = <-ss.Group().DeleteMembers(group.Id, []string{userIds[0], userIds[2]})
+	require.Nil(t, res.Err)
+	members := res.Data.([]*model.GroupMember)
+	require.Len(t, members, 1)
+	require.Equal(t, userIds[0], members[0].UserId)
+	require.NotZero(t, members[0].DeleteAt)
+
+	res = <-ss.Group().GetMemberUsers(group.Id)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data.([]*model.User), 1)
+
+	res = <-ss.Group().DeleteMembers(group.Id, []string{userIds[0]})
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data.([]*model.GroupMember), 0)
 }
 
-func testGetAllGroupSyncablesByGroup(t *testing.T, ss store.Store) {
-	numGroupSyncables := 10
+func testGroupDeleteAllMembers(t *testing.T, ss store.Store) {
+	createGroup := func() *model.Group {
+		res := <-ss.Group().Create(&model.Group{
+			Name:        model.NewId(),
+			DisplayName: model.NewId(),
+			Source:      model.GroupSourceCustom,
+		})
+		require.Nil(t, res.Err)
+		return res.Data.(*model.Group)
+	}
+	group := createGroup()
+	otherGroup := createGroup()
 
-	// Create group
-	g := &model.Group{
+	var userIds []string
+	for i := 0; i < 3; i++ {
+		res := <-ss.User().Save(&model.User{Email: MakeEmail(), Username: model.NewId()})
+		require.Nil(t, res.Err)
+		userIds = append(userIds, res.Data.(*model.User).Id)
+	}
+
+	res := <-ss.Group().UpsertMembers(group.Id, userIds)
+	require.Nil(t, res.Err)
+	res = <-ss.Group().UpsertMembers(otherGroup.Id, userIds[:1])
+	require.Nil(t, res.Err)
+	res = <-ss.Group().DeleteMember(group.Id, userIds[2])
+	require.Nil(t, res.Err)
+
+	// Members removed earlier are not reported again
+	res = <-ss.Group().DeleteAllMembers(group.Id)
+	require.Nil(t, res.Err)
+	require.ElementsMatch(t, userIds[:2], res.Data.([]string))
+
+	res = <-ss.Group().GetMemberCount(group.Id)
+	require.Nil(t, res.Err)
+	require.Zero(t, res.Data.(int64))
+
+	res = <-ss.Group().GetMemberCount(otherGroup.Id)
+	require.Nil(t, res.Err)
+	require.Equal(t, int64(1), res.Data.(int64))
+
+	res = <-ss.Group().DeleteAllMembers(group.Id)
+	require.Nil(t, res.Err)
+	require.Empty(t, res.Data.([]string))
+}
+
+func testGroupUpdateMember(t *testing.T, ss store.Store) {
+	res := <-ss.Group().Create(&model.Group{
 		Name:        model.NewId(),
 		DisplayName: model.NewId(),
-		Description: model.NewId(),
 		Source:      model.GroupSourceLdap,
 		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, res.Err)
+	group := res.Data.(*model.Group)
+
+	var userIds []string
+	for i := 0; i < 2; i++ {
+		res = <-ss.User().Save(&model.User{Email: MakeEmail(), Username: model.NewId()})
+		require.Nil(t, res.Err)
+		userIds = append(userIds, res.Data.(*model.User).Id)
 	}
-	res1 := <-ss.Group().Create(g)
-	require.Nil(t, res1.Err)
-	group := res1.Data.(*model.Group)
 
-	groupTeams := []*model.GroupSyncable{}
+	res = <-ss.Group().UpsertMembers(group.Id, userIds[:1])
+	require.Nil(t, res.Err)
 
-	// Create groupTeams
-	for i := 0; i < numGroupSyncables; i++ {
-		// Create Team
-		t1 := &model.Team{
-			DisplayName:     "Name",
-			Description:     "Some description",
-			CompanyName:     "Some company name",
-			AllowOpenInvite: false,
-			InviteId:        "inviteid0",
-			Name:            "z-z-" + model.NewId() + "a",
-			Email:           "success+" + model.NewId() + "@simulator.amazonses.com",
-			Type:            model.TEAM_OPEN,
-		}
-		team, err := ss.Team().Save(t1)
-		require.Nil(t, err)
+	res = <-ss.Group().GetMembers(group.Id, userIds)
+	require.Nil(t, res.Err)
+	members := res.Data.([]*model.GroupMember)
+	require.Len(t, members, 1)
+	require.Equal(t, "", members[0].Roles)
 
-		// create groupteam
-		res3 := <-ss.Group().CreateGroupSyncable(model.NewGroupTeam(group.Id, team.Id, false))
-		require.Nil(t, res3.Err)
-		groupTeam := res3.Data.(*model.GroupSyncable)
-		groupTeams = append(groupTeams, groupTeam)
+	members[0].Roles = "owner"
+	res = <-ss.Group().UpdateMember(members[0])
+	require.Nil(t, res.Err)
+
+	// Updating with unchanged roles still succeeds
+	res = <-ss.Group().UpdateMember(members[0])
+	require.Nil(t, res.Err)
+
+	// Roles survive a repeated upsert
+	res = <-ss.Group().UpsertMembers(group.Id, userIds[:1])
+	require.Nil(t, res.Err)
+
+	res = <-ss.Group().GetMembers(group.Id, userIds[:1])
+	require.Nil(t, res.Err)
+	require.Equal(t, "owner", res.Data.([]*model.GroupMember)[0].Roles)
+
+	// Roles survive the member being removed and restored by a sync
+	res = <-ss.Group().DeleteMember(group.Id, userIds[0])
+	require.Nil(t, res.Err)
+
+	res = <-ss.Group().GetMembers(group.Id, userIds[:1])
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data.([]*model.GroupMember), 0)
+
+	res = <-ss.Group().CreateOrRestoreMember(group.Id, userIds[0])
+	require.Nil(t, res.Err)
+	require.Equal(t, "owner", res.Data.(*model.GroupMember).Roles)
+
+	res = <-ss.Group().GetMembers(group.Id, userIds[:1])
+	require.Nil(t, res.Err)
+	require.Equal(t, "owner", res.Data.([]*model.GroupMember)[0].Roles)
+
+	// Not a member
+	res = <-ss.Group().UpdateMember(&model.GroupMember{GroupId: group.Id, UserId: userIds[1], Roles: "owner"})
+	require.NotNil(t, res.Err)
+	require.Equal(t, "store.sql_group.no_rows", res.Err.Id)
+
+	// Roles too long
+	res = <-ss.Group().UpdateMember(&model.GroupMember{GroupId: group.Id, UserId: userIds[0], Roles: strings.Repeat("a", model.GroupMemberRolesMaxLength+1)})
+	require.NotNil(t, res.Err)
+	require.Equal(t, "model.group_member.roles.app_error", res.Err.Id)
+}
+
+func testGroupExpiringMembers(t *testing.T, ss store.Store) {
+	res := <-ss.Group().Create(&model.Group{
+		Name:        model.NewId(),
+		DisplayName: model.NewId(),
+		Source:      model.GroupSourceCustom,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, res.Err)
+	group := res.Data.(*model.Group)
+
+	var userIds []string
+	for i := 0; i < 3; i++ {
+		res = <-ss.User().Save(&model.User{Email: MakeEmail(), Username: model.NewId()})
+		require.Nil(t, res.Err)
+		userIds = append(userIds, res.Data.(*model.User).Id)
 	}
 
-	// Returns all the group teams
-	res4 := <-ss.Group().GetAllGroupSyncablesByGroupId(group.Id, model.GroupSyncableTypeTeam)
-	d1 := res4.Data.([]*model.GroupSyncable)
-	require.Condition(t, func() bool { return len(d1) >= numGroupSyncables })
-	for _, expectedGroupTeam := range groupTeams {
-		present := false
-		for _, dbGroupTeam := range d1 {
-			if dbGroupTeam.GroupId == expectedGroupTeam.GroupId && dbGroupTeam.SyncableId == expectedGroupTeam.SyncableId {
-				present = true
-				break
+	res = <-ss.Group().UpsertMembers(group.Id, userIds)
+	require.Nil(t, res.Err)
+
+	// The first member expires soonest, the second later and the third never
+	now := model.GetMillis()
+	for i, expiresAt := range []int64{now + 1000, now + 2000} {
+		res = <-ss.Group().UpdateMember(&model.GroupMember{GroupId: group.Id, UserId: userIds[i], ExpiresAt: expiresAt})
+		require.Nil(t, res.Err)
+	}
+
+	expiredInGroup := func(at int64) []string {
+		res := <-ss.Group().GetExpiredMembers(at, 1000)
+		require.Nil(t, res.Err)
+		var expired []string
+		for _, member := range res.Data.([]*model.GroupMember) {
+			if member.GroupId == group.Id {
+				expired = append(expired, member.UserId)
 			}
 		}
-		require.True(t, present)
+		return expired
 	}
+
+	require.Empty(t, expiredInGroup(now))
+	require.Equal(t, userIds[:1], expiredInGroup(now+1000))
+	require.Equal(t, userIds[:2], expiredInGroup(now+5000))
+
+	res = <-ss.Group().GetMemberUsersExpiringPage(group.Id, now+5000, 0, 1)
+	require.Nil(t, res.Err)
+	users := res.Data.([]*model.User)
+	require.Len(t, users, 1)
+	require.Equal(t, userIds[0], users[0].Id)
+
+	res = <-ss.Group().GetMemberUsersExpiringPage(group.Id, now+5000, 1, 1)
+	require.Nil(t, res.Err)
+	users = res.Data.([]*model.User)
+	require.Len(t, users, 1)
+	require.Equal(t, userIds[1], users[0].Id)
+
+	res = <-ss.Group().GetExpiringMemberCount(group.Id, now+1500)
+	require.Nil(t, res.Err)
+	require.Equal(t, int64(1), res.Data.(int64))
+
+	// Deleted memberships no longer expire, and a restored membership no longer has an expiry
+	res = <-ss.Group().DeleteMember(group.Id, userIds[0])
+	require.Nil(t, res.Err)
+	require.Equal(t, userIds[1:2], expiredInGroup(now+5000))
+
+	res = <-ss.Group().UpsertMembers(group.Id, userIds[:1])
+	require.Nil(t, res.Err)
+	res = <-ss.Group().GetMembers(group.Id, userIds[:1])
+	require.Nil(t, res.Err)
+	require.Zero(t, res.Data.([]*model.GroupMember)[0].ExpiresAt)
 }
 
-func testUpdateGroupSyncable(t *testing.T, ss store.Store) {
+func testGroupMemberHistory(t *testing.T, ss store.Store) {
+	groupID := model.NewId()
+	userID1 := model.NewId()
+	userID2 := model.NewId()
+
+	res := <-ss.Group().LogMemberEvents(groupID, []string{userID1, userID2}, model.GroupMembershipWebhookActionAdd)
+	require.Nil(t, res.Err)
+
+	time.Sleep(10 * time.Millisecond)
+	between := model.GetMillis()
+	time.Sleep(10 * time.Millisecond)
+
+	res = <-ss.Group().LogMemberEvents(groupID, []string{userID1}, model.GroupMembershipWebhookActionRemove)
+	require.Nil(t, res.Err)
+
+	// Nothing is recorded for an empty batch
+	res = <-ss.Group().LogMemberEvents(groupID, []string{}, model.GroupMembershipWebhookActionRemove)
+	require.Nil(t, res.Err)
+
+	res = <-ss.Group().GetMemberHistory(groupID, 0, 0, 0, 100)
+	require.Nil(t, res.Err)
+	events := res.Data.([]*model.GroupMemberHistoryEvent)
+	require.Len(t, events, 3)
+	require.ElementsMatch(t, []string{userID1, userID2}, []string{events[0].UserId, events[1].UserId})
+	require.Equal(t, model.GroupMembershipWebhookActionAdd, events[0].Action)
+	require.Equal(t, model.GroupMembershipWebhookActionAdd, events[1].Action)
+	require.Equal(t, userID1, events[2].UserId)
+	require.Equal(t, model.GroupMembershipWebhookActionRemove, events[2].Action)
+	for _, event := range events {
+		require.Len(t, event.Id, 26)
+		require.Equal(t, groupID, event.GroupId)
+		require.NotZero(t, event.CreateAt)
+	}
+
+	// Paging
+	res = <-ss.Group().GetMemberHistory(groupID, 0, 0, 1, 2)
+	require.Nil(t, res.Err)
+	require.Equal(t, events[2:], res.Data.([]*model.GroupMemberHistoryEvent))
+
+	// Time bounds
+	res = <-ss.Group().GetMemberHistory(groupID, between, 0, 0, 100)
+	require.Nil(t, res.Err)
+	require.Equal(t, events[2:], res.Data.([]*model.GroupMemberHistoryEvent))
+
+	res = <-ss.Group().GetMemberHistory(groupID, 0, between, 0, 100)
+	require.Nil(t, res.Err)
+	require.Equal(t, events[:2], res.Data.([]*model.GroupMemberHistoryEvent))
+
+	// Another group
+	res = <-ss.Group().GetMemberHistory(model.NewId(), 0, 0, 0, 100)
+	require.Nil(t, res.Err)
+	require.Empty(t, res.Data.([]*model.GroupMemberHistoryEvent))
+}
+
+func testGroupMembersBatches(t *testing.T, ss store.Store) {
+	res := <-ss.Group().Create(&model.Group{
+		Name:        model.NewId(),
+		DisplayName: model.NewId(),
+		Source:      model.GroupSourceCustom,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, res.Err)
+	group := res.Data.(*model.Group)
+
+	// Enough users to span several of the store's internal batches
+	userIds := make([]string, 1201)
+	for i := range userIds {
+		userIds[i] = model.NewId()
+	}
+
+	res = <-ss.Group().UpsertMembers(group.Id, userIds[:700])
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data.(*model.GroupMembersUpsertResult).Added, 700)
+
+	res = <-ss.Group().UpsertMembers(group.Id, userIds)
+	require.Nil(t, res.Err)
+	upsertResult := res.Data.(*model.GroupMembersUpsertResult)
+	require.Equal(t, userIds[700:], upsertResult.Added)
+	require.Equal(t, userIds[:700], upsertResult.AlreadyMembers)
+
+	res = <-ss.Group().DeleteMembers(group.Id, userIds)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data.([]*model.GroupMember), len(userIds))
+}
+
+func testCreateGroupSyncable(t *testing.T, ss store.Store) {
+	// Invalid GroupID
+	res2 := <-ss.Group().CreateGroupSyncable(model.NewGroupTeam("x", model.NewId(), false))
+	require.Equal(t, res2.Err.Id, "model.group_syncable.group_id.app_error")
+
 	// Create Group
 	g1 := &model.Group{
 		Name:        model.NewId(),
@@ -794,51 +1280,24 @@ func testUpdateGroupSyncable(t *testing.T, ss store.Store) {
 	res6 := <-ss.Group().CreateGroupSyncable(gt1)
 	require.Nil(t, res6.Err)
 	d1 := res6.Data.(*model.GroupSyncable)
+	require.Equal(t, gt1.SyncableId, d1.SyncableId)
+	require.Equal(t, gt1.GroupId, d1.GroupId)
+	require.Equal(t, gt1.AutoAdd, d1.AutoAdd)
+	require.NotZero(t, d1.CreateAt)
+	require.Zero(t, d1.DeleteAt)
 
-	// Update existing group team
-	gt1.AutoAdd = true
-	res7 := <-ss.Group().UpdateGroupSyncable(gt1)
-	require.Nil(t, res7.Err)
-	d2 := res7.Data.(*model.GroupSyncable)
-	require.True(t, d2.AutoAdd)
-
-	// Non-existent Group
-	gt2 := model.NewGroupTeam(model.NewId(), team.Id, false)
-	res9 := <-ss.Group().UpdateGroupSyncable(gt2)
-	require.Equal(t, res9.Err.Id, "store.sql_group.no_rows")
-
-	// Non-existent Team
-	gt3 := model.NewGroupTeam(group.Id, model.NewId(), false)
-	res10 := <-ss.Group().UpdateGroupSyncable(gt3)
-	require.Equal(t, res10.Err.Id, "store.sql_group.no_rows")
-
-	// Cannot update CreateAt or DeleteAt
-	origCreateAt := d1.CreateAt
-	d1.CreateAt = model.GetMillis()
-	d1.AutoAdd = true
-	res11 := <-ss.Group().UpdateGroupSyncable(d1)
-	require.Nil(t, res11.Err)
-	d3 := res11.Data.(*model.GroupSyncable)
-	require.Equal(t, origCreateAt, d3.CreateAt)
-
-	// Cannot update DeleteAt to arbitrary value
-	d1.DeleteAt = 1
-	res12 := <-ss.Group().UpdateGroupSyncable(d1)
-	require.Equal(t, "model.group.delete_at.app_error", res12.Err.Id)
-
-	// Can update DeleteAt to 0
-	d1.DeleteAt = 0
-	res13 := <-ss.Group().UpdateGroupSyncable(d1)
-	require.Nil(t, res13.Err)
-	d4 := res13.Data.(*model.GroupSyncable)
-	require.Zero(t, d4.DeleteAt)
+	// Duplicate GroupSyncable
+	res7 := <-ss.Group().CreateGroupSyncable(model.NewGroupTeam(group.Id, team.Id, false))
+	require.NotNil(t, res7.Err)
+	require.Equal(t, "store.sql_group.syncable_uniqueness_error", res7.Err.Id)
 }
 
-func testDeleteGroupSyncable(t *testing.T, ss store.Store) {
-	// Create Group
+func testGetGroupSyncable(t *testing.T, ss store.Store) {
+	// Create a group
 	g1 := &model.Group{
 		Name:        model.NewId(),
 		DisplayName: model.NewId(),
+		Description: model.NewId(),
 		Source:      model.GroupSourceLdap,
 		RemoteId:    model.NewId(),
 	}
@@ -862,899 +1321,2422 @@ func testDeleteGroupSyncable(t *testing.T, ss store.Store) {
 
 	// Create GroupSyncable
 	gt1 := model.NewGroupTeam(group.Id, team.Id, false)
-	res7 := <-ss.Group().CreateGroupSyncable(gt1)
-	require.Nil(t, res7.Err)
-	groupTeam := res7.Data.(*model.GroupSyncable)
-
-	// Non-existent Group
-	res5 := <-ss.Group().DeleteGroupSyncable(model.NewId(), groupTeam.SyncableId, model.GroupSyncableTypeTeam)
-	require.Equal(t, res5.Err.Id, "store.sql_group.no_rows")
-
-	// Non-existent Team
-	res6 := <-ss.Group().DeleteGroupSyncable(groupTeam.GroupId, string(model.NewId()), model.GroupSyncableTypeTeam)
-	require.Equal(t, res6.Err.Id, "store.sql_group.no_rows")
-
-	// Happy path...
-	res8 := <-ss.Group().DeleteGroupSyncable(groupTeam.GroupId, groupTeam.SyncableId, model.GroupSyncableTypeTeam)
-	require.Nil(t, res8.Err)
-	d1 := res8.Data.(*model.GroupSyncable)
-	require.NotZero(t, d1.DeleteAt)
-	require.Equal(t, d1.GroupId, groupTeam.GroupId)
-	require.Equal(t, d1.SyncableId, groupTeam.SyncableId)
-	require.Equal(t, d1.AutoAdd, groupTeam.AutoAdd)
-	require.Equal(t, d1.CreateAt, groupTeam.CreateAt)
-	require.Condition(t, func() bool { return d1.UpdateAt > groupTeam.UpdateAt })
+	res3 := <-ss.Group().CreateGroupSyncable(gt1)
+	require.Nil(t, res3.Err)
+	groupTeam := res3.Data.(*model.GroupSyncable)
 
-	// Record already deleted
-	res9 := <-ss.Group().DeleteGroupSyncable(d1.GroupId, d1.SyncableId, d1.Type)
-	require.NotNil(t, res9.Err)
-	require.Equal(t, res9.Err.Id, "store.sql_group.group_syncable_already_deleted")
+	// Get GroupSyncable
+	res4 := <-ss.Group().GetGroupSyncable(groupTeam.GroupId, groupTeam.SyncableId, model.GroupSyncableTypeTeam)
+	require.Nil(t, res4.Err)
+	dgt := res4.Data.(*model.GroupSyncable)
+	require.Equal(t, gt1.GroupId, dgt.GroupId)
+	require.Equal(t, gt1.SyncableId, dgt.SyncableId)
+	require.Equal(t, gt1.AutoAdd, dgt.AutoAdd)
+	require.NotZero(t, gt1.CreateAt)
+	require.NotZero(t, gt1.UpdateAt)
+	require.Zero(t, gt1.DeleteAt)
 }
 
-func testPendingAutoAddTeamMembers(t *testing.T, ss store.Store) {
-	// Create Group
-	res := <-ss.Group().Create(&model.Group{
+func testGroupSyncableExists(t *testing.T, ss store.Store) {
+	res1 := <-ss.Group().Create(&model.Group{
 		Name:        model.NewId(),
-		DisplayName: "TeamMembersToAdd Test Group",
-		RemoteId:    model.NewId(),
+		DisplayName: model.NewId(),
 		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
 	})
-	require.Nil(t, res.Err)
-	group := res.Data.(*model.Group)
+	require.Nil(t, res1.Err)
+	group := res1.Data.(*model.Group)
 
-	// Create User
-	user := &model.User{
-		Email:    MakeEmail(),
-		Username: model.NewId(),
-	}
-	res = <-ss.User().Save(user)
-	require.Nil(t, res.Err)
-	user = res.Data.(*model.User)
+	team, err := ss.Team().Save(&model.Team{
+		DisplayName: "Name",
+		Name:        "z-z-" + model.NewId() + "a",
+		Email:       "success+" + model.NewId() + "@simulator.amazonses.com",
+		Type:        model.TEAM_OPEN,
+	})
+	require.Nil(t, err)
 
-	// Create GroupMember
-	res = <-ss.Group().CreateOrRestoreMember(group.Id, user.Id)
+	res := <-ss.Channel().Save(&model.Channel{
+		TeamId:      team.Id,
+		DisplayName: "Name",
+		Name:        "z-z-" + model.NewId() + "a",
+		Type:        model.CHANNEL_OPEN,
+	}, 9999)
 	require.Nil(t, res.Err)
+	channel := res.Data.(*model.Channel)
 
-	// Create Team
-	team := &model.Team{
-		DisplayName:     "Name",
-		Description:     "Some description",
-		CompanyName:     "Some company name",
-		AllowOpenInvite: false,
-		InviteId:        "inviteid0",
-		Name:            "z-z-" + model.NewId() + "a",
-		Email:           "success+" + model.NewId() + "@simulator.amazonses.com",
-		Type:            model.TEAM_OPEN,
-	}
-	team, err := ss.Team().Save(team)
-	require.Nil(t, err)
+	res2 := <-ss.Group().CreateGroupSyncable(model.NewGroupTeam(group.Id, team.Id, false))
+	require.Nil(t, res2.Err)
+	res3 := <-ss.Group().CreateGroupSyncable(model.NewGroupChannel(group.Id, channel.Id, false))
+	require.Nil(t, res3.Err)
 
-	// Create GroupTeam
-	res = <-ss.Group().CreateGroupSyncable(model.NewGroupTeam(group.Id, team.Id, true))
-	require.Nil(t, res.Err)
-	syncable := res.Data.(*model.GroupSyncable)
+	// Present
+	res4 := <-ss.Group().GroupSyncableExists(group.Id, team.Id, model.GroupSyncableTypeTeam)
+	require.Nil(t, res4.Err)
+	require.True(t, res4.Data.(bool))
 
-	// Time before syncable was created
-	res = <-ss.Group().TeamMembersToAdd(syncable.CreateAt - 1)
-	require.Nil(t, res.Err)
-	teamMembers := res.Data.([]*model.UserTeamIDPair)
-	require.Len(t, teamMembers, 1)
-	require.Equal(t, user.Id, teamMembers[0].UserID)
-	require.Equal(t, team.Id, teamMembers[0].TeamID)
+	res5 := <-ss.Group().GroupSyncableExists(group.Id, channel.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, res5.Err)
+	require.True(t, res5.Data.(bool))
 
-	// Time after syncable was created
-	res = <-ss.Group().TeamMembersToAdd(syncable.CreateAt + 1)
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 0)
+	// The syncable type decides which table is checked
+	res6 := <-ss.Group().GroupSyncableExists(group.Id, channel.Id, model.GroupSyncableTypeTeam)
+	require.Nil(t, res6.Err)
+	require.False(t, res6.Data.(bool))
 
-	// Delete and restore GroupMember should return result
-	res = <-ss.Group().DeleteMember(group.Id, user.Id)
-	require.Nil(t, res.Err)
-	res = <-ss.Group().CreateOrRestoreMember(group.Id, user.Id)
-	require.Nil(t, res.Err)
-	res = <-ss.Group().TeamMembersToAdd(syncable.CreateAt + 1)
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 1)
+	// Absent
+	res7 := <-ss.Group().GroupSyncableExists(model.NewId(), team.Id, model.GroupSyncableTypeTeam)
+	require.Nil(t, res7.Err)
+	require.False(t, res7.Data.(bool))
 
-	pristineSyncable := *syncable
+	res8 := <-ss.Group().GroupSyncableExists(group.Id, model.NewId(), model.GroupSyncableTypeChannel)
+	require.Nil(t, res8.Err)
+	require.False(t, res8.Data.(bool))
 
+	// Deleted
+	res9 := <-ss.Group().DeleteGroupSyncable(group.Id, team.Id, model.GroupSyncableTypeTeam)
+	require.Nil(t, res9.Err)
+
+	res10 := <-ss.Group().GroupSyncableExists(group.Id, team.Id, model.GroupSyncableTypeTeam)
+	require.Nil(t, res10.Err)
+	require.False(t, res10.Data.(bool))
+}
+
+func testGetLinkedSyncableIds(t *testing.T, ss store.Store) {
+	var groupIds []string
+	for i := 0; i < 3; i++ {
+		res := <-ss.Group().Create(&model.Group{
+			Name:        model.NewId(),
+			DisplayName: model.NewId(),
+			Source:      model.GroupSourceLdap,
+			RemoteId:    model.NewId(),
+		})
+		require.Nil(t, res.Err)
+		groupIds = append(groupIds, res.Data.(*model.Group).Id)
+	}
+	channelIds := []string{model.NewId(), model.NewId(), model.NewId()}
+	teamId := model.NewId()
+
+	for _, groupSyncable := range []*model.GroupSyncable{
+		model.NewGroupChannel(groupIds[0], channelIds[0], false),
+		model.NewGroupChannel(groupIds[0], channelIds[1], false),
+		model.NewGroupChannel(groupIds[1], channelIds[1], false),
+		model.NewGroupChannel(groupIds[1], channelIds[2], false),
+		model.NewGroupTeam(groupIds[2], teamId, false),
+	} {
+		res := <-ss.Group().CreateGroupSyncable(groupSyncable)
+		require.Nil(t, res.Err)
+	}
+	res := <-ss.Group().DeleteGroupSyncable(groupIds[1], channelIds[1], model.GroupSyncableTypeChannel)
+	require.Nil(t, res.Err)
+
+	// Links to channels outside the report and deleted links are left out
+	res = <-ss.Group().GetLinkedSyncableIds(groupIds, channelIds[:2], model.GroupSyncableTypeChannel)
+	require.Nil(t, res.Err)
+	linked := res.Data.(map[string][]string)
+	require.Len(t, linked, 1)
+	require.ElementsMatch(t, channelIds[:2], linked[groupIds[0]])
+
+	res = <-ss.Group().GetLinkedSyncableIds(groupIds, []string{teamId}, model.GroupSyncableTypeTeam)
+	require.Nil(t, res.Err)
+	require.Equal(t, map[string][]string{groupIds[2]: {teamId}}, res.Data.(map[string][]string))
+
+	res = <-ss.Group().GetLinkedSyncableIds(groupIds, []string{}, model.GroupSyncableTypeChannel)
+	require.Nil(t, res.Err)
+	require.Empty(t, res.Data.(map[string][]string))
+}
+
+func testGetGroupsMissingTeamLink(t *testing.T, ss store.Store) {
+	res := <-ss.Channel().Save(&model.Channel{
+		TeamId:      model.NewId(),
+		DisplayName: "A Name",
+		Name:        model.NewId(),
+		Type:        model.CHANNEL_OPEN,
+	}, 9999)
+	require.Nil(t, res.Err)
+	channel := res.Data.(*model.Channel)
+
+	var groupIds []string
+	for i := 0; i < 3; i++ {
+		res = <-ss.Group().Create(&model.Group{
+			Name:        model.NewId(),
+			DisplayName: fmt.Sprintf("dn_%d", i),
+			Source:      model.GroupSourceLdap,
+			RemoteId:    model.NewId(),
+		})
+		require.Nil(t, res.Err)
+		groupIds = append(groupIds, res.Data.(*model.Group).Id)
+
+		res = <-ss.Group().CreateGroupSyncable(model.NewGroupChannel(groupIds[i], channel.Id, false))
+		require.Nil(t, res.Err)
+	}
+
+	// Only the first group is linked to the channel's team, and the third to another team
+	res = <-ss.Group().CreateGroupSyncable(model.NewGroupTeam(groupIds[0], channel.TeamId, false))
+	require.Nil(t, res.Err)
+	res = <-ss.Group().CreateGroupSyncable(model.NewGroupTeam(groupIds[2], model.NewId(), false))
+	require.Nil(t, res.Err)
+
+	res = <-ss.Group().GetGroupsMissingTeamLink(channel.Id)
+	require.Nil(t, res.Err)
+	var missing []string
+	for _, group := range res.Data.([]*model.Group) {
+		missing = append(missing, group.Id)
+	}
+	require.Equal(t, groupIds[1:], missing)
+}
+
+func testGetExpiredGroupSyncables(t *testing.T, ss store.Store) {
+	res := <-ss.Group().Create(&model.Group{
+		Name:        model.NewId(),
+		DisplayName: model.NewId(),
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, res.Err)
+	group := res.Data.(*model.Group)
+
+	// The first channel link expires soonest, the second later and the third never
+	now := model.GetMillis()
+	channelIds := []string{model.NewId(), model.NewId(), model.NewId()}
+	for i, expiresAt := range []int64{now + 1000, now + 2000, 0} {
+		groupSyncable := model.NewGroupChannel(group.Id, channelIds[i], false)
+		groupSyncable.ExpiresAt = expiresAt
+		res = <-ss.Group().CreateGroupSyncable(groupSyncable)
+		require.Nil(t, res.Err)
+	}
+	teamSyncable := model.NewGroupTeam(group.Id, model.NewId(), false)
+	teamSyncable.ExpiresAt = now + 1000
+	res = <-ss.Group().CreateGroupSyncable(teamSyncable)
+	require.Nil(t, res.Err)
+
+	res = <-ss.Group().GetGroupSyncable(group.Id, channelIds[0], model.GroupSyncableTypeChannel)
+	require.Nil(t, res.Err)
+	require.Equal(t, now+1000, res.Data.(*model.GroupSyncable).ExpiresAt)
+
+	expiredInGroup := func(syncableType model.GroupSyncableType, at int64) []string {
+		res := <-ss.Group().GetExpiredGroupSyncables(syncableType, at, 1000)
+		require.Nil(t, res.Err)
+		var expired []string
+		for _, groupSyncable := range res.Data.([]*model.GroupSyncable) {
+			if groupSyncable.GroupId == group.Id {
+				require.Equal(t, syncableType, groupSyncable.Type)
+				expired = append(expired, groupSyncable.SyncableId)
+			}
+		}
+		return expired
+	}
+
+	require.Empty(t, expiredInGroup(model.GroupSyncableTypeChannel, now))
+	require.Equal(t, channelIds[:1], expiredInGroup(model.GroupSyncableTypeChannel, now+1000))
+	require.Equal(t, channelIds[:2], expiredInGroup(model.GroupSyncableTypeChannel, now+5000))
+	require.Equal(t, []string{teamSyncable.SyncableId}, expiredInGroup(model.GroupSyncableTypeTeam, now+5000))
+
+	// Deleted links are not reported again
+	res = <-ss.Group().DeleteGroupSyncable(group.Id, channelIds[0], model.GroupSyncableTypeChannel)
+	require.Nil(t, res.Err)
+	require.Equal(t, channelIds[1:2], expiredInGroup(model.GroupSyncableTypeChannel, now+5000))
+}
+
+func testGetGroupsLocaleSort(t *testing.T, ss store.Store) {
+	token := model.NewId()
+	for _, displayName := range []string{"Zoë", "Émile", "Able", "Ärger", "Eve"} {
+		res := <-ss.Group().Create(&model.Group{
+			Name:        model.NewId(),
+			DisplayName: displayName + " " + token,
+			Source:      model.GroupSourceCustom,
+			RemoteId:    model.NewId(),
+		})
+		require.Nil(t, res.Err)
+	}
+
+	sortedDisplayNames := func(locale string) []string {
+		res := <-ss.Group().GetGroups(0, 100, model.GroupSearchOpts{Q: token, Locale: locale})
+		require.Nil(t, res.Err)
+		var displayNames []string
+		for _, group := range res.Data.([]*model.Group) {
+			displayNames = append(displayNames, strings.TrimSuffix(group.DisplayName, " "+token))
+		}
+		return displayNames
+	}
+
+	// An unknown locale falls back to the default ordering
+	require.Len(t, sortedDisplayNames("xx"), 5)
+
+	displayNames := sortedDisplayNames("de")
+	if strings.Join(displayNames, ",") == "Able,Eve,Zoë,Ärger,Émile" {
+		t.Skip("the database has no collation for the locale")
+	}
+	require.Equal(t, []string{"Able", "Ärger", "Émile", "Eve", "Zoë"}, displayNames)
+}
+
+func testGetAllGroupSyncablesByGroup(t *testing.T, ss store.Store) {
+	numGroupSyncables := 10
+
+	// Create group
+	g := &model.Group{
+		Name:        model.NewId(),
+		DisplayName: model.NewId(),
+		Description: model.NewId(),
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	}
+	res1 := <-ss.Group().Create(g)
+	require.Nil(t, res1.Err)
+	group := res1.Data.(*model.Group)
+
+	groupTeams := []*model.GroupSyncable{}
+
+	// Create groupTeams
+	for i := 0; i < numGroupSyncables; i++ {
+		// Create Team
+		t1 := &model.Team{
+			DisplayName:     "Name",
+			Description:     "Some description",
+			CompanyName:     "Some company name",
+			AllowOpenInvite: false,
+			InviteId:        "inviteid0",
+			Name:            "z-z-" + model.NewId() + "a",
+			Email:           "success+" + model.NewId() + "@simulator.amazonses.com",
+			Type:            model.TEAM_OPEN,
+		}
+		team, err := ss.Team().Save(t1)
+		require.Nil(t, err)
+
+		// create groupteam
+		res3 := <-ss.Group().CreateGroupSyncable(model.NewGroupTeam(group.Id, team.Id, false))
+		require.Nil(t, res3.Err)
+		groupTeam := res3.Data.(*model.GroupSyncable)
+		groupTeams = append(groupTeams, groupTeam)
+	}
+
+	// Returns all the group teams
+	res4 := <-ss.Group().GetAllGroupSyncablesByGroupId(group.Id, model.GroupSyncableTypeTeam)
+	d1 := res4.Data.([]*model.GroupSyncable)
+	require.Condition(t, func() bool { return len(d1) >= numGroupSyncables })
+	for _, expectedGroupTeam := range groupTeams {
+		present := false
+		for _, dbGroupTeam := range d1 {
+			if dbGroupTeam.GroupId == expectedGroupTeam.GroupId && dbGroupTeam.SyncableId == expectedGroupTeam.SyncableId {
+				present = true
+				break
+			}
+		}
+		require.True(t, present)
+	}
+}
+
+func testUpdateGroupSyncable(t *testing.T, ss store.Store) {
+	// Create Group
+	g1 := &model.Group{
+		Name:        model.NewId(),
+		DisplayName: model.NewId(),
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	}
+	res4 := <-ss.Group().Create(g1)
+	require.Nil(t, res4.Err)
+	group := res4.Data.(*model.Group)
+
+	// Create Team
+	t1 := &model.Team{
+		DisplayName:     "Name",
+		Description:     "Some description",
+		CompanyName:     "Some company name",
+		AllowOpenInvite: false,
+		InviteId:        "inviteid0",
+		Name:            "z-z-" + model.NewId() + "a",
+		Email:           "success+" + model.NewId() + "@simulator.amazonses.com",
+		Type:            model.TEAM_OPEN,
+	}
+	team, err := ss.Team().Save(t1)
+	require.Nil(t, err)
+
+	// New GroupSyncable, happy path
+	gt1 := model.NewGroupTeam(group.Id, team.Id, false)
+	res6 := <-ss.Group().CreateGroupSyncable(gt1)
+	require.Nil(t, res6.Err)
+	d1 := res6.Data.(*model.GroupSyncable)
+
+	// Update existing group team
+	gt1.AutoAdd = true
+	res7 := <-ss.Group().UpdateGroupSyncable(gt1)
+	require.Nil(t, res7.Err)
+	d2 := res7.Data.(*model.GroupSyncable)
+	require.True(t, d2.AutoAdd)
+
+	// Non-existent Group
+	gt2 := model.NewGroupTeam(model.NewId(), team.Id, false)
+	res9 := <-ss.Group().UpdateGroupSyncable(gt2)
+	require.Equal(t, res9.Err.Id, "store.sql_group.no_rows")
+
+	// Non-existent Team
+	gt3 := model.NewGroupTeam(group.Id, model.NewId(), false)
+	res10 := <-ss.Group().UpdateGroupSyncable(gt3)
+	require.Equal(t, res10.Err.Id, "store.sql_group.no_rows")
+
+	// Cannot update CreateAt or DeleteAt
+	origCreateAt := d1.CreateAt
+	d1.CreateAt = model.GetMillis()
+	d1.AutoAdd = true
+	res11 := <-ss.Group().UpdateGroupSyncable(d1)
+	require.Nil(t, res11.Err)
+	d3 := res11.Data.(*model.GroupSyncable)
+	require.Equal(t, origCreateAt, d3.CreateAt)
+
+	// Cannot update DeleteAt to arbitrary value
+	d1.DeleteAt = 1
+	res12 := <-ss.Group().UpdateGroupSyncable(d1)
+	require.Equal(t, "model.group.delete_at.app_error", res12.Err.Id)
+
+	// Can update DeleteAt to 0
+	d1.DeleteAt = 0
+	res13 := <-ss.Group().UpdateGroupSyncable(d1)
+	require.Nil(t, res13.Err)
+	d4 := res13.Data.(*model.GroupSyncable)
+	require.Zero(t, d4.DeleteAt)
+}
+
+func testDeleteGroupSyncable(t *testing.T, ss store.Store) {
+	// Create Group
+	g1 := &model.Group{
+		Name:        model.NewId(),
+		DisplayName: model.NewId(),
+		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
+	}
+	res1 := <-ss.Group().Create(g1)
+	require.Nil(t, res1.Err)
+	group := res1.Data.(*model.Group)
+
+	// Create Team
+	t1 := &model.Team{
+		DisplayName:     "Name",
+		Description:     "Some description",
+		CompanyName:     "Some company name",
+		AllowOpenInvite: false,
+		InviteId:        "inviteid0",
+		Name:            "z-z-" + model.NewId() + "a",
+		Email:           "success+" + model.NewId() + "@simulator.amazonses.com",
+		Type:            model.TEAM_OPEN,
+	}
+	team, err := ss.Team().Save(t1)
+	require.Nil(t, err)
+
+	// Create GroupSyncable
+	gt1 := model.NewGroupTeam(group.Id, team.Id, false)
+	res7 := <-ss.Group().CreateGroupSyncable(gt1)
+	require.Nil(t, res7.Err)
+	groupTeam := res7.Data.(*model.GroupSyncable)
+
+	// Non-existent Group
+	res5 := <-ss.Group().DeleteGroupSyncable(model.NewId(), groupTeam.SyncableId, model.GroupSyncableTypeTeam)
+	require.Equal(t, res5.Err.Id, "store.sql_group.no_rows")
+
+	// Non-existent Team
+	res6 := <-ss.Group().DeleteGroupSyncable(groupTeam.GroupId, string(model.NewId()), model.GroupSyncableTypeTeam)
+	require.Equal(t, res6.Err.Id, "store.sql_group.no_rows")
+
+	// Happy path...
+	res8 := <-ss.Group().DeleteGroupSyncable(groupTeam.GroupId, groupTeam.SyncableId, model.GroupSyncableTypeTeam)
+	require.Nil(t, res8.Err)
+	d1 := res8.Data.(*model.GroupSyncable)
+	require.NotZero(t, d1.DeleteAt)
+	require.Equal(t, d1.GroupId, groupTeam.GroupId)
+	require.Equal(t, d1.SyncableId, groupTeam.SyncableId)
+	require.Equal(t, d1.AutoAdd, groupTeam.AutoAdd)
+	require.Equal(t, d1.CreateAt, groupTeam.CreateAt)
+	require.Condition(t, func() bool { return d1.UpdateAt > groupTeam.UpdateAt })
+
+	// Record already deleted
+	res9 := <-ss.Group().DeleteGroupSyncable(d1.GroupId, d1.SyncableId, d1.Type)
+	require.NotNil(t, res9.Err)
+	require.Equal(t, res9.Err.Id, "store.sql_group.group_syncable_already_deleted")
+}
+
+func testPendingAutoAddTeamMembers(t *testing.T, ss store.Store) {
+	// Create Group
+	res := <-ss.Group().Create(&model.Group{
+		Name:        model.NewId(),
+		DisplayName: "TeamMembersToAdd Test Group",
+		RemoteId:    model.NewId(),
+		Source:      model.GroupSourceLdap,
+	})
+	require.Nil(t, res.Err)
+	group := res.Data.(*model.Group)
+
+	// Create User
+	user := &model.User{
+		Email:    MakeEmail(),
+		Username: model.NewId(),
+	}
+	res = <-ss.User().Save(user)
+	require.Nil(t, res.Err)
+	user = res.Data.(*model.User)
+
+	// Create GroupMember
+	res = <-ss.Group().CreateOrRestoreMember(group.Id, user.Id)
+	require.Nil(t, res.Err)
+
+	// Create Team
+	team := &model.Team{
+		DisplayName:     "Name",
+		Description:     "Some description",
+		CompanyName:     "Some company name",
+		AllowOpenInvite: false,
+		InviteId:        "inviteid0",
+		Name:            "z-z-" + model.NewId() + "a",
+		Email:           "success+" + model.NewId() + "@simulator.amazonses.com",
+		Type:            model.TEAM_OPEN,
+	}
+	team, err := ss.Team().Save(team)
+	require.Nil(t, err)
+
+	// Create GroupTeam
+	res = <-ss.Group().CreateGroupSyncable(model.NewGroupTeam(group.Id, team.Id, true))
+	require.Nil(t, res.Err)
+	syncable := res.Data.(*model.GroupSyncable)
+
+	// Time before syncable was created
+	res = <-ss.Group().TeamMembersToAdd(syncable.CreateAt - 1)
+	require.Nil(t, res.Err)
+	teamMembers := res.Data.([]*model.UserTeamIDPair)
+	require.Len(t, teamMembers, 1)
+	require.Equal(t, user.Id, teamMembers[0].UserID)
+	require.Equal(t, team.Id, teamMembers[0].TeamID)
+
+	// Time after syncable was created
+	res = <-ss.Group().TeamMembersToAdd(syncable.CreateAt + 1)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 0)
+
+	// Delete and restore GroupMember should return result
+	res = <-ss.Group().DeleteMember(group.Id, user.Id)
+	require.Nil(t, res.Err)
+	res = <-ss.Group().CreateOrRestoreMember(group.Id, user.Id)
+	require.Nil(t, res.Err)
+	res = <-ss.Group().TeamMembersToAdd(syncable.CreateAt + 1)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 1)
+
+	pristineSyncable := *syncable
+
+	res = <-ss.Group().UpdateGroupSyncable(syncable)
+	require.Nil(t, res.Err)
+
+	// Time before syncable was updated
+	res = <-ss.Group().TeamMembersToAdd(syncable.UpdateAt - 1)
+	require.Nil(t, res.Err)
+	teamMembers = res.Data.([]*model.UserTeamIDPair)
+	require.Len(t, teamMembers, 1)
+	require.Equal(t, user.Id, teamMembers[0].UserID)
+	require.Equal(t, team.Id, teamMembers[0].TeamID)
+
+	// Time after syncable was updated
+	res = <-ss.Group().TeamMembersToAdd(syncable.UpdateAt + 1)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 0)
+
+	// Only includes if auto-add
+	syncable.AutoAdd = false
+	res = <-ss.Group().UpdateGroupSyncable(syncable)
+	require.Nil(t, res.Err)
+	res = <-ss.Group().TeamMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 0)
+
+	// reset state of syncable and verify
+	res = <-ss.Group().UpdateGroupSyncable(&pristineSyncable)
+	require.Nil(t, res.Err)
+	res = <-ss.Group().TeamMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 1)
+
+	// No result if Group deleted
+	res = <-ss.Group().Delete(group.Id)
+	require.Nil(t, res.Err)
+	res = <-ss.Group().TeamMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 0)
+
+	// reset state of group and verify
+	group.DeleteAt = 0
+	res = <-ss.Group().Update(group)
+	res = <-ss.Group().TeamMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 1)
+
+	// No result if Team deleted
+	team.DeleteAt = model.GetMillis()
+	team, err = ss.Team().Update(team)
+	require.Nil(t, err)
+	res = <-ss.Group().TeamMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 0)
+
+	// reset state of team and verify
+	team.DeleteAt = 0
+	team, err = ss.Team().Update(team)
+	require.Nil(t, err)
+	res = <-ss.Group().TeamMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 1)
+
+	// No result if GroupTeam deleted
+	res = <-ss.Group().DeleteGroupSyncable(group.Id, team.Id, model.GroupSyncableTypeTeam)
+	require.Nil(t, res.Err)
+	res = <-ss.Group().TeamMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 0)
+
+	// reset GroupTeam and verify
+	res = <-ss.Group().UpdateGroupSyncable(&pristineSyncable)
+	require.Nil(t, res.Err)
+	res = <-ss.Group().TeamMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 1)
+
+	// No result if GroupMember deleted
+	res = <-ss.Group().DeleteMember(group.Id, user.Id)
+	require.Nil(t, res.Err)
+	res = <-ss.Group().TeamMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 0)
+
+	// restore group member and verify
+	res = <-ss.Group().CreateOrRestoreMember(group.Id, user.Id)
+	res = <-ss.Group().TeamMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 1)
+
+	// adding team membership stops returning result
+	res = <-ss.Team().SaveMember(&model.TeamMember{
+		TeamId: team.Id,
+		UserId: user.Id,
+	}, 999)
+	require.Nil(t, res.Err)
+	res = <-ss.Group().TeamMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 0)
+}
+
+func testPendingAutoAddChannelMembers(t *testing.T, ss store.Store) {
+	// Create Group
+	res := <-ss.Group().Create(&model.Group{
+		Name:        model.NewId(),
+		DisplayName: "ChannelMembersToAdd Test Group",
+		RemoteId:    model.NewId(),
+		Source:      model.GroupSourceLdap,
+	})
+	require.Nil(t, res.Err)
+	group := res.Data.(*model.Group)
+
+	// Create User
+	user := &model.User{
+		Email:    MakeEmail(),
+		Username: model.NewId(),
+	}
+	res = <-ss.User().Save(user)
+	require.Nil(t, res.Err)
+	user = res.Data.(*model.User)
+
+	// Create GroupMember
+	res = <-ss.Group().CreateOrRestoreMember(group.Id, user.Id)
+	require.Nil(t, res.Err)
+
+	// Create Channel
+	channel := &model.Channel{
+		TeamId:      model.NewId(),
+		DisplayName: "A Name",
+		Name:        model.NewId(),
+		Type:        model.CHANNEL_OPEN, // Query does not look at type so this shouldn't matter.
+	}
+	res = <-ss.Channel().Save(channel, 9999)
+	require.Nil(t, res.Err)
+	channel = res.Data.(*model.Channel)
+
+	// Create GroupChannel
+	res = <-ss.Group().CreateGroupSyncable(model.NewGroupChannel(group.Id, channel.Id, true))
+	require.Nil(t, res.Err)
+	syncable := res.Data.(*model.GroupSyncable)
+
+	// Time before syncable was created
+	res = <-ss.Group().ChannelMembersToAdd(syncable.CreateAt - 1)
+	require.Nil(t, res.Err)
+	channelMembers := res.Data.([]*model.UserChannelIDPair)
+	require.Len(t, channelMembers, 1)
+	require.Equal(t, user.Id, channelMembers[0].UserID)
+	require.Equal(t, channel.Id, channelMembers[0].ChannelID)
+
+	// Time after syncable was created
+	res = <-ss.Group().ChannelMembersToAdd(syncable.CreateAt + 1)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 0)
+
+	// Delete and restore GroupMember should return result
+	res = <-ss.Group().DeleteMember(group.Id, user.Id)
+	require.Nil(t, res.Err)
+	res = <-ss.Group().CreateOrRestoreMember(group.Id, user.Id)
+	require.Nil(t, res.Err)
+	res = <-ss.Group().ChannelMembersToAdd(syncable.CreateAt + 1)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 1)
+
+	pristineSyncable := *syncable
+
+	res = <-ss.Group().UpdateGroupSyncable(syncable)
+	require.Nil(t, res.Err)
+
+	// Time before syncable was updated
+	res = <-ss.Group().ChannelMembersToAdd(syncable.UpdateAt - 1)
+	require.Nil(t, res.Err)
+	channelMembers = res.Data.([]*model.UserChannelIDPair)
+	require.Len(t, channelMembers, 1)
+	require.Equal(t, user.Id, channelMembers[0].UserID)
+	require.Equal(t, channel.Id, channelMembers[0].ChannelID)
+
+	// Time after syncable was updated
+	res = <-ss.Group().ChannelMembersToAdd(syncable.UpdateAt + 1)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 0)
+
+	// Only includes if auto-add
+	syncable.AutoAdd = false
 	res = <-ss.Group().UpdateGroupSyncable(syncable)
 	require.Nil(t, res.Err)
+	res = <-ss.Group().ChannelMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 0)
+
+	// reset state of syncable and verify
+	res = <-ss.Group().UpdateGroupSyncable(&pristineSyncable)
+	require.Nil(t, res.Err)
+	res = <-ss.Group().ChannelMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 1)
+
+	// No result if Group deleted
+	res = <-ss.Group().Delete(group.Id)
+	require.Nil(t, res.Err)
+	res = <-ss.Group().ChannelMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 0)
+
+	// reset state of group and verify
+	group.DeleteAt = 0
+	res = <-ss.Group().Update(group)
+	res = <-ss.Group().ChannelMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 1)
+
+	// No result if Channel deleted
+	res = <-ss.Channel().Delete(channel.Id, model.GetMillis())
+	require.Nil(t, res.Err)
+	res = <-ss.Group().ChannelMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 0)
+
+	// reset state of channel and verify
+	channel.DeleteAt = 0
+	res = <-ss.Channel().Update(channel)
+	require.Nil(t, res.Err)
+	res = <-ss.Group().ChannelMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 1)
+
+	// No result if GroupChannel deleted
+	res = <-ss.Group().DeleteGroupSyncable(group.Id, channel.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, res.Err)
+	res = <-ss.Group().ChannelMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 0)
+
+	// reset GroupChannel and verify
+	res = <-ss.Group().UpdateGroupSyncable(&pristineSyncable)
+	require.Nil(t, res.Err)
+	res = <-ss.Group().ChannelMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 1)
+
+	// No result if GroupMember deleted
+	res = <-ss.Group().DeleteMember(group.Id, user.Id)
+	require.Nil(t, res.Err)
+	res = <-ss.Group().ChannelMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 0)
+
+	// restore group member and verify
+	res = <-ss.Group().CreateOrRestoreMember(group.Id, user.Id)
+	require.Nil(t, res.Err)
+	res = <-ss.Group().ChannelMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 1)
+
+	// Adding Channel (ChannelMemberHistory) should stop returning result
+	res = <-ss.ChannelMemberHistory().LogJoinEvent(user.Id, channel.Id, model.GetMillis())
+	require.Nil(t, res.Err)
+	res = <-ss.Group().ChannelMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 0)
+
+	// Leaving Channel (ChannelMemberHistory) should still not return result
+	res = <-ss.ChannelMemberHistory().LogLeaveEvent(user.Id, channel.Id, model.GetMillis())
+	require.Nil(t, res.Err)
+	res = <-ss.Group().ChannelMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 0)
+
+	// Purging ChannelMemberHistory re-returns the result
+	res = <-ss.ChannelMemberHistory().PermanentDeleteBatch(model.GetMillis()+1, 100)
+	require.Nil(t, res.Err)
+	res = <-ss.Group().ChannelMembersToAdd(0)
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 1)
+}
+
+func testPendingTeamMemberRemovals(t *testing.T, ss store.Store) {
+	data := pendingMemberRemovalsDataSetup(t, ss)
+
+	// one result when both users are in the group (for user C)
+	res := <-ss.Group().TeamMembersToRemove()
+
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 1)
+	teamMembers := res.Data.([]*model.TeamMember)
+	require.Equal(t, data.UserC.Id, teamMembers[0].UserId)
+
+	res = <-ss.Group().DeleteMember(data.Group.Id, data.UserB.Id)
+	require.Nil(t, res.Err)
+
+	// user b and c should now be returned
+	res = <-ss.Group().TeamMembersToRemove()
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 2)
+	teamMembers = res.Data.([]*model.TeamMember)
+
+	var userIDs []string
+	for _, item := range teamMembers {
+		userIDs = append(userIDs, item.UserId)
+	}
+	require.Contains(t, userIDs, data.UserB.Id)
+	require.Contains(t, userIDs, data.UserC.Id)
+	require.Equal(t, data.ConstrainedTeam.Id, teamMembers[0].TeamId)
+	require.Equal(t, data.ConstrainedTeam.Id, teamMembers[1].TeamId)
+
+	res = <-ss.Group().DeleteMember(data.Group.Id, data.UserA.Id)
+	require.Nil(t, res.Err)
+
+	res = <-ss.Group().TeamMembersToRemove()
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 3)
+
+	// add users back to groups
+	res = <-ss.Team().RemoveMember(data.ConstrainedTeam.Id, data.UserA.Id)
+	require.Nil(t, res.Err)
+	res = <-ss.Team().RemoveMember(data.ConstrainedTeam.Id, data.UserB.Id)
+	require.Nil(t, res.Err)
+	res = <-ss.Team().RemoveMember(data.ConstrainedTeam.Id, data.UserC.Id)
+	require.Nil(t, res.Err)
+	res = <-ss.Channel().RemoveMember(data.ConstrainedChannel.Id, data.UserA.Id)
+	require.Nil(t, res.Err)
+	res = <-ss.Channel().RemoveMember(data.ConstrainedChannel.Id, data.UserB.Id)
+	require.Nil(t, res.Err)
+	res = <-ss.Channel().RemoveMember(data.ConstrainedChannel.Id, data.UserC.Id)
+	require.Nil(t, res.Err)
+}
+
+func testPendingChannelMemberRemovals(t *testing.T, ss store.Store) {
+	data := pendingMemberRemovalsDataSetup(t, ss)
+
+	// one result when both users are in the group (for user C)
+	res := <-ss.Group().ChannelMembersToRemove()
+
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 1)
+	channelMembers := res.Data.([]*model.ChannelMember)
+	require.Equal(t, data.UserC.Id, channelMembers[0].UserId)
+
+	res = <-ss.Group().DeleteMember(data.Group.Id, data.UserB.Id)
+	require.Nil(t, res.Err)
+
+	// user b and c should now be returned
+	res = <-ss.Group().ChannelMembersToRemove()
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 2)
+	channelMembers = res.Data.([]*model.ChannelMember)
+
+	var userIDs []string
+	for _, item := range channelMembers {
+		userIDs = append(userIDs, item.UserId)
+	}
+	require.Contains(t, userIDs, data.UserB.Id)
+	require.Contains(t, userIDs, data.UserC.Id)
+	require.Equal(t, data.ConstrainedChannel.Id, channelMembers[0].ChannelId)
+	require.Equal(t, data.ConstrainedChannel.Id, channelMembers[1].ChannelId)
+
+	res = <-ss.Group().DeleteMember(data.Group.Id, data.UserA.Id)
+	require.Nil(t, res.Err)
+
+	res = <-ss.Group().ChannelMembersToRemove()
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data, 3)
+
+	// add users back to groups
+	res = <-ss.Team().RemoveMember(data.ConstrainedTeam.Id, data.UserA.Id)
+	require.Nil(t, res.Err)
+	res = <-ss.Team().RemoveMember(data.ConstrainedTeam.Id, data.UserB.Id)
+	require.Nil(t, res.Err)
+	res = <-ss.Team().RemoveMember(data.ConstrainedTeam.Id, data.UserC.Id)
+	require.Nil(t, res.Err)
+	res = <-ss.Channel().RemoveMember(data.ConstrainedChannel.Id, data.UserA.Id)
+	require.Nil(t, res.Err)
+	res = <-ss.Channel().RemoveMember(data.ConstrainedChannel.Id, data.UserB.Id)
+	require.Nil(t, res.Err)
+	res = <-ss.Channel().RemoveMember(data.ConstrainedChannel.Id, data.UserC.Id)
+	require.Nil(t, res.Err)
+}
+
+type removalsData struct {
+	UserA                *model.User
+	UserB                *model.User
+	UserC                *model.User
+	ConstrainedChannel   *model.Channel
+	UnconstrainedChannel *model.Channel
+	ConstrainedTeam      *model.Team
+	UnconstrainedTeam    *model.Team
+	Group                *model.Group
+}
+
+func pendingMemberRemovalsDataSetup(t *testing.T, ss store.Store) *removalsData {
+	// create group
+	res := <-ss.Group().Create(&model.Group{
+		Name:        model.NewId(),
+		DisplayName: "Pending[Channel|Team]MemberRemovals Test Group",
+		RemoteId:    model.NewId(),
+		Source:      model.GroupSourceLdap,
+	})
+	require.Nil(t, res.Err)
+	group := res.Data.(*model.Group)
+
+	// create users
+	// userA will get removed from the group
+	userA := &model.User{
+		Email:    MakeEmail(),
+		Username: model.NewId(),
+	}
+	res = <-ss.User().Save(userA)
+	require.Nil(t, res.Err)
+	userA = res.Data.(*model.User)
+
+	// userB will not get removed from the group
+	userB := &model.User{
+		Email:    MakeEmail(),
+		Username: model.NewId(),
+	}
+	res = <-ss.User().Save(userB)
+	require.Nil(t, res.Err)
+	userB = res.Data.(*model.User)
+
+	// userC was never in the group
+	userC := &model.User{
+		Email:    MakeEmail(),
+		Username: model.NewId(),
+	}
+	res = <-ss.User().Save(userC)
+	require.Nil(t, res.Err)
+	userC = res.Data.(*model.User)
+
+	// add users to group (but not userC)
+	res = <-ss.Group().CreateOrRestoreMember(group.Id, userA.Id)
+	require.Nil(t, res.Err)
+
+	res = <-ss.Group().CreateOrRestoreMember(group.Id, userB.Id)
+	require.Nil(t, res.Err)
+
+	// create channels
+	channelConstrained := &model.Channel{
+		TeamId:           model.NewId(),
+		DisplayName:      "A Name",
+		Name:             model.NewId(),
+		Type:             model.CHANNEL_PRIVATE,
+		GroupConstrained: model.NewBool(true),
+	}
+	res = <-ss.Channel().Save(channelConstrained, 9999)
+	require.Nil(t, res.Err)
+	channelConstrained = res.Data.(*model.Channel)
+
+	channelUnconstrained := &model.Channel{
+		TeamId:      model.NewId(),
+		DisplayName: "A Name",
+		Name:        model.NewId(),
+		Type:        model.CHANNEL_PRIVATE,
+	}
+	res = <-ss.Channel().Save(channelUnconstrained, 9999)
+	require.Nil(t, res.Err)
+	channelUnconstrained = res.Data.(*model.Channel)
+
+	// create teams
+	teamConstrained := &model.Team{
+		DisplayName:      "Name",
+		Description:      "Some description",
+		CompanyName:      "Some company name",
+		AllowOpenInvite:  false,
+		InviteId:         "inviteid0",
+		Name:             "z-z-" + model.NewId() + "a",
+		Email:            "success+" + model.NewId() + "@simulator.amazonses.com",
+		Type:             model.TEAM_INVITE,
+		GroupConstrained: model.NewBool(true),
+	}
+	teamConstrained, err := ss.Team().Save(teamConstrained)
+	require.Nil(t, err)
+
+	teamUnconstrained := &model.Team{
+		DisplayName:     "Name",
+		Description:     "Some description",
+		CompanyName:     "Some company name",
+		AllowOpenInvite: false,
+		InviteId:        "inviteid1",
+		Name:            "z-z-" + model.NewId() + "a",
+		Email:           "success+" + model.NewId() + "@simulator.amazonses.com",
+		Type:            model.TEAM_INVITE,
+	}
+	teamUnconstrained, err = ss.Team().Save(teamUnconstrained)
+	require.Nil(t, err)
+
+	// create groupteams
+	res = <-ss.Group().CreateGroupSyncable(model.NewGroupTeam(group.Id, teamConstrained.Id, true))
+	require.Nil(t, res.Err)
+
+	res = <-ss.Group().CreateGroupSyncable(model.NewGroupTeam(group.Id, teamUnconstrained.Id, true))
+	require.Nil(t, res.Err)
+
+	// create groupchannels
+	res = <-ss.Group().CreateGroupSyncable(model.NewGroupChannel(group.Id, channelConstrained.Id, true))
+	require.Nil(t, res.Err)
+
+	res = <-ss.Group().CreateGroupSyncable(model.NewGroupChannel(group.Id, channelUnconstrained.Id, true))
+	require.Nil(t, res.Err)
+
+	// add users to teams
+	userIDTeamIDs := [][]string{
+		{userA.Id, teamConstrained.Id},
+		{userB.Id, teamConstrained.Id},
+		{userC.Id, teamConstrained.Id},
+		{userA.Id, teamUnconstrained.Id},
+		{userB.Id, teamUnconstrained.Id},
+		{userC.Id, teamUnconstrained.Id},
+	}
+
+	for _, item := range userIDTeamIDs {
+		res = <-ss.Team().SaveMember(&model.TeamMember{
+			UserId: item[0],
+			TeamId: item[1],
+		}, 99)
+		require.Nil(t, res.Err)
+	}
+
+	// add users to channels
+	userIDChannelIDs := [][]string{
+		{userA.Id, channelConstrained.Id},
+		{userB.Id, channelConstrained.Id},
+		{userC.Id, channelConstrained.Id},
+		{userA.Id, channelUnconstrained.Id},
+		{userB.Id, channelUnconstrained.Id},
+		{userC.Id, channelUnconstrained.Id},
+	}
+
+	for _, item := range userIDChannelIDs {
+		res = <-ss.Channel().SaveMember(&model.ChannelMember{
+			UserId:      item[0],
+			ChannelId:   item[1],
+			NotifyProps: model.GetDefaultChannelNotifyProps(),
+		})
+		require.Nil(t, res.Err)
+	}
+
+	return &removalsData{
+		UserA:                userA,
+		UserB:                userB,
+		UserC:                userC,
+		ConstrainedChannel:   channelConstrained,
+		UnconstrainedChannel: channelUnconstrained,
+		ConstrainedTeam:      teamConstrained,
+		UnconstrainedTeam:    teamUnconstrained,
+		Group:                group,
+	}
+}
+
+func testGetGroupsByChannelIncludingTeam(t *testing.T, ss store.Store) {
+	teamId := model.NewId()
+	res := <-ss.Channel().Save(&model.Channel{
+		TeamId:      teamId,
+		DisplayName: "Channel1",
+		Name:        model.NewId(),
+		Type:        model.CHANNEL_OPEN,
+	}, 9999)
+	require.Nil(t, res.Err)
+	channel := res.Data.(*model.Channel)
+
+	createGroup := func(displayName string) *model.Group {
+		res := <-ss.Group().Create(&model.Group{
+			Name:        model.NewId(),
+			DisplayName: displayName,
+			RemoteId:    model.NewId(),
+			Source:      model.GroupSourceLdap,
+		})
+		require.Nil(t, res.Err)
+		return res.Data.(*model.Group)
+	}
+	link := func(group *model.Group, syncableId string, syncableType model.GroupSyncableType) {
+		res := <-ss.Group().CreateGroupSyncable(&model.GroupSyncable{
+			AutoAdd:    true,
+			SyncableId: syncableId,
+			Type:       syncableType,
+			GroupId:    group.Id,
+		})
+		require.Nil(t, res.Err)
+	}
+
+	channelGroup := createGroup("group-1")
+	teamGroup := createGroup("group-2")
+	bothGroup := createGroup("group-3")
+	unlinkedGroup := createGroup("group-4")
+
+	link(channelGroup, channel.Id, model.GroupSyncableTypeChannel)
+	link(teamGroup, teamId, model.GroupSyncableTypeTeam)
+	link(bothGroup, channel.Id, model.GroupSyncableTypeChannel)
+	link(bothGroup, teamId, model.GroupSyncableTypeTeam)
+	link(unlinkedGroup, teamId, model.GroupSyncableTypeTeam)
+	res = <-ss.Group().DeleteGroupSyncable(unlinkedGroup.Id, teamId, model.GroupSyncableTypeTeam)
+	require.Nil(t, res.Err)
+
+	res = <-ss.Group().GetGroupsByChannel(channel.Id, 0, 60, model.GroupSearchOpts{})
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data.([]*model.Group), 2)
+
+	res = <-ss.Group().GetGroupsByChannel(channel.Id, 0, 60, model.GroupSearchOpts{IncludeTeamGroups: true})
+	require.Nil(t, res.Err)
+	groups := res.Data.([]*model.Group)
+	require.Len(t, groups, 3)
+
+	viaTeam := map[string]bool{}
+	for _, group := range groups {
+		viaTeam[group.Id] = group.ViaTeam
+	}
+	require.Equal(t, map[string]bool{channelGroup.Id: false, teamGroup.Id: true, bothGroup.Id: false}, viaTeam)
+}
+
+func testGetGroupsByTeamIncludingChannels(t *testing.T, ss store.Store) {
+	teamId := model.NewId()
+	var channels []*model.Channel
+	for i := 0; i < 2; i++ {
+		res := <-ss.Channel().Save(&model.Channel{
+			TeamId:      teamId,
+			DisplayName: "Channel",
+			Name:        model.NewId(),
+			Type:        model.CHANNEL_OPEN,
+		}, 9999)
+		require.Nil(t, res.Err)
+		channels = append(channels, res.Data.(*model.Channel))
+	}
+
+	createGroup := func(displayName string) *model.Group {
+		res := <-ss.Group().Create(&model.Group{
+			Name:        model.NewId(),
+			DisplayName: displayName,
+			RemoteId:    model.NewId(),
+			Source:      model.GroupSourceLdap,
+		})
+		require.Nil(t, res.Err)
+		return res.Data.(*model.Group)
+	}
+	link := func(group *model.Group, syncableId string, syncableType model.GroupSyncableType) {
+		res := <-ss.Group().CreateGroupSyncable(&model.GroupSyncable{
+			AutoAdd:    true,
+			SyncableId: syncableId,
+			Type:       syncableType,
+			GroupId:    group.Id,
+		})
+		require.Nil(t, res.Err)
+	}
+
+	teamGroup := createGroup("group-1")
+	channelGroup := createGroup("group-2")
+	bothGroup := createGroup("group-3")
+	otherTeamGroup := createGroup("group-4")
+
+	link(teamGroup, teamId, model.GroupSyncableTypeTeam)
+	link(channelGroup, channels[0].Id, model.GroupSyncableTypeChannel)
+	link(channelGroup, channels[1].Id, model.GroupSyncableTypeChannel)
+	link(bothGroup, teamId, model.GroupSyncableTypeTeam)
+	link(bothGroup, channels[0].Id, model.GroupSyncableTypeChannel)
+	link(otherTeamGroup, model.NewId(), model.GroupSyncableTypeTeam)
+
+	res := <-ss.Group().GetGroupsByTeam(teamId, 0, 60, model.GroupSearchOpts{})
+	require.Nil(t, res.Err)
+	require.Len(t, res.Data.([]*model.Group), 2)
+
+	res = <-ss.Group().GetGroupsByTeam(teamId, 0, 60, model.GroupSearchOpts{IncludeChannelGroups: true})
+	require.Nil(t, res.Err)
+	groups := res.Data.([]*model.Group)
+	require.Len(t, groups, 3)
+
+	viaChannel := map[string]bool{}
+	for _, group := range groups {
+		viaChannel[group.Id] = group.ViaChannel
+	}
+	require.Equal(t, map[string]bool{teamGroup.Id: false, channelGroup.Id: true, bothGroup.Id: false}, viaChannel)
+}
+
+func testGetGroupsByChannel(t *testing.T, ss store.Store) {
+	// Create Channel1
+	channel1 := &model.Channel{
+		TeamId:      model.NewId(),
+		DisplayName: "Channel1",
+		Name:        model.NewId(),
+		Type:        model.CHANNEL_OPEN,
+	}
+	res := <-ss.Channel().Save(channel1, 9999)
+	require.Nil(t, res.Err)
+	channel1 = res.Data.(*model.Channel)
+
+	// Create Groups 1 and 2
+	res = <-ss.Group().Create(&model.Group{
+		Name:        model.NewId(),
+		DisplayName: "group-1",
+		RemoteId:    model.NewId(),
+		Source:      model.GroupSourceLdap,
+	})
+	require.Nil(t, res.Err)
+	group1 := res.Data.(*model.Group)
+
+	res = <-ss.Group().Create(&model.Group{
+		Name:        model.NewId(),
+		DisplayName: "group-2",
+		RemoteId:    model.NewId(),
+		Source:      model.GroupSourceLdap,
+	})
+	require.Nil(t, res.Err)
+	group2 := res.Data.(*model.Group)
+
+	// And associate them with Channel1
+	for _, g := range []*model.Group{group1, group2} {
+		res = <-ss.Group().CreateGroupSyncable(&model.GroupSyncable{
+			AutoAdd:    true,
+			SyncableId: channel1.Id,
+			Type:       model.GroupSyncableTypeChannel,
+			GroupId:    g.Id,
+		})
+		require.Nil(t, res.Err)
+	}
+
+	// Create Channel2
+	channel2 := &model.Channel{
+		TeamId:      model.NewId(),
+		DisplayName: "Channel2",
+		Name:        model.NewId(),
+		Type:        model.CHANNEL_OPEN,
+	}
+	res = <-ss.Channel().Save(channel2, 9999)
+	require.Nil(t, res.Err)
+	channel2 = res.Data.(*model.Channel)
+
+	// Create Group3
+	res = <-ss.Group().Create(&model.Group{
+		Name:        model.NewId(),
+		DisplayName: "group-3",
+		RemoteId:    model.NewId(),
+		Source:      model.GroupSourceLdap,
+	})
+	require.Nil(t, res.Err)
+	group3 := res.Data.(*model.Group)
+
+	// And associate it to Channel2
+	res = <-ss.Group().CreateGroupSyncable(&model.GroupSyncable{
+		AutoAdd:    true,
+		SyncableId: channel2.Id,
+		Type:       model.GroupSyncableTypeChannel,
+		GroupId:    group3.Id,
+	})
+	require.Nil(t, res.Err)
+
+	// Link Group1 to Channel2 and unlink it again, which should leave it out of the results
+	res = <-ss.Group().CreateGroupSyncable(&model.GroupSyncable{
+		AutoAdd:    true,
+		SyncableId: channel2.Id,
+		Type:       model.GroupSyncableTypeChannel,
+		GroupId:    group1.Id,
+	})
+	require.Nil(t, res.Err)
+	res = <-ss.Group().DeleteGroupSyncable(group1.Id, channel2.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, res.Err)
+
+	testCases := []struct {
+		Name      string
+		ChannelId string
+		Page      int
+		PerPage   int
+		Result    []*model.Group
+	}{
+		{
+			Name:      "Get the two Groups for Channel1",
+			ChannelId: channel1.Id,
+			Page:      0,
+			PerPage:   60,
+			Result:    []*model.Group{group1, group2},
+		},
+		{
+			Name:      "Get first Group for Channel1 with page 0 with 1 element",
+			ChannelId: channel1.Id,
+			Page:      0,
+			PerPage:   1,
+			Result:    []*model.Group{group1},
+		},
+		{
+			Name:      "Get second Group for Channel1 with page 1 with 1 element",
+			ChannelId: channel1.Id,
+			Page:      1,
+			PerPage:   1,
+			Result:    []*model.Group{group2},
+		},
+		{
+			Name:      "Get third Group for Channel2",
+			ChannelId: channel2.Id,
+			Page:      0,
+			PerPage:   60,
+			Result:    []*model.Group{group3},
+		},
+		{
+			Name:      "Get empty Groups for a fake id",
+			ChannelId: model.NewId(),
+			Page:      0,
+			PerPage:   60,
+			Result:    []*model.Group{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			res := <-ss.Group().GetGroupsByChannel(tc.ChannelId, tc.Page, tc.PerPage, model.GroupSearchOpts{})
+			require.Nil(t, res.Err)
+			require.ElementsMatch(t, tc.Result, res.Data.([]*model.Group))
+		})
+	}
+}
+
+func testGetGroupsByTeam(t *testing.T, ss store.Store) {
+	// Create Team1
+	team1 := &model.Team{
+		DisplayName:     "Team1",
+		Description:     model.NewId(),
+		CompanyName:     model.NewId(),
+		AllowOpenInvite: false,
+		InviteId:        model.NewId(),
+		Name:            model.NewId(),
+		Email:           "success+" + model.NewId() + "@simulator.amazonses.com",
+		Type:            model.TEAM_OPEN,
+	}
+	team1, err := ss.Team().Save(team1)
+	require.Nil(t, err)
+
+	// Create Groups 1 and 2
+	res := <-ss.Group().Create(&model.Group{
+		Name:        model.NewId(),
+		DisplayName: "group-1",
+		RemoteId:    model.NewId(),
+		Source:      model.GroupSourceLdap,
+	})
+	require.Nil(t, res.Err)
+	group1 := res.Data.(*model.Group)
+
+	res = <-ss.Group().Create(&model.Group{
+		Name:        model.NewId(),
+		DisplayName: "group-2",
+		RemoteId:    model.NewId(),
+		Source:      model.GroupSourceLdap,
+	})
+	require.Nil(t, res.Err)
+	group2 := res.Data.(*model.Group)
+
+	// And associate them with Team1, only Group1 adding members automatically
+	for _, g := range []*model.Group{group1, group2} {
+		res = <-ss.Group().CreateGroupSyncable(&model.GroupSyncable{
+			AutoAdd:    g == group1,
+			SyncableId: team1.Id,
+			Type:       model.GroupSyncableTypeTeam,
+			GroupId:    g.Id,
+		})
+		require.Nil(t, res.Err)
+	}
+
+	// Create Team2
+	team2 := &model.Team{
+		DisplayName:     "Team2",
+		Description:     model.NewId(),
+		CompanyName:     model.NewId(),
+		AllowOpenInvite: false,
+		InviteId:        model.NewId(),
+		Name:            model.NewId(),
+		Email:           "success+" + model.NewId() + "@simulator.amazonses.com",
+		Type:            model.TEAM_INVITE,
+	}
+	team2, err = ss.Team().Save(team2)
+	require.Nil(t, err)
+
+	// Create Group3
+	res = <-ss.Group().Create(&model.Group{
+		Name:        model.NewId(),
+		DisplayName: "group-3",
+		RemoteId:    model.NewId(),
+		Source:      model.GroupSourceLdap,
+	})
+	require.Nil(t, res.Err)
+	group3 := res.Data.(*model.Group)
 
-	// Time before syncable was updated
-	res = <-ss.Group().TeamMembersToAdd(syncable.UpdateAt - 1)
+	// And associate it to Team2
+	res = <-ss.Group().CreateGroupSyncable(&model.GroupSyncable{
+		AutoAdd:    true,
+		SyncableId: team2.Id,
+		Type:       model.GroupSyncableTypeTeam,
+		GroupId:    group3.Id,
+	})
 	require.Nil(t, res.Err)
-	teamMembers = res.Data.([]*model.UserTeamIDPair)
-	require.Len(t, teamMembers, 1)
-	require.Equal(t, user.Id, teamMembers[0].UserID)
-	require.Equal(t, team.Id, teamMembers[0].TeamID)
 
-	// Time after syncable was updated
-	res = <-ss.Group().TeamMembersToAdd(syncable.UpdateAt + 1)
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 0)
+	// Link Group1 to Team2 and unlink it again, which should leave it out of the results
+	res = <-ss.Group().CreateGroupSyncable(&model.GroupSyncable{
+		AutoAdd:    true,
+		SyncableId: team2.Id,
+		Type:       model.GroupSyncableTypeTeam,
+		GroupId:    group1.Id,
+	})
+	require.Nil(t, res.Err)
+	res = <-ss.Group().DeleteGroupSyncable(group1.Id, team2.Id, model.GroupSyncableTypeTeam)
+	require.Nil(t, res.Err)
+
+	testCases := []struct {
+		Name    string
+		TeamId  string
+		Page    int
+		PerPage int
+		Opts    model.GroupSearchOpts
+		Result  []*model.Group
+	}{
+		{
+			Name:    "Get only the auto-add Group for Team1",
+			TeamId:  team1.Id,
+			Page:    0,
+			PerPage: 60,
+			Opts:    model.GroupSearchOpts{FilterAutoAdd: true},
+			Result:  []*model.Group{group1},
+		},
+		{
+			Name:    "Get the two Groups for Team1",
+			TeamId:  team1.Id,
+			Page:    0,
+			PerPage: 60,
+			Result:  []*model.Group{group1, group2},
+		},
+		{
+			Name:    "Get first Group for Team1 with page 0 with 1 element",
+			TeamId:  team1.Id,
+			Page:    0,
+			PerPage: 1,
+			Result:  []*model.Group{group1},
+		},
+		{
+			Name:    "Get second Group for Team1 with page 1 with 1 element",
+			TeamId:  team1.Id,
+			Page:    1,
+			PerPage: 1,
+			Result:  []*model.Group{group2},
+		},
+		{
+			Name:    "Get third Group for Team2",
+			TeamId:  team2.Id,
+			Page:    0,
+			PerPage: 60,
+			Result:  []*model.Group{group3},
+		},
+		{
+			Name:    "Get empty Groups for a fake id",
+			TeamId:  model.NewId(),
+			Page:    0,
+			PerPage: 60,
+			Result:  []*model.Group{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			res := <-ss.Group().GetGroupsByTeam(tc.TeamId, tc.Page, tc.PerPage, tc.Opts)
+			require.Nil(t, res.Err)
+			require.ElementsMatch(t, tc.Result, res.Data.([]*model.Group))
+		})
+	}
+}
+
+func testGetGroups(t *testing.T, ss store.Store) {
+	// Create Team1 and a Channel in it
+	team1 := &model.Team{
+		DisplayName:     "Team1",
+		Description:     model.NewId(),
+		CompanyName:     model.NewId(),
+		AllowOpenInvite: false,
+		InviteId:        model.NewId(),
+		Name:            model.NewId(),
+		Email:           "success+" + model.NewId() + "@simulator.amazonses.com",
+		Type:            model.TEAM_OPEN,
+	}
+	team1, err := ss.Team().Save(team1)
+	require.Nil(t, err)
+
+	channel1 := &model.Channel{
+		TeamId:      team1.Id,
+		DisplayName: "Channel1",
+		Name:        model.NewId(),
+		Type:        model.CHANNEL_OPEN,
+	}
+	res := <-ss.Channel().Save(channel1, 9999)
+	require.Nil(t, res.Err)
+	channel1 = res.Data.(*model.Channel)
+
+	// Create Groups 1, 2, 3 and 4
+	groups := []*model.Group{}
+	for i := 1; i <= 4; i++ {
+		res = <-ss.Group().Create(&model.Group{
+			Name:        model.NewId(),
+			DisplayName: fmt.Sprintf("group-%d", i),
+			RemoteId:    model.NewId(),
+			Source:      model.GroupSourceLdap,
+		})
+		require.Nil(t, res.Err)
+		groups = append(groups, res.Data.(*model.Group))
+	}
+	group1, group2, group3, group4 := groups[0], groups[1], groups[2], groups[3]
+
+	// Link Groups 1, 2 and 4 to Team1
+	for _, g := range []*model.Group{group1, group2, group4} {
+		res = <-ss.Group().CreateGroupSyncable(&model.GroupSyncable{
+			AutoAdd:    true,
+			SyncableId: team1.Id,
+			Type:       model.GroupSyncableTypeTeam,
+			GroupId:    g.Id,
+		})
+		require.Nil(t, res.Err)
+	}
+
+	// Unlink Group4 again, leaving a deleted syncable behind
+	res = <-ss.Group().DeleteGroupSyncable(group4.Id, team1.Id, model.GroupSyncableTypeTeam)
+	require.Nil(t, res.Err)
+
+	// Link Group2 to Channel1
+	res = <-ss.Group().CreateGroupSyncable(&model.GroupSyncable{
+		AutoAdd:    true,
+		SyncableId: channel1.Id,
+		Type:       model.GroupSyncableTypeChannel,
+		GroupId:    group2.Id,
+	})
+	require.Nil(t, res.Err)
+
+	testCases := []struct {
+		Name    string
+		Page    int
+		PerPage int
+		Opts    model.GroupSearchOpts
+		Result  []*model.Group
+	}{
+		{
+			Name:    "Filter by parent team returns only groups linked to the team",
+			Page:    0,
+			PerPage: 60,
+			Opts:    model.GroupSearchOpts{FilterParentTeamPermitted: &team1.Id},
+			Result:  []*model.Group{group1, group2},
+		},
+		{
+			Name:    "Filter by parent team with paging",
+			Page:    1,
+			PerPage: 1,
+			Opts:    model.GroupSearchOpts{FilterParentTeamPermitted: &team1.Id},
+			Result:  []*model.Group{group2},
+		},
+		{
+			Name:    "Filter by parent team excludes groups already linked to the channel",
+			Page:    0,
+			PerPage: 60,
+			Opts:    model.GroupSearchOpts{FilterParentTeamPermitted: &team1.Id, NotAssociatedToChannel: channel1.Id},
+			Result:  []*model.Group{group1},
+		},
+		{
+			Name:    "Filter by parent team excludes groups already linked to the team",
+			Page:    0,
+			PerPage: 60,
+			Opts:    model.GroupSearchOpts{FilterParentTeamPermitted: &team1.Id, NotAssociatedToTeam: team1.Id},
+			Result:  []*model.Group{},
+		},
+		{
+			Name:    "Filter by parent team and search term",
+			Page:    0,
+			PerPage: 60,
+			Opts:    model.GroupSearchOpts{FilterParentTeamPermitted: &team1.Id, Q: group1.Name},
+			Result:  []*model.Group{group1},
+		},
+		{
+			Name:    "Search term wildcards are matched literally",
+			Page:    0,
+			PerPage: 60,
+			Opts:    model.GroupSearchOpts{FilterParentTeamPermitted: &team1.Id, Q: "%"},
+			Result:  []*model.Group{},
+		},
+		{
+			Name:    "Search term alone matches unlinked groups",
+			Page:    0,
+			PerPage: 60,
+			Opts:    model.GroupSearchOpts{Q: group3.Name},
+			Result:  []*model.Group{group3},
+		},
+		{
+			Name:    "Filter by a team without groups",
+			Page:    0,
+			PerPage: 60,
+			Opts:    model.GroupSearchOpts{FilterParentTeamPermitted: model.NewString(model.NewId())},
+			Result:  []*model.Group{},
+		},
+		{
+			Name:    "Filter by a set of teams returns groups linked to any of them",
+			Page:    0,
+			PerPage: 60,
+			Opts:    model.GroupSearchOpts{FilterTeamIds: []string{model.NewId(), team1.Id}},
+			Result:  []*model.Group{group1, group2},
+		},
+		{
+			Name:    "Filter by an empty set of teams returns nothing",
+			Page:    0,
+			PerPage: 60,
+			Opts:    model.GroupSearchOpts{FilterTeamIds: []string{}, Q: group1.Name},
+			Result:  []*model.Group{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			res := <-ss.Group().GetGroups(tc.Page, tc.PerPage, tc.Opts)
+			require.Nil(t, res.Err)
+			require.ElementsMatch(t, tc.Result, res.Data.([]*model.Group))
+		})
+	}
+}
+
+func testGetGroupsExcludeDefault(t *testing.T, ss store.Store) {
+	prefix := model.NewId()
+
+	createGroup := func(isDefault bool) *model.Group {
+		res := <-ss.Group().Create(&model.Group{
+			Name:        prefix + model.NewId(),
+			DisplayName: model.NewId(),
+			RemoteId:    model.NewId(),
+			Source:      model.GroupSourceCustom,
+			IsDefault:   isDefault,
+		})
+		require.Nil(t, res.Err)
+		return res.Data.(*model.Group)
+	}
+
+	defaultGroup := createGroup(true)
+	userGroup := createGroup(false)
+	require.True(t, defaultGroup.IsDefault)
+
+	res := <-ss.Group().GetGroups(0, 60, model.GroupSearchOpts{Q: prefix})
+	require.Nil(t, res.Err)
+	require.ElementsMatch(t, []*model.Group{defaultGroup, userGroup}, res.Data.([]*model.Group))
+
+	res = <-ss.Group().GetGroups(0, 60, model.GroupSearchOpts{Q: prefix, ExcludeDefault: true})
+	require.Nil(t, res.Err)
+	require.ElementsMatch(t, []*model.Group{userGroup}, res.Data.([]*model.Group))
+}
+
+func testGetGroupsByTag(t *testing.T, ss store.Store) {
+	tag := model.NewId()
+
+	createGroup := func(tags ...string) *model.Group {
+		res := <-ss.Group().Create(&model.Group{
+			Name:        model.NewId(),
+			DisplayName: model.NewId(),
+			RemoteId:    model.NewId(),
+			Source:      model.GroupSourceLdap,
+			Tags:        tags,
+		})
+		require.Nil(t, res.Err)
+		return res.Data.(*model.Group)
+	}
+
+	group1 := createGroup(tag, "department")
+	group2 := createGroup(tag + "-other")
+	createGroup()
+
+	getByTag := func() []*model.Group {
+		res := <-ss.Group().GetGroups(0, 60, model.GroupSearchOpts{Tag: tag})
+		require.Nil(t, res.Err)
+		return res.Data.([]*model.Group)
+	}
+
+	// Only whole tags match
+	require.ElementsMatch(t, []*model.Group{group1}, getByTag())
+
+	// Adding the tag
+	group2.Tags = model.StringArray{tag}
+	res := <-ss.Group().Update(group2)
+	require.Nil(t, res.Err)
+	group2 = res.Data.(*model.Group)
+	require.ElementsMatch(t, []*model.Group{group1, group2}, getByTag())
+
+	// Removing the tag
+	group1.Tags = model.StringArray{"department"}
+	res = <-ss.Group().Update(group1)
+	require.Nil(t, res.Err)
+	require.ElementsMatch(t, []*model.Group{group2}, getByTag())
+
+	// Invalid tags
+	for _, tags := range [][]string{{""}, {"Upper"}, {"a b"}, {tag, tag}} {
+		res = <-ss.Group().Create(&model.Group{
+			Name:        model.NewId(),
+			DisplayName: model.NewId(),
+			RemoteId:    model.NewId(),
+			Source:      model.GroupSourceLdap,
+			Tags:        tags,
+		})
+		require.NotNil(t, res.Err, tags)
+		require.Equal(t, "model.group.tag.app_error", res.Err.Id)
+	}
+
+	tooMany := make(model.StringArray, model.GroupTagsMaxCount+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("tag-%d", i)
+	}
+	res = <-ss.Group().Create(&model.Group{
+		Name:        model.NewId(),
+		DisplayName: model.NewId(),
+		RemoteId:    model.NewId(),
+		Source:      model.GroupSourceLdap,
+		Tags:        tooMany,
+	})
+	require.NotNil(t, res.Err)
+	require.Equal(t, "model.group.tags.app_error", res.Err.Id)
+}
+
+func testMergeGroups(t *testing.T, ss store.Store) {
+	createGroup := func() *model.Group {
+		res := <-ss.Group().Create(&model.Group{
+			Name:        model.NewId(),
+			DisplayName: model.NewId(),
+			RemoteId:    model.NewId(),
+			Source:      model.GroupSourceCustom,
+		})
+		require.Nil(t, res.Err)
+		return res.Data.(*model.Group)
+	}
+	target := createGroup()
+	source := createGroup()
 
-	// Only includes if auto-add
-	syncable.AutoAdd = false
-	res = <-ss.Group().UpdateGroupSyncable(syncable)
-	require.Nil(t, res.Err)
-	res = <-ss.Group().TeamMembersToAdd(0)
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 0)
+	var userIds []string
+	for i := 0; i < 4; i++ {
+		res := <-ss.User().Save(&model.User{Email: MakeEmail(), Username: model.NewId()})
+		require.Nil(t, res.Err)
+		userIds = append(userIds, res.Data.(*model.User).Id)
+	}
 
-	// reset state of syncable and verify
-	res = <-ss.Group().UpdateGroupSyncable(&pristineSyncable)
+	// Overlapping members, including one that was removed from the target
+	res := <-ss.Group().UpsertMembers(target.Id, userIds[:2])
 	require.Nil(t, res.Err)
-	res = <-ss.Group().TeamMembersToAdd(0)
+	res = <-ss.Group().UpsertMembers(target.Id, userIds[3:])
 	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 1)
-
-	// No result if Group deleted
-	res = <-ss.Group().Delete(group.Id)
+	res = <-ss.Group().DeleteMembers(target.Id, userIds[3:])
 	require.Nil(t, res.Err)
-	res = <-ss.Group().TeamMembersToAdd(0)
+	res = <-ss.Group().UpsertMembers(source.Id, userIds[1:])
 	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 0)
 
-	// reset state of group and verify
-	group.DeleteAt = 0
-	res = <-ss.Group().Update(group)
-	res = <-ss.Group().TeamMembersToAdd(0)
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 1)
+	var teams []*model.Team
+	for i := 0; i < 2; i++ {
+		team, err := ss.Team().Save(&model.Team{
+			DisplayName: model.NewId(),
+			Name:        model.NewId(),
+			Email:       MakeEmail(),
+			Type:        model.TEAM_OPEN,
+		})
+		require.Nil(t, err)
+		teams = append(teams, team)
+	}
 
-	// No result if Team deleted
-	team.DeleteAt = model.GetMillis()
-	team, err = ss.Team().Update(team)
-	require.Nil(t, err)
-	res = <-ss.Group().TeamMembersToAdd(0)
+	res = <-ss.Channel().Save(&model.Channel{
+		TeamId:      teams[0].Id,
+		DisplayName: model.NewId(),
+		Name:        model.NewId(),
+		Type:        model.CHANNEL_OPEN,
+	}, 9999)
 	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 0)
+	channel := res.Data.(*model.Channel)
 
-	// reset state of team and verify
-	team.DeleteAt = 0
-	team, err = ss.Team().Update(team)
-	require.Nil(t, err)
-	res = <-ss.Group().TeamMembersToAdd(0)
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 1)
+	link := func(groupId, syncableId string, syncableType model.GroupSyncableType, autoAdd bool) {
+		res := <-ss.Group().CreateGroupSyncable(&model.GroupSyncable{
+			GroupId:    groupId,
+			SyncableId: syncableId,
+			Type:       syncableType,
+			AutoAdd:    autoAdd,
+		})
+		require.Nil(t, res.Err)
+	}
 
-	// No result if GroupTeam deleted
-	res = <-ss.Group().DeleteGroupSyncable(group.Id, team.Id, model.GroupSyncableTypeTeam)
-	require.Nil(t, res.Err)
-	res = <-ss.Group().TeamMembersToAdd(0)
+	// Overlapping links: both groups are linked to the first team, and the target's channel link was removed
+	link(target.Id, teams[0].Id, model.GroupSyncableTypeTeam, false)
+	link(target.Id, channel.Id, model.GroupSyncableTypeChannel, false)
+	res = <-ss.Group().DeleteGroupSyncable(target.Id, channel.Id, model.GroupSyncableTypeChannel)
 	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 0)
+	link(source.Id, teams[0].Id, model.GroupSyncableTypeTeam, true)
+	link(source.Id, teams[1].Id, model.GroupSyncableTypeTeam, true)
+	link(source.Id, channel.Id, model.GroupSyncableTypeChannel, true)
 
-	// reset GroupTeam and verify
-	res = <-ss.Group().UpdateGroupSyncable(&pristineSyncable)
+	res = <-ss.Group().MergeGroups(target.Id, source.Id)
 	require.Nil(t, res.Err)
-	res = <-ss.Group().TeamMembersToAdd(0)
+	require.Equal(t, int64(4), res.Data.(int64))
+
+	res = <-ss.Group().GetMemberUsers(target.Id)
 	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 1)
+	var memberIds []string
+	for _, user := range res.Data.([]*model.User) {
+		memberIds = append(memberIds, user.Id)
+	}
+	require.ElementsMatch(t, userIds, memberIds)
 
-	// No result if GroupMember deleted
-	res = <-ss.Group().DeleteMember(group.Id, user.Id)
+	res = <-ss.Group().GetMemberUsers(source.Id)
 	require.Nil(t, res.Err)
-	res = <-ss.Group().TeamMembersToAdd(0)
+	require.Empty(t, res.Data.([]*model.User))
+
+	// The target keeps its own settings for links it already had
+	res = <-ss.Group().GetGroupSyncable(target.Id, teams[0].Id, model.GroupSyncableTypeTeam)
 	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 0)
+	require.False(t, res.Data.(*model.GroupSyncable).AutoAdd)
 
-	// restore group member and verify
-	res = <-ss.Group().CreateOrRestoreMember(group.Id, user.Id)
-	res = <-ss.Group().TeamMembersToAdd(0)
+	res = <-ss.Group().GetGroupSyncable(target.Id, teams[1].Id, model.GroupSyncableTypeTeam)
 	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 1)
+	require.True(t, res.Data.(*model.GroupSyncable).AutoAdd)
+	require.Zero(t, res.Data.(*model.GroupSyncable).DeleteAt)
 
-	// adding team membership stops returning result
-	res = <-ss.Team().SaveMember(&model.TeamMember{
-		TeamId: team.Id,
-		UserId: user.Id,
-	}, 999)
+	res = <-ss.Group().GetGroupSyncable(target.Id, channel.Id, model.GroupSyncableTypeChannel)
 	require.Nil(t, res.Err)
-	res = <-ss.Group().TeamMembersToAdd(0)
+	require.Zero(t, res.Data.(*model.GroupSyncable).DeleteAt)
+
+	for _, teamId := range []string{teams[0].Id, teams[1].Id} {
+		res = <-ss.Group().GetGroupSyncable(source.Id, teamId, model.GroupSyncableTypeTeam)
+		require.Nil(t, res.Err)
+		require.NotZero(t, res.Data.(*model.GroupSyncable).DeleteAt)
+	}
+
+	res = <-ss.Group().Get(source.Id)
 	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 0)
+	require.NotZero(t, res.Data.(*model.Group).DeleteAt)
 }
 
-func testPendingAutoAddChannelMembers(t *testing.T, ss store.Store) {
-	// Create Group
-	res := <-ss.Group().Create(&model.Group{
-		Name:        model.NewId(),
-		DisplayName: "ChannelMembersToAdd Test Group",
-		RemoteId:    model.NewId(),
-		Source:      model.GroupSourceLdap,
-	})
-	require.Nil(t, res.Err)
-	group := res.Data.(*model.Group)
+func testGroupAutocomplete(t *testing.T, ss store.Store) {
+	prefix := "ac" + model.NewId()[:10]
 
-	// Create User
-	user := &model.User{
-		Email:    MakeEmail(),
-		Username: model.NewId(),
+	createGroup := func(name string, allowReference bool) *model.Group {
+		res := <-ss.Group().Create(&model.Group{
+			Name:           name,
+			DisplayName:    name,
+			RemoteId:       model.NewId(),
+			Source:         model.GroupSourceLdap,
+			AllowReference: allowReference,
+		})
+		require.Nil(t, res.Err)
+		return res.Data.(*model.Group)
 	}
-	res = <-ss.User().Save(user)
-	require.Nil(t, res.Err)
-	user = res.Data.(*model.User)
 
-	// Create GroupMember
-	res = <-ss.Group().CreateOrRestoreMember(group.Id, user.Id)
-	require.Nil(t, res.Err)
+	group2 := createGroup(prefix+"-b", true)
+	group1 := createGroup(prefix+"-a", true)
+	createGroup(prefix+"-hidden", false)
+	createGroup("x"+prefix, true)
 
-	// Create Channel
-	channel := &model.Channel{
-		TeamId:      model.NewId(),
-		DisplayName: "A Name",
-		Name:        model.NewId(),
-		Type:        model.CHANNEL_OPEN, // Query does not look at type so this shouldn't matter.
+	autocomplete := func(namePrefix string, limit int) []*model.Group {
+		res := <-ss.Group().Autocomplete(namePrefix, limit)
+		require.Nil(t, res.Err)
+		return res.Data.([]*model.Group)
 	}
-	res = <-ss.Channel().Save(channel, 9999)
-	require.Nil(t, res.Err)
-	channel = res.Data.(*model.Channel)
 
-	// Create GroupChannel
-	res = <-ss.Group().CreateGroupSyncable(model.NewGroupChannel(group.Id, channel.Id, true))
-	require.Nil(t, res.Err)
-	syncable := res.Data.(*model.GroupSyncable)
+	// Only referenceable groups starting with the prefix match, sorted by name
+	require.Equal(t, []*model.Group{group1, group2}, autocomplete(prefix, 10))
 
-	// Time before syncable was created
-	res = <-ss.Group().ChannelMembersToAdd(syncable.CreateAt - 1)
-	require.Nil(t, res.Err)
-	channelMembers := res.Data.([]*model.UserChannelIDPair)
-	require.Len(t, channelMembers, 1)
-	require.Equal(t, user.Id, channelMembers[0].UserID)
-	require.Equal(t, channel.Id, channelMembers[0].ChannelID)
+	// The middle of a name does not match
+	require.Empty(t, autocomplete(prefix[2:], 10))
 
-	// Time after syncable was created
-	res = <-ss.Group().ChannelMembersToAdd(syncable.CreateAt + 1)
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 0)
+	// Case is ignored
+	require.Equal(t, []*model.Group{group1, group2}, autocomplete(strings.ToUpper(prefix), 10))
 
-	// Delete and restore GroupMember should return result
-	res = <-ss.Group().DeleteMember(group.Id, user.Id)
-	require.Nil(t, res.Err)
-	res = <-ss.Group().CreateOrRestoreMember(group.Id, user.Id)
-	require.Nil(t, res.Err)
-	res = <-ss.Group().ChannelMembersToAdd(syncable.CreateAt + 1)
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 1)
+	// The result is capped
+	require.Equal(t, []*model.Group{group1}, autocomplete(prefix, 1))
 
-	pristineSyncable := *syncable
+	// Like wildcards are matched literally
+	require.Empty(t, autocomplete("%"+prefix, 10))
 
-	res = <-ss.Group().UpdateGroupSyncable(syncable)
+	// Deleted groups are excluded
+	res := <-ss.Group().Delete(group1.Id)
 	require.Nil(t, res.Err)
+	require.Equal(t, []*model.Group{group2}, autocomplete(prefix, 10))
+}
 
-	// Time before syncable was updated
-	res = <-ss.Group().ChannelMembersToAdd(syncable.UpdateAt - 1)
-	require.Nil(t, res.Err)
-	channelMembers = res.Data.([]*model.UserChannelIDPair)
-	require.Len(t, channelMembers, 1)
-	require.Equal(t, user.Id, channelMembers[0].UserID)
-	require.Equal(t, channel.Id, channelMembers[0].ChannelID)
+func testGetGroupsByMemberEmailDomain(t *testing.T, ss store.Store) {
+	domain := model.NewId() + ".com"
+	otherDomain := model.NewId() + ".com"
 
-	// Time after syncable was updated
-	res = <-ss.Group().ChannelMembersToAdd(syncable.UpdateAt + 1)
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 0)
+	createGroup := func(emails ...string) *model.Group {
+		res := <-ss.Group().Create(&model.Group{
+			Name:        model.NewId(),
+			DisplayName: model.NewId(),
+			Source:      model.GroupSourceCustom,
+		})
+		require.Nil(t, res.Err)
+		group := res.Data.(*model.Group)
 
-	// Only includes if auto-add
-	syncable.AutoAdd = false
-	res = <-ss.Group().UpdateGroupSyncable(syncable)
-	require.Nil(t, res.Err)
-	res = <-ss.Group().ChannelMembersToAdd(0)
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 0)
+		for _, email := range emails {
+			res = <-ss.User().Save(&model.User{Email: email, Username: model.NewId()})
+			require.Nil(t, res.Err)
+			res = <-ss.Group().CreateOrRestoreMember(group.Id, res.Data.(*model.User).Id)
+			require.Nil(t, res.Err)
+		}
+		return group
+	}
 
-	// reset state of syncable and verify
-	res = <-ss.Group().UpdateGroupSyncable(&pristineSyncable)
+	matching := createGroup(model.NewId()+"@"+domain, model.NewId()+"@"+otherDomain)
+	otherOnly := createGroup(model.NewId() + "@" + otherDomain)
+	subdomain := createGroup(model.NewId() + "@sub." + domain)
+	createGroup()
+
+	removedMember := createGroup(model.NewId() + "@" + domain)
+	res := <-ss.Group().GetMemberUsers(removedMember.Id)
 	require.Nil(t, res.Err)
-	res = <-ss.Group().ChannelMembersToAdd(0)
+	res = <-ss.Group().DeleteMember(removedMember.Id, res.Data.([]*model.User)[0].Id)
 	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 1)
 
-	// No result if Group deleted
-	res = <-ss.Group().Delete(group.Id)
+	deactivatedMember := createGroup(model.NewId() + "@" + domain)
+	res = <-ss.Group().GetMemberUsers(deactivatedMember.Id)
 	require.Nil(t, res.Err)
-	res = <-ss.Group().ChannelMembersToAdd(0)
+	user := res.Data.([]*model.User)[0]
+	user.DeleteAt = model.GetMillis()
+	res = <-ss.User().Update(user, true)
 	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 0)
 
-	// reset state of group and verify
-	group.DeleteAt = 0
-	res = <-ss.Group().Update(group)
-	res = <-ss.Group().ChannelMembersToAdd(0)
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 1)
+	getIds := func(domain string, page, perPage int) []string {
+		res := <-ss.Group().GetGroupsByMemberEmailDomain(domain, page, perPage)
+		require.Nil(t, res.Err)
+		ids := []string{}
+		for _, group := range res.Data.([]*model.Group) {
+			ids = append(ids, group.Id)
+		}
+		return ids
+	}
 
-	// No result if Channel deleted
-	res = <-ss.Channel().Delete(channel.Id, model.GetMillis())
-	require.Nil(t, res.Err)
-	res = <-ss.Group().ChannelMembersToAdd(0)
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 0)
+	require.Equal(t, []string{matching.Id}, getIds(domain, 0, 100))
+	require.Equal(t, []string{matching.Id}, getIds(strings.ToUpper(domain), 0, 100))
+	require.ElementsMatch(t, []string{matching.Id, otherOnly.Id}, getIds(otherDomain, 0, 100))
+	require.Equal(t, []string{subdomain.Id}, getIds("sub."+domain, 0, 100))
 
-	// reset state of channel and verify
-	channel.DeleteAt = 0
-	res = <-ss.Channel().Update(channel)
-	require.Nil(t, res.Err)
-	res = <-ss.Group().ChannelMembersToAdd(0)
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 1)
+	require.Len(t, getIds(otherDomain, 0, 1), 1)
+	require.Len(t, getIds(otherDomain, 1, 1), 1)
+	require.Empty(t, getIds(otherDomain, 2, 1))
+}
 
-	// No result if GroupChannel deleted
-	res = <-ss.Group().DeleteGroupSyncable(group.Id, channel.Id, model.GroupSyncableTypeChannel)
-	require.Nil(t, res.Err)
-	res = <-ss.Group().ChannelMembersToAdd(0)
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 0)
+func testGetUnusedGroups(t *testing.T, ss store.Store) {
+	createGroup := func(source model.GroupSource) *model.Group {
+		group := &model.Group{
+			Name:        model.NewId(),
+			DisplayName: model.NewId(),
+			Source:      source,
+		}
+		if source == model.GroupSourceLdap {
+			group.RemoteId = model.NewId()
+		}
+		res := <-ss.Group().Create(group)
+		require.Nil(t, res.Err)
+		return res.Data.(*model.Group)
+	}
 
-	// reset GroupChannel and verify
-	res = <-ss.Group().UpdateGroupSyncable(&pristineSyncable)
-	require.Nil(t, res.Err)
-	res = <-ss.Group().ChannelMembersToAdd(0)
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 1)
+	createUser := func() *model.User {
+		res := <-ss.User().Save(&model.User{Email: MakeEmail(), Username: model.NewId()})
+		require.Nil(t, res.Err)
+		return res.Data.(*model.User)
+	}
 
-	// No result if GroupMember deleted
-	res = <-ss.Group().DeleteMember(group.Id, user.Id)
-	require.Nil(t, res.Err)
-	res = <-ss.Group().ChannelMembersToAdd(0)
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 0)
+	user1 := createUser()
+	user2 := createUser()
 
-	// restore group member and verify
-	res = <-ss.Group().CreateOrRestoreMember(group.Id, user.Id)
-	require.Nil(t, res.Err)
-	res = <-ss.Group().ChannelMembersToAdd(0)
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 1)
+	unused := createGroup(model.GroupSourceCustom)
+	unusedLdap := createGroup(model.GroupSourceLdap)
+	linked := createGroup(model.GroupSourceCustom)
+	recentlyChanged := createGroup(model.GroupSourceCustom)
+	removedMember := createGroup(model.GroupSourceCustom)
 
-	// Adding Channel (ChannelMemberHistory) should stop returning result
-	res = <-ss.ChannelMemberHistory().LogJoinEvent(user.Id, channel.Id, model.GetMillis())
-	require.Nil(t, res.Err)
-	res = <-ss.Group().ChannelMembersToAdd(0)
+	res := <-ss.Group().CreateGroupSyncable(model.NewGroupTeam(linked.Id, model.NewId(), false))
 	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 0)
 
-	// Leaving Channel (ChannelMemberHistory) should still not return result
-	res = <-ss.ChannelMemberHistory().LogLeaveEvent(user.Id, channel.Id, model.GetMillis())
-	require.Nil(t, res.Err)
-	res = <-ss.Group().ChannelMembersToAdd(0)
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 0)
+	for _, group := range []*model.Group{unused, removedMember} {
+		res = <-ss.Group().CreateOrRestoreMember(group.Id, user1.Id)
+		require.Nil(t, res.Err)
+	}
 
-	// Purging ChannelMemberHistory re-returns the result
-	res = <-ss.ChannelMemberHistory().PermanentDeleteBatch(model.GetMillis()+1, 100)
+	time.Sleep(5 * time.Millisecond)
+	since := model.GetMillis()
+	time.Sleep(5 * time.Millisecond)
+
+	res = <-ss.Group().CreateOrRestoreMember(recentlyChanged.Id, user2.Id)
 	require.Nil(t, res.Err)
-	res = <-ss.Group().ChannelMembersToAdd(0)
+	res = <-ss.Group().DeleteMember(removedMember.Id, user1.Id)
 	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 1)
-}
 
-func testPendingTeamMemberRemovals(t *testing.T, ss store.Store) {
-	data := pendingMemberRemovalsDataSetup(t, ss)
+	// Created after the window starts
+	createGroup(model.GroupSourceCustom)
 
-	// one result when both users are in the group (for user C)
-	res := <-ss.Group().TeamMembersToRemove()
+	getUnused := func(page, perPage int, opts model.UnusedGroupSearchOpts) []string {
+		res := <-ss.Group().GetUnusedGroups(page, perPage, opts)
+		require.Nil(t, res.Err)
+		ids := []string{}
+		for _, group := range res.Data.([]*model.Group) {
+			ids = append(ids, group.Id)
+		}
+		return ids
+	}
 
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 1)
-	teamMembers := res.Data.([]*model.TeamMember)
-	require.Equal(t, data.UserC.Id, teamMembers[0].UserId)
+	ids := getUnused(0, 1000, model.UnusedGroupSearchOpts{Since: since})
+	require.Contains(t, ids, unused.Id)
+	require.Contains(t, ids, unusedLdap.Id)
+	require.NotContains(t, ids, linked.Id)
+	require.NotContains(t, ids, recentlyChanged.Id)
+	require.NotContains(t, ids, removedMember.Id)
+
+	ids = getUnused(0, 1000, model.UnusedGroupSearchOpts{Source: model.GroupSourceCustom, Since: since})
+	require.Contains(t, ids, unused.Id)
+	require.NotContains(t, ids, unusedLdap.Id)
+
+	// Pages are disjoint
+	all := getUnused(0, 1000, model.UnusedGroupSearchOpts{Since: since})
+	require.True(t, len(all) >= 2)
+	first := getUnused(0, 1, model.UnusedGroupSearchOpts{Since: since})
+	second := getUnused(1, 1, model.UnusedGroupSearchOpts{Since: since})
+	require.Equal(t, all[:1], first)
+	require.Equal(t, all[1:2], second)
+}
 
-	res = <-ss.Group().DeleteMember(data.Group.Id, data.UserB.Id)
-	require.Nil(t, res.Err)
+func testGetGroupsLastUpdateAt(t *testing.T, ss store.Store) {
+	prefix := model.NewId()
 
-	// user b and c should now be returned
-	res = <-ss.Group().TeamMembersToRemove()
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 2)
-	teamMembers = res.Data.([]*model.TeamMember)
+	createGroup := func() *model.Group {
+		res := <-ss.Group().Create(&model.Group{
+			Name:        prefix + model.NewId(),
+			DisplayName: model.NewId(),
+			Source:      model.GroupSourceCustom,
+		})
+		require.Nil(t, res.Err)
+		return res.Data.(*model.Group)
+	}
 
-	var userIDs []string
-	for _, item := range teamMembers {
-		userIDs = append(userIDs, item.UserId)
+	lastUpdateAt := func(q string) int64 {
+		res := <-ss.Group().GetGroupsLastUpdateAt(model.GroupSearchOpts{Q: q})
+		require.Nil(t, res.Err)
+		return res.Data.(int64)
 	}
-	require.Contains(t, userIDs, data.UserB.Id)
-	require.Contains(t, userIDs, data.UserC.Id)
-	require.Equal(t, data.ConstrainedTeam.Id, teamMembers[0].TeamId)
-	require.Equal(t, data.ConstrainedTeam.Id, teamMembers[1].TeamId)
 
-	res = <-ss.Group().DeleteMember(data.Group.Id, data.UserA.Id)
-	require.Nil(t, res.Err)
+	require.Equal(t, int64(0), lastUpdateAt(prefix))
 
-	res = <-ss.Group().TeamMembersToRemove()
-	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 3)
+	group1 := createGroup()
+	time.Sleep(2 * time.Millisecond)
+	group2 := createGroup()
+	require.Equal(t, group2.UpdateAt, lastUpdateAt(prefix))
 
-	// add users back to groups
-	res = <-ss.Team().RemoveMember(data.ConstrainedTeam.Id, data.UserA.Id)
-	require.Nil(t, res.Err)
-	res = <-ss.Team().RemoveMember(data.ConstrainedTeam.Id, data.UserB.Id)
-	require.Nil(t, res.Err)
-	res = <-ss.Team().RemoveMember(data.ConstrainedTeam.Id, data.UserC.Id)
-	require.Nil(t, res.Err)
-	res = <-ss.Channel().RemoveMember(data.ConstrainedChannel.Id, data.UserA.Id)
-	require.Nil(t, res.Err)
-	res = <-ss.Channel().RemoveMember(data.ConstrainedChannel.Id, data.UserB.Id)
+	// Updating a group moves the time forward.
+	time.Sleep(2 * time.Millisecond)
+	group1.DisplayName = model.NewId()
+	res := <-ss.Group().Update(group1)
 	require.Nil(t, res.Err)
-	res = <-ss.Channel().RemoveMember(data.ConstrainedChannel.Id, data.UserC.Id)
+	group1 = res.Data.(*model.Group)
+	require.Equal(t, group1.UpdateAt, lastUpdateAt(prefix))
+
+	// Deleted groups still count, so that a deletion is seen as a change.
+	time.Sleep(2 * time.Millisecond)
+	res = <-ss.Group().Delete(group2.Id)
 	require.Nil(t, res.Err)
+	group2 = res.Data.(*model.Group)
+	require.Equal(t, group2.UpdateAt, lastUpdateAt(prefix))
+
+	// Only groups matching the search are considered.
+	require.Equal(t, group1.UpdateAt, lastUpdateAt(group1.Name))
 }
 
-func testPendingChannelMemberRemovals(t *testing.T, ss store.Store) {
-	data := pendingMemberRemovalsDataSetup(t, ss)
+func testGroupNestedMembers(t *testing.T, ss store.Store) {
+	var groups []*model.Group
+	for i := 0; i < 3; i++ {
+		res := <-ss.Group().Create(&model.Group{
+			Name:        model.NewId(),
+			DisplayName: model.NewId(),
+			Source:      model.GroupSourceCustom,
+			RemoteId:    model.NewId(),
+		})
+		require.Nil(t, res.Err)
+		groups = append(groups, res.Data.(*model.Group))
+	}
 
-	// one result when both users are in the group (for user C)
-	res := <-ss.Group().ChannelMembersToRemove()
+	// groups[2] is nested beneath groups[1], which is nested beneath groups[0]
+	for i := 1; i < 3; i++ {
+		groups[i].ParentGroupId = groups[i-1].Id
+		res := <-ss.Group().Update(groups[i])
+		require.Nil(t, res.Err)
+	}
 
+	var userIds []string
+	for i := 0; i < 3; i++ {
+		res := <-ss.User().Save(&model.User{Email: MakeEmail(), Username: model.NewId()})
+		require.Nil(t, res.Err)
+		userIds = append(userIds, res.Data.(*model.User).Id)
+	}
+
+	// Each group has its own member, and the first user is in every group
+	for i, group := range groups {
+		res := <-ss.Group().UpsertMembers(group.Id, []string{userIds[0], userIds[i]})
+		require.Nil(t, res.Err)
+	}
+
+	res := <-ss.Group().GetChildGroupIds([]string{groups[0].Id})
 	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 1)
-	channelMembers := res.Data.([]*model.ChannelMember)
-	require.Equal(t, data.UserC.Id, channelMembers[0].UserId)
+	require.Equal(t, []string{groups[1].Id}, res.Data.([]string))
 
-	res = <-ss.Group().DeleteMember(data.Group.Id, data.UserB.Id)
+	res = <-ss.Group().GetChildGroupIds([]string{groups[0].Id, groups[1].Id})
 	require.Nil(t, res.Err)
+	require.ElementsMatch(t, []string{groups[1].Id, groups[2].Id}, res.Data.([]string))
 
-	// user b and c should now be returned
-	res = <-ss.Group().ChannelMembersToRemove()
+	res = <-ss.Group().GetChildGroupIds([]string{})
 	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 2)
-	channelMembers = res.Data.([]*model.ChannelMember)
+	require.Empty(t, res.Data.([]string))
 
-	var userIDs []string
-	for _, item := range channelMembers {
-		userIDs = append(userIDs, item.UserId)
-	}
-	require.Contains(t, userIDs, data.UserB.Id)
-	require.Contains(t, userIDs, data.UserC.Id)
-	require.Equal(t, data.ConstrainedChannel.Id, channelMembers[0].ChannelId)
-	require.Equal(t, data.ConstrainedChannel.Id, channelMembers[1].ChannelId)
+	allGroupIds := []string{groups[0].Id, groups[1].Id, groups[2].Id}
 
-	res = <-ss.Group().DeleteMember(data.Group.Id, data.UserA.Id)
+	// Users in several of the groups are listed and counted once
+	res = <-ss.Group().GetNestedMemberUsersPage(allGroupIds, 0, 100)
 	require.Nil(t, res.Err)
+	var listed []string
+	for _, user := range res.Data.([]*model.User) {
+		listed = append(listed, user.Id)
+	}
+	require.ElementsMatch(t, userIds, listed)
 
-	res = <-ss.Group().ChannelMembersToRemove()
+	res = <-ss.Group().GetNestedMemberCount(allGroupIds)
 	require.Nil(t, res.Err)
-	require.Len(t, res.Data, 3)
+	require.Equal(t, int64(3), res.Data.(int64))
 
-	// add users back to groups
-	res = <-ss.Team().RemoveMember(data.ConstrainedTeam.Id, data.UserA.Id)
-	require.Nil(t, res.Err)
-	res = <-ss.Team().RemoveMember(data.ConstrainedTeam.Id, data.UserB.Id)
-	require.Nil(t, res.Err)
-	res = <-ss.Team().RemoveMember(data.ConstrainedTeam.Id, data.UserC.Id)
+	res = <-ss.Group().GetNestedMemberUsersPage(allGroupIds, 1, 2)
 	require.Nil(t, res.Err)
-	res = <-ss.Channel().RemoveMember(data.ConstrainedChannel.Id, data.UserA.Id)
+	require.Len(t, res.Data.([]*model.User), 1)
+
+	// Removed members are left out
+	res = <-ss.Group().DeleteMember(groups[2].Id, userIds[2])
 	require.Nil(t, res.Err)
-	res = <-ss.Channel().RemoveMember(data.ConstrainedChannel.Id, data.UserB.Id)
+
+	res = <-ss.Group().GetNestedMemberCount(allGroupIds)
 	require.Nil(t, res.Err)
-	res = <-ss.Channel().RemoveMember(data.ConstrainedChannel.Id, data.UserC.Id)
+	require.Equal(t, int64(2), res.Data.(int64))
+
+	// Deleted groups are not children
+	res = <-ss.Group().Delete(groups[2].Id)
 	require.Nil(t, res.Err)
-}
 
-type removalsData struct {
-	UserA                *model.User
-	UserB                *model.User
-	UserC                *model.User
-	ConstrainedChannel   *model.Channel
-	UnconstrainedChannel *model.Channel
-	ConstrainedTeam      *model.Team
-	UnconstrainedTeam    *model.Team
-	Group                *model.Group
+	res = <-ss.Group().GetChildGroupIds([]string{groups[1].Id})
+	require.Nil(t, res.Err)
+	require.Empty(t, res.Data.([]string))
 }
 
-func pendingMemberRemovalsDataSetup(t *testing.T, ss store.Store) *removalsData {
-	// create group
+func testGroupChannelPatterns(t *testing.T, ss store.Store) {
 	res := <-ss.Group().Create(&model.Group{
 		Name:        model.NewId(),
-		DisplayName: "Pending[Channel|Team]MemberRemovals Test Group",
-		RemoteId:    model.NewId(),
+		DisplayName: model.NewId(),
 		Source:      model.GroupSourceLdap,
+		RemoteId:    model.NewId(),
 	})
 	require.Nil(t, res.Err)
 	group := res.Data.(*model.Group)
 
-	// create users
-	// userA will get removed from the group
-	userA := &model.User{
-		Email:    MakeEmail(),
-		Username: model.NewId(),
-	}
-	res = <-ss.User().Save(userA)
+	res = <-ss.Group().CreateChannelPattern(&model.GroupChannelPattern{GroupId: group.Id, Pattern: "team-*", AutoAdd: true})
 	require.Nil(t, res.Err)
-	userA = res.Data.(*model.User)
+	pattern := res.Data.(*model.GroupChannelPattern)
+	require.NotZero(t, pattern.CreateAt)
 
-	// userB will not get removed from the group
-	userB := &model.User{
-		Email:    MakeEmail(),
-		Username: model.NewId(),
-	}
-	res = <-ss.User().Save(userB)
+	res = <-ss.Group().CreateChannelPattern(&model.GroupChannelPattern{GroupId: group.Id, Pattern: "team-*"})
+	require.NotNil(t, res.Err)
+	require.Equal(t, "store.sql_group.channel_pattern_exists", res.Err.Id)
+
+	res = <-ss.Group().CreateChannelPattern(&model.GroupChannelPattern{GroupId: group.Id, Pattern: "team-["})
+	require.NotNil(t, res.Err)
+
+	res = <-ss.Group().GetChannelPatterns(group.Id)
 	require.Nil(t, res.Err)
-	userB = res.Data.(*model.User)
+	require.Equal(t, []*model.GroupChannelPattern{pattern}, res.Data.([]*model.GroupChannelPattern))
 
-	// userC was never in the group
-	userC := &model.User{
-		Email:    MakeEmail(),
-		Username: model.NewId(),
-	}
-	res = <-ss.User().Save(userC)
+	res = <-ss.Group().GetAllChannelPatterns()
 	require.Nil(t, res.Err)
-	userC = res.Data.(*model.User)
+	require.Contains(t, res.Data.([]*model.GroupChannelPattern), pattern)
 
-	// add users to group (but not userC)
-	res = <-ss.Group().CreateOrRestoreMember(group.Id, userA.Id)
+	// Channels are filtered by prefix, creation time and existing links
+	teamId := model.NewId()
+	prefix := "team-" + model.NewId()[:8]
+	var channels []*model.Channel
+	for _, name := range []string{prefix + "-a", prefix + "-b", "other-" + model.NewId()} {
+		res = <-ss.Channel().Save(&model.Channel{
+			TeamId:      teamId,
+			DisplayName: name,
+			Name:        name,
+			Type:        model.CHANNEL_OPEN,
+		}, 9999)
+		require.Nil(t, res.Err)
+		channels = append(channels, res.Data.(*model.Channel))
+	}
+
+	unlinkedIds := func(namePrefix string, since int64) []string {
+		res := <-ss.Group().GetUnlinkedChannelsCreatedSince(group.Id, namePrefix, since)
+		require.Nil(t, res.Err)
+		var ids []string
+		for _, channel := range res.Data.([]*model.Channel) {
+			ids = append(ids, channel.Id)
+		}
+		return ids
+	}
+
+	require.ElementsMatch(t, []string{channels[0].Id, channels[1].Id}, unlinkedIds(prefix, 0))
+	require.Empty(t, unlinkedIds(prefix, channels[1].CreateAt+1))
+
+	res = <-ss.Group().CreateGroupSyncable(model.NewGroupChannel(group.Id, channels[0].Id, false))
+	require.Nil(t, res.Err)
+	res = <-ss.Group().DeleteGroupSyncable(group.Id, channels[0].Id, model.GroupSyncableTypeChannel)
 	require.Nil(t, res.Err)
 
-	res = <-ss.Group().CreateOrRestoreMember(group.Id, userB.Id)
+	// A removed link still counts as linked
+	require.Equal(t, []string{channels[1].Id}, unlinkedIds(prefix, 0))
+
+	// Deleted groups have no patterns
+	res = <-ss.Group().Delete(group.Id)
 	require.Nil(t, res.Err)
 
-	// create channels
-	channelConstrained := &model.Channel{
-		TeamId:           model.NewId(),
-		DisplayName:      "A Name",
-		Name:             model.NewId(),
-		Type:             model.CHANNEL_PRIVATE,
-		GroupConstrained: model.NewBool(true),
-	}
-	res = <-ss.Channel().Save(channelConstrained, 9999)
+	res = <-ss.Group().GetAllChannelPatterns()
 	require.Nil(t, res.Err)
-	channelConstrained = res.Data.(*model.Channel)
+	require.NotContains(t, res.Data.([]*model.GroupChannelPattern), pattern)
+}
 
-	channelUnconstrained := &model.Channel{
-		TeamId:      model.NewId(),
-		DisplayName: "A Name",
+func testGroupMembersNotInChannel(t *testing.T, ss store.Store) {
+	res := <-ss.Group().Create(&model.Group{
 		Name:        model.NewId(),
-		Type:        model.CHANNEL_PRIVATE,
-	}
-	res = <-ss.Channel().Save(channelUnconstrained, 9999)
+		DisplayName: model.NewId(),
+		Source:      model.GroupSourceCustom,
+		RemoteId:    model.NewId(),
+	})
 	require.Nil(t, res.Err)
-	channelUnconstrained = res.Data.(*model.Channel)
+	group := res.Data.(*model.Group)
 
-	// create teams
-	teamConstrained := &model.Team{
-		DisplayName:      "Name",
-		Description:      "Some description",
-		CompanyName:      "Some company name",
-		AllowOpenInvite:  false,
-		InviteId:         "inviteid0",
-		Name:             "z-z-" + model.NewId() + "a",
-		Email:            "success+" + model.NewId() + "@simulator.amazonses.com",
-		Type:             model.TEAM_INVITE,
-		GroupConstrained: model.NewBool(true),
-	}
-	teamConstrained, err := ss.Team().Save(teamConstrained)
-	require.Nil(t, err)
+	channelId := model.NewId()
 
-	teamUnconstrained := &model.Team{
-		DisplayName:     "Name",
-		Description:     "Some description",
-		CompanyName:     "Some company name",
-		AllowOpenInvite: false,
-		InviteId:        "inviteid1",
-		Name:            "z-z-" + model.NewId() + "a",
-		Email:           "success+" + model.NewId() + "@simulator.amazonses.com",
-		Type:            model.TEAM_INVITE,
+	var userIds []string
+	for i := 0; i < 4; i++ {
+		res = <-ss.User().Save(&model.User{Email: MakeEmail(), Username: model.NewId()})
+		require.Nil(t, res.Err)
+		userIds = append(userIds, res.Data.(*model.User).Id)
 	}
-	teamUnconstrained, err = ss.Team().Save(teamUnconstrained)
-	require.Nil(t, err)
 
-	// create groupteams
-	res = <-ss.Group().CreateGroupSyncable(model.NewGroupTeam(group.Id, teamConstrained.Id, true))
+	// Every user is in the group and the last two in the channel
+	res = <-ss.Group().UpsertMembers(group.Id, userIds)
 	require.Nil(t, res.Err)
+	for _, userId := range userIds[2:] {
+		res = <-ss.Channel().SaveMember(&model.ChannelMember{
+			UserId:      userId,
+			ChannelId:   channelId,
+			NotifyProps: model.GetDefaultChannelNotifyProps(),
+		})
+		require.Nil(t, res.Err)
+	}
 
-	res = <-ss.Group().CreateGroupSyncable(model.NewGroupTeam(group.Id, teamUnconstrained.Id, true))
+	res = <-ss.Group().GetMembersNotInChannel(group.Id, channelId)
 	require.Nil(t, res.Err)
+	require.ElementsMatch(t, userIds[:2], res.Data.([]string))
 
-	// create groupchannels
-	res = <-ss.Group().CreateGroupSyncable(model.NewGroupChannel(group.Id, channelConstrained.Id, true))
+	// Removed members are left out
+	res = <-ss.Group().DeleteMember(group.Id, userIds[0])
 	require.Nil(t, res.Err)
 
-	res = <-ss.Group().CreateGroupSyncable(model.NewGroupChannel(group.Id, channelUnconstrained.Id, true))
+	res = <-ss.Group().GetMembersNotInChannel(group.Id, channelId)
 	require.Nil(t, res.Err)
+	require.Equal(t, userIds[1:2], res.Data.([]string))
+}
 
-	// add users to teams
-	userIDTeamIDs := [][]string{
-		{userA.Id, teamConstrained.Id},
-		{userB.Id, teamConstrained.Id},
-		{userC.Id, teamConstrained.Id},
-		{userA.Id, teamUnconstrained.Id},
-		{userB.Id, teamUnconstrained.Id},
-		{userC.Id, teamUnconstrained.Id},
-	}
+func testGroupMemberCountInChannel(t *testing.T, ss store.Store) {
+	res := <-ss.Group().Create(&model.Group{
+		Name:        model.NewId(),
+		DisplayName: model.NewId(),
+		Source:      model.GroupSourceCustom,
+		RemoteId:    model.NewId(),
+	})
+	require.Nil(t, res.Err)
+	group := res.Data.(*model.Group)
 
-	for _, item := range userIDTeamIDs {
-		res = <-ss.Team().SaveMember(&model.TeamMember{
-			UserId: item[0],
-			TeamId: item[1],
-		}, 99)
-		require.Nil(t, res.Err)
-	}
+	res = <-ss.Channel().Save(&model.Channel{
+		TeamId:      model.NewId(),
+		DisplayName: "A Name",
+		Name:        model.NewId(),
+		Type:        model.CHANNEL_OPEN,
+	}, 9999)
+	require.Nil(t, res.Err)
+	channel := res.Data.(*model.Channel)
 
-	// add users to channels
-	userIDChannelIDs := [][]string{
-		{userA.Id, channelConstrained.Id},
-		{userB.Id, channelConstrained.Id},
-		{userC.Id, channelConstrained.Id},
-		{userA.Id, channelUnconstrained.Id},
-		{userB.Id, channelUnconstrained.Id},
-		{userC.Id, channelUnconstrained.Id},
+	var userIds []string
+	for i := 0; i < 4; i++ {
+		res = <-ss.User().Save(&model.User{Email: MakeEmail(), Username: model.NewId()})
+		require.Nil(t, res.Err)
+		userIds = append(userIds, res.Data.(*model.User).Id)
 	}
 
-	for _, item := range userIDChannelIDs {
+	// The first three users are in the group and the last three in the channel
+	res = <-ss.Group().UpsertMembers(group.Id, userIds[:3])
+	require.Nil(t, res.Err)
+	for _, userId := range userIds[1:] {
 		res = <-ss.Channel().SaveMember(&model.ChannelMember{
-			UserId:      item[0],
-			ChannelId:   item[1],
+			UserId:      userId,
+			ChannelId:   channel.Id,
 			NotifyProps: model.GetDefaultChannelNotifyProps(),
 		})
 		require.Nil(t, res.Err)
 	}
 
-	return &removalsData{
-		UserA:                userA,
-		UserB:                userB,
-		UserC:                userC,
-		ConstrainedChannel:   channelConstrained,
-		UnconstrainedChannel: channelUnconstrained,
-		ConstrainedTeam:      teamConstrained,
-		UnconstrainedTeam:    teamUnconstrained,
-		Group:                group,
-	}
-}
-
-func testGetGroupsByChannel(t *testing.T, ss store.Store) {
-	// Create Channel1
-	channel1 := &model.Channel{
-		TeamId:      model.NewId(),
-		DisplayName: "Channel1",
-		Name:        model.NewId(),
-		Type:        model.CHANNEL_OPEN,
-	}
-	res := <-ss.Channel().Save(channel1, 9999)
+	res = <-ss.Group().GetMemberCountInChannel(group.Id, channel.Id)
 	require.Nil(t, res.Err)
-	channel1 = res.Data.(*model.Channel)
+	require.Equal(t, int64(2), res.Data.(int64))
 
-	// Create Groups 1 and 2
-	res = <-ss.Group().Create(&model.Group{
-		Name:        model.NewId(),
-		DisplayName: "group-1",
-		RemoteId:    model.NewId(),
-		Source:      model.GroupSourceLdap,
-	})
+	// Removed members are not counted
+	res = <-ss.Group().DeleteMember(group.Id, userIds[1])
 	require.Nil(t, res.Err)
-	group1 := res.Data.(*model.Group)
 
-	res = <-ss.Group().Create(&model.Group{
-		Name:        model.NewId(),
-		DisplayName: "group-2",
-		RemoteId:    model.NewId(),
-		Source:      model.GroupSourceLdap,
-	})
+	res = <-ss.Group().GetMemberCountInChannel(group.Id, channel.Id)
 	require.Nil(t, res.Err)
-	group2 := res.Data.(*model.Group)
+	require.Equal(t, int64(1), res.Data.(int64))
+}
 
-	// And associate them with Channel1
-	for _, g := range []*model.Group{group1, group2} {
-		res = <-ss.Group().CreateGroupSyncable(&model.GroupSyncable{
-			AutoAdd:    true,
-			SyncableId: channel1.Id,
-			Type:       model.GroupSyncableTypeChannel,
-			GroupId:    g.Id,
+func createExcludeGroupsChannel(t *testing.T, ss store.Store) (include, exclude *model.Group, channel *model.Channel, userIds []string) {
+	createGroup := func() *model.Group {
+		res := <-ss.Group().Create(&model.Group{
+			Name:        model.NewId(),
+			DisplayName: model.NewId(),
+			Source:      model.GroupSourceCustom,
+			RemoteId:    model.NewId(),
 		})
 		require.Nil(t, res.Err)
+		return res.Data.(*model.Group)
 	}
+	include = createGroup()
+	exclude = createGroup()
 
-	// Create Channel2
-	channel2 := &model.Channel{
+	res := <-ss.Channel().Save(&model.Channel{
 		TeamId:      model.NewId(),
-		DisplayName: "Channel2",
+		DisplayName: "A Name",
 		Name:        model.NewId(),
 		Type:        model.CHANNEL_OPEN,
-	}
-	res = <-ss.Channel().Save(channel2, 9999)
+	}, 9999)
 	require.Nil(t, res.Err)
-	channel2 = res.Data.(*model.Channel)
+	channel = res.Data.(*model.Channel)
 
-	// Create Group3
-	res = <-ss.Group().Create(&model.Group{
-		Name:        model.NewId(),
-		DisplayName: "group-3",
-		RemoteId:    model.NewId(),
-		Source:      model.GroupSourceLdap,
-	})
+	res = <-ss.Group().CreateGroupSyncable(model.NewGroupChannel(include.Id, channel.Id, true))
 	require.Nil(t, res.Err)
-	group3 := res.Data.(*model.Group)
 
-	// And associate it to Channel2
-	res = <-ss.Group().CreateGroupSyncable(&model.GroupSyncable{
-		AutoAdd:    true,
-		SyncableId: channel2.Id,
-		Type:       model.GroupSyncableTypeChannel,
-		GroupId:    group3.Id,
-	})
+	excludeSyncable := model.NewGroupChannel(exclude.Id, channel.Id, false)
+	excludeSyncable.ExcludeMembers = true
+	res = <-ss.Group().CreateGroupSyncable(excludeSyncable)
 	require.Nil(t, res.Err)
 
-	testCases := []struct {
-		Name      string
-		ChannelId string
-		Page      int
-		PerPage   int
-		Result    []*model.Group
-	}{
-		{
-			Name:      "Get the two Groups for Channel1",
-			ChannelId: channel1.Id,
-			Page:      0,
-			PerPage:   60,
-			Result:    []*model.Group{group1, group2},
-		},
-		{
-			Name:      "Get first Group for Channel1 with page 0 with 1 element",
-			ChannelId: channel1.Id,
-			Page:      0,
-			PerPage:   1,
-			Result:    []*model.Group{group1},
-		},
-		{
-			Name:      "Get second Group for Channel1 with page 1 with 1 element",
-			ChannelId: channel1.Id,
-			Page:      1,
-			PerPage:   1,
-			Result:    []*model.Group{group2},
-		},
-		{
-			Name:      "Get third Group for Channel2",
-			ChannelId: channel2.Id,
-			Page:      0,
-			PerPage:   60,
-			Result:    []*model.Group{group3},
-		},
-		{
-			Name:      "Get empty Groups for a fake id",
-			ChannelId: model.NewId(),
-			Page:      0,
-			PerPage:   60,
-			Result:    []*model.Group{},
-		},
+	for i := 0; i < 4; i++ {
+		res = <-ss.User().Save(&model.User{Email: MakeEmail(), Username: model.NewId()})
+		require.Nil(t, res.Err)
+		userIds = append(userIds, res.Data.(*model.User).Id)
 	}
 
-	for _, tc := range testCases {
-		t.Run(tc.Name, func(t *testing.T) {
-			res := <-ss.Group().GetGroupsByChannel(tc.ChannelId, tc.Page, tc.PerPage)
-			require.Nil(t, res.Err)
-			require.ElementsMatch(t, tc.Result, res.Data.([]*model.Group))
+	res = <-ss.Group().UpsertMembers(include.Id, []string{userIds[0], userIds[1], userIds[3]})
+	require.Nil(t, res.Err)
+	res = <-ss.Group().UpsertMembers(exclude.Id, []string{userIds[0], userIds[2], userIds[3]})
+	require.Nil(t, res.Err)
+
+	for _, userId := range userIds[:3] {
+		res = <-ss.Channel().SaveMember(&model.ChannelMember{
+			UserId:      userId,
+			ChannelId:   channel.Id,
+			NotifyProps: model.GetDefaultChannelNotifyProps(),
 		})
+		require.Nil(t, res.Err)
 	}
+
+	return include, exclude, channel, userIds
 }
 
-func testGetGroupsByTeam(t *testing.T, ss store.Store) {
-	// Create Team1
-	team1 := &model.Team{
-		DisplayName:     "Team1",
-		Description:     model.NewId(),
-		CompanyName:     model.NewId(),
-		AllowOpenInvite: false,
-		InviteId:        model.NewId(),
-		Name:            model.NewId(),
-		Email:           "success+" + model.NewId() + "@simulator.amazonses.com",
-		Type:            model.TEAM_OPEN,
+func testPendingAutoAddChannelMembersExcluded(t *testing.T, ss store.Store) {
+	include, exclude, channel, userIds := createExcludeGroupsChannel(t, ss)
+
+	channelUserIds := func() []string {
+		res := <-ss.Group().ChannelMembersToAdd(0)
+		require.Nil(t, res.Err)
+		var ids []string
+		for _, pair := range res.Data.([]*model.UserChannelIDPair) {
+			if pair.ChannelID == channel.Id {
+				ids = append(ids, pair.UserID)
+			}
+		}
+		return ids
 	}
-	team1, err := ss.Team().Save(team1)
-	require.Nil(t, err)
 
-	// Create Groups 1 and 2
-	res := <-ss.Group().Create(&model.Group{
-		Name:        model.NewId(),
-		DisplayName: "group-1",
-		RemoteId:    model.NewId(),
-		Source:      model.GroupSourceLdap,
-	})
+	// Exclusion wins over the auto-add link
+	require.Equal(t, []string{userIds[1]}, channelUserIds())
+
+	// Once the user leaves the excluding group, the auto-add link applies again
+	res := <-ss.Group().DeleteMember(exclude.Id, userIds[3])
 	require.Nil(t, res.Err)
-	group1 := res.Data.(*model.Group)
+	require.ElementsMatch(t, []string{userIds[1], userIds[3]}, channelUserIds())
 
-	res = <-ss.Group().Create(&model.Group{
-		Name:        model.NewId(),
-		DisplayName: "group-2",
-		RemoteId:    model.NewId(),
-		Source:      model.GroupSourceLdap,
-	})
+	// A removed excluding link no longer excludes
+	res = <-ss.Group().DeleteGroupSyncable(exclude.Id, channel.Id, model.GroupSyncableTypeChannel)
 	require.Nil(t, res.Err)
-	group2 := res.Data.(*model.Group)
+	require.ElementsMatch(t, []string{userIds[0], userIds[1], userIds[3]}, channelUserIds())
 
-	// And associate them with Team1
-	for _, g := range []*model.Group{group1, group2} {
-		res = <-ss.Group().CreateGroupSyncable(&model.GroupSyncable{
-			AutoAdd:    true,
-			SyncableId: team1.Id,
-			Type:       model.GroupSyncableTypeTeam,
-			GroupId:    g.Id,
-		})
-		require.Nil(t, res.Err)
-	}
+	res = <-ss.Group().DeleteGroupSyncable(include.Id, channel.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, res.Err)
+	require.Empty(t, channelUserIds())
+}
 
-	// Create Team2
-	team2 := &model.Team{
-		DisplayName:     "Team2",
-		Description:     model.NewId(),
-		CompanyName:     model.NewId(),
-		AllowOpenInvite: false,
-		InviteId:        model.NewId(),
-		Name:            model.NewId(),
-		Email:           "success+" + model.NewId() + "@simulator.amazonses.com",
-		Type:            model.TEAM_INVITE,
+func testGetChannelMembershipConflicts(t *testing.T, ss store.Store) {
+	include, exclude, channel, userIds := createExcludeGroupsChannel(t, ss)
+
+	res := <-ss.Group().GetChannelMembershipConflicts(channel.Id)
+	require.Nil(t, res.Err)
+	conflicts := res.Data.([]*model.GroupMembershipConflict)
+
+	// Only the channel members in the excluding group conflict, whether or not another group includes them
+	expected := map[string]*model.GroupMembershipConflict{
+		userIds[0]: {UserId: userIds[0], ExcludedByGroupIds: []string{exclude.Id}, IncludedByGroupIds: []string{include.Id}},
+		userIds[2]: {UserId: userIds[2], ExcludedByGroupIds: []string{exclude.Id}, IncludedByGroupIds: []string{}},
+	}
+	require.Len(t, conflicts, len(expected))
+	for _, conflict := range conflicts {
+		require.Equal(t, expected[conflict.UserId], conflict)
 	}
-	team2, err = ss.Team().Save(team2)
-	require.Nil(t, err)
 
-	// Create Group3
-	res = <-ss.Group().Create(&model.Group{
-		Name:        model.NewId(),
-		DisplayName: "group-3",
-		RemoteId:    model.NewId(),
-		Source:      model.GroupSourceLdap,
-	})
+	// Removed members of the excluding group no longer conflict
+	res = <-ss.Group().DeleteMember(exclude.Id, userIds[0])
 	require.Nil(t, res.Err)
-	group3 := res.Data.(*model.Group)
 
-	// And associate it to Team2
-	res = <-ss.Group().CreateGroupSyncable(&model.GroupSyncable{
-		AutoAdd:    true,
-		SyncableId: team2.Id,
-		Type:       model.GroupSyncableTypeTeam,
-		GroupId:    group3.Id,
-	})
+	res = <-ss.Group().GetChannelMembershipConflicts(channel.Id)
 	require.Nil(t, res.Err)
+	conflicts = res.Data.([]*model.GroupMembershipConflict)
+	require.Len(t, conflicts, 1)
+	require.Equal(t, userIds[2], conflicts[0].UserId)
 
-	testCases := []struct {
-		Name    string
-		TeamId  string
-		Page    int
-		PerPage int
-		Result  []*model.Group
-	}{
-		{
-			Name:    "Get the two Groups for Team1",
-			TeamId:  team1.Id,
-			Page:    0,
-			PerPage: 60,
-			Result:  []*model.Group{group1, group2},
-		},
-		{
-			Name:    "Get first Group for Team1 with page 0 with 1 element",
-			TeamId:  team1.Id,
-			Page:    0,
-			PerPage: 1,
-			Result:  []*model.Group{group1},
-		},
-		{
-			Name:    "Get second Group for Team1 with page 1 with 1 element",
-			TeamId:  team1.Id,
-			Page:    1,
-			PerPage: 1,
-			Result:  []*model.Group{group2},
-		},
-		{
-			Name:    "Get third Group for Team2",
-			TeamId:  team2.Id,
-			Page:    0,
-			PerPage: 60,
-			Result:  []*model.Group{group3},
-		},
-		{
-			Name:    "Get empty Groups for a fake id",
-			TeamId:  model.NewId(),
-			Page:    0,
-			PerPage: 60,
-			Result:  []*model.Group{},
-		},
-	}
+	// Nor does anyone once the excluding link is removed
+	res = <-ss.Group().DeleteGroupSyncable(exclude.Id, channel.Id, model.GroupSyncableTypeChannel)
+	require.Nil(t, res.Err)
 
-	for _, tc := range testCases {
-		t.Run(tc.Name, func(t *testing.T) {
-			res := <-ss.Group().GetGroupsByTeam(tc.TeamId, tc.Page, tc.PerPage)
-			require.Nil(t, res.Err)
-			require.ElementsMatch(t, tc.Result, res.Data.([]*model.Group))
-		})
-	}
+	res = <-ss.Group().GetChannelMembershipConflicts(channel.Id)
+	require.Nil(t, res.Err)
+	require.Empty(t, res.Data.([]*model.GroupMembershipConflict))
 }
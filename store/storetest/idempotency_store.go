@@ -0,0 +1,117 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package storetest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+)
+
+func TestIdempotencyStore(t *testing.T, ss store.Store) {
+	t.Run("Reserve", func(t *testing.T) { testIdempotencyStoreReserve(t, ss) })
+	t.Run("Save", func(t *testing.T) { testIdempotencyStoreSave(t, ss) })
+	t.Run("Delete", func(t *testing.T) { testIdempotencyStoreDelete(t, ss) })
+	t.Run("Cleanup", func(t *testing.T) { testIdempotencyStoreCleanup(t, ss) })
+}
+
+func testIdempotencyStoreReserve(t *testing.T, ss store.Store) {
+	t.Run("should reserve a new key", func(t *testing.T) {
+		key := model.NewId()
+
+		result := <-ss.Idempotency().Reserve(key, &model.IdempotentResponse{RequestHash: "hash", Pending: true}, model.GetMillis()+60000)
+		require.Nil(t, result.Err)
+		assert.Nil(t, result.Data)
+	})
+
+	t.Run("should return the pending response of a reserved key", func(t *testing.T) {
+		key := model.NewId()
+
+		result := <-ss.Idempotency().Reserve(key, &model.IdempotentResponse{RequestHash: "hash", Pending: true}, model.GetMillis()+60000)
+		require.Nil(t, result.Err)
+
+		result = <-ss.Idempotency().Reserve(key, &model.IdempotentResponse{RequestHash: "other", Pending: true}, model.GetMillis()+60000)
+		require.Nil(t, result.Err)
+		require.IsType(t, &model.IdempotentResponse{}, result.Data)
+		response := result.Data.(*model.IdempotentResponse)
+		assert.Equal(t, "hash", response.RequestHash)
+		assert.True(t, response.Pending)
+	})
+
+	t.Run("should replace an expired key", func(t *testing.T) {
+		key := model.NewId()
+
+		result := <-ss.Idempotency().Reserve(key, &model.IdempotentResponse{RequestHash: "hash", Pending: true}, model.GetMillis()-1000)
+		require.Nil(t, result.Err)
+
+		result = <-ss.Idempotency().Reserve(key, &model.IdempotentResponse{RequestHash: "other", Pending: true}, model.GetMillis()+60000)
+		require.Nil(t, result.Err)
+		assert.Nil(t, result.Data)
+
+		result = <-ss.Idempotency().Reserve(key, &model.IdempotentResponse{RequestHash: "hash", Pending: true}, model.GetMillis()+60000)
+		require.Nil(t, result.Err)
+		require.NotNil(t, result.Data)
+		assert.Equal(t, "other", result.Data.(*model.IdempotentResponse).RequestHash)
+	})
+}
+
+func testIdempotencyStoreSave(t *testing.T, ss store.Store) {
+	key := model.NewId()
+
+	result := <-ss.Idempotency().Reserve(key, &model.IdempotentResponse{RequestHash: "hash", Pending: true}, model.GetMillis()+60000)
+	require.Nil(t, result.Err)
+
+	saved := &model.IdempotentResponse{
+		RequestHash: "hash",
+		StatusCode:  http.StatusCreated,
+		Header:      http.Header{"Content-Type": []string{"application/json"}},
+		Body:        []byte(`{"id":"group"}`),
+	}
+	result = <-ss.Idempotency().Save(key, saved, model.GetMillis()+300000)
+	require.Nil(t, result.Err)
+
+	result = <-ss.Idempotency().Reserve(key, &model.IdempotentResponse{RequestHash: "hash", Pending: true}, model.GetMillis()+60000)
+	require.Nil(t, result.Err)
+	require.NotNil(t, result.Data)
+	assert.Equal(t, saved, result.Data.(*model.IdempotentResponse))
+}
+
+func testIdempotencyStoreDelete(t *testing.T, ss store.Store) {
+	key := model.NewId()
+
+	result := <-ss.Idempotency().Reserve(key, &model.IdempotentResponse{RequestHash: "hash", Pending: true}, model.GetMillis()+60000)
+	require.Nil(t, result.Err)
+
+	result = <-ss.Idempotency().Delete(key)
+	require.Nil(t, result.Err)
+
+	result = <-ss.Idempotency().Reserve(key, &model.IdempotentResponse{RequestHash: "hash", Pending: true}, model.GetMillis()+60000)
+	require.Nil(t, result.Err)
+	assert.Nil(t, result.Data)
+}
+
+func testIdempotencyStoreCleanup(t *testing.T, ss store.Store) {
+	expiredKey := model.NewId()
+	result := <-ss.Idempotency().Reserve(expiredKey, &model.IdempotentResponse{RequestHash: "hash", Pending: true}, model.GetMillis()-1000)
+	require.Nil(t, result.Err)
+
+	activeKey := model.NewId()
+	result = <-ss.Idempotency().Reserve(activeKey, &model.IdempotentResponse{RequestHash: "hash", Pending: true}, model.GetMillis()+60000)
+	require.Nil(t, result.Err)
+
+	ss.Idempotency().Cleanup()
+
+	result = <-ss.Idempotency().Reserve(expiredKey, &model.IdempotentResponse{RequestHash: "hash", Pending: true}, model.GetMillis()+60000)
+	require.Nil(t, result.Err)
+	assert.Nil(t, result.Data)
+
+	result = <-ss.Idempotency().Reserve(activeKey, &model.IdempotentResponse{RequestHash: "hash", Pending: true}, model.GetMillis()+60000)
+	require.Nil(t, result.Err)
+	assert.NotNil(t, result.Data)
+}
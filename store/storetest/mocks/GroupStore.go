@@ -13,6 +13,22 @@ type GroupStore struct {
 	mock.Mock
 }
 
+// Autocomplete provides a mock function with given fields: namePrefix, limit
+func (_m *GroupStore) Autocomplete(namePrefix string, limit int) store.StoreChannel {
+	ret := _m.Called(namePrefix, limit)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, int) store.StoreChannel); ok {
+		r0 = rf(namePrefix, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // ChannelMembersToAdd provides a mock function with given fields: since
 func (_m *GroupStore) ChannelMembersToAdd(since int64) store.StoreChannel {
 	ret := _m.Called(since)
@@ -61,6 +77,38 @@ func (_m *GroupStore) Create(group *model.Group) store.StoreChannel {
 	return r0
 }
 
+// CreateChannelExemption provides a mock function with given fields: exemption
+func (_m *GroupStore) CreateChannelExemption(exemption *model.GroupChannelExemption) store.StoreChannel {
+	ret := _m.Called(exemption)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(*model.GroupChannelExemption) store.StoreChannel); ok {
+		r0 = rf(exemption)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// CreateChannelPattern provides a mock function with given fields: pattern
+func (_m *GroupStore) CreateChannelPattern(pattern *model.GroupChannelPattern) store.StoreChannel {
+	ret := _m.Called(pattern)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(*model.GroupChannelPattern) store.StoreChannel); ok {
+		r0 = rf(pattern)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // CreateGroupSyncable provides a mock function with given fields: groupSyncable
 func (_m *GroupStore) CreateGroupSyncable(groupSyncable *model.GroupSyncable) store.StoreChannel {
 	ret := _m.Called(groupSyncable)
@@ -109,6 +157,22 @@ func (_m *GroupStore) Delete(groupID string) store.StoreChannel {
 	return r0
 }
 
+// DeleteAllMembers provides a mock function with given fields: groupID
+func (_m *GroupStore) DeleteAllMembers(groupID string) store.StoreChannel {
+	ret := _m.Called(groupID)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string) store.StoreChannel); ok {
+		r0 = rf(groupID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // DeleteGroupSyncable provides a mock function with given fields: groupID, syncableID, syncableType
 func (_m *GroupStore) DeleteGroupSyncable(groupID string, syncableID string, syncableType model.GroupSyncableType) store.StoreChannel {
 	ret := _m.Called(groupID, syncableID, syncableType)
@@ -141,6 +205,22 @@ func (_m *GroupStore) DeleteMember(groupID string, userID string) store.StoreCha
 	return r0
 }
 
+// DeleteMembers provides a mock function with given fields: groupID, userIDs
+func (_m *GroupStore) DeleteMembers(groupID string, userIDs []string) store.StoreChannel {
+	ret := _m.Called(groupID, userIDs)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, []string) store.StoreChannel); ok {
+		r0 = rf(groupID, userIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // Get provides a mock function with given fields: groupID
 func (_m *GroupStore) Get(groupID string) store.StoreChannel {
 	ret := _m.Called(groupID)
@@ -173,6 +253,22 @@ func (_m *GroupStore) GetAllBySource(groupSource model.GroupSource) store.StoreC
 	return r0
 }
 
+// GetAllChannelPatterns provides a mock function with given fields:
+func (_m *GroupStore) GetAllChannelPatterns() store.StoreChannel {
+	ret := _m.Called()
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func() store.StoreChannel); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // GetAllGroupSyncablesByGroupId provides a mock function with given fields: groupID, syncableType
 func (_m *GroupStore) GetAllGroupSyncablesByGroupId(groupID string, syncableType model.GroupSyncableType) store.StoreChannel {
 	ret := _m.Called(groupID, syncableType)
@@ -189,6 +285,38 @@ func (_m *GroupStore) GetAllGroupSyncablesByGroupId(groupID string, syncableType
 	return r0
 }
 
+// GetByIDs provides a mock function with given fields: groupIDs
+func (_m *GroupStore) GetByIDs(groupIDs []string) store.StoreChannel {
+	ret := _m.Called(groupIDs)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func([]string) store.StoreChannel); ok {
+		r0 = rf(groupIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetByNames provides a mock function with given fields: names
+func (_m *GroupStore) GetByNames(names []string) store.StoreChannel {
+	ret := _m.Called(names)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func([]string) store.StoreChannel); ok {
+		r0 = rf(names)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // GetByRemoteID provides a mock function with given fields: remoteID, groupSource
 func (_m *GroupStore) GetByRemoteID(remoteID string, groupSource model.GroupSource) store.StoreChannel {
 	ret := _m.Called(remoteID, groupSource)
@@ -205,6 +333,118 @@ func (_m *GroupStore) GetByRemoteID(remoteID string, groupSource model.GroupSour
 	return r0
 }
 
+// GetChannelMembershipConflicts provides a mock function with given fields: channelID
+func (_m *GroupStore) GetChannelMembershipConflicts(channelID string) store.StoreChannel {
+	ret := _m.Called(channelID)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string) store.StoreChannel); ok {
+		r0 = rf(channelID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetChannelPatterns provides a mock function with given fields: groupID
+func (_m *GroupStore) GetChannelPatterns(groupID string) store.StoreChannel {
+	ret := _m.Called(groupID)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string) store.StoreChannel); ok {
+		r0 = rf(groupID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetChildGroupIds provides a mock function with given fields: parentIDs
+func (_m *GroupStore) GetChildGroupIds(parentIDs []string) store.StoreChannel {
+	ret := _m.Called(parentIDs)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func([]string) store.StoreChannel); ok {
+		r0 = rf(parentIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetDeleteImpact provides a mock function with given fields: groupID
+func (_m *GroupStore) GetDeleteImpact(groupID string) store.StoreChannel {
+	ret := _m.Called(groupID)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string) store.StoreChannel); ok {
+		r0 = rf(groupID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetExpiredGroupSyncables provides a mock function with given fields: syncableType, now, limit
+func (_m *GroupStore) GetExpiredGroupSyncables(syncableType model.GroupSyncableType, now int64, limit int) store.StoreChannel {
+	ret := _m.Called(syncableType, now, limit)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(model.GroupSyncableType, int64, int) store.StoreChannel); ok {
+		r0 = rf(syncableType, now, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetExpiredMembers provides a mock function with given fields: now, limit
+func (_m *GroupStore) GetExpiredMembers(now int64, limit int) store.StoreChannel {
+	ret := _m.Called(now, limit)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(int64, int) store.StoreChannel); ok {
+		r0 = rf(now, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetExpiringMemberCount provides a mock function with given fields: groupID, before
+func (_m *GroupStore) GetExpiringMemberCount(groupID string, before int64) store.StoreChannel {
+	ret := _m.Called(groupID, before)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, int64) store.StoreChannel); ok {
+		r0 = rf(groupID, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // GetGroupSyncable provides a mock function with given fields: groupID, syncableID, syncableType
 func (_m *GroupStore) GetGroupSyncable(groupID string, syncableID string, syncableType model.GroupSyncableType) store.StoreChannel {
 	ret := _m.Called(groupID, syncableID, syncableType)
@@ -221,13 +461,29 @@ func (_m *GroupStore) GetGroupSyncable(groupID string, syncableID string, syncab
 	return r0
 }
 
-// GetGroupsByChannel provides a mock function with given fields: channelId, page, perPage
-func (_m *GroupStore) GetGroupsByChannel(channelId string, page int, perPage int) store.StoreChannel {
-	ret := _m.Called(channelId, page, perPage)
+// GetGroups provides a mock function with given fields: page, perPage, opts
+func (_m *GroupStore) GetGroups(page int, perPage int, opts model.GroupSearchOpts) store.StoreChannel {
+	ret := _m.Called(page, perPage, opts)
 
 	var r0 store.StoreChannel
-	if rf, ok := ret.Get(0).(func(string, int, int) store.StoreChannel); ok {
-		r0 = rf(channelId, page, perPage)
+	if rf, ok := ret.Get(0).(func(int, int, model.GroupSearchOpts) store.StoreChannel); ok {
+		r0 = rf(page, perPage, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetGroupsByChannel provides a mock function with given fields: channelId, page, perPage, opts
+func (_m *GroupStore) GetGroupsByChannel(channelId string, page int, perPage int, opts model.GroupSearchOpts) store.StoreChannel {
+	ret := _m.Called(channelId, page, perPage, opts)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, int, int, model.GroupSearchOpts) store.StoreChannel); ok {
+		r0 = rf(channelId, page, perPage, opts)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(store.StoreChannel)
@@ -237,13 +493,13 @@ func (_m *GroupStore) GetGroupsByChannel(channelId string, page int, perPage int
 	return r0
 }
 
-// GetGroupsByTeam provides a mock function with given fields: teamId, page, perPage
-func (_m *GroupStore) GetGroupsByTeam(teamId string, page int, perPage int) store.StoreChannel {
-	ret := _m.Called(teamId, page, perPage)
+// GetGroupsByMemberEmailDomain provides a mock function with given fields: domain, page, perPage
+func (_m *GroupStore) GetGroupsByMemberEmailDomain(domain string, page int, perPage int) store.StoreChannel {
+	ret := _m.Called(domain, page, perPage)
 
 	var r0 store.StoreChannel
 	if rf, ok := ret.Get(0).(func(string, int, int) store.StoreChannel); ok {
-		r0 = rf(teamId, page, perPage)
+		r0 = rf(domain, page, perPage)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(store.StoreChannel)
@@ -253,13 +509,29 @@ func (_m *GroupStore) GetGroupsByTeam(teamId string, page int, perPage int) stor
 	return r0
 }
 
-// GetMemberCount provides a mock function with given fields: groupID
-func (_m *GroupStore) GetMemberCount(groupID string) store.StoreChannel {
-	ret := _m.Called(groupID)
+// GetGroupsByTeam provides a mock function with given fields: teamId, page, perPage, opts
+func (_m *GroupStore) GetGroupsByTeam(teamId string, page int, perPage int, opts model.GroupSearchOpts) store.StoreChannel {
+	ret := _m.Called(teamId, page, perPage, opts)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, int, int, model.GroupSearchOpts) store.StoreChannel); ok {
+		r0 = rf(teamId, page, perPage, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetGroupsByUserId provides a mock function with given fields: userID
+func (_m *GroupStore) GetGroupsByUserId(userID string) store.StoreChannel {
+	ret := _m.Called(userID)
 
 	var r0 store.StoreChannel
 	if rf, ok := ret.Get(0).(func(string) store.StoreChannel); ok {
-		r0 = rf(groupID)
+		r0 = rf(userID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(store.StoreChannel)
@@ -269,13 +541,29 @@ func (_m *GroupStore) GetMemberCount(groupID string) store.StoreChannel {
 	return r0
 }
 
-// GetMemberUsers provides a mock function with given fields: groupID
-func (_m *GroupStore) GetMemberUsers(groupID string) store.StoreChannel {
-	ret := _m.Called(groupID)
+// GetGroupsLastUpdateAt provides a mock function with given fields: opts
+func (_m *GroupStore) GetGroupsLastUpdateAt(opts model.GroupSearchOpts) store.StoreChannel {
+	ret := _m.Called(opts)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(model.GroupSearchOpts) store.StoreChannel); ok {
+		r0 = rf(opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetGroupsMissingTeamLink provides a mock function with given fields: channelID
+func (_m *GroupStore) GetGroupsMissingTeamLink(channelID string) store.StoreChannel {
+	ret := _m.Called(channelID)
 
 	var r0 store.StoreChannel
 	if rf, ok := ret.Get(0).(func(string) store.StoreChannel); ok {
-		r0 = rf(groupID)
+		r0 = rf(channelID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(store.StoreChannel)
@@ -285,13 +573,13 @@ func (_m *GroupStore) GetMemberUsers(groupID string) store.StoreChannel {
 	return r0
 }
 
-// GetMemberUsersPage provides a mock function with given fields: groupID, offset, limit
-func (_m *GroupStore) GetMemberUsersPage(groupID string, offset int, limit int) store.StoreChannel {
-	ret := _m.Called(groupID, offset, limit)
+// GetIntersectionMemberCount provides a mock function with given fields: groupIDs
+func (_m *GroupStore) GetIntersectionMemberCount(groupIDs []string) store.StoreChannel {
+	ret := _m.Called(groupIDs)
 
 	var r0 store.StoreChannel
-	if rf, ok := ret.Get(0).(func(string, int, int) store.StoreChannel); ok {
-		r0 = rf(groupID, offset, limit)
+	if rf, ok := ret.Get(0).(func([]string) store.StoreChannel); ok {
+		r0 = rf(groupIDs)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(store.StoreChannel)
@@ -301,13 +589,13 @@ func (_m *GroupStore) GetMemberUsersPage(groupID string, offset int, limit int)
 	return r0
 }
 
-// TeamMembersToAdd provides a mock function with given fields: since
-func (_m *GroupStore) TeamMembersToAdd(since int64) store.StoreChannel {
-	ret := _m.Called(since)
+// GetIntersectionMemberUsersPage provides a mock function with given fields: groupIDs, page, perPage
+func (_m *GroupStore) GetIntersectionMemberUsersPage(groupIDs []string, page int, perPage int) store.StoreChannel {
+	ret := _m.Called(groupIDs, page, perPage)
 
 	var r0 store.StoreChannel
-	if rf, ok := ret.Get(0).(func(int64) store.StoreChannel); ok {
-		r0 = rf(since)
+	if rf, ok := ret.Get(0).(func([]string, int, int) store.StoreChannel); ok {
+		r0 = rf(groupIDs, page, perPage)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(store.StoreChannel)
@@ -317,13 +605,13 @@ func (_m *GroupStore) TeamMembersToAdd(since int64) store.StoreChannel {
 	return r0
 }
 
-// TeamMembersToRemove provides a mock function with given fields:
-func (_m *GroupStore) TeamMembersToRemove() store.StoreChannel {
-	ret := _m.Called()
+// GetLinkedSyncableIds provides a mock function with given fields: groupIDs, syncableIDs, syncableType
+func (_m *GroupStore) GetLinkedSyncableIds(groupIDs []string, syncableIDs []string, syncableType model.GroupSyncableType) store.StoreChannel {
+	ret := _m.Called(groupIDs, syncableIDs, syncableType)
 
 	var r0 store.StoreChannel
-	if rf, ok := ret.Get(0).(func() store.StoreChannel); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func([]string, []string, model.GroupSyncableType) store.StoreChannel); ok {
+		r0 = rf(groupIDs, syncableIDs, syncableType)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(store.StoreChannel)
@@ -333,13 +621,13 @@ func (_m *GroupStore) TeamMembersToRemove() store.StoreChannel {
 	return r0
 }
 
-// Update provides a mock function with given fields: group
-func (_m *GroupStore) Update(group *model.Group) store.StoreChannel {
-	ret := _m.Called(group)
+// GetMemberCount provides a mock function with given fields: groupID
+func (_m *GroupStore) GetMemberCount(groupID string) store.StoreChannel {
+	ret := _m.Called(groupID)
 
 	var r0 store.StoreChannel
-	if rf, ok := ret.Get(0).(func(*model.Group) store.StoreChannel); ok {
-		r0 = rf(group)
+	if rf, ok := ret.Get(0).(func(string) store.StoreChannel); ok {
+		r0 = rf(groupID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(store.StoreChannel)
@@ -349,13 +637,482 @@ func (_m *GroupStore) Update(group *model.Group) store.StoreChannel {
 	return r0
 }
 
-// UpdateGroupSyncable provides a mock function with given fields: groupSyncable
-func (_m *GroupStore) UpdateGroupSyncable(groupSyncable *model.GroupSyncable) store.StoreChannel {
-	ret := _m.Called(groupSyncable)
+// GetMemberCountInChannel provides a mock function with given fields: groupID, channelID
+func (_m *GroupStore) GetMemberCountInChannel(groupID string, channelID string) store.StoreChannel {
+	ret := _m.Called(groupID, channelID)
 
 	var r0 store.StoreChannel
-	if rf, ok := ret.Get(0).(func(*model.GroupSyncable) store.StoreChannel); ok {
-		r0 = rf(groupSyncable)
+	if rf, ok := ret.Get(0).(func(string, string) store.StoreChannel); ok {
+		r0 = rf(groupID, channelID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetMemberHistory provides a mock function with given fields: groupID, from, to, page, perPage
+func (_m *GroupStore) GetMemberHistory(groupID string, from int64, to int64, page int, perPage int) store.StoreChannel {
+	ret := _m.Called(groupID, from, to, page, perPage)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, int64, int64, int, int) store.StoreChannel); ok {
+		r0 = rf(groupID, from, to, page, perPage)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetMemberIds provides a mock function with given fields: groupID, limit
+func (_m *GroupStore) GetMemberIds(groupID string, limit int) store.StoreChannel {
+	ret := _m.Called(groupID, limit)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, int) store.StoreChannel); ok {
+		r0 = rf(groupID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetMemberIdsPage provides a mock function with given fields: groupID, page, perPage
+func (_m *GroupStore) GetMemberIdsPage(groupID string, page int, perPage int) store.StoreChannel {
+	ret := _m.Called(groupID, page, perPage)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, int, int) store.StoreChannel); ok {
+		r0 = rf(groupID, page, perPage)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetMemberTimezones provides a mock function with given fields: groupID
+func (_m *GroupStore) GetMemberTimezones(groupID string) store.StoreChannel {
+	ret := _m.Called(groupID)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string) store.StoreChannel); ok {
+		r0 = rf(groupID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetMemberUsers provides a mock function with given fields: groupID
+func (_m *GroupStore) GetMemberUsers(groupID string) store.StoreChannel {
+	ret := _m.Called(groupID)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string) store.StoreChannel); ok {
+		r0 = rf(groupID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetMemberUsersExpiringPage provides a mock function with given fields: groupID, before, page, perPage
+func (_m *GroupStore) GetMemberUsersExpiringPage(groupID string, before int64, page int, perPage int) store.StoreChannel {
+	ret := _m.Called(groupID, before, page, perPage)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, int64, int, int) store.StoreChannel); ok {
+		r0 = rf(groupID, before, page, perPage)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetMemberUsersPage provides a mock function with given fields: groupID, page, perPage
+func (_m *GroupStore) GetMemberUsersPage(groupID string, page int, perPage int) store.StoreChannel {
+	ret := _m.Called(groupID, page, perPage)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, int, int) store.StoreChannel); ok {
+		r0 = rf(groupID, page, perPage)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetMembers provides a mock function with given fields: groupID, userIDs
+func (_m *GroupStore) GetMembers(groupID string, userIDs []string) store.StoreChannel {
+	ret := _m.Called(groupID, userIDs)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, []string) store.StoreChannel); ok {
+		r0 = rf(groupID, userIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetMembersNotInChannel provides a mock function with given fields: groupID, channelID
+func (_m *GroupStore) GetMembersNotInChannel(groupID string, channelID string) store.StoreChannel {
+	ret := _m.Called(groupID, channelID)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, string) store.StoreChannel); ok {
+		r0 = rf(groupID, channelID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetNestedMemberCount provides a mock function with given fields: groupIDs
+func (_m *GroupStore) GetNestedMemberCount(groupIDs []string) store.StoreChannel {
+	ret := _m.Called(groupIDs)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func([]string) store.StoreChannel); ok {
+		r0 = rf(groupIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetNestedMemberUsersPage provides a mock function with given fields: groupIDs, page, perPage
+func (_m *GroupStore) GetNestedMemberUsersPage(groupIDs []string, page int, perPage int) store.StoreChannel {
+	ret := _m.Called(groupIDs, page, perPage)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func([]string, int, int) store.StoreChannel); ok {
+		r0 = rf(groupIDs, page, perPage)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetStaleLdapGroups provides a mock function with given fields: since, page, perPage
+func (_m *GroupStore) GetStaleLdapGroups(since int64, page int, perPage int) store.StoreChannel {
+	ret := _m.Called(since, page, perPage)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(int64, int, int) store.StoreChannel); ok {
+		r0 = rf(since, page, perPage)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetStats provides a mock function with given fields: groupID, includeDeleted
+func (_m *GroupStore) GetStats(groupID string, includeDeleted bool) store.StoreChannel {
+	ret := _m.Called(groupID, includeDeleted)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, bool) store.StoreChannel); ok {
+		r0 = rf(groupID, includeDeleted)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetTeamGroupSyncables provides a mock function with given fields: teamID, page, perPage
+func (_m *GroupStore) GetTeamGroupSyncables(teamID string, page int, perPage int) store.StoreChannel {
+	ret := _m.Called(teamID, page, perPage)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, int, int) store.StoreChannel); ok {
+		r0 = rf(teamID, page, perPage)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetUngroupedUserCount provides a mock function with given fields:
+func (_m *GroupStore) GetUngroupedUserCount() store.StoreChannel {
+	ret := _m.Called()
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func() store.StoreChannel); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetUngroupedUsers provides a mock function with given fields: page, perPage
+func (_m *GroupStore) GetUngroupedUsers(page int, perPage int) store.StoreChannel {
+	ret := _m.Called(page, perPage)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(int, int) store.StoreChannel); ok {
+		r0 = rf(page, perPage)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetUnlinkedChannelsCreatedSince provides a mock function with given fields: groupID, namePrefix, since
+func (_m *GroupStore) GetUnlinkedChannelsCreatedSince(groupID string, namePrefix string, since int64) store.StoreChannel {
+	ret := _m.Called(groupID, namePrefix, since)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, string, int64) store.StoreChannel); ok {
+		r0 = rf(groupID, namePrefix, since)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetUnusedGroups provides a mock function with given fields: page, perPage, opts
+func (_m *GroupStore) GetUnusedGroups(page int, perPage int, opts model.UnusedGroupSearchOpts) store.StoreChannel {
+	ret := _m.Called(page, perPage, opts)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(int, int, model.UnusedGroupSearchOpts) store.StoreChannel); ok {
+		r0 = rf(page, perPage, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GroupSyncableExists provides a mock function with given fields: groupID, syncableID, syncableType
+func (_m *GroupStore) GroupSyncableExists(groupID string, syncableID string, syncableType model.GroupSyncableType) store.StoreChannel {
+	ret := _m.Called(groupID, syncableID, syncableType)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, string, model.GroupSyncableType) store.StoreChannel); ok {
+		r0 = rf(groupID, syncableID, syncableType)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// InvalidateMembershipCacheForUser provides a mock function with given fields: userID
+func (_m *GroupStore) InvalidateMembershipCacheForUser(userID string) {
+	_m.Called(userID)
+}
+
+// LogMemberEvents provides a mock function with given fields: groupID, userIDs, action
+func (_m *GroupStore) LogMemberEvents(groupID string, userIDs []string, action string) store.StoreChannel {
+	ret := _m.Called(groupID, userIDs, action)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, []string, string) store.StoreChannel); ok {
+		r0 = rf(groupID, userIDs, action)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// MergeGroups provides a mock function with given fields: targetID, sourceID
+func (_m *GroupStore) MergeGroups(targetID string, sourceID string) store.StoreChannel {
+	ret := _m.Called(targetID, sourceID)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, string) store.StoreChannel); ok {
+		r0 = rf(targetID, sourceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// SetLastSyncAt provides a mock function with given fields: groupID, lastSyncAt
+func (_m *GroupStore) SetLastSyncAt(groupID string, lastSyncAt int64) store.StoreChannel {
+	ret := _m.Called(groupID, lastSyncAt)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, int64) store.StoreChannel); ok {
+		r0 = rf(groupID, lastSyncAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// TeamMembersToAdd provides a mock function with given fields: since
+func (_m *GroupStore) TeamMembersToAdd(since int64) store.StoreChannel {
+	ret := _m.Called(since)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(int64) store.StoreChannel); ok {
+		r0 = rf(since)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// TeamMembersToRemove provides a mock function with given fields:
+func (_m *GroupStore) TeamMembersToRemove() store.StoreChannel {
+	ret := _m.Called()
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func() store.StoreChannel); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: group
+func (_m *GroupStore) Update(group *model.Group) store.StoreChannel {
+	ret := _m.Called(group)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(*model.Group) store.StoreChannel); ok {
+		r0 = rf(group)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// UpdateGroupSyncable provides a mock function with given fields: groupSyncable
+func (_m *GroupStore) UpdateGroupSyncable(groupSyncable *model.GroupSyncable) store.StoreChannel {
+	ret := _m.Called(groupSyncable)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(*model.GroupSyncable) store.StoreChannel); ok {
+		r0 = rf(groupSyncable)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// UpdateMember provides a mock function with given fields: member
+func (_m *GroupStore) UpdateMember(member *model.GroupMember) store.StoreChannel {
+	ret := _m.Called(member)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(*model.GroupMember) store.StoreChannel); ok {
+		r0 = rf(member)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// UpdateMetadata provides a mock function with given fields: groups
+func (_m *GroupStore) UpdateMetadata(groups []*model.Group) store.StoreChannel {
+	ret := _m.Called(groups)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func([]*model.Group) store.StoreChannel); ok {
+		r0 = rf(groups)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// UpsertMembers provides a mock function with given fields: groupID, userIDs
+func (_m *GroupStore) UpsertMembers(groupID string, userIDs []string) store.StoreChannel {
+	ret := _m.Called(groupID, userIDs)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, []string) store.StoreChannel); ok {
+		r0 = rf(groupID, userIDs)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(store.StoreChannel)
@@ -660,6 +660,22 @@ func (_m *LayeredStoreDatabaseLayer) GroupUpdateGroupSyncable(ctx context.Contex
 	return r0
 }
 
+// Idempotency provides a mock function with given fields:
+func (_m *LayeredStoreDatabaseLayer) Idempotency() store.IdempotencyStore {
+	ret := _m.Called()
+
+	var r0 store.IdempotencyStore
+	if rf, ok := ret.Get(0).(func() store.IdempotencyStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.IdempotencyStore)
+		}
+	}
+
+	return r0
+}
+
 // Job provides a mock function with given fields:
 func (_m *LayeredStoreDatabaseLayer) Job() store.JobStore {
 	ret := _m.Called()
@@ -198,6 +198,22 @@ func (_m *Store) Group() store.GroupStore {
 	return r0
 }
 
+// Idempotency provides a mock function with given fields:
+func (_m *Store) Idempotency() store.IdempotencyStore {
+	ret := _m.Called()
+
+	var r0 store.IdempotencyStore
+	if rf, ok := ret.Get(0).(func() store.IdempotencyStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.IdempotencyStore)
+		}
+	}
+
+	return r0
+}
+
 // Job provides a mock function with given fields:
 func (_m *Store) Job() store.JobStore {
 	ret := _m.Called()
@@ -50,6 +50,7 @@ type Store struct {
 	GroupStore                mocks.GroupStore
 	UserTermsOfServiceStore   mocks.UserTermsOfServiceStore
 	LinkMetadataStore         mocks.LinkMetadataStore
+	IdempotencyStore          mocks.IdempotencyStore
 }
 
 func (s *Store) Team() store.TeamStore                             { return &s.TeamStore }
@@ -85,6 +86,7 @@ func (s *Store) ChannelMemberHistory() store.ChannelMemberHistoryStore {
 }
 func (s *Store) Group() store.GroupStore               { return &s.GroupStore }
 func (s *Store) LinkMetadata() store.LinkMetadataStore { return &s.LinkMetadataStore }
+func (s *Store) Idempotency() store.IdempotencyStore   { return &s.IdempotencyStore }
 func (s *Store) MarkSystemRanUnitTests()               { /* do nothing */ }
 func (s *Store) Close()                                { /* do nothing */ }
 func (s *Store) LockToMaster()                         { /* do nothing */ }
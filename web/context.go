@@ -157,6 +157,12 @@ func (c *Context) SetInvalidUrlParam(parameter string) {
 	c.Err = NewInvalidUrlParamError(parameter)
 }
 
+// SetUnprocessableParam reports a body parameter that could be read but whose value breaks a rule, unlike
+// SetInvalidParam which is meant for a malformed body.
+func (c *Context) SetUnprocessableParam(parameter string) {
+	c.Err = NewUnprocessableParamError(parameter)
+}
+
 func (c *Context) HandleEtag(etag string, routeName string, w http.ResponseWriter, r *http.Request) bool {
 	metrics := c.App.Metrics
 	if et := r.Header.Get(model.HEADER_ETAG_CLIENT); len(etag) > 0 {
@@ -181,6 +187,10 @@ func NewInvalidParamError(parameter string) *model.AppError {
 	err := model.NewAppError("Context", "api.context.invalid_body_param.app_error", map[string]interface{}{"Name": parameter}, "", http.StatusBadRequest)
 	return err
 }
+func NewUnprocessableParamError(parameter string) *model.AppError {
+	err := model.NewAppError("Context", "api.context.invalid_body_param.app_error", map[string]interface{}{"Name": parameter}, "", http.StatusUnprocessableEntity)
+	return err
+}
 func NewInvalidUrlParamError(parameter string) *model.AppError {
 	err := model.NewAppError("Context", "api.context.invalid_url_param.app_error", map[string]interface{}{"Name": parameter}, "", http.StatusBadRequest)
 	return err
@@ -536,6 +546,28 @@ func (c *Context) RequireGroupId() *Context {
 	return c
 }
 
+func (c *Context) RequireSourceGroupId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.SourceGroupId) != 26 {
+		c.SetInvalidUrlParam("source_group_id")
+	}
+	return c
+}
+
+func (c *Context) RequireEmailDomain() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.EmailDomain) == 0 || strings.HasPrefix(c.Params.EmailDomain, ".") || strings.HasSuffix(c.Params.EmailDomain, ".") {
+		c.SetInvalidUrlParam("email_domain")
+	}
+	return c
+}
+
 func (c *Context) RequireRemoteId() *Context {
 	if c.Err != nil {
 		return c
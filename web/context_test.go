@@ -3,6 +3,8 @@ package web
 import (
 	"net/http"
 	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
 )
 
 func TestRequireHookId(t *testing.T) {
@@ -29,3 +31,31 @@ func TestRequireHookId(t *testing.T) {
 		}
 	})
 }
+
+func TestRequireSyncableType(t *testing.T) {
+	c := &Context{}
+
+	for _, syncableType := range []model.GroupSyncableType{model.GroupSyncableTypeTeam, model.GroupSyncableTypeChannel} {
+		c.Err = nil
+		c.Params = &Params{SyncableType: syncableType}
+		c.RequireSyncableType()
+
+		if c.Err != nil {
+			t.Fatalf("%s is a valid syncable type. Should not have set error in context", syncableType)
+		}
+	}
+
+	for _, syncableType := range []model.GroupSyncableType{"", "teams", "Bogus"} {
+		c.Err = nil
+		c.Params = &Params{SyncableType: syncableType}
+		c.RequireSyncableType()
+
+		if c.Err == nil {
+			t.Fatalf("%q is not a valid syncable type. Should have set error in context", syncableType)
+		}
+
+		if c.Err.StatusCode != http.StatusBadRequest {
+			t.Fatal("Should have set status as 400")
+		}
+	}
+}
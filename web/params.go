@@ -51,17 +51,35 @@ type Params struct {
 	SchemeId       string
 	Scope          string
 	GroupId        string
+	SourceGroupId  string
 	Page           int
 	PerPage        int
 	LogsPerPage    int
 	Permanent      bool
 	RemoteId       string
+	EmailDomain    string
 	SyncableId     string
 	SyncableType   model.GroupSyncableType
 	BotUserId      string
 	Q              string
 	IsLinked       *bool
 	IsConfigured   *bool
+
+	NotAssociatedToTeam       string
+	NotAssociatedToChannel    string
+	FilterParentTeamPermitted *string
+	IncludeSyncables          bool
+	IncludeMemberIds          bool
+	FilterAutoAdd             bool
+	Tag                       string
+	IncludeArchivedChannels   bool
+	IncludeTeamGroups         bool
+	IncludeChannelGroups      bool
+	ManageableOnly            bool
+	MemberOfMe                bool
+	ExcludeDefault            bool
+	Locale                    string
+	Envelope                  bool
 }
 
 func ParamsFromRequest(r *http.Request) *Params {
@@ -186,10 +204,18 @@ func ParamsFromRequest(r *http.Request) *Params {
 		params.GroupId = val
 	}
 
+	if val, ok := props["source_group_id"]; ok {
+		params.SourceGroupId = val
+	}
+
 	if val, ok := props["remote_id"]; ok {
 		params.RemoteId = val
 	}
 
+	if val, ok := props["email_domain"]; ok {
+		params.EmailDomain = strings.ToLower(val)
+	}
+
 	params.Scope = query.Get("scope")
 
 	if val, err := strconv.Atoi(query.Get("page")); err != nil || val < 0 {
@@ -223,11 +249,9 @@ func ParamsFromRequest(r *http.Request) *Params {
 	}
 
 	if val, ok := props["syncable_type"]; ok {
-		switch val {
-		case "teams":
-			params.SyncableType = model.GroupSyncableTypeTeam
-		case "channels":
-			params.SyncableType = model.GroupSyncableTypeChannel
+		// An unknown type is left empty and rejected by RequireSyncableType.
+		if syncableType, err := model.GroupSyncableTypeFromString(val); err == nil {
+			params.SyncableType = syncableType
 		}
 	}
 
@@ -245,5 +269,56 @@ func ParamsFromRequest(r *http.Request) *Params {
 		params.IsConfigured = &val
 	}
 
+	params.NotAssociatedToTeam = query.Get("not_associated_to_team")
+	params.NotAssociatedToChannel = query.Get("not_associated_to_channel")
+
+	if val, ok := query["filter_parent_team_permitted"]; ok && len(val) > 0 {
+		params.FilterParentTeamPermitted = &val[0]
+	}
+
+	if val, err := strconv.ParseBool(query.Get("include_syncables")); err == nil {
+		params.IncludeSyncables = val
+	}
+
+	if val, err := strconv.ParseBool(query.Get("include_member_ids")); err == nil {
+		params.IncludeMemberIds = val
+	}
+
+	if val, err := strconv.ParseBool(query.Get("manageable_only")); err == nil {
+		params.ManageableOnly = val
+	}
+
+	if val, err := strconv.ParseBool(query.Get("member_of_me")); err == nil {
+		params.MemberOfMe = val
+	}
+
+	params.Locale = query.Get("locale")
+
+	if val, err := strconv.ParseBool(query.Get("exclude_default")); err == nil {
+		params.ExcludeDefault = val
+	}
+
+	if val, err := strconv.ParseBool(query.Get("filter_auto_add")); err == nil {
+		params.FilterAutoAdd = val
+	}
+
+	params.Tag = query.Get("tag")
+
+	if val, err := strconv.ParseBool(query.Get("include_archived_channels")); err == nil {
+		params.IncludeArchivedChannels = val
+	}
+
+	if val, err := strconv.ParseBool(query.Get("include_team_groups")); err == nil {
+		params.IncludeTeamGroups = val
+	}
+
+	if val, err := strconv.ParseBool(query.Get("include_channel_groups")); err == nil {
+		params.IncludeChannelGroups = val
+	}
+
+	if val, err := strconv.ParseBool(query.Get("envelope")); err == nil {
+		params.Envelope = val
+	}
+
 	return params
 }
@@ -101,6 +101,15 @@ func completeSaml(c *Context, w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// A failure to update the user's SAML groups does not prevent them from logging in.
+		if len(*c.App.Config().SamlSettings.GroupAttribute) > 0 {
+			if attributes, err := samlInterface.GetAssertionAttributes(encodedXML); err != nil {
+				mlog.Error("Failed to read the group attribute of the SAML assertion", mlog.String("user_id", user.Id), mlog.Err(err))
+			} else if err := c.App.SyncSamlGroupsForUser(user.Id, attributes); err != nil {
+				mlog.Error("Failed to sync SAML groups", mlog.String("user_id", user.Id), mlog.Err(err))
+			}
+		}
+
 		switch action {
 		case model.OAUTH_ACTION_SIGNUP:
 			teamId := relayProps["team_id"]